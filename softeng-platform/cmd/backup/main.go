@@ -0,0 +1,107 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"softeng-platform/internal/backup"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/repository"
+	"strings"
+	"time"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// backup 导出全部数据为 JSON Lines 归档，或加 -restore 把归档导入空数据库。
+// 文件名以 .gz 结尾时自动压缩/解压，- 表示标准输出/输入；统计信息输出到标准错误
+func main() {
+	output := flag.String("o", "-", "导出的归档文件")
+	restore := flag.String("restore", "", "要导入的归档文件，指定时执行导入而不是导出")
+	filesDir := flag.String("files", "", "上传文件目录，导出时一并打包，导入时写入该目录")
+	timeout := flag.Duration("timeout", time.Hour, "单条语句的超时时间，导出大表时需要足够长")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	pool := cfg.DatabasePool
+	pool.QueryTimeout = *timeout
+	db, err := repository.NewDatabase(cfg.DatabaseDriver, cfg.DatabaseURL, pool)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	var stats *backup.Stats
+	if *restore != "" {
+		stats, err = restoreArchive(db, *restore, *filesDir)
+	} else {
+		stats, err = exportArchive(db, *output, *filesDir)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	encoder := json.NewEncoder(os.Stderr)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(stats); err != nil {
+		log.Fatal("Failed to write stats:", err)
+	}
+}
+
+func exportArchive(db *repository.Database, path, filesDir string) (*backup.Stats, error) {
+	if path == "-" {
+		return backup.Export(context.Background(), db, os.Stdout, filesDir)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := writeArchive(db, f, strings.HasSuffix(path, ".gz"), filesDir)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		// 不留下不完整的归档
+		os.Remove(path)
+		return nil, err
+	}
+	return stats, nil
+}
+
+func writeArchive(db *repository.Database, w io.Writer, compress bool, filesDir string) (*backup.Stats, error) {
+	if !compress {
+		return backup.Export(context.Background(), db, w, filesDir)
+	}
+	zw := gzip.NewWriter(w)
+	stats, err := backup.Export(context.Background(), db, zw, filesDir)
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	return stats, err
+}
+
+func restoreArchive(db *repository.Database, path, filesDir string) (*backup.Stats, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		r = f
+	}
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		r = zr
+	}
+	return backup.Restore(context.Background(), db, r, filesDir)
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/service"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// reconcile 按来源表重新统计点赞数、收藏数和回复数，修正偏差并删除孤儿行。
+// 数据库配置与服务端相同，报告以 JSON 输出到标准输出
+func main() {
+	dryRun := flag.Bool("dry-run", false, "只报告偏差和孤儿行，不做修改")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	db, err := repository.NewDatabase(cfg.DatabaseDriver, cfg.DatabaseURL, cfg.DatabasePool)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	reconcileService := service.NewReconcileService(repository.NewReconcileRepository(db))
+	report, err := reconcileService.Reconcile(context.Background(), !*dryRun)
+	if err != nil {
+		log.Fatal("Failed to reconcile counters:", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		log.Fatal("Failed to write report:", err)
+	}
+}
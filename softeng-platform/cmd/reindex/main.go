@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/repository"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// reindex 从数据库重建统一检索的嵌入式索引。数据库和索引目录的配置与服务端相同，
+// 索引同时只能被一个进程打开，重建前需要先停止服务端
+func main() {
+	path := flag.String("path", "", "索引目录，默认使用 SEARCH_INDEX_PATH")
+	flag.Parse()
+
+	cfg := config.LoadConfig()
+	if *path == "" {
+		*path = cfg.SearchIndexPath
+	}
+	if *path == "" {
+		log.Fatal("Search index path is not configured, set SEARCH_INDEX_PATH or -path")
+	}
+
+	db, err := repository.NewDatabase(cfg.DatabaseDriver, cfg.DatabaseURL, cfg.DatabasePool)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	count, err := repository.RebuildBleveSearchIndex(context.Background(), *path, repository.NewSearchRepository(db))
+	if err != nil {
+		log.Fatal("Failed to rebuild search index:", err)
+	}
+	log.Printf("Indexed %d resources into %s", count, *path)
+}
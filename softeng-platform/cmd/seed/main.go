@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/seed"
+
+	_ "github.com/joho/godotenv/autoload"
+)
+
+// seed 写入演示数据：-file 指定 YAML/JSON 数据文件，或用 -users 等参数生成随机数据，两者可以同时使用。
+// 数据按自然键匹配，重复执行不会产生重复行
+func main() {
+	file := flag.String("file", "", "YAML 或 JSON 格式的数据文件")
+	users := flag.Int("users", 0, "随机生成的用户数")
+	tools := flag.Int("tools", 0, "随机生成的工具数")
+	courses := flag.Int("courses", 0, "随机生成的课程数")
+	projects := flag.Int("projects", 0, "随机生成的项目数")
+	interactions := flag.Int("interactions", 5, "每个随机资源平均的点赞、收藏和评论数")
+	randomSeed := flag.Int64("seed", 1, "随机种子")
+	flag.Parse()
+
+	var batches []*seed.Fixtures
+	if *file != "" {
+		f, err := seed.LoadFile(*file)
+		if err != nil {
+			log.Fatal("Failed to load fixtures:", err)
+		}
+		batches = append(batches, f)
+	}
+	if *users+*tools+*courses+*projects > 0 {
+		f, err := seed.Generate(seed.Options{
+			Users:        *users,
+			Tools:        *tools,
+			Courses:      *courses,
+			Projects:     *projects,
+			Interactions: *interactions,
+			Seed:         *randomSeed,
+		})
+		if err != nil {
+			log.Fatal("Failed to generate fixtures:", err)
+		}
+		batches = append(batches, f)
+	}
+	if len(batches) == 0 {
+		log.Fatal("Nothing to seed: use -file or -users/-tools/-courses/-projects")
+	}
+
+	cfg := config.LoadConfig()
+	db, err := repository.NewDatabase(cfg.DatabaseDriver, cfg.DatabaseURL, cfg.DatabasePool)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer db.Close()
+
+	seeder := seed.NewSeeder(db)
+	for _, f := range batches {
+		summary, err := seeder.Apply(context.Background(), f)
+		if err != nil {
+			log.Fatal("Failed to seed database:", err)
+		}
+		fmt.Println(summary)
+	}
+}
@@ -1,186 +1,309 @@
-package main
-
-import (
-	"context"
-	"log"
-	"net/http"
-	"os"
-	"os/signal"
-	"softeng-platform/internal/config"
-	"softeng-platform/internal/handler"
-	"softeng-platform/internal/middleware"
-	"softeng-platform/internal/repository"
-	"softeng-platform/internal/service"
-	"syscall"
-	"time"
-
-	"github.com/gin-gonic/gin"
-	_ "github.com/joho/godotenv/autoload"
-)
-
-func main() {
-	// 初始化配置
-	cfg := config.LoadConfig()
-
-	// 初始化数据库
-	db, err := repository.NewDatabase(cfg.DatabaseURL)
-	if err != nil {
-		log.Fatal("Failed to connect to database:", err)
-	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			log.Printf("Error closing database: %v", err)
-		} else {
-			log.Println("Database connection closed")
-		}
-	}()
-
-	// 初始化仓库
-	userRepo := repository.NewUserRepository(db)
-	toolRepo := repository.NewToolRepository(db)
-	courseRepo := repository.NewCourseRepository(db)
-	projectRepo := repository.NewProjectRepository(db)
-
-	// 初始化服务
-	authService := service.NewAuthService(userRepo)
-	userService := service.NewUserService(userRepo)
-	toolService := service.NewToolService(toolRepo)
-	courseService := service.NewCourseService(courseRepo)
-	projectService := service.NewProjectService(projectRepo)
-	adminService := service.NewAdminService(toolRepo, courseRepo, projectRepo)
-
-	// 初始化处理器
-	authHandler := handler.NewAuthHandler(authService)
-	userHandler := handler.NewUserHandler(userService)
-	toolHandler := handler.NewToolHandler(toolService)
-	courseHandler := handler.NewCourseHandler(courseService)
-	projectHandler := handler.NewProjectHandler(projectService)
-	adminHandler := handler.NewAdminHandler(adminService)
-
-	// 设置路由
-	r := gin.Default()
-
-	// 中间件
-	r.Use(middleware.CORS())
-
-	// 认证路由
-	auth := r.Group("/auth")
-	{
-		auth.POST("/register", authHandler.Register)
-		auth.POST("/login", authHandler.Login)
-		auth.POST("/forgot-password", authHandler.ForgotPassword)
-	}
-
-	// 用户路由
-	users := r.Group("/users")
-	users.Use(middleware.AuthMiddleware())
-	{
-		users.POST("/logout", userHandler.Logout)
-		users.GET("/profile", userHandler.GetProfile)
-		users.GET("/status", userHandler.GetStatus)
-		users.GET("/collection", userHandler.GetCollection)
-		users.POST("/update", userHandler.UpdateProfile)
-		users.DELETE("/collection/:resourceType/:resourceId/", userHandler.DeleteCollection)
-		users.GET("/summit", userHandler.GetSummit)
-		users.PUT("/status/:resourceType/:resourceId/statu", userHandler.UpdateResourceStatus)
-		users.POST("/profile/new_email", userHandler.UpdateEmail)
-		users.POST("/profile/new_passward", userHandler.UpdatePassword) // 保持与API文档一致（即使拼写错误）
-	}
-
-	// 工具路由
-	tools := r.Group("/tools")
-	{
-		tools.GET("/profile", toolHandler.GetTools)
-		tools.GET("/search", toolHandler.SearchTools)
-		tools.GET("/:resourceId", toolHandler.GetTool)
-		tools.POST("/submit", middleware.AuthMiddleware(), toolHandler.SubmitTool)
-		tools.POST("/:resourceId/views", toolHandler.AddView)
-		tools.POST("/:resourceId/collections", middleware.AuthMiddleware(), toolHandler.CollectTool)
-		tools.DELETE("/:resourceId/collections", middleware.AuthMiddleware(), toolHandler.UncollectTool)
-		tools.POST("/:resourceId/comments", middleware.AuthMiddleware(), toolHandler.AddComment)
-		tools.DELETE("/:resourceId/comments", middleware.AuthMiddleware(), toolHandler.DeleteComment)
-		tools.POST("/:resourceId/comments/:commentId/reply", middleware.AuthMiddleware(), toolHandler.ReplyComment)
-		tools.DELETE("/:resourceId/comments/:commentId/reply", middleware.AuthMiddleware(), toolHandler.DeleteReply)
-		tools.POST("/:resourceId/like", middleware.AuthMiddleware(), toolHandler.LikeTool)
-		tools.DELETE("/:resourceId/like", middleware.AuthMiddleware(), toolHandler.UnlikeTool)
-	}
-
-	// 课程路由
-	courses := r.Group("/courses")
-	{
-		courses.GET("/profile", courseHandler.GetCourses)
-		courses.GET("/search", courseHandler.SearchCourses)
-		courses.GET("/:courseId", courseHandler.GetCourse)
-		courses.POST("/:courseId/upload", middleware.AuthMiddleware(), courseHandler.UploadResource)
-		courses.GET("/:courseId/textbooks/:textbookId/download", middleware.AuthMiddleware(), courseHandler.DownloadTextbook)
-		courses.POST("/:courseId/comments", middleware.AuthMiddleware(), courseHandler.AddComment)
-		courses.DELETE("/:courseId/comments", middleware.AuthMiddleware(), courseHandler.DeleteComment)
-		courses.POST("/:courseId/comments/:commentId/reply", middleware.AuthMiddleware(), courseHandler.ReplyComment)
-		courses.DELETE("/:courseId/comments/:commentId/reply", middleware.AuthMiddleware(), courseHandler.DeleteReply)
-		courses.POST("/:courseId/view", courseHandler.AddView)
-		courses.POST("/:courseId/collected", middleware.AuthMiddleware(), courseHandler.CollectCourse)
-		courses.DELETE("/:courseId/collected", middleware.AuthMiddleware(), courseHandler.UncollectCourse)
-		courses.POST("/:courseId/like", middleware.AuthMiddleware(), courseHandler.LikeCourse)
-		courses.DELETE("/:courseId/like", middleware.AuthMiddleware(), courseHandler.UnlikeCourse)
-	}
-
-	// 项目路由
-	projects := r.Group("/projects")
-	{
-		projects.GET("/profile", projectHandler.GetProjects)
-		projects.GET("/search", projectHandler.SearchProjects)
-		projects.GET("/:projectId", projectHandler.GetProject)
-		projects.PUT("/:projectId", middleware.AuthMiddleware(), projectHandler.UpdateProject)
-		projects.POST("/upload", middleware.AuthMiddleware(), projectHandler.UploadProject)
-		projects.POST("/:projectId/like", middleware.AuthMiddleware(), projectHandler.LikeProject)
-		projects.DELETE("/:projectId/like", middleware.AuthMiddleware(), projectHandler.UnlikeProject)
-		projects.POST("/:projectId/comments", middleware.AuthMiddleware(), projectHandler.AddComment)
-		projects.DELETE("/:projectId/comments", middleware.AuthMiddleware(), projectHandler.DeleteComment)
-		projects.POST("/:projectId/comments/:commentId/reply", middleware.AuthMiddleware(), projectHandler.ReplyComment)
-		projects.DELETE("/:projectId/comments/:commentId/reply", middleware.AuthMiddleware(), projectHandler.DeleteReply)
-		projects.POST("/:projectId/view", projectHandler.AddView)
-		projects.POST("/:projectId/collected", middleware.AuthMiddleware(), projectHandler.CollectProject)
-		projects.DELETE("/:projectId/collected", middleware.AuthMiddleware(), projectHandler.UncollectProject)
-	}
-
-	// 管理员路由
-	admin := r.Group("/admin")
-	admin.Use(middleware.AuthMiddleware()) // 先验证身份
-	admin.Use(middleware.AdminMiddleware()) // 再验证管理员权限
-	{
-		admin.GET("/pending", adminHandler.GetPending)
-		admin.POST("/review/:itemId", adminHandler.ReviewItem) // 改为POST方法以支持requestBody
-	}
-
-	// 创建HTTP服务器
-	srv := &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: r,
-	}
-
-	// 在goroutine中启动服务器
-	go func() {
-		log.Printf("Server starting on port %s", cfg.Port)
-		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Failed to start server: %v", err)
-		}
-	}()
-
-	// 等待中断信号以优雅地关闭服务器
-	quit := make(chan os.Signal, 1)
-	// 监听 SIGINT 和 SIGTERM 信号
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	log.Println("Shutting down server...")
-
-	// 设置5秒的超时时间用于优雅关闭
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	// 优雅关闭服务器
-	if err := srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
-	}
-
-	log.Println("Server exited")
-}
+package main
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"softeng-platform/internal/config"
+	"softeng-platform/internal/handler"
+	"softeng-platform/internal/mail"
+	"softeng-platform/internal/middleware"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/service"
+	"syscall"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	_ "github.com/joho/godotenv/autoload"
+)
+
+func main() {
+	// 初始化配置
+	cfg := config.LoadConfig()
+
+	// 初始化数据库
+	db, err := repository.NewDatabase(cfg.DatabaseDriver, cfg.DatabaseURL, cfg.DatabasePool)
+	if err != nil {
+		log.Fatal("Failed to connect to database:", err)
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			log.Printf("Error closing database: %v", err)
+		} else {
+			log.Println("Database connection closed")
+		}
+	}()
+
+	// 初始化仓库
+	userRepo := repository.NewUserRepository(db)
+	toolRepo := repository.NewToolRepository(db)
+	courseRepo := repository.NewCourseRepository(db)
+	projectRepo := repository.NewProjectRepository(db)
+	trashRepo := repository.NewTrashRepository(db)
+	viewRepo := repository.NewViewRepository(db)
+	reconcileRepo := repository.NewReconcileRepository(db)
+	searchRepo := repository.NewSearchRepository(db)
+	savedSearchRepo := repository.NewSavedSearchRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	reviewRepo := repository.NewReviewRepository(db)
+	reviewClaimRepo := repository.NewReviewClaimRepository(db)
+	commentReportRepo := repository.NewCommentReportRepository(db)
+	sensitiveWordRepo := repository.NewSensitiveWordRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+
+	// 统一检索优先使用嵌入式索引，未配置时直接查询数据库
+	searchIndex := repository.NewSQLSearchIndex(searchRepo)
+	if cfg.SearchIndexPath != "" {
+		searchIndex, err = repository.OpenBleveSearchIndex(context.Background(), cfg.SearchIndexPath, searchRepo)
+		if err != nil {
+			log.Fatal("Failed to open search index:", err)
+		}
+	}
+	defer func() {
+		if err := searchIndex.Close(); err != nil {
+			log.Printf("Error closing search index: %v", err)
+		}
+	}()
+
+	// 初始化服务
+	authService := service.NewAuthService(userRepo)
+	cursors := pagination.NewCodec(cfg.CursorSecret)
+	auditor := service.NewAuditor(db, auditLogRepo)
+	trashService := service.NewTrashService(trashRepo, searchIndex, auditor, cursors, cfg.TrashRetention)
+	userService := service.NewUserService(userRepo, trashService, reviewRepo, cursors)
+	viewCounter := service.NewViewCounter(viewRepo, searchIndex, cfg.ViewDedupWindow)
+	suggester := service.NewSuggester(searchRepo)
+	if count, err := suggester.Refresh(context.Background()); err != nil {
+		log.Printf("Failed to load search suggestions: %v", err)
+	} else {
+		log.Printf("Loaded %d search suggestions", count)
+	}
+	contentFilter := service.NewContentFilter(sensitiveWordRepo, commentReportRepo)
+	if count, err := contentFilter.Refresh(context.Background()); err != nil {
+		log.Printf("Failed to load sensitive words: %v", err)
+	} else {
+		log.Printf("Loaded %d sensitive words", count)
+	}
+	toolService := service.NewToolService(toolRepo, searchIndex, viewCounter, suggester, contentFilter, cursors)
+	courseService := service.NewCourseService(courseRepo, searchIndex, viewCounter, suggester, contentFilter, cursors)
+	projectService := service.NewProjectService(projectRepo, searchIndex, viewCounter, suggester, contentFilter, cursors)
+	searchService := service.NewSearchService(searchIndex, suggester, cursors)
+	reconcileService := service.NewReconcileService(reconcileRepo)
+	healthService := service.NewHealthService(db)
+	notifier := service.NewNotifier(notificationRepo, userRepo, mail.New(cfg.SMTP))
+	adminService := service.NewAdminService(toolRepo, courseRepo, projectRepo, reviewRepo, commentReportRepo, reviewClaimRepo,
+		searchIndex, notifier, auditor, cursors, cfg.ReviewBatchLimit, cfg.ReviewClaimLease)
+	commentReportService := service.NewCommentReportService(commentReportRepo, notifier, cfg.CommentReportThreshold)
+	sensitiveWordService := service.NewSensitiveWordService(sensitiveWordRepo, contentFilter, auditor)
+	auditService := service.NewAuditService(auditLogRepo, cursors)
+	savedSearchService := service.NewSavedSearchService(savedSearchRepo)
+	notificationService := service.NewNotificationService(notificationRepo, cursors)
+	alertMatcher := service.NewAlertMatcher(savedSearchRepo, searchRepo, notificationRepo, notifier, cfg.AlertLimit, cfg.AlertWindow)
+
+	// 初始化处理器
+	authHandler := handler.NewAuthHandler(authService)
+	userHandler := handler.NewUserHandler(userService, trashService)
+	toolHandler := handler.NewToolHandler(toolService)
+	courseHandler := handler.NewCourseHandler(courseService)
+	projectHandler := handler.NewProjectHandler(projectService)
+	searchHandler := handler.NewSearchHandler(searchService)
+	adminHandler := handler.NewAdminHandler(adminService, trashService)
+	healthHandler := handler.NewHealthHandler(healthService)
+	notificationHandler := handler.NewNotificationHandler(savedSearchService, notificationService)
+	commentHandler := handler.NewCommentHandler(commentReportService)
+	sensitiveWordHandler := handler.NewSensitiveWordHandler(sensitiveWordService)
+	auditHandler := handler.NewAuditHandler(auditService)
+
+	// 设置路由
+	r := gin.Default()
+
+	// 中间件
+	r.Use(middleware.CORS())
+	r.Use(middleware.RequestID())
+
+	// 健康检查，供负载均衡和容器探活使用
+	r.GET("/healthz", healthHandler.Health)
+
+	// 认证路由
+	auth := r.Group("/auth")
+	{
+		auth.POST("/register", authHandler.Register)
+		auth.POST("/login", authHandler.Login)
+		auth.POST("/forgot-password", authHandler.ForgotPassword)
+	}
+
+	// 用户路由
+	users := r.Group("/users")
+	users.Use(middleware.AuthMiddleware())
+	{
+		users.POST("/logout", userHandler.Logout)
+		users.GET("/profile", userHandler.GetProfile)
+		users.GET("/status", userHandler.GetStatus)
+		users.GET("/collection", userHandler.GetCollection)
+		users.POST("/update", userHandler.UpdateProfile)
+		users.DELETE("/collection/:resourceType/:resourceId/", userHandler.DeleteCollection)
+		users.GET("/summit", userHandler.GetSummit)
+		users.PUT("/status/:resourceType/:resourceId/statu", userHandler.UpdateResourceStatus)
+		users.GET("/trash", userHandler.GetTrash)
+		users.POST("/trash/:resourceType/:resourceId/restore", userHandler.RestoreTrash)
+		users.DELETE("/trash/:resourceType/:resourceId", userHandler.PurgeTrash)
+		users.POST("/profile/new_email", userHandler.UpdateEmail)
+		users.POST("/profile/new_passward", userHandler.UpdatePassword) // 保持与API文档一致（即使拼写错误）
+		users.GET("/saved-searches", notificationHandler.GetSavedSearches)
+		users.POST("/saved-searches", notificationHandler.CreateSavedSearch)
+		users.DELETE("/saved-searches/:id", notificationHandler.DeleteSavedSearch)
+		users.GET("/notifications", notificationHandler.GetNotifications)
+		users.POST("/notifications/read", notificationHandler.MarkAllNotificationsRead)
+		users.POST("/notifications/:id/read", notificationHandler.MarkNotificationRead)
+	}
+
+	// 统一检索
+	r.GET("/search", searchHandler.Search)
+	r.GET("/search/suggest", searchHandler.Suggest)
+
+	// 评论举报，评论ID在工具、课程、项目之间唯一
+	r.POST("/comments/:commentId/report", middleware.AuthMiddleware(), commentHandler.ReportComment)
+
+	// 工具路由
+	tools := r.Group("/tools")
+	{
+		tools.GET("/profile", toolHandler.GetTools)
+		tools.GET("/search", toolHandler.SearchTools)
+		tools.GET("/:resourceId", toolHandler.GetTool)
+		tools.POST("/submit", middleware.AuthMiddleware(), toolHandler.SubmitTool)
+		tools.POST("/:resourceId/views", middleware.OptionalAuth(), toolHandler.AddView)
+		tools.GET("/:resourceId/views/daily", toolHandler.GetDailyViews)
+		tools.POST("/:resourceId/collections", middleware.AuthMiddleware(), toolHandler.CollectTool)
+		tools.DELETE("/:resourceId/collections", middleware.AuthMiddleware(), toolHandler.UncollectTool)
+		tools.GET("/:resourceId/comments", toolHandler.GetComments)
+		tools.POST("/:resourceId/comments", middleware.AuthMiddleware(), toolHandler.AddComment)
+		tools.DELETE("/:resourceId/comments", middleware.AuthMiddleware(), toolHandler.DeleteComment)
+		tools.POST("/:resourceId/comments/:commentId/reply", middleware.AuthMiddleware(), toolHandler.ReplyComment)
+		tools.DELETE("/:resourceId/comments/:commentId/reply", middleware.AuthMiddleware(), toolHandler.DeleteReply)
+		tools.POST("/:resourceId/like", middleware.AuthMiddleware(), toolHandler.LikeTool)
+		tools.DELETE("/:resourceId/like", middleware.AuthMiddleware(), toolHandler.UnlikeTool)
+	}
+
+	// 课程路由
+	courses := r.Group("/courses")
+	{
+		courses.GET("/profile", courseHandler.GetCourses)
+		courses.GET("/search", courseHandler.SearchCourses)
+		courses.GET("/:courseId", courseHandler.GetCourse)
+		courses.POST("/:courseId/upload", middleware.AuthMiddleware(), courseHandler.UploadResource)
+		courses.GET("/:courseId/textbooks/:textbookId/download", middleware.AuthMiddleware(), courseHandler.DownloadTextbook)
+		courses.GET("/:courseId/comments", courseHandler.GetComments)
+		courses.POST("/:courseId/comments", middleware.AuthMiddleware(), courseHandler.AddComment)
+		courses.DELETE("/:courseId/comments", middleware.AuthMiddleware(), courseHandler.DeleteComment)
+		courses.POST("/:courseId/comments/:commentId/reply", middleware.AuthMiddleware(), courseHandler.ReplyComment)
+		courses.DELETE("/:courseId/comments/:commentId/reply", middleware.AuthMiddleware(), courseHandler.DeleteReply)
+		courses.POST("/:courseId/view", middleware.OptionalAuth(), courseHandler.AddView)
+		courses.GET("/:courseId/view/daily", courseHandler.GetDailyViews)
+		courses.POST("/:courseId/collected", middleware.AuthMiddleware(), courseHandler.CollectCourse)
+		courses.DELETE("/:courseId/collected", middleware.AuthMiddleware(), courseHandler.UncollectCourse)
+		courses.POST("/:courseId/like", middleware.AuthMiddleware(), courseHandler.LikeCourse)
+		courses.DELETE("/:courseId/like", middleware.AuthMiddleware(), courseHandler.UnlikeCourse)
+	}
+
+	// 项目路由
+	projects := r.Group("/projects")
+	{
+		projects.GET("/profile", projectHandler.GetProjects)
+		projects.GET("/search", projectHandler.SearchProjects)
+		projects.GET("/:projectId", projectHandler.GetProject)
+		projects.PUT("/:projectId", middleware.AuthMiddleware(), projectHandler.UpdateProject)
+		projects.POST("/upload", middleware.AuthMiddleware(), projectHandler.UploadProject)
+		projects.POST("/:projectId/like", middleware.AuthMiddleware(), projectHandler.LikeProject)
+		projects.DELETE("/:projectId/like", middleware.AuthMiddleware(), projectHandler.UnlikeProject)
+		projects.GET("/:projectId/comments", projectHandler.GetComments)
+		projects.POST("/:projectId/comments", middleware.AuthMiddleware(), projectHandler.AddComment)
+		projects.DELETE("/:projectId/comments", middleware.AuthMiddleware(), projectHandler.DeleteComment)
+		projects.POST("/:projectId/comments/:commentId/reply", middleware.AuthMiddleware(), projectHandler.ReplyComment)
+		projects.DELETE("/:projectId/comments/:commentId/reply", middleware.AuthMiddleware(), projectHandler.DeleteReply)
+		projects.POST("/:projectId/view", middleware.OptionalAuth(), projectHandler.AddView)
+		projects.GET("/:projectId/view/daily", projectHandler.GetDailyViews)
+		projects.POST("/:projectId/collected", middleware.AuthMiddleware(), projectHandler.CollectProject)
+		projects.DELETE("/:projectId/collected", middleware.AuthMiddleware(), projectHandler.UncollectProject)
+	}
+
+	// 管理员路由
+	admin := r.Group("/admin")
+	admin.Use(middleware.AuthMiddleware()) // 先验证身份
+	admin.Use(middleware.AdminMiddleware()) // 再验证管理员权限
+	{
+		admin.GET("/pending", adminHandler.GetPending)
+		admin.POST("/pending/assign", adminHandler.AssignPending)
+		admin.POST("/review/batch", adminHandler.ReviewItems)
+		admin.POST("/review/:resourceType/:itemId", adminHandler.ReviewItem)
+		admin.POST("/review/:resourceType", adminHandler.ReviewItem) // 兼容旧接口，参数为带类型前缀的 itemId，如 tool-12
+		admin.GET("/review/:resourceType/:itemId/history", adminHandler.GetReviewHistory)
+		admin.POST("/review/:resourceType/:itemId/claim", adminHandler.ClaimItem)
+		admin.DELETE("/review/:resourceType/:itemId/claim", adminHandler.ReleaseItem)
+		admin.POST("/comments/:commentId/moderate", adminHandler.ModerateComment)
+		admin.GET("/sensitive-words", sensitiveWordHandler.GetWords)
+		admin.POST("/sensitive-words", sensitiveWordHandler.AddWord)
+		admin.DELETE("/sensitive-words/:id", sensitiveWordHandler.DeleteWord)
+		admin.POST("/sensitive-words/reload", sensitiveWordHandler.ReloadWords)
+		admin.DELETE("/resources/:resourceType/:resourceId", adminHandler.DeleteResource)
+		admin.GET("/trash", adminHandler.GetTrash)
+		admin.POST("/trash/:resourceType/:resourceId/restore", adminHandler.RestoreTrash)
+		admin.DELETE("/trash/:resourceType/:resourceId", adminHandler.PurgeTrash)
+		admin.GET("/audit", auditHandler.GetAuditLogs)
+		admin.GET("/audit/export", auditHandler.ExportAuditLogs)
+		admin.GET("/db/stats", healthHandler.DBStats)
+	}
+
+	// 后台任务：定期永久删除超过保留期的回收站资源、写入内存中聚合的浏览量、校对计数、重建输入提示、发送检索提醒、分配待审核资源
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+	go service.RunTrashRetention(jobsCtx, trashService, cfg.TrashPurgeInterval)
+	go viewCounter.Run(jobsCtx, cfg.ViewFlushInterval)
+	go service.RunReconcile(jobsCtx, reconcileService, cfg.ReconcileInterval)
+	go suggester.Run(jobsCtx, cfg.SuggestRefreshInterval)
+	go contentFilter.Run(jobsCtx, cfg.SensitiveRefreshInterval)
+	go alertMatcher.Run(jobsCtx, cfg.AlertInterval)
+	go service.RunReviewAssignment(jobsCtx, adminService, cfg.ReviewAssignInterval)
+
+	// 创建HTTP服务器
+	srv := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: r,
+	}
+
+	// 在goroutine中启动服务器
+	go func() {
+		log.Printf("Server starting on port %s", cfg.Port)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// 等待中断信号以优雅地关闭服务器
+	quit := make(chan os.Signal, 1)
+	// 监听 SIGINT 和 SIGTERM 信号
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	<-quit
+	log.Println("Shutting down server...")
+	stopJobs()
+
+	// 设置5秒的超时时间用于优雅关闭
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// 优雅关闭服务器；超时只记录日志，仍需写入浏览量并执行 defer 中的关闭数据库和索引
+	if err := srv.Shutdown(ctx); err != nil {
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
+	// 请求处理完毕（或关闭超时）后写入剩余的浏览量，与关闭服务器使用不同的超时，避免被前者耗尽
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	if err := viewCounter.Flush(flushCtx); err != nil {
+		log.Printf("Failed to flush views: %v", err)
+	}
+
+	log.Println("Server exited")
+}
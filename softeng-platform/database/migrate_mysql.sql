@@ -0,0 +1,126 @@
+-- 已有 MySQL 数据库的升级脚本
+-- schema.sql 中的 CREATE TABLE IF NOT EXISTS 不会给已存在的表加列，旧库需先执行本脚本再执行 schema.sql（创建新增的表）：
+--   mysql -u root -p < database/migrate_mysql.sql
+--   mysql -u root -p < database/schema.sql
+-- 每一步都先检查 information_schema，可以重复执行；新增的列、索引和外键需同步加到本脚本
+
+USE softeng;
+
+DROP PROCEDURE IF EXISTS add_column_if_missing;
+DROP PROCEDURE IF EXISTS add_index_if_missing;
+DROP PROCEDURE IF EXISTS add_foreign_key_if_missing;
+
+DELIMITER //
+
+-- 列不存在时添加；backfill 不为空时在加列后执行一次，用于给已有的行补上取值
+CREATE PROCEDURE add_column_if_missing(IN tbl VARCHAR(64), IN col VARCHAR(64), IN def TEXT, IN backfill TEXT)
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.COLUMNS
+        WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = tbl AND COLUMN_NAME = col) THEN
+        SET @ddl = CONCAT('ALTER TABLE ', tbl, ' ADD COLUMN ', col, ' ', def);
+        PREPARE stmt FROM @ddl;
+        EXECUTE stmt;
+        DEALLOCATE PREPARE stmt;
+        IF backfill IS NOT NULL THEN
+            SET @dml = backfill;
+            PREPARE stmt FROM @dml;
+            EXECUTE stmt;
+            DEALLOCATE PREPARE stmt;
+        END IF;
+    END IF;
+END //
+
+-- 同名索引不存在时添加，def 为 ADD 之后的索引定义
+CREATE PROCEDURE add_index_if_missing(IN tbl VARCHAR(64), IN idx VARCHAR(64), IN def TEXT)
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.STATISTICS
+        WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = tbl AND INDEX_NAME = idx) THEN
+        SET @ddl = CONCAT('ALTER TABLE ', tbl, ' ADD ', def);
+        PREPARE stmt FROM @ddl;
+        EXECUTE stmt;
+        DEALLOCATE PREPARE stmt;
+    END IF;
+END //
+
+-- 列上还没有指向 users(id) 的外键时添加（CREATE TABLE 中的外键名由 MySQL 生成，因此按列判断）
+CREATE PROCEDURE add_foreign_key_if_missing(IN tbl VARCHAR(64), IN col VARCHAR(64))
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM information_schema.KEY_COLUMN_USAGE
+        WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = tbl AND COLUMN_NAME = col
+        AND REFERENCED_TABLE_NAME = 'users') THEN
+        SET @ddl = CONCAT('ALTER TABLE ', tbl, ' ADD FOREIGN KEY (', col, ') REFERENCES users(id) ON DELETE SET NULL');
+        PREPARE stmt FROM @ddl;
+        EXECUTE stmt;
+        DEALLOCATE PREPARE stmt;
+    END IF;
+END //
+
+DELIMITER ;
+
+-- ==================== 回收站（软删除）与乐观锁版本 ====================
+
+CALL add_column_if_missing('tools', 'deleted_at', "TIMESTAMP NULL COMMENT '删除时间（软删除，进入回收站）'", NULL);
+CALL add_column_if_missing('tools', 'deleted_by', "INT NULL COMMENT '删除操作用户ID'", NULL);
+CALL add_column_if_missing('tools', 'version', "INT NOT NULL DEFAULT 1 COMMENT '乐观锁版本号，资源行修改、删除、恢复时加一'", NULL);
+CALL add_index_if_missing('tools', 'idx_deleted_at', 'INDEX idx_deleted_at (deleted_at)');
+CALL add_foreign_key_if_missing('tools', 'deleted_by');
+
+CALL add_column_if_missing('courses', 'deleted_at', "TIMESTAMP NULL COMMENT '删除时间（软删除，进入回收站）'", NULL);
+CALL add_column_if_missing('courses', 'deleted_by', "INT NULL COMMENT '删除操作用户ID'", NULL);
+CALL add_column_if_missing('courses', 'version', "INT NOT NULL DEFAULT 1 COMMENT '乐观锁版本号，资源行修改、删除、恢复时加一'", NULL);
+CALL add_index_if_missing('courses', 'idx_deleted_at', 'INDEX idx_deleted_at (deleted_at)');
+CALL add_foreign_key_if_missing('courses', 'deleted_by');
+
+CALL add_column_if_missing('projects', 'deleted_at', "TIMESTAMP NULL COMMENT '删除时间（软删除，进入回收站）'", NULL);
+CALL add_column_if_missing('projects', 'deleted_by', "INT NULL COMMENT '删除操作用户ID'", NULL);
+CALL add_column_if_missing('projects', 'version', "INT NOT NULL DEFAULT 1 COMMENT '乐观锁版本号，资源行修改、删除、恢复时加一'", NULL);
+CALL add_index_if_missing('projects', 'idx_deleted_at', 'INDEX idx_deleted_at (deleted_at)');
+CALL add_foreign_key_if_missing('projects', 'deleted_by');
+
+-- ==================== 项目和课程资源的审核状态 ====================
+-- 升级前的项目和课程资源都已公开，加列时标为已通过，审核时间取创建时间
+
+CALL add_column_if_missing('projects', 'status', "VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected'",
+    "UPDATE projects SET status = 'approved'");
+CALL add_column_if_missing('projects', 'audit_time', "TIMESTAMP NULL COMMENT '审核时间'",
+    "UPDATE projects SET audit_time = created_at WHERE status = 'approved'");
+CALL add_column_if_missing('projects', 'reject_reason', "TEXT COMMENT '驳回原因'", NULL);
+CALL add_column_if_missing('projects', 'submitter_id', "INT COMMENT '提交用户ID'", NULL);
+CALL add_index_if_missing('projects', 'idx_status', 'INDEX idx_status (status)');
+CALL add_index_if_missing('projects', 'idx_submitter', 'INDEX idx_submitter (submitter_id)');
+CALL add_foreign_key_if_missing('projects', 'submitter_id');
+
+CALL add_column_if_missing('course_resources_web', 'status', "VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected'",
+    "UPDATE course_resources_web SET status = 'approved'");
+CALL add_column_if_missing('course_resources_web', 'audit_time', "TIMESTAMP NULL COMMENT '审核时间'",
+    "UPDATE course_resources_web SET audit_time = created_at WHERE status = 'approved'");
+CALL add_column_if_missing('course_resources_web', 'reject_reason', "TEXT COMMENT '驳回原因'", NULL);
+CALL add_column_if_missing('course_resources_web', 'submitter_id', "INT COMMENT '提交用户ID'", NULL);
+CALL add_index_if_missing('course_resources_web', 'idx_status', 'INDEX idx_status (status)');
+CALL add_foreign_key_if_missing('course_resources_web', 'submitter_id');
+
+CALL add_column_if_missing('course_resources_upload', 'status', "VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected'",
+    "UPDATE course_resources_upload SET status = 'approved'");
+CALL add_column_if_missing('course_resources_upload', 'audit_time', "TIMESTAMP NULL COMMENT '审核时间'",
+    "UPDATE course_resources_upload SET audit_time = created_at WHERE status = 'approved'");
+CALL add_column_if_missing('course_resources_upload', 'reject_reason', "TEXT COMMENT '驳回原因'", NULL);
+CALL add_column_if_missing('course_resources_upload', 'submitter_id', "INT COMMENT '提交用户ID'", NULL);
+CALL add_index_if_missing('course_resources_upload', 'idx_status', 'INDEX idx_status (status)');
+CALL add_foreign_key_if_missing('course_resources_upload', 'submitter_id');
+
+-- ==================== 评论隐藏 ====================
+
+CALL add_column_if_missing('comments', 'hidden_at', "TIMESTAMP NULL COMMENT '被举报或审核隐藏的时间'", NULL);
+
+-- ==================== 全文索引 ====================
+-- 使用 ngram 分词；数据量大时建索引耗时较长
+
+CALL add_index_if_missing('tools', 'ft_tool_text',
+    'FULLTEXT INDEX ft_tool_text (resource_name, description, description_detail) WITH PARSER ngram');
+CALL add_index_if_missing('courses', 'ft_course_text', 'FULLTEXT INDEX ft_course_text (name) WITH PARSER ngram');
+CALL add_index_if_missing('projects', 'ft_project_text',
+    'FULLTEXT INDEX ft_project_text (name, description, detail) WITH PARSER ngram');
+
+DROP PROCEDURE add_column_if_missing;
+DROP PROCEDURE add_index_if_missing;
+DROP PROCEDURE add_foreign_key_if_missing;
@@ -0,0 +1,9 @@
+// Package database 保存数据库表结构
+package database
+
+import _ "embed"
+
+// SQLiteSchema 是 schema.sql 的 SQLite 版本，使用 SQLite 后端时启动自动执行
+//
+//go:embed schema_sqlite.sql
+var SQLiteSchema string
@@ -43,10 +43,18 @@ CREATE TABLE IF NOT EXISTS tools (
     audit_time TIMESTAMP NULL COMMENT '审核时间',
     reject_reason TEXT COMMENT '驳回原因',
     submitter_id INT COMMENT '提交用户ID',
+    deleted_at TIMESTAMP NULL COMMENT '删除时间（软删除，进入回收站）',
+    deleted_by INT NULL COMMENT '删除操作用户ID',
+    version INT NOT NULL DEFAULT 1 COMMENT '乐观锁版本号，资源行修改、删除、恢复时加一',
     INDEX idx_category (category),
     INDEX idx_status (status),
     INDEX idx_submitter (submitter_id),
-    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
+    INDEX idx_deleted_at (deleted_at),
+    -- 全文索引使用 ngram 分词以支持中文；旧版本创建的索引需要重建，课程和项目表同理：
+    -- ALTER TABLE tools DROP INDEX ft_tool_text, ADD FULLTEXT INDEX ft_tool_text (resource_name, description, description_detail) WITH PARSER ngram;
+    FULLTEXT INDEX ft_tool_text (resource_name, description, description_detail) WITH PARSER ngram,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (deleted_by) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='工具表';
 
 -- 工具图片表
@@ -98,8 +106,14 @@ CREATE TABLE IF NOT EXISTS courses (
     collections INT DEFAULT 0 COMMENT '收藏量',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL COMMENT '删除时间（软删除，进入回收站）',
+    deleted_by INT NULL COMMENT '删除操作用户ID',
+    version INT NOT NULL DEFAULT 1 COMMENT '乐观锁版本号，资源行修改、删除、恢复时加一',
     INDEX idx_semester (semester),
-    INDEX idx_name (name)
+    INDEX idx_name (name),
+    INDEX idx_deleted_at (deleted_at),
+    FULLTEXT INDEX ft_course_text (name) WITH PARSER ngram,
+    FOREIGN KEY (deleted_by) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程表';
 
 -- 课程教师表
@@ -128,8 +142,14 @@ CREATE TABLE IF NOT EXISTS course_resources_web (
     resource_url VARCHAR(500) NOT NULL COMMENT '资源网址',
     sort_order INT DEFAULT 0 COMMENT '排序',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT COMMENT '驳回原因',
+    submitter_id INT COMMENT '提交用户ID',
     INDEX idx_course_id (course_id),
-    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+    INDEX idx_status (status),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程URL资源表';
 
 -- 课程资源表（上传资源/课本）
@@ -140,8 +160,14 @@ CREATE TABLE IF NOT EXISTS course_resources_upload (
     resource_upload VARCHAR(500) NOT NULL COMMENT '上传文件URL',
     sort_order INT DEFAULT 0 COMMENT '排序',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT COMMENT '驳回原因',
+    submitter_id INT COMMENT '提交用户ID',
     INDEX idx_course_id (course_id),
-    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE
+    INDEX idx_status (status),
+    FOREIGN KEY (course_id) REFERENCES courses(course_id) ON DELETE CASCADE,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='课程上传资源表';
 
 -- 课程贡献者表
@@ -173,8 +199,21 @@ CREATE TABLE IF NOT EXISTS projects (
     collections INT DEFAULT 0 COMMENT '收藏量',
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending' COMMENT '审核状态：pending/approved/rejected',
+    audit_time TIMESTAMP NULL COMMENT '审核时间',
+    reject_reason TEXT COMMENT '驳回原因',
+    submitter_id INT COMMENT '提交用户ID',
+    deleted_at TIMESTAMP NULL COMMENT '删除时间（软删除，进入回收站）',
+    deleted_by INT NULL COMMENT '删除操作用户ID',
+    version INT NOT NULL DEFAULT 1 COMMENT '乐观锁版本号，资源行修改、删除、恢复时加一',
     INDEX idx_category (category),
-    INDEX idx_name (name)
+    INDEX idx_name (name),
+    INDEX idx_status (status),
+    INDEX idx_submitter (submitter_id),
+    INDEX idx_deleted_at (deleted_at),
+    FULLTEXT INDEX ft_project_text (name, description, detail) WITH PARSER ngram,
+    FOREIGN KEY (submitter_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (deleted_by) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='项目表';
 
 -- 项目技术栈表
@@ -225,6 +264,7 @@ CREATE TABLE IF NOT EXISTS comments (
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '评论时间',
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
     deleted_at TIMESTAMP NULL COMMENT '删除时间（软删除）',
+    hidden_at TIMESTAMP NULL COMMENT '被举报或审核隐藏的时间',
     INDEX idx_resource (resource_type, resource_id),
     INDEX idx_user_id (user_id),
     INDEX idx_parent_id (parent_id),
@@ -245,6 +285,24 @@ CREATE TABLE IF NOT EXISTS comment_likes (
     UNIQUE KEY uk_comment_user (comment_id, user_id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='评论点赞表';
 
+-- 评论举报表（user_id 为空时是系统标记；处理后记录处理结果，同一评论可以再次被举报）
+CREATE TABLE IF NOT EXISTS comment_reports (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    comment_id INT NOT NULL COMMENT '评论ID',
+    user_id INT NULL COMMENT '举报用户ID',
+    reason VARCHAR(50) NOT NULL COMMENT '举报理由：spam/abuse/illegal/other',
+    detail TEXT COMMENT '补充说明',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP COMMENT '举报时间',
+    resolved_at TIMESTAMP NULL COMMENT '处理时间，为空表示待处理',
+    resolution VARCHAR(20) NULL COMMENT '处理结果：dismiss/hide/delete/warn',
+    resolved_by INT NULL COMMENT '处理的管理员ID',
+    INDEX idx_comment_resolved (comment_id, resolved_at),
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (comment_id) REFERENCES comments(comment_id) ON DELETE CASCADE,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (resolved_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='评论举报表';
+
 -- ==================== 用户行为表 ====================
 
 -- 收藏表
@@ -273,6 +331,15 @@ CREATE TABLE IF NOT EXISTS likes (
     UNIQUE KEY uk_user_resource (user_id, resource_type, resource_id)
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='点赞表';
 
+-- 每日浏览量表（浏览计数在内存中聚合后定期批量写入，同时累加到资源表的 views 列）
+CREATE TABLE IF NOT EXISTS resource_daily_views (
+    resource_type VARCHAR(50) NOT NULL COMMENT '资源类型：tool/course/project',
+    resource_id INT NOT NULL COMMENT '资源ID',
+    view_date DATE NOT NULL COMMENT '日期',
+    views INT NOT NULL DEFAULT 0 COMMENT '当天浏览量',
+    PRIMARY KEY (resource_type, resource_id, view_date)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='每日浏览量表';
+
 -- ==================== 审核/状态管理表 ====================
 
 -- 资源状态变更记录表
@@ -288,6 +355,88 @@ CREATE TABLE IF NOT EXISTS resource_status_logs (
     FOREIGN KEY (operator_id) REFERENCES users(id) ON DELETE SET NULL
 ) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='资源状态变更记录表';
 
+-- 审核认领与分配表（待审核资源轮流分配给管理员，管理员认领后在租期内独占审核）
+CREATE TABLE IF NOT EXISTS review_claims (
+    resource_type VARCHAR(50) NOT NULL COMMENT '资源类型',
+    resource_id INT NOT NULL COMMENT '资源ID',
+    assignee_id INT NULL COMMENT '分配给的管理员ID',
+    assign_seq INT NOT NULL DEFAULT 0 COMMENT '分配顺序号，用于轮流分配',
+    assigned_at TIMESTAMP NULL COMMENT '分配时间',
+    claimant_id INT NULL COMMENT '认领的管理员ID',
+    expires_at TIMESTAMP NULL COMMENT '认领到期时间',
+    PRIMARY KEY (resource_type, resource_id),
+    INDEX idx_assignee (assignee_id),
+    INDEX idx_claimant (claimant_id, expires_at),
+    FOREIGN KEY (assignee_id) REFERENCES users(id) ON DELETE SET NULL,
+    FOREIGN KEY (claimant_id) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='审核认领与分配表';
+
+-- ==================== 检索提醒/通知表 ====================
+
+-- 已保存的检索表（之后审核通过的资源满足条件时提醒用户）
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '用户ID',
+    name VARCHAR(100) NOT NULL COMMENT '名称',
+    query TEXT NOT NULL COMMENT '检索条件（JSON）',
+    channel VARCHAR(20) NOT NULL DEFAULT 'in_app' COMMENT '提醒方式：in_app/email',
+    checked_at TIMESTAMP NULL COMMENT '已检查到的审核时间',
+    last_notified_at TIMESTAMP NULL COMMENT '最近一次提醒时间',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_id (user_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='已保存的检索表';
+
+-- 站内通知表
+CREATE TABLE IF NOT EXISTS notifications (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    user_id INT NOT NULL COMMENT '接收用户ID',
+    kind VARCHAR(50) NOT NULL COMMENT '通知类型：saved_search/comment_moderation',
+    title VARCHAR(255) NOT NULL COMMENT '标题',
+    content TEXT COMMENT '内容',
+    channel VARCHAR(20) NOT NULL DEFAULT 'in_app' COMMENT '送达方式：in_app/email',
+    resource_type VARCHAR(50) COMMENT '涉及的资源类型',
+    resource_id INT COMMENT '涉及的资源ID',
+    ref_id INT COMMENT '关联对象ID，如已保存检索的ID',
+    is_read BOOLEAN NOT NULL DEFAULT FALSE COMMENT '是否已读',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_user_created (user_id, created_at),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='站内通知表';
+
+-- ==================== 内容安全表 ====================
+
+-- 敏感词表（评论和提交的内容按词表过滤，修改后无需重启即可生效）
+CREATE TABLE IF NOT EXISTS sensitive_words (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    word VARCHAR(100) NOT NULL COMMENT '敏感词',
+    level VARCHAR(20) NOT NULL COMMENT '等级：mild 送审核/middle 替换为*/severe 拒绝',
+    created_by INT NULL COMMENT '添加的管理员ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_word (word),
+    FOREIGN KEY (created_by) REFERENCES users(id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='敏感词表';
+
+-- ==================== 审计日志表 ====================
+
+-- 管理员操作的审计日志，只追加不修改；actor_id 不设外键，用户删除后日志保持原样
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id INT AUTO_INCREMENT PRIMARY KEY,
+    actor_id INT NULL COMMENT '操作的管理员ID',
+    actor_name VARCHAR(255) NOT NULL DEFAULT '' COMMENT '操作时的用户名',
+    action VARCHAR(50) NOT NULL COMMENT '操作，如 review.approve、resource.delete、sensitive_word.save',
+    target_type VARCHAR(50) NOT NULL COMMENT '目标类型',
+    target_id INT NULL COMMENT '目标ID',
+    before_state TEXT COMMENT '操作前的快照（JSON）',
+    after_state TEXT COMMENT '操作后的快照（JSON）',
+    ip VARCHAR(64) NOT NULL DEFAULT '' COMMENT '请求来源IP',
+    request_id VARCHAR(64) NOT NULL DEFAULT '' COMMENT '请求ID',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_created (created_at),
+    INDEX idx_actor_created (actor_id, created_at),
+    INDEX idx_target (target_type, target_id, created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='管理员审计日志表';
+
 -- ==================== 初始化数据 ====================
 
 -- 插入一个管理员用户（密码需要在使用时设置）
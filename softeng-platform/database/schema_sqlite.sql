@@ -0,0 +1,447 @@
+-- 软件工程平台数据库表结构（SQLite 版本）
+-- 与 schema.sql 保持一致，修改表结构时两份文件需要同步更新
+-- 差异：自增主键使用 INTEGER PRIMARY KEY AUTOINCREMENT；索引单独创建；
+-- ON UPDATE CURRENT_TIMESTAMP 由仓库层写入 updated_at；全文索引使用 FTS5 虚拟表并由触发器同步
+
+-- ==================== 用户相关表 ====================
+
+-- 用户表
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    username VARCHAR(255) NOT NULL UNIQUE,
+    nickname VARCHAR(255),
+    email VARCHAR(255) NOT NULL UNIQUE,
+    password VARCHAR(255) NOT NULL,
+    avatar VARCHAR(500),
+    description TEXT,
+    face_photo VARCHAR(500),
+    role VARCHAR(50) DEFAULT 'user',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ==================== 工具相关表 ====================
+
+-- 工具表
+CREATE TABLE IF NOT EXISTS tools (
+    resource_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_type VARCHAR(50) DEFAULT 'tool',
+    resource_name VARCHAR(255) NOT NULL,
+    resource_link VARCHAR(500),
+    description VARCHAR(500),
+    description_detail TEXT,
+    category VARCHAR(100),
+    views INT DEFAULT 0,
+    collections INT DEFAULT 0,
+    loves INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending',
+    audit_time TIMESTAMP NULL,
+    reject_reason TEXT,
+    submitter_id INT REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMP NULL,
+    deleted_by INT REFERENCES users(id) ON DELETE SET NULL,
+    version INT NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_tools_category ON tools (category);
+CREATE INDEX IF NOT EXISTS idx_tools_status ON tools (status);
+CREATE INDEX IF NOT EXISTS idx_tools_submitter ON tools (submitter_id);
+CREATE INDEX IF NOT EXISTS idx_tools_deleted_at ON tools (deleted_at);
+
+-- 工具图片表
+CREATE TABLE IF NOT EXISTS tool_images (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tool_id INT NOT NULL REFERENCES tools(resource_id) ON DELETE CASCADE,
+    image_url VARCHAR(500) NOT NULL,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_tool_images_tool_id ON tool_images (tool_id);
+
+-- 工具标签表
+CREATE TABLE IF NOT EXISTS tool_tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tool_id INT NOT NULL REFERENCES tools(resource_id) ON DELETE CASCADE,
+    tag VARCHAR(50) NOT NULL,
+    UNIQUE (tool_id, tag)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_tags_tag ON tool_tags (tag);
+
+-- 工具贡献者表
+CREATE TABLE IF NOT EXISTS tool_contributors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    tool_id INT NOT NULL REFERENCES tools(resource_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (tool_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_tool_contributors_user_id ON tool_contributors (user_id);
+
+-- ==================== 课程相关表 ====================
+
+-- 课程表
+CREATE TABLE IF NOT EXISTS courses (
+    course_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_type VARCHAR(50) DEFAULT 'course',
+    name VARCHAR(255) NOT NULL,
+    semester VARCHAR(50),
+    credit INT,
+    cover VARCHAR(500),
+    views INT DEFAULT 0,
+    loves INT DEFAULT 0,
+    collections INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    deleted_by INT REFERENCES users(id) ON DELETE SET NULL,
+    version INT NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_courses_semester ON courses (semester);
+CREATE INDEX IF NOT EXISTS idx_courses_name ON courses (name);
+CREATE INDEX IF NOT EXISTS idx_courses_deleted_at ON courses (deleted_at);
+
+-- 课程教师表
+CREATE TABLE IF NOT EXISTS course_teachers (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_id INT NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    teacher_name VARCHAR(100) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_course_teachers_course_id ON course_teachers (course_id);
+
+-- 课程分类表
+CREATE TABLE IF NOT EXISTS course_categories (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_id INT NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    category VARCHAR(100) NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_course_categories_course_id ON course_categories (course_id);
+
+-- 课程资源表（URL资源）
+CREATE TABLE IF NOT EXISTS course_resources_web (
+    resource_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_id INT NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    resource_intro VARCHAR(255) NOT NULL,
+    resource_url VARCHAR(500) NOT NULL,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending',
+    audit_time TIMESTAMP NULL,
+    reject_reason TEXT,
+    submitter_id INT REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_course_resources_web_course_id ON course_resources_web (course_id);
+CREATE INDEX IF NOT EXISTS idx_course_resources_web_status ON course_resources_web (status);
+
+-- 课程资源表（上传资源/课本）
+CREATE TABLE IF NOT EXISTS course_resources_upload (
+    resource_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_id INT NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    resource_intro VARCHAR(255) NOT NULL,
+    resource_upload VARCHAR(500) NOT NULL,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending',
+    audit_time TIMESTAMP NULL,
+    reject_reason TEXT,
+    submitter_id INT REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_course_resources_upload_course_id ON course_resources_upload (course_id);
+CREATE INDEX IF NOT EXISTS idx_course_resources_upload_status ON course_resources_upload (status);
+
+-- 课程贡献者表
+CREATE TABLE IF NOT EXISTS course_contributors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    course_id INT NOT NULL REFERENCES courses(course_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (course_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_course_contributors_user_id ON course_contributors (user_id);
+
+-- ==================== 项目相关表 ====================
+
+-- 项目表
+CREATE TABLE IF NOT EXISTS projects (
+    project_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_type VARCHAR(50) DEFAULT 'project',
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description VARCHAR(500),
+    detail TEXT,
+    github_url VARCHAR(500),
+    category VARCHAR(100),
+    cover VARCHAR(500),
+    views INT DEFAULT 0,
+    loves INT DEFAULT 0,
+    collections INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    status VARCHAR(50) DEFAULT 'pending',
+    audit_time TIMESTAMP NULL,
+    reject_reason TEXT,
+    submitter_id INT REFERENCES users(id) ON DELETE SET NULL,
+    deleted_at TIMESTAMP NULL,
+    deleted_by INT REFERENCES users(id) ON DELETE SET NULL,
+    version INT NOT NULL DEFAULT 1
+);
+CREATE INDEX IF NOT EXISTS idx_projects_category ON projects (category);
+CREATE INDEX IF NOT EXISTS idx_projects_status ON projects (status);
+CREATE INDEX IF NOT EXISTS idx_projects_submitter ON projects (submitter_id);
+CREATE INDEX IF NOT EXISTS idx_projects_deleted_at ON projects (deleted_at);
+
+-- 项目技术栈表
+CREATE TABLE IF NOT EXISTS project_tech_stack (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INT NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    tech VARCHAR(50) NOT NULL,
+    UNIQUE (project_id, tech)
+);
+
+-- 项目图片表
+CREATE TABLE IF NOT EXISTS project_images (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INT NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    image_url VARCHAR(500) NOT NULL,
+    sort_order INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_project_images_project_id ON project_images (project_id);
+
+-- 项目作者表
+CREATE TABLE IF NOT EXISTS project_authors (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    project_id INT NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (project_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_project_authors_user_id ON project_authors (user_id);
+
+-- ==================== 评论相关表 ====================
+
+-- 评论表（通用，用于工具/课程/项目）
+CREATE TABLE IF NOT EXISTS comments (
+    comment_id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INT NOT NULL,
+    parent_id INT NULL REFERENCES comments(comment_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    love_count INT DEFAULT 0,
+    reply_total INT DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    deleted_at TIMESTAMP NULL,
+    hidden_at TIMESTAMP NULL
+);
+CREATE INDEX IF NOT EXISTS idx_comments_resource ON comments (resource_type, resource_id);
+CREATE INDEX IF NOT EXISTS idx_comments_user_id ON comments (user_id);
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments (parent_id);
+
+-- 评论点赞表
+CREATE TABLE IF NOT EXISTS comment_likes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    comment_id INT NOT NULL REFERENCES comments(comment_id) ON DELETE CASCADE,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (comment_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_comment_likes_user_id ON comment_likes (user_id);
+
+-- 评论举报表
+CREATE TABLE IF NOT EXISTS comment_reports (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    comment_id INT NOT NULL REFERENCES comments(comment_id) ON DELETE CASCADE,
+    user_id INT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason VARCHAR(50) NOT NULL,
+    detail TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMP NULL,
+    resolution VARCHAR(20) NULL,
+    resolved_by INT NULL REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX IF NOT EXISTS idx_comment_reports_comment ON comment_reports (comment_id, resolved_at);
+CREATE INDEX IF NOT EXISTS idx_comment_reports_user_id ON comment_reports (user_id);
+
+-- ==================== 用户行为表 ====================
+
+-- 收藏表
+CREATE TABLE IF NOT EXISTS collections (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, resource_type, resource_id)
+);
+CREATE INDEX IF NOT EXISTS idx_collections_resource ON collections (resource_type, resource_id);
+
+-- 点赞表
+CREATE TABLE IF NOT EXISTS likes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (user_id, resource_type, resource_id)
+);
+CREATE INDEX IF NOT EXISTS idx_likes_resource ON likes (resource_type, resource_id);
+
+-- 每日浏览量表
+CREATE TABLE IF NOT EXISTS resource_daily_views (
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INT NOT NULL,
+    view_date DATE NOT NULL,
+    views INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (resource_type, resource_id, view_date)
+);
+
+-- ==================== 审核/状态管理表 ====================
+
+-- 资源状态变更记录表
+CREATE TABLE IF NOT EXISTS resource_status_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INT NOT NULL,
+    old_status VARCHAR(50),
+    new_status VARCHAR(50) NOT NULL,
+    operator_id INT REFERENCES users(id) ON DELETE SET NULL,
+    operate_time TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_resource_status_logs_resource ON resource_status_logs (resource_type, resource_id);
+
+-- 审核认领与分配表，每个审核目标一行；认领到 expires_at 为止
+CREATE TABLE IF NOT EXISTS review_claims (
+    resource_type VARCHAR(50) NOT NULL,
+    resource_id INT NOT NULL,
+    assignee_id INT NULL REFERENCES users(id) ON DELETE SET NULL,
+    assign_seq INT NOT NULL DEFAULT 0,
+    assigned_at TIMESTAMP NULL,
+    claimant_id INT NULL REFERENCES users(id) ON DELETE SET NULL,
+    expires_at TIMESTAMP NULL,
+    PRIMARY KEY (resource_type, resource_id)
+);
+CREATE INDEX IF NOT EXISTS idx_review_claims_assignee ON review_claims (assignee_id);
+CREATE INDEX IF NOT EXISTS idx_review_claims_claimant ON review_claims (claimant_id, expires_at);
+
+-- ==================== 检索提醒/通知表 ====================
+
+-- 已保存的检索表
+CREATE TABLE IF NOT EXISTS saved_searches (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    query TEXT NOT NULL,
+    channel VARCHAR(20) NOT NULL DEFAULT 'in_app',
+    checked_at TIMESTAMP NULL,
+    last_notified_at TIMESTAMP NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_saved_searches_user_id ON saved_searches (user_id);
+
+-- 站内通知表
+CREATE TABLE IF NOT EXISTS notifications (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    content TEXT,
+    channel VARCHAR(20) NOT NULL DEFAULT 'in_app',
+    resource_type VARCHAR(50),
+    resource_id INT,
+    ref_id INT,
+    is_read BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_notifications_user_created ON notifications (user_id, created_at);
+
+-- ==================== 内容安全表 ====================
+
+-- 敏感词表
+CREATE TABLE IF NOT EXISTS sensitive_words (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    word VARCHAR(100) NOT NULL UNIQUE,
+    level VARCHAR(20) NOT NULL,
+    created_by INT NULL REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- ==================== 审计日志表 ====================
+
+-- 管理员审计日志表
+CREATE TABLE IF NOT EXISTS admin_audit_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    actor_id INT NULL,
+    actor_name VARCHAR(255) NOT NULL DEFAULT '',
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(50) NOT NULL,
+    target_id INT NULL,
+    before_state TEXT,
+    after_state TEXT,
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_created ON admin_audit_logs (created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_actor ON admin_audit_logs (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_admin_audit_logs_target ON admin_audit_logs (target_type, target_id, created_at);
+
+-- ==================== 全文索引 ====================
+-- trigram 分词按三个字符切分，中文和英文都可以按子串检索；短于三个字符的检索词由程序改用 LIKE 匹配。
+-- 旧版本使用默认分词器创建的索引在连接时自动重建
+
+-- 工具全文索引
+CREATE VIRTUAL TABLE IF NOT EXISTS tools_fts USING fts5(
+    resource_name, description, description_detail,
+    content='tools', content_rowid='resource_id',
+    tokenize='trigram'
+);
+CREATE TRIGGER IF NOT EXISTS tools_fts_insert AFTER INSERT ON tools BEGIN
+    INSERT INTO tools_fts (rowid, resource_name, description, description_detail)
+    VALUES (new.resource_id, new.resource_name, new.description, new.description_detail);
+END;
+CREATE TRIGGER IF NOT EXISTS tools_fts_delete AFTER DELETE ON tools BEGIN
+    INSERT INTO tools_fts (tools_fts, rowid, resource_name, description, description_detail)
+    VALUES ('delete', old.resource_id, old.resource_name, old.description, old.description_detail);
+END;
+CREATE TRIGGER IF NOT EXISTS tools_fts_update AFTER UPDATE OF resource_name, description, description_detail ON tools BEGIN
+    INSERT INTO tools_fts (tools_fts, rowid, resource_name, description, description_detail)
+    VALUES ('delete', old.resource_id, old.resource_name, old.description, old.description_detail);
+    INSERT INTO tools_fts (rowid, resource_name, description, description_detail)
+    VALUES (new.resource_id, new.resource_name, new.description, new.description_detail);
+END;
+
+-- 课程全文索引
+CREATE VIRTUAL TABLE IF NOT EXISTS courses_fts USING fts5(
+    name,
+    content='courses', content_rowid='course_id',
+    tokenize='trigram'
+);
+CREATE TRIGGER IF NOT EXISTS courses_fts_insert AFTER INSERT ON courses BEGIN
+    INSERT INTO courses_fts (rowid, name) VALUES (new.course_id, new.name);
+END;
+CREATE TRIGGER IF NOT EXISTS courses_fts_delete AFTER DELETE ON courses BEGIN
+    INSERT INTO courses_fts (courses_fts, rowid, name) VALUES ('delete', old.course_id, old.name);
+END;
+CREATE TRIGGER IF NOT EXISTS courses_fts_update AFTER UPDATE OF name ON courses BEGIN
+    INSERT INTO courses_fts (courses_fts, rowid, name) VALUES ('delete', old.course_id, old.name);
+    INSERT INTO courses_fts (rowid, name) VALUES (new.course_id, new.name);
+END;
+
+-- 项目全文索引
+CREATE VIRTUAL TABLE IF NOT EXISTS projects_fts USING fts5(
+    name, description, detail,
+    content='projects', content_rowid='project_id',
+    tokenize='trigram'
+);
+CREATE TRIGGER IF NOT EXISTS projects_fts_insert AFTER INSERT ON projects BEGIN
+    INSERT INTO projects_fts (rowid, name, description, detail)
+    VALUES (new.project_id, new.name, new.description, new.detail);
+END;
+CREATE TRIGGER IF NOT EXISTS projects_fts_delete AFTER DELETE ON projects BEGIN
+    INSERT INTO projects_fts (projects_fts, rowid, name, description, detail)
+    VALUES ('delete', old.project_id, old.name, old.description, old.detail);
+END;
+CREATE TRIGGER IF NOT EXISTS projects_fts_update AFTER UPDATE OF name, description, detail ON projects BEGIN
+    INSERT INTO projects_fts (projects_fts, rowid, name, description, detail)
+    VALUES ('delete', old.project_id, old.name, old.description, old.detail);
+    INSERT INTO projects_fts (rowid, name, description, detail)
+    VALUES (new.project_id, new.name, new.description, new.detail);
+END;
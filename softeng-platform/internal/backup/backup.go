@@ -0,0 +1,262 @@
+// Package backup 把平台的全部数据导出为可移植的 JSON Lines 归档，并可导入到另一个空数据库。
+// 归档与数据库后端无关，保留原有的ID，可用于备份和在服务器、数据库之间迁移。
+//
+// 归档每行一条记录，依次为：
+//
+//	{"type":"header","format":"softeng-platform-backup","version":1,...}
+//	{"type":"table","table":"users","columns":["id","username",...]}
+//	{"type":"row","values":[1,"alice",...]}   该表的每一行
+//	...                                        其余各表
+//	{"type":"file","path":"a/b.pdf","data":"<base64>"}   可选的上传文件，大文件分为多条
+//	{"type":"end","rows":123,"files":4}
+package backup
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"softeng-platform/internal/repository"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Format 归档头中的格式名
+	Format = "softeng-platform-backup"
+	// Version 当前的归档版本，导入时拒绝更新版本的归档
+	Version = 1
+
+	// fileChunkSize 上传文件按此大小拆分为多条记录
+	fileChunkSize = 1 << 20
+)
+
+// table 一张需要备份的表，orderBy 为导出顺序
+type table struct {
+	name    string
+	orderBy string
+}
+
+// tables 平台的全部表，按外键依赖排序，导入时按此顺序写入。
+// 评论按ID导出，父评论总在回复之前。新增表时需要加入这里
+var tables = []table{
+	{"users", "id"},
+	{"tools", "resource_id"},
+	{"tool_images", "id"},
+	{"tool_tags", "id"},
+	{"tool_contributors", "id"},
+	{"courses", "course_id"},
+	{"course_teachers", "id"},
+	{"course_categories", "id"},
+	{"course_resources_web", "resource_id"},
+	{"course_resources_upload", "resource_id"},
+	{"course_contributors", "id"},
+	{"projects", "project_id"},
+	{"project_tech_stack", "id"},
+	{"project_images", "id"},
+	{"project_authors", "id"},
+	{"comments", "comment_id"},
+	{"comment_likes", "id"},
+	{"comment_reports", "id"},
+	{"collections", "id"},
+	{"likes", "id"},
+	{"resource_daily_views", "resource_type, resource_id, view_date"},
+	{"resource_status_logs", "id"},
+	{"review_claims", "resource_type, resource_id"},
+	{"saved_searches", "id"},
+	{"notifications", "id"},
+	{"sensitive_words", "id"},
+	{"admin_audit_logs", "id"},
+}
+
+func lookupTable(name string) (table, bool) {
+	for _, t := range tables {
+		if t.name == name {
+			return t, true
+		}
+	}
+	return table{}, false
+}
+
+// record 归档中的一行，type 决定其余哪些字段有效
+type record struct {
+	Type string `json:"type"`
+
+	// header
+	Format    string     `json:"format,omitempty"`
+	Version   int        `json:"version,omitempty"`
+	Driver    string     `json:"driver,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+
+	// table
+	Table   string   `json:"table,omitempty"`
+	Columns []string `json:"columns,omitempty"`
+
+	// row
+	Values []interface{} `json:"values,omitempty"`
+
+	// file
+	Path string `json:"path,omitempty"`
+	Data []byte `json:"data,omitempty"`
+
+	// end
+	Rows  int `json:"rows,omitempty"`
+	Files int `json:"files,omitempty"`
+}
+
+const (
+	recordHeader = "header"
+	recordTable  = "table"
+	recordRow    = "row"
+	recordFile   = "file"
+	recordEnd    = "end"
+)
+
+// Stats 导出或导入的统计
+type Stats struct {
+	Tables map[string]int `json:"tables"` // 表名 -> 行数
+	Rows   int            `json:"rows"`
+	Files  int            `json:"files"`
+}
+
+func newStats() *Stats {
+	return &Stats{Tables: make(map[string]int)}
+}
+
+// kind 列的值在归档中的表示方式，由数据库声明的列类型决定
+type kind int
+
+const (
+	kindText kind = iota
+	kindInt
+	kindFloat
+	kindTime // 带时区的 RFC 3339 文本
+	kindDate // 2006-01-02
+)
+
+func columnKind(databaseType string) kind {
+	t := strings.ToUpper(databaseType)
+	switch {
+	case t == "DATE":
+		return kindDate
+	case strings.Contains(t, "TIME"):
+		return kindTime
+	case strings.Contains(t, "INT") || strings.Contains(t, "BOOL"):
+		return kindInt
+	case strings.Contains(t, "FLOAT") || strings.Contains(t, "DOUBLE") || strings.Contains(t, "REAL") ||
+		strings.Contains(t, "DECIMAL") || strings.Contains(t, "NUMERIC"):
+		return kindFloat
+	default:
+		return kindText
+	}
+}
+
+// columnKinds 返回 table 中各列的类型
+func columnKinds(columnTypes []*sql.ColumnType) map[string]kind {
+	kinds := make(map[string]kind, len(columnTypes))
+	for _, ct := range columnTypes {
+		kinds[ct.Name()] = columnKind(ct.DatabaseTypeName())
+	}
+	return kinds
+}
+
+// timeLayouts 导入时依次尝试的时间格式，后两种为驱动未解析时间时的原始文本
+var timeLayouts = []string{time.RFC3339Nano, "2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05"}
+
+// encodeValue 把驱动扫描出的值转换为归档中的 JSON 值。
+// MySQL 的文本协议以 []byte 返回数字，按列类型还原
+func encodeValue(k kind, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return parseText(k, string(v))
+	case string:
+		return parseText(k, v)
+	case time.Time:
+		if k == kindDate {
+			return v.Format("2006-01-02"), nil
+		}
+		return v.UTC().Format(time.RFC3339Nano), nil
+	default:
+		return v, nil
+	}
+}
+
+func parseText(k kind, s string) (interface{}, error) {
+	switch k {
+	case kindInt:
+		return strconv.ParseInt(s, 10, 64)
+	case kindFloat:
+		return strconv.ParseFloat(s, 64)
+	case kindDate:
+		if len(s) < len("2006-01-02") {
+			return nil, fmt.Errorf("invalid date %q", s)
+		}
+		return s[:len("2006-01-02")], nil
+	case kindTime:
+		t, err := parseTime(s)
+		if err != nil {
+			return nil, err
+		}
+		return t.UTC().Format(time.RFC3339Nano), nil
+	default:
+		return s, nil
+	}
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// decodeValue 把归档中的 JSON 值转换为写入目标列的参数
+func decodeValue(k kind, v interface{}) (interface{}, error) {
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+	case json.Number:
+		switch k {
+		case kindInt:
+			return v.Int64()
+		case kindFloat:
+			return v.Float64()
+		default:
+			return v.String(), nil
+		}
+	case bool:
+		if k == kindInt || k == kindFloat {
+			if v {
+				return 1, nil
+			}
+			return 0, nil
+		}
+		return strconv.FormatBool(v), nil
+	case string:
+		switch k {
+		case kindTime:
+			return parseTime(v)
+		case kindText:
+			return v, nil
+		default:
+			return parseText(k, v)
+		}
+	default:
+		return nil, fmt.Errorf("unexpected value %v", v)
+	}
+}
+
+// emptyColumns 查询表的列而不读取数据
+func emptyColumns(ctx context.Context, db *repository.Database, name string) ([]*sql.ColumnType, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read columns of %s: %w", name, err)
+	}
+	defer rows.Close()
+	return rows.ColumnTypes()
+}
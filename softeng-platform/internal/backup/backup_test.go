@@ -0,0 +1,161 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"regexp"
+	"slices"
+	"softeng-platform/database"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/seed"
+	"strings"
+	"testing"
+	"time"
+)
+
+// sqliteMemoryDSN 每次打开都是一个全新的空库（连接池只保留一个连接）
+const sqliteMemoryDSN = "file::memory:?_pragma=foreign_keys(1)&_time_format=sqlite"
+
+// extraRows 随机数据没有覆盖到的表各写入一行，保证每张表都经过导出和导入
+var extraRows = []string{
+	`INSERT INTO tool_images (tool_id, image_url) VALUES ((SELECT MIN(resource_id) FROM tools), 'https://example.com/a.png')`,
+	`INSERT INTO course_resources_upload (course_id, resource_intro, resource_upload, status, audit_time, submitter_id)
+		VALUES ((SELECT MIN(course_id) FROM courses), '课本', '/uploads/book.pdf', 'approved', ?, (SELECT MIN(id) FROM users))`,
+	`INSERT INTO project_images (project_id, image_url) VALUES ((SELECT MIN(project_id) FROM projects), 'https://example.com/p.png')`,
+	`INSERT INTO comment_likes (comment_id, user_id) VALUES ((SELECT MIN(comment_id) FROM comments), (SELECT MIN(id) FROM users))`,
+	`INSERT INTO comment_reports (comment_id, user_id, reason, detail, resolved_at, resolution, resolved_by)
+		VALUES ((SELECT MIN(comment_id) FROM comments), (SELECT MAX(id) FROM users), 'spam', '广告', ?, 'dismiss', (SELECT MIN(id) FROM users))`,
+	`INSERT INTO resource_daily_views (resource_type, resource_id, view_date, views) VALUES ('tool', (SELECT MIN(resource_id) FROM tools), '2024-05-01', 7)`,
+	`INSERT INTO resource_status_logs (resource_type, resource_id, old_status, new_status, operator_id)
+		VALUES ('tool', (SELECT MIN(resource_id) FROM tools), 'pending', 'approved', (SELECT MIN(id) FROM users))`,
+	`INSERT INTO review_claims (resource_type, resource_id, assignee_id, assign_seq, assigned_at, claimant_id, expires_at)
+		VALUES ('tool', (SELECT MAX(resource_id) FROM tools), (SELECT MIN(id) FROM users), 1, ?, (SELECT MIN(id) FROM users), ?)`,
+	`INSERT INTO saved_searches (user_id, name, query, checked_at) VALUES ((SELECT MIN(id) FROM users), 'Go', '{"keyword":"go"}', ?)`,
+	`INSERT INTO notifications (user_id, kind, title, content, resource_type, resource_id, is_read)
+		VALUES ((SELECT MIN(id) FROM users), 'saved_search', '新资源', '内容', 'tool', (SELECT MIN(resource_id) FROM tools), 1)`,
+	`INSERT INTO sensitive_words (word, level, created_by) VALUES ('广告', 'mild', (SELECT MIN(id) FROM users))`,
+	`INSERT INTO admin_audit_logs (actor_id, actor_name, action, target_type, target_id, before_state, after_state, ip, request_id)
+		VALUES ((SELECT MIN(id) FROM users), 'admin', 'review.approve', 'tool', 1, '{"auditStatus":"pending"}', '{"auditStatus":"approved"}', '127.0.0.1', 'r1')`,
+}
+
+func openDB(t *testing.T) *repository.Database {
+	t.Helper()
+	db, err := repository.NewDatabase("sqlite", sqliteMemoryDSN, repository.DefaultPoolConfig)
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// keys 按导出顺序返回表中每一行的主键，用于比较导入后ID是否保留
+func keys(t *testing.T, db *repository.Database, tb table) []string {
+	t.Helper()
+	columns := strings.ReplaceAll(tb.orderBy, ", ", " || '/' || ")
+	rows, err := db.QueryContext(context.Background(), "SELECT "+columns+" FROM "+tb.name+" ORDER BY "+tb.orderBy)
+	if err != nil {
+		t.Fatalf("query %s: %v", tb.name, err)
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var key string
+		if err := rows.Scan(&key); err != nil {
+			t.Fatalf("scan %s: %v", tb.name, err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatalf("read %s: %v", tb.name, err)
+	}
+	return keys
+}
+
+func TestRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	src := openDB(t)
+
+	fixtures, err := seed.Generate(seed.Options{Users: 4, Tools: 4, Courses: 2, Projects: 2, Interactions: 3, Seed: 1})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	if _, err := seed.NewSeeder(src).Apply(ctx, fixtures); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	now := time.Now()
+	for _, query := range extraRows {
+		args := make([]interface{}, strings.Count(query, "?"))
+		for i := range args {
+			args[i] = now
+		}
+		if _, err := src.ExecContext(ctx, query, args...); err != nil {
+			t.Fatalf("insert extra row: %v\n%s", err, query)
+		}
+	}
+
+	want := make(map[string][]string, len(tables))
+	for _, tb := range tables {
+		if want[tb.name] = keys(t, src, tb); len(want[tb.name]) == 0 {
+			t.Errorf("%s is empty before backup, add a row to extraRows", tb.name)
+		}
+	}
+
+	var archive bytes.Buffer
+	exported, err := Export(ctx, src, &archive, "")
+	if err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	dst := openDB(t)
+	restored, err := Restore(ctx, dst, bytes.NewReader(archive.Bytes()), "")
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if exported.Rows != restored.Rows {
+		t.Errorf("rows: exported %d, restored %d", exported.Rows, restored.Rows)
+	}
+
+	for _, tb := range tables {
+		got := keys(t, dst, tb)
+		if len(got) != len(want[tb.name]) || exported.Tables[tb.name] != len(got) || restored.Tables[tb.name] != len(got) {
+			t.Errorf("%s: source %d rows, exported %d, restored %d, target %d",
+				tb.name, len(want[tb.name]), exported.Tables[tb.name], restored.Tables[tb.name], len(got))
+			continue
+		}
+		if !slices.Equal(got, want[tb.name]) {
+			t.Errorf("%s: keys changed after restore: got %v, want %v", tb.name, got, want[tb.name])
+		}
+	}
+
+	// 导入后数据库不再为空，再次导入被拒绝
+	if _, err := Restore(ctx, dst, bytes.NewReader(archive.Bytes()), ""); !errors.Is(err, ErrNotEmpty) {
+		t.Errorf("Restore into non-empty database: got %v, want ErrNotEmpty", err)
+	}
+}
+
+// TestTablesCoverSchema 表结构中的每张表都必须加入 tables，否则备份会静默丢掉这张表
+func TestTablesCoverSchema(t *testing.T) {
+	mysqlSchema, err := os.ReadFile("../../database/schema.sql")
+	if err != nil {
+		t.Fatalf("read schema.sql: %v", err)
+	}
+	createTable := regexp.MustCompile(`(?m)^CREATE TABLE IF NOT EXISTS (\w+)`)
+
+	for file, schema := range map[string]string{"schema.sql": string(mysqlSchema), "schema_sqlite.sql": database.SQLiteSchema} {
+		var names []string
+		for _, m := range createTable.FindAllStringSubmatch(schema, -1) {
+			names = append(names, m[1])
+			if _, ok := lookupTable(m[1]); !ok {
+				t.Errorf("%s: table %s is missing from backup tables", file, m[1])
+			}
+		}
+		for _, tb := range tables {
+			if !slices.Contains(names, tb.name) {
+				t.Errorf("%s: backup table %s is not in the schema", file, tb.name)
+			}
+		}
+	}
+}
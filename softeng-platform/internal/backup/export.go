@@ -0,0 +1,163 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"softeng-platform/internal/repository"
+	"time"
+)
+
+type exporter struct {
+	db    *repository.Database
+	enc   *json.Encoder
+	stats *Stats
+}
+
+// Export 在一个只读的一致性快照中导出全部表，边读边写，不在内存中保留整表数据。
+// filesDir 不为空时在表之后导出该目录下的上传文件，文件不在快照范围内
+func Export(ctx context.Context, db *repository.Database, w io.Writer, filesDir string) (*Stats, error) {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+	e := &exporter{db: db, enc: enc, stats: newStats()}
+
+	now := time.Now().UTC()
+	if err := e.write(record{Type: recordHeader, Format: Format, Version: Version, Driver: db.Dialect.Name(), CreatedAt: &now}); err != nil {
+		return nil, err
+	}
+
+	err := db.ReadSnapshot(ctx, func(ctx context.Context) error {
+		for _, t := range tables {
+			if err := e.table(ctx, t); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if filesDir != "" {
+		if err := e.files(filesDir); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := e.write(record{Type: recordEnd, Rows: e.stats.Rows, Files: e.stats.Files}); err != nil {
+		return nil, err
+	}
+	if err := bw.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write archive: %w", err)
+	}
+	return e.stats, nil
+}
+
+func (e *exporter) write(r record) error {
+	if err := e.enc.Encode(r); err != nil {
+		return fmt.Errorf("failed to write archive: %w", err)
+	}
+	return nil
+}
+
+func (e *exporter) table(ctx context.Context, t table) error {
+	rows, err := e.db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s ORDER BY %s", t.name, t.orderBy))
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", t.name, err)
+	}
+	defer rows.Close()
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return fmt.Errorf("failed to read columns of %s: %w", t.name, err)
+	}
+	columns := make([]string, len(columnTypes))
+	kinds := make([]kind, len(columnTypes))
+	for i, ct := range columnTypes {
+		columns[i] = ct.Name()
+		kinds[i] = columnKind(ct.DatabaseTypeName())
+	}
+	if err := e.write(record{Type: recordTable, Table: t.name, Columns: columns}); err != nil {
+		return err
+	}
+
+	values := make([]interface{}, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	e.stats.Tables[t.name] = 0
+	for rows.Next() {
+		if err := rows.Scan(dest...); err != nil {
+			return fmt.Errorf("failed to scan %s: %w", t.name, err)
+		}
+		out := make([]interface{}, len(values))
+		for i, v := range values {
+			if out[i], err = encodeValue(kinds[i], v); err != nil {
+				return fmt.Errorf("%s.%s: %w", t.name, columns[i], err)
+			}
+		}
+		if err := e.write(record{Type: recordRow, Values: out}); err != nil {
+			return err
+		}
+		e.stats.Tables[t.name]++
+		e.stats.Rows++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to read %s: %w", t.name, err)
+	}
+	return nil
+}
+
+// files 按相对路径导出 dir 下的全部普通文件
+func (e *exporter) files(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if err := e.file(path, filepath.ToSlash(rel)); err != nil {
+			return fmt.Errorf("failed to export %s: %w", path, err)
+		}
+		e.stats.Files++
+		return nil
+	})
+}
+
+// file 把一个文件按 fileChunkSize 拆分为连续的多条记录，空文件也写一条
+func (e *exporter) file(path, name string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	buf := make([]byte, fileChunkSize)
+	for first := true; ; first = false {
+		n, err := io.ReadFull(f, buf)
+		if n > 0 || first {
+			if err := e.write(record{Type: recordFile, Path: name, Data: buf[:n]}); err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
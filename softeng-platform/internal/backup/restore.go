@@ -0,0 +1,249 @@
+package backup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"softeng-platform/internal/repository"
+	"strings"
+)
+
+// ErrNotEmpty 目标数据库中已有数据
+var ErrNotEmpty = errors.New("target database is not empty")
+
+// maxInsertParams 一条多行 INSERT 语句中参数的上限
+const maxInsertParams = 1000
+
+type restorer struct {
+	db       *repository.Database
+	dec      *json.Decoder
+	filesDir string
+	stats    *Stats
+
+	// 当前正在写入的表
+	table   string
+	columns []string
+	kinds   []kind
+	batch   []interface{}
+	rows    int
+
+	// 当前正在写入的文件
+	path      string
+	file      *os.File
+	fileCount int
+}
+
+// Restore 把归档导入空数据库，保留原有的ID。表结构需要事先创建（SQLite 连接时自动创建）。
+// 全部数据在一个事务中写入，归档损坏或不完整时整体回滚；
+// filesDir 不为空时把归档中的上传文件写入该目录，文件不随事务回滚
+func Restore(ctx context.Context, db *repository.Database, r io.Reader, filesDir string) (*Stats, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	var header record
+	if err := dec.Decode(&header); err != nil {
+		return nil, fmt.Errorf("failed to read archive header: %w", err)
+	}
+	if header.Type != recordHeader || header.Format != Format {
+		return nil, errors.New("not a backup archive")
+	}
+	if header.Version < 1 || header.Version > Version {
+		return nil, fmt.Errorf("unsupported archive version %d", header.Version)
+	}
+
+	rs := &restorer{db: db, dec: dec, filesDir: filesDir, stats: newStats()}
+	defer rs.closeFile()
+
+	// 归档只能顺序读取一次，事务失败时不能重放
+	attempted := false
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		if attempted {
+			return errors.New("restore was interrupted by a conflicting write, run it again")
+		}
+		attempted = true
+		if err := rs.checkEmpty(ctx); err != nil {
+			return err
+		}
+		return rs.run(ctx)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return rs.stats, nil
+}
+
+func (rs *restorer) checkEmpty(ctx context.Context) error {
+	for _, t := range tables {
+		var n int
+		if err := rs.db.QueryRowContext(ctx, fmt.Sprintf("SELECT COUNT(*) FROM %s", t.name)).Scan(&n); err != nil {
+			return fmt.Errorf("failed to check %s: %w", t.name, err)
+		}
+		if n > 0 {
+			return fmt.Errorf("%w: %s has %d rows", ErrNotEmpty, t.name, n)
+		}
+	}
+	return nil
+}
+
+func (rs *restorer) run(ctx context.Context) error {
+	for {
+		var rec record
+		err := rs.dec.Decode(&rec)
+		if errors.Is(err, io.EOF) {
+			return errors.New("archive is truncated: missing end record")
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read archive: %w", err)
+		}
+
+		switch rec.Type {
+		case recordTable:
+			if err := rs.flush(ctx); err != nil {
+				return err
+			}
+			if err := rs.beginTable(ctx, rec.Table, rec.Columns); err != nil {
+				return err
+			}
+		case recordRow:
+			if err := rs.row(ctx, rec.Values); err != nil {
+				return err
+			}
+		case recordFile:
+			if err := rs.flush(ctx); err != nil {
+				return err
+			}
+			if err := rs.writeFile(rec.Path, rec.Data); err != nil {
+				return err
+			}
+		case recordEnd:
+			if err := rs.flush(ctx); err != nil {
+				return err
+			}
+			if err := rs.closeFile(); err != nil {
+				return err
+			}
+			if rec.Rows != rs.stats.Rows || rec.Files != rs.fileCount {
+				return fmt.Errorf("archive is incomplete: expected %d rows and %d files, got %d and %d",
+					rec.Rows, rec.Files, rs.stats.Rows, rs.fileCount)
+			}
+			return nil
+		default:
+			return fmt.Errorf("unknown record type %q", rec.Type)
+		}
+	}
+}
+
+// beginTable 按目标库的列类型转换后续各行；归档中的列必须都存在于目标表，目标表多出的列使用默认值
+func (rs *restorer) beginTable(ctx context.Context, name string, columns []string) error {
+	if _, ok := lookupTable(name); !ok {
+		return fmt.Errorf("unknown table %q", name)
+	}
+	columnTypes, err := emptyColumns(ctx, rs.db, name)
+	if err != nil {
+		return err
+	}
+	target := columnKinds(columnTypes)
+
+	rs.kinds = make([]kind, len(columns))
+	for i, column := range columns {
+		k, ok := target[column]
+		if !ok {
+			return fmt.Errorf("column %s.%s does not exist in the target database", name, column)
+		}
+		rs.kinds[i] = k
+	}
+	rs.table, rs.columns = name, columns
+	rs.stats.Tables[name] = 0
+	return nil
+}
+
+func (rs *restorer) row(ctx context.Context, values []interface{}) error {
+	if rs.table == "" {
+		return errors.New("row record before table record")
+	}
+	if len(values) != len(rs.columns) {
+		return fmt.Errorf("%s: expected %d values, got %d", rs.table, len(rs.columns), len(values))
+	}
+	for i, v := range values {
+		arg, err := decodeValue(rs.kinds[i], v)
+		if err != nil {
+			return fmt.Errorf("%s.%s: %w", rs.table, rs.columns[i], err)
+		}
+		rs.batch = append(rs.batch, arg)
+	}
+	rs.rows++
+	rs.stats.Tables[rs.table]++
+	rs.stats.Rows++
+
+	if len(rs.batch)+len(rs.columns) > maxInsertParams {
+		return rs.flush(ctx)
+	}
+	return nil
+}
+
+// flush 用一条多行 INSERT 写入缓存的行
+func (rs *restorer) flush(ctx context.Context) error {
+	if rs.rows == 0 {
+		return nil
+	}
+	row := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(rs.columns)), ", ") + ")"
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES %s",
+		rs.table, strings.Join(rs.columns, ", "), strings.TrimSuffix(strings.Repeat(row+", ", rs.rows), ", "))
+	if _, err := rs.db.ExecContext(ctx, query, rs.batch...); err != nil {
+		return fmt.Errorf("failed to restore %s: %w", rs.table, err)
+	}
+	rs.batch, rs.rows = rs.batch[:0], 0
+	return nil
+}
+
+// writeFile 写入文件的一段；路径变化时开始一个新文件
+func (rs *restorer) writeFile(path string, data []byte) error {
+	if path != rs.path {
+		if err := rs.closeFile(); err != nil {
+			return err
+		}
+		if !filepath.IsLocal(filepath.FromSlash(path)) {
+			return fmt.Errorf("invalid file path %q", path)
+		}
+		rs.path = path
+		rs.fileCount++
+		if rs.filesDir == "" {
+			return nil
+		}
+
+		target := filepath.Join(rs.filesDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for %s: %w", path, err)
+		}
+		f, err := os.Create(target)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", path, err)
+		}
+		rs.file = f
+		rs.stats.Files++
+	}
+	if rs.file == nil {
+		return nil
+	}
+	if _, err := rs.file.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", rs.path, err)
+	}
+	return nil
+}
+
+func (rs *restorer) closeFile() error {
+	if rs.file == nil {
+		return nil
+	}
+	err := rs.file.Close()
+	rs.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to write %s: %w", rs.path, err)
+	}
+	return nil
+}
@@ -1,26 +1,132 @@
 package config
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
 	"os"
+	"softeng-platform/internal/mail"
+	"softeng-platform/internal/repository"
+	"strconv"
+	"time"
 )
 
 type Config struct {
-	Port        string
-	DatabaseURL string
-	JWTSecret   string
+	Port           string
+	DatabaseDriver string
+	DatabaseURL    string
+	JWTSecret      string
+	CursorSecret   string
+	DatabasePool   repository.PoolConfig
+
+	TrashRetention     time.Duration // 回收站保留期，超过后永久删除
+	TrashPurgeInterval time.Duration // 清理过期回收站资源的间隔
+
+	ViewDedupWindow   time.Duration // 同一访客重复浏览只计一次的时间窗口
+	ViewFlushInterval time.Duration // 浏览量批量写入数据库的间隔
+
+	ReconcileInterval time.Duration // 校对点赞、收藏、回复计数的间隔
+
+	SearchIndexPath        string        // 统一检索的嵌入式索引目录，为空时直接查询数据库
+	SuggestRefreshInterval time.Duration // 从数据库重建输入提示的间隔
+
+	AlertInterval time.Duration // 检查新上架资源是否满足已保存检索的间隔
+	AlertLimit    int           // 每个用户在 AlertWindow 内最多收到的检索提醒数
+	AlertWindow   time.Duration
+
+	CommentReportThreshold int // 评论的待处理举报达到该数目时自动隐藏
+
+	SensitiveRefreshInterval time.Duration // 从数据库重新加载敏感词表的间隔
+
+	ReviewBatchLimit     int           // 批量审核一次最多处理的资源数
+	ReviewClaimLease     time.Duration // 管理员认领待审核资源的租期
+	ReviewAssignInterval time.Duration // 把新提交的待审核资源轮流分配给管理员的间隔
+
+	SMTP mail.SMTPConfig // 未配置 SMTP_ADDR 时邮件只写入日志
 }
 
 func LoadConfig() *Config {
+	// 数据库类型：mysql（默认）或 sqlite，sqlite 不需要外部数据库，适合本地开发和测试
+	driver := getEnv("DB_DRIVER", "mysql")
+
 	// 构建数据库连接字符串 - 使用 softeng_app:123456
 	databaseURL := buildDatabaseURL()
+	if driver == "sqlite" {
+		databaseURL = buildSQLiteURL()
+	}
+
+	jwtSecret := getEnv("JWT_SECRET", "your-secret-key")
 
 	return &Config{
-		Port:        getEnv("PORT", "8080"),
-		DatabaseURL: databaseURL,
-		JWTSecret:   getEnv("JWT_SECRET", "your-secret-key"),
+		Port:           getEnv("PORT", "8080"),
+		DatabaseDriver: driver,
+		DatabaseURL:    databaseURL,
+		JWTSecret:      jwtSecret,
+		DatabasePool:   buildPoolConfig(),
+		// 分页游标的签名密钥，未单独配置时由 JWT 密钥派生，两者不共用同一个密钥
+		CursorSecret: getEnv("CURSOR_SECRET", deriveSecret(jwtSecret, "cursor")),
+		// 默认保留 30 天，每小时清理一次
+		TrashRetention:     getDuration("TRASH_RETENTION", 30*24*time.Hour),
+		TrashPurgeInterval: getDuration("TRASH_PURGE_INTERVAL", time.Hour),
+		// 同一访客 30 分钟内的重复浏览只计一次，每 10 秒写入一次浏览量
+		ViewDedupWindow:   getDuration("VIEW_DEDUP_WINDOW", 30*time.Minute),
+		ViewFlushInterval: getDuration("VIEW_FLUSH_INTERVAL", 10*time.Second),
+		// 每天校对一次计数
+		ReconcileInterval: getDuration("RECONCILE_INTERVAL", 24*time.Hour),
+		// 统一检索的索引目录，为空时不使用嵌入式索引
+		SearchIndexPath: getEnv("SEARCH_INDEX_PATH", ""),
+		// 每 5 分钟重建一次输入提示
+		SuggestRefreshInterval: getDuration("SUGGEST_REFRESH_INTERVAL", 5*time.Minute),
+		// 每分钟检查一次新上架的资源，每个用户每天最多 10 条检索提醒
+		AlertInterval: getDuration("ALERT_INTERVAL", time.Minute),
+		AlertLimit:    getInt("ALERT_LIMIT", 10),
+		AlertWindow:   getDuration("ALERT_WINDOW", 24*time.Hour),
+		// 被 3 个用户举报的评论先隐藏，等待审核
+		CommentReportThreshold: getInt("COMMENT_REPORT_THRESHOLD", 3),
+		// 每分钟重新加载一次敏感词表，同步其他实例对词表的修改
+		SensitiveRefreshInterval: getDuration("SENSITIVE_REFRESH_INTERVAL", time.Minute),
+		// 批量审核一次最多 50 项；认领 30 分钟后过期，每分钟分配一次新提交的资源
+		ReviewBatchLimit:     getInt("REVIEW_BATCH_LIMIT", 50),
+		ReviewClaimLease:     getDuration("REVIEW_CLAIM_LEASE", 30*time.Minute),
+		ReviewAssignInterval: getDuration("REVIEW_ASSIGN_INTERVAL", time.Minute),
+		SMTP: mail.SMTPConfig{
+			Addr:     getEnv("SMTP_ADDR", ""),
+			Username: getEnv("SMTP_USERNAME", ""),
+			Password: getEnv("SMTP_PASSWORD", ""),
+			From:     getEnv("MAIL_FROM", "noreply@softeng.local"),
+			Timeout:  getDuration("SMTP_TIMEOUT", 30*time.Second),
+		},
+	}
+}
+
+// buildPoolConfig 读取连接池配置，SQLite 只使用其中的 DB_QUERY_TIMEOUT
+// deriveSecret 由主密钥派生出用于 purpose 的子密钥：HMAC-SHA256(secret, purpose)
+func deriveSecret(secret, purpose string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(purpose))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func buildPoolConfig() repository.PoolConfig {
+	defaults := repository.DefaultPoolConfig
+	return repository.PoolConfig{
+		MaxOpenConns:    getInt("DB_MAX_OPEN_CONNS", defaults.MaxOpenConns),
+		MaxIdleConns:    getInt("DB_MAX_IDLE_CONNS", defaults.MaxIdleConns),
+		ConnMaxLifetime: getDuration("DB_CONN_MAX_LIFETIME", defaults.ConnMaxLifetime),
+		ConnMaxIdleTime: getDuration("DB_CONN_MAX_IDLE_TIME", defaults.ConnMaxIdleTime),
+		QueryTimeout:    getDuration("DB_QUERY_TIMEOUT", defaults.QueryTimeout),
 	}
 }
 
+func buildSQLiteURL() string {
+	// DB_PATH 为 :memory: 时使用内存数据库，进程退出后数据丢失
+	path := getEnv("DB_PATH", "softeng.db")
+
+	// 开启外键约束（级联删除依赖它），并在数据库忙时等待而不是立即失败
+	return "file:" + path + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+}
+
 func buildDatabaseURL() string {
 	// 从环境变量获取配置，如果没有则使用默认值
 	user := getEnv("DB_USER", "softeng_app")    // 默认用户
@@ -42,3 +148,31 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getDuration 读取 time.ParseDuration 格式的时长（如 720h），无效或非正数时使用默认值
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getInt 读取正整数，无效或非正数时使用默认值
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		log.Printf("Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
@@ -1,41 +1,75 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/service"
 	"softeng-platform/pkg/response"
-	"strconv"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AdminHandler struct {
 	adminService service.AdminService
+	trashService service.TrashService
 }
 
-func NewAdminHandler(adminService service.AdminService) *AdminHandler {
-	return &AdminHandler{adminService: adminService}
+func NewAdminHandler(adminService service.AdminService, trashService service.TrashService) *AdminHandler {
+	return &AdminHandler{adminService: adminService, trashService: trashService}
 }
 
-// GetPending 获取待审核内容
+// GetPending 获取待审核内容，claim 为 unclaimed、mine 或 assigned 时按认领情况筛选
 func (h *AdminHandler) GetPending(c *gin.Context) {
 	itemType := c.Query("type")
-	cursor, _ := strconv.Atoi(c.Query("cursor"))
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
 	sort := c.Query("sort")
+	filter := model.PendingFilter{Claim: c.Query("claim"), ReviewerID: c.GetInt("userID")}
+	switch filter.Claim {
+	case "", model.ClaimUnclaimed, model.ClaimMine, model.ClaimAssigned:
+	default:
+		response.Error(c, http.StatusBadRequest, "Invalid claim")
+		return
+	}
 
-	result, err := h.adminService.GetPending(c.Request.Context(), itemType, cursor, limit, sort)
+	result, err := h.adminService.GetPending(c.Request.Context(), itemType, filter, pageRequest(c, "limit"), sort)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		listError(c, err)
 		return
 	}
 
 	response.Success(c, result)
 }
 
-// ReviewItem 审核项目
+// reviewTarget 读取审核目标：/review/:resourceType/:itemId，或兼容旧接口的 /review/:itemId，
+// 此时 itemId 带有类型前缀，如 tool-12、course_web-3
+func reviewTarget(c *gin.Context) (string, int, bool) {
+	if c.Param("itemId") != "" {
+		itemID, ok := paramID(c, "itemId")
+		return c.Param("resourceType"), itemID, ok
+	}
+	target := c.Param("resourceType")
+	i := strings.LastIndex(target, "-")
+	if i < 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid itemId")
+		return "", 0, false
+	}
+	itemID, ok := parseID(c, "itemId", target[i+1:])
+	return target[:i], itemID, ok
+}
+
+// ReviewItem 审核工具、项目或课程资源，工具和项目需要 If-Match；课程资源没有版本号，不需要
 func (h *AdminHandler) ReviewItem(c *gin.Context) {
-	itemID := c.Param("itemId")
+	resourceType, itemID, ok := reviewTarget(c)
+	if !ok {
+		return
+	}
+	version := 0
+	if model.ReviewVersioned(resourceType) {
+		if version, ok = ifMatch(c); !ok {
+			return
+		}
+	}
 
 	var req struct {
 		Action       string `form:"action" json:"action" binding:"required"`
@@ -48,13 +82,227 @@ func (h *AdminHandler) ReviewItem(c *gin.Context) {
 		return
 	}
 
-	err := h.adminService.ReviewItem(c.Request.Context(), itemID, req.Action, req.RejectReason)
+	result, err := h.adminService.ReviewItem(auditContext(c), c.GetInt("userID"), resourceType, itemID, version, req.Action, req.RejectReason)
+	if err != nil {
+		reviewError(c, err)
+		return
+	}
+
+	if result.Version != 0 {
+		setETag(c, result.Version)
+	}
+	response.Success(c, gin.H{
+		"message":    "Review completed successfully",
+		"manipulate": result,
+	})
+}
+
+// ReviewItems 批量通过或拒绝工具、项目和课程资源，返回每一项的结果；
+// 部分项失败时仍返回 200，由各项的 status 区分
+func (h *AdminHandler) ReviewItems(c *gin.Context) {
+	var req model.BulkReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	result, err := h.adminService.ReviewItems(auditContext(c), c.GetInt("userID"), req)
+	if err != nil {
+		if errors.Is(err, service.ErrTooManyItems) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		reviewError(c, err)
+		return
+	}
+
+	for i := range result.Results {
+		item := &result.Results[i]
+		if item.Err != nil {
+			item.Status = errorStatus(item.Err)
+			item.Error = item.Err.Error()
+		} else {
+			item.Status = http.StatusOK
+		}
+	}
+	response.Success(c, gin.H{
+		"message":   "Bulk review completed",
+		"succeeded": result.Succeeded,
+		"failed":    result.Failed,
+		"results":   result.Results,
+	})
+}
+
+// ClaimItem 认领待审核资源，已是自己认领的则续期
+func (h *AdminHandler) ClaimItem(c *gin.Context) {
+	itemID, ok := paramID(c, "itemId")
+	if !ok {
+		return
+	}
+
+	claim, err := h.adminService.ClaimItem(auditContext(c), c.GetInt("userID"), c.Param("resourceType"), itemID)
+	if err != nil {
+		reviewError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Item claimed successfully",
+		"claim":   claim,
+	})
+}
+
+// ReleaseItem 放弃自己的认领
+func (h *AdminHandler) ReleaseItem(c *gin.Context) {
+	itemID, ok := paramID(c, "itemId")
+	if !ok {
+		return
+	}
+
+	if err := h.adminService.ReleaseItem(auditContext(c), c.GetInt("userID"), c.Param("resourceType"), itemID); err != nil {
+		reviewError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Claim released successfully",
+	})
+}
+
+// AssignPending 立即把还没有分配的待审核资源轮流分配给各管理员
+func (h *AdminHandler) AssignPending(c *gin.Context) {
+	assigned, err := h.adminService.AssignPending(auditContext(c))
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
 	}
 
 	response.Success(c, gin.H{
-		"message": "Review completed successfully",
+		"message":  "Pending items assigned successfully",
+		"assigned": assigned,
+	})
+}
+
+// GetReviewHistory 获取资源的审核状态变更记录
+func (h *AdminHandler) GetReviewHistory(c *gin.Context) {
+	itemID, ok := paramID(c, "itemId")
+	if !ok {
+		return
+	}
+
+	logs, err := h.adminService.ReviewHistory(c.Request.Context(), c.Param("resourceType"), itemID)
+	if err != nil {
+		reviewError(c, err)
+		return
+	}
+
+	response.Success(c, model.NewListResponse("success", logs))
+}
+
+// ModerateComment 处理评论审核队列中的评论
+func (h *AdminHandler) ModerateComment(c *gin.Context) {
+	commentID, ok := paramID(c, "commentId")
+	if !ok {
+		return
+	}
+
+	var req model.ModerateCommentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	result, err := h.adminService.ModerateComment(auditContext(c), c.GetInt("userID"), commentID, req.Action, req.Note)
+	if err != nil {
+		moderationError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Comment moderated successfully",
+		"result":  result,
+	})
+}
+
+// GetTrash 获取全站回收站中的资源
+func (h *AdminHandler) GetTrash(c *gin.Context) {
+	trash, err := h.trashService.List(c.Request.Context(), 0, pageRequest(c, "limit"))
+	if err != nil {
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, trash)
+}
+
+// DeleteResource 将任意资源移入回收站
+func (h *AdminHandler) DeleteResource(c *gin.Context) {
+	userID := c.GetInt("userID")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.trashService.Delete(auditContext(c), 0, userID, c.Param("resourceType"), resourceID, version)
+	if err != nil {
+		trashError(c, err)
+		return
+	}
+
+	setETag(c, item.Version)
+	response.Success(c, gin.H{
+		"message": "Resource moved to trash",
+		"item":    item,
+	})
+}
+
+// RestoreTrash 从回收站恢复资源
+func (h *AdminHandler) RestoreTrash(c *gin.Context) {
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.trashService.Restore(auditContext(c), 0, c.Param("resourceType"), resourceID, version)
+	if err != nil {
+		trashError(c, err)
+		return
+	}
+
+	setETag(c, item.Version)
+	response.Success(c, gin.H{
+		"message": "Resource restored successfully",
+		"item":    item,
+	})
+}
+
+// PurgeTrash 永久删除回收站中的资源
+func (h *AdminHandler) PurgeTrash(c *gin.Context) {
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.trashService.Purge(auditContext(c), 0, c.Param("resourceType"), resourceID, version)
+	if err != nil {
+		trashError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Resource purged successfully",
+		"item":    item,
 	})
 }
@@ -0,0 +1,173 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"softeng-platform/internal/mail"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/service"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// reviewFixture 基于内存仓库的审核接口，请求以管理员 amy 的身份发出
+type reviewFixture struct {
+	router  *gin.Engine
+	tools   repository.ToolRepository
+	audit   repository.AuditLogRepository
+	admin   service.AdminService
+	author  *model.User
+	amy     *model.User
+	ben     *model.User
+	t       *testing.T
+	context context.Context
+}
+
+func newReviewFixture(t *testing.T) *reviewFixture {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	users := repository.NewMemoryUserRepository(store)
+	f := &reviewFixture{
+		tools:   repository.NewMemoryToolRepository(store),
+		audit:   repository.NewMemoryAuditLogRepository(store),
+		t:       t,
+		context: ctx,
+	}
+	newUser := func(name, role string) *model.User {
+		user := &model.User{Username: name, Nickname: name, Email: name + "@example.com", Password: "hashed", Role: role}
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("create user %s: %v", name, err)
+		}
+		return user
+	}
+	f.author, f.amy, f.ben = newUser("zack", "user"), newUser("amy", "admin"), newUser("ben", "admin")
+
+	notifications := repository.NewMemoryNotificationRepository(store)
+	f.admin = service.NewAdminService(f.tools, repository.NewMemoryCourseRepository(store), repository.NewMemoryProjectRepository(store),
+		repository.NewMemoryReviewRepository(store), repository.NewMemoryCommentReportRepository(store), repository.NewMemoryReviewClaimRepository(store),
+		repository.NewSQLSearchIndex(repository.NewMemorySearchRepository(store)), service.NewNotifier(notifications, users, mail.New(mail.SMTPConfig{})),
+		service.NewAuditor(store, f.audit), pagination.NewCodec("test"), 10, time.Hour)
+	h := NewAdminHandler(f.admin, nil)
+
+	f.router = gin.New()
+	f.router.Use(func(c *gin.Context) {
+		c.Set("userID", f.amy.ID)
+		c.Set("username", f.amy.Username)
+	})
+	f.router.POST("/admin/review/batch", h.ReviewItems)
+	f.router.POST("/admin/review/:resourceType/:itemId", h.ReviewItem)
+	return f
+}
+
+func (f *reviewFixture) tool(name string) int {
+	f.t.Helper()
+	review, err := f.tools.Create(f.context, f.author.ID, model.ToolSubmitRequest{Name: name, Link: "https://example.com/" + name,
+		Description: name, DescriptionDetail: name, Category: "IDE"})
+	if err != nil {
+		f.t.Fatalf("create tool %s: %v", name, err)
+	}
+	return review.ResourceID
+}
+
+func (f *reviewFixture) do(path, ifMatch, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	w := httptest.NewRecorder()
+	f.router.ServeHTTP(w, req)
+	return w
+}
+
+func (f *reviewFixture) auditLogs(action string) []model.AuditLog {
+	f.t.Helper()
+	result, err := f.audit.List(f.context, model.AuditFilter{Action: action}, pagination.Page{Limit: 20})
+	if err != nil {
+		f.t.Fatalf("Audit.List: %v", err)
+	}
+	return result.Items
+}
+
+func TestReviewItemPreconditions(t *testing.T) {
+	f := newReviewFixture(t)
+	id := f.tool("alpha")
+	path := fmt.Sprintf("/admin/review/tool/%d", id)
+	body := `{"action":"approve"}`
+
+	// 缺少 If-Match 返回 428，版本过期返回 412 及当前版本
+	if w := f.do(path, "", body); w.Code != http.StatusPreconditionRequired {
+		t.Errorf("without If-Match: got %d %s, want 428", w.Code, w.Body)
+	}
+	if w := f.do(path, `"9"`, body); w.Code != http.StatusPreconditionFailed || w.Header().Get("ETag") != `"1"` {
+		t.Errorf("stale If-Match: got %d ETag %s, want 412 with ETag \"1\"", w.Code, w.Header().Get("ETag"))
+	}
+	if got := f.auditLogs("review"); len(got) != 0 {
+		t.Errorf("failed reviews were audited: %+v", got)
+	}
+
+	if w := f.do(path, `"1"`, body); w.Code != http.StatusOK || w.Header().Get("ETag") != `"2"` {
+		t.Errorf("current If-Match: got %d ETag %s, want 200 with ETag \"2\"", w.Code, w.Header().Get("ETag"))
+	}
+	if got := f.auditLogs(model.AuditReviewPrefix + "approve"); len(got) != 1 || got[0].ActorID != f.amy.ID || got[0].TargetID != id {
+		t.Errorf("approve audit logs: got %+v", got)
+	}
+}
+
+func TestReviewItemsStatuses(t *testing.T) {
+	f := newReviewFixture(t)
+	approved, stale, unversioned, claimed := f.tool("alpha"), f.tool("beta"), f.tool("gamma"), f.tool("delta")
+	if _, err := f.admin.ClaimItem(f.context, f.ben.ID, model.ResourceTypeTool, claimed); err != nil {
+		t.Fatalf("ClaimItem: %v", err)
+	}
+
+	body := fmt.Sprintf(`{"action":"approve","items":[
+		{"resourceType":"tool","resourceId":%d,"version":1},
+		{"resourceType":"tool","resourceId":%d,"version":9},
+		{"resourceType":"tool","resourceId":%d},
+		{"resourceType":"tool","resourceId":%d,"version":1},
+		{"resourceType":"tool","resourceId":%d,"version":1},
+		{"resourceType":"article","resourceId":1}
+	]}`, approved, stale, unversioned, claimed, claimed+100)
+	w := f.do("/admin/review/batch", "", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("ReviewItems: got %d %s", w.Code, w.Body)
+	}
+	var resp struct {
+		Succeeded int                      `json:"succeeded"`
+		Failed    int                      `json:"failed"`
+		Results   []model.BulkReviewResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	// 每一项的状态码与单独审核该项时相同
+	want := []int{http.StatusOK, http.StatusPreconditionFailed, http.StatusPreconditionRequired,
+		http.StatusConflict, http.StatusNotFound, http.StatusBadRequest}
+	var got []int
+	for _, result := range resp.Results {
+		got = append(got, result.Status)
+		if (result.Status == http.StatusOK) != (result.Error == "") {
+			t.Errorf("result %s %d: status %d with error %q", result.ResourceType, result.ResourceID, result.Status, result.Error)
+		}
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) || resp.Succeeded != 1 || resp.Failed != 5 {
+		t.Errorf("ReviewItems: got statuses %v, %d succeeded, %d failed; want %v, 1, 5", got, resp.Succeeded, resp.Failed, want)
+	}
+
+	// 只有成功的一项记入审计日志
+	if logs := f.auditLogs(model.AuditReviewPrefix + "approve"); len(logs) != 1 || logs[0].ActorID != f.amy.ID || logs[0].TargetID != approved {
+		t.Errorf("audit logs: got %+v; want one approve of tool %d", logs, approved)
+	}
+}
@@ -0,0 +1,134 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// auditTimeLayouts 检索审计日志时可用的时间格式，不带时区的按服务器本地时间解析
+var auditTimeLayouts = []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02"}
+
+type AuditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// GetAuditLogs 按时间倒序分页检索审计日志
+func (h *AuditHandler) GetAuditLogs(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+
+	logs, err := h.auditService.List(c.Request.Context(), filter, pageRequest(c, "limit"))
+	if err != nil {
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, logs)
+}
+
+// ExportAuditLogs 导出满足条件的全部审计日志，format 为 csv（默认）或 jsonl
+func (h *AuditHandler) ExportAuditLogs(c *gin.Context) {
+	filter, ok := auditFilter(c)
+	if !ok {
+		return
+	}
+	format := c.DefaultQuery("format", "csv")
+	if format != "csv" && format != "jsonl" {
+		response.Error(c, http.StatusBadRequest, "Invalid format")
+		return
+	}
+
+	filename := "audit-" + time.Now().Format("20060102-150405") + "." + format
+	c.Header("Content-Disposition", `attachment; filename="`+filename+`"`)
+	var err error
+	if format == "jsonl" {
+		c.Header("Content-Type", "application/x-ndjson; charset=utf-8")
+		encoder := json.NewEncoder(c.Writer)
+		err = h.auditService.Export(c.Request.Context(), filter, func(entry *model.AuditLog) error {
+			return encoder.Encode(entry)
+		})
+	} else {
+		c.Header("Content-Type", "text/csv; charset=utf-8")
+		w := csv.NewWriter(c.Writer)
+		err = w.Write([]string{"id", "created_at", "actor_id", "actor", "action", "target_type", "target_id", "before", "after", "ip", "request_id"})
+		if err == nil {
+			err = h.auditService.Export(c.Request.Context(), filter, func(entry *model.AuditLog) error {
+				return w.Write([]string{
+					strconv.Itoa(entry.ID), entry.CreatedAt, strconv.Itoa(entry.ActorID), entry.Actor, entry.Action,
+					entry.TargetType, strconv.Itoa(entry.TargetID), string(entry.Before), string(entry.After), entry.IP, entry.RequestID,
+				})
+			})
+		}
+		w.Flush()
+	}
+	if err != nil {
+		// 响应头已经发出，只能中断输出
+		c.Error(err)
+		c.Abort()
+	}
+}
+
+// auditFilter 读取检索条件：actor 为用户ID或用户名，action 为完整的操作或类别，
+// targetType、targetId 为目标，from、to 为时间范围，只有日期的 to 包含当天
+func auditFilter(c *gin.Context) (model.AuditFilter, bool) {
+	filter := model.AuditFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("targetType"),
+	}
+	if actor := c.Query("actor"); actor != "" {
+		if id, err := strconv.Atoi(actor); err == nil {
+			filter.ActorID = id
+		} else {
+			filter.Actor = actor
+		}
+	}
+	if c.Query("targetId") != "" {
+		var ok bool
+		if filter.TargetID, ok = queryID(c, "targetId"); !ok {
+			return filter, false
+		}
+	}
+
+	var ok bool
+	if filter.From, ok = auditTime(c, "from", false); !ok {
+		return filter, false
+	}
+	if filter.To, ok = auditTime(c, "to", true); !ok {
+		return filter, false
+	}
+	return filter, true
+}
+
+// auditTime 解析时间参数，参数为空时返回零值；endOfDay 为 true 时只有日期的值取次日零点
+func auditTime(c *gin.Context, name string, endOfDay bool) (time.Time, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return time.Time{}, true
+	}
+	for _, layout := range auditTimeLayouts {
+		t, err := time.ParseInLocation(layout, value, time.Local)
+		if err != nil {
+			continue
+		}
+		if endOfDay && layout == "2006-01-02" {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, true
+	}
+	response.Error(c, http.StatusBadRequest, "Invalid "+name)
+	return time.Time{}, false
+}
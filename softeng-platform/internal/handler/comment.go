@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CommentHandler struct {
+	reportService service.CommentReportService
+}
+
+func NewCommentHandler(reportService service.CommentReportService) *CommentHandler {
+	return &CommentHandler{reportService: reportService}
+}
+
+// ReportComment 举报评论或回复
+func (h *CommentHandler) ReportComment(c *gin.Context) {
+	commentID, ok := paramID(c, "commentId")
+	if !ok {
+		return
+	}
+
+	var req model.CommentReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	if err := h.reportService.Report(c.Request.Context(), c.GetInt("userID"), commentID, req); err != nil {
+		moderationError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Comment reported successfully",
+	})
+}
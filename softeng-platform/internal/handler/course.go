@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/service"
 	"softeng-platform/pkg/response"
 	"strconv"
@@ -22,13 +23,11 @@ func (h *CourseHandler) GetCourses(c *gin.Context) {
 	semester := c.Query("semester")
 	category := c.QueryArray("category")
 	sort := c.Query("sort")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	cursor, _ := strconv.Atoi(c.Query("cursor"))
 	resourceType := c.Query("resourceType")
 
-	courses, err := h.courseService.GetCourses(c.Request.Context(), semester, category, sort, limit, cursor, resourceType)
+	courses, err := h.courseService.GetCourses(c.Request.Context(), semester, category, sort, pageRequest(c, "limit"), resourceType)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		listError(c, err)
 		return
 	}
 
@@ -39,13 +38,12 @@ func (h *CourseHandler) GetCourses(c *gin.Context) {
 func (h *CourseHandler) SearchCourses(c *gin.Context) {
 	keyword := c.Query("keyword")
 	category := c.QueryArray("category")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	cursor, _ := strconv.Atoi(c.Query("cursor"))
+	sort := c.Query("sort")
 	resourceType := c.Query("resourceType")
 
-	courses, err := h.courseService.SearchCourses(c.Request.Context(), keyword, category, limit, cursor, resourceType)
+	courses, err := h.courseService.SearchCourses(c.Request.Context(), keyword, category, sort, pageRequest(c, "limit"), resourceType)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		listError(c, err)
 		return
 	}
 
@@ -54,7 +52,10 @@ func (h *CourseHandler) SearchCourses(c *gin.Context) {
 
 // GetCourse 获取课程详情
 func (h *CourseHandler) GetCourse(c *gin.Context) {
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
 	resourceType := c.Query("resourceType")
 
 	course, err := h.courseService.GetCourse(c.Request.Context(), courseID, resourceType)
@@ -63,13 +64,17 @@ func (h *CourseHandler) GetCourse(c *gin.Context) {
 		return
 	}
 
+	setETag(c, course.Courses[0].Version)
 	response.Success(c, course)
 }
 
 // UploadResource 上传课程资源
 func (h *CourseHandler) UploadResource(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
 	resourceType := c.Query("resourceType")
 	
 	// resourceType 是必需的query参数
@@ -78,7 +83,7 @@ func (h *CourseHandler) UploadResource(c *gin.Context) {
 		return
 	}
 
-	var req service.CourseUploadRequest
+	var req model.CourseUploadRequest
 	// 支持 multipart/form-data 和 application/json
 	if err := c.ShouldBind(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid request data")
@@ -87,7 +92,7 @@ func (h *CourseHandler) UploadResource(c *gin.Context) {
 
 	result, err := h.courseService.UploadResource(c.Request.Context(), userID, courseID, resourceType, req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -96,8 +101,14 @@ func (h *CourseHandler) UploadResource(c *gin.Context) {
 
 // DownloadTextbook 下载课本
 func (h *CourseHandler) DownloadTextbook(c *gin.Context) {
-	courseID := c.Param("courseId")
-	textbookID := c.Param("textbookId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
+	textbookID, ok := paramID(c, "textbookId")
+	if !ok {
+		return
+	}
 
 	result, err := h.courseService.DownloadTextbook(c.Request.Context(), courseID, textbookID)
 	if err != nil {
@@ -111,7 +122,10 @@ func (h *CourseHandler) DownloadTextbook(c *gin.Context) {
 // AddComment 发表评论
 func (h *CourseHandler) AddComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
 
 	var req struct {
 		Content string `form:"content" json:"content" binding:"required"`
@@ -125,7 +139,7 @@ func (h *CourseHandler) AddComment(c *gin.Context) {
 
 	result, err := h.courseService.AddComment(c.Request.Context(), userID, courseID, req.Content)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -135,9 +149,16 @@ func (h *CourseHandler) AddComment(c *gin.Context) {
 // DeleteComment 删除评论
 func (h *CourseHandler) DeleteComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
+	commentID, ok := queryID(c, "commentId")
+	if !ok {
+		return
+	}
 
-	result, err := h.courseService.DeleteComment(c.Request.Context(), userID, courseID)
+	result, err := h.courseService.DeleteComment(c.Request.Context(), userID, courseID, commentID)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -149,8 +170,14 @@ func (h *CourseHandler) DeleteComment(c *gin.Context) {
 // ReplyComment 回复评论
 func (h *CourseHandler) ReplyComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
-	commentID := c.Param("commentId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
+	commentID, ok := paramID(c, "commentId")
+	if !ok {
+		return
+	}
 
 	var req struct {
 		Content string `form:"content" json:"content" binding:"required"`
@@ -164,7 +191,7 @@ func (h *CourseHandler) ReplyComment(c *gin.Context) {
 
 	result, err := h.courseService.ReplyComment(c.Request.Context(), userID, courseID, commentID, req.Content)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -174,8 +201,14 @@ func (h *CourseHandler) ReplyComment(c *gin.Context) {
 // DeleteReply 删除回复
 func (h *CourseHandler) DeleteReply(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
-	commentID := c.Param("commentId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
+	commentID, ok := paramID(c, "commentId")
+	if !ok {
+		return
+	}
 
 	result, err := h.courseService.DeleteReply(c.Request.Context(), userID, courseID, commentID)
 	if err != nil {
@@ -188,11 +221,31 @@ func (h *CourseHandler) DeleteReply(c *gin.Context) {
 
 // AddView 增加课程浏览量
 func (h *CourseHandler) AddView(c *gin.Context) {
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
 
-	result, err := h.courseService.AddView(c.Request.Context(), courseID)
+	result, err := h.courseService.AddView(c.Request.Context(), courseID, visitorKey(c))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		viewError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetDailyViews 获取课程最近 days 天每天的浏览量
+func (h *CourseHandler) GetDailyViews(c *gin.Context) {
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	result, err := h.courseService.GetDailyViews(c.Request.Context(), courseID, days)
+	if err != nil {
+		viewError(c, err)
 		return
 	}
 
@@ -202,7 +255,10 @@ func (h *CourseHandler) AddView(c *gin.Context) {
 // CollectCourse 收藏课程
 func (h *CourseHandler) CollectCourse(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
 
 	result, err := h.courseService.CollectCourse(c.Request.Context(), userID, courseID)
 	if err != nil {
@@ -216,7 +272,10 @@ func (h *CourseHandler) CollectCourse(c *gin.Context) {
 // UncollectCourse 取消收藏课程
 func (h *CourseHandler) UncollectCourse(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
 
 	result, err := h.courseService.UncollectCourse(c.Request.Context(), userID, courseID)
 	if err != nil {
@@ -230,7 +289,10 @@ func (h *CourseHandler) UncollectCourse(c *gin.Context) {
 // LikeCourse 点赞课程
 func (h *CourseHandler) LikeCourse(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
 
 	result, err := h.courseService.LikeCourse(c.Request.Context(), userID, courseID)
 	if err != nil {
@@ -244,7 +306,10 @@ func (h *CourseHandler) LikeCourse(c *gin.Context) {
 // UnlikeCourse 取消点赞课程
 func (h *CourseHandler) UnlikeCourse(c *gin.Context) {
 	userID := c.GetInt("userID")
-	courseID := c.Param("courseId")
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
 
 	result, err := h.courseService.UnlikeCourse(c.Request.Context(), userID, courseID)
 	if err != nil {
@@ -254,3 +319,19 @@ func (h *CourseHandler) UnlikeCourse(c *gin.Context) {
 
 	response.Success(c, result)
 }
+
+// GetComments 分页获取评论，回复随父评论一起返回
+func (h *CourseHandler) GetComments(c *gin.Context) {
+	courseID, ok := paramID(c, "courseId")
+	if !ok {
+		return
+	}
+
+	comments, err := h.courseService.GetComments(c.Request.Context(), courseID, pageRequest(c, "limit"))
+	if err != nil {
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, comments)
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthHandler struct {
+	healthService service.HealthService
+}
+
+func NewHealthHandler(healthService service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// Health 健康检查，数据库不可用时返回 503；不需要认证，不返回内部细节
+func (h *HealthHandler) Health(c *gin.Context) {
+	if !h.healthService.Healthy(c.Request.Context()) {
+		response.Error(c, http.StatusServiceUnavailable, "Database unavailable")
+		return
+	}
+
+	response.Success(c, model.NewDataResponse("success", gin.H{"status": "ok"}))
+}
+
+// DBStats 数据库连接池统计，供管理员按实际流量调整连接池大小
+func (h *HealthHandler) DBStats(c *gin.Context) {
+	result, err := h.healthService.DBStats(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationHandler struct {
+	savedSearchService  service.SavedSearchService
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(savedSearchService service.SavedSearchService, notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{savedSearchService: savedSearchService, notificationService: notificationService}
+}
+
+// CreateSavedSearch 保存检索条件，之后上架的资源满足条件时提醒
+func (h *NotificationHandler) CreateSavedSearch(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	var req model.SavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	result, err := h.savedSearchService.Create(c.Request.Context(), userID, req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSavedSearch) || errors.Is(err, service.ErrInvalidResourceType) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetSavedSearches 获取自己保存的检索
+func (h *NotificationHandler) GetSavedSearches(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	result, err := h.savedSearchService.List(c.Request.Context(), userID)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// DeleteSavedSearch 删除保存的检索
+func (h *NotificationHandler) DeleteSavedSearch(c *gin.Context) {
+	userID := c.GetInt("userID")
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.savedSearchService.Delete(c.Request.Context(), userID, id); err != nil {
+		if errors.Is(err, service.ErrSavedSearchNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Saved search deleted successfully",
+	})
+}
+
+// GetNotifications 获取站内通知，unread=true 时只返回未读的
+func (h *NotificationHandler) GetNotifications(c *gin.Context) {
+	userID := c.GetInt("userID")
+	unreadOnly, _ := strconv.ParseBool(c.Query("unread"))
+
+	result, err := h.notificationService.List(c.Request.Context(), userID, unreadOnly, pageRequest(c, "limit"))
+	if err != nil {
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// MarkNotificationRead 把一条通知标为已读
+func (h *NotificationHandler) MarkNotificationRead(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+	h.markRead(c, id)
+}
+
+// MarkAllNotificationsRead 把全部通知标为已读
+func (h *NotificationHandler) MarkAllNotificationsRead(c *gin.Context) {
+	h.markRead(c, 0)
+}
+
+func (h *NotificationHandler) markRead(c *gin.Context, id int) {
+	marked, err := h.notificationService.MarkRead(c.Request.Context(), c.GetInt("userID"), id)
+	if err != nil {
+		if errors.Is(err, service.ErrNotificationNotFound) {
+			response.Error(c, http.StatusNotFound, err.Error())
+			return
+		}
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Notifications marked as read",
+		"marked":  marked,
+	})
+}
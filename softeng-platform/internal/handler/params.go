@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/service"
+	"softeng-platform/internal/tagquery"
+	"softeng-platform/pkg/response"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// paramID 解析路径中的数字ID，解析失败时直接返回 400
+func paramID(c *gin.Context, name string) (int, bool) {
+	return parseID(c, name, c.Param(name))
+}
+
+// queryID 解析 query 或表单中的数字ID，解析失败时直接返回 400
+func queryID(c *gin.Context, name string) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		value = c.PostForm(name)
+	}
+	return parseID(c, name, value)
+}
+
+func parseID(c *gin.Context, name, value string) (int, bool) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		response.Error(c, http.StatusBadRequest, "Invalid "+name)
+		return 0, false
+	}
+	return id, true
+}
+
+// pageRequest 读取分页参数：cursor 为上一页返回的 next_cursor，limitName 为每页条数的参数名，
+// with_total=true 时额外返回总数
+func pageRequest(c *gin.Context, limitName string) pagination.Request {
+	limit, _ := strconv.Atoi(c.DefaultQuery(limitName, "10"))
+	withTotal, _ := strconv.ParseBool(c.Query("with_total"))
+	return pagination.Request{Cursor: c.Query("cursor"), Limit: limit, WithTotal: withTotal}
+}
+
+// listError 列表接口的错误响应，游标无效或标签查询有语法错误时返回 400
+func listError(c *gin.Context, err error) {
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		response.Error(c, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	var syntaxErr *tagquery.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		response.Error(c, http.StatusBadRequest, syntaxErr.Error())
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, err.Error())
+}
+
+// setETag 在响应头中返回资源的版本号，客户端修改资源时原样放入 If-Match
+func setETag(c *gin.Context, version int) {
+	c.Header("ETag", strconv.Quote(strconv.Itoa(version)))
+}
+
+// ifMatch 读取 If-Match 请求头中的版本号。缺少时返回 428，格式错误时返回 400；
+// 为 * 时返回 0，表示不检查版本
+func ifMatch(c *gin.Context) (int, bool) {
+	value := strings.TrimSpace(c.GetHeader("If-Match"))
+	if value == "" {
+		response.Error(c, http.StatusPreconditionRequired, "If-Match header required")
+		return 0, false
+	}
+	if value == "*" {
+		return 0, true
+	}
+
+	// 只接受强校验的 ETag，即 setETag 返回的 "版本号"
+	version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(value, `"`), `"`))
+	if err != nil || version <= 0 || !strings.HasPrefix(value, `"`) || !strings.HasSuffix(value, `"`) {
+		response.Error(c, http.StatusBadRequest, "Invalid If-Match header")
+		return 0, false
+	}
+	return version, true
+}
+
+// preconditionFailed 版本过期时返回 412，data 为资源的当前状态，ETag 为当前版本
+func preconditionFailed(c *gin.Context, err error) bool {
+	var conflict *service.PreconditionFailedError
+	if !errors.As(err, &conflict) {
+		return false
+	}
+	setETag(c, conflict.Version)
+	response.ErrorWithObject(c, http.StatusPreconditionFailed, conflict.Error(), conflict.Current)
+	return true
+}
+
+// trashError 回收站相关接口的错误响应：找不到资源返回 404，参数错误返回 400，版本过期返回 412
+func trashError(c *gin.Context, err error) {
+	if preconditionFailed(c, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, service.ErrResourceNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidResourceType), errors.Is(err, service.ErrInvalidAction):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		listError(c, err)
+	}
+}
+
+// reviewError 审核状态变更的错误响应，状态码见 errorStatus；版本过期时同 preconditionFailed，
+// 被其他管理员认领时在 data 中附上认领情况
+func reviewError(c *gin.Context, err error) {
+	if preconditionFailed(c, err) {
+		return
+	}
+	var claimed *service.ClaimConflictError
+	if errors.As(err, &claimed) {
+		response.ErrorWithObject(c, http.StatusConflict, err.Error(), claimed.Claim)
+		return
+	}
+	response.Error(c, errorStatus(err), err.Error())
+}
+
+// errorStatus 审核失败时的状态码，reviewError 和批量审核的逐项结果共用：版本过期 412，
+// 被他人认领或当前状态不允许该操作 409，缺少版本号 428，找不到资源 404，参数错误 400
+func errorStatus(err error) int {
+	var conflict *service.PreconditionFailedError
+	var claimed *service.ClaimConflictError
+	var syntaxErr *tagquery.SyntaxError
+	switch {
+	case errors.As(err, &conflict):
+		return http.StatusPreconditionFailed
+	case errors.As(err, &claimed), errors.Is(err, service.ErrInvalidTransition):
+		return http.StatusConflict
+	case errors.Is(err, service.ErrVersionRequired):
+		return http.StatusPreconditionRequired
+	case errors.Is(err, service.ErrResourceNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalidResourceType), errors.Is(err, service.ErrInvalidAction),
+		errors.Is(err, pagination.ErrInvalidCursor), errors.As(err, &syntaxErr):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// moderationError 举报和审核评论的错误响应，评论不存在时返回 404，理由或操作无效时返回 400
+func moderationError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrCommentNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidReport), errors.Is(err, service.ErrInvalidAction):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
+
+// contentError 发表评论和提交内容的错误响应，内容含有被拒绝的敏感词时返回 400
+func contentError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrSensitiveContent) {
+		response.Error(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, err.Error())
+}
+
+// auditContext 带上当前管理员、IP 和请求 ID 的 ctx，管理员操作以此记入审计日志
+func auditContext(c *gin.Context) context.Context {
+	return service.WithAuditActor(c.Request.Context(), model.AuditActor{
+		UserID:    c.GetInt("userID"),
+		Name:      c.GetString("username"),
+		IP:        c.ClientIP(),
+		RequestID: c.GetString("requestID"),
+	})
+}
+
+// visitorKey 浏览去重使用的访客标识：登录用户为用户ID，匿名访客为 IP 与 User-Agent 的摘要
+func visitorKey(c *gin.Context) string {
+	if userID := c.GetInt("userID"); userID > 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	sum := sha256.Sum256([]byte(c.ClientIP() + "|" + c.GetHeader("User-Agent")))
+	return "anon:" + hex.EncodeToString(sum[:])
+}
+
+// viewError 浏览量接口的错误响应，资源不存在或已删除时返回 404
+func viewError(c *gin.Context, err error) {
+	if errors.Is(err, service.ErrResourceNotFound) {
+		response.Error(c, http.StatusNotFound, err.Error())
+		return
+	}
+	response.Error(c, http.StatusInternalServerError, err.Error())
+}
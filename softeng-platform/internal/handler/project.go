@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/service"
 	"softeng-platform/pkg/response"
 	"strconv"
@@ -20,15 +21,14 @@ func NewProjectHandler(projectService service.ProjectService) *ProjectHandler {
 // GetProjects 获取项目列表
 func (h *ProjectHandler) GetProjects(c *gin.Context) {
 	category := c.Query("catagory")
+	// techStack 为技术栈查询表达式，如 techStack=Go AND React
 	techStack := c.QueryArray("techStack")
 	sort := c.Query("sort")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	cursor := c.Query("cursor")
 	resourceType := c.Query("resourceType")
 
-	projects, err := h.projectService.GetProjects(c.Request.Context(), category, techStack, sort, limit, cursor, resourceType)
+	projects, err := h.projectService.GetProjects(c.Request.Context(), category, techStack, sort, pageRequest(c, "limit"), resourceType)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		listError(c, err)
 		return
 	}
 
@@ -39,12 +39,11 @@ func (h *ProjectHandler) GetProjects(c *gin.Context) {
 func (h *ProjectHandler) SearchProjects(c *gin.Context) {
 	keyword := c.Query("keyword")
 	category := c.QueryArray("category")
-	cursor := c.Query("cursor")
-	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	sort := c.Query("sort")
 
-	projects, err := h.projectService.SearchProjects(c.Request.Context(), keyword, category, cursor, limit)
+	projects, err := h.projectService.SearchProjects(c.Request.Context(), keyword, category, sort, pageRequest(c, "limit"))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		listError(c, err)
 		return
 	}
 
@@ -53,7 +52,10 @@ func (h *ProjectHandler) SearchProjects(c *gin.Context) {
 
 // GetProject 获取项目详情
 func (h *ProjectHandler) GetProject(c *gin.Context) {
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
 
 	project, err := h.projectService.GetProject(c.Request.Context(), projectID)
 	if err != nil {
@@ -61,26 +63,37 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 		return
 	}
 
+	setETag(c, project.Data.Version)
 	response.Success(c, project)
 }
 
-// UpdateProject 更新项目
+// UpdateProject 更新项目，需要在 If-Match 中带上读取时的 ETag，防止覆盖其他作者的修改
 func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
 
-	var req service.ProjectUploadRequest
+	var req model.ProjectUploadRequest
 	if err := c.ShouldBind(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid request data")
 		return
 	}
 
-	result, err := h.projectService.UpdateProject(c.Request.Context(), userID, projectID, req)
+	result, err := h.projectService.UpdateProject(c.Request.Context(), userID, projectID, version, req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		if !preconditionFailed(c, err) {
+			contentError(c, err)
+		}
 		return
 	}
 
+	setETag(c, result.Data.Version)
 	response.Success(c, result)
 }
 
@@ -88,7 +101,7 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 func (h *ProjectHandler) UploadProject(c *gin.Context) {
 	userID := c.GetInt("userID")
 
-	var req service.ProjectUploadRequest
+	var req model.ProjectUploadRequest
 	if err := c.ShouldBind(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid request data")
 		return
@@ -96,7 +109,7 @@ func (h *ProjectHandler) UploadProject(c *gin.Context) {
 
 	result, err := h.projectService.UploadProject(c.Request.Context(), userID, req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -106,7 +119,10 @@ func (h *ProjectHandler) UploadProject(c *gin.Context) {
 // LikeProject 点赞项目
 func (h *ProjectHandler) LikeProject(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
 
 	result, err := h.projectService.LikeProject(c.Request.Context(), userID, projectID)
 	if err != nil {
@@ -120,7 +136,10 @@ func (h *ProjectHandler) LikeProject(c *gin.Context) {
 // UnlikeProject 取消点赞项目
 func (h *ProjectHandler) UnlikeProject(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
 
 	result, err := h.projectService.UnlikeProject(c.Request.Context(), userID, projectID)
 	if err != nil {
@@ -134,7 +153,10 @@ func (h *ProjectHandler) UnlikeProject(c *gin.Context) {
 // AddComment 添加评论
 func (h *ProjectHandler) AddComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
 
 	var req struct {
 		Content string `form:"content" json:"content" binding:"required"`
@@ -148,7 +170,7 @@ func (h *ProjectHandler) AddComment(c *gin.Context) {
 
 	result, err := h.projectService.AddComment(c.Request.Context(), userID, projectID, req.Content)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -158,9 +180,16 @@ func (h *ProjectHandler) AddComment(c *gin.Context) {
 // DeleteComment 删除评论
 func (h *ProjectHandler) DeleteComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
+	commentID, ok := queryID(c, "commentId")
+	if !ok {
+		return
+	}
 
-	result, err := h.projectService.DeleteComment(c.Request.Context(), userID, projectID)
+	result, err := h.projectService.DeleteComment(c.Request.Context(), userID, projectID, commentID)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -172,8 +201,14 @@ func (h *ProjectHandler) DeleteComment(c *gin.Context) {
 // ReplyComment 回复评论
 func (h *ProjectHandler) ReplyComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
-	commentID := c.Param("commentId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
+	commentID, ok := paramID(c, "commentId")
+	if !ok {
+		return
+	}
 
 	var req struct {
 		Content string `form:"content" json:"content" binding:"required"`
@@ -187,7 +222,7 @@ func (h *ProjectHandler) ReplyComment(c *gin.Context) {
 
 	result, err := h.projectService.ReplyComment(c.Request.Context(), userID, projectID, commentID, req.Content)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -197,8 +232,14 @@ func (h *ProjectHandler) ReplyComment(c *gin.Context) {
 // DeleteReply 删除回复
 func (h *ProjectHandler) DeleteReply(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
-	commentID := c.Param("commentId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
+	commentID, ok := paramID(c, "commentId")
+	if !ok {
+		return
+	}
 
 	result, err := h.projectService.DeleteReply(c.Request.Context(), userID, projectID, commentID)
 	if err != nil {
@@ -211,11 +252,31 @@ func (h *ProjectHandler) DeleteReply(c *gin.Context) {
 
 // AddView 增加项目浏览量
 func (h *ProjectHandler) AddView(c *gin.Context) {
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
 
-	result, err := h.projectService.AddView(c.Request.Context(), projectID)
+	result, err := h.projectService.AddView(c.Request.Context(), projectID, visitorKey(c))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		viewError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetDailyViews 获取项目最近 days 天每天的浏览量
+func (h *ProjectHandler) GetDailyViews(c *gin.Context) {
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	result, err := h.projectService.GetDailyViews(c.Request.Context(), projectID, days)
+	if err != nil {
+		viewError(c, err)
 		return
 	}
 
@@ -225,7 +286,10 @@ func (h *ProjectHandler) AddView(c *gin.Context) {
 // CollectProject 收藏项目
 func (h *ProjectHandler) CollectProject(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
 
 	result, err := h.projectService.CollectProject(c.Request.Context(), userID, projectID)
 	if err != nil {
@@ -239,7 +303,10 @@ func (h *ProjectHandler) CollectProject(c *gin.Context) {
 // UncollectProject 取消收藏项目
 func (h *ProjectHandler) UncollectProject(c *gin.Context) {
 	userID := c.GetInt("userID")
-	projectID := c.Param("projectId")
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
 
 	result, err := h.projectService.UncollectProject(c.Request.Context(), userID, projectID)
 	if err != nil {
@@ -249,3 +316,19 @@ func (h *ProjectHandler) UncollectProject(c *gin.Context) {
 
 	response.Success(c, result)
 }
+
+// GetComments 分页获取评论，回复随父评论一起返回
+func (h *ProjectHandler) GetComments(c *gin.Context) {
+	projectID, ok := paramID(c, "projectId")
+	if !ok {
+		return
+	}
+
+	comments, err := h.projectService.GetComments(c.Request.Context(), projectID, pageRequest(c, "limit"))
+	if err != nil {
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, comments)
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler struct {
+	searchService service.SearchService
+}
+
+func NewSearchHandler(searchService service.SearchService) *SearchHandler {
+	return &SearchHandler{searchService: searchService}
+}
+
+// Search 同时检索工具、课程和项目，type、category、tag、techStack、semester 为分面筛选，均可重复
+func (h *SearchHandler) Search(c *gin.Context) {
+	q := model.SearchQuery{
+		Keyword:   c.Query("keyword"),
+		Types:     c.QueryArray("type"),
+		Category:  c.QueryArray("category"),
+		Tags:      c.QueryArray("tag"),
+		TechStack: c.QueryArray("techStack"),
+		Semester:  c.QueryArray("semester"),
+		Sort:      c.Query("sort"),
+	}
+
+	result, err := h.searchService.Search(c.Request.Context(), q, pageRequest(c, "limit"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidResourceType) {
+			response.Error(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// Suggest 检索框输入提示，q 为已输入的前缀，可以是原文、全拼或拼音首字母
+func (h *SearchHandler) Suggest(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	result, err := h.searchService.Suggest(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, result)
+}
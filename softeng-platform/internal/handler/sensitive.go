@@ -0,0 +1,96 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/service"
+	"softeng-platform/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SensitiveWordHandler struct {
+	wordService service.SensitiveWordService
+}
+
+func NewSensitiveWordHandler(wordService service.SensitiveWordService) *SensitiveWordHandler {
+	return &SensitiveWordHandler{wordService: wordService}
+}
+
+// GetWords 列出敏感词表
+func (h *SensitiveWordHandler) GetWords(c *gin.Context) {
+	words, err := h.wordService.List(c.Request.Context())
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "success",
+		"words":   words,
+	})
+}
+
+// AddWord 添加敏感词或修改已有词的等级，立即生效
+func (h *SensitiveWordHandler) AddWord(c *gin.Context) {
+	var req model.SensitiveWordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, http.StatusBadRequest, "Invalid request data")
+		return
+	}
+
+	word, err := h.wordService.Add(auditContext(c), c.GetInt("userID"), req)
+	if err != nil {
+		sensitiveWordError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Sensitive word saved successfully",
+		"word":    word,
+	})
+}
+
+// DeleteWord 删除敏感词，立即生效
+func (h *SensitiveWordHandler) DeleteWord(c *gin.Context) {
+	id, ok := paramID(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.wordService.Delete(auditContext(c), id); err != nil {
+		sensitiveWordError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Sensitive word deleted successfully",
+	})
+}
+
+// ReloadWords 从数据库重新加载敏感词表
+func (h *SensitiveWordHandler) ReloadWords(c *gin.Context) {
+	count, err := h.wordService.Reload(auditContext(c))
+	if err != nil {
+		response.Error(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Sensitive words reloaded successfully",
+		"count":   count,
+	})
+}
+
+// sensitiveWordError 维护敏感词表的错误响应，词不存在时返回 404，词或等级无效时返回 400
+func sensitiveWordError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrSensitiveWordNotFound):
+		response.Error(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidSensitiveWord):
+		response.Error(c, http.StatusBadRequest, err.Error())
+	default:
+		response.Error(c, http.StatusInternalServerError, err.Error())
+	}
+}
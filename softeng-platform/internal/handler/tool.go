@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"softeng-platform/internal/model"
 	"softeng-platform/internal/service"
 	"softeng-platform/pkg/response"
 	"strconv"
@@ -20,14 +21,13 @@ func NewToolHandler(toolService service.ToolService) *ToolHandler {
 // GetTools 获取工具列表
 func (h *ToolHandler) GetTools(c *gin.Context) {
 	category := c.QueryArray("catagory")
-	tags := c.QueryArray("tag")
+	// tag 和 tags 都是标签查询表达式，如 tags=AI AND (免费 OR 开源) -付费
+	tags := append(c.QueryArray("tag"), c.QueryArray("tags")...)
 	sort := c.Query("sort")
-	cursor := c.Query("cursor")
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
 
-	tools, err := h.toolService.GetTools(c.Request.Context(), category, tags, sort, cursor, pageSize)
+	tools, err := h.toolService.GetTools(c.Request.Context(), category, tags, sort, pageRequest(c, "page_size"))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		listError(c, err)
 		return
 	}
 
@@ -37,13 +37,14 @@ func (h *ToolHandler) GetTools(c *gin.Context) {
 // SearchTools 搜索工具
 func (h *ToolHandler) SearchTools(c *gin.Context) {
 	keyword := c.Query("keyword")
-	cursor := c.Query("cursor")
-	pageSize, _ := strconv.Atoi(c.DefaultQuery("page_size", "10"))
+	category := c.QueryArray("catagory")
+	tags := append(c.QueryArray("tag"), c.QueryArray("tags")...)
+	sort := c.Query("sort")
 	resourceType := c.Query("resourceType")
 
-	tools, err := h.toolService.SearchTools(c.Request.Context(), keyword, cursor, pageSize, resourceType)
+	tools, err := h.toolService.SearchTools(c.Request.Context(), keyword, category, tags, sort, pageRequest(c, "page_size"), resourceType)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		listError(c, err)
 		return
 	}
 
@@ -52,7 +53,10 @@ func (h *ToolHandler) SearchTools(c *gin.Context) {
 
 // GetTool 获取工具详情
 func (h *ToolHandler) GetTool(c *gin.Context) {
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
 	resourceType := c.Query("resourceType")
 
 	tool, err := h.toolService.GetTool(c.Request.Context(), resourceID, resourceType)
@@ -61,6 +65,7 @@ func (h *ToolHandler) GetTool(c *gin.Context) {
 		return
 	}
 
+	setETag(c, tool.Data.Version)
 	response.Success(c, tool)
 }
 
@@ -68,7 +73,7 @@ func (h *ToolHandler) GetTool(c *gin.Context) {
 func (h *ToolHandler) SubmitTool(c *gin.Context) {
 	userID := c.GetInt("userID")
 
-	var req service.ToolSubmitRequest
+	var req model.ToolSubmitRequest
 	if err := c.ShouldBind(&req); err != nil {
 		response.Error(c, http.StatusBadRequest, "Invalid request data")
 		return
@@ -76,7 +81,7 @@ func (h *ToolHandler) SubmitTool(c *gin.Context) {
 
 	result, err := h.toolService.SubmitTool(c.Request.Context(), userID, req)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -86,7 +91,10 @@ func (h *ToolHandler) SubmitTool(c *gin.Context) {
 // LikeTool 点赞工具
 func (h *ToolHandler) LikeTool(c *gin.Context) {
 	userID := c.GetInt("userID")
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
 
 	result, err := h.toolService.LikeTool(c.Request.Context(), userID, resourceID)
 	if err != nil {
@@ -100,7 +108,10 @@ func (h *ToolHandler) LikeTool(c *gin.Context) {
 // UnlikeTool 取消点赞工具
 func (h *ToolHandler) UnlikeTool(c *gin.Context) {
 	userID := c.GetInt("userID")
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
 
 	result, err := h.toolService.UnlikeTool(c.Request.Context(), userID, resourceID)
 	if err != nil {
@@ -114,7 +125,10 @@ func (h *ToolHandler) UnlikeTool(c *gin.Context) {
 // CollectTool 收藏工具
 func (h *ToolHandler) CollectTool(c *gin.Context) {
 	userID := c.GetInt("userID")
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
 	resourceType := c.Query("resourceType")
 	
 	// resourceType 是必需的query参数
@@ -135,7 +149,10 @@ func (h *ToolHandler) CollectTool(c *gin.Context) {
 // UncollectTool 取消收藏工具
 func (h *ToolHandler) UncollectTool(c *gin.Context) {
 	userID := c.GetInt("userID")
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
 	resourceType := c.Query("resourceType")
 	
 	// resourceType 是必需的query参数
@@ -156,7 +173,10 @@ func (h *ToolHandler) UncollectTool(c *gin.Context) {
 // AddComment 添加评论
 func (h *ToolHandler) AddComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
 	resourceType := c.Query("resourceType")
 	
 	// resourceType 是必需的query参数
@@ -177,7 +197,7 @@ func (h *ToolHandler) AddComment(c *gin.Context) {
 
 	result, err := h.toolService.AddComment(c.Request.Context(), userID, resourceID, resourceType, req.Content)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -187,9 +207,16 @@ func (h *ToolHandler) AddComment(c *gin.Context) {
 // DeleteComment 删除评论
 func (h *ToolHandler) DeleteComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	commentID, ok := queryID(c, "commentId")
+	if !ok {
+		return
+	}
 
-	result, err := h.toolService.DeleteComment(c.Request.Context(), userID, resourceID)
+	result, err := h.toolService.DeleteComment(c.Request.Context(), userID, resourceID, commentID)
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -201,8 +228,14 @@ func (h *ToolHandler) DeleteComment(c *gin.Context) {
 // ReplyComment 回复评论
 func (h *ToolHandler) ReplyComment(c *gin.Context) {
 	userID := c.GetInt("userID")
-	resourceID := c.Param("resourceId")
-	commentID := c.Param("commentId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	commentID, ok := paramID(c, "commentId")
+	if !ok {
+		return
+	}
 	resourceType := c.Query("resourceType")
 	
 	// resourceType 是必需的query参数
@@ -223,7 +256,7 @@ func (h *ToolHandler) ReplyComment(c *gin.Context) {
 
 	result, err := h.toolService.ReplyComment(c.Request.Context(), userID, resourceID, commentID, resourceType, req.Content)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		contentError(c, err)
 		return
 	}
 
@@ -233,8 +266,14 @@ func (h *ToolHandler) ReplyComment(c *gin.Context) {
 // DeleteReply 删除回复
 func (h *ToolHandler) DeleteReply(c *gin.Context) {
 	userID := c.GetInt("userID")
-	resourceID := c.Param("resourceId")
-	commentID := c.Param("commentId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	commentID, ok := paramID(c, "commentId")
+	if !ok {
+		return
+	}
 
 	result, err := h.toolService.DeleteReply(c.Request.Context(), userID, resourceID, commentID)
 	if err != nil {
@@ -247,13 +286,49 @@ func (h *ToolHandler) DeleteReply(c *gin.Context) {
 
 // AddView 增加浏览量
 func (h *ToolHandler) AddView(c *gin.Context) {
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
 
-	result, err := h.toolService.AddView(c.Request.Context(), resourceID)
+	result, err := h.toolService.AddView(c.Request.Context(), resourceID, visitorKey(c))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		viewError(c, err)
+		return
+	}
+
+	response.Success(c, result)
+}
+
+// GetDailyViews 获取工具最近 days 天每天的浏览量
+func (h *ToolHandler) GetDailyViews(c *gin.Context) {
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	days, _ := strconv.Atoi(c.DefaultQuery("days", "30"))
+
+	result, err := h.toolService.GetDailyViews(c.Request.Context(), resourceID, days)
+	if err != nil {
+		viewError(c, err)
 		return
 	}
 
 	response.Success(c, result)
 }
+
+// GetComments 分页获取评论，回复随父评论一起返回
+func (h *ToolHandler) GetComments(c *gin.Context) {
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+
+	comments, err := h.toolService.GetComments(c.Request.Context(), resourceID, pageRequest(c, "limit"))
+	if err != nil {
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, comments)
+}
@@ -11,11 +11,12 @@ import (
 )
 
 type UserHandler struct {
-	userService service.UserService
+	userService  service.UserService
+	trashService service.TrashService
 }
 
-func NewUserHandler(userService service.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService service.UserService, trashService service.TrashService) *UserHandler {
+	return &UserHandler{userService: userService, trashService: trashService}
 }
 
 // GetProfile 获取个人资料
@@ -66,9 +67,9 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 func (h *UserHandler) GetCollection(c *gin.Context) {
 	userID := c.GetInt("userID")
 
-	collection, err := h.userService.GetCollection(c.Request.Context(), userID)
+	collection, err := h.userService.GetCollection(c.Request.Context(), userID, pageRequest(c, "limit"))
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		listError(c, err)
 		return
 	}
 
@@ -87,7 +88,7 @@ func (h *UserHandler) DeleteCollection(c *gin.Context) {
 		return
 	}
 
-	collection, err := h.userService.DeleteCollection(c.Request.Context(), userID, resourceType, resourceIDInt)
+	collection, err := h.userService.DeleteCollection(c.Request.Context(), userID, resourceType, resourceIDInt, pageRequest(c, "limit"))
 	if err != nil {
 		response.Error(c, http.StatusInternalServerError, err.Error())
 		return
@@ -122,11 +123,18 @@ func (h *UserHandler) GetSummit(c *gin.Context) {
 	response.Success(c, summit)
 }
 
-// UpdateResourceStatus 更新资源状态
+// UpdateResourceStatus 更新资源状态，需要在 If-Match 中带上资源的 ETag
 func (h *UserHandler) UpdateResourceStatus(c *gin.Context) {
 	userID := c.GetInt("userID")
 	resourceType := c.Param("resourceType")
-	resourceID := c.Param("resourceId")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
 
 	var req struct {
 		Action string `form:"action" json:"action" binding:"required"`
@@ -139,15 +147,78 @@ func (h *UserHandler) UpdateResourceStatus(c *gin.Context) {
 		return
 	}
 
-	result, err := h.userService.UpdateResourceStatus(c.Request.Context(), userID, resourceType, resourceID, req.Action, req.State)
+	result, err := h.userService.UpdateResourceStatus(c.Request.Context(), userID, resourceType, resourceID, version, req.Action, req.State)
 	if err != nil {
-		response.Error(c, http.StatusInternalServerError, err.Error())
+		reviewError(c, err)
 		return
 	}
 
+	setETag(c, result.Manipulate.Version)
 	response.Success(c, result)
 }
 
+// GetTrash 获取自己回收站中的资源
+func (h *UserHandler) GetTrash(c *gin.Context) {
+	userID := c.GetInt("userID")
+
+	trash, err := h.trashService.List(c.Request.Context(), userID, pageRequest(c, "limit"))
+	if err != nil {
+		listError(c, err)
+		return
+	}
+
+	response.Success(c, trash)
+}
+
+// RestoreTrash 从回收站恢复自己的资源
+func (h *UserHandler) RestoreTrash(c *gin.Context) {
+	userID := c.GetInt("userID")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.trashService.Restore(c.Request.Context(), userID, c.Param("resourceType"), resourceID, version)
+	if err != nil {
+		trashError(c, err)
+		return
+	}
+
+	setETag(c, item.Version)
+	response.Success(c, gin.H{
+		"message": "Resource restored successfully",
+		"item":    item,
+	})
+}
+
+// PurgeTrash 永久删除自己回收站中的资源
+func (h *UserHandler) PurgeTrash(c *gin.Context) {
+	userID := c.GetInt("userID")
+	resourceID, ok := paramID(c, "resourceId")
+	if !ok {
+		return
+	}
+	version, ok := ifMatch(c)
+	if !ok {
+		return
+	}
+
+	item, err := h.trashService.Purge(c.Request.Context(), userID, c.Param("resourceType"), resourceID, version)
+	if err != nil {
+		trashError(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{
+		"message": "Resource purged successfully",
+		"item":    item,
+	})
+}
+
 // UpdateEmail 更新邮箱
 func (h *UserHandler) UpdateEmail(c *gin.Context) {
 	userID := c.GetInt("userID")
@@ -0,0 +1,109 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// listen 在本地端口上接受连接，每个连接交给 serve 处理
+func listen(t *testing.T, serve func(conn net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				serve(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+// fakeSMTP 最简单的 SMTP 服务器，不支持 STARTTLS 和 AUTH，把收到的邮件正文写入 received
+func fakeSMTP(received chan<- string) func(conn net.Conn) {
+	return func(conn net.Conn) {
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch cmd := strings.ToUpper(strings.Fields(line)[0]); cmd {
+			case "EHLO":
+				reply("250 localhost")
+			case "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(line)
+				}
+				received <- data.String()
+				reply("250 queued")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}
+}
+
+func TestSend(t *testing.T) {
+	received := make(chan string, 1)
+	addr := listen(t, fakeSMTP(received))
+	mailer := New(SMTPConfig{Addr: addr, From: "noreply@example.com", Timeout: time.Second})
+	if err := mailer.Send(context.Background(), "amy@example.com", "新资源", "第一行\n第二行"); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if data := <-received; !strings.Contains(data, "To: amy@example.com\r\n") || !strings.HasSuffix(data, "第一行\r\n第二行\r\n") {
+		t.Errorf("received message:\n%s", data)
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	// 接受连接后不再响应的服务器
+	hang := make(chan struct{})
+	t.Cleanup(func() { close(hang) })
+	addr := listen(t, func(conn net.Conn) { <-hang })
+
+	mailer := New(SMTPConfig{Addr: addr, From: "noreply@example.com", Timeout: 200 * time.Millisecond})
+	start := time.Now()
+	err := mailer.Send(context.Background(), "amy@example.com", "subject", "body")
+	if !errors.Is(err, context.DeadlineExceeded) || time.Since(start) > 2*time.Second {
+		t.Errorf("Send to hung server: got %v after %v; want deadline exceeded after about 200ms", err, time.Since(start))
+	}
+
+	// ctx 取消时中断正在进行的读写
+	mailer = New(SMTPConfig{Addr: addr, From: "noreply@example.com", Timeout: time.Minute})
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start = time.Now()
+	err = mailer.Send(ctx, "amy@example.com", "subject", "body")
+	if !errors.Is(err, context.Canceled) || time.Since(start) > 2*time.Second {
+		t.Errorf("Send with canceled ctx: got %v after %v; want canceled", err, time.Since(start))
+	}
+}
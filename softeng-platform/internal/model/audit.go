@@ -0,0 +1,67 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 审计日志的操作，格式为“类别.动作”，按类别检索时匹配该类别下的全部动作
+const (
+	AuditReviewPrefix  = "review."  // 审核资源，后接 approve/reject/hide
+	AuditCommentPrefix = "comment." // 处理被举报的评论，后接 dismiss/hide/delete/warn
+
+	AuditReviewClaim   = "review.claim"   // 认领或续期待审核资源
+	AuditReviewRelease = "review.release" // 放弃自己的认领
+	AuditReviewAssign  = "review.assign"  // 把待审核资源轮流分配给各管理员
+
+	AuditResourceDelete  = "resource.delete"  // 管理员把资源移入回收站
+	AuditResourceRestore = "resource.restore" // 管理员从回收站恢复资源
+	AuditResourcePurge   = "resource.purge"   // 管理员永久删除资源
+
+	AuditSensitiveWordSave   = "sensitive_word.save"   // 添加敏感词或修改等级
+	AuditSensitiveWordDelete = "sensitive_word.delete" // 删除敏感词
+	AuditSensitiveWordReload = "sensitive_word.reload" // 重新加载敏感词表
+)
+
+// 审计日志中除资源类型之外的目标类型
+const (
+	AuditTargetComment       = "comment"
+	AuditTargetSensitiveWord = "sensitive_word"
+	AuditTargetReviewQueue   = "review_queue" // 轮流分配时的整个审核队列
+)
+
+// AuditActor 发起操作的管理员及请求信息，由处理器放入 ctx
+type AuditActor struct {
+	UserID    int
+	Name      string
+	IP        string
+	RequestID string
+}
+
+// AuditLog 一条审计日志。before、after 为操作前后目标的快照（JSON），新建时 before 为 null，删除时 after 为 null
+type AuditLog struct {
+	ID         int             `json:"id"`
+	ActorID    int             `json:"actorId"`
+	Actor      string          `json:"actor"` // 操作时的用户名，用户删除后仍保留
+	Action     string          `json:"action"`
+	TargetType string          `json:"targetType"`
+	TargetID   int             `json:"targetId,omitempty"`
+	Before     json.RawMessage `json:"before"`
+	After      json.RawMessage `json:"after"`
+	IP         string          `json:"ip"`
+	RequestID  string          `json:"requestId"`
+	CreatedAt  string          `json:"createdAt"`
+
+	CreatedTime time.Time `json:"-"`
+}
+
+// AuditFilter 审计日志的检索条件，零值表示不限。时间范围为 [From, To)
+type AuditFilter struct {
+	ActorID    int
+	Actor      string // 按操作时的用户名
+	Action     string // 完整的操作，或类别（如 review）
+	TargetType string
+	TargetID   int
+	From       time.Time
+	To         time.Time
+}
@@ -1,5 +1,84 @@
 package model
 
+import "time"
+
+// 资源类型，对应 likes/collections/comments 等多态表中的 resource_type 列
+const (
+	ResourceTypeTool    = "tool"
+	ResourceTypeCourse  = "course"
+	ResourceTypeProject = "project"
+
+	// 课程资源（网页链接 / 上传文件）分别存放在两张表中，审核时需要区分
+	ResourceTypeCourseWeb    = "course_web"
+	ResourceTypeCourseUpload = "course_upload"
+)
+
+// 审核状态
+const (
+	StatusPending  = "pending"
+	StatusApproved = "approved"
+	StatusRejected = "rejected"
+	// StatusHidden 审核通过后被管理员隐藏，不再出现在列表和检索中
+	StatusHidden = "hidden"
+	// StatusResubmitted 被拒绝后由作者重新提交，与 pending 一起进入审核队列
+	StatusResubmitted = "resubmitted"
+
+	// StatusDeleted 资源进入回收站，只出现在状态变更记录中，不写入 status 列
+	StatusDeleted = "deleted"
+)
+
+// InReviewQueue 资源是否在等待审核：新提交的和被拒绝后重新提交的
+func InReviewQueue(status string) bool {
+	return status == StatusPending || status == StatusResubmitted
+}
+
+// ReviewVersioned 审核目标是否有版本号：工具和项目有，课程资源没有，审核时不需要 If-Match
+func ReviewVersioned(resourceType string) bool {
+	return resourceType == ResourceTypeTool || resourceType == ResourceTypeProject
+}
+
+// PageInfo 键集分页信息。next_cursor 原样传回即可获取下一页，为空表示没有下一页；
+// total 仅在请求 with_total 时返回
+type PageInfo struct {
+	NextCursor string `json:"next_cursor"`
+	HasMore    bool   `json:"has_more"`
+	Total      *int   `json:"total,omitempty"`
+}
+
+// ListResponse 通用列表响应，分页列表带有分页信息
+type ListResponse[T any] struct {
+	Message    string `json:"message"`
+	Data       []T    `json:"data"`
+	DidYouMean string `json:"didYouMean,omitempty"` // 仅检索，含义同 SearchResponse
+	*PageInfo
+}
+
+// DataResponse 通用单对象响应
+type DataResponse[T any] struct {
+	Message string `json:"message"`
+	Data    T      `json:"data"`
+}
+
+// NewListResponse 构造列表响应，保证 data 在 JSON 中为 [] 而不是 null
+func NewListResponse[T any](message string, data []T) *ListResponse[T] {
+	if data == nil {
+		data = []T{}
+	}
+	return &ListResponse[T]{Message: message, Data: data}
+}
+
+// NewPageResponse 构造分页列表响应
+func NewPageResponse[T any](message string, data []T, info PageInfo) *ListResponse[T] {
+	resp := NewListResponse(message, data)
+	resp.PageInfo = &info
+	return resp
+}
+
+// NewDataResponse 构造单对象响应
+func NewDataResponse[T any](message string, data T) *DataResponse[T] {
+	return &DataResponse[T]{Message: message, Data: data}
+}
+
 type ResourceWeb struct {
 	ResourceIntro string `json:"resource_intro"`
 	ResourceURL   string `json:"resource_url"`
@@ -13,13 +92,14 @@ type ResourceUpload struct {
 }
 
 type ResourceReview struct {
-	ResourceID   int    `json:"resourceId"`
-	ResourceType string `json:"resourceType"`
-	Resource     string `json:"resource"`
-	AuditStatus  string `json:"auditStatus"`
-	SubmitTime   string `json:"submitTime"`
-	AuditTime    string `json:"auditTime"`
-	RejectReason string `json:"rejectReason"`
+	ResourceID   int     `json:"resourceId"`
+	ResourceType string  `json:"resourceType"`
+	Resource     string  `json:"resource"`
+	AuditStatus  string  `json:"auditStatus"`
+	SubmitTime   string  `json:"submitTime"`
+	AuditTime    *string `json:"auditTime"`
+	RejectReason *string `json:"rejectReason"`
+	Version      int     `json:"version,omitempty"` // 修改资源后的版本号，提交时为空
 }
 
 type ResourcePersonal struct {
@@ -38,6 +118,111 @@ type Maneuver struct {
 	OldStatus    string `json:"oldestatus"`
 	OperateTime  string `json:"operateTime"`
 	Operator     string `json:"operator"`
+	Version      int    `json:"version"` // 操作后资源的版本号
+}
+
+// ManeuverResponse 资源状态变更响应
+type ManeuverResponse struct {
+	Message    string   `json:"message"`
+	Manipulate Maneuver `json:"manipulate"`
+}
+
+// ReviewState 审核目标（工具、项目、课程资源）的当前审核状态
+type ReviewState struct {
+	ResourceID   int    `json:"resourceId"`
+	ResourceType string `json:"resourceType"`
+	ResourceName string `json:"resourcename"`
+	AuditStatus  string `json:"auditStatus"`
+	RejectReason string `json:"rejectReason,omitempty"`
+	Version      int    `json:"version"` // 课程资源没有版本号，为 0
+}
+
+// 待审核列表按认领情况筛选
+const (
+	ClaimUnclaimed = "unclaimed" // 没有人认领或认领已过期
+	ClaimMine      = "mine"      // 当前管理员认领的
+	ClaimAssigned  = "assigned"  // 分配给当前管理员的
+)
+
+// PendingFilter 待审核列表的筛选条件，Claim 为空时不筛选
+type PendingFilter struct {
+	Claim      string
+	ReviewerID int
+}
+
+// ReviewTarget 一个审核目标（工具、项目或课程资源）
+type ReviewTarget struct {
+	ResourceType string `json:"resourceType"`
+	ResourceID   int    `json:"resourceId"`
+}
+
+// ReviewClaim 审核目标的分配和认领情况；认领过期后不再返回认领人
+type ReviewClaim struct {
+	ReviewTarget
+	AssigneeID int    `json:"assigneeId,omitempty"`
+	Assignee   string `json:"assignee,omitempty"`
+	ClaimantID int    `json:"claimantId,omitempty"`
+	Claimant   string `json:"claimant,omitempty"`
+	ExpiresAt  string `json:"expiresAt,omitempty"` // 认领到期时间
+
+	ExpiresTime time.Time `json:"-"`
+}
+
+// StatusLog 一条资源状态变更记录
+type StatusLog struct {
+	OldStatus   string `json:"oldstatus"`
+	NewStatus   string `json:"newstatus"`
+	Operator    string `json:"operator"`
+	OperateTime string `json:"operateTime"`
+}
+
+// BulkReviewRequest 批量审核请求：对每一项执行同一个 action（approve 或 reject），
+// 拒绝时项目自己的理由优先，没有时使用共用的 rejectReason
+type BulkReviewRequest struct {
+	Action       string           `json:"action" binding:"required"`
+	RejectReason string           `json:"rejectReason"`
+	Items        []BulkReviewItem `json:"items" binding:"required,min=1,dive"`
+}
+
+// BulkReviewItem 批量审核中的一项，工具和项目的 version 必填，含义同 If-Match，为 0 时相当于 * 不检查；
+// 课程资源没有版本号，忽略 version
+type BulkReviewItem struct {
+	ResourceType string `json:"resourceType" binding:"required"`
+	ResourceID   int    `json:"resourceId" binding:"required,min=1"`
+	Version      *int   `json:"version"`
+	RejectReason string `json:"rejectReason"`
+}
+
+// BulkReviewResult 批量审核中一项的结果，status 为单独审核该项时的 HTTP 状态码
+type BulkReviewResult struct {
+	ResourceType string    `json:"resourceType"`
+	ResourceID   int       `json:"resourceId"`
+	Status       int       `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	Manipulate   *Maneuver `json:"manipulate,omitempty"`
+
+	Err error `json:"-"` // 失败原因，由处理器转换为 status 和 error
+}
+
+// BulkReviewResponse 批量审核的结果，results 与请求中的 items 一一对应
+type BulkReviewResponse struct {
+	Succeeded int                `json:"succeeded"`
+	Failed    int                `json:"failed"`
+	Results   []BulkReviewResult `json:"results"`
+}
+
+// TrashItem 回收站中的资源；版本冲突时也用于描述未删除资源的当前状态，此时删除相关字段为空
+type TrashItem struct {
+	ResourceID   int    `json:"resourceId"`
+	ResourceType string `json:"resourceType"`
+	ResourceName string `json:"resourcename"`
+	AuditStatus  string `json:"auditStatus"` // 删除前的审核状态，恢复后不变；课程没有审核流程，为 approved
+	DeletedAt    string `json:"deletedAt"`
+	DeletedBy    string `json:"deletedBy"`
+	PurgeAt      string `json:"purgeAt"` // 超过保留期后将被永久删除的时间
+	Version      int    `json:"version"`
+
+	DeletedTime time.Time `json:"-"` // 原始删除时间，用于计算 PurgeAt
 }
 
 type Submit struct {
@@ -51,15 +236,109 @@ type Submit struct {
 	Description  string   `json:"description"`
 	Tags         []string `json:"tags"`
 	ResourceName string   `json:"resourcename"`
+
+	// Report 评论审核队列中被举报评论的详情，此时 tags 为举报理由
+	Report *ReportedComment `json:"report,omitempty"`
+	// Claim 待审核资源的分配和认领情况，没有分配也没有人认领时为空
+	Claim *ReviewClaim `json:"claim,omitempty"`
+}
+
+// PendingList 待审核列表
+type PendingList struct {
+	PageInfo
+	Data []Submit `json:"data"`
 }
 
+// Comment 评论（工具/课程/项目共用）
 type Comment struct {
 	CommentID   int       `json:"comment_Id"`
+	ParentID    *int      `json:"commentId"`
 	Nickname    string    `json:"nickname"`
 	Avatar      string    `json:"avater"`
 	Comment     string    `json:"comment"`
 	CommentDate string    `json:"commentDate"`
+	DeleteDate  string    `json:"delete_Date,omitempty"`
 	LoveCount   int       `json:"love_count"`
+	IsOwner     *bool     `json:"isowner"`
+	IsReply     bool      `json:"isreply"`
 	ReplyTotal  int       `json:"reply_total"`
 	Replies     []Comment `json:"replies"`
 }
+
+// LikeStatus 点赞状态
+type LikeStatus struct {
+	IsLiked bool `json:"isliked"`
+	Likes   int  `json:"likes"`
+}
+
+// CollectStatus 收藏状态
+type CollectStatus struct {
+	IsCollected bool `json:"iscollected"`
+	Collections int  `json:"collections"`
+}
+
+// ViewCount 浏览量
+type ViewCount struct {
+	Views int `json:"views"`
+}
+
+// Textbook 课本下载内容
+type Textbook struct {
+	Message string `json:"message"`
+	Content string `json:"content"`
+}
+
+// DailyViews 资源某一天的浏览量
+type DailyViews struct {
+	Date  string `json:"date"`
+	Views int    `json:"views"`
+}
+
+// CounterDrift 计数列与来源表统计结果不一致的一行
+type CounterDrift struct {
+	Table  string `json:"table"`
+	ID     int    `json:"id"`
+	Column string `json:"column"`
+	Stored int    `json:"stored"`
+	Actual int    `json:"actual"`
+}
+
+// OrphanRows 多态表中指向不存在资源的行，按资源汇总
+type OrphanRows struct {
+	Table        string `json:"table"`
+	ResourceType string `json:"resource_type"`
+	ResourceID   int    `json:"resource_id"`
+	Rows         int    `json:"rows"`
+}
+
+// ReconcileReport 一次计数校对的结果，Fixed 为 true 时已修正计数并删除孤儿行
+type ReconcileReport struct {
+	Drifts  []CounterDrift `json:"drifts"`
+	Orphans []OrphanRows   `json:"orphans"`
+	Fixed   bool           `json:"fixed"`
+}
+
+// DBStats 数据库健康状态与连接池统计（对应 sql.DBStats），用于按实际流量调整连接池大小
+type DBStats struct {
+	Status     string  `json:"status"` // ok 或 unavailable
+	Driver     string  `json:"driver"`
+	PingMillis float64 `json:"ping_ms"`
+	Error      string  `json:"error,omitempty"`
+
+	// 生效的连接池配置
+	MaxOpenConnections int    `json:"max_open_connections"`
+	MaxIdleConnections int    `json:"max_idle_connections"`
+	ConnMaxLifetime    string `json:"conn_max_lifetime"`
+	ConnMaxIdleTime    string `json:"conn_max_idle_time"`
+	QueryTimeout       string `json:"query_timeout"`
+
+	// 连接池当前状态与累计值
+	OpenConnections   int     `json:"open_connections"`
+	InUse             int     `json:"in_use"`
+	Idle              int     `json:"idle"`
+	WaitCount         int64   `json:"wait_count"`
+	WaitMillis        float64 `json:"wait_ms"`
+	MaxIdleClosed     int64   `json:"max_idle_closed"`
+	MaxIdleTimeClosed int64   `json:"max_idle_time_closed"`
+	MaxLifetimeClosed int64   `json:"max_lifetime_closed"`
+}
@@ -12,13 +12,20 @@ type Course struct {
 	Views        int      `json:"views"`
 	Loves        int      `json:"loves"`
 	Collections  int      `json:"collections"`
+	Version      int      `json:"version"`
+
+	Highlights *Highlights `json:"highlights,omitempty"` // 仅检索结果
 }
 
 type CourseDetail struct {
 	CourseID     int              `json:"courseId"`
 	ResourceType string           `json:"resourceType"`
 	Name         string           `json:"name"`
-	Category     string           `json:"catagory"`
+	Teacher      []string         `json:"teacher"`
+	Category     []string         `json:"category"`
+	Semester     string           `json:"semester"`
+	Credit       int              `json:"credit"`
+	Cover        string           `json:"cover"`
 	URLForm      []ResourceWeb    `json:"url_form"`
 	UploadForm   []ResourceUpload `json:"upload_form"`
 	Contributor  []string         `json:"contributor"`
@@ -28,33 +35,20 @@ type CourseDetail struct {
 	IsLiked      bool             `json:"isliked"`
 	IsCollected  bool             `json:"iscollected"`
 	CommentTotal int              `json:"comment_total"`
-	Comments     []CourseComment  `json:"comments"`
+	Comments     []Comment        `json:"comments"`
 	CreatedAt    string           `json:"createdAt"`
-}
-
-type CourseComment struct {
-	CommentID   int             `json:"comment_Id"`
-	CommentIDP  *int            `json:"commentId"`
-	Nickname    string          `json:"nickname"`
-	Avatar      string          `json:"avater"`
-	Comment     string          `json:"comment"`
-	CommentDate string          `json:"commentDate"`
-	LoveCount   int             `json:"love_count"`
-	IsOwner     *bool           `json:"isowner"`
-	IsReply     bool            `json:"isreply"`
-	ReplyTotal  int             `json:"reply_total"`
-	Replies     []CourseComment `json:"replies"`
+	Version      int              `json:"version"`
 }
 
 type TeachReview struct {
-	ResourceID   int            `json:"resourceId"`
-	ResourceType string         `json:"resourceType"`
-	Resource1    *ResourceWeb   `json:"resource1"`
-	Resource2    ResourceUpload `json:"resource2"`
-	AuditStatus  string         `json:"auditStatus"`
-	SubmitTime   string         `json:"submitTime"`
-	AuditTime    string         `json:"auditTime"`
-	RejectReason string         `json:"rejectReason"`
+	ResourceID   int             `json:"resourceId"`
+	ResourceType string          `json:"resourceType"`
+	Resource1    *ResourceWeb    `json:"resource1"`
+	Resource2    *ResourceUpload `json:"resource2"`
+	AuditStatus  string          `json:"auditStatus"`
+	SubmitTime   string          `json:"submitTime"`
+	AuditTime    *string         `json:"auditTime"`
+	RejectReason *string         `json:"rejectReason"`
 }
 
 type TeachPersonal struct {
@@ -65,3 +59,25 @@ type TeachPersonal struct {
 	Introduce    string `json:"introduce"`
 	Contributer  []User `json:"contributer"`
 }
+
+// CourseList 课程列表响应
+type CourseList struct {
+	Message    string   `json:"message"`
+	CoursesAgg []Course `json:"courses_agg"`
+	DidYouMean string   `json:"didYouMean,omitempty"` // 仅检索，含义同 SearchResponse
+	PageInfo
+}
+
+// CourseDetailResponse 课程详情响应
+type CourseDetailResponse struct {
+	Message string         `json:"message"`
+	Courses []CourseDetail `json:"courses"`
+}
+
+// CourseUploadRequest 课程资源上传请求
+type CourseUploadRequest struct {
+	File        string   `form:"file" json:"file"`
+	Resource    string   `form:"resource" json:"resource"`
+	Description string   `form:"description" json:"description" binding:"required"`
+	Tags        []string `form:"tags" json:"tags"`
+}
@@ -0,0 +1,84 @@
+package model
+
+import "time"
+
+// 评论的举报理由
+const (
+	ReportSpam    = "spam"    // 广告、刷屏
+	ReportAbuse   = "abuse"   // 辱骂、人身攻击
+	ReportIllegal = "illegal" // 违法违规内容
+	ReportOther   = "other"
+
+	// ReportSensitive 系统标记：评论命中 mild 等级的敏感词，用户不能选择
+	ReportSensitive = "sensitive"
+)
+
+// IsReportReason 用户可以选择的举报理由
+func IsReportReason(reason string) bool {
+	switch reason {
+	case ReportSpam, ReportAbuse, ReportIllegal, ReportOther:
+		return true
+	}
+	return false
+}
+
+// 审核队列中评论的处理方式
+const (
+	ModerationDismiss = "dismiss" // 驳回举报，评论恢复显示
+	ModerationHide    = "hide"    // 隐藏评论
+	ModerationDelete  = "delete"  // 删除评论
+	ModerationWarn    = "warn"    // 隐藏评论并警告作者
+)
+
+// CommentReportRequest 举报评论的请求
+type CommentReportRequest struct {
+	Reason string `json:"reason" binding:"required"`
+	Detail string `json:"detail" binding:"max=500"`
+}
+
+// ModerateCommentRequest 处理被举报评论的请求，note 会附在发给作者的通知中
+type ModerateCommentRequest struct {
+	Action string `json:"action" binding:"required"`
+	Note   string `json:"note" binding:"max=500"`
+}
+
+// ReportedComment 审核队列中的一条评论及其待处理举报的汇总
+type ReportedComment struct {
+	CommentID    int            `json:"commentId"`
+	ResourceType string         `json:"resourceType"` // 评论所在的资源
+	ResourceID   int            `json:"resourceId"`
+	AuthorID     int            `json:"-"`
+	Author       string         `json:"author"`
+	Content      string         `json:"content"`
+	CommentDate  string         `json:"commentDate"`
+	Hidden       bool           `json:"hidden"`
+	Reports      int            `json:"reports"`    // 待处理的举报数，含系统标记
+	Reasons      map[string]int `json:"reasons"`    // 各举报理由的次数
+	ReportedAt   string         `json:"reportedAt"` // 最早一条待处理举报的时间
+
+	ReportedTime time.Time `json:"-"`
+}
+
+// ModerationResult 处理被举报评论的结果
+type ModerationResult struct {
+	CommentID int    `json:"commentId"`
+	Action    string `json:"action"`
+	Resolved  int    `json:"resolved"` // 一起处理掉的举报数
+	Hidden    bool   `json:"hidden"`
+	Deleted   bool   `json:"deleted"`
+}
+
+// SensitiveWord 敏感词表中的一个词，level 为 mild、middle 或 severe
+type SensitiveWord struct {
+	ID        int    `json:"id"`
+	Word      string `json:"word"`
+	Level     string `json:"level"`
+	CreatedBy int    `json:"-"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// SensitiveWordRequest 添加敏感词的请求，词已存在时修改其等级
+type SensitiveWordRequest struct {
+	Word  string `json:"word" binding:"required,max=100"`
+	Level string `json:"level" binding:"required"`
+}
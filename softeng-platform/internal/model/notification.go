@@ -0,0 +1,70 @@
+package model
+
+import "time"
+
+// 通知的送达方式，也是已保存检索可选的提醒方式
+const (
+	ChannelInApp = "in_app"
+	ChannelEmail = "email"
+)
+
+// 通知类型
+const (
+	NotificationSavedSearch = "saved_search"       // 已保存的检索有新结果
+	NotificationModeration  = "comment_moderation" // 自己的评论被隐藏或审核处理
+)
+
+// SavedQuery 已保存的检索条件，字段与 /search 的参数相同。tag、techStack 为标签查询表达式，
+// 多个表达式之间为“或”，与 /tools/profile 的 tag 参数一致
+type SavedQuery struct {
+	Keyword   string   `json:"keyword,omitempty"`
+	Types     []string `json:"type,omitempty"`
+	Category  []string `json:"category,omitempty"`
+	Tags      []string `json:"tag,omitempty"`
+	TechStack []string `json:"techStack,omitempty"`
+	Semester  []string `json:"semester,omitempty"`
+}
+
+// IsEmpty 没有任何检索条件
+func (q SavedQuery) IsEmpty() bool {
+	return q.Keyword == "" && len(q.Types) == 0 && len(q.Category) == 0 &&
+		len(q.Tags) == 0 && len(q.TechStack) == 0 && len(q.Semester) == 0
+}
+
+// SavedSearch 用户保存的检索，之后审核通过（课程为新导入）的资源满足条件时提醒用户
+type SavedSearch struct {
+	ID             int        `json:"id"`
+	UserID         int        `json:"-"`
+	Name           string     `json:"name"`
+	Query          SavedQuery `json:"query"`
+	Channel        string     `json:"channel"`
+	CreatedAt      string     `json:"createdAt"`
+	LastNotifiedAt *string    `json:"lastNotifiedAt"`
+
+	// CheckedAt 已检查到的审核时间，此后审核通过的资源还没有提醒过
+	CheckedAt time.Time `json:"-"`
+}
+
+// SavedSearchRequest 保存检索的请求，channel 为空时站内提醒
+type SavedSearchRequest struct {
+	Name    string     `json:"name" binding:"required,max=100"`
+	Query   SavedQuery `json:"query"`
+	Channel string     `json:"channel"`
+}
+
+// Notification 一条站内通知。邮件提醒同样在站内保留一份，channel 为 email
+type Notification struct {
+	ID           int    `json:"id"`
+	UserID       int    `json:"-"`
+	Kind         string `json:"kind"`
+	Title        string `json:"title"`
+	Content      string `json:"content"`
+	Channel      string `json:"channel"`
+	ResourceType string `json:"resourceType,omitempty"` // 通知只涉及一个资源时指向该资源
+	ResourceID   int    `json:"resourceId,omitempty"`
+	RefID        int    `json:"refId,omitempty"` // 关联对象，如已保存检索的ID
+	IsRead       bool   `json:"isRead"`
+	CreatedAt    string `json:"createdAt"`
+
+	CreatedTime time.Time `json:"-"`
+}
@@ -10,42 +10,44 @@ type Project struct {
 	LikeCount    int      `json:"likecount"`
 	AuthorName   []string `json:"authername"`
 	Cover        string   `json:"cover"`
-	CreatedAt    string   `json:"createdat"`
+	CreatedAt    string   `json:"createdAt"`
 	Loves        int      `json:"loves"`
 	Collections  int      `json:"collections"`
 	Views        int      `json:"views"`
+
+	Highlights *Highlights `json:"highlights,omitempty"` // 仅检索结果
 }
 
 type ProjectDetail struct {
-	ProjectID    int              `json:"projectId"`
-	ResourceType string           `json:"resourceType"`
-	Name         string           `json:"name"`
-	Description  string           `json:"description"`
-	GithubURL    string           `json:"githubURL"`
-	TechStack    []string         `json:"techStack"`
-	Category     string           `json:"catagory"`
-	Images       []string         `json:"images"`
-	Likes        int              `json:"likes"`
-	Views        int              `json:"views"`
-	Collections  int              `json:"collections"`
-	IsLiked      bool             `json:"isliked"`
-	IsCollected  bool             `json:"iscollected"`
-	Author       []string         `json:"author"`
-	CommentCount int              `json:"comment_count"`
-	Comments     []ProjectComment `json:"comments"`
-	CreatedAt    string           `json:"createdAt"`
+	ProjectID    int       `json:"projectId"`
+	ResourceType string    `json:"resourceType"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	Detail       string    `json:"detail"`
+	GithubURL    string    `json:"githubURL"`
+	TechStack    []string  `json:"techStack"`
+	Category     string    `json:"category"`
+	Cover        string    `json:"cover"`
+	Images       []string  `json:"images"`
+	Likes        int       `json:"likes"`
+	Views        int       `json:"views"`
+	Collections  int       `json:"collections"`
+	IsLiked      bool      `json:"isliked"`
+	IsCollected  bool      `json:"iscollected"`
+	Author       []string  `json:"author"`
+	CommentCount int       `json:"comment_count"`
+	Comments     []Comment `json:"comments"`
+	CreatedAt    string    `json:"createdAt"`
+	Version      int       `json:"version"`
 }
 
-type ProjectComment struct {
-	CommentID   int              `json:"comment_Id"`
-	CommentIDP  *int             `json:"commentId"`
-	Nickname    string           `json:"nickname"`
-	Avatar      string           `json:"avater"`
-	Comment     string           `json:"comment"`
-	CommentDate string           `json:"commentDate"`
-	LoveCount   int              `json:"love_count"`
-	IsOwner     *bool            `json:"isowner"`
-	IsReply     bool             `json:"isreply"`
-	ReplyTotal  int              `json:"reply_total"`
-	Replies     []ProjectComment `json:"replies"`
+// ProjectUploadRequest 项目上传请求
+type ProjectUploadRequest struct {
+	Name        string   `form:"name" json:"name" binding:"required"`
+	Description string   `form:"description" json:"description" binding:"required"`
+	Detail      string   `form:"detail" json:"detail" binding:"required"`
+	Github      string   `form:"github" json:"github"`
+	TechStack   []string `form:"techStack" json:"techStack" binding:"required"`
+	Category    string   `form:"catagory" json:"catagory" binding:"required"`
+	Images      []string `form:"images" json:"images"`
 }
@@ -0,0 +1,105 @@
+package model
+
+import "time"
+
+// SearchQuery 统一检索的关键词和筛选条件。同一字段的多个取值为“或”，不同字段之间为“且”；
+// 筛选了某类资源没有的字段（如按标签筛选课程）时该类资源不出现在结果中
+type SearchQuery struct {
+	Keyword   string
+	Types     []string // tool / course / project
+	Category  []string // 工具、项目的分类，课程的类别
+	Tags      []string // 工具标签
+	TechStack []string // 项目技术栈
+	Semester  []string // 课程学期
+	Sort      string   // relevance / latest / views / loves / collections，为空时有关键词按相关度排序
+}
+
+// SearchHit 统一检索的一条结果，resourceType 区分资源类型
+type SearchHit struct {
+	ResourceType string   `json:"resourceType"`
+	ResourceID   int      `json:"resourceId"`
+	Name         string   `json:"name"`
+	Description  string   `json:"description"`
+	Category     []string `json:"category"`
+	Tags         []string `json:"tags"`     // 工具标签 / 项目技术栈 / 课程教师
+	Semester     string   `json:"semester"` // 仅课程
+	Views        int      `json:"views"`
+	Loves        int      `json:"loves"`
+	Collections  int      `json:"collections"`
+	CreatedDate  string   `json:"createdDate"`
+
+	Highlights *Highlights `json:"highlights,omitempty"`
+
+	CreatedAt time.Time `json:"-"`
+	Relevance int64     `json:"-"`
+}
+
+// FacetCount 分面中的一个取值及命中的资源数
+type FacetCount struct {
+	Value string `json:"value"`
+	Count int    `json:"count"`
+}
+
+// SearchFacets 统一检索的分面统计。每个分面按除自身以外的筛选条件统计，
+// 选中某个取值后同一分面的其他取值仍有计数；每个分面最多返回命中数最多的若干个取值
+type SearchFacets struct {
+	Type      []FacetCount `json:"type"`
+	Category  []FacetCount `json:"category"`
+	Tag       []FacetCount `json:"tag"`
+	TechStack []FacetCount `json:"techStack"`
+	Semester  []FacetCount `json:"semester"`
+}
+
+// SearchResponse 统一检索响应
+type SearchResponse struct {
+	Message string        `json:"message"`
+	Data    []SearchHit   `json:"data"`
+	Facets  *SearchFacets `json:"facets,omitempty"` // 只在第一页返回
+	// DidYouMean 第一页没有结果时建议改用的关键词，只在建议的关键词有结果时返回
+	DidYouMean string `json:"didYouMean,omitempty"`
+	PageInfo
+}
+
+// SearchDocument 写入检索索引的一个资源，只有已审核、未删除的资源会被索引
+type SearchDocument struct {
+	SearchHit
+	Detail string
+}
+
+// 输入提示的类型
+const (
+	SuggestionName      = "name"      // 资源名称
+	SuggestionTag       = "tag"       // 工具标签
+	SuggestionTechStack = "techStack" // 项目技术栈
+	SuggestionTeacher   = "teacher"   // 课程教师
+)
+
+// Suggestion 检索框的一条输入提示。名称提示指向一个资源；标签、技术栈、教师提示汇总了
+// 使用它的全部资源，浏览量和点赞数为这些资源之和
+type Suggestion struct {
+	Text         string `json:"text"`
+	Kind         string `json:"kind"`
+	ResourceType string `json:"resourceType"`
+	ResourceID   int    `json:"resourceId,omitempty"` // 仅名称提示
+	Views        int    `json:"views"`
+	Loves        int    `json:"loves"`
+}
+
+// SuggestResponse 输入提示响应
+type SuggestResponse struct {
+	Message string       `json:"message"`
+	Data    []Suggestion `json:"data"`
+}
+
+// HighlightSpan 高亮片段中的一段文本，Match 表示该段命中了检索词
+type HighlightSpan struct {
+	Text  string `json:"text"`
+	Match bool   `json:"match,omitempty"`
+}
+
+// Highlights 检索结果的高亮片段，依次拼接各段即为原文（简介过长时截取命中处附近，两端加省略号）；
+// 只返回命中了检索词的字段
+type Highlights struct {
+	Name        []HighlightSpan `json:"name,omitempty"`
+	Description []HighlightSpan `json:"description,omitempty"`
+}
@@ -19,16 +19,9 @@ type Tool struct {
 	Comments          []Comment `json:"comments"`
 	CreatedDate       string    `json:"createdDate"`
 	Contributors      []string  `json:"contributors"`
-}
+	Version           int       `json:"version"`
 
-type ToolReview struct {
-	ResourceID   int     `json:"resourceId"`
-	ResourceType string  `json:"resourceType"`
-	Resource     string  `json:"resource"`
-	AuditStatus  string  `json:"auditStatus"`
-	SubmitTime   string  `json:"submitTime"`
-	AuditTime    *string `json:"auditTime"`
-	RejectReason *string `json:"rejectReason"`
+	Highlights *Highlights `json:"highlights,omitempty"` // 仅检索结果
 }
 
 type ToolPersonal struct {
@@ -40,16 +33,12 @@ type ToolPersonal struct {
 	Contributer  []User `json:"contributer"`
 }
 
-type ToolComment struct {
-	CommentID   int           `json:"comment_Id"`
-	CommetnID   *int          `json:"commetnId"`
-	Nickname    string        `json:"nickname"`
-	Avatar      string        `json:"avater"`
-	Comment     string        `json:"comment"`
-	CommentDate string        `json:"commentDate"`
-	LoveCount   int           `json:"love_count"`
-	IsOwner     *bool         `json:"isowner"`
-	IsReply     bool          `json:"isreply"`
-	ReplyTotal  int           `json:"reply_total"`
-	Replies     []ToolComment `json:"replies"`
+// ToolSubmitRequest 工具提交请求结构体
+type ToolSubmitRequest struct {
+	Name              string   `form:"name" json:"name" binding:"required"`
+	Link              string   `form:"link" json:"link" binding:"required"`
+	Description       string   `form:"description" json:"description" binding:"required"`
+	DescriptionDetail string   `form:"description_detail" json:"description_detail" binding:"required"`
+	Category          string   `form:"catagory" json:"catagory" binding:"required"`
+	Tags              []string `form:"tags" json:"tags" binding:"required"`
 }
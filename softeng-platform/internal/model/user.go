@@ -37,3 +37,20 @@ type UpdateProfileRequest struct {
 	Description string `form:"description" json:"description"`
 	FacePhoto   string `form:"face_photo" json:"face_photo"`
 }
+
+// UserResources 个人收藏/个人提交列表，个人收藏分页并带有分页信息
+type UserResources struct {
+	Message   string             `json:"message"`
+	Resources []ResourcePersonal `json:"resources"`
+	Tools     []ToolPersonal     `json:"tools"`
+	Teaches   []TeachPersonal    `json:"teaches"`
+	*PageInfo
+}
+
+// UserReviewStatus 个人提交的审核状态
+type UserReviewStatus struct {
+	Message   string           `json:"message"`
+	Resources []ResourceReview `json:"resources"`
+	Tools     []ResourceReview `json:"tools"`
+	Teaches   []TeachReview    `json:"teaches"`
+}
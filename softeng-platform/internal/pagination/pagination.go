@@ -0,0 +1,65 @@
+// Package pagination 列表接口共用的键集分页。
+// 游标中编码上一页最后一条记录的排序键和ID，并带有签名，客户端只能原样传回
+package pagination
+
+import "errors"
+
+// ErrInvalidCursor 游标无法解析、签名不符或与当前列表/排序不匹配
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Key 一条记录在排序中的位置
+type Key struct {
+	Sort  string `json:"s"`           // 排序方式，游标只能用于生成它的排序
+	Value int64  `json:"v"`           // 排序键：计数列的值，或时间列的 UnixNano
+	Kind  string `json:"k,omitempty"` // 多表合并的列表中区分来源表
+	ID    int    `json:"i"`
+}
+
+// Request 接口传入的分页参数
+type Request struct {
+	Cursor    string
+	Limit     int
+	WithTotal bool
+}
+
+// Page 解码后的分页参数，After 为 nil 表示第一页
+type Page struct {
+	After     *Key
+	Limit     int
+	WithTotal bool
+}
+
+// Info 一页的分页信息
+type Info struct {
+	Next    *Key
+	HasMore bool
+	Total   *int // 仅在请求总数时填写
+}
+
+// Result 一页数据
+type Result[T any] struct {
+	Items []T
+	Info
+}
+
+// NewResult 由多取一条的查询结果构造一页：keys 与 items 一一对应，
+// 超出 limit 的那一条只用于判断是否还有下一页
+func NewResult[T any](items []T, keys []Key, limit int) *Result[T] {
+	result := &Result[T]{Items: items}
+	if len(items) > limit {
+		result.Items = items[:limit]
+		result.HasMore = true
+		next := keys[limit-1]
+		result.Next = &next
+	}
+	return result
+}
+
+// Map 转换一页数据的元素类型，分页信息不变
+func Map[T, U any](r *Result[T], fn func(T) U) *Result[U] {
+	items := make([]U, len(r.Items))
+	for i, item := range r.Items {
+		items[i] = fn(item)
+	}
+	return &Result[U]{Items: items, Info: r.Info}
+}
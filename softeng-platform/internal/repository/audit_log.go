@@ -0,0 +1,135 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"time"
+)
+
+// AuditLogRepository 管理员操作的审计日志，只能追加和查询，没有修改和删除
+type AuditLogRepository interface {
+	// Append 追加一条日志，写回 ID 和创建时间
+	Append(ctx context.Context, entry *model.AuditLog) error
+	// List 按时间倒序分页列出满足条件的日志
+	List(ctx context.Context, filter model.AuditFilter, page pagination.Page) (*pagination.Result[model.AuditLog], error)
+}
+
+type auditLogRepository struct {
+	db *Database
+}
+
+func NewAuditLogRepository(db *Database) AuditLogRepository {
+	return &auditLogRepository{db: db}
+}
+
+// auditLogSort 审计日志按时间倒序
+var auditLogSort = keyset{name: "latest", field: byCreatedAt, column: "created_at", idColumn: "id"}
+
+func (r *auditLogRepository) Append(ctx context.Context, entry *model.AuditLog) error {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO admin_audit_logs (actor_id, actor_name, action, target_type, target_id, before_state, after_state, ip, request_id, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		nullIfZero(entry.ActorID), entry.Actor, entry.Action, entry.TargetType, nullIfZero(entry.TargetID),
+		nullIfEmpty(string(entry.Before)), nullIfEmpty(string(entry.After)), entry.IP, entry.RequestID, now)
+	if err != nil {
+		return fmt.Errorf("failed to append audit log: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	entry.ID = int(id)
+	entry.CreatedTime = now
+	entry.CreatedAt = formatTime(now)
+	return nil
+}
+
+// auditLogWhere 把检索条件转换为 WHERE 子句
+func (r *auditLogRepository) auditLogWhere(filter model.AuditFilter) ([]string, []interface{}) {
+	var where []string
+	var args []interface{}
+	if filter.ActorID != 0 {
+		where = append(where, "actor_id = ?")
+		args = append(args, filter.ActorID)
+	}
+	if filter.Actor != "" {
+		where = append(where, "actor_name = ?")
+		args = append(args, filter.Actor)
+	}
+	if filter.Action != "" {
+		where = append(where, "(action = ? OR action LIKE ?)")
+		args = append(args, filter.Action, filter.Action+".%")
+	}
+	if filter.TargetType != "" {
+		where = append(where, "target_type = ?")
+		args = append(args, filter.TargetType)
+	}
+	if filter.TargetID != 0 {
+		where = append(where, "target_id = ?")
+		args = append(args, filter.TargetID)
+	}
+	if !filter.From.IsZero() {
+		where = append(where, r.db.Dialect.TimeKey("created_at")+" >= "+r.db.Dialect.TimeKey("?"))
+		args = append(args, filter.From)
+	}
+	if !filter.To.IsZero() {
+		where = append(where, r.db.Dialect.TimeKey("created_at")+" < "+r.db.Dialect.TimeKey("?"))
+		args = append(args, filter.To)
+	}
+	return where, args
+}
+
+func (r *auditLogRepository) List(ctx context.Context, filter model.AuditFilter, page pagination.Page) (*pagination.Result[model.AuditLog], error) {
+	where, args := r.auditLogWhere(filter)
+	query, queryArgs, err := pageQuery(r.db.Dialect, `SELECT id, actor_id, actor_name, action, target_type, target_id,
+		before_state, after_state, ip, request_id, created_at FROM admin_audit_logs`, where, args, auditLogSort, page)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.AuditLog
+	var keys []pagination.Key
+	for rows.Next() {
+		var entry model.AuditLog
+		var actorID, targetID sql.NullInt64
+		var before, after sql.NullString
+		if err := rows.Scan(&entry.ID, &actorID, &entry.Actor, &entry.Action, &entry.TargetType, &targetID,
+			&before, &after, &entry.IP, &entry.RequestID, &entry.CreatedTime); err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+		entry.ActorID = int(actorID.Int64)
+		entry.TargetID = int(targetID.Int64)
+		entry.Before = rawJSON(before.String)
+		entry.After = rawJSON(after.String)
+		entry.CreatedAt = formatTime(entry.CreatedTime)
+		items = append(items, entry)
+		keys = append(keys, auditLogSort.key(sortValues{createdAt: entry.CreatedTime}, entry.ID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewResult(items, keys, normalizeLimit(page.Limit))
+	if result.Total, err = pageTotal(ctx, r.db, "admin_audit_logs", where, args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// rawJSON 空快照输出为 null
+func rawJSON(s string) json.RawMessage {
+	if s == "" {
+		return json.RawMessage("null")
+	}
+	return json.RawMessage(s)
+}
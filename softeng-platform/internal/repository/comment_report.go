@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"time"
+)
+
+// CommentReportRepository 评论的举报和审核队列。审核时评论的全部待处理举报一起标为已处理，
+// 之后评论可以再次被举报
+type CommentReportRepository interface {
+	// Get 返回评论及其待处理举报的汇总，评论不存在或已删除时返回 nil
+	Get(ctx context.Context, commentID int) (*model.ReportedComment, error)
+	// Report 记录一条举报，userID 为 0 表示系统标记。只能举报未删除、未隐藏的评论，否则返回 nil；
+	// 同一用户（或系统）对该评论已有待处理的举报时不重复记录，返回的 bool 为 false
+	Report(ctx context.Context, commentID, userID int, reason, detail string) (*model.ReportedComment, bool, error)
+	// Hide 隐藏评论，评论不存在、已删除或已隐藏时返回 false
+	Hide(ctx context.Context, commentID int) (bool, error)
+	// Pending 分页列出有待处理举报的未删除评论，按最早一条待处理举报的时间先后
+	Pending(ctx context.Context, page pagination.Page) (*pagination.Result[model.ReportedComment], error)
+	// Resolve 按 action 处理评论并把它的待处理举报标为已处理：dismiss 恢复显示，hide 和 warn 隐藏，
+	// delete 软删除并更新父评论的 reply_total。返回处理前的评论，评论不存在或已删除时返回 nil
+	Resolve(ctx context.Context, commentID, moderatorID int, action string) (*model.ReportedComment, error)
+}
+
+type commentReportRepository struct {
+	db *Database
+}
+
+func NewCommentReportRepository(db *Database) CommentReportRepository {
+	return &commentReportRepository{db: db}
+}
+
+const reportedCommentColumns = `
+	c.comment_id, c.resource_type, c.resource_id, c.user_id, COALESCE(u.nickname, u.username),
+	c.content, c.created_at, c.hidden_at
+`
+
+// pendingReportSort 审核队列按最早一条待处理举报的时间先后，举报ID随时间递增，取 MIN(id) 对应的举报
+var pendingReportSort = keyset{name: "reported", field: byCreatedAt, column: "f.created_at", idColumn: "c.comment_id", asc: true}
+
+// pendingReportFrom 有待处理举报的评论及其作者，f 为其中最早的一条举报。
+// 列表和总数使用同一个 FROM，作者不存在的评论两者都不计
+const pendingReportFrom = `comments c
+	JOIN (SELECT comment_id, MIN(id) AS first_id FROM comment_reports WHERE resolved_at IS NULL GROUP BY comment_id) r
+		ON r.comment_id = c.comment_id
+	JOIN comment_reports f ON f.id = r.first_id
+	JOIN users u ON u.id = c.user_id`
+
+func scanReportedComment(scanner interface{ Scan(...interface{}) error }, extra ...interface{}) (*model.ReportedComment, error) {
+	var comment model.ReportedComment
+	var createdAt time.Time
+	var hiddenAt sql.NullTime
+	dest := append([]interface{}{&comment.CommentID, &comment.ResourceType, &comment.ResourceID, &comment.AuthorID,
+		&comment.Author, &comment.Content, &createdAt, &hiddenAt}, extra...)
+	if err := scanner.Scan(dest...); err != nil {
+		return nil, err
+	}
+	comment.CommentDate = formatTime(createdAt)
+	comment.Hidden = hiddenAt.Valid
+	comment.Reasons = map[string]int{}
+	return &comment, nil
+}
+
+// loadReportReasons 统计各评论待处理举报的理由和数目
+func loadReportReasons(ctx context.Context, db *Database, comments []*model.ReportedComment) error {
+	if len(comments) == 0 {
+		return nil
+	}
+	byID := make(map[int]*model.ReportedComment, len(comments))
+	ids := make([]int, len(comments))
+	for i, c := range comments {
+		byID[c.CommentID] = c
+		ids[i] = c.CommentID
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT comment_id, reason, COUNT(*) FROM comment_reports
+		WHERE resolved_at IS NULL AND comment_id IN (`+placeholders(len(ids))+`)
+		GROUP BY comment_id, reason`, intArgs(ids)...)
+	if err != nil {
+		return fmt.Errorf("failed to count reports: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID, count int
+		var reason string
+		if err := rows.Scan(&commentID, &reason, &count); err != nil {
+			return fmt.Errorf("failed to scan report count: %w", err)
+		}
+		c := byID[commentID]
+		c.Reasons[reason] = count
+		c.Reports += count
+	}
+	return rows.Err()
+}
+
+func (r *commentReportRepository) Get(ctx context.Context, commentID int) (*model.ReportedComment, error) {
+	comment, err := scanReportedComment(r.db.QueryRowContext(ctx, `SELECT `+reportedCommentColumns+`
+		FROM comments c JOIN users u ON u.id = c.user_id
+		WHERE c.comment_id = ? AND c.deleted_at IS NULL`, commentID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	if err := loadReportReasons(ctx, r.db, []*model.ReportedComment{comment}); err != nil {
+		return nil, err
+	}
+	if comment.Reports == 0 {
+		return comment, nil
+	}
+
+	err = r.db.QueryRowContext(ctx, `SELECT created_at FROM comment_reports
+		WHERE comment_id = ? AND resolved_at IS NULL ORDER BY id LIMIT 1`, commentID).Scan(&comment.ReportedTime)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get report time: %w", err)
+	}
+	comment.ReportedAt = formatTime(comment.ReportedTime)
+	return comment, nil
+}
+
+func (r *commentReportRepository) Report(ctx context.Context, commentID, userID int, reason, detail string) (*model.ReportedComment, bool, error) {
+	type reported struct {
+		comment *model.ReportedComment
+		added   bool
+	}
+	result, err := inTx(ctx, r.db, func(ctx context.Context) (reported, error) {
+		// 锁住评论，同一用户并发的举报只记录一条
+		var id int
+		err := r.db.QueryRowContext(ctx, `SELECT comment_id FROM comments
+			WHERE comment_id = ? AND deleted_at IS NULL AND hidden_at IS NULL`+r.db.Dialect.ForUpdate(), commentID).Scan(&id)
+		if err == sql.ErrNoRows {
+			return reported{}, nil
+		}
+		if err != nil {
+			return reported{}, fmt.Errorf("failed to lock comment: %w", err)
+		}
+
+		reporter, args := "user_id = ?", []interface{}{commentID, userID}
+		if userID == 0 {
+			reporter, args = "user_id IS NULL", args[:1]
+		}
+		var count int
+		if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM comment_reports
+			WHERE comment_id = ? AND `+reporter+` AND resolved_at IS NULL`, args...).Scan(&count); err != nil {
+			return reported{}, fmt.Errorf("failed to check report: %w", err)
+		}
+
+		if count == 0 {
+			if _, err := r.db.ExecContext(ctx,
+				`INSERT INTO comment_reports (comment_id, user_id, reason, detail, created_at) VALUES (?, ?, ?, ?, ?)`,
+				commentID, nullIfZero(userID), reason, nullIfEmpty(detail), time.Now(),
+			); err != nil {
+				return reported{}, fmt.Errorf("failed to create report: %w", err)
+			}
+		}
+
+		comment, err := r.Get(ctx, commentID)
+		return reported{comment: comment, added: count == 0}, err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return result.comment, result.added, nil
+}
+
+func (r *commentReportRepository) Hide(ctx context.Context, commentID int) (bool, error) {
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE comments SET hidden_at = ? WHERE comment_id = ? AND deleted_at IS NULL AND hidden_at IS NULL`,
+		time.Now(), commentID)
+	if err != nil {
+		return false, fmt.Errorf("failed to hide comment: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *commentReportRepository) Pending(ctx context.Context, page pagination.Page) (*pagination.Result[model.ReportedComment], error) {
+	where := []string{"c.deleted_at IS NULL"}
+	query, args, err := pageQuery(r.db.Dialect, `SELECT `+reportedCommentColumns+`, f.created_at
+		FROM `+pendingReportFrom, where, nil, pendingReportSort, page)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reported comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*model.ReportedComment
+	for rows.Next() {
+		var reportedAt time.Time
+		comment, err := scanReportedComment(rows, &reportedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan reported comment: %w", err)
+		}
+		comment.ReportedTime = reportedAt
+		comment.ReportedAt = formatTime(reportedAt)
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list reported comments: %w", err)
+	}
+	if err := loadReportReasons(ctx, r.db, comments); err != nil {
+		return nil, err
+	}
+
+	items := make([]model.ReportedComment, len(comments))
+	keys := make([]pagination.Key, len(comments))
+	for i, c := range comments {
+		items[i] = *c
+		keys[i] = pendingReportSort.key(sortValues{createdAt: c.ReportedTime}, c.CommentID)
+	}
+	result := pagination.NewResult(items, keys, normalizeLimit(page.Limit))
+	if result.Total, err = pageTotal(ctx, r.db, pendingReportFrom, where, nil, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *commentReportRepository) Resolve(ctx context.Context, commentID, moderatorID int, action string) (*model.ReportedComment, error) {
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.ReportedComment, error) {
+		before, err := r.Get(ctx, commentID)
+		if err != nil || before == nil {
+			return nil, err
+		}
+
+		now := time.Now()
+		switch action {
+		case model.ModerationDismiss:
+			_, err = r.db.ExecContext(ctx, `UPDATE comments SET hidden_at = NULL WHERE comment_id = ?`, commentID)
+		case model.ModerationHide, model.ModerationWarn:
+			_, err = r.db.ExecContext(ctx, `UPDATE comments SET hidden_at = ? WHERE comment_id = ? AND hidden_at IS NULL`, now, commentID)
+		case model.ModerationDelete:
+			err = r.delete(ctx, commentID, now)
+		default:
+			return nil, fmt.Errorf("unknown moderation action: %s", action)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to %s comment: %w", action, err)
+		}
+
+		if _, err := r.db.ExecContext(ctx,
+			`UPDATE comment_reports SET resolved_at = ?, resolution = ?, resolved_by = ? WHERE comment_id = ? AND resolved_at IS NULL`,
+			now, action, nullIfZero(moderatorID), commentID,
+		); err != nil {
+			return nil, fmt.Errorf("failed to resolve reports: %w", err)
+		}
+		return before, nil
+	})
+}
+
+// delete 软删除评论，与作者自己删除相同，父评论的 reply_total 减一
+func (r *commentReportRepository) delete(ctx context.Context, commentID int, now time.Time) error {
+	comment, err := getComment(ctx, r.db, commentID)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.ExecContext(ctx, `UPDATE comments SET deleted_at = ? WHERE comment_id = ? AND deleted_at IS NULL`, now, commentID); err != nil {
+		return err
+	}
+	if comment.ParentID != nil {
+		_, err = r.db.ExecContext(ctx,
+			`UPDATE comments SET reply_total = CASE WHEN reply_total > 0 THEN reply_total - 1 ELSE 0 END WHERE comment_id = ?`,
+			*comment.ParentID,
+		)
+	}
+	return err
+}
@@ -0,0 +1,611 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"strings"
+	"time"
+)
+
+// timeLayout 接口中时间字段统一使用的格式
+const timeLayout = "2006-01-02 15:04:05"
+
+// resourceTable 资源类型对应的主表及主键列
+type resourceTable struct {
+	table      string
+	idColumn   string
+	nameColumn string
+	// statusColumn 审核状态列，课程没有审核流程，为空
+	statusColumn string
+	// ownerTable 贡献者/作者表，ownerColumn 为其中指向资源的列，表中的用户可以删除和恢复该资源
+	ownerTable  string
+	ownerColumn string
+}
+
+var resourceTables = map[string]resourceTable{
+	model.ResourceTypeTool: {
+		table: "tools", idColumn: "resource_id", nameColumn: "resource_name", statusColumn: "status",
+		ownerTable: "tool_contributors", ownerColumn: "tool_id",
+	},
+	model.ResourceTypeCourse: {
+		table: "courses", idColumn: "course_id", nameColumn: "name",
+		ownerTable: "course_contributors", ownerColumn: "course_id",
+	},
+	model.ResourceTypeProject: {
+		table: "projects", idColumn: "project_id", nameColumn: "name", statusColumn: "status",
+		ownerTable: "project_authors", ownerColumn: "project_id",
+	},
+}
+
+// resourceTypes 三类资源的固定顺序，多表合并查询按此顺序拼接
+var resourceTypes = []string{model.ResourceTypeTool, model.ResourceTypeCourse, model.ResourceTypeProject}
+
+func lookupResourceTable(resourceType string) (resourceTable, error) {
+	t, ok := resourceTables[resourceType]
+	if !ok {
+		return resourceTable{}, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+	return t, nil
+}
+
+// ErrVersionConflict 修改资源时传入的版本号与当前版本不一致，调用方应重新读取后再修改
+var ErrVersionConflict = errors.New("version conflict")
+
+// versionMatch 乐观锁条件，version 为 0 时不检查版本
+func versionMatch(column string, version int) (string, []interface{}) {
+	if version == 0 {
+		return "", nil
+	}
+	return " AND " + column + " = ?", []interface{}{version}
+}
+
+func formatTime(t time.Time) string {
+	return t.Format(timeLayout)
+}
+
+func formatNullTime(t sql.NullTime) *string {
+	if !t.Valid {
+		return nil
+	}
+	s := formatTime(t.Time)
+	return &s
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+// nullIfEmpty 空字符串写入 NULL
+func nullIfEmpty(s string) interface{} {
+	if s == "" {
+		return nil
+	}
+	return s
+}
+
+// nullIfZero ID 为 0 时写入 NULL
+func nullIfZero(id int) interface{} {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+// placeholders 生成 IN 子句使用的占位符，如 "?, ?, ?"
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+func intArgs(ids []int) []interface{} {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	return args
+}
+
+// loadStrings 按ID批量加载一对多的字符串列（标签、图片、教师等）
+// query 中的 %s 会被替换为 IN 子句的占位符，查询结果必须是 (id, value) 两列
+func loadStrings(ctx context.Context, db *Database, query string, ids []int) (map[int][]string, error) {
+	result := make(map[int][]string, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(query, placeholders(len(ids))), intArgs(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load related values: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var value string
+		if err := rows.Scan(&id, &value); err != nil {
+			return nil, fmt.Errorf("failed to scan related value: %w", err)
+		}
+		result[id] = append(result[id], value)
+	}
+	return result, rows.Err()
+}
+
+// nonNil 保证切片在 JSON 中输出为 [] 而不是 null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// uniqueStrings 去除空白和重复项，保持原有顺序
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var result []string
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" || seen[v] {
+			continue
+		}
+		seen[v] = true
+		result = append(result, v)
+	}
+	return result
+}
+
+func normalizeLimit(limit int) int {
+	if limit <= 0 {
+		return 10
+	}
+	if limit > 100 {
+		return 100
+	}
+	return limit
+}
+
+// ==================== 点赞 / 收藏 / 浏览 ====================
+
+func resourceExists(ctx context.Context, db *Database, resourceType string, resourceID int) (bool, error) {
+	t, err := lookupResourceTable(resourceType)
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE %s = ? AND deleted_at IS NULL", t.table, t.idColumn)
+	if err := db.QueryRowContext(ctx, query, resourceID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to check %s: %w", resourceType, err)
+	}
+	return count > 0, nil
+}
+
+func counterValue(ctx context.Context, db *Database, resourceType string, resourceID int, column string) (int, error) {
+	t, err := lookupResourceTable(resourceType)
+	if err != nil {
+		return 0, err
+	}
+
+	var value int
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ?", column, t.table, t.idColumn)
+	err = db.QueryRowContext(ctx, query, resourceID).Scan(&value)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%s not found", resourceType)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to get %s %s: %w", resourceType, column, err)
+	}
+	return value, nil
+}
+
+func adjustCounter(ctx context.Context, db *Database, resourceType string, resourceID int, column string, delta int) error {
+	t, err := lookupResourceTable(resourceType)
+	if err != nil {
+		return err
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s = %s + ? WHERE %s = ?", t.table, column, column, t.idColumn)
+	if delta < 0 {
+		// 计数不允许减为负数
+		query = fmt.Sprintf("UPDATE %s SET %s = CASE WHEN %s + ? < 0 THEN 0 ELSE %s + ? END WHERE %s = ?",
+			t.table, column, column, column, t.idColumn)
+		_, err = db.ExecContext(ctx, query, delta, delta, resourceID)
+	} else {
+		_, err = db.ExecContext(ctx, query, delta, resourceID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update %s %s: %w", resourceType, column, err)
+	}
+	return nil
+}
+
+func hasRelation(ctx context.Context, db *Database, table string, userID int, resourceType string, resourceID int) (bool, error) {
+	var count int
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE user_id = ? AND resource_type = ? AND resource_id = ?", table)
+	if err := db.QueryRowContext(ctx, query, userID, resourceType, resourceID).Scan(&count); err != nil {
+		return false, fmt.Errorf("failed to query %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// lockResource 锁定资源行直到事务结束，用于串行化同一资源上的计数更新；资源不存在时返回 false
+func lockResource(ctx context.Context, db *Database, resourceType string, resourceID int) (bool, error) {
+	t, err := lookupResourceTable(resourceType)
+	if err != nil {
+		return false, err
+	}
+
+	var id int
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE %s = ? AND deleted_at IS NULL%s", t.idColumn, t.table, t.idColumn, db.Dialect.ForUpdate())
+	err = db.QueryRowContext(ctx, query, resourceID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to lock %s: %w", resourceType, err)
+	}
+	return true, nil
+}
+
+// toggleRelation 在 likes/collections 表中添加或删除一条记录，并同步维护资源表上的计数列
+func toggleRelation(ctx context.Context, db *Database, table, counter string, userID int, resourceType string, resourceID int, add bool) (int, error) {
+	var value int
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		exists, err := lockResource(ctx, db, resourceType, resourceID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%s not found", resourceType)
+		}
+
+		has, err := hasRelation(ctx, db, table, userID, resourceType, resourceID)
+		if err != nil {
+			return err
+		}
+
+		if add && !has {
+			// 唯一键 (user_id, resource_type, resource_id) 兜底并发重复提交，只有真正插入时才增加计数
+			query := db.Dialect.InsertIgnore(table, []string{"user_id", "resource_type", "resource_id", "created_at"})
+			result, err := db.ExecContext(ctx, query, userID, resourceType, resourceID, time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to insert into %s: %w", table, err)
+			}
+			if inserted, err := result.RowsAffected(); err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			} else if inserted > 0 {
+				if err := adjustCounter(ctx, db, resourceType, resourceID, counter, 1); err != nil {
+					return err
+				}
+			}
+		}
+		if !add && has {
+			query := fmt.Sprintf("DELETE FROM %s WHERE user_id = ? AND resource_type = ? AND resource_id = ?", table)
+			if _, err := db.ExecContext(ctx, query, userID, resourceType, resourceID); err != nil {
+				return fmt.Errorf("failed to delete from %s: %w", table, err)
+			}
+			if err := adjustCounter(ctx, db, resourceType, resourceID, counter, -1); err != nil {
+				return err
+			}
+		}
+
+		value, err = counterValue(ctx, db, resourceType, resourceID, counter)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+func setLike(ctx context.Context, db *Database, userID int, resourceType string, resourceID int, liked bool) (*model.LikeStatus, error) {
+	likes, err := toggleRelation(ctx, db, "likes", "loves", userID, resourceType, resourceID, liked)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LikeStatus{IsLiked: liked, Likes: likes}, nil
+}
+
+func setCollect(ctx context.Context, db *Database, userID int, resourceType string, resourceID int, collected bool) (*model.CollectStatus, error) {
+	collections, err := toggleRelation(ctx, db, "collections", "collections", userID, resourceType, resourceID, collected)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CollectStatus{IsCollected: collected, Collections: collections}, nil
+}
+
+func addView(ctx context.Context, db *Database, resourceType string, resourceID int) (int, error) {
+	exists, err := resourceExists(ctx, db, resourceType, resourceID)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("%s not found", resourceType)
+	}
+	if err := adjustCounter(ctx, db, resourceType, resourceID, "views", 1); err != nil {
+		return 0, err
+	}
+	return counterValue(ctx, db, resourceType, resourceID, "views")
+}
+
+// ==================== 评论 ====================
+
+const commentColumns = `
+	c.comment_id, c.parent_id, COALESCE(u.nickname, u.username), COALESCE(u.avatar, ''),
+	c.content, c.love_count, c.reply_total, c.created_at, c.deleted_at
+`
+
+// commentSort 顶层评论按发表时间先后排列
+var commentSort = keyset{name: "oldest", field: byCreatedAt, column: "c.created_at", idColumn: "c.comment_id", asc: true}
+
+func scanComment(scanner interface{ Scan(...interface{}) error }) (*model.Comment, time.Time, error) {
+	var comment model.Comment
+	var parentID sql.NullInt64
+	var createdAt time.Time
+	var deletedAt sql.NullTime
+
+	err := scanner.Scan(
+		&comment.CommentID,
+		&parentID,
+		&comment.Nickname,
+		&comment.Avatar,
+		&comment.Comment,
+		&comment.LoveCount,
+		&comment.ReplyTotal,
+		&createdAt,
+		&deletedAt,
+	)
+	if err != nil {
+		return nil, createdAt, err
+	}
+
+	if parentID.Valid {
+		id := int(parentID.Int64)
+		comment.ParentID = &id
+		comment.IsReply = true
+	}
+	comment.CommentDate = formatTime(createdAt)
+	if deletedAt.Valid {
+		comment.DeleteDate = formatTime(deletedAt.Time)
+	}
+	comment.Replies = []model.Comment{}
+	return &comment, createdAt, nil
+}
+
+func getComment(ctx context.Context, db *Database, commentID int) (*model.Comment, error) {
+	query := `SELECT ` + commentColumns + ` FROM comments c JOIN users u ON u.id = c.user_id WHERE c.comment_id = ?`
+	comment, _, err := scanComment(db.QueryRowContext(ctx, query, commentID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("comment not found")
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// listComments 获取资源下未删除、未隐藏的评论，回复挂在各自的父评论下；被隐藏评论的回复一起隐藏
+func listComments(ctx context.Context, db *Database, resourceType string, resourceID int) ([]model.Comment, error) {
+	all, _, err := queryComments(ctx, db, `SELECT `+commentColumns+`
+		FROM comments c JOIN users u ON u.id = c.user_id
+		WHERE c.resource_type = ? AND c.resource_id = ? AND c.deleted_at IS NULL AND c.hidden_at IS NULL
+		ORDER BY c.created_at, c.comment_id`, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	return buildCommentTree(all), nil
+}
+
+// pageComments 分页获取资源下未删除、未隐藏的顶层评论，每条评论带上它下面全部的回复
+func pageComments(ctx context.Context, db *Database, resourceType string, resourceID int, page pagination.Page) (*pagination.Result[model.Comment], error) {
+	where := []string{"c.resource_type = ?", "c.resource_id = ?", "c.parent_id IS NULL", "c.deleted_at IS NULL", "c.hidden_at IS NULL"}
+	args := []interface{}{resourceType, resourceID}
+
+	query, queryArgs, err := pageQuery(db.Dialect, `SELECT `+commentColumns+` FROM comments c JOIN users u ON u.id = c.user_id`,
+		where, args, commentSort, page)
+	if err != nil {
+		return nil, err
+	}
+	roots, createdAt, err := queryComments(ctx, db, query, queryArgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]pagination.Key, len(roots))
+	for i, c := range roots {
+		keys[i] = commentSort.key(sortValues{createdAt: createdAt[i]}, c.CommentID)
+	}
+	result := pagination.NewResult(roots, keys, normalizeLimit(page.Limit))
+
+	replies, _, err := queryComments(ctx, db, `SELECT `+commentColumns+`
+		FROM comments c JOIN users u ON u.id = c.user_id
+		WHERE c.resource_type = ? AND c.resource_id = ? AND c.parent_id IS NOT NULL AND c.deleted_at IS NULL AND c.hidden_at IS NULL
+		ORDER BY c.created_at, c.comment_id`, resourceType, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	all := append(append([]*model.Comment{}, result.Items...), replies...)
+	tree := &pagination.Result[model.Comment]{Items: buildCommentTree(all), Info: result.Info}
+	if tree.Total, err = pageTotal(ctx, db, "comments c", where, args, page); err != nil {
+		return nil, err
+	}
+	return tree, nil
+}
+
+// queryComments 执行评论查询，同时返回每条评论的原始发表时间
+func queryComments(ctx context.Context, db *Database, query string, args ...interface{}) ([]*model.Comment, []time.Time, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []*model.Comment
+	var createdAt []time.Time
+	for rows.Next() {
+		comment, t, err := scanComment(rows)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		comments = append(comments, comment)
+		createdAt = append(createdAt, t)
+	}
+	return comments, createdAt, rows.Err()
+}
+
+func buildCommentTree(all []*model.Comment) []model.Comment {
+	children := make(map[int][]*model.Comment)
+	known := make(map[int]bool, len(all))
+	for _, c := range all {
+		known[c.CommentID] = true
+	}
+
+	var roots []*model.Comment
+	for _, c := range all {
+		if c.ParentID != nil && known[*c.ParentID] {
+			children[*c.ParentID] = append(children[*c.ParentID], c)
+		} else if c.ParentID == nil {
+			roots = append(roots, c)
+		}
+	}
+
+	var build func(c *model.Comment) model.Comment
+	build = func(c *model.Comment) model.Comment {
+		result := *c
+		result.Replies = []model.Comment{}
+		for _, child := range children[c.CommentID] {
+			result.Replies = append(result.Replies, build(child))
+		}
+		return result
+	}
+
+	tree := make([]model.Comment, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return tree
+}
+
+func countComments(ctx context.Context, db *Database, resourceType string, resourceID int) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM comments WHERE resource_type = ? AND resource_id = ? AND deleted_at IS NULL AND hidden_at IS NULL`
+	if err := db.QueryRowContext(ctx, query, resourceType, resourceID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count comments: %w", err)
+	}
+	return count, nil
+}
+
+// addComment 发表评论；parentID 不为 nil 时为回复，同时更新父评论的 reply_total
+func addComment(ctx context.Context, db *Database, userID int, resourceType string, resourceID int, parentID *int, content string) (*model.Comment, error) {
+	var comment *model.Comment
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		exists, err := resourceExists(ctx, db, resourceType, resourceID)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return fmt.Errorf("%s not found", resourceType)
+		}
+
+		var parent interface{}
+		if parentID != nil {
+			var count int
+			query := `SELECT COUNT(*) FROM comments WHERE comment_id = ? AND resource_type = ? AND resource_id = ? AND deleted_at IS NULL AND hidden_at IS NULL`
+			if err := db.QueryRowContext(ctx, query, *parentID, resourceType, resourceID).Scan(&count); err != nil {
+				return fmt.Errorf("failed to check parent comment: %w", err)
+			}
+			if count == 0 {
+				return fmt.Errorf("comment not found")
+			}
+			parent = *parentID
+		}
+
+		now := time.Now()
+		result, err := db.ExecContext(ctx,
+			`INSERT INTO comments (resource_type, resource_id, parent_id, user_id, content, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?)`,
+			resourceType, resourceID, parent, userID, content, now, now,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+
+		id, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get last insert id: %w", err)
+		}
+
+		if parentID != nil {
+			if _, err := db.ExecContext(ctx, `UPDATE comments SET reply_total = reply_total + 1 WHERE comment_id = ?`, *parentID); err != nil {
+				return fmt.Errorf("failed to update reply total: %w", err)
+			}
+		}
+
+		comment, err = getComment(ctx, db, int(id))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// deleteComment 软删除当前用户的评论或回复
+func deleteComment(ctx context.Context, db *Database, userID int, resourceType string, resourceID, commentID int, reply bool) (*model.Comment, error) {
+	var deleted *model.Comment
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		comment, err := getComment(ctx, db, commentID)
+		if err != nil {
+			return err
+		}
+		if comment.IsReply != reply {
+			if reply {
+				return fmt.Errorf("comment is not a reply")
+			}
+			return fmt.Errorf("comment is a reply")
+		}
+
+		result, err := db.ExecContext(ctx,
+			`UPDATE comments SET deleted_at = ?
+			WHERE comment_id = ? AND user_id = ? AND resource_type = ? AND resource_id = ? AND deleted_at IS NULL`,
+			time.Now(), commentID, userID, resourceType, resourceID,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rows == 0 {
+			return fmt.Errorf("comment not found")
+		}
+
+		if comment.ParentID != nil {
+			_, err := db.ExecContext(ctx,
+				`UPDATE comments SET reply_total = CASE WHEN reply_total > 0 THEN reply_total - 1 ELSE 0 END WHERE comment_id = ?`,
+				*comment.ParentID,
+			)
+			if err != nil {
+				return fmt.Errorf("failed to update reply total: %w", err)
+			}
+		}
+
+		deleted, err = getComment(ctx, db, commentID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return deleted, nil
+}
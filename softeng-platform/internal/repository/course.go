@@ -2,24 +2,32 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"time"
 )
 
 type CourseRepository interface {
-	GetCourses(ctx context.Context, semester string, category []string, sort string, limit, cursor int) ([]map[string]interface{}, error)
-	GetByID(ctx context.Context, courseID string) (map[string]interface{}, error)
-	Search(ctx context.Context, keyword string, category []string, limit, cursor int) ([]map[string]interface{}, error)
-	UploadResource(ctx context.Context, userID int, courseID string, data map[string]interface{}) (map[string]interface{}, error)
-	DownloadTextbook(ctx context.Context, courseID, textbookID string) (string, error)
-	AddComment(ctx context.Context, userID int, courseID, content string) (map[string]interface{}, error)
-	DeleteComment(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	ReplyComment(ctx context.Context, userID int, courseID, commentID, content string) (map[string]interface{}, error)
-	DeleteReply(ctx context.Context, userID int, courseID, commentID string) (map[string]interface{}, error)
-	AddView(ctx context.Context, courseID string) (int, error)
-	CollectCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	UncollectCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	LikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	UnlikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) // 新增方法
+	GetCourses(ctx context.Context, semester string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Course], error)
+	GetByID(ctx context.Context, courseID int) (*model.CourseDetail, error)
+	// Search 按关键词检索课程名和教师，同时按分类筛选；sort 为空时按相关度排序
+	Search(ctx context.Context, keyword string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Course], error)
+	UploadResource(ctx context.Context, userID, courseID int, req model.CourseUploadRequest) (*model.TeachReview, error)
+	DownloadTextbook(ctx context.Context, courseID, textbookID int) (string, error)
+	AddComment(ctx context.Context, userID, courseID int, content string) (*model.Comment, error)
+	DeleteComment(ctx context.Context, userID, courseID, commentID int) (*model.Comment, error)
+	ReplyComment(ctx context.Context, userID, courseID, commentID int, content string) (*model.Comment, error)
+	DeleteReply(ctx context.Context, userID, courseID, commentID int) (*model.Comment, error)
+	AddView(ctx context.Context, courseID int) (int, error)
+	CollectCourse(ctx context.Context, userID, courseID int) (*model.CollectStatus, error)
+	UncollectCourse(ctx context.Context, userID, courseID int) (*model.CollectStatus, error)
+	LikeCourse(ctx context.Context, userID, courseID int) (*model.LikeStatus, error)
+	UnlikeCourse(ctx context.Context, userID, courseID int) (*model.LikeStatus, error)
+	GetComments(ctx context.Context, courseID int, page pagination.Page) (*pagination.Result[model.Comment], error)
+	GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) // 新增方法
 }
 
 type courseRepository struct {
@@ -30,195 +38,423 @@ func NewCourseRepository(db *Database) CourseRepository {
 	return &courseRepository{db: db}
 }
 
-func (r *courseRepository) GetCourses(ctx context.Context, semester string, category []string, sort string, limit, cursor int) ([]map[string]interface{}, error) {
-	// 实现获取课程列表的逻辑
-	return []map[string]interface{}{
-		{
-			"courseId":     1,
-			"resourceType": "course",
-			"name":         "软件工程导论",
-			"teacher":      []string{"张教授", "李教授"},
-			"category":     []string{"专必", "有签到"},
-			"semester":     "大二上",
-			"credit":       3,
-			"cover":        "https://example.com/course1.jpg",
-			"views":        1000,
-			"loves":        200,
-			"collections":  150,
-		},
-	}, nil
-}
-
-func (r *courseRepository) GetByID(ctx context.Context, courseID string) (map[string]interface{}, error) {
-	// 实现根据ID获取课程详情的逻辑
-	return map[string]interface{}{
-		"courseId":     courseID,
-		"resourceType": "course",
-		"name":         "软件工程导论",
-		"catagory":     "专业必修",
-		"url_form": []map[string]interface{}{
-			{
-				"resource_intro": "课程视频",
-				"resource_url":   "https://example.com/video1",
-				"resource_id":    1,
-			},
-		},
-		"upload_form": []map[string]interface{}{
-			{
-				"resource_intro":  "课程讲义",
-				"resource_upload": "https://example.com/lecture1.pdf",
-				"resource_id":     2,
-			},
-		},
-		"contributor":   []string{"user1", "user2"},
-		"collections":   150,
-		"views":         1000,
-		"likes":         200,
-		"isliked":       false,
-		"iscollected":   false,
-		"comment_total": 25,
-		"comments":      []map[string]interface{}{},
-		"createdAt":     "2023-09-01",
-	}, nil
-}
-
-func (r *courseRepository) Search(ctx context.Context, keyword string, category []string, limit, cursor int) ([]map[string]interface{}, error) {
-	// 实现搜索课程的逻辑
-	return []map[string]interface{}{
-		{
-			"courseId":     2,
-			"resourceType": "course",
-			"name":         "高级软件工程 - " + keyword,
-			"teacher":      []string{"王教授"},
-			"category":     []string{"专选", "无签到"},
-			"semester":     "大三上",
-			"credit":       2,
-			"cover":        "https://example.com/course2.jpg",
-			"views":        800,
-			"loves":        150,
-			"collections":  100,
-		},
-	}, nil
-}
-
-func (r *courseRepository) UploadResource(ctx context.Context, userID int, courseID string, data map[string]interface{}) (map[string]interface{}, error) {
-	// 实现上传课程资源的逻辑
-	return map[string]interface{}{
-		"resourceId":   1,
-		"resourceType": "teach",
-		"resource1": map[string]interface{}{
-			"resource_intro": data["description"],
-			"resource_url":   data["resource"],
-			"resource_id":    1,
-		},
-		"resource2": map[string]interface{}{
-			"resource_intro":  data["description"],
-			"resource_upload": data["file"],
-			"resource_id":     2,
-		},
-		"auditStatus":  "pending",
-		"submitTime":   "2023-12-01 10:00:00",
-		"auditTime":    nil,
-		"rejectReason": nil,
-	}, nil
-}
-
-func (r *courseRepository) DownloadTextbook(ctx context.Context, courseID, textbookID string) (string, error) {
-	// 实现下载课本的逻辑
-	return "Textbook content for course " + courseID + " textbook " + textbookID, nil
-}
-
-func (r *courseRepository) AddComment(ctx context.Context, userID int, courseID, content string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"comment_Id":  1,
-		"nickname":    "用户",
-		"avater":      "https://example.com/avatar.jpg",
-		"comment":     content,
-		"commentDate": "2023-12-01 10:00:00",
-		"love_count":  0,
-		"reply_total": 0,
-		"replies":     []map[string]interface{}{},
-	}, nil
-}
-
-func (r *courseRepository) DeleteComment(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"commentId":   1,
-		"nickname":    "用户",
-		"avater":      "https://example.com/avatar.jpg",
-		"comment":     "已删除的评论",
-		"delete_Date": "2023-12-01 10:00:00",
-	}, nil
-}
-
-func (r *courseRepository) ReplyComment(ctx context.Context, userID int, courseID, commentID, content string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"commentId":   2,
-		"nickname":    "用户",
-		"avater":      "https://example.com/avatar.jpg",
-		"comment":     content,
-		"commentDate": "2023-12-01 10:00:00",
-		"isreply":     true,
-		"reply_id":    commentID,
-		"replies":     []map[string]interface{}{},
-	}, nil
-}
-
-func (r *courseRepository) DeleteReply(ctx context.Context, userID int, courseID, commentID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"commentId":   2,
-		"nickname":    "用户",
-		"avater":      "https://example.com/avatar.jpg",
-		"comment":     "已删除的回复",
-		"delete_Date": "2023-12-01 10:00:00",
-	}, nil
-}
-
-func (r *courseRepository) AddView(ctx context.Context, courseID string) (int, error) {
-	return 1001, nil
-}
-
-func (r *courseRepository) CollectCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"iscollected": true,
-		"collections": 151,
-	}, nil
-}
-
-func (r *courseRepository) UncollectCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"iscollected": false,
-		"collections": 150,
-	}, nil
-}
-
-func (r *courseRepository) LikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"isliked": true,
-		"likes":   201,
-	}, nil
-}
-
-func (r *courseRepository) UnlikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"isliked": false,
-		"likes":   200,
-	}, nil
-}
-
-func (r *courseRepository) GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) {
-	return []map[string]interface{}{
-		{
-			"submitor":     "user1",
-			"submitDate":   "2023-12-01 10:00:00",
-			"reourceId":    1,
-			"resourceType": "course",
-			"resourcename": "新课程资源",
-			"catagory":     "教学资料",
-			"link":         "https://example.com/resource",
-			"description":  "课程相关资源",
-			"tags":         []string{"讲义", "视频"},
-			"file":         "resource.pdf",
-		},
-	}, nil
+const courseColumns = `
+	course_id, name, COALESCE(semester, ''), COALESCE(credit, 0), COALESCE(cover, ''),
+	views, loves, collections, created_at, version
+`
+
+var (
+	courseSorts       = resourceSorts("course_id")
+	courseSearchSorts = searchSorts("course_id")
+)
+
+// pendingCourseSort 待审核的网页资源和上传资源合并后按提交时间先后排列，
+// 两张表的ID可能相同，以资源类型区分
+var pendingCourseSort = keyset{
+	name: "pending", field: byCreatedAt, column: "p.created_at",
+	kindColumn: "p.resource_type", idColumn: "p.resource_id", asc: true,
+}
+
+func (r *courseRepository) GetCourses(ctx context.Context, semester string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Course], error) {
+	where := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if semester != "" {
+		where = append(where, "semester = ?")
+		args = append(args, semester)
+	}
+	if len(category) > 0 {
+		where = append(where, "course_id IN (SELECT course_id FROM course_categories WHERE category IN ("+placeholders(len(category))+"))")
+		for _, c := range category {
+			args = append(args, c)
+		}
+	}
+
+	return r.pageCourses(ctx, tableQuery(courseColumns, "courses", where, args), lookupSort(courseSorts, sort), page)
+}
+
+func (r *courseRepository) GetByID(ctx context.Context, courseID int) (*model.CourseDetail, error) {
+	query := `SELECT ` + courseColumns + ` FROM courses WHERE course_id = ? AND deleted_at IS NULL`
+
+	course, createdAt, err := scanCourse(r.db.QueryRowContext(ctx, query, courseID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get course by id: %w", err)
+	}
+
+	courses := []model.Course{*course}
+	if err := r.loadRelations(ctx, courses); err != nil {
+		return nil, err
+	}
+	course = &courses[0]
+
+	detail := &model.CourseDetail{
+		CourseID:     course.CourseID,
+		ResourceType: course.ResourceType,
+		Name:         course.Name,
+		Teacher:      course.Teacher,
+		Category:     course.Category,
+		Semester:     course.Semester,
+		Credit:       course.Credit,
+		Cover:        course.Cover,
+		Collections:  course.Collections,
+		Views:        course.Views,
+		Likes:        course.Loves,
+		CreatedAt:    formatTime(createdAt),
+		Version:      course.Version,
+	}
+
+	if detail.URLForm, err = r.webResources(ctx, courseID); err != nil {
+		return nil, err
+	}
+	if detail.UploadForm, err = r.uploadResources(ctx, courseID); err != nil {
+		return nil, err
+	}
+
+	contributors, err := loadStrings(ctx, r.db, `
+		SELECT cc.course_id, COALESCE(u.nickname, u.username)
+		FROM course_contributors cc JOIN users u ON u.id = cc.user_id
+		WHERE cc.course_id IN (%s) ORDER BY cc.id`, []int{courseID})
+	if err != nil {
+		return nil, err
+	}
+	detail.Contributor = nonNil(contributors[courseID])
+
+	if detail.Comments, err = listComments(ctx, r.db, model.ResourceTypeCourse, courseID); err != nil {
+		return nil, err
+	}
+	if detail.CommentTotal, err = countComments(ctx, r.db, model.ResourceTypeCourse, courseID); err != nil {
+		return nil, err
+	}
+
+	return detail, nil
+}
+
+func (r *courseRepository) Search(ctx context.Context, keyword string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Course], error) {
+	where := []string{"deleted_at IS NULL"}
+	var args []interface{}
+
+	if len(category) > 0 {
+		where = append(where, "course_id IN (SELECT course_id FROM course_categories WHERE category IN ("+placeholders(len(category))+"))")
+		for _, c := range category {
+			args = append(args, c)
+		}
+	}
+
+	q := courseSearch.query(r.db.Dialect, courseColumns, keyword, where, args)
+	return r.pageCourses(ctx, q, searchSort(courseSearchSorts, sort, keyword), page)
+}
+
+// UploadResource 在一个事务中写入网页资源和/或上传资源
+func (r *courseRepository) UploadResource(ctx context.Context, userID, courseID int, req model.CourseUploadRequest) (*model.TeachReview, error) {
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.TeachReview, error) {
+		return r.uploadResource(ctx, userID, courseID, req)
+	})
+}
+
+func (r *courseRepository) uploadResource(ctx context.Context, userID, courseID int, req model.CourseUploadRequest) (*model.TeachReview, error) {
+	if req.Resource == "" && req.File == "" {
+		return nil, fmt.Errorf("resource or file is required")
+	}
+
+	exists, err := resourceExists(ctx, r.db, model.ResourceTypeCourse, courseID)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, fmt.Errorf("course not found")
+	}
+
+	now := time.Now()
+	review := &model.TeachReview{
+		ResourceType: model.ResourceTypeCourse,
+		AuditStatus:  model.StatusPending,
+		SubmitTime:   formatTime(now),
+	}
+
+	if req.Resource != "" {
+		result, err := r.db.ExecContext(ctx,
+			`INSERT INTO course_resources_web (course_id, resource_intro, resource_url, status, submitter_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			courseID, req.Description, req.Resource, model.StatusPending, userID, now,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create course web resource: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last insert id: %w", err)
+		}
+		review.ResourceID = int(id)
+		review.Resource1 = &model.ResourceWeb{ResourceIntro: req.Description, ResourceURL: req.Resource, ResourceID: int(id)}
+	}
+
+	if req.File != "" {
+		result, err := r.db.ExecContext(ctx,
+			`INSERT INTO course_resources_upload (course_id, resource_intro, resource_upload, status, submitter_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			courseID, req.Description, req.File, model.StatusPending, userID, now,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create course upload resource: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get last insert id: %w", err)
+		}
+		if review.ResourceID == 0 {
+			review.ResourceID = int(id)
+		}
+		review.Resource2 = &model.ResourceUpload{ResourceIntro: req.Description, ResourceUpload: req.File, ResourceID: int(id)}
+	}
+
+	return review, nil
+}
+
+func (r *courseRepository) DownloadTextbook(ctx context.Context, courseID, textbookID int) (string, error) {
+	var url string
+	err := r.db.QueryRowContext(ctx,
+		`SELECT f.resource_upload FROM course_resources_upload f JOIN courses c ON c.course_id = f.course_id
+		WHERE f.resource_id = ? AND f.course_id = ? AND f.status = ? AND c.deleted_at IS NULL`,
+		textbookID, courseID, model.StatusApproved,
+	).Scan(&url)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", fmt.Errorf("textbook not found")
+		}
+		return "", fmt.Errorf("failed to get textbook: %w", err)
+	}
+	return url, nil
+}
+
+func (r *courseRepository) AddComment(ctx context.Context, userID, courseID int, content string) (*model.Comment, error) {
+	return addComment(ctx, r.db, userID, model.ResourceTypeCourse, courseID, nil, content)
+}
+
+func (r *courseRepository) DeleteComment(ctx context.Context, userID, courseID, commentID int) (*model.Comment, error) {
+	return deleteComment(ctx, r.db, userID, model.ResourceTypeCourse, courseID, commentID, false)
+}
+
+func (r *courseRepository) ReplyComment(ctx context.Context, userID, courseID, commentID int, content string) (*model.Comment, error) {
+	return addComment(ctx, r.db, userID, model.ResourceTypeCourse, courseID, &commentID, content)
+}
+
+func (r *courseRepository) DeleteReply(ctx context.Context, userID, courseID, commentID int) (*model.Comment, error) {
+	return deleteComment(ctx, r.db, userID, model.ResourceTypeCourse, courseID, commentID, true)
+}
+
+func (r *courseRepository) AddView(ctx context.Context, courseID int) (int, error) {
+	return addView(ctx, r.db, model.ResourceTypeCourse, courseID)
+}
+
+func (r *courseRepository) CollectCourse(ctx context.Context, userID, courseID int) (*model.CollectStatus, error) {
+	return setCollect(ctx, r.db, userID, model.ResourceTypeCourse, courseID, true)
+}
+
+func (r *courseRepository) UncollectCourse(ctx context.Context, userID, courseID int) (*model.CollectStatus, error) {
+	return setCollect(ctx, r.db, userID, model.ResourceTypeCourse, courseID, false)
+}
+
+func (r *courseRepository) LikeCourse(ctx context.Context, userID, courseID int) (*model.LikeStatus, error) {
+	return setLike(ctx, r.db, userID, model.ResourceTypeCourse, courseID, true)
+}
+
+func (r *courseRepository) UnlikeCourse(ctx context.Context, userID, courseID int) (*model.LikeStatus, error) {
+	return setLike(ctx, r.db, userID, model.ResourceTypeCourse, courseID, false)
+}
+
+func (r *courseRepository) GetComments(ctx context.Context, courseID int, page pagination.Page) (*pagination.Result[model.Comment], error) {
+	return pageComments(ctx, r.db, model.ResourceTypeCourse, courseID, page)
+}
+
+// pendingCourseResources 两类待审核资源合并后的子查询，参数为两组审核队列中的状态值
+const pendingCourseResources = `(
+	SELECT w.resource_id, '` + model.ResourceTypeCourseWeb + `' AS resource_type, c.name, w.resource_url AS link, '' AS file,
+		w.resource_intro AS intro, w.created_at, w.submitter_id
+	FROM course_resources_web w JOIN courses c ON c.course_id = w.course_id
+	WHERE w.status IN (?, ?) AND c.deleted_at IS NULL
+	UNION ALL
+	SELECT f.resource_id, '` + model.ResourceTypeCourseUpload + `' AS resource_type, c.name, '' AS link, f.resource_upload AS file,
+		f.resource_intro AS intro, f.created_at, f.submitter_id
+	FROM course_resources_upload f JOIN courses c ON c.course_id = f.course_id
+	WHERE f.status IN (?, ?) AND c.deleted_at IS NULL
+) p`
+
+func (r *courseRepository) GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) {
+	args := []interface{}{model.StatusPending, model.StatusResubmitted, model.StatusPending, model.StatusResubmitted}
+	where, claimArgs := claimWhere(r.db.Dialect, filter, "p.resource_type", "p.resource_id")
+	args = append(args, claimArgs...)
+
+	query, queryArgs, err := pageQuery(r.db.Dialect, `
+		SELECT p.resource_id, p.resource_type, p.name, p.link, p.file, p.intro, p.created_at, COALESCE(u.nickname, u.username, '')
+		FROM `+pendingCourseResources+` LEFT JOIN users u ON u.id = p.submitter_id`, where, args, pendingCourseSort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending course resources: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.Submit
+	var keys []pagination.Key
+	for rows.Next() {
+		var item model.Submit
+		var createdAt time.Time
+		if err := rows.Scan(&item.ResourceID, &item.ResourceType, &item.ResourceName, &item.Link, &item.File,
+			&item.Description, &createdAt, &item.Submitor); err != nil {
+			return nil, fmt.Errorf("failed to scan pending course resource: %w", err)
+		}
+		item.SubmitDate = formatTime(createdAt)
+		item.Tags = []string{}
+		items = append(items, item)
+
+		key := pendingCourseSort.key(sortValues{createdAt: createdAt}, item.ResourceID)
+		key.Kind = item.ResourceType
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewResult(items, keys, normalizeLimit(page.Limit))
+	if result.Total, err = pageTotal(ctx, r.db, pendingCourseResources, where, args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func scanCourse(scanner interface{ Scan(...interface{}) error }) (*model.Course, time.Time, error) {
+	var course model.Course
+	var createdAt time.Time
+	err := scanner.Scan(
+		&course.CourseID,
+		&course.Name,
+		&course.Semester,
+		&course.Credit,
+		&course.Cover,
+		&course.Views,
+		&course.Loves,
+		&course.Collections,
+		&createdAt,
+		&course.Version,
+	)
+	if err != nil {
+		return nil, createdAt, err
+	}
+	course.ResourceType = model.ResourceTypeCourse
+	return &course, createdAt, nil
+}
+
+// pageCourses 按筛选条件和排序查询一页已加载关联数据的课程
+func (r *courseRepository) pageCourses(ctx context.Context, q listQuery, sort keyset, page pagination.Page) (*pagination.Result[model.Course], error) {
+	query, queryArgs, err := pageQuery(r.db.Dialect, q.selectFrom(), q.where, q.args, sort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query courses: %w", err)
+	}
+	defer rows.Close()
+
+	var courses []model.Course
+	var keys []pagination.Key
+	var relevance float64
+	for rows.Next() {
+		course, createdAt, err := scanCourse(q.scanner(rows, &relevance))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan course: %w", err)
+		}
+		courses = append(courses, *course)
+		keys = append(keys, sort.key(sortValues{
+			createdAt: createdAt, views: course.Views, loves: course.Loves, collections: course.Collections,
+			relevance: int64(relevance),
+		}, course.CourseID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewResult(courses, keys, normalizeLimit(page.Limit))
+	if err := r.loadRelations(ctx, result.Items); err != nil {
+		return nil, err
+	}
+	if result.Total, err = pageTotal(ctx, r.db, q.from, q.where, q.args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// loadRelations 批量加载课程的教师和分类
+func (r *courseRepository) loadRelations(ctx context.Context, courses []model.Course) error {
+	ids := make([]int, len(courses))
+	for i, c := range courses {
+		ids[i] = c.CourseID
+	}
+
+	teachers, err := loadStrings(ctx, r.db, `SELECT course_id, teacher_name FROM course_teachers WHERE course_id IN (%s) ORDER BY id`, ids)
+	if err != nil {
+		return err
+	}
+	categories, err := loadStrings(ctx, r.db, `SELECT course_id, category FROM course_categories WHERE course_id IN (%s) ORDER BY id`, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range courses {
+		id := courses[i].CourseID
+		courses[i].Teacher = nonNil(teachers[id])
+		courses[i].Category = nonNil(categories[id])
+	}
+	return nil
+}
+
+func (r *courseRepository) webResources(ctx context.Context, courseID int) ([]model.ResourceWeb, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT resource_id, resource_intro, resource_url FROM course_resources_web
+		WHERE course_id = ? AND status = ? ORDER BY sort_order, resource_id`,
+		courseID, model.StatusApproved,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course web resources: %w", err)
+	}
+	defer rows.Close()
+
+	resources := []model.ResourceWeb{}
+	for rows.Next() {
+		var res model.ResourceWeb
+		if err := rows.Scan(&res.ResourceID, &res.ResourceIntro, &res.ResourceURL); err != nil {
+			return nil, fmt.Errorf("failed to scan course web resource: %w", err)
+		}
+		resources = append(resources, res)
+	}
+	return resources, rows.Err()
+}
+
+func (r *courseRepository) uploadResources(ctx context.Context, courseID int) ([]model.ResourceUpload, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT resource_id, resource_intro, resource_upload FROM course_resources_upload
+		WHERE course_id = ? AND status = ? ORDER BY sort_order, resource_id`,
+		courseID, model.StatusApproved,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course upload resources: %w", err)
+	}
+	defer rows.Close()
+
+	resources := []model.ResourceUpload{}
+	for rows.Next() {
+		var res model.ResourceUpload
+		if err := rows.Scan(&res.ResourceID, &res.ResourceIntro, &res.ResourceUpload); err != nil {
+			return nil, fmt.Errorf("failed to scan course upload resource: %w", err)
+		}
+		resources = append(resources, res)
+	}
+	return resources, rows.Err()
 }
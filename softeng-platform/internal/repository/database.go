@@ -1,39 +1,344 @@
-
-package repository
-
-import (
-	"database/sql"
-	"fmt"
-	_ "github.com/go-sql-driver/mysql"
-	"log"
-	"time"
-	_ "time"
-)
-
-type Database struct {
-	*sql.DB
-}
-
-func NewDatabase(connectionString string) (*Database, error) {
-	db, err := sql.Open("mysql", connectionString)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open database: %v", err)
-	}
-
-	// 设置连接池参数
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(25)
-	db.SetConnMaxLifetime(5 * time.Minute)
-
-	if err = db.Ping(); err != nil {
-		return nil, fmt.Errorf("failed to ping database: %v", err)
-	}
-
-	log.Println("Successfully connected to MySQL database")
-	return &Database{db}, nil
-}
-
-func (db *Database) Close() error {
-	return db.DB.Close()
-}
-
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"softeng-platform/database"
+	"softeng-platform/internal/model"
+	"strings"
+	"time"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "modernc.org/sqlite"
+)
+
+type Database struct {
+	*sql.DB
+	Dialect Dialect
+	pool    PoolConfig
+}
+
+// PoolConfig 连接池与超时配置，零值字段使用 DefaultPoolConfig 中的值。
+// SQLite 固定使用一个连接，只有 QueryTimeout 生效
+type PoolConfig struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+	// QueryTimeout 单条语句的默认截止时间，ctx 已有更早的截止时间时以 ctx 为准
+	QueryTimeout time.Duration
+}
+
+// DefaultPoolConfig 默认的连接池配置
+var DefaultPoolConfig = PoolConfig{
+	MaxOpenConns:    25,
+	MaxIdleConns:    25,
+	ConnMaxLifetime: 5 * time.Minute,
+	ConnMaxIdleTime: 10 * time.Minute,
+	QueryTimeout:    10 * time.Second,
+}
+
+// withDefaults 用默认值补全未设置的字段，空闲连接数不超过最大连接数
+func (p PoolConfig) withDefaults() PoolConfig {
+	if p.MaxOpenConns <= 0 {
+		p.MaxOpenConns = DefaultPoolConfig.MaxOpenConns
+	}
+	if p.MaxIdleConns <= 0 {
+		p.MaxIdleConns = DefaultPoolConfig.MaxIdleConns
+	}
+	p.MaxIdleConns = min(p.MaxIdleConns, p.MaxOpenConns)
+	if p.ConnMaxLifetime <= 0 {
+		p.ConnMaxLifetime = DefaultPoolConfig.ConnMaxLifetime
+	}
+	if p.ConnMaxIdleTime <= 0 {
+		p.ConnMaxIdleTime = DefaultPoolConfig.ConnMaxIdleTime
+	}
+	if p.QueryTimeout <= 0 {
+		p.QueryTimeout = DefaultPoolConfig.QueryTimeout
+	}
+	return p
+}
+
+// Transactor 由 Database 实现，服务层通过它把多个仓库调用组合成一个事务
+type Transactor interface {
+	WithTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// DBTX 是 *sql.DB 与 *sql.Tx 共有的查询方法
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type txKey struct{}
+
+const (
+	// maxTxAttempts 事务因死锁或序列化失败被回滚后的最大尝试次数
+	maxTxAttempts = 3
+	txRetryDelay  = 20 * time.Millisecond
+)
+
+// NewDatabase 按驱动名连接数据库；driver 为 mysql 或 sqlite
+func NewDatabase(driver, connectionString string, pool PoolConfig) (*Database, error) {
+	dialect, err := LookupDialect(driver)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open(dialect.DriverName(), connectionString)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	// 设置连接池参数
+	pool = pool.withDefaults()
+	if dialect.Name() == "sqlite" {
+		// SQLite 同一时刻只允许一个写入者，内存数据库的每个连接还是独立的库，
+		// 所以只保留一个连接；事务中的查询都通过 ctx 复用同一个 *sql.Tx
+		pool.MaxOpenConns, pool.MaxIdleConns = 1, 1
+		pool.ConnMaxLifetime, pool.ConnMaxIdleTime = 0, 0
+	}
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pool.QueryTimeout)
+	defer cancel()
+	if err = db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to ping database: %w", err)
+	}
+
+	if dialect.Name() == "sqlite" {
+		// SQLite 库文件随用随建，启动时自动建表
+		if err := applySQLiteSchema(db); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+
+	log.Printf("Successfully connected to %s database", dialect.Name())
+	return &Database{DB: db, Dialect: dialect, pool: pool}, nil
+}
+
+// sqliteFTSTables SQLite 的全文索引表
+var sqliteFTSTables = []string{"tools_fts", "courses_fts", "projects_fts"}
+
+// applySQLiteSchema 建表，并把旧版本用默认分词器创建的全文索引改为 trigram 分词后重建
+func applySQLiteSchema(db *sql.DB) error {
+	var stale []string
+	for _, name := range sqliteFTSTables {
+		var ddl string
+		err := db.QueryRow("SELECT sql FROM sqlite_master WHERE type = 'table' AND name = ?", name).Scan(&ddl)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to inspect %s: %w", name, err)
+		}
+		if !strings.Contains(ddl, "trigram") {
+			if _, err := db.Exec("DROP TABLE " + name); err != nil {
+				return fmt.Errorf("failed to drop %s: %w", name, err)
+			}
+			stale = append(stale, name)
+		}
+	}
+
+	if _, err := db.Exec(database.SQLiteSchema); err != nil {
+		return fmt.Errorf("failed to apply sqlite schema: %w", err)
+	}
+
+	for _, name := range stale {
+		if _, err := db.Exec(fmt.Sprintf("INSERT INTO %s (%s) VALUES ('rebuild')", name, name)); err != nil {
+			return fmt.Errorf("failed to rebuild %s: %w", name, err)
+		}
+		log.Printf("Rebuilt full-text index %s with trigram tokenizer", name)
+	}
+	return nil
+}
+
+// Pool 返回生效的连接池配置
+func (db *Database) Pool() PoolConfig {
+	return db.pool
+}
+
+// HealthChecker 报告数据库是否可用以及连接池状态，由 Database 实现
+type HealthChecker interface {
+	DBStats(ctx context.Context) *model.DBStats
+}
+
+// DBStats ping 一次数据库并返回连接池统计，ping 失败时 Status 为 unavailable
+func (db *Database) DBStats(ctx context.Context) *model.DBStats {
+	stats := db.Stats()
+	result := &model.DBStats{
+		Status:             "ok",
+		Driver:             db.Dialect.Name(),
+		MaxOpenConnections: stats.MaxOpenConnections,
+		MaxIdleConnections: db.pool.MaxIdleConns,
+		ConnMaxLifetime:    db.pool.ConnMaxLifetime.String(),
+		ConnMaxIdleTime:    db.pool.ConnMaxIdleTime.String(),
+		QueryTimeout:       db.pool.QueryTimeout.String(),
+		OpenConnections:    stats.OpenConnections,
+		InUse:              stats.InUse,
+		Idle:               stats.Idle,
+		WaitCount:          stats.WaitCount,
+		WaitMillis:         millis(stats.WaitDuration),
+		MaxIdleClosed:      stats.MaxIdleClosed,
+		MaxIdleTimeClosed:  stats.MaxIdleTimeClosed,
+		MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+	}
+
+	ctx, cancel := db.queryContext(ctx)
+	defer cancel()
+	start := time.Now()
+	if err := db.PingContext(ctx); err != nil {
+		result.Status = "unavailable"
+		result.Error = err.Error()
+	}
+	result.PingMillis = millis(time.Since(start))
+	return result
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func (db *Database) Close() error {
+	return db.DB.Close()
+}
+
+// WithTx 在事务中执行 fn，fn 收到的 ctx 携带该事务，仓库方法会自动使用它。
+// 如果 ctx 中已经有事务，fn 直接加入外层事务，由外层负责提交或回滚。
+// 因死锁或序列化失败导致的错误会整体重试。
+func (db *Database) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 1; attempt <= maxTxAttempts; attempt++ {
+		err = db.runTx(ctx, nil, fn)
+		if err == nil || !db.Dialect.IsRetryable(err) {
+			return err
+		}
+
+		log.Printf("Retrying transaction (attempt %d/%d): %v", attempt, maxTxAttempts, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(attempt) * txRetryDelay):
+		}
+	}
+	return err
+}
+
+// ReadSnapshot 在只读的一致性快照事务中执行 fn，用于导出等需要多次查询且彼此一致的场景。
+// fn 可能是不可重放的（例如边读边写出），所以失败时不重试
+func (db *Database) ReadSnapshot(ctx context.Context, fn func(ctx context.Context) error) error {
+	return db.runTx(ctx, db.Dialect.SnapshotTxOptions(), fn)
+}
+
+func (db *Database) runTx(ctx context.Context, opts *sql.TxOptions, fn func(ctx context.Context) error) error {
+	tx, err := db.DB.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(context.WithValue(ctx, txKey{}, tx)); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil && !errors.Is(rbErr, sql.ErrTxDone) {
+			log.Printf("Error rolling back transaction: %v", rbErr)
+		}
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// conn 返回 ctx 中的事务，没有事务时返回连接池
+func (db *Database) conn(ctx context.Context) DBTX {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+	return db.DB
+}
+
+// queryContext 为单条语句加上默认截止时间，ctx 已有更早的截止时间时原样返回
+func (db *Database) queryContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if db.pool.QueryTimeout <= 0 {
+		return ctx, func() {}
+	}
+	deadline := time.Now().Add(db.pool.QueryTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// ExecContext 覆盖 *sql.DB 的同名方法，优先使用 ctx 中的事务
+func (db *Database) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := db.queryContext(ctx)
+	defer cancel()
+	return db.conn(ctx).ExecContext(ctx, query, args...)
+}
+
+// QueryContext 覆盖 *sql.DB 的同名方法，优先使用 ctx 中的事务。
+// 返回的结果集在读取完之前都需要 ctx 有效，截止时间在关闭结果集时释放
+func (db *Database) QueryContext(ctx context.Context, query string, args ...interface{}) (*Rows, error) {
+	ctx, cancel := db.queryContext(ctx)
+	rows, err := db.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	return &Rows{Rows: rows, cancel: cancel}, nil
+}
+
+// QueryRowContext 覆盖 *sql.DB 的同名方法，优先使用 ctx 中的事务，截止时间在 Scan 后释放
+func (db *Database) QueryRowContext(ctx context.Context, query string, args ...interface{}) *Row {
+	ctx, cancel := db.queryContext(ctx)
+	return &Row{Row: db.conn(ctx).QueryRowContext(ctx, query, args...), cancel: cancel}
+}
+
+// Rows 是 QueryContext 返回的结果集，调用方必须 Close
+type Rows struct {
+	*sql.Rows
+	cancel context.CancelFunc
+}
+
+// Close 关闭结果集并释放语句的截止时间，可以重复调用
+func (r *Rows) Close() error {
+	err := r.Rows.Close()
+	r.cancel()
+	return err
+}
+
+// Row 是 QueryRowContext 返回的单行结果，调用方必须 Scan
+type Row struct {
+	*sql.Row
+	cancel context.CancelFunc
+}
+
+// Scan 读取结果并释放语句的截止时间
+func (r *Row) Scan(dest ...interface{}) error {
+	defer r.cancel()
+	return r.Row.Scan(dest...)
+}
+
+// inTx 是 WithTx 的泛型版本，便于在事务中执行有返回值的操作
+func inTx[T any](ctx context.Context, db *Database, fn func(ctx context.Context) (T, error)) (T, error) {
+	var result T
+	err := db.WithTx(ctx, func(ctx context.Context) error {
+		var err error
+		result, err = fn(ctx)
+		return err
+	})
+	return result, err
+}
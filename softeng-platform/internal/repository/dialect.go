@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/go-sql-driver/mysql"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// Dialect 封装不同数据库之间不兼容的 SQL 写法，仓库层只通过它生成这部分语句
+type Dialect interface {
+	// Name 返回方言名称，同时也是配置中 DB_DRIVER 的取值
+	Name() string
+	// DriverName 返回 database/sql 注册的驱动名
+	DriverName() string
+	// Now 返回当前时间的 SQL 表达式
+	Now() string
+	// TimeKey 返回时间列（或参数占位符）用于排序和比较时的表达式
+	TimeKey(expr string) string
+	// ForUpdate 返回行锁子句，不支持行锁的数据库返回空字符串
+	ForUpdate() string
+	// InsertIgnore 生成唯一键冲突时忽略的插入语句
+	InsertIgnore(table string, columns []string) string
+	// Upsert 生成唯一键冲突时更新的插入语句；update 中的列使用新值覆盖，
+	// assignments 为额外的赋值表达式，可通过 Excluded 引用新值
+	Upsert(table string, columns, conflict, update []string, assignments ...string) string
+	// Excluded 返回 upsert 语句中引用待插入新值的表达式
+	Excluded(column string) string
+	// FullTextMatch 返回全文检索条件，条件中只有一个占位符，参数由 FullTextTerm 生成
+	FullTextMatch(index fullTextIndex) string
+	// FullTextScore 返回全文检索相关度的表达式，越大越相关，占位符与 FullTextMatch 相同
+	FullTextScore(index fullTextIndex) string
+	// FullTextTerm 将一个检索词转换为全文检索参数；检索词短于分词的最小长度、
+	// 无法走全文索引时返回 false，调用方改用 LIKE 匹配
+	FullTextTerm(term string) (string, bool)
+	// SnapshotTxOptions 返回只读一致性快照事务的选项，事务中的多次查询看到同一时刻的数据
+	SnapshotTxOptions() *sql.TxOptions
+	// IsRetryable 判断事务失败是否可以整体重试（死锁、锁等待超时、数据库忙等）
+	IsRetryable(err error) bool
+	// IsDuplicate 判断错误是否为唯一键冲突
+	IsDuplicate(err error) bool
+}
+
+// fullTextIndex 描述一张表上的全文索引
+type fullTextIndex struct {
+	table    string
+	idColumn string
+	columns  []string
+}
+
+var (
+	toolTextIndex    = fullTextIndex{table: "tools", idColumn: "resource_id", columns: []string{"resource_name", "description", "description_detail"}}
+	courseTextIndex  = fullTextIndex{table: "courses", idColumn: "course_id", columns: []string{"name"}}
+	projectTextIndex = fullTextIndex{table: "projects", idColumn: "project_id", columns: []string{"name", "description", "detail"}}
+)
+
+var dialects = map[string]Dialect{
+	"mysql":  mysqlDialect{},
+	"sqlite": sqliteDialect{},
+}
+
+// LookupDialect 根据配置中的驱动名返回对应方言
+func LookupDialect(name string) (Dialect, error) {
+	d, ok := dialects[strings.ToLower(name)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported database driver: %s", name)
+	}
+	return d, nil
+}
+
+// ==================== MySQL ====================
+
+type mysqlDialect struct{}
+
+func (mysqlDialect) Name() string       { return "mysql" }
+func (mysqlDialect) DriverName() string { return "mysql" }
+func (mysqlDialect) Now() string        { return "NOW()" }
+func (mysqlDialect) ForUpdate() string  { return " FOR UPDATE" }
+
+func (mysqlDialect) TimeKey(expr string) string { return expr }
+
+func (mysqlDialect) InsertIgnore(table string, columns []string) string {
+	return fmt.Sprintf("INSERT IGNORE INTO %s (%s) VALUES (%s)",
+		table, strings.Join(columns, ", "), placeholders(len(columns)))
+}
+
+func (d mysqlDialect) Upsert(table string, columns, conflict, update []string, assignments ...string) string {
+	sets := make([]string, 0, len(update)+len(assignments))
+	for _, column := range update {
+		sets = append(sets, fmt.Sprintf("%s = %s", column, d.Excluded(column)))
+	}
+	sets = append(sets, assignments...)
+	if len(sets) == 0 {
+		// MySQL 没有 DO NOTHING，用无副作用的自赋值代替
+		sets = append(sets, fmt.Sprintf("%s = %s", conflict[0], conflict[0]))
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s",
+		table, strings.Join(columns, ", "), placeholders(len(columns)), strings.Join(sets, ", "))
+}
+
+func (mysqlDialect) Excluded(column string) string {
+	return fmt.Sprintf("VALUES(%s)", column)
+}
+
+func (mysqlDialect) FullTextMatch(index fullTextIndex) string {
+	return fmt.Sprintf("MATCH(%s) AGAINST (? IN BOOLEAN MODE)", strings.Join(index.columns, ", "))
+}
+
+func (d mysqlDialect) FullTextScore(index fullTextIndex) string {
+	return d.FullTextMatch(index)
+}
+
+// FullTextTerm 全文索引使用 ngram 分词（ngram_token_size 默认为 2），
+// 布尔模式下检索词按短语匹配，中英文都相当于子串匹配
+func (mysqlDialect) FullTextTerm(term string) (string, bool) {
+	term = strings.ReplaceAll(term, `"`, "")
+	if utf8.RuneCountInString(term) < 2 {
+		return "", false
+	}
+	return `"` + term + `"`, true
+}
+
+// SnapshotTxOptions InnoDB 的可重复读事务在第一次读取时建立快照
+func (mysqlDialect) SnapshotTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true}
+}
+
+func (mysqlDialect) IsRetryable(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	if errors.As(err, &mysqlErr) {
+		switch mysqlErr.Number {
+		case 1213, // ER_LOCK_DEADLOCK
+			1205: // ER_LOCK_WAIT_TIMEOUT
+			return true
+		}
+	}
+	return false
+}
+
+func (mysqlDialect) IsDuplicate(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 // ER_DUP_ENTRY
+}
+
+// ==================== SQLite ====================
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) Name() string       { return "sqlite" }
+func (sqliteDialect) DriverName() string { return "sqlite" }
+func (sqliteDialect) Now() string        { return "CURRENT_TIMESTAMP" }
+
+// ForUpdate SQLite 在写事务中锁定整个数据库，不需要行锁
+func (sqliteDialect) ForUpdate() string { return "" }
+
+// TimeKey SQLite 以文本保存时间，列默认值 CURRENT_TIMESTAMP 与驱动写入的格式不同，
+// 不能直接按字符串比较，统一换算为 UTC 的毫秒精度文本
+func (sqliteDialect) TimeKey(expr string) string {
+	return "strftime('%Y-%m-%d %H:%M:%f', " + expr + ")"
+}
+
+func (sqliteDialect) InsertIgnore(table string, columns []string) string {
+	return fmt.Sprintf("INSERT OR IGNORE INTO %s (%s) VALUES (%s)",
+		table, strings.Join(columns, ", "), placeholders(len(columns)))
+}
+
+func (d sqliteDialect) Upsert(table string, columns, conflict, update []string, assignments ...string) string {
+	sets := make([]string, 0, len(update)+len(assignments))
+	for _, column := range update {
+		sets = append(sets, fmt.Sprintf("%s = %s", column, d.Excluded(column)))
+	}
+	sets = append(sets, assignments...)
+
+	action := "DO NOTHING"
+	if len(sets) > 0 {
+		action = "DO UPDATE SET " + strings.Join(sets, ", ")
+	}
+	return fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) %s",
+		table, strings.Join(columns, ", "), placeholders(len(columns)), strings.Join(conflict, ", "), action)
+}
+
+func (sqliteDialect) Excluded(column string) string {
+	return "excluded." + column
+}
+
+// FullTextMatch 使用 schema 中与主表同步的 FTS5 虚拟表 <table>_fts
+func (sqliteDialect) FullTextMatch(index fullTextIndex) string {
+	return fmt.Sprintf("%s IN (SELECT rowid FROM %s_fts WHERE %s_fts MATCH ?)", index.idColumn, index.table, index.table)
+}
+
+// FullTextScore bm25 越小越相关，取反后与 MySQL 的相关度方向一致；主表需以表名引用
+func (sqliteDialect) FullTextScore(index fullTextIndex) string {
+	return fmt.Sprintf("COALESCE((SELECT -bm25(%[1]s_fts) FROM %[1]s_fts WHERE %[1]s_fts MATCH ? AND rowid = %[1]s.%[2]s), 0)",
+		index.table, index.idColumn)
+}
+
+// FullTextTerm 全文索引使用 trigram 分词，检索词按短语做子串匹配，至少需要三个字符
+func (sqliteDialect) FullTextTerm(term string) (string, bool) {
+	if utf8.RuneCountInString(term) < 3 {
+		return "", false
+	}
+	return `"` + strings.ReplaceAll(term, `"`, `""`) + `"`, true
+}
+
+// SnapshotTxOptions SQLite 的事务本身是可串行化的，读事务在第一次读取后看到固定的快照
+func (sqliteDialect) SnapshotTxOptions() *sql.TxOptions {
+	return &sql.TxOptions{ReadOnly: true}
+}
+
+func (sqliteDialect) IsRetryable(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() & 0xff {
+		case sqlite3.SQLITE_BUSY, sqlite3.SQLITE_LOCKED:
+			return true
+		}
+	}
+	return false
+}
+
+func (sqliteDialect) IsDuplicate(err error) bool {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		switch sqliteErr.Code() {
+		case sqlite3.SQLITE_CONSTRAINT_UNIQUE, sqlite3.SQLITE_CONSTRAINT_PRIMARYKEY:
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/pagination"
+	"strings"
+	"time"
+)
+
+// sortField 可作为排序键的字段
+type sortField int
+
+const (
+	byCreatedAt sortField = iota
+	byViews
+	byLoves
+	byCollections
+	byRelevance
+)
+
+// sortValues 一行记录上可用作排序键的值
+type sortValues struct {
+	createdAt   time.Time
+	views       int
+	loves       int
+	collections int
+	// relevance 检索相关度，见 textSearch
+	relevance int64
+}
+
+// keyset 一种排序方式：依次按 column、kindColumn（可选）、idColumn 排序，方向相同。
+// 多表合并的列表中ID可能重复，需要用 kindColumn 区分来源表
+type keyset struct {
+	name       string
+	field      sortField
+	column     string
+	kindColumn string
+	idColumn   string
+	asc        bool
+}
+
+// resourceSorts 资源列表支持的排序，接口中的 sort 参数即 map 的键
+func resourceSorts(idColumn string) map[string]keyset {
+	return map[string]keyset{
+		"latest":      {name: "latest", field: byCreatedAt, column: "created_at", idColumn: idColumn},
+		"views":       {name: "views", field: byViews, column: "views", idColumn: idColumn},
+		"loves":       {name: "loves", field: byLoves, column: "loves", idColumn: idColumn},
+		"collections": {name: "collections", field: byCollections, column: "collections", idColumn: idColumn},
+	}
+}
+
+// lookupSort 将接口中的排序参数映射为排序方式，未知取值按最新排序
+func lookupSort(sorts map[string]keyset, sort string) keyset {
+	if k, ok := sorts[sort]; ok {
+		return k
+	}
+	return sorts["latest"]
+}
+
+// sortExpr 排序列或占位符在 SQL 中的写法，时间列由方言统一格式
+func (k keyset) sortExpr(d Dialect, expr string) string {
+	if k.field == byCreatedAt {
+		return d.TimeKey(expr)
+	}
+	return expr
+}
+
+func (k keyset) orderBy(d Dialect) string {
+	columns := []string{k.sortExpr(d, k.column)}
+	if k.kindColumn != "" {
+		columns = append(columns, k.kindColumn)
+	}
+	columns = append(columns, k.idColumn)
+	if !k.asc {
+		for i := range columns {
+			columns[i] += " DESC"
+		}
+	}
+	return strings.Join(columns, ", ")
+}
+
+// where 返回排在游标之后的条件；游标来自其他排序时返回 ErrInvalidCursor
+func (k keyset) where(d Dialect, after *pagination.Key) (string, []interface{}, error) {
+	if after == nil {
+		return "", nil, nil
+	}
+	if after.Sort != k.name {
+		return "", nil, pagination.ErrInvalidCursor
+	}
+
+	op := "<"
+	if k.asc {
+		op = ">"
+	}
+	value := k.param(after.Value)
+	tie, tieArgs := k.idColumn+" "+op+" ?", []interface{}{after.ID}
+	if k.kindColumn != "" {
+		tie = "(" + k.kindColumn + " " + op + " ? OR (" + k.kindColumn + " = ? AND " + tie + "))"
+		tieArgs = append([]interface{}{after.Kind, after.Kind}, tieArgs...)
+	}
+	column, param := k.sortExpr(d, k.column), k.sortExpr(d, "?")
+	return "(" + column + " " + op + " " + param + " OR (" + column + " = " + param + " AND " + tie + "))",
+		append([]interface{}{value, value}, tieArgs...), nil
+}
+
+// param 将游标中的排序键还原为查询参数
+func (k keyset) param(value int64) interface{} {
+	if k.field == byCreatedAt {
+		return time.Unix(0, value)
+	}
+	return value
+}
+
+func (k keyset) key(v sortValues, id int) pagination.Key {
+	var value int64
+	switch k.field {
+	case byCreatedAt:
+		value = v.createdAt.UnixNano()
+	case byViews:
+		value = int64(v.views)
+	case byLoves:
+		value = int64(v.loves)
+	case byCollections:
+		value = int64(v.collections)
+	case byRelevance:
+		value = v.relevance
+	}
+	return pagination.Key{Sort: k.name, Value: value, ID: id}
+}
+
+// less 判断 a 是否排在 b 之前，内存实现用它排序和定位游标
+func (k keyset) less(a, b pagination.Key) bool {
+	if a.Value != b.Value {
+		return (a.Value < b.Value) == k.asc
+	}
+	if a.Kind != b.Kind {
+		return (a.Kind < b.Kind) == k.asc
+	}
+	if a.ID != b.ID {
+		return (a.ID < b.ID) == k.asc
+	}
+	return false
+}
+
+// pageQuery 拼接键集分页查询。where 为筛选条件（不含游标），多取一条用于判断是否还有下一页
+func pageQuery(d Dialect, selectFrom string, where []string, args []interface{}, sort keyset, page pagination.Page) (string, []interface{}, error) {
+	after, afterArgs, err := sort.where(d, page.After)
+	if err != nil {
+		return "", nil, err
+	}
+
+	conditions := where
+	queryArgs := append([]interface{}{}, args...)
+	if after != "" {
+		conditions = append(append([]string{}, where...), after)
+		queryArgs = append(queryArgs, afterArgs...)
+	}
+
+	query := selectFrom
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY ` + sort.orderBy(d) + ` LIMIT ?`
+	queryArgs = append(queryArgs, normalizeLimit(page.Limit)+1)
+	return query, queryArgs, nil
+}
+
+// pageTotal 请求总数时统计满足筛选条件的行数，否则返回 nil
+func pageTotal(ctx context.Context, db *Database, from string, where []string, args []interface{}, page pagination.Page) (*int, error) {
+	if !page.WithTotal {
+		return nil, nil
+	}
+
+	query := `SELECT COUNT(*) FROM ` + from
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+
+	var total int
+	if err := db.QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+		return nil, fmt.Errorf("failed to count rows: %w", err)
+	}
+	return &total, nil
+}
@@ -0,0 +1,834 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"sort"
+	"sync"
+	"time"
+)
+
+// errDuplicateEntry 内存实现中违反唯一约束时返回的错误
+var errDuplicateEntry = errors.New("duplicate entry")
+
+// MemoryStore 是各仓库内存实现共用的数据，结构与 schema.sql 中的表一一对应，
+// 并模拟唯一约束、外键级联删除和分页，用于不依赖数据库的服务层测试
+type MemoryStore struct {
+	mu  sync.Mutex
+	seq map[string]int
+
+	users          map[int]*memUser
+	tools          map[int]*memTool
+	courses        map[int]*memCourse
+	courseWeb      map[int]*memCourseResource
+	courseUpload   map[int]*memCourseResource
+	projects       map[int]*memProject
+	comments       map[int]*memComment
+	likes          map[relationKey]*memRelation
+	collections    map[relationKey]*memRelation
+	courseContribs map[int][]int
+	dailyViews     map[dailyViewKey]int
+	savedSearches  map[int]*memSavedSearch
+	notifications  map[int]*memNotification
+	statusLogs     map[int]*memStatusLog
+	commentReports map[int]*memCommentReport
+	sensitiveWords map[int]*model.SensitiveWord
+	auditLogs      map[int]*model.AuditLog
+	reviewClaims   map[model.ReviewTarget]*memReviewClaim
+}
+
+// memCounters 资源表上的计数列
+type memCounters struct {
+	views       int
+	loves       int
+	collections int
+}
+
+// memReview 资源表上的审核相关列
+type memReview struct {
+	status       string
+	auditTime    *time.Time
+	rejectReason *string
+	submitterID  int // 0 表示 NULL
+}
+
+// memTrash 资源表上的软删除列
+type memTrash struct {
+	deletedAt *time.Time
+	deletedBy int // 0 表示 NULL
+}
+
+// memVersion 资源表上的乐观锁版本号
+type memVersion struct {
+	version int
+}
+
+// match 对应 SQL 实现中的 versionMatch 条件
+func (v memVersion) match(version int) bool {
+	return version == 0 || v.version == version
+}
+
+type memUser struct {
+	model.User
+}
+
+type memTool struct {
+	memCounters
+	memReview
+	memTrash
+	memVersion
+	id           int
+	name         string
+	link         string
+	description  string
+	detail       string
+	category     string
+	createdAt    time.Time
+	updatedAt    time.Time
+	tags         []string
+	images       []string
+	contributors []int
+}
+
+type memCourse struct {
+	memCounters
+	memTrash
+	memVersion
+	id         int
+	name       string
+	semester   string
+	credit     int
+	cover      string
+	createdAt  time.Time
+	teachers   []string
+	categories []string
+}
+
+type memCourseResource struct {
+	memReview
+	id        int
+	courseID  int
+	intro     string
+	resource  string
+	sortOrder int
+	createdAt time.Time
+}
+
+type memProject struct {
+	memCounters
+	memReview
+	memTrash
+	memVersion
+	id          int
+	name        string
+	description string
+	detail      string
+	github      string
+	category    string
+	cover       string
+	createdAt   time.Time
+	updatedAt   time.Time
+	techStack   []string
+	images      []string
+	authors     []int
+}
+
+type memComment struct {
+	id           int
+	resourceType string
+	resourceID   int
+	parentID     int // 0 表示顶层评论
+	userID       int
+	content      string
+	loveCount    int
+	replyTotal   int
+	createdAt    time.Time
+	deletedAt    *time.Time
+	hiddenAt     *time.Time
+}
+
+type relationKey struct {
+	userID       int
+	resourceType string
+	resourceID   int
+}
+
+type memRelation struct {
+	id        int
+	createdAt time.Time
+}
+
+type dailyViewKey struct {
+	resourceType string
+	resourceID   int
+	date         string
+}
+
+type memSavedSearch struct {
+	model.SavedSearch
+	createdAt time.Time
+}
+
+type memNotification struct {
+	model.Notification
+}
+
+type memCommentReport struct {
+	id         int
+	commentID  int
+	userID     int // 0 表示系统标记
+	reason     string
+	detail     string
+	createdAt  time.Time
+	resolvedAt *time.Time
+	resolution string
+	resolvedBy int // 0 表示 NULL
+}
+
+type memReviewClaim struct {
+	assigneeID int // 0 表示 NULL
+	assignSeq  int
+	assignedAt *time.Time
+	claimantID int // 0 表示 NULL
+	expiresAt  *time.Time
+}
+
+type memStatusLog struct {
+	id           int
+	resourceType string
+	resourceID   int
+	oldStatus    string
+	newStatus    string
+	operatorID   int // 0 表示 NULL
+	operateTime  time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		seq:            make(map[string]int),
+		users:          make(map[int]*memUser),
+		tools:          make(map[int]*memTool),
+		courses:        make(map[int]*memCourse),
+		courseWeb:      make(map[int]*memCourseResource),
+		courseUpload:   make(map[int]*memCourseResource),
+		projects:       make(map[int]*memProject),
+		comments:       make(map[int]*memComment),
+		likes:          make(map[relationKey]*memRelation),
+		collections:    make(map[relationKey]*memRelation),
+		courseContribs: make(map[int][]int),
+		dailyViews:     make(map[dailyViewKey]int),
+		savedSearches:  make(map[int]*memSavedSearch),
+		notifications:  make(map[int]*memNotification),
+		statusLogs:     make(map[int]*memStatusLog),
+		commentReports: make(map[int]*memCommentReport),
+		sensitiveWords: make(map[int]*model.SensitiveWord),
+		auditLogs:      make(map[int]*model.AuditLog),
+		reviewClaims:   make(map[model.ReviewTarget]*memReviewClaim),
+	}
+}
+
+// WithTx 内存版事务：fn 返回错误时恢复到执行前的快照。
+// 同一时刻只有一个事务，嵌套调用直接加入外层事务
+func (s *MemoryStore) WithTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(memTxKey{}) != nil {
+		return fn(ctx)
+	}
+
+	s.mu.Lock()
+	snapshot := s.clone()
+	s.mu.Unlock()
+
+	if err := fn(context.WithValue(ctx, memTxKey{}, true)); err != nil {
+		s.mu.Lock()
+		s.restore(snapshot)
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+type memTxKey struct{}
+
+// CreateCourse 写入一门课程及其教师、分类；接口中没有创建课程的操作，供测试和开发数据使用
+func (s *MemoryStore) CreateCourse(ctx context.Context, course model.Course) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.nextID("courses")
+	s.courses[id] = &memCourse{
+		memCounters: memCounters{views: course.Views, loves: course.Loves, collections: course.Collections},
+		memVersion:  memVersion{version: 1},
+		id:          id,
+		name:        course.Name,
+		semester:    course.Semester,
+		credit:      course.Credit,
+		cover:       course.Cover,
+		createdAt:   time.Now(),
+		teachers:    append([]string(nil), course.Teacher...),
+		categories:  append([]string(nil), course.Category...),
+	}
+	return id, nil
+}
+
+// SetStatus 直接修改资源的审核状态，供测试和开发数据使用
+func (s *MemoryStore) SetStatus(ctx context.Context, resourceType string, resourceID int, status string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	review := s.review(resourceType, resourceID)
+	if review == nil {
+		return fmt.Errorf("%s not found", resourceType)
+	}
+	now := time.Now()
+	review.status = status
+	review.auditTime = &now
+	return nil
+}
+
+// SetCounter 直接修改计数列，table 为 tools/courses/projects/comments，供测试使用
+func (s *MemoryStore) SetCounter(ctx context.Context, table string, id int, column string, value int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if table == "comments" {
+		c, ok := s.comments[id]
+		if !ok || column != "reply_total" {
+			return fmt.Errorf("unknown counter: %s.%s", table, column)
+		}
+		c.replyTotal = value
+		return nil
+	}
+
+	var counters *memCounters
+	for _, resourceType := range resourceTypes {
+		if resourceTables[resourceType].table == table {
+			counters = s.rawCounters(resourceType, id)
+		}
+	}
+	if counters == nil {
+		return fmt.Errorf("%s %d not found", table, id)
+	}
+	switch column {
+	case "views":
+		counters.views = value
+	case "loves":
+		counters.loves = value
+	case "collections":
+		counters.collections = value
+	default:
+		return fmt.Errorf("unknown counter: %s.%s", table, column)
+	}
+	return nil
+}
+
+// InsertRelation 不检查资源是否存在，直接写入一条点赞或收藏，供测试使用
+func (s *MemoryStore) InsertRelation(ctx context.Context, table string, userID int, resourceType string, resourceID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	relations := map[string]map[relationKey]*memRelation{"likes": s.likes, "collections": s.collections}[table]
+	if relations == nil {
+		return fmt.Errorf("unknown table: %s", table)
+	}
+	key := relationKey{userID: userID, resourceType: resourceType, resourceID: resourceID}
+	if _, ok := relations[key]; ok {
+		return fmt.Errorf("failed to insert into %s: %w", table, errDuplicateEntry)
+	}
+	relations[key] = &memRelation{id: s.nextID(table), createdAt: time.Now()}
+	return nil
+}
+
+func (s *MemoryStore) nextID(table string) int {
+	s.seq[table]++
+	return s.seq[table]
+}
+
+func (s *MemoryStore) review(resourceType string, resourceID int) *memReview {
+	switch resourceType {
+	case model.ResourceTypeTool:
+		if t, ok := s.tools[resourceID]; ok {
+			return &t.memReview
+		}
+	case model.ResourceTypeProject:
+		if p, ok := s.projects[resourceID]; ok {
+			return &p.memReview
+		}
+	case model.ResourceTypeCourseWeb:
+		if r, ok := s.courseWeb[resourceID]; ok {
+			return &r.memReview
+		}
+	case model.ResourceTypeCourseUpload:
+		if r, ok := s.courseUpload[resourceID]; ok {
+			return &r.memReview
+		}
+	}
+	return nil
+}
+
+// counters 返回资源的计数列，资源不存在或在回收站中时返回 nil
+func (s *MemoryStore) counters(resourceType string, resourceID int) *memCounters {
+	switch resourceType {
+	case model.ResourceTypeTool:
+		if t, ok := s.tools[resourceID]; ok && t.deletedAt == nil {
+			return &t.memCounters
+		}
+	case model.ResourceTypeCourse:
+		if c, ok := s.courses[resourceID]; ok && c.deletedAt == nil {
+			return &c.memCounters
+		}
+	case model.ResourceTypeProject:
+		if p, ok := s.projects[resourceID]; ok && p.deletedAt == nil {
+			return &p.memCounters
+		}
+	}
+	return nil
+}
+
+// liveCourse 返回未删除的课程
+func (s *MemoryStore) liveCourse(courseID int) (*memCourse, bool) {
+	c, ok := s.courses[courseID]
+	if !ok || c.deletedAt != nil {
+		return nil, false
+	}
+	return c, true
+}
+
+// ==================== 快照 ====================
+
+func cloneRows[K comparable, V any](rows map[K]*V) map[K]*V {
+	result := make(map[K]*V, len(rows))
+	for k, v := range rows {
+		c := *v
+		result[k] = &c
+	}
+	return result
+}
+
+// clone 复制全部数据；行中的切片只会整体替换、不会原地修改，浅拷贝即可
+func (s *MemoryStore) clone() *MemoryStore {
+	c := &MemoryStore{
+		seq:            make(map[string]int, len(s.seq)),
+		users:          cloneRows(s.users),
+		tools:          cloneRows(s.tools),
+		courses:        cloneRows(s.courses),
+		courseWeb:      cloneRows(s.courseWeb),
+		courseUpload:   cloneRows(s.courseUpload),
+		projects:       cloneRows(s.projects),
+		comments:       cloneRows(s.comments),
+		likes:          cloneRows(s.likes),
+		collections:    cloneRows(s.collections),
+		courseContribs: make(map[int][]int, len(s.courseContribs)),
+		dailyViews:     make(map[dailyViewKey]int, len(s.dailyViews)),
+		savedSearches:  cloneRows(s.savedSearches),
+		notifications:  cloneRows(s.notifications),
+		statusLogs:     cloneRows(s.statusLogs),
+		commentReports: cloneRows(s.commentReports),
+		sensitiveWords: cloneRows(s.sensitiveWords),
+		auditLogs:      cloneRows(s.auditLogs),
+		reviewClaims:   cloneRows(s.reviewClaims),
+	}
+	for k, v := range s.seq {
+		c.seq[k] = v
+	}
+	for k, v := range s.courseContribs {
+		c.courseContribs[k] = v
+	}
+	for k, v := range s.dailyViews {
+		c.dailyViews[k] = v
+	}
+	return c
+}
+
+func (s *MemoryStore) restore(c *MemoryStore) {
+	s.seq = c.seq
+	s.users = c.users
+	s.tools = c.tools
+	s.courses = c.courses
+	s.courseWeb = c.courseWeb
+	s.courseUpload = c.courseUpload
+	s.projects = c.projects
+	s.comments = c.comments
+	s.likes = c.likes
+	s.collections = c.collections
+	s.courseContribs = c.courseContribs
+	s.dailyViews = c.dailyViews
+	s.savedSearches = c.savedSearches
+	s.notifications = c.notifications
+	s.statusLogs = c.statusLogs
+	s.commentReports = c.commentReports
+	s.sensitiveWords = c.sensitiveWords
+	s.auditLogs = c.auditLogs
+	s.reviewClaims = c.reviewClaims
+}
+
+// ==================== 查询辅助 ====================
+
+func (c memCounters) sortValues(createdAt time.Time) sortValues {
+	return sortValues{createdAt: createdAt, views: c.views, loves: c.loves, collections: c.collections}
+}
+
+// sortByKey 按排序方式排序，与 SQL 实现中 keyset.orderBy 的顺序一致
+func sortByKey[T any](items []T, by keyset, key func(T) pagination.Key) {
+	sort.SliceStable(items, func(i, j int) bool {
+		return by.less(key(items[i]), key(items[j]))
+	})
+}
+
+// pageItems 内存实现的键集分页，结果与 pageQuery + pagination.NewResult 一致
+func pageItems[T any](items []T, by keyset, key func(T) pagination.Key, page pagination.Page) (*pagination.Result[T], error) {
+	if page.After != nil && page.After.Sort != by.name {
+		return nil, pagination.ErrInvalidCursor
+	}
+	sortByKey(items, by, key)
+
+	limit := normalizeLimit(page.Limit)
+	var rest []T
+	var keys []pagination.Key
+	for _, item := range items {
+		k := key(item)
+		if page.After != nil && !by.less(*page.After, k) {
+			continue
+		}
+		rest = append(rest, item)
+		keys = append(keys, k)
+		if len(rest) > limit {
+			break
+		}
+	}
+
+	result := pagination.NewResult(rest, keys, limit)
+	if page.WithTotal {
+		total := len(items)
+		result.Total = &total
+	}
+	return result, nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAny(values, targets []string) bool {
+	for _, t := range targets {
+		if containsString(values, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsInt(values []int, target int) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+func removeInt(values []int, target int) []int {
+	var result []int
+	for _, v := range values {
+		if v != target {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func formatTimePtr(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+	s := formatTime(*t)
+	return &s
+}
+
+func copyStringPtr(s *string) *string {
+	if s == nil {
+		return nil
+	}
+	c := *s
+	return &c
+}
+
+// displayName 对应 SQL 中的 COALESCE(u.nickname, u.username)
+func (s *MemoryStore) displayName(userID int) (string, bool) {
+	u, ok := s.users[userID]
+	if !ok {
+		return "", false
+	}
+	return u.Nickname, true
+}
+
+// displayNames 按顺序返回用户显示名，已删除的用户被跳过
+func (s *MemoryStore) displayNames(userIDs []int) []string {
+	names := []string{}
+	for _, id := range userIDs {
+		if name, ok := s.displayName(id); ok {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// publicUsers 按顺序返回用户的公开信息
+func (s *MemoryStore) publicUsers(userIDs []int) []model.User {
+	users := []model.User{}
+	for _, id := range userIDs {
+		if u, ok := s.users[id]; ok {
+			users = append(users, publicUser(u.User))
+		}
+	}
+	return users
+}
+
+// submitterName 对应 SQL 中的 COALESCE(u.nickname, u.username, "")，提交者为空时返回空串
+func (s *MemoryStore) submitterName(userID int) string {
+	name, _ := s.displayName(userID)
+	return name
+}
+
+// ==================== 点赞 / 收藏 / 浏览 ====================
+
+func (s *MemoryStore) toggleRelation(relations map[relationKey]*memRelation, table string, userID int, resourceType string, resourceID int, add bool) (*memCounters, error) {
+	if _, ok := resourceTables[resourceType]; !ok {
+		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+	counters := s.counters(resourceType, resourceID)
+	if counters == nil {
+		return nil, fmt.Errorf("%s not found", resourceType)
+	}
+	if _, ok := s.users[userID]; !ok && add {
+		return nil, fmt.Errorf("failed to insert into %s: user %d does not exist", table, userID)
+	}
+
+	key := relationKey{userID: userID, resourceType: resourceType, resourceID: resourceID}
+	_, has := relations[key]
+
+	delta := 0
+	if add && !has {
+		relations[key] = &memRelation{id: s.nextID(table), createdAt: time.Now()}
+		delta = 1
+	}
+	if !add && has {
+		delete(relations, key)
+		delta = -1
+	}
+
+	switch table {
+	case "likes":
+		counters.loves = max(counters.loves+delta, 0)
+	case "collections":
+		counters.collections = max(counters.collections+delta, 0)
+	}
+	return counters, nil
+}
+
+func (s *MemoryStore) setLike(userID int, resourceType string, resourceID int, liked bool) (*model.LikeStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters, err := s.toggleRelation(s.likes, "likes", userID, resourceType, resourceID, liked)
+	if err != nil {
+		return nil, err
+	}
+	return &model.LikeStatus{IsLiked: liked, Likes: counters.loves}, nil
+}
+
+func (s *MemoryStore) setCollect(userID int, resourceType string, resourceID int, collected bool) (*model.CollectStatus, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters, err := s.toggleRelation(s.collections, "collections", userID, resourceType, resourceID, collected)
+	if err != nil {
+		return nil, err
+	}
+	return &model.CollectStatus{IsCollected: collected, Collections: counters.collections}, nil
+}
+
+func (s *MemoryStore) addView(resourceType string, resourceID int) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counters := s.counters(resourceType, resourceID)
+	if counters == nil {
+		return 0, fmt.Errorf("%s not found", resourceType)
+	}
+	counters.views++
+	return counters.views, nil
+}
+
+// ==================== 评论 ====================
+
+func (s *MemoryStore) commentModel(c *memComment) *model.Comment {
+	comment := &model.Comment{
+		CommentID:   c.id,
+		Comment:     c.content,
+		LoveCount:   c.loveCount,
+		ReplyTotal:  c.replyTotal,
+		CommentDate: formatTime(c.createdAt),
+		Replies:     []model.Comment{},
+	}
+	if u, ok := s.users[c.userID]; ok {
+		comment.Nickname = u.Nickname
+		comment.Avatar = u.Avatar
+	}
+	if c.parentID != 0 {
+		id := c.parentID
+		comment.ParentID = &id
+		comment.IsReply = true
+	}
+	if c.deletedAt != nil {
+		comment.DeleteDate = formatTime(*c.deletedAt)
+	}
+	return comment
+}
+
+// activeComments 资源下未删除、未隐藏的评论，按发表时间排序
+func (s *MemoryStore) activeComments(resourceType string, resourceID int) []*memComment {
+	var result []*memComment
+	for _, c := range s.comments {
+		if c.resourceType == resourceType && c.resourceID == resourceID && c.visible() {
+			result = append(result, c)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].createdAt.Equal(result[j].createdAt) {
+			return result[i].createdAt.Before(result[j].createdAt)
+		}
+		return result[i].id < result[j].id
+	})
+	return result
+}
+
+func (s *MemoryStore) listComments(resourceType string, resourceID int) ([]model.Comment, int) {
+	active := s.activeComments(resourceType, resourceID)
+	all := make([]*model.Comment, len(active))
+	for i, c := range active {
+		all[i] = s.commentModel(c)
+	}
+	return buildCommentTree(all), len(active)
+}
+
+// pageComments 分页获取顶层评论，每条评论带上它下面全部的回复
+func (s *MemoryStore) pageComments(resourceType string, resourceID int, page pagination.Page) (*pagination.Result[model.Comment], error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var roots []*memComment
+	var all []*model.Comment
+	for _, c := range s.activeComments(resourceType, resourceID) {
+		if c.parentID == 0 {
+			roots = append(roots, c)
+		} else {
+			all = append(all, s.commentModel(c))
+		}
+	}
+
+	result, err := pageItems(roots, commentSort, func(c *memComment) pagination.Key {
+		return commentSort.key(sortValues{createdAt: c.createdAt}, c.id)
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range result.Items {
+		all = append(all, s.commentModel(c))
+	}
+	return &pagination.Result[model.Comment]{Items: buildCommentTree(all), Info: result.Info}, nil
+}
+
+func (s *MemoryStore) addComment(userID int, resourceType string, resourceID int, parentID *int, content string) (*model.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := resourceTables[resourceType]; !ok {
+		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+	if s.counters(resourceType, resourceID) == nil {
+		return nil, fmt.Errorf("%s not found", resourceType)
+	}
+
+	var parent *memComment
+	if parentID != nil {
+		p, ok := s.comments[*parentID]
+		if !ok || p.resourceType != resourceType || p.resourceID != resourceID || !p.visible() {
+			return nil, fmt.Errorf("comment not found")
+		}
+		parent = p
+	}
+	if _, ok := s.users[userID]; !ok {
+		return nil, fmt.Errorf("failed to create comment: user %d does not exist", userID)
+	}
+
+	c := &memComment{
+		id:           s.nextID("comments"),
+		resourceType: resourceType,
+		resourceID:   resourceID,
+		userID:       userID,
+		content:      content,
+		createdAt:    time.Now(),
+	}
+	if parent != nil {
+		c.parentID = parent.id
+		parent.replyTotal++
+	}
+	s.comments[c.id] = c
+
+	return s.commentModel(c), nil
+}
+
+func (s *MemoryStore) deleteComment(userID int, resourceType string, resourceID, commentID int, reply bool) (*model.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.comments[commentID]
+	if !ok {
+		return nil, fmt.Errorf("comment not found")
+	}
+	if (c.parentID != 0) != reply {
+		if reply {
+			return nil, fmt.Errorf("comment is not a reply")
+		}
+		return nil, fmt.Errorf("comment is a reply")
+	}
+	if c.userID != userID || c.resourceType != resourceType || c.resourceID != resourceID || c.deletedAt != nil {
+		return nil, fmt.Errorf("comment not found")
+	}
+
+	now := time.Now()
+	c.deletedAt = &now
+	if parent, ok := s.comments[c.parentID]; ok && parent.replyTotal > 0 {
+		parent.replyTotal--
+	}
+
+	return s.commentModel(c), nil
+}
+
+// visible 评论未删除也未隐藏
+func (c *memComment) visible() bool {
+	return c.deletedAt == nil && c.hiddenAt == nil
+}
+
+// deleteCommentRows 物理删除评论及其全部回复和举报，对应外键 parent_id、comment_id 的 ON DELETE CASCADE
+func (s *MemoryStore) deleteCommentRows(commentID int) {
+	delete(s.comments, commentID)
+	for id, report := range s.commentReports {
+		if report.commentID == commentID {
+			delete(s.commentReports, id)
+		}
+	}
+	for id, c := range s.comments {
+		if c.parentID == commentID {
+			s.deleteCommentRows(id)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"strings"
+	"time"
+)
+
+type memoryAuditLogRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryAuditLogRepository(store *MemoryStore) AuditLogRepository {
+	return &memoryAuditLogRepository{store: store}
+}
+
+func (r *memoryAuditLogRepository) Append(ctx context.Context, entry *model.AuditLog) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry.ID = s.nextID("admin_audit_logs")
+	entry.CreatedTime = now
+	entry.CreatedAt = formatTime(now)
+
+	saved := *entry
+	saved.Before = rawJSON(string(entry.Before))
+	saved.After = rawJSON(string(entry.After))
+	s.auditLogs[saved.ID] = &saved
+	return nil
+}
+
+// auditLogMatches 与 SQL 实现的 WHERE 条件相同
+func auditLogMatches(entry *model.AuditLog, filter model.AuditFilter) bool {
+	switch {
+	case filter.ActorID != 0 && entry.ActorID != filter.ActorID,
+		filter.Actor != "" && entry.Actor != filter.Actor,
+		filter.Action != "" && entry.Action != filter.Action && !strings.HasPrefix(entry.Action, filter.Action+"."),
+		filter.TargetType != "" && entry.TargetType != filter.TargetType,
+		filter.TargetID != 0 && entry.TargetID != filter.TargetID,
+		!filter.From.IsZero() && entry.CreatedTime.Before(filter.From),
+		!filter.To.IsZero() && !entry.CreatedTime.Before(filter.To):
+		return false
+	}
+	return true
+}
+
+func (r *memoryAuditLogRepository) List(ctx context.Context, filter model.AuditFilter, page pagination.Page) (*pagination.Result[model.AuditLog], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []model.AuditLog
+	for _, entry := range s.auditLogs {
+		if auditLogMatches(entry, filter) {
+			item := *entry
+			item.Before = append(json.RawMessage(nil), entry.Before...)
+			item.After = append(json.RawMessage(nil), entry.After...)
+			items = append(items, item)
+		}
+	}
+	return pageItems(items, auditLogSort, func(entry model.AuditLog) pagination.Key {
+		return auditLogSort.key(sortValues{createdAt: entry.CreatedTime}, entry.ID)
+	}, page)
+}
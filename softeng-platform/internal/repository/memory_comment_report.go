@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"time"
+)
+
+type memoryCommentReportRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryCommentReportRepository(store *MemoryStore) CommentReportRepository {
+	return &memoryCommentReportRepository{store: store}
+}
+
+// reportedComment 评论及其待处理举报的汇总，评论不存在或已删除时返回 nil
+func (s *MemoryStore) reportedComment(commentID int) *model.ReportedComment {
+	c, ok := s.comments[commentID]
+	if !ok || c.deletedAt != nil {
+		return nil
+	}
+	author, _ := s.displayName(c.userID)
+	comment := &model.ReportedComment{
+		CommentID:    c.id,
+		ResourceType: c.resourceType,
+		ResourceID:   c.resourceID,
+		AuthorID:     c.userID,
+		Author:       author,
+		Content:      c.content,
+		CommentDate:  formatTime(c.createdAt),
+		Hidden:       c.hiddenAt != nil,
+		Reasons:      map[string]int{},
+	}
+
+	var first *memCommentReport
+	for _, report := range s.commentReports {
+		if report.commentID != commentID || report.resolvedAt != nil {
+			continue
+		}
+		comment.Reasons[report.reason]++
+		comment.Reports++
+		if first == nil || report.id < first.id {
+			first = report
+		}
+	}
+	if first != nil {
+		comment.ReportedTime = first.createdAt
+		comment.ReportedAt = formatTime(first.createdAt)
+	}
+	return comment
+}
+
+func (r *memoryCommentReportRepository) Get(ctx context.Context, commentID int) (*model.ReportedComment, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reportedComment(commentID), nil
+}
+
+func (r *memoryCommentReportRepository) Report(ctx context.Context, commentID, userID int, reason, detail string) (*model.ReportedComment, bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.comments[commentID]
+	if !ok || !c.visible() {
+		return nil, false, nil
+	}
+	if _, ok := s.users[userID]; userID != 0 && !ok {
+		return nil, false, fmt.Errorf("failed to create report: user %d does not exist", userID)
+	}
+
+	for _, report := range s.commentReports {
+		if report.commentID == commentID && report.userID == userID && report.resolvedAt == nil {
+			return s.reportedComment(commentID), false, nil
+		}
+	}
+	id := s.nextID("comment_reports")
+	s.commentReports[id] = &memCommentReport{
+		id:        id,
+		commentID: commentID,
+		userID:    userID,
+		reason:    reason,
+		detail:    detail,
+		createdAt: time.Now(),
+	}
+	return s.reportedComment(commentID), true, nil
+}
+
+func (r *memoryCommentReportRepository) Hide(ctx context.Context, commentID int) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.comments[commentID]
+	if !ok || !c.visible() {
+		return false, nil
+	}
+	now := time.Now()
+	c.hiddenAt = &now
+	return true, nil
+}
+
+func (r *memoryCommentReportRepository) Pending(ctx context.Context, page pagination.Page) (*pagination.Result[model.ReportedComment], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	seen := make(map[int]bool)
+	var comments []model.ReportedComment
+	for _, report := range s.commentReports {
+		if report.resolvedAt != nil || seen[report.commentID] {
+			continue
+		}
+		seen[report.commentID] = true
+		if comment := s.reportedComment(report.commentID); comment != nil {
+			comments = append(comments, *comment)
+		}
+	}
+
+	result, err := pageItems(comments, pendingReportSort, func(c model.ReportedComment) pagination.Key {
+		return pendingReportSort.key(sortValues{createdAt: c.ReportedTime}, c.CommentID)
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+	if page.WithTotal {
+		total := len(comments)
+		result.Total = &total
+	}
+	return result, nil
+}
+
+func (r *memoryCommentReportRepository) Resolve(ctx context.Context, commentID, moderatorID int, action string) (*model.ReportedComment, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	before := s.reportedComment(commentID)
+	if before == nil {
+		return nil, nil
+	}
+
+	c := s.comments[commentID]
+	now := time.Now()
+	switch action {
+	case model.ModerationDismiss:
+		c.hiddenAt = nil
+	case model.ModerationHide, model.ModerationWarn:
+		if c.hiddenAt == nil {
+			c.hiddenAt = &now
+		}
+	case model.ModerationDelete:
+		c.deletedAt = &now
+		if parent, ok := s.comments[c.parentID]; ok && parent.replyTotal > 0 {
+			parent.replyTotal--
+		}
+	default:
+		return nil, fmt.Errorf("unknown moderation action: %s", action)
+	}
+
+	for _, report := range s.commentReports {
+		if report.commentID == commentID && report.resolvedAt == nil {
+			report.resolvedAt = &now
+			report.resolution = action
+			report.resolvedBy = moderatorID
+		}
+	}
+	return before, nil
+}
@@ -0,0 +1,311 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"sort"
+	"time"
+)
+
+type memoryCourseRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryCourseRepository(store *MemoryStore) CourseRepository {
+	return &memoryCourseRepository{store: store}
+}
+
+func (r *memoryCourseRepository) GetCourses(ctx context.Context, semester string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Course], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	courses := s.sortedCourses(func(c *memCourse) bool {
+		return (semester == "" || c.semester == semester) &&
+			(len(category) == 0 || containsAny(c.categories, category))
+	})
+	return pageCourses(courses, lookupSort(courseSorts, sort), nil, page)
+}
+
+func (r *memoryCourseRepository) GetByID(ctx context.Context, courseID int) (*model.CourseDetail, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.liveCourse(courseID)
+	if !ok {
+		return nil, nil
+	}
+
+	detail := &model.CourseDetail{
+		CourseID:     c.id,
+		ResourceType: model.ResourceTypeCourse,
+		Name:         c.name,
+		Teacher:      nonNil(c.teachers),
+		Category:     nonNil(c.categories),
+		Semester:     c.semester,
+		Credit:       c.credit,
+		Cover:        c.cover,
+		URLForm:      []model.ResourceWeb{},
+		UploadForm:   []model.ResourceUpload{},
+		Contributor:  s.displayNames(s.courseContribs[c.id]),
+		Collections:  c.collections,
+		Views:        c.views,
+		Likes:        c.loves,
+		CreatedAt:    formatTime(c.createdAt),
+		Version:      c.version,
+	}
+	for _, res := range approvedCourseResources(s.courseWeb, courseID) {
+		detail.URLForm = append(detail.URLForm, model.ResourceWeb{ResourceIntro: res.intro, ResourceURL: res.resource, ResourceID: res.id})
+	}
+	for _, res := range approvedCourseResources(s.courseUpload, courseID) {
+		detail.UploadForm = append(detail.UploadForm, model.ResourceUpload{ResourceIntro: res.intro, ResourceUpload: res.resource, ResourceID: res.id})
+	}
+	detail.Comments, detail.CommentTotal = s.listComments(model.ResourceTypeCourse, courseID)
+
+	return detail, nil
+}
+
+func (r *memoryCourseRepository) Search(ctx context.Context, keyword string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Course], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	relevance := make(map[int]int64)
+	courses := s.sortedCourses(func(c *memCourse) bool {
+		if len(category) > 0 && !containsAny(c.categories, category) {
+			return false
+		}
+		score, ok := c.search(keyword)
+		relevance[c.id] = score
+		return ok
+	})
+	return pageCourses(courses, searchSort(courseSearchSorts, sort, keyword), relevance, page)
+}
+
+func (r *memoryCourseRepository) UploadResource(ctx context.Context, userID, courseID int, req model.CourseUploadRequest) (*model.TeachReview, error) {
+	if req.Resource == "" && req.File == "" {
+		return nil, fmt.Errorf("resource or file is required")
+	}
+
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.liveCourse(courseID); !ok {
+		return nil, fmt.Errorf("course not found")
+	}
+	if _, ok := s.users[userID]; !ok {
+		return nil, fmt.Errorf("failed to create course resource: user %d does not exist", userID)
+	}
+
+	now := time.Now()
+	review := &model.TeachReview{
+		ResourceType: model.ResourceTypeCourse,
+		AuditStatus:  model.StatusPending,
+		SubmitTime:   formatTime(now),
+	}
+
+	if req.Resource != "" {
+		res := &memCourseResource{
+			memReview: memReview{status: model.StatusPending, submitterID: userID},
+			id:        s.nextID("course_resources_web"),
+			courseID:  courseID,
+			intro:     req.Description,
+			resource:  req.Resource,
+			createdAt: now,
+		}
+		s.courseWeb[res.id] = res
+		review.ResourceID = res.id
+		review.Resource1 = &model.ResourceWeb{ResourceIntro: req.Description, ResourceURL: req.Resource, ResourceID: res.id}
+	}
+
+	if req.File != "" {
+		res := &memCourseResource{
+			memReview: memReview{status: model.StatusPending, submitterID: userID},
+			id:        s.nextID("course_resources_upload"),
+			courseID:  courseID,
+			intro:     req.Description,
+			resource:  req.File,
+			createdAt: now,
+		}
+		s.courseUpload[res.id] = res
+		if review.ResourceID == 0 {
+			review.ResourceID = res.id
+		}
+		review.Resource2 = &model.ResourceUpload{ResourceIntro: req.Description, ResourceUpload: req.File, ResourceID: res.id}
+	}
+
+	return review, nil
+}
+
+func (r *memoryCourseRepository) DownloadTextbook(ctx context.Context, courseID, textbookID int) (string, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	res, ok := s.courseUpload[textbookID]
+	if !ok || res.courseID != courseID || res.status != model.StatusApproved {
+		return "", fmt.Errorf("textbook not found")
+	}
+	if _, ok := s.liveCourse(courseID); !ok {
+		return "", fmt.Errorf("textbook not found")
+	}
+	return res.resource, nil
+}
+
+func (r *memoryCourseRepository) AddComment(ctx context.Context, userID, courseID int, content string) (*model.Comment, error) {
+	return r.store.addComment(userID, model.ResourceTypeCourse, courseID, nil, content)
+}
+
+func (r *memoryCourseRepository) DeleteComment(ctx context.Context, userID, courseID, commentID int) (*model.Comment, error) {
+	return r.store.deleteComment(userID, model.ResourceTypeCourse, courseID, commentID, false)
+}
+
+func (r *memoryCourseRepository) ReplyComment(ctx context.Context, userID, courseID, commentID int, content string) (*model.Comment, error) {
+	return r.store.addComment(userID, model.ResourceTypeCourse, courseID, &commentID, content)
+}
+
+func (r *memoryCourseRepository) DeleteReply(ctx context.Context, userID, courseID, commentID int) (*model.Comment, error) {
+	return r.store.deleteComment(userID, model.ResourceTypeCourse, courseID, commentID, true)
+}
+
+func (r *memoryCourseRepository) AddView(ctx context.Context, courseID int) (int, error) {
+	return r.store.addView(model.ResourceTypeCourse, courseID)
+}
+
+func (r *memoryCourseRepository) CollectCourse(ctx context.Context, userID, courseID int) (*model.CollectStatus, error) {
+	return r.store.setCollect(userID, model.ResourceTypeCourse, courseID, true)
+}
+
+func (r *memoryCourseRepository) UncollectCourse(ctx context.Context, userID, courseID int) (*model.CollectStatus, error) {
+	return r.store.setCollect(userID, model.ResourceTypeCourse, courseID, false)
+}
+
+func (r *memoryCourseRepository) LikeCourse(ctx context.Context, userID, courseID int) (*model.LikeStatus, error) {
+	return r.store.setLike(userID, model.ResourceTypeCourse, courseID, true)
+}
+
+func (r *memoryCourseRepository) UnlikeCourse(ctx context.Context, userID, courseID int) (*model.LikeStatus, error) {
+	return r.store.setLike(userID, model.ResourceTypeCourse, courseID, false)
+}
+
+func (r *memoryCourseRepository) GetComments(ctx context.Context, courseID int, page pagination.Page) (*pagination.Result[model.Comment], error) {
+	return r.store.pageComments(model.ResourceTypeCourse, courseID, page)
+}
+
+func (r *memoryCourseRepository) GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type pending struct {
+		res          *memCourseResource
+		resourceType string
+	}
+	var list []pending
+	now := time.Now()
+	for _, res := range s.courseWeb {
+		target := model.ReviewTarget{ResourceType: model.ResourceTypeCourseWeb, ResourceID: res.id}
+		if _, ok := s.liveCourse(res.courseID); ok && model.InReviewQueue(res.status) && s.claimMatches(filter, target, now) {
+			list = append(list, pending{res, model.ResourceTypeCourseWeb})
+		}
+	}
+	for _, res := range s.courseUpload {
+		target := model.ReviewTarget{ResourceType: model.ResourceTypeCourseUpload, ResourceID: res.id}
+		if _, ok := s.liveCourse(res.courseID); ok && model.InReviewQueue(res.status) && s.claimMatches(filter, target, now) {
+			list = append(list, pending{res, model.ResourceTypeCourseUpload})
+		}
+	}
+	result, err := pageItems(list, pendingCourseSort, func(p pending) pagination.Key {
+		key := pendingCourseSort.key(sortValues{createdAt: p.res.createdAt}, p.res.id)
+		key.Kind = p.resourceType
+		return key
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.Map(result, func(p pending) model.Submit {
+		item := model.Submit{
+			Submitor:     s.submitterName(p.res.submitterID),
+			SubmitDate:   formatTime(p.res.createdAt),
+			ResourceID:   p.res.id,
+			ResourceType: p.resourceType,
+			Description:  p.res.intro,
+			Tags:         []string{},
+			ResourceName: s.courses[p.res.courseID].name,
+		}
+		if p.resourceType == model.ResourceTypeCourseWeb {
+			item.Link = p.res.resource
+		} else {
+			item.File = p.res.resource
+		}
+		return item
+	}), nil
+}
+
+// sortedCourses 返回满足条件且不在回收站中的课程，按创建时间和ID倒序（即 courseSorts["latest"]）
+func (s *MemoryStore) sortedCourses(match func(c *memCourse) bool) []*memCourse {
+	var result []*memCourse
+	for _, c := range s.courses {
+		if c.deletedAt == nil && match(c) {
+			result = append(result, c)
+		}
+	}
+	sortByKey(result, courseSorts["latest"], courseKey(courseSorts["latest"]))
+	return result
+}
+
+func courseKey(by keyset) func(c *memCourse) pagination.Key {
+	return func(c *memCourse) pagination.Key {
+		return by.key(c.sortValues(c.createdAt), c.id)
+	}
+}
+
+// pageCourses 截取一页课程并转换为接口模型，relevance 为检索结果的相关度
+func pageCourses(courses []*memCourse, by keyset, relevance map[int]int64, page pagination.Page) (*pagination.Result[model.Course], error) {
+	result, err := pageItems(courses, by, func(c *memCourse) pagination.Key {
+		values := c.sortValues(c.createdAt)
+		values.relevance = relevance[c.id]
+		return by.key(values, c.id)
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.Map(result, func(c *memCourse) model.Course {
+		return model.Course{
+			CourseID:     c.id,
+			ResourceType: model.ResourceTypeCourse,
+			Name:         c.name,
+			Teacher:      nonNil(c.teachers),
+			Category:     nonNil(c.categories),
+			Semester:     c.semester,
+			Credit:       c.credit,
+			Cover:        c.cover,
+			Views:        c.views,
+			Loves:        c.loves,
+			Collections:  c.collections,
+			Version:      c.version,
+		}
+	}), nil
+}
+
+// approvedCourseResources 课程下已通过审核的资源，按 sort_order 和ID排序
+func approvedCourseResources(table map[int]*memCourseResource, courseID int) []*memCourseResource {
+	var result []*memCourseResource
+	for _, res := range table {
+		if res.courseID == courseID && res.status == model.StatusApproved {
+			result = append(result, res)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].sortOrder != result[j].sortOrder {
+			return result[i].sortOrder < result[j].sortOrder
+		}
+		return result[i].id < result[j].id
+	})
+	return result
+}
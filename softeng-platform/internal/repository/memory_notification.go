@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"sort"
+	"time"
+)
+
+type memorySavedSearchRepository struct {
+	store *MemoryStore
+}
+
+func NewMemorySavedSearchRepository(store *MemoryStore) SavedSearchRepository {
+	return &memorySavedSearchRepository{store: store}
+}
+
+func (r *memorySavedSearchRepository) Create(ctx context.Context, search *model.SavedSearch) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	search.ID = s.nextID("saved_searches")
+	search.CreatedAt = formatTime(now)
+	search.LastNotifiedAt = nil
+	s.savedSearches[search.ID] = &memSavedSearch{SavedSearch: *search, createdAt: now}
+	return nil
+}
+
+func (r *memorySavedSearchRepository) List(ctx context.Context, userID int) ([]model.SavedSearch, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var searches []model.SavedSearch
+	for _, search := range s.savedSearches {
+		if userID == 0 || search.UserID == userID {
+			searches = append(searches, search.SavedSearch)
+		}
+	}
+	sort.Slice(searches, func(i, j int) bool { return searches[i].ID < searches[j].ID })
+	return searches, nil
+}
+
+func (r *memorySavedSearchRepository) Delete(ctx context.Context, userID, id int) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.savedSearches[id]
+	if !ok || search.UserID != userID {
+		return false, nil
+	}
+	delete(s.savedSearches, id)
+	return true, nil
+}
+
+func (r *memorySavedSearchRepository) MarkChecked(ctx context.Context, id int, checkedAt time.Time, notifiedAt *time.Time) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if search, ok := s.savedSearches[id]; ok {
+		search.CheckedAt = checkedAt
+		if notifiedAt != nil {
+			at := formatTime(*notifiedAt)
+			search.LastNotifiedAt = &at
+		}
+	}
+	return nil
+}
+
+func (r *memorySavedSearchRepository) Approved(ctx context.Context, since, until time.Time) ([]ApprovedResource, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var resources []ApprovedResource
+	add := func(resourceType string, id int, at *time.Time) {
+		if at != nil && at.After(since) && !at.After(until) {
+			resources = append(resources, ApprovedResource{ResourceType: resourceType, ResourceID: id, ApprovedAt: *at})
+		}
+	}
+	for _, t := range s.tools {
+		if t.deletedAt == nil && t.status == model.StatusApproved {
+			add(model.ResourceTypeTool, t.id, t.auditTime)
+		}
+	}
+	for _, c := range s.courses {
+		if c.deletedAt == nil {
+			createdAt := c.createdAt
+			add(model.ResourceTypeCourse, c.id, &createdAt)
+		}
+	}
+	for _, p := range s.projects {
+		if p.deletedAt == nil && p.status == model.StatusApproved {
+			add(model.ResourceTypeProject, p.id, p.auditTime)
+		}
+	}
+
+	sort.Slice(resources, func(i, j int) bool {
+		a, b := resources[i], resources[j]
+		if !a.ApprovedAt.Equal(b.ApprovedAt) {
+			return a.ApprovedAt.Before(b.ApprovedAt)
+		}
+		if a.ResourceType != b.ResourceType {
+			return a.ResourceType < b.ResourceType
+		}
+		return a.ResourceID < b.ResourceID
+	})
+	return resources, nil
+}
+
+type memoryNotificationRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryNotificationRepository(store *MemoryStore) NotificationRepository {
+	return &memoryNotificationRepository{store: store}
+}
+
+func (r *memoryNotificationRepository) Create(ctx context.Context, n *model.Notification) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	n.ID = s.nextID("notifications")
+	n.CreatedTime = now
+	n.CreatedAt = formatTime(now)
+	s.notifications[n.ID] = &memNotification{Notification: *n}
+	return nil
+}
+
+func (r *memoryNotificationRepository) List(ctx context.Context, userID int, unreadOnly bool, page pagination.Page) (*pagination.Result[model.Notification], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var items []model.Notification
+	for _, n := range s.notifications {
+		if n.UserID == userID && !(unreadOnly && n.IsRead) {
+			items = append(items, n.Notification)
+		}
+	}
+	return pageItems(items, notificationSort, func(n model.Notification) pagination.Key {
+		return notificationSort.key(sortValues{createdAt: n.CreatedTime}, n.ID)
+	}, page)
+}
+
+func (r *memoryNotificationRepository) MarkRead(ctx context.Context, userID, id int) (int, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	marked := 0
+	for _, n := range s.notifications {
+		if n.UserID == userID && !n.IsRead && (id == 0 || n.ID == id) {
+			n.IsRead = true
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+func (r *memoryNotificationRepository) Count(ctx context.Context, userID int, kind string, since time.Time) (int, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	count := 0
+	for _, n := range s.notifications {
+		if n.UserID == userID && n.Kind == kind && !n.CreatedTime.Before(since) {
+			count++
+		}
+	}
+	return count, nil
+}
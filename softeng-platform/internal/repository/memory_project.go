@@ -0,0 +1,287 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/tagquery"
+	"time"
+)
+
+type memoryProjectRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryProjectRepository(store *MemoryStore) ProjectRepository {
+	return &memoryProjectRepository{store: store}
+}
+
+func (r *memoryProjectRepository) GetProjects(ctx context.Context, category string, techStack tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Project], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	projects := s.sortedProjects(func(p *memProject) bool {
+		return p.status == model.StatusApproved &&
+			(category == "" || p.category == category) &&
+			tagquery.Match(techStack, p.techStack)
+	})
+	return s.pageProjects(projects, lookupSort(projectSorts, sort), nil, page)
+}
+
+func (r *memoryProjectRepository) GetByID(ctx context.Context, projectID int) (*model.ProjectDetail, error) {
+	return r.get(projectID, true)
+}
+
+func (r *memoryProjectRepository) GetCurrent(ctx context.Context, projectID int) (*model.ProjectDetail, error) {
+	return r.get(projectID, false)
+}
+
+func (r *memoryProjectRepository) get(projectID int, approvedOnly bool) (*model.ProjectDetail, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[projectID]
+	if !ok || p.deletedAt != nil || (approvedOnly && p.status != model.StatusApproved) {
+		return nil, nil
+	}
+
+	detail := s.projectDetail(p)
+	detail.Comments, detail.CommentCount = s.listComments(model.ResourceTypeProject, projectID)
+	return &detail, nil
+}
+
+func (r *memoryProjectRepository) Search(ctx context.Context, keyword string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Project], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	relevance := make(map[int]int64)
+	projects := s.sortedProjects(func(p *memProject) bool {
+		if p.status != model.StatusApproved || (len(category) > 0 && !containsString(category, p.category)) {
+			return false
+		}
+		score, ok := p.search(keyword)
+		relevance[p.id] = score
+		return ok
+	})
+	return s.pageProjects(projects, searchSort(projectSearchSorts, sort, keyword), relevance, page)
+}
+
+func (r *memoryProjectRepository) Create(ctx context.Context, userID int, req model.ProjectUploadRequest) (*model.ResourceReview, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.projectNameTaken(req.Name, 0) {
+		return nil, fmt.Errorf("failed to create project: %w", errDuplicateEntry)
+	}
+	if _, ok := s.users[userID]; !ok {
+		return nil, fmt.Errorf("failed to create project: user %d does not exist", userID)
+	}
+
+	now := time.Now()
+	p := &memProject{
+		memReview:   memReview{status: model.StatusPending, submitterID: userID},
+		memVersion:  memVersion{version: 1},
+		id:          s.nextID("projects"),
+		name:        req.Name,
+		description: req.Description,
+		detail:      req.Detail,
+		github:      req.Github,
+		category:    req.Category,
+		cover:       firstOrEmpty(req.Images),
+		createdAt:   now,
+		updatedAt:   now,
+		techStack:   uniqueStrings(req.TechStack),
+		images:      append([]string(nil), req.Images...),
+		authors:     []int{userID},
+	}
+	s.projects[p.id] = p
+
+	return &model.ResourceReview{
+		ResourceID:   p.id,
+		ResourceType: model.ResourceTypeProject,
+		Resource:     req.Github,
+		AuditStatus:  model.StatusPending,
+		SubmitTime:   formatTime(now),
+	}, nil
+}
+
+func (r *memoryProjectRepository) Update(ctx context.Context, userID, projectID, version int, req model.ProjectUploadRequest) (*model.ResourceReview, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	p, ok := s.projects[projectID]
+	if !ok || p.deletedAt != nil || !containsInt(p.authors, userID) {
+		return nil, fmt.Errorf("project not found")
+	}
+	if !p.match(version) {
+		return nil, ErrVersionConflict
+	}
+	if s.projectNameTaken(req.Name, projectID) {
+		return nil, fmt.Errorf("failed to update project: %w", errDuplicateEntry)
+	}
+
+	// 修改后的项目需要重新审核
+	now := time.Now()
+	p.name = req.Name
+	p.description = req.Description
+	p.detail = req.Detail
+	p.github = req.Github
+	p.category = req.Category
+	p.cover = firstOrEmpty(req.Images)
+	p.status = model.StatusPending
+	p.auditTime = nil
+	p.rejectReason = nil
+	p.updatedAt = now
+	p.techStack = uniqueStrings(req.TechStack)
+	p.images = append([]string(nil), req.Images...)
+	p.version++
+
+	return &model.ResourceReview{
+		ResourceID:   projectID,
+		ResourceType: model.ResourceTypeProject,
+		Resource:     req.Github,
+		AuditStatus:  model.StatusPending,
+		SubmitTime:   formatTime(now),
+		Version:      p.version,
+	}, nil
+}
+
+func (r *memoryProjectRepository) LikeProject(ctx context.Context, userID, projectID int) (*model.LikeStatus, error) {
+	return r.store.setLike(userID, model.ResourceTypeProject, projectID, true)
+}
+
+func (r *memoryProjectRepository) UnlikeProject(ctx context.Context, userID, projectID int) (*model.LikeStatus, error) {
+	return r.store.setLike(userID, model.ResourceTypeProject, projectID, false)
+}
+
+func (r *memoryProjectRepository) AddComment(ctx context.Context, userID, projectID int, content string) (*model.Comment, error) {
+	return r.store.addComment(userID, model.ResourceTypeProject, projectID, nil, content)
+}
+
+func (r *memoryProjectRepository) DeleteComment(ctx context.Context, userID, projectID, commentID int) (*model.Comment, error) {
+	return r.store.deleteComment(userID, model.ResourceTypeProject, projectID, commentID, false)
+}
+
+func (r *memoryProjectRepository) ReplyComment(ctx context.Context, userID, projectID, commentID int, content string) (*model.Comment, error) {
+	return r.store.addComment(userID, model.ResourceTypeProject, projectID, &commentID, content)
+}
+
+func (r *memoryProjectRepository) DeleteReply(ctx context.Context, userID, projectID, commentID int) (*model.Comment, error) {
+	return r.store.deleteComment(userID, model.ResourceTypeProject, projectID, commentID, true)
+}
+
+func (r *memoryProjectRepository) AddView(ctx context.Context, projectID int) (int, error) {
+	return r.store.addView(model.ResourceTypeProject, projectID)
+}
+
+func (r *memoryProjectRepository) CollectProject(ctx context.Context, userID, projectID int) (*model.CollectStatus, error) {
+	return r.store.setCollect(userID, model.ResourceTypeProject, projectID, true)
+}
+
+func (r *memoryProjectRepository) UncollectProject(ctx context.Context, userID, projectID int) (*model.CollectStatus, error) {
+	return r.store.setCollect(userID, model.ResourceTypeProject, projectID, false)
+}
+
+func (r *memoryProjectRepository) GetComments(ctx context.Context, projectID int, page pagination.Page) (*pagination.Result[model.Comment], error) {
+	return r.store.pageComments(model.ResourceTypeProject, projectID, page)
+}
+
+func (r *memoryProjectRepository) GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	projects := s.sortedProjects(func(p *memProject) bool {
+		return model.InReviewQueue(p.status) && s.claimMatches(filter, model.ReviewTarget{ResourceType: model.ResourceTypeProject, ResourceID: p.id}, now)
+	})
+	result, err := pageItems(projects, pendingProjectSort, projectKey(pendingProjectSort), page)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.Map(result, func(p *memProject) model.Submit {
+		return model.Submit{
+			Submitor:     s.submitterName(p.submitterID),
+			SubmitDate:   formatTime(p.createdAt),
+			ResourceID:   p.id,
+			ResourceType: model.ResourceTypeProject,
+			Category:     p.category,
+			Link:         p.github,
+			Description:  p.description,
+			Tags:         nonNil(p.techStack),
+			ResourceName: p.name,
+		}
+	}), nil
+}
+
+// projectNameTaken 模拟 projects.name 上的唯一约束，exceptID 为正在修改的项目
+func (s *MemoryStore) projectNameTaken(name string, exceptID int) bool {
+	for _, p := range s.projects {
+		if p.id != exceptID && p.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedProjects 返回满足条件且不在回收站中的项目，按创建时间和ID倒序（即 projectSorts["latest"]）
+func (s *MemoryStore) sortedProjects(match func(p *memProject) bool) []*memProject {
+	var result []*memProject
+	for _, p := range s.projects {
+		if p.deletedAt == nil && match(p) {
+			result = append(result, p)
+		}
+	}
+	sortByKey(result, projectSorts["latest"], projectKey(projectSorts["latest"]))
+	return result
+}
+
+func projectKey(by keyset) func(p *memProject) pagination.Key {
+	return func(p *memProject) pagination.Key {
+		return by.key(p.sortValues(p.createdAt), p.id)
+	}
+}
+
+// pageProjects 截取一页项目并转换为列表项，relevance 为检索结果的相关度
+func (s *MemoryStore) pageProjects(projects []*memProject, by keyset, relevance map[int]int64, page pagination.Page) (*pagination.Result[model.Project], error) {
+	result, err := pageItems(projects, by, func(p *memProject) pagination.Key {
+		values := p.sortValues(p.createdAt)
+		values.relevance = relevance[p.id]
+		return by.key(values, p.id)
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.Map(result, func(p *memProject) model.Project {
+		return projectRow{ProjectDetail: s.projectDetail(p)}.summary()
+	}), nil
+}
+
+func (s *MemoryStore) projectDetail(p *memProject) model.ProjectDetail {
+	return model.ProjectDetail{
+		ProjectID:    p.id,
+		ResourceType: model.ResourceTypeProject,
+		Name:         p.name,
+		Description:  p.description,
+		Detail:       p.detail,
+		GithubURL:    p.github,
+		TechStack:    nonNil(p.techStack),
+		Category:     p.category,
+		Cover:        p.cover,
+		Images:       nonNil(p.images),
+		Likes:        p.loves,
+		Views:        p.views,
+		Collections:  p.collections,
+		Author:       s.displayNames(p.authors),
+		Comments:     []model.Comment{},
+		CreatedAt:    formatTime(p.createdAt),
+		Version:      p.version,
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"softeng-platform/internal/model"
+	"sort"
+)
+
+type memoryReconcileRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryReconcileRepository(store *MemoryStore) ReconcileRepository {
+	return &memoryReconcileRepository{store: store}
+}
+
+func (r *memoryReconcileRepository) Reconcile(ctx context.Context, fix bool) (*model.ReconcileReport, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &model.ReconcileReport{Drifts: []model.CounterDrift{}, Orphans: []model.OrphanRows{}, Fixed: fix}
+
+	// 与 counterSources 的顺序一致：资源类型、计数列、ID
+	loves := relationCounts(s.likes)
+	collections := relationCounts(s.collections)
+	for _, resourceType := range resourceTypes {
+		t := resourceTables[resourceType]
+		ids := s.resourceIDs(resourceType)
+		for _, id := range ids {
+			counters := s.rawCounters(resourceType, id)
+			actual := loves[relationKey{resourceType: resourceType, resourceID: id}]
+			report.Drifts = appendDrift(report.Drifts, t.table, id, "loves", &counters.loves, actual, fix)
+		}
+		for _, id := range ids {
+			counters := s.rawCounters(resourceType, id)
+			actual := collections[relationKey{resourceType: resourceType, resourceID: id}]
+			report.Drifts = appendDrift(report.Drifts, t.table, id, "collections", &counters.collections, actual, fix)
+		}
+	}
+
+	replies := make(map[int]int)
+	for _, c := range s.comments {
+		if c.parentID != 0 && c.deletedAt == nil {
+			replies[c.parentID]++
+		}
+	}
+	for _, id := range sortedKeys(s.comments) {
+		c := s.comments[id]
+		report.Drifts = appendDrift(report.Drifts, "comments", id, "reply_total", &c.replyTotal, replies[id], fix)
+	}
+
+	report.Orphans = append(report.Orphans, s.relationOrphans("likes", s.likes, fix)...)
+	report.Orphans = append(report.Orphans, s.relationOrphans("collections", s.collections, fix)...)
+	report.Orphans = append(report.Orphans, s.commentOrphans(fix)...)
+	report.Orphans = append(report.Orphans, s.dailyViewOrphans(fix)...)
+	return report, nil
+}
+
+func appendDrift(drifts []model.CounterDrift, table string, id int, column string, stored *int, actual int, fix bool) []model.CounterDrift {
+	if *stored == actual {
+		return drifts
+	}
+	drifts = append(drifts, model.CounterDrift{Table: table, ID: id, Column: column, Stored: *stored, Actual: actual})
+	if fix {
+		*stored = actual
+	}
+	return drifts
+}
+
+// relationCounts 按资源统计点赞或收藏的行数，键中的 userID 为 0
+func relationCounts(relations map[relationKey]*memRelation) map[relationKey]int {
+	counts := make(map[relationKey]int)
+	for key := range relations {
+		counts[relationKey{resourceType: key.resourceType, resourceID: key.resourceID}]++
+	}
+	return counts
+}
+
+// resourceIDs 返回某类资源全部的ID，包括回收站中的资源
+func (s *MemoryStore) resourceIDs(resourceType string) []int {
+	switch resourceType {
+	case model.ResourceTypeTool:
+		return sortedKeys(s.tools)
+	case model.ResourceTypeCourse:
+		return sortedKeys(s.courses)
+	case model.ResourceTypeProject:
+		return sortedKeys(s.projects)
+	}
+	return nil
+}
+
+// rawCounters 返回资源的计数列，与 counters 不同，回收站中的资源也会返回
+func (s *MemoryStore) rawCounters(resourceType string, resourceID int) *memCounters {
+	switch resourceType {
+	case model.ResourceTypeTool:
+		if t, ok := s.tools[resourceID]; ok {
+			return &t.memCounters
+		}
+	case model.ResourceTypeCourse:
+		if c, ok := s.courses[resourceID]; ok {
+			return &c.memCounters
+		}
+	case model.ResourceTypeProject:
+		if p, ok := s.projects[resourceID]; ok {
+			return &p.memCounters
+		}
+	}
+	return nil
+}
+
+func sortedKeys[V any](rows map[int]V) []int {
+	ids := make([]int, 0, len(rows))
+	for id := range rows {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// orphanCounter 按资源汇总孤儿行，结果按资源类型、资源ID排序
+type orphanCounter map[relationKey]int
+
+func (o orphanCounter) rows(table string) []model.OrphanRows {
+	var orphans []model.OrphanRows
+	for key, n := range o {
+		orphans = append(orphans, model.OrphanRows{Table: table, ResourceType: key.resourceType, ResourceID: key.resourceID, Rows: n})
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].ResourceType != orphans[j].ResourceType {
+			return orphans[i].ResourceType < orphans[j].ResourceType
+		}
+		return orphans[i].ResourceID < orphans[j].ResourceID
+	})
+	return orphans
+}
+
+func (s *MemoryStore) relationOrphans(table string, relations map[relationKey]*memRelation, fix bool) []model.OrphanRows {
+	orphans := orphanCounter{}
+	for key := range relations {
+		if s.rawCounters(key.resourceType, key.resourceID) == nil {
+			orphans[relationKey{resourceType: key.resourceType, resourceID: key.resourceID}]++
+			if fix {
+				delete(relations, key)
+			}
+		}
+	}
+	return orphans.rows(table)
+}
+
+func (s *MemoryStore) commentOrphans(fix bool) []model.OrphanRows {
+	orphans := orphanCounter{}
+	var ids []int
+	for id, c := range s.comments {
+		if s.rawCounters(c.resourceType, c.resourceID) == nil {
+			orphans[relationKey{resourceType: c.resourceType, resourceID: c.resourceID}]++
+			ids = append(ids, id)
+		}
+	}
+	if fix {
+		for _, id := range ids {
+			delete(s.comments, id)
+		}
+	}
+	return orphans.rows("comments")
+}
+
+func (s *MemoryStore) dailyViewOrphans(fix bool) []model.OrphanRows {
+	orphans := orphanCounter{}
+	for key := range s.dailyViews {
+		if s.rawCounters(key.resourceType, key.resourceID) == nil {
+			orphans[relationKey{resourceType: key.resourceType, resourceID: key.resourceID}]++
+			if fix {
+				delete(s.dailyViews, key)
+			}
+		}
+	}
+	return orphans.rows("resource_daily_views")
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"softeng-platform/internal/model"
+	"sort"
+	"time"
+)
+
+type memoryReviewRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryReviewRepository(store *MemoryStore) ReviewRepository {
+	return &memoryReviewRepository{store: store}
+}
+
+// memReviewRow 审核目标的公共部分，memReview 指向资源行本身；课程资源没有版本号，version 为 nil
+type memReviewRow struct {
+	*memReview
+	version   *memVersion
+	updatedAt *time.Time
+	name      string
+}
+
+// reviewRow 对应 SQL 实现中 reviewTable.live 条件下的资源行，找不到时返回 false
+func (s *MemoryStore) reviewRow(resourceType string, resourceID int) (memReviewRow, bool) {
+	switch resourceType {
+	case model.ResourceTypeTool:
+		if t, ok := s.tools[resourceID]; ok && t.deletedAt == nil {
+			return memReviewRow{&t.memReview, &t.memVersion, &t.updatedAt, t.name}, true
+		}
+	case model.ResourceTypeProject:
+		if p, ok := s.projects[resourceID]; ok && p.deletedAt == nil {
+			return memReviewRow{&p.memReview, &p.memVersion, &p.updatedAt, p.name}, true
+		}
+	case model.ResourceTypeCourseWeb, model.ResourceTypeCourseUpload:
+		rows := s.courseWeb
+		if resourceType == model.ResourceTypeCourseUpload {
+			rows = s.courseUpload
+		}
+		if res, ok := rows[resourceID]; ok {
+			if _, live := s.liveCourse(res.courseID); live {
+				return memReviewRow{memReview: &res.memReview, name: res.intro}, true
+			}
+		}
+	}
+	return memReviewRow{}, false
+}
+
+func (row memReviewRow) state(resourceType string, resourceID int) *model.ReviewState {
+	state := &model.ReviewState{
+		ResourceID:   resourceID,
+		ResourceType: resourceType,
+		ResourceName: row.name,
+		AuditStatus:  row.status,
+	}
+	if row.rejectReason != nil {
+		state.RejectReason = *row.rejectReason
+	}
+	if row.version != nil {
+		state.Version = row.version.version
+	}
+	return state
+}
+
+func (r *memoryReviewRepository) Get(ctx context.Context, ownerID int, resourceType string, resourceID int) (*model.ReviewState, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := lookupReviewTable(resourceType); err != nil {
+		return nil, err
+	}
+	row, ok := s.reviewRow(resourceType, resourceID)
+	if !ok || (ownerID != 0 && row.submitterID != ownerID) {
+		return nil, nil
+	}
+	return row.state(resourceType, resourceID), nil
+}
+
+func (r *memoryReviewRepository) Transition(ctx context.Context, change StatusChange) (*model.Maneuver, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := lookupReviewTable(change.ResourceType); err != nil {
+		return nil, err
+	}
+	row, ok := s.reviewRow(change.ResourceType, change.ResourceID)
+	if !ok || (change.OwnerID != 0 && row.submitterID != change.OwnerID) {
+		return nil, nil
+	}
+	if !slices.Contains(change.From, row.status) {
+		return nil, ErrStatusConflict
+	}
+	if row.version != nil && !row.version.match(change.Version) {
+		return nil, ErrVersionConflict
+	}
+
+	now := time.Now()
+	oldStatus := row.status
+	row.status = change.To
+	switch change.To {
+	case model.StatusApproved:
+		row.auditTime = &now
+		row.rejectReason = nil
+	case model.StatusResubmitted:
+		row.auditTime = nil
+	default:
+		row.auditTime = &now
+		row.rejectReason = nil
+		if change.RejectReason != "" {
+			reason := change.RejectReason
+			row.rejectReason = &reason
+		}
+	}
+	if row.version != nil {
+		row.version.version++
+		*row.updatedAt = now
+	}
+
+	id := s.nextID("resource_status_logs")
+	s.statusLogs[id] = &memStatusLog{
+		id:           id,
+		resourceType: change.ResourceType,
+		resourceID:   change.ResourceID,
+		oldStatus:    oldStatus,
+		newStatus:    change.To,
+		operatorID:   change.OperatorID,
+		operateTime:  now,
+	}
+
+	operator, _ := s.displayName(change.OperatorID)
+	maneuver := &model.Maneuver{
+		ResourceID:   change.ResourceID,
+		ResourceType: change.ResourceType,
+		NewStatus:    change.To,
+		OldStatus:    oldStatus,
+		OperateTime:  formatTime(now),
+		Operator:     operator,
+	}
+	if row.version != nil {
+		maneuver.Version = row.version.version
+	}
+	return maneuver, nil
+}
+
+func (r *memoryReviewRepository) History(ctx context.Context, resourceType string, resourceID int) ([]model.StatusLog, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []*memStatusLog
+	for _, log := range s.statusLogs {
+		if log.resourceType == resourceType && log.resourceID == resourceID {
+			rows = append(rows, log)
+		}
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].id < rows[j].id })
+
+	logs := []model.StatusLog{}
+	for _, row := range rows {
+		operator, _ := s.displayName(row.operatorID)
+		logs = append(logs, model.StatusLog{
+			OldStatus:   row.oldStatus,
+			NewStatus:   row.newStatus,
+			Operator:    operator,
+			OperateTime: formatTime(row.operateTime),
+		})
+	}
+	return logs, nil
+}
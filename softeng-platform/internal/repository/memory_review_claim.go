@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"context"
+	"softeng-platform/internal/model"
+	"sort"
+	"time"
+)
+
+type memoryReviewClaimRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryReviewClaimRepository(store *MemoryStore) ReviewClaimRepository {
+	return &memoryReviewClaimRepository{store: store}
+}
+
+// active 认领在 now 时是否有效
+func (c *memReviewClaim) active(now time.Time) bool {
+	return c.claimantID != 0 && c.expiresAt != nil && c.expiresAt.After(now)
+}
+
+// claimMatches 与 SQL 实现中 claimWhere 的条件相同
+func (s *MemoryStore) claimMatches(filter model.PendingFilter, target model.ReviewTarget, now time.Time) bool {
+	claim := s.reviewClaims[target]
+	switch filter.Claim {
+	case model.ClaimUnclaimed:
+		return claim == nil || !claim.active(now)
+	case model.ClaimMine:
+		return claim != nil && claim.active(now) && claim.claimantID == filter.ReviewerID
+	case model.ClaimAssigned:
+		return claim != nil && claim.assigneeID == filter.ReviewerID
+	}
+	return true
+}
+
+func (s *MemoryStore) reviewClaim(target model.ReviewTarget, now time.Time) *model.ReviewClaim {
+	c, ok := s.reviewClaims[target]
+	if !ok {
+		return nil
+	}
+	claim := &model.ReviewClaim{ReviewTarget: target, AssigneeID: c.assigneeID, Assignee: s.submitterName(c.assigneeID)}
+	if c.active(now) {
+		claim.ClaimantID = c.claimantID
+		claim.Claimant = s.submitterName(c.claimantID)
+		claim.ExpiresTime = *c.expiresAt
+		claim.ExpiresAt = formatTime(*c.expiresAt)
+	}
+	return claim
+}
+
+func (r *memoryReviewClaimRepository) Get(ctx context.Context, target model.ReviewTarget) (*model.ReviewClaim, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.reviewClaim(target, time.Now()), nil
+}
+
+// Lock 内存实现的事务不隔离，只与 SQL 实现一样建好空记录
+func (r *memoryReviewClaimRepository) Lock(ctx context.Context, target model.ReviewTarget) (*model.ReviewClaim, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.reviewClaims[target]; !ok {
+		s.reviewClaims[target] = &memReviewClaim{}
+	}
+	return s.reviewClaim(target, time.Now()), nil
+}
+
+func (r *memoryReviewClaimRepository) List(ctx context.Context, targets []model.ReviewTarget) (map[model.ReviewTarget]model.ReviewClaim, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	result := make(map[model.ReviewTarget]model.ReviewClaim, len(targets))
+	for _, target := range targets {
+		if claim := s.reviewClaim(target, now); claim != nil {
+			result[target] = *claim
+		}
+	}
+	return result, nil
+}
+
+func (r *memoryReviewClaimRepository) Claim(ctx context.Context, reviewerID int, target model.ReviewTarget, expiresAt time.Time) (*model.ReviewClaim, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	c, ok := s.reviewClaims[target]
+	// 到期时间早于现在的认领立即失效，与 SQL 实现一样不留下任何修改
+	if ok && c.active(now) && c.claimantID != reviewerID || !expiresAt.After(now) {
+		return s.reviewClaim(target, now), ErrClaimConflict
+	}
+	if !ok {
+		c = &memReviewClaim{}
+		s.reviewClaims[target] = c
+	}
+	c.claimantID = reviewerID
+	c.expiresAt = &expiresAt
+	return s.reviewClaim(target, now), nil
+}
+
+func (r *memoryReviewClaimRepository) Release(ctx context.Context, reviewerID int, target model.ReviewTarget) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c, ok := s.reviewClaims[target]
+	if !ok || c.claimantID == 0 || c.claimantID != reviewerID {
+		return false, nil
+	}
+	c.claimantID = 0
+	c.expiresAt = nil
+	return true, nil
+}
+
+func (r *memoryReviewClaimRepository) Distribute(ctx context.Context) (int, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var reviewers []int
+	for id, u := range s.users {
+		if u.Role == "admin" {
+			reviewers = append(reviewers, id)
+		}
+	}
+	if len(reviewers) == 0 {
+		return 0, nil
+	}
+	sort.Ints(reviewers)
+
+	last, seq := 0, 0
+	for _, c := range s.reviewClaims {
+		if c.assignSeq > seq {
+			last, seq = c.assigneeID, c.assignSeq
+		}
+	}
+
+	// 与 SQL 实现中 pendingReviewTargets 相同的待审核资源，按提交时间、类型和ID排序
+	type pending struct {
+		target    model.ReviewTarget
+		createdAt time.Time
+	}
+	var list []pending
+	add := func(resourceType string, id int, createdAt time.Time) {
+		target := model.ReviewTarget{ResourceType: resourceType, ResourceID: id}
+		if c, ok := s.reviewClaims[target]; !ok || c.assigneeID == 0 {
+			list = append(list, pending{target, createdAt})
+		}
+	}
+	for _, t := range s.tools {
+		if t.deletedAt == nil && model.InReviewQueue(t.status) {
+			add(model.ResourceTypeTool, t.id, t.createdAt)
+		}
+	}
+	for _, p := range s.projects {
+		if p.deletedAt == nil && model.InReviewQueue(p.status) {
+			add(model.ResourceTypeProject, p.id, p.createdAt)
+		}
+	}
+	for resourceType, rows := range map[string]map[int]*memCourseResource{
+		model.ResourceTypeCourseWeb: s.courseWeb, model.ResourceTypeCourseUpload: s.courseUpload,
+	} {
+		for _, res := range rows {
+			if _, ok := s.liveCourse(res.courseID); ok && model.InReviewQueue(res.status) {
+				add(resourceType, res.id, res.createdAt)
+			}
+		}
+	}
+	sort.Slice(list, func(i, j int) bool {
+		a, b := list[i], list[j]
+		if !a.createdAt.Equal(b.createdAt) {
+			return a.createdAt.Before(b.createdAt)
+		}
+		if a.target.ResourceType != b.target.ResourceType {
+			return a.target.ResourceType < b.target.ResourceType
+		}
+		return a.target.ResourceID < b.target.ResourceID
+	})
+
+	now := time.Now()
+	assignee := last
+	for _, p := range list {
+		assignee = nextReviewer(reviewers, assignee)
+		seq++
+		c, ok := s.reviewClaims[p.target]
+		if !ok {
+			c = &memReviewClaim{}
+			s.reviewClaims[p.target] = c
+		}
+		c.assigneeID = assignee
+		c.assignSeq = seq
+		c.assignedAt = &now
+	}
+	return len(list), nil
+}
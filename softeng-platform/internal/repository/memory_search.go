@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"sort"
+	"time"
+)
+
+type memorySearchRepository struct {
+	store *MemoryStore
+}
+
+func NewMemorySearchRepository(store *MemoryStore) SearchRepository {
+	return &memorySearchRepository{store: store}
+}
+
+// search 按名称、简介、详情和标签检索工具，返回相关度
+func (t *memTool) search(keyword string) (int64, bool) {
+	return memorySearch(keyword, t.name, []string{t.description, t.detail}, t.tags)
+}
+
+// search 按名称和教师检索课程，返回相关度
+func (c *memCourse) search(keyword string) (int64, bool) {
+	return memorySearch(keyword, c.name, nil, c.teachers)
+}
+
+// search 按名称、简介、详情和技术栈检索项目，返回相关度
+func (p *memProject) search(keyword string) (int64, bool) {
+	return memorySearch(keyword, p.name, []string{p.description, p.detail}, p.techStack)
+}
+
+// memSearchRow 统一检索命中的一行，facets 为该行在各分面上的取值
+type memSearchRow struct {
+	hit    model.SearchHit
+	detail string
+	facets map[string][]string
+}
+
+// searchRows 返回 q 命中的全部资源，筛选规则与 searchSources 一致
+func (s *MemoryStore) searchRows(q model.SearchQuery) []memSearchRow {
+	wanted := func(resourceType string) bool {
+		return len(q.Types) == 0 || containsString(q.Types, resourceType)
+	}
+	var rows []memSearchRow
+
+	if wanted(model.ResourceTypeTool) && len(q.TechStack) == 0 && len(q.Semester) == 0 {
+		for _, t := range s.tools {
+			if t.deletedAt != nil || t.status != model.StatusApproved ||
+				(len(q.Category) > 0 && !containsString(q.Category, t.category)) ||
+				(len(q.Tags) > 0 && !containsAny(t.tags, q.Tags)) {
+				continue
+			}
+			relevance, ok := t.search(q.Keyword)
+			if !ok {
+				continue
+			}
+			rows = append(rows, memSearchRow{
+				hit: searchHit(model.ResourceTypeTool, t.id, t.name, t.description, nonEmpty(t.category), t.tags, "",
+					t.memCounters, t.createdAt, relevance),
+				detail: t.detail,
+				facets: map[string][]string{"category": nonEmpty(t.category), "tag": t.tags},
+			})
+		}
+	}
+
+	if wanted(model.ResourceTypeCourse) && len(q.Tags) == 0 && len(q.TechStack) == 0 {
+		for _, c := range s.courses {
+			if c.deletedAt != nil ||
+				(len(q.Category) > 0 && !containsAny(c.categories, q.Category)) ||
+				(len(q.Semester) > 0 && !containsString(q.Semester, c.semester)) {
+				continue
+			}
+			relevance, ok := c.search(q.Keyword)
+			if !ok {
+				continue
+			}
+			rows = append(rows, memSearchRow{
+				hit: searchHit(model.ResourceTypeCourse, c.id, c.name, "", c.categories, c.teachers, c.semester,
+					c.memCounters, c.createdAt, relevance),
+				facets: map[string][]string{"category": c.categories, "semester": nonEmpty(c.semester)},
+			})
+		}
+	}
+
+	if wanted(model.ResourceTypeProject) && len(q.Tags) == 0 && len(q.Semester) == 0 {
+		for _, p := range s.projects {
+			if p.deletedAt != nil || p.status != model.StatusApproved ||
+				(len(q.Category) > 0 && !containsString(q.Category, p.category)) ||
+				(len(q.TechStack) > 0 && !containsAny(p.techStack, q.TechStack)) {
+				continue
+			}
+			relevance, ok := p.search(q.Keyword)
+			if !ok {
+				continue
+			}
+			rows = append(rows, memSearchRow{
+				hit: searchHit(model.ResourceTypeProject, p.id, p.name, p.description, nonEmpty(p.category), p.techStack, "",
+					p.memCounters, p.createdAt, relevance),
+				detail: p.detail,
+				facets: map[string][]string{"category": nonEmpty(p.category), "techStack": p.techStack},
+			})
+		}
+	}
+
+	for i := range rows {
+		rows[i].facets["type"] = []string{rows[i].hit.ResourceType}
+	}
+	return rows
+}
+
+func searchHit(resourceType string, id int, name, description string, category, tags []string, semester string,
+	counters memCounters, createdAt time.Time, relevance int64) model.SearchHit {
+	return model.SearchHit{
+		ResourceType: resourceType,
+		ResourceID:   id,
+		Name:         name,
+		Description:  description,
+		Category:     nonNil(append([]string{}, category...)),
+		Tags:         nonNil(append([]string{}, tags...)),
+		Semester:     semester,
+		Views:        counters.views,
+		Loves:        counters.loves,
+		Collections:  counters.collections,
+		CreatedDate:  formatTime(createdAt),
+		CreatedAt:    createdAt,
+		Relevance:    relevance,
+	}
+}
+
+// nonEmpty 把可能为空的单值列转换为取值列表
+func nonEmpty(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return []string{value}
+}
+
+func (r *memorySearchRepository) Search(ctx context.Context, q model.SearchQuery, page pagination.Page) (*pagination.Result[model.SearchHit], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rows := s.searchRows(q)
+	hits := make([]model.SearchHit, len(rows))
+	for i, row := range rows {
+		hits[i] = row.hit
+	}
+	by := searchSort(unifiedSearchSorts, q.Sort, q.Keyword)
+	return pageItems(hits, by, func(hit model.SearchHit) pagination.Key {
+		return searchHitKey(by, hit)
+	}, page)
+}
+
+func (r *memorySearchRepository) Facets(ctx context.Context, q model.SearchQuery) (*model.SearchFacets, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	facets := &model.SearchFacets{}
+	for _, facet := range searchFacets {
+		counts := make(map[string]int)
+		for _, row := range s.searchRows(facet.without(q)) {
+			for _, value := range uniqueStrings(row.facets[facet.name]) {
+				counts[value]++
+			}
+		}
+
+		result := []model.FacetCount{}
+		for value, count := range counts {
+			result = append(result, model.FacetCount{Value: value, Count: count})
+		}
+		sort.Slice(result, func(i, j int) bool {
+			if result[i].Count != result[j].Count {
+				return result[i].Count > result[j].Count
+			}
+			return result[i].Value < result[j].Value
+		})
+		if len(result) > maxFacetValues {
+			result = result[:maxFacetValues]
+		}
+		facet.set(facets, result)
+	}
+	return facets, nil
+}
+
+func (r *memorySearchRepository) Document(ctx context.Context, resourceType string, resourceID int) (*model.SearchDocument, error) {
+	if _, err := lookupResourceTable(resourceType); err != nil {
+		return nil, err
+	}
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, row := range s.searchRows(model.SearchQuery{Types: []string{resourceType}}) {
+		if row.hit.ResourceID == resourceID {
+			return &model.SearchDocument{SearchHit: row.hit, Detail: row.detail}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memorySearchRepository) Documents(ctx context.Context, fn func(doc *model.SearchDocument) error) error {
+	s := r.store
+	s.mu.Lock()
+	rows := s.searchRows(model.SearchQuery{})
+	s.mu.Unlock()
+
+	for _, row := range rows {
+		if err := fn(&model.SearchDocument{SearchHit: row.hit, Detail: row.detail}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
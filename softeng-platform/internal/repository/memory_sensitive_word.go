@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"sort"
+	"time"
+)
+
+type memorySensitiveWordRepository struct {
+	store *MemoryStore
+}
+
+func NewMemorySensitiveWordRepository(store *MemoryStore) SensitiveWordRepository {
+	return &memorySensitiveWordRepository{store: store}
+}
+
+func (r *memorySensitiveWordRepository) List(ctx context.Context) ([]model.SensitiveWord, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	words := make([]model.SensitiveWord, 0, len(s.sensitiveWords))
+	for _, w := range s.sensitiveWords {
+		words = append(words, *w)
+	}
+	sort.Slice(words, func(i, j int) bool { return words[i].ID < words[j].ID })
+	return words, nil
+}
+
+func (r *memorySensitiveWordRepository) Save(ctx context.Context, word *model.SensitiveWord) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, w := range s.sensitiveWords {
+		if w.Word == word.Word {
+			w.Level = word.Level
+			*word = *w
+			return nil
+		}
+	}
+	if _, ok := s.users[word.CreatedBy]; word.CreatedBy != 0 && !ok {
+		return fmt.Errorf("failed to save sensitive word: user %d does not exist", word.CreatedBy)
+	}
+
+	saved := *word
+	saved.ID = s.nextID("sensitive_words")
+	saved.CreatedAt = formatTime(time.Now())
+	s.sensitiveWords[saved.ID] = &saved
+	*word = saved
+	return nil
+}
+
+func (r *memorySensitiveWordRepository) Delete(ctx context.Context, id int) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.sensitiveWords[id]; !ok {
+		return false, nil
+	}
+	delete(s.sensitiveWords, id)
+	return true, nil
+}
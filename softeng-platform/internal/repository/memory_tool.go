@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/tagquery"
+	"time"
+)
+
+type memoryToolRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryToolRepository(store *MemoryStore) ToolRepository {
+	return &memoryToolRepository{store: store}
+}
+
+func (r *memoryToolRepository) GetTools(ctx context.Context, category []string, tags tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Tool], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tools := s.sortedTools(func(t *memTool) bool {
+		return t.status == model.StatusApproved &&
+			(len(category) == 0 || containsString(category, t.category)) &&
+			tagquery.Match(tags, t.tags)
+	})
+	return s.pageTools(tools, lookupSort(toolSorts, sort), nil, page)
+}
+
+func (r *memoryToolRepository) GetByID(ctx context.Context, resourceID int) (*model.Tool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tools[resourceID]
+	if !ok || t.status != model.StatusApproved || t.deletedAt != nil {
+		return nil, nil
+	}
+
+	tool := s.toolModel(t)
+	tool.Comments, tool.CommentCount = s.listComments(model.ResourceTypeTool, resourceID)
+	return &tool, nil
+}
+
+func (r *memoryToolRepository) Search(ctx context.Context, keyword string, category []string, tags tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Tool], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	relevance := make(map[int]int64)
+	tools := s.sortedTools(func(t *memTool) bool {
+		if t.status != model.StatusApproved ||
+			(len(category) > 0 && !containsString(category, t.category)) ||
+			!tagquery.Match(tags, t.tags) {
+			return false
+		}
+		score, ok := t.search(keyword)
+		relevance[t.id] = score
+		return ok
+	})
+	return s.pageTools(tools, searchSort(toolSearchSorts, sort, keyword), relevance, page)
+}
+
+func (r *memoryToolRepository) Create(ctx context.Context, userID int, req model.ToolSubmitRequest) (*model.ResourceReview, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return nil, fmt.Errorf("failed to create tool: user %d does not exist", userID)
+	}
+
+	now := time.Now()
+	t := &memTool{
+		memReview:    memReview{status: model.StatusPending, submitterID: userID},
+		memVersion:   memVersion{version: 1},
+		id:           s.nextID("tools"),
+		name:         req.Name,
+		link:         req.Link,
+		description:  req.Description,
+		detail:       req.DescriptionDetail,
+		category:     req.Category,
+		createdAt:    now,
+		updatedAt:    now,
+		tags:         uniqueStrings(req.Tags),
+		contributors: []int{userID},
+	}
+	s.tools[t.id] = t
+
+	return &model.ResourceReview{
+		ResourceID:   t.id,
+		ResourceType: model.ResourceTypeTool,
+		Resource:     req.Link,
+		AuditStatus:  model.StatusPending,
+		SubmitTime:   formatTime(now),
+	}, nil
+}
+
+func (r *memoryToolRepository) LikeTool(ctx context.Context, userID, resourceID int) (*model.LikeStatus, error) {
+	return r.store.setLike(userID, model.ResourceTypeTool, resourceID, true)
+}
+
+func (r *memoryToolRepository) UnlikeTool(ctx context.Context, userID, resourceID int) (*model.LikeStatus, error) {
+	return r.store.setLike(userID, model.ResourceTypeTool, resourceID, false)
+}
+
+func (r *memoryToolRepository) CollectTool(ctx context.Context, userID, resourceID int) (*model.CollectStatus, error) {
+	return r.store.setCollect(userID, model.ResourceTypeTool, resourceID, true)
+}
+
+func (r *memoryToolRepository) UncollectTool(ctx context.Context, userID, resourceID int) (*model.CollectStatus, error) {
+	return r.store.setCollect(userID, model.ResourceTypeTool, resourceID, false)
+}
+
+func (r *memoryToolRepository) AddComment(ctx context.Context, userID, resourceID int, content string) (*model.Comment, error) {
+	return r.store.addComment(userID, model.ResourceTypeTool, resourceID, nil, content)
+}
+
+func (r *memoryToolRepository) DeleteComment(ctx context.Context, userID, resourceID, commentID int) (*model.Comment, error) {
+	return r.store.deleteComment(userID, model.ResourceTypeTool, resourceID, commentID, false)
+}
+
+func (r *memoryToolRepository) ReplyComment(ctx context.Context, userID, resourceID, commentID int, content string) (*model.Comment, error) {
+	return r.store.addComment(userID, model.ResourceTypeTool, resourceID, &commentID, content)
+}
+
+func (r *memoryToolRepository) DeleteReply(ctx context.Context, userID, resourceID, commentID int) (*model.Comment, error) {
+	return r.store.deleteComment(userID, model.ResourceTypeTool, resourceID, commentID, true)
+}
+
+func (r *memoryToolRepository) AddView(ctx context.Context, resourceID int) (int, error) {
+	return r.store.addView(model.ResourceTypeTool, resourceID)
+}
+
+func (r *memoryToolRepository) GetComments(ctx context.Context, resourceID int, page pagination.Page) (*pagination.Result[model.Comment], error) {
+	return r.store.pageComments(model.ResourceTypeTool, resourceID, page)
+}
+
+func (r *memoryToolRepository) GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	tools := s.sortedTools(func(t *memTool) bool {
+		return model.InReviewQueue(t.status) && s.claimMatches(filter, model.ReviewTarget{ResourceType: model.ResourceTypeTool, ResourceID: t.id}, now)
+	})
+	result, err := pageItems(tools, pendingToolSort, toolKey(pendingToolSort), page)
+	if err != nil {
+		return nil, err
+	}
+
+	return pagination.Map(result, func(t *memTool) model.Submit {
+		return model.Submit{
+			Submitor:     s.submitterName(t.submitterID),
+			SubmitDate:   formatTime(t.createdAt),
+			ResourceID:   t.id,
+			ResourceType: model.ResourceTypeTool,
+			Category:     t.category,
+			Link:         t.link,
+			Description:  t.description,
+			Tags:         nonNil(t.tags),
+			ResourceName: t.name,
+		}
+	}), nil
+}
+
+// sortedTools 返回满足条件且不在回收站中的工具，按创建时间和ID倒序（即 toolSorts["latest"]）
+func (s *MemoryStore) sortedTools(match func(t *memTool) bool) []*memTool {
+	var result []*memTool
+	for _, t := range s.tools {
+		if t.deletedAt == nil && match(t) {
+			result = append(result, t)
+		}
+	}
+	sortByKey(result, toolSorts["latest"], toolKey(toolSorts["latest"]))
+	return result
+}
+
+func toolKey(by keyset) func(t *memTool) pagination.Key {
+	return func(t *memTool) pagination.Key {
+		return by.key(t.sortValues(t.createdAt), t.id)
+	}
+}
+
+// pageTools 截取一页工具并转换为接口模型，relevance 为检索结果的相关度
+func (s *MemoryStore) pageTools(tools []*memTool, by keyset, relevance map[int]int64, page pagination.Page) (*pagination.Result[model.Tool], error) {
+	result, err := pageItems(tools, by, func(t *memTool) pagination.Key {
+		values := t.sortValues(t.createdAt)
+		values.relevance = relevance[t.id]
+		return by.key(values, t.id)
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.Map(result, s.toolModel), nil
+}
+
+func (s *MemoryStore) toolModel(t *memTool) model.Tool {
+	return model.Tool{
+		ResourceID:        t.id,
+		ResourceType:      model.ResourceTypeTool,
+		ResourceName:      t.name,
+		ResourceLink:      t.link,
+		Description:       t.description,
+		DescriptionDetail: t.detail,
+		Category:          t.category,
+		Tags:              nonNil(t.tags),
+		Image:             nonNil(t.images),
+		Views:             t.views,
+		Collections:       t.collections,
+		Loves:             t.loves,
+		Comments:          []model.Comment{},
+		CreatedDate:       formatTime(t.createdAt),
+		Contributors:      s.displayNames(t.contributors),
+		Version:           t.version,
+	}
+}
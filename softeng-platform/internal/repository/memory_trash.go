@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"time"
+)
+
+type memoryTrashRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryTrashRepository(store *MemoryStore) TrashRepository {
+	return &memoryTrashRepository{store: store}
+}
+
+// memTrashRow 三类资源在回收站视角下的公共部分，memTrash、memVersion 指向资源行本身
+type memTrashRow struct {
+	*memTrash
+	*memVersion
+	resourceType string
+	id           int
+	name         string
+	status       string
+	owners       []int
+}
+
+func (row memTrashRow) ownedBy(ownerID int) bool {
+	return ownerID == 0 || containsInt(row.owners, ownerID)
+}
+
+func (r *memoryTrashRepository) Delete(ctx context.Context, ownerID, operatorID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := resourceTables[resourceType]; !ok {
+		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+	row, ok := s.trashRow(resourceType, resourceID)
+	if !ok || row.deletedAt != nil || !row.ownedBy(ownerID) {
+		return nil, nil
+	}
+	if !row.match(version) {
+		return nil, ErrVersionConflict
+	}
+	if _, ok := s.users[operatorID]; !ok {
+		return nil, fmt.Errorf("failed to delete %s: user %d does not exist", resourceType, operatorID)
+	}
+
+	now := time.Now()
+	row.deletedAt = &now
+	row.deletedBy = operatorID
+	row.version++
+	item := s.trashItem(row)
+	return &item, nil
+}
+
+func (r *memoryTrashRepository) Restore(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row, err := s.trashed(ownerID, resourceType, resourceID, version)
+	if err != nil || row == nil {
+		return nil, err
+	}
+
+	item := s.trashItem(*row)
+	row.deletedAt = nil
+	row.deletedBy = 0
+	row.version++
+	item.Version = row.version
+	return &item, nil
+}
+
+func (r *memoryTrashRepository) Purge(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	row, err := s.trashed(ownerID, resourceType, resourceID, version)
+	if err != nil || row == nil {
+		return nil, err
+	}
+
+	item := s.trashItem(*row)
+	s.purge(resourceType, resourceID)
+	return &item, nil
+}
+
+func (r *memoryTrashRepository) Get(ctx context.Context, ownerID int, resourceType string, resourceID int) (*model.TrashItem, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := resourceTables[resourceType]; !ok {
+		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+	row, ok := s.trashRow(resourceType, resourceID)
+	if !ok || !row.ownedBy(ownerID) {
+		return nil, nil
+	}
+	item := s.trashItem(row)
+	return &item, nil
+}
+
+func (r *memoryTrashRepository) List(ctx context.Context, ownerID int, page pagination.Page) (*pagination.Result[model.TrashItem], error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var rows []memTrashRow
+	for _, row := range s.trashRows() {
+		if row.deletedAt != nil && row.ownedBy(ownerID) {
+			rows = append(rows, row)
+		}
+	}
+
+	result, err := pageItems(rows, trashSort, func(row memTrashRow) pagination.Key {
+		key := trashSort.key(sortValues{createdAt: *row.deletedAt}, row.id)
+		key.Kind = row.resourceType
+		return key
+	}, page)
+	if err != nil {
+		return nil, err
+	}
+	return pagination.Map(result, s.trashItem), nil
+}
+
+func (r *memoryTrashRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	purged := 0
+	for _, row := range s.trashRows() {
+		if row.deletedAt != nil && row.deletedAt.Before(before) {
+			s.purge(row.resourceType, row.id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+// trashRow 返回资源的软删除列及归属，资源不存在时返回 false
+func (s *MemoryStore) trashRow(resourceType string, resourceID int) (memTrashRow, bool) {
+	switch resourceType {
+	case model.ResourceTypeTool:
+		if t, ok := s.tools[resourceID]; ok {
+			return memTrashRow{&t.memTrash, &t.memVersion, resourceType, t.id, t.name, t.status, t.contributors}, true
+		}
+	case model.ResourceTypeCourse:
+		if c, ok := s.courses[resourceID]; ok {
+			return memTrashRow{&c.memTrash, &c.memVersion, resourceType, c.id, c.name, model.StatusApproved, s.courseContribs[c.id]}, true
+		}
+	case model.ResourceTypeProject:
+		if p, ok := s.projects[resourceID]; ok {
+			return memTrashRow{&p.memTrash, &p.memVersion, resourceType, p.id, p.name, p.status, p.authors}, true
+		}
+	}
+	return memTrashRow{}, false
+}
+
+// trashRows 返回全部资源，包括不在回收站中的
+func (s *MemoryStore) trashRows() []memTrashRow {
+	var rows []memTrashRow
+	for _, t := range s.tools {
+		rows = append(rows, memTrashRow{&t.memTrash, &t.memVersion, model.ResourceTypeTool, t.id, t.name, t.status, t.contributors})
+	}
+	for _, c := range s.courses {
+		rows = append(rows, memTrashRow{&c.memTrash, &c.memVersion, model.ResourceTypeCourse, c.id, c.name, model.StatusApproved, s.courseContribs[c.id]})
+	}
+	for _, p := range s.projects {
+		rows = append(rows, memTrashRow{&p.memTrash, &p.memVersion, model.ResourceTypeProject, p.id, p.name, p.status, p.authors})
+	}
+	return rows
+}
+
+// trashed 返回回收站中属于 ownerID 的资源，找不到时返回 nil，版本不一致时返回 ErrVersionConflict
+func (s *MemoryStore) trashed(ownerID int, resourceType string, resourceID, version int) (*memTrashRow, error) {
+	if _, ok := resourceTables[resourceType]; !ok {
+		return nil, fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+	row, ok := s.trashRow(resourceType, resourceID)
+	if !ok || row.deletedAt == nil || !row.ownedBy(ownerID) {
+		return nil, nil
+	}
+	if !row.match(version) {
+		return nil, ErrVersionConflict
+	}
+	return &row, nil
+}
+
+// inTrash 资源是否在回收站中，对应 SQL 中的 notDeleted 条件取反
+func (s *MemoryStore) inTrash(resourceType string, resourceID int) bool {
+	row, ok := s.trashRow(resourceType, resourceID)
+	return ok && row.deletedAt != nil
+}
+
+func (s *MemoryStore) trashItem(row memTrashRow) model.TrashItem {
+	item := model.TrashItem{
+		ResourceID:   row.id,
+		ResourceType: row.resourceType,
+		ResourceName: row.name,
+		AuditStatus:  row.status,
+		Version:      row.version,
+	}
+	if row.deletedAt != nil {
+		item.DeletedAt = formatTime(*row.deletedAt)
+		item.DeletedBy = s.submitterName(row.deletedBy)
+		item.DeletedTime = *row.deletedAt
+	}
+	return item
+}
+
+// purge 物理删除资源，对应外键级联删除以及 trashRepository.purge 中对多态表的清理
+func (s *MemoryStore) purge(resourceType string, resourceID int) {
+	switch resourceType {
+	case model.ResourceTypeTool:
+		delete(s.tools, resourceID)
+	case model.ResourceTypeCourse:
+		delete(s.courses, resourceID)
+		delete(s.courseContribs, resourceID)
+		for _, table := range []map[int]*memCourseResource{s.courseWeb, s.courseUpload} {
+			for id, res := range table {
+				if res.courseID == resourceID {
+					delete(table, id)
+				}
+			}
+		}
+	case model.ResourceTypeProject:
+		delete(s.projects, resourceID)
+	}
+
+	for id, c := range s.comments {
+		if c.resourceType == resourceType && c.resourceID == resourceID {
+			s.deleteCommentRows(id)
+		}
+	}
+	for _, relations := range []map[relationKey]*memRelation{s.likes, s.collections} {
+		for key := range relations {
+			if key.resourceType == resourceType && key.resourceID == resourceID {
+				delete(relations, key)
+			}
+		}
+	}
+	for key := range s.dailyViews {
+		if key.resourceType == resourceType && key.resourceID == resourceID {
+			delete(s.dailyViews, key)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"sort"
+	"time"
+)
+
+type memoryUserRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryUserRepository(store *MemoryStore) UserRepository {
+	return &memoryUserRepository{store: store}
+}
+
+func (r *memoryUserRepository) Create(ctx context.Context, user *model.User) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if u.Username == user.Username || u.Email == user.Email {
+			return fmt.Errorf("failed to create user: %w", errDuplicateEntry)
+		}
+	}
+
+	now := time.Now()
+	row := &memUser{User: *user}
+	row.ID = s.nextID("users")
+	row.CreatedAt = now
+	row.UpdatedAt = now
+	s.users[row.ID] = row
+
+	user.ID = row.ID
+	return nil
+}
+
+func (r *memoryUserRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
+	return r.find(func(u *memUser) bool { return u.ID == id }), nil
+}
+
+func (r *memoryUserRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
+	return r.find(func(u *memUser) bool { return u.Username == username }), nil
+}
+
+func (r *memoryUserRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
+	return r.find(func(u *memUser) bool { return u.Email == email }), nil
+}
+
+func (r *memoryUserRepository) find(match func(u *memUser) bool) *model.User {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, u := range s.users {
+		if match(u) {
+			user := u.User
+			return &user
+		}
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) Update(ctx context.Context, user *model.User) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[user.ID]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	u.Nickname = user.Nickname
+	u.Avatar = user.Avatar
+	u.Description = user.Description
+	u.FacePhoto = user.FacePhoto
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *memoryUserRepository) UpdatePassword(ctx context.Context, userID int, hashedPassword string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[userID]
+	if !ok {
+		return fmt.Errorf("user not found")
+	}
+	u.Password = hashedPassword
+	u.UpdatedAt = time.Now()
+	return nil
+}
+
+// Delete 删除用户，按外键定义级联删除其点赞、收藏、评论、举报和贡献者记录，提交记录的 submitter_id、
+// 回收站记录的 deleted_by、状态变更记录的 operator_id、举报的 resolved_by 和敏感词的 created_by 置空
+func (r *memoryUserRepository) Delete(ctx context.Context, userID int) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[userID]; !ok {
+		return fmt.Errorf("user not found")
+	}
+	delete(s.users, userID)
+
+	for key := range s.likes {
+		if key.userID == userID {
+			delete(s.likes, key)
+		}
+	}
+	for key := range s.collections {
+		if key.userID == userID {
+			delete(s.collections, key)
+		}
+	}
+	for id, c := range s.comments {
+		if c.userID == userID {
+			s.deleteCommentRows(id)
+		}
+	}
+	for id, search := range s.savedSearches {
+		if search.UserID == userID {
+			delete(s.savedSearches, id)
+		}
+	}
+	for id, n := range s.notifications {
+		if n.UserID == userID {
+			delete(s.notifications, id)
+		}
+	}
+
+	for _, t := range s.tools {
+		t.contributors = removeInt(t.contributors, userID)
+		if t.submitterID == userID {
+			t.submitterID = 0
+		}
+		if t.deletedBy == userID {
+			t.deletedBy = 0
+		}
+	}
+	for _, p := range s.projects {
+		p.authors = removeInt(p.authors, userID)
+		if p.submitterID == userID {
+			p.submitterID = 0
+		}
+		if p.deletedBy == userID {
+			p.deletedBy = 0
+		}
+	}
+	for _, c := range s.courses {
+		if c.deletedBy == userID {
+			c.deletedBy = 0
+		}
+	}
+	for courseID, ids := range s.courseContribs {
+		s.courseContribs[courseID] = removeInt(ids, userID)
+	}
+	for _, res := range s.courseWeb {
+		if res.submitterID == userID {
+			res.submitterID = 0
+		}
+	}
+	for _, res := range s.courseUpload {
+		if res.submitterID == userID {
+			res.submitterID = 0
+		}
+	}
+	for _, log := range s.statusLogs {
+		if log.operatorID == userID {
+			log.operatorID = 0
+		}
+	}
+	for id, report := range s.commentReports {
+		if report.userID == userID {
+			delete(s.commentReports, id)
+		} else if report.resolvedBy == userID {
+			report.resolvedBy = 0
+		}
+	}
+	for _, w := range s.sensitiveWords {
+		if w.CreatedBy == userID {
+			w.CreatedBy = 0
+		}
+	}
+	for _, claim := range s.reviewClaims {
+		if claim.assigneeID == userID {
+			claim.assigneeID = 0
+		}
+		if claim.claimantID == userID {
+			claim.claimantID = 0
+		}
+	}
+	return nil
+}
+
+func (r *memoryUserRepository) GetCollection(ctx context.Context, userID int, page pagination.Page) (*model.UserResources, pagination.Info, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type collected struct {
+		resourceType string
+		resourceID   int
+		relation     *memRelation
+	}
+	var items []collected
+	for key, rel := range s.collections {
+		if _, ok := resourceTables[key.resourceType]; ok && key.userID == userID && !s.inTrash(key.resourceType, key.resourceID) {
+			items = append(items, collected{key.resourceType, key.resourceID, rel})
+		}
+	}
+	paged, err := pageItems(items, collectionSort, func(c collected) pagination.Key {
+		return collectionSort.key(sortValues{createdAt: c.relation.createdAt}, c.relation.id)
+	}, page)
+	if err != nil {
+		return nil, pagination.Info{}, err
+	}
+
+	result := &model.UserResources{
+		Resources: []model.ResourcePersonal{},
+		Tools:     []model.ToolPersonal{},
+		Teaches:   []model.TeachPersonal{},
+	}
+	for _, item := range paged.Items {
+		switch item.resourceType {
+		case model.ResourceTypeProject:
+			if p, ok := s.projects[item.resourceID]; ok {
+				result.Resources = append(result.Resources, s.projectPersonal(p))
+			}
+		case model.ResourceTypeTool:
+			if t, ok := s.tools[item.resourceID]; ok {
+				result.Tools = append(result.Tools, model.ToolPersonal(s.toolPersonal(t)))
+			}
+		case model.ResourceTypeCourse:
+			if c, ok := s.courses[item.resourceID]; ok {
+				result.Teaches = append(result.Teaches, model.TeachPersonal{
+					ResourceID:   c.id,
+					ResourceType: model.ResourceTypeCourse,
+					Resource:     c.name,
+					Image:        c.cover,
+					Introduce:    c.semester,
+					Contributer:  s.publicUsers(s.courseContribs[c.id]),
+				})
+			}
+		}
+	}
+	return result, paged.Info, nil
+}
+
+func (r *memoryUserRepository) DeleteCollection(ctx context.Context, userID int, resourceType string, resourceID int) error {
+	s := r.store
+	s.mu.Lock()
+	_, has := s.collections[relationKey{userID: userID, resourceType: resourceType, resourceID: resourceID}]
+	s.mu.Unlock()
+	if !has {
+		return fmt.Errorf("collection not found")
+	}
+
+	_, err := s.setCollect(userID, resourceType, resourceID, false)
+	return err
+}
+
+func (r *memoryUserRepository) GetSubmissions(ctx context.Context, userID int) (*model.UserResources, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &model.UserResources{
+		Resources: []model.ResourcePersonal{},
+		Tools:     []model.ToolPersonal{},
+		Teaches:   []model.TeachPersonal{},
+	}
+
+	for _, p := range s.sortedProjects(func(p *memProject) bool { return p.submitterID == userID }) {
+		result.Resources = append(result.Resources, s.projectPersonal(p))
+	}
+	for _, t := range s.sortedTools(func(t *memTool) bool { return t.submitterID == userID }) {
+		result.Tools = append(result.Tools, model.ToolPersonal(s.toolPersonal(t)))
+	}
+
+	contributer := []model.User{}
+	if u, ok := s.users[userID]; ok {
+		contributer = []model.User{publicUser(u.User)}
+	}
+	for _, res := range s.courseResources(func(res *memCourseResource) bool { return res.submitterID == userID }) {
+		image := ""
+		if c, ok := s.courses[res.courseID]; ok {
+			image = c.cover
+		}
+		result.Teaches = append(result.Teaches, model.TeachPersonal{
+			ResourceID:   res.id,
+			ResourceType: model.ResourceTypeCourse,
+			Resource:     res.resource,
+			Image:        image,
+			Introduce:    res.intro,
+			Contributer:  contributer,
+		})
+	}
+	return result, nil
+}
+
+func (r *memoryUserRepository) GetReviewStatus(ctx context.Context, userID int) (*model.UserReviewStatus, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := &model.UserReviewStatus{
+		Resources: []model.ResourceReview{},
+		Tools:     []model.ResourceReview{},
+		Teaches:   []model.TeachReview{},
+	}
+
+	for _, p := range s.sortedProjects(func(p *memProject) bool { return p.submitterID == userID }) {
+		result.Resources = append(result.Resources, model.ResourceReview{
+			ResourceID:   p.id,
+			ResourceType: model.ResourceTypeProject,
+			Resource:     p.github,
+			AuditStatus:  p.status,
+			SubmitTime:   formatTime(p.createdAt),
+			AuditTime:    formatTimePtr(p.auditTime),
+			RejectReason: copyStringPtr(p.rejectReason),
+		})
+	}
+	for _, t := range s.sortedTools(func(t *memTool) bool { return t.submitterID == userID }) {
+		result.Tools = append(result.Tools, model.ResourceReview{
+			ResourceID:   t.id,
+			ResourceType: model.ResourceTypeTool,
+			Resource:     t.link,
+			AuditStatus:  t.status,
+			SubmitTime:   formatTime(t.createdAt),
+			AuditTime:    formatTimePtr(t.auditTime),
+			RejectReason: copyStringPtr(t.rejectReason),
+		})
+	}
+	for _, res := range s.courseResources(func(res *memCourseResource) bool { return res.submitterID == userID }) {
+		review := model.TeachReview{
+			ResourceID:   res.id,
+			ResourceType: model.ResourceTypeCourse,
+			AuditStatus:  res.status,
+			SubmitTime:   formatTime(res.createdAt),
+			AuditTime:    formatTimePtr(res.auditTime),
+			RejectReason: copyStringPtr(res.rejectReason),
+		}
+		if s.courseWeb[res.id] == res {
+			review.Resource1 = &model.ResourceWeb{ResourceIntro: res.intro, ResourceURL: res.resource, ResourceID: res.id}
+		} else {
+			review.Resource2 = &model.ResourceUpload{ResourceIntro: res.intro, ResourceUpload: res.resource, ResourceID: res.id}
+		}
+		result.Teaches = append(result.Teaches, review)
+	}
+	return result, nil
+}
+
+func (s *MemoryStore) projectPersonal(p *memProject) model.ResourcePersonal {
+	return model.ResourcePersonal{
+		ResourceID:   p.id,
+		ResourceType: model.ResourceTypeProject,
+		Resource:     p.name,
+		Image:        p.cover,
+		Introduce:    p.description,
+		Contributer:  s.publicUsers(p.authors),
+	}
+}
+
+func (s *MemoryStore) toolPersonal(t *memTool) model.ResourcePersonal {
+	return model.ResourcePersonal{
+		ResourceID:   t.id,
+		ResourceType: model.ResourceTypeTool,
+		Resource:     t.name,
+		Image:        firstOrEmpty(t.images),
+		Introduce:    t.description,
+		Contributer:  s.publicUsers(t.contributors),
+	}
+}
+
+// courseResources 返回满足条件的网页和上传资源，按提交时间和ID倒序
+func (s *MemoryStore) courseResources(match func(res *memCourseResource) bool) []*memCourseResource {
+	var result []*memCourseResource
+	for _, table := range []map[int]*memCourseResource{s.courseWeb, s.courseUpload} {
+		for _, res := range table {
+			if match(res) {
+				result = append(result, res)
+			}
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if !result[i].createdAt.Equal(result[j].createdAt) {
+			return result[i].createdAt.After(result[j].createdAt)
+		}
+		return result[i].id > result[j].id
+	})
+	return result
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"context"
+	"softeng-platform/internal/model"
+	"sort"
+)
+
+type memoryViewRepository struct {
+	store *MemoryStore
+}
+
+func NewMemoryViewRepository(store *MemoryStore) ViewRepository {
+	return &memoryViewRepository{store: store}
+}
+
+func (r *memoryViewRepository) Views(ctx context.Context, resourceType string, resourceID int) (int, bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := lookupResourceTable(resourceType); err != nil {
+		return 0, false, err
+	}
+	counters := s.counters(resourceType, resourceID)
+	if counters == nil {
+		return 0, false, nil
+	}
+	return counters.views, true, nil
+}
+
+func (r *memoryViewRepository) AddViews(ctx context.Context, deltas []ViewDelta) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 先检查全部类型，出错时不写入任何增量，与 SQL 实现的事务一致
+	for _, d := range deltas {
+		if _, err := lookupResourceTable(d.ResourceType); err != nil {
+			return err
+		}
+	}
+	for _, d := range deltas {
+		counters := s.counters(d.ResourceType, d.ResourceID)
+		if counters == nil {
+			continue
+		}
+		counters.views += d.Views
+		s.dailyViews[dailyViewKey{d.ResourceType, d.ResourceID, d.Date}] += d.Views
+	}
+	return nil
+}
+
+func (r *memoryViewRepository) DailyViews(ctx context.Context, resourceType string, resourceID int, from, to string) ([]model.DailyViews, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	daily := []model.DailyViews{}
+	for key, views := range s.dailyViews {
+		if key.resourceType == resourceType && key.resourceID == resourceID && key.date >= from && key.date <= to {
+			daily = append(daily, model.DailyViews{Date: key.date, Views: views})
+		}
+	}
+	sort.Slice(daily, func(i, j int) bool { return daily[i].Date < daily[j].Date })
+	return daily, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"time"
+)
+
+// NotificationRepository 站内通知
+type NotificationRepository interface {
+	// Create 写入一条通知，写回 ID 和创建时间
+	Create(ctx context.Context, n *model.Notification) error
+	// List 分页列出用户的通知，按时间倒序；unreadOnly 为 true 时只返回未读的
+	List(ctx context.Context, userID int, unreadOnly bool, page pagination.Page) (*pagination.Result[model.Notification], error)
+	// MarkRead 把用户的一条通知标为已读，id 为 0 时标记全部；返回标记的条数
+	MarkRead(ctx context.Context, userID, id int) (int, error)
+	// Count 统计用户自 since 以来收到的某类通知数
+	Count(ctx context.Context, userID int, kind string, since time.Time) (int, error)
+}
+
+type notificationRepository struct {
+	db *Database
+}
+
+func NewNotificationRepository(db *Database) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// notificationSort 通知按时间倒序
+var notificationSort = keyset{name: "latest", field: byCreatedAt, column: "created_at", idColumn: "id"}
+
+func (r *notificationRepository) Create(ctx context.Context, n *model.Notification) error {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO notifications (user_id, kind, title, content, channel, resource_type, resource_id, ref_id, is_read, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		n.UserID, n.Kind, n.Title, n.Content, n.Channel, nullIfEmpty(n.ResourceType), nullIfZero(n.ResourceID), nullIfZero(n.RefID), n.IsRead, now)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	n.ID = int(id)
+	n.CreatedTime = now
+	n.CreatedAt = formatTime(now)
+	return nil
+}
+
+func (r *notificationRepository) List(ctx context.Context, userID int, unreadOnly bool, page pagination.Page) (*pagination.Result[model.Notification], error) {
+	where := []string{"user_id = ?"}
+	args := []interface{}{userID}
+	if unreadOnly {
+		where = append(where, "is_read = ?")
+		args = append(args, false)
+	}
+
+	query, queryArgs, err := pageQuery(r.db.Dialect, `SELECT id, user_id, kind, title, content, channel,
+		resource_type, resource_id, ref_id, is_read, created_at FROM notifications`, where, args, notificationSort, page)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list notifications: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.Notification
+	var keys []pagination.Key
+	for rows.Next() {
+		var n model.Notification
+		var content, resourceType sql.NullString
+		var resourceID, refID sql.NullInt64
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Kind, &n.Title, &content, &n.Channel,
+			&resourceType, &resourceID, &refID, &n.IsRead, &n.CreatedTime); err != nil {
+			return nil, fmt.Errorf("failed to scan notification: %w", err)
+		}
+		n.Content = content.String
+		n.ResourceType = resourceType.String
+		n.ResourceID = int(resourceID.Int64)
+		n.RefID = int(refID.Int64)
+		n.CreatedAt = formatTime(n.CreatedTime)
+		items = append(items, n)
+		keys = append(keys, notificationSort.key(sortValues{createdAt: n.CreatedTime}, n.ID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewResult(items, keys, normalizeLimit(page.Limit))
+	if result.Total, err = pageTotal(ctx, r.db, "notifications", where, args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id int) (int, error) {
+	query := `UPDATE notifications SET is_read = ? WHERE user_id = ? AND is_read = ?`
+	args := []interface{}{true, userID, false}
+	if id != 0 {
+		query += ` AND id = ?`
+		args = append(args, id)
+	}
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return int(rows), nil
+}
+
+func (r *notificationRepository) Count(ctx context.Context, userID int, kind string, since time.Time) (int, error) {
+	var count int
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM notifications WHERE user_id = ? AND kind = ? AND %s >= %s`,
+		r.db.Dialect.TimeKey("created_at"), r.db.Dialect.TimeKey("?"))
+	if err := r.db.QueryRowContext(ctx, query, userID, kind, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
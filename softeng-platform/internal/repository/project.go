@@ -2,24 +2,37 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/tagquery"
+	"time"
 )
 
 type ProjectRepository interface {
-	GetProjects(ctx context.Context, category string, techStack []string, sort string, limit int, cursor string) ([]map[string]interface{}, error)
-	GetByID(ctx context.Context, projectID string) (map[string]interface{}, error)
-	Search(ctx context.Context, keyword string, category []string, cursor string, limit int) ([]map[string]interface{}, error)
-	Create(ctx context.Context, userID int, data map[string]interface{}) (map[string]interface{}, error)
-	Update(ctx context.Context, userID int, projectID string, data map[string]interface{}) (map[string]interface{}, error)
-	LikeProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	UnlikeProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	AddComment(ctx context.Context, userID int, projectID, content string) (map[string]interface{}, error)
-	DeleteComment(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	ReplyComment(ctx context.Context, userID int, projectID, commentID, content string) (map[string]interface{}, error)
-	DeleteReply(ctx context.Context, userID int, projectID, commentID string) (map[string]interface{}, error)
-	AddView(ctx context.Context, projectID string) (int, error)
-	CollectProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	UncollectProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) // 新增方法
+	// GetProjects 技术栈满足 techStack 的项目，category 为空、techStack 为 nil 时不筛选
+	GetProjects(ctx context.Context, category string, techStack tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Project], error)
+	GetByID(ctx context.Context, projectID int) (*model.ProjectDetail, error)
+	// GetCurrent 与 GetByID 相同，但不限审核状态，用于作者修改时获取最新内容
+	GetCurrent(ctx context.Context, projectID int) (*model.ProjectDetail, error)
+	// Search 按关键词检索名称、简介、详情和技术栈，同时按分类筛选；sort 为空时按相关度排序
+	Search(ctx context.Context, keyword string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Project], error)
+	Create(ctx context.Context, userID int, req model.ProjectUploadRequest) (*model.ResourceReview, error)
+	// Update 修改项目，version 为 0 时不检查版本，与当前版本不一致时返回 ErrVersionConflict
+	Update(ctx context.Context, userID, projectID, version int, req model.ProjectUploadRequest) (*model.ResourceReview, error)
+	LikeProject(ctx context.Context, userID, projectID int) (*model.LikeStatus, error)
+	UnlikeProject(ctx context.Context, userID, projectID int) (*model.LikeStatus, error)
+	AddComment(ctx context.Context, userID, projectID int, content string) (*model.Comment, error)
+	DeleteComment(ctx context.Context, userID, projectID, commentID int) (*model.Comment, error)
+	ReplyComment(ctx context.Context, userID, projectID, commentID int, content string) (*model.Comment, error)
+	DeleteReply(ctx context.Context, userID, projectID, commentID int) (*model.Comment, error)
+	AddView(ctx context.Context, projectID int) (int, error)
+	CollectProject(ctx context.Context, userID, projectID int) (*model.CollectStatus, error)
+	UncollectProject(ctx context.Context, userID, projectID int) (*model.CollectStatus, error)
+	GetComments(ctx context.Context, projectID int, page pagination.Page) (*pagination.Result[model.Comment], error)
+	GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) // 新增方法
 }
 
 type projectRepository struct {
@@ -30,188 +43,441 @@ func NewProjectRepository(db *Database) ProjectRepository {
 	return &projectRepository{db: db}
 }
 
-func (r *projectRepository) GetProjects(ctx context.Context, category string, techStack []string, sort string, limit int, cursor string) ([]map[string]interface{}, error) {
-	// 实现获取项目列表的逻辑
-	return []map[string]interface{}{
-		{
-			"projectId":    1,
-			"resourceType": "project",
-			"name":         "校园社交平台",
-			"description":  "基于Go和React的校园社交平台",
-			"category":     "实训项目",
-			"techStack":    []string{"Go", "React", "PostgreSQL"},
-			"likecount":    45,
-			"authername":   []string{"张三", "李四"},
-			"cover":        "https://example.com/project1.jpg",
-			"createdat":    "2023-11-01",
-			"loves":        45,
-			"collections":  30,
-			"views":        200,
-		},
-	}, nil
+const projectColumns = `
+	project_id, name, COALESCE(description, ''), COALESCE(detail, ''), COALESCE(github_url, ''),
+	COALESCE(category, ''), COALESCE(cover, ''), views, loves, collections, created_at, version
+`
+
+var (
+	projectSorts       = resourceSorts("project_id")
+	projectSearchSorts = searchSorts("project_id")
+)
+
+// pendingProjectSort 待审核项目按提交时间先后排列
+var pendingProjectSort = keyset{name: "pending", field: byCreatedAt, column: "p.created_at", idColumn: "p.project_id", asc: true}
+
+// projectRow 项目表的一行，列表和详情共用
+type projectRow struct {
+	model.ProjectDetail
+	createdAt time.Time
 }
 
-func (r *projectRepository) GetByID(ctx context.Context, projectID string) (map[string]interface{}, error) {
-	// 实现根据ID获取项目详情的逻辑
-	return map[string]interface{}{
-		"projectId":     projectID,
-		"resourceType":  "project",
-		"name":          "校园社交平台",
-		"description":   "基于Go和React的校园社交平台",
-		"githubURL":     "https://github.com/example/social-platform",
-		"techStack":     []string{"Go", "React", "PostgreSQL"},
-		"catagory":      "实训项目",
-		"images":        []string{"https://example.com/image1.jpg", "https://example.com/image2.jpg"},
-		"likes":         45,
-		"views":         200,
-		"collections":   30,
-		"isliked":       false,
-		"iscollected":   false,
-		"author":        []string{"张三", "李四"},
-		"comment_count": 10,
-		"comments":      []map[string]interface{}{},
-		"createdAt":     "2023-11-01",
-	}, nil
+func (row projectRow) sortValues() sortValues {
+	return sortValues{createdAt: row.createdAt, views: row.Views, loves: row.Likes, collections: row.Collections}
 }
 
-func (r *projectRepository) Search(ctx context.Context, keyword string, category []string, cursor string, limit int) ([]map[string]interface{}, error) {
-	// 实现搜索项目的逻辑
-	return []map[string]interface{}{
-		{
-			"projectId":    2,
-			"resourceType": "project",
-			"name":         "电商平台 - " + keyword,
-			"description":  "完整的电商平台解决方案",
-			"category":     "课程设计",
-			"techStack":    []string{"Java", "Spring Boot", "Vue"},
-			"likecount":    35,
-			"authername":   []string{"王五"},
-			"cover":        "https://example.com/project2.jpg",
-			"createdat":    "2023-10-15",
-			"loves":        35,
-			"collections":  25,
-			"views":        150,
-		},
-	}, nil
+func (r *projectRepository) GetProjects(ctx context.Context, category string, techStack tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Project], error) {
+	where := []string{"status = ?", "deleted_at IS NULL"}
+	args := []interface{}{model.StatusApproved}
+
+	if category != "" {
+		where = append(where, "category = ?")
+		args = append(args, category)
+	}
+	if techStack != nil {
+		condition, techArgs := tagquery.SQL(techStack, "project_id IN (SELECT project_id FROM project_tech_stack WHERE tech = ?)")
+		where = append(where, condition)
+		args = append(args, techArgs...)
+	}
+
+	return r.pageProjects(ctx, tableQuery(projectColumns, "projects", where, args), lookupSort(projectSorts, sort), page)
 }
 
-func (r *projectRepository) Create(ctx context.Context, userID int, data map[string]interface{}) (map[string]interface{}, error) {
-	// 实现创建项目的逻辑
-	return map[string]interface{}{
-		"resourceId":   1,
-		"resourceType": "resource",
-		"resource":     "https://example.com/project",
-		"auditStatus":  "pending",
-		"submitTime":   "2023-12-01 10:00:00",
-		"auditTime":    nil,
-		"rejectReason": nil,
-	}, nil
+func (r *projectRepository) GetByID(ctx context.Context, projectID int) (*model.ProjectDetail, error) {
+	return r.get(ctx, projectID, true)
 }
 
-func (r *projectRepository) Update(ctx context.Context, userID int, projectID string, data map[string]interface{}) (map[string]interface{}, error) {
-	// 实现更新项目的逻辑
-	return map[string]interface{}{
-		"resourceId":   1,
-		"resourceType": "resource",
-		"resource":     "https://example.com/updated-project",
-		"auditStatus":  "pending",
-		"submitTime":   "2023-12-01 10:00:00",
-		"auditTime":    nil,
-		"rejectReason": nil,
-	}, nil
+func (r *projectRepository) GetCurrent(ctx context.Context, projectID int) (*model.ProjectDetail, error) {
+	return r.get(ctx, projectID, false)
 }
 
-func (r *projectRepository) LikeProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"likecounts": 46,
-		"isliked":    true,
-	}, nil
+func (r *projectRepository) get(ctx context.Context, projectID int, approvedOnly bool) (*model.ProjectDetail, error) {
+	query := `SELECT ` + projectColumns + ` FROM projects WHERE project_id = ? AND deleted_at IS NULL`
+	args := []interface{}{projectID}
+	if approvedOnly {
+		query += ` AND status = ?`
+		args = append(args, model.StatusApproved)
+	}
+
+	row, err := scanProject(r.db.QueryRowContext(ctx, query, args...))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get project by id: %w", err)
+	}
+
+	rows := []projectRow{*row}
+	if err := r.loadRelations(ctx, rows); err != nil {
+		return nil, err
+	}
+	detail := rows[0].ProjectDetail
+
+	if detail.Comments, err = listComments(ctx, r.db, model.ResourceTypeProject, projectID); err != nil {
+		return nil, err
+	}
+	if detail.CommentCount, err = countComments(ctx, r.db, model.ResourceTypeProject, projectID); err != nil {
+		return nil, err
+	}
+
+	return &detail, nil
 }
 
-func (r *projectRepository) UnlikeProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"likecounts": 45,
-		"isliked":    false,
-	}, nil
+func (r *projectRepository) Search(ctx context.Context, keyword string, category []string, sort string, page pagination.Page) (*pagination.Result[model.Project], error) {
+	where := []string{"status = ?", "deleted_at IS NULL"}
+	args := []interface{}{model.StatusApproved}
+
+	if len(category) > 0 {
+		where = append(where, "category IN ("+placeholders(len(category))+")")
+		for _, c := range category {
+			args = append(args, c)
+		}
+	}
+
+	q := projectSearch.query(r.db.Dialect, projectColumns, keyword, where, args)
+	return r.pageProjects(ctx, q, searchSort(projectSearchSorts, sort, keyword), page)
 }
 
-func (r *projectRepository) AddComment(ctx context.Context, userID int, projectID, content string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"comment_Id":  1,
-		"nickname":    "用户",
-		"avater":      "https://example.com/avatar.jpg",
-		"comment":     content,
-		"commentDate": "2023-12-01 10:00:00",
-		"love_count":  0,
-		"reply_total": 0,
-		"replies":     []map[string]interface{}{},
-	}, nil
+// Create 在一个事务中写入项目及其作者、技术栈、图片
+func (r *projectRepository) Create(ctx context.Context, userID int, req model.ProjectUploadRequest) (*model.ResourceReview, error) {
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.ResourceReview, error) {
+		return r.create(ctx, userID, req)
+	})
 }
 
-func (r *projectRepository) DeleteComment(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"comment_Id":  1,
-		"nickname":    "用户",
-		"avater":      "https://example.com/avatar.jpg",
-		"comment":     "已删除的评论",
-		"delete_Date": "2023-12-01 10:00:00",
+func (r *projectRepository) create(ctx context.Context, userID int, req model.ProjectUploadRequest) (*model.ResourceReview, error) {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO projects (resource_type, name, description, detail, github_url, category, cover, status, submitter_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		model.ResourceTypeProject, req.Name, req.Description, req.Detail, req.Github, req.Category,
+		firstOrEmpty(req.Images), model.StatusPending, userID, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	if err := r.replaceRelations(ctx, int(id), req); err != nil {
+		return nil, err
+	}
+	if _, err := r.db.ExecContext(ctx, `INSERT INTO project_authors (project_id, user_id) VALUES (?, ?)`, id, userID); err != nil {
+		return nil, fmt.Errorf("failed to create project author: %w", err)
+	}
+
+	return &model.ResourceReview{
+		ResourceID:   int(id),
+		ResourceType: model.ResourceTypeProject,
+		Resource:     req.Github,
+		AuditStatus:  model.StatusPending,
+		SubmitTime:   formatTime(now),
 	}, nil
 }
 
-func (r *projectRepository) ReplyComment(ctx context.Context, userID int, projectID, commentID, content string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"comment_Id":  2,
-		"nickname":    "用户",
-		"avater":      "https://example.com/avatar.jpg",
-		"comment":     content,
-		"commentDate": "2023-12-01 10:00:00",
-		"love_count":  0,
-		"reply_total": 0,
-		"replies":     []map[string]interface{}{},
-	}, nil
+// Update 在一个事务中更新项目并替换技术栈、图片
+func (r *projectRepository) Update(ctx context.Context, userID, projectID, version int, req model.ProjectUploadRequest) (*model.ResourceReview, error) {
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.ResourceReview, error) {
+		return r.update(ctx, userID, projectID, version, req)
+	})
 }
 
-func (r *projectRepository) DeleteReply(ctx context.Context, userID int, projectID, commentID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"comment_Id":  2,
-		"nickname":    "用户",
-		"avater":      "https://example.com/avatar.jpg",
-		"comment":     "已删除的回复",
-		"delete_Date": "2023-12-01 10:00:00",
+func (r *projectRepository) update(ctx context.Context, userID, projectID, version int, req model.ProjectUploadRequest) (*model.ResourceReview, error) {
+	var authors int
+	err := r.db.QueryRowContext(ctx,
+		`SELECT COUNT(*) FROM project_authors pa JOIN projects p ON p.project_id = pa.project_id
+		WHERE pa.project_id = ? AND pa.user_id = ? AND p.deleted_at IS NULL`, projectID, userID,
+	).Scan(&authors)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check project author: %w", err)
+	}
+	if authors == 0 {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	// 修改后的项目需要重新审核；版本号在同一条语句中检查并递增，并发修改只有一个能成功
+	now := time.Now()
+	match, matchArgs := versionMatch("version", version)
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE projects
+		SET name = ?, description = ?, detail = ?, github_url = ?, category = ?, cover = ?,
+			status = ?, audit_time = NULL, reject_reason = NULL, updated_at = ?, version = version + 1
+		WHERE project_id = ? AND deleted_at IS NULL`+match,
+		append([]interface{}{req.Name, req.Description, req.Detail, req.Github, req.Category, firstOrEmpty(req.Images),
+			model.StatusPending, now, projectID}, matchArgs...)...,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update project: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return nil, ErrVersionConflict
+	}
+
+	var newVersion int
+	if err := r.db.QueryRowContext(ctx, `SELECT version FROM projects WHERE project_id = ?`, projectID).Scan(&newVersion); err != nil {
+		return nil, fmt.Errorf("failed to get project version: %w", err)
+	}
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM project_tech_stack WHERE project_id = ?`, projectID); err != nil {
+		return nil, fmt.Errorf("failed to clear project tech stack: %w", err)
+	}
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM project_images WHERE project_id = ?`, projectID); err != nil {
+		return nil, fmt.Errorf("failed to clear project images: %w", err)
+	}
+	if err := r.replaceRelations(ctx, projectID, req); err != nil {
+		return nil, err
+	}
+
+	return &model.ResourceReview{
+		ResourceID:   projectID,
+		ResourceType: model.ResourceTypeProject,
+		Resource:     req.Github,
+		AuditStatus:  model.StatusPending,
+		SubmitTime:   formatTime(now),
+		Version:      newVersion,
 	}, nil
 }
 
-func (r *projectRepository) AddView(ctx context.Context, projectID string) (int, error) {
-	return 201, nil
+func (r *projectRepository) LikeProject(ctx context.Context, userID, projectID int) (*model.LikeStatus, error) {
+	return setLike(ctx, r.db, userID, model.ResourceTypeProject, projectID, true)
 }
 
-func (r *projectRepository) CollectProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"iscollected": true,
-		"collections": 31,
-	}, nil
+func (r *projectRepository) UnlikeProject(ctx context.Context, userID, projectID int) (*model.LikeStatus, error) {
+	return setLike(ctx, r.db, userID, model.ResourceTypeProject, projectID, false)
 }
 
-func (r *projectRepository) UncollectProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"iscollected": false,
-		"collections": 30,
-	}, nil
+func (r *projectRepository) AddComment(ctx context.Context, userID, projectID int, content string) (*model.Comment, error) {
+	return addComment(ctx, r.db, userID, model.ResourceTypeProject, projectID, nil, content)
 }
 
-func (r *projectRepository) GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) {
-	return []map[string]interface{}{
-		{
-			"submitor":     "user2",
-			"submitDate":   "2023-12-01 10:00:00",
-			"reourceId":    1,
-			"resourceType": "project",
-			"resourcename": "新项目",
-			"catagory":     "实训项目",
-			"link":         "https://github.com/example/project",
-			"description":  "项目描述",
-			"tags":         []string{"Go", "React"},
-			"file":         "project.zip",
-		},
-	}, nil
+func (r *projectRepository) DeleteComment(ctx context.Context, userID, projectID, commentID int) (*model.Comment, error) {
+	return deleteComment(ctx, r.db, userID, model.ResourceTypeProject, projectID, commentID, false)
+}
+
+func (r *projectRepository) ReplyComment(ctx context.Context, userID, projectID, commentID int, content string) (*model.Comment, error) {
+	return addComment(ctx, r.db, userID, model.ResourceTypeProject, projectID, &commentID, content)
+}
+
+func (r *projectRepository) DeleteReply(ctx context.Context, userID, projectID, commentID int) (*model.Comment, error) {
+	return deleteComment(ctx, r.db, userID, model.ResourceTypeProject, projectID, commentID, true)
+}
+
+func (r *projectRepository) AddView(ctx context.Context, projectID int) (int, error) {
+	return addView(ctx, r.db, model.ResourceTypeProject, projectID)
+}
+
+func (r *projectRepository) CollectProject(ctx context.Context, userID, projectID int) (*model.CollectStatus, error) {
+	return setCollect(ctx, r.db, userID, model.ResourceTypeProject, projectID, true)
+}
+
+func (r *projectRepository) UncollectProject(ctx context.Context, userID, projectID int) (*model.CollectStatus, error) {
+	return setCollect(ctx, r.db, userID, model.ResourceTypeProject, projectID, false)
+}
+
+func (r *projectRepository) GetComments(ctx context.Context, projectID int, page pagination.Page) (*pagination.Result[model.Comment], error) {
+	return pageComments(ctx, r.db, model.ResourceTypeProject, projectID, page)
+}
+
+func (r *projectRepository) GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) {
+	where := []string{"p.status IN (?, ?)", "p.deleted_at IS NULL"}
+	args := []interface{}{model.StatusPending, model.StatusResubmitted}
+	claim, claimArgs := claimWhere(r.db.Dialect, filter, "'"+model.ResourceTypeProject+"'", "p.project_id")
+	where = append(where, claim...)
+	args = append(args, claimArgs...)
+
+	query, queryArgs, err := pageQuery(r.db.Dialect, `
+		SELECT p.project_id, p.name, COALESCE(p.category, ''), COALESCE(p.github_url, ''),
+			COALESCE(p.description, ''), p.created_at, COALESCE(u.nickname, u.username, '')
+		FROM projects p LEFT JOIN users u ON u.id = p.submitter_id`, where, args, pendingProjectSort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending projects: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.Submit
+	var keys []pagination.Key
+	var ids []int
+	for rows.Next() {
+		var item model.Submit
+		var createdAt time.Time
+		if err := rows.Scan(&item.ResourceID, &item.ResourceName, &item.Category, &item.Link,
+			&item.Description, &createdAt, &item.Submitor); err != nil {
+			return nil, fmt.Errorf("failed to scan pending project: %w", err)
+		}
+		item.ResourceType = model.ResourceTypeProject
+		item.SubmitDate = formatTime(createdAt)
+		items = append(items, item)
+		keys = append(keys, pendingProjectSort.key(sortValues{createdAt: createdAt}, item.ResourceID))
+		ids = append(ids, item.ResourceID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	techs, err := loadStrings(ctx, r.db, `SELECT project_id, tech FROM project_tech_stack WHERE project_id IN (%s) ORDER BY id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Tags = nonNil(techs[items[i].ResourceID])
+	}
+
+	result := pagination.NewResult(items, keys, normalizeLimit(page.Limit))
+	if result.Total, err = pageTotal(ctx, r.db, "projects p", where, args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func scanProject(scanner interface{ Scan(...interface{}) error }) (*projectRow, error) {
+	var row projectRow
+	err := scanner.Scan(
+		&row.ProjectID,
+		&row.Name,
+		&row.Description,
+		&row.Detail,
+		&row.GithubURL,
+		&row.Category,
+		&row.Cover,
+		&row.Views,
+		&row.Likes,
+		&row.Collections,
+		&row.createdAt,
+		&row.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	row.ResourceType = model.ResourceTypeProject
+	row.CreatedAt = formatTime(row.createdAt)
+	row.Comments = []model.Comment{}
+	return &row, nil
+}
+
+// summary 将项目行转换为列表项
+func (row projectRow) summary() model.Project {
+	return model.Project{
+		ProjectID:    row.ProjectID,
+		ResourceType: row.ResourceType,
+		Name:         row.Name,
+		Description:  row.Description,
+		Category:     row.Category,
+		TechStack:    row.TechStack,
+		LikeCount:    row.Likes,
+		AuthorName:   row.Author,
+		Cover:        row.Cover,
+		CreatedAt:    row.CreatedAt,
+		Loves:        row.Likes,
+		Collections:  row.Collections,
+		Views:        row.Views,
+	}
+}
+
+// pageProjects 按筛选条件和排序查询一页项目列表项
+func (r *projectRepository) pageProjects(ctx context.Context, q listQuery, sort keyset, page pagination.Page) (*pagination.Result[model.Project], error) {
+	query, queryArgs, err := pageQuery(r.db.Dialect, q.selectFrom(), q.where, q.args, sort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	var list []projectRow
+	var keys []pagination.Key
+	var relevance float64
+	for rows.Next() {
+		row, err := scanProject(q.scanner(rows, &relevance))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		values := row.sortValues()
+		values.relevance = int64(relevance)
+		list = append(list, *row)
+		keys = append(keys, sort.key(values, row.ProjectID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewResult(list, keys, normalizeLimit(page.Limit))
+	if err := r.loadRelations(ctx, result.Items); err != nil {
+		return nil, err
+	}
+	projects := pagination.Map(result, projectRow.summary)
+	if projects.Total, err = pageTotal(ctx, r.db, q.from, q.where, q.args, page); err != nil {
+		return nil, err
+	}
+	return projects, nil
+}
+
+// loadRelations 批量加载项目的技术栈、图片和作者
+func (r *projectRepository) loadRelations(ctx context.Context, list []projectRow) error {
+	ids := make([]int, len(list))
+	for i, p := range list {
+		ids[i] = p.ProjectID
+	}
+
+	techs, err := loadStrings(ctx, r.db, `SELECT project_id, tech FROM project_tech_stack WHERE project_id IN (%s) ORDER BY id`, ids)
+	if err != nil {
+		return err
+	}
+	images, err := loadStrings(ctx, r.db, `SELECT project_id, image_url FROM project_images WHERE project_id IN (%s) ORDER BY sort_order, id`, ids)
+	if err != nil {
+		return err
+	}
+	authors, err := loadStrings(ctx, r.db, `
+		SELECT pa.project_id, COALESCE(u.nickname, u.username)
+		FROM project_authors pa JOIN users u ON u.id = pa.user_id
+		WHERE pa.project_id IN (%s) ORDER BY pa.id`, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range list {
+		id := list[i].ProjectID
+		list[i].TechStack = nonNil(techs[id])
+		list[i].Images = nonNil(images[id])
+		list[i].Author = nonNil(authors[id])
+	}
+	return nil
+}
+
+// replaceRelations 写入项目的技术栈和图片
+func (r *projectRepository) replaceRelations(ctx context.Context, projectID int, req model.ProjectUploadRequest) error {
+	for _, tech := range uniqueStrings(req.TechStack) {
+		if _, err := r.db.ExecContext(ctx, `INSERT INTO project_tech_stack (project_id, tech) VALUES (?, ?)`, projectID, tech); err != nil {
+			return fmt.Errorf("failed to create project tech stack: %w", err)
+		}
+	}
+	for i, image := range req.Images {
+		if _, err := r.db.ExecContext(ctx,
+			`INSERT INTO project_images (project_id, image_url, sort_order) VALUES (?, ?, ?)`, projectID, image, i,
+		); err != nil {
+			return fmt.Errorf("failed to create project image: %w", err)
+		}
+	}
+	return nil
+}
+
+func firstOrEmpty(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
 }
@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"strings"
+)
+
+// ReconcileRepository 校对冗余计数列，并找出多态表中的孤儿行
+type ReconcileRepository interface {
+	// Reconcile 在一个事务中重新统计 loves、collections、reply_total 并找出孤儿行，
+	// fix 为 true 时同时修正计数并删除孤儿行
+	Reconcile(ctx context.Context, fix bool) (*model.ReconcileReport, error)
+}
+
+// counterSource 一个冗余计数列及其来源：source 为按 r 中的行统计来源表的子查询
+type counterSource struct {
+	table    string
+	idColumn string
+	column   string
+	source   string
+	args     []interface{}
+}
+
+// orphanTables 没有外键约束的多态表，resource_type/resource_id 可能指向已不存在的资源
+var orphanTables = []string{"likes", "collections", "comments", "resource_daily_views"}
+
+func counterSources() []counterSource {
+	var sources []counterSource
+	for _, resourceType := range resourceTypes {
+		t := resourceTables[resourceType]
+		for _, c := range []struct{ column, table string }{{"loves", "likes"}, {"collections", "collections"}} {
+			sources = append(sources, counterSource{
+				table:    t.table,
+				idColumn: t.idColumn,
+				column:   c.column,
+				source:   fmt.Sprintf("SELECT COUNT(*) FROM %s s WHERE s.resource_type = ? AND s.resource_id = r.%s", c.table, t.idColumn),
+				args:     []interface{}{resourceType},
+			})
+		}
+	}
+	// 回复软删除后不再计入父评论的 reply_total
+	return append(sources, counterSource{
+		table:    "comments",
+		idColumn: "comment_id",
+		column:   "reply_total",
+		source:   "SELECT COUNT(*) FROM comments s WHERE s.parent_id = r.comment_id AND s.deleted_at IS NULL",
+	})
+}
+
+type reconcileRepository struct {
+	db *Database
+}
+
+func NewReconcileRepository(db *Database) ReconcileRepository {
+	return &reconcileRepository{db: db}
+}
+
+func (r *reconcileRepository) Reconcile(ctx context.Context, fix bool) (*model.ReconcileReport, error) {
+	report := &model.ReconcileReport{Drifts: []model.CounterDrift{}, Orphans: []model.OrphanRows{}, Fixed: fix}
+	err := r.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, c := range counterSources() {
+			drifts, err := r.drifts(ctx, c)
+			if err != nil {
+				return err
+			}
+			if fix {
+				if err := r.fixDrifts(ctx, c, drifts); err != nil {
+					return err
+				}
+			}
+			report.Drifts = append(report.Drifts, drifts...)
+		}
+
+		for _, table := range orphanTables {
+			orphans, err := r.orphans(ctx, table)
+			if err != nil {
+				return err
+			}
+			if fix {
+				if err := r.deleteOrphans(ctx, orphans); err != nil {
+					return err
+				}
+			}
+			report.Orphans = append(report.Orphans, orphans...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (r *reconcileRepository) drifts(ctx context.Context, c counterSource) ([]model.CounterDrift, error) {
+	query := fmt.Sprintf(
+		`SELECT r.%[1]s, COALESCE(r.%[2]s, 0), (%[3]s) FROM %[4]s r
+		WHERE COALESCE(r.%[2]s, 0) <> (%[3]s) ORDER BY r.%[1]s`,
+		c.idColumn, c.column, c.source, c.table,
+	)
+	args := append(append([]interface{}{}, c.args...), c.args...)
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check %s.%s: %w", c.table, c.column, err)
+	}
+	defer rows.Close()
+
+	var drifts []model.CounterDrift
+	for rows.Next() {
+		drift := model.CounterDrift{Table: c.table, Column: c.column}
+		if err := rows.Scan(&drift.ID, &drift.Stored, &drift.Actual); err != nil {
+			return nil, fmt.Errorf("failed to scan counter drift: %w", err)
+		}
+		drifts = append(drifts, drift)
+	}
+	return drifts, rows.Err()
+}
+
+// fixDrifts 按差值而不是绝对值修正，校对期间并发的点赞、收藏对计数的增减不会被覆盖
+func (r *reconcileRepository) fixDrifts(ctx context.Context, c counterSource, drifts []model.CounterDrift) error {
+	query := fmt.Sprintf("UPDATE %[1]s SET %[2]s = COALESCE(%[2]s, 0) + ? WHERE %[3]s = ?", c.table, c.column, c.idColumn)
+	for _, d := range drifts {
+		if _, err := r.db.ExecContext(ctx, query, d.Actual-d.Stored, d.ID); err != nil {
+			return fmt.Errorf("failed to fix %s.%s: %w", c.table, c.column, err)
+		}
+	}
+	return nil
+}
+
+// missingResource 资源表中不存在 s 行所指资源的条件，资源类型未知的行同样视为孤儿；
+// 回收站中的资源仍然存在，其关联行保留到永久删除
+func missingResource() string {
+	conditions := make([]string, 0, len(resourceTypes))
+	for _, resourceType := range resourceTypes {
+		t := resourceTables[resourceType]
+		conditions = append(conditions, fmt.Sprintf(
+			"NOT EXISTS (SELECT 1 FROM %s r WHERE s.resource_type = '%s' AND r.%s = s.resource_id)",
+			t.table, resourceType, t.idColumn,
+		))
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+func (r *reconcileRepository) orphans(ctx context.Context, table string) ([]model.OrphanRows, error) {
+	query := fmt.Sprintf(
+		`SELECT s.resource_type, s.resource_id, COUNT(*) FROM %s s WHERE %s
+		GROUP BY s.resource_type, s.resource_id ORDER BY s.resource_type, s.resource_id`,
+		table, missingResource(),
+	)
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find orphaned %s: %w", table, err)
+	}
+	defer rows.Close()
+
+	var orphans []model.OrphanRows
+	for rows.Next() {
+		orphan := model.OrphanRows{Table: table}
+		if err := rows.Scan(&orphan.ResourceType, &orphan.ResourceID, &orphan.Rows); err != nil {
+			return nil, fmt.Errorf("failed to scan orphaned rows: %w", err)
+		}
+		orphans = append(orphans, orphan)
+	}
+	return orphans, rows.Err()
+}
+
+// deleteOrphans 删除孤儿行；删除评论时其回复和评论点赞由外键级联删除
+func (r *reconcileRepository) deleteOrphans(ctx context.Context, orphans []model.OrphanRows) error {
+	for _, o := range orphans {
+		query := fmt.Sprintf("DELETE FROM %s WHERE resource_type = ? AND resource_id = ?", o.Table)
+		if _, err := r.db.ExecContext(ctx, query, o.ResourceType, o.ResourceID); err != nil {
+			return fmt.Errorf("failed to delete orphaned %s: %w", o.Table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package repository_test
+
+import (
+	"context"
+	"os"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/repository/repotest"
+	"strings"
+	"testing"
+)
+
+// sqliteMemoryDSN 每次打开都是一个全新的空库（连接池只保留一个连接）
+const sqliteMemoryDSN = "file::memory:?_pragma=foreign_keys(1)&_time_format=sqlite"
+
+// mysqlDSNEnv 设置后契约用例还会在该 MySQL 库上执行一遍，如
+// softeng_test:123456@tcp(127.0.0.1:3306)/softeng_test?parseTime=true&loc=Local&charset=utf8mb4。
+// 每个用例之前清空库中的所有表，只能指向专用的测试库
+const mysqlDSNEnv = "REPOTEST_MYSQL_DSN"
+
+// TestContracts 分别对内存实现和 SQLite 内存库执行全部契约用例，不需要外部数据库；
+// 设置了 REPOTEST_MYSQL_DSN 时再对 MySQL 执行一遍
+func TestContracts(t *testing.T) {
+	type contractBackend struct {
+		name       string
+		newHarness func(t *testing.T) repotest.Harness
+	}
+	backends := []contractBackend{
+		{"memory", func(t *testing.T) repotest.Harness {
+			return repotest.NewMemoryHarness()
+		}},
+		{"sqlite", func(t *testing.T) repotest.Harness {
+			db, err := repository.NewDatabase("sqlite", sqliteMemoryDSN, repository.DefaultPoolConfig)
+			if err != nil {
+				t.Fatalf("open sqlite: %v", err)
+			}
+			t.Cleanup(func() { db.Close() })
+			return repotest.NewSQLHarness(db)
+		}},
+	}
+	if dsn := os.Getenv(mysqlDSNEnv); dsn != "" {
+		db := openMySQL(t, dsn)
+		backends = append(backends, contractBackend{"mysql", func(t *testing.T) repotest.Harness {
+			truncateMySQL(t, db)
+			return repotest.NewSQLHarness(db)
+		}})
+	}
+
+	for _, backend := range backends {
+		backend := backend
+		t.Run(backend.name, func(t *testing.T) {
+			for _, c := range repotest.Cases {
+				c := c
+				t.Run(c.Name, func(t *testing.T) {
+					c.Run(t, backend.newHarness(t))
+				})
+			}
+		})
+	}
+}
+
+// openMySQL 连接测试库并执行 schema.sql 建表，去掉其中创建和切换到 softeng 库的语句
+func openMySQL(t *testing.T, dsn string) *repository.Database {
+	t.Helper()
+	db, err := repository.NewDatabase("mysql", dsn, repository.DefaultPoolConfig)
+	if err != nil {
+		t.Fatalf("open mysql: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	schema, err := os.ReadFile("../../database/schema.sql")
+	if err != nil {
+		t.Fatalf("read schema.sql: %v", err)
+	}
+	for _, stmt := range strings.Split(string(schema), ";\n") {
+		var lines []string
+		for _, line := range strings.Split(stmt, "\n") {
+			if trimmed := strings.TrimSpace(line); trimmed != "" && !strings.HasPrefix(trimmed, "--") {
+				lines = append(lines, line)
+			}
+		}
+		stmt = strings.TrimSuffix(strings.TrimSpace(strings.Join(lines, "\n")), ";")
+		if stmt == "" || strings.HasPrefix(stmt, "CREATE DATABASE") || strings.HasPrefix(stmt, "USE ") {
+			continue
+		}
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("apply schema.sql: %v\n%s", err, stmt)
+		}
+	}
+	return db
+}
+
+// truncateMySQL 清空库中的所有表，自增ID从 1 重新开始，与新建的库一致
+func truncateMySQL(t *testing.T, db *repository.Database) {
+	t.Helper()
+	ctx := context.Background()
+	// FOREIGN_KEY_CHECKS 是会话变量，需在同一个连接上执行
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		t.Fatalf("mysql conn: %v", err)
+	}
+	defer conn.Close()
+
+	rows, err := conn.QueryContext(ctx, `SELECT table_name FROM information_schema.tables WHERE table_schema = DATABASE() AND table_type = 'BASE TABLE'`)
+	if err != nil {
+		t.Fatalf("list mysql tables: %v", err)
+	}
+	var tables []string
+	for rows.Next() {
+		var table string
+		if err := rows.Scan(&table); err != nil {
+			rows.Close()
+			t.Fatalf("scan mysql table: %v", err)
+		}
+		tables = append(tables, table)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		t.Fatalf("list mysql tables: %v", err)
+	}
+
+	if _, err := conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 0"); err != nil {
+		t.Fatalf("disable foreign key checks: %v", err)
+	}
+	defer conn.ExecContext(ctx, "SET FOREIGN_KEY_CHECKS = 1")
+	for _, table := range tables {
+		if _, err := conn.ExecContext(ctx, "TRUNCATE TABLE `"+table+"`"); err != nil {
+			t.Fatalf("truncate %s: %v", table, err)
+		}
+	}
+}
@@ -0,0 +1,2347 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"softeng-platform/internal/mail"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/service"
+	"softeng-platform/internal/tagquery"
+	"strings"
+	"time"
+)
+
+// Cases 全部契约用例
+var Cases = []Case{
+	{"用户名和邮箱唯一", testUserUnique},
+	{"项目名称唯一", testProjectNameUnique},
+	{"工具列表分页", testToolPagination},
+	{"列表只包含已审核资源", testApprovedOnly},
+	{"标签查询表达式", testTagQuery},
+	{"点赞和收藏幂等", testToggleRelations},
+	{"评论与回复", testComments},
+	{"评论分页", testCommentPagination},
+	{"删除用户级联清理", testUserCascade},
+	{"课程资源上传与审核", testCourseResources},
+	{"全文检索", testSearch},
+	{"中文检索与相关度排序", testSearchRanking},
+	{"统一检索与分面", testUnifiedSearch},
+	{"嵌入式检索索引", testSearchIndex},
+	{"计数变化同步检索索引", testSearchIndexCounters},
+	{"项目修改需作者并重新审核", testProjectUpdate},
+	{"事务回滚", testTxRollback},
+	{"回收站", testTrash},
+	{"乐观锁版本", testVersion},
+	{"浏览量批量写入", testViews},
+	{"计数校对与孤儿行", testReconcile},
+	{"保存的检索与上架时间窗口", testSavedSearches},
+	{"站内通知", testNotifications},
+	{"审核状态机", testReview},
+	{"评论举报与审核队列", testCommentReports},
+	{"敏感词表", testSensitiveWords},
+	{"审计日志", testAuditLog},
+	{"审核认领与轮流分配", testReviewClaims},
+	{"批量审核", testBulkReview},
+	{"认领与分配记入审计日志", testClaimAudit},
+}
+
+// ==================== 数据准备 ====================
+
+func mustUser(t T, h Harness, name string) *model.User {
+	t.Helper()
+	user := &model.User{
+		Username: name,
+		Nickname: name + "_nick",
+		Email:    name + "@example.com",
+		Password: "hashed",
+		Role:     "user",
+	}
+	if err := h.Users.Create(context.Background(), user); err != nil {
+		t.Fatalf("create user %s: %v", name, err)
+	}
+	return user
+}
+
+func mustTool(t T, h Harness, userID int, name string, approve bool) int {
+	t.Helper()
+	ctx := context.Background()
+	review, err := h.Tools.Create(ctx, userID, model.ToolSubmitRequest{
+		Name:              name,
+		Link:              "https://example.com/" + name,
+		Description:       name + " description",
+		DescriptionDetail: name + " detail",
+		Category:          "IDE",
+		Tags:              []string{"go", "editor"},
+	})
+	if err != nil {
+		t.Fatalf("create tool %s: %v", name, err)
+	}
+	if approve {
+		mustStatus(t, h, model.ResourceTypeTool, review.ResourceID, model.StatusApproved)
+	}
+	return review.ResourceID
+}
+
+func mustProject(t T, h Harness, userID int, name string) int {
+	t.Helper()
+	review, err := h.Projects.Create(context.Background(), userID, projectRequest(name))
+	if err != nil {
+		t.Fatalf("create project %s: %v", name, err)
+	}
+	return review.ResourceID
+}
+
+func projectRequest(name string) model.ProjectUploadRequest {
+	return model.ProjectUploadRequest{
+		Name:        name,
+		Description: name + " description",
+		Detail:      name + " detail",
+		Github:      "https://github.com/example/" + name,
+		Category:    "web",
+		TechStack:   []string{"Go", "Vue"},
+		Images:      []string{"cover.png", "second.png"},
+	}
+}
+
+func mustStatus(t T, h Harness, resourceType string, resourceID int, status string) {
+	t.Helper()
+	if err := h.Fixtures.SetStatus(context.Background(), resourceType, resourceID, status); err != nil {
+		t.Fatalf("set %s %d status: %v", resourceType, resourceID, err)
+	}
+}
+
+// firstPage 不带游标的一页
+func firstPage(limit int) pagination.Page {
+	return pagination.Page{Limit: limit}
+}
+
+func toolIDs(tools []model.Tool) []int {
+	ids := []int{}
+	for _, tool := range tools {
+		ids = append(ids, tool.ResourceID)
+	}
+	return ids
+}
+
+// ==================== 用例 ====================
+
+func testUserUnique(t T, h Harness) {
+	ctx := context.Background()
+	first := mustUser(t, h, "alice")
+
+	if err := h.Users.Create(ctx, &model.User{Username: "alice", Email: "other@example.com", Password: "x"}); err == nil {
+		t.Errorf("duplicate username: expected error")
+	}
+	if err := h.Users.Create(ctx, &model.User{Username: "alice2", Email: "alice@example.com", Password: "x"}); err == nil {
+		t.Errorf("duplicate email: expected error")
+	}
+
+	found, err := h.Users.GetByUsername(ctx, "alice")
+	if err != nil || found == nil || found.ID != first.ID {
+		t.Errorf("GetByUsername: got %+v, %v", found, err)
+	}
+	if missing, err := h.Users.GetByEmail(ctx, "nobody@example.com"); err != nil || missing != nil {
+		t.Errorf("GetByEmail missing: got %+v, %v; want nil, nil", missing, err)
+	}
+}
+
+func testProjectNameUnique(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "bob")
+	first := mustProject(t, h, user.ID, "blog")
+	second := mustProject(t, h, user.ID, "wiki")
+
+	if _, err := h.Projects.Create(ctx, user.ID, projectRequest("blog")); err == nil {
+		t.Errorf("duplicate project name: expected error")
+	}
+	if _, err := h.Projects.Update(ctx, user.ID, second, 0, projectRequest("blog")); err == nil {
+		t.Errorf("rename to existing project name: expected error")
+	}
+	if _, err := h.Projects.Update(ctx, user.ID, first, 0, projectRequest("blog")); err != nil {
+		t.Errorf("update keeping own name: %v", err)
+	}
+}
+
+func testToolPagination(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "carol")
+	var created []int
+	for i := 0; i < 5; i++ {
+		created = append(created, mustTool(t, h, user.ID, fmt.Sprintf("tool%d", i), true))
+	}
+
+	var seen []int
+	page := pagination.Page{Limit: 2, WithTotal: true}
+	for i := 0; ; i++ {
+		result, err := h.Tools.GetTools(ctx, nil, nil, "latest", page)
+		if err != nil {
+			t.Fatalf("GetTools page %d: %v", i+1, err)
+		}
+		if result.Total == nil || *result.Total != len(created) {
+			t.Errorf("GetTools page %d total: got %v, want %d", i+1, result.Total, len(created))
+		}
+		seen = append(seen, toolIDs(result.Items)...)
+		if !result.HasMore {
+			if result.Next != nil {
+				t.Errorf("last page has next cursor: %+v", result.Next)
+			}
+			break
+		}
+		if i > len(created) {
+			t.Fatalf("pagination does not terminate: seen %v", seen)
+		}
+		page.After = result.Next
+	}
+
+	if len(seen) != len(created) {
+		t.Fatalf("paged tools: got %v, want %d items", seen, len(created))
+	}
+	for i, id := range seen {
+		// 创建时间可能相同，此时按ID倒序
+		if want := created[len(created)-1-i]; id != want {
+			t.Errorf("page order: got %v, want newest first", seen)
+			break
+		}
+	}
+
+	if _, err := h.Tools.LikeTool(ctx, user.ID, created[0]); err != nil {
+		t.Fatalf("LikeTool: %v", err)
+	}
+	top, err := h.Tools.GetTools(ctx, nil, nil, "loves", firstPage(1))
+	if err != nil || len(top.Items) != 1 || top.Items[0].ResourceID != created[0] || !top.HasMore {
+		t.Fatalf("sort by loves: got %+v, %v", top, err)
+	}
+	// 游标只能用于生成它的排序
+	if _, err := h.Tools.GetTools(ctx, nil, nil, "latest", pagination.Page{After: top.Next, Limit: 1}); !errors.Is(err, pagination.ErrInvalidCursor) {
+		t.Errorf("cursor of another sort: got %v, want %v", err, pagination.ErrInvalidCursor)
+	}
+}
+
+func testApprovedOnly(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "dave")
+	approved := mustTool(t, h, user.ID, "approved", true)
+	pending := mustTool(t, h, user.ID, "pending", false)
+
+	tools, err := h.Tools.GetTools(ctx, nil, nil, "", firstPage(10))
+	if err != nil {
+		t.Fatalf("GetTools: %v", err)
+	}
+	if ids := toolIDs(tools.Items); len(ids) != 1 || ids[0] != approved || tools.HasMore {
+		t.Errorf("GetTools: got %v, want [%d]", ids, approved)
+	}
+	if tool, err := h.Tools.GetByID(ctx, pending); err != nil || tool != nil {
+		t.Errorf("GetByID pending: got %+v, %v; want nil, nil", tool, err)
+	}
+
+	submits, err := h.Tools.GetPending(ctx, model.PendingFilter{}, firstPage(10))
+	if err != nil {
+		t.Fatalf("GetPending: %v", err)
+	}
+	if len(submits.Items) != 1 || submits.Items[0].ResourceID != pending || submits.Items[0].Submitor != "dave_nick" {
+		t.Errorf("GetPending: got %+v", submits.Items)
+	}
+}
+
+// testTagQuery 标签、技术栈查询表达式在数据库和内存实现中的结果一致
+func testTagQuery(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "tagger")
+
+	tools := map[string]int{}
+	for name, tags := range map[string][]string{
+		"free-ai": {"AI", "免费"},
+		"open-ai": {"AI", "开源"},
+		"paid-ai": {"AI", "免费", "付费"},
+		"free":    {"免费"},
+	} {
+		review, err := h.Tools.Create(ctx, user.ID, model.ToolSubmitRequest{Name: name, Link: "https://example.com/" + name, Category: "AI", Tags: tags})
+		if err != nil {
+			t.Fatalf("create tool %s: %v", name, err)
+		}
+		mustStatus(t, h, model.ResourceTypeTool, review.ResourceID, model.StatusApproved)
+		tools[name] = review.ResourceID
+	}
+
+	for query, want := range map[string][]string{
+		"AI AND (免费 OR 开源) -付费": {"free-ai", "open-ai"},
+		"AI 免费":                 {"free-ai", "paid-ai"},
+		"开源 OR NOT AI":          {"free", "open-ai"},
+		"-(AI OR 付费)":           {"free"},
+		"付费":                    {"paid-ai"},
+	} {
+		expr, err := tagquery.Parse(query)
+		if err != nil {
+			t.Fatalf("Parse %q: %v", query, err)
+		}
+		result, err := h.Tools.GetTools(ctx, nil, expr, "", firstPage(10))
+		if err != nil {
+			t.Fatalf("GetTools %q: %v", query, err)
+		}
+		wantIDs := map[int]bool{}
+		for _, name := range want {
+			wantIDs[tools[name]] = true
+		}
+		got := toolIDs(result.Items)
+		ok := len(got) == len(want)
+		for _, id := range got {
+			ok = ok && wantIDs[id]
+		}
+		if !ok {
+			t.Errorf("GetTools %q: got %v, want %v", query, got, want)
+		}
+	}
+
+	goReact, err := h.Projects.Create(ctx, user.ID, model.ProjectUploadRequest{Name: "go-react", Category: "web", TechStack: []string{"Go", "React"}})
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+	mustStatus(t, h, model.ResourceTypeProject, goReact.ResourceID, model.StatusApproved)
+	goVue := mustProject(t, h, user.ID, "go-vue")
+	mustStatus(t, h, model.ResourceTypeProject, goVue, model.StatusApproved)
+
+	expr, _ := tagquery.Parse("Go AND React")
+	if projects, err := h.Projects.GetProjects(ctx, "", expr, "", firstPage(10)); err != nil || len(projects.Items) != 1 || projects.Items[0].ProjectID != goReact.ResourceID {
+		t.Errorf("GetProjects Go AND React: got %+v, %v", projects, err)
+	}
+	if projects, err := h.Projects.GetProjects(ctx, "", tagquery.AnyOf([]string{"React", "Vue"}), "", firstPage(10)); err != nil || len(projects.Items) != 2 {
+		t.Errorf("GetProjects React OR Vue: got %+v, %v", projects, err)
+	}
+}
+
+func testToggleRelations(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "erin")
+	tool := mustTool(t, h, user.ID, "liked", true)
+
+	for i := 0; i < 2; i++ {
+		status, err := h.Tools.LikeTool(ctx, user.ID, tool)
+		if err != nil || !status.IsLiked || status.Likes != 1 {
+			t.Errorf("LikeTool #%d: got %+v, %v", i+1, status, err)
+		}
+	}
+	status, err := h.Tools.UnlikeTool(ctx, user.ID, tool)
+	if err != nil || status.IsLiked || status.Likes != 0 {
+		t.Errorf("UnlikeTool: got %+v, %v", status, err)
+	}
+	if status, err := h.Tools.UnlikeTool(ctx, user.ID, tool); err != nil || status.Likes != 0 {
+		t.Errorf("UnlikeTool again: got %+v, %v", status, err)
+	}
+	if _, err := h.Tools.LikeTool(ctx, user.ID, 9999); err == nil {
+		t.Errorf("LikeTool missing tool: expected error")
+	}
+
+	if _, err := h.Tools.CollectTool(ctx, user.ID, tool); err != nil {
+		t.Fatalf("CollectTool: %v", err)
+	}
+	project := mustProject(t, h, user.ID, "starred")
+	if _, err := h.Projects.CollectProject(ctx, user.ID, project); err != nil {
+		t.Fatalf("CollectProject: %v", err)
+	}
+	// 收藏按收藏时间倒序分页，两类资源共用一个游标
+	collection, info, err := h.Users.GetCollection(ctx, user.ID, pagination.Page{Limit: 1, WithTotal: true})
+	if err != nil {
+		t.Fatalf("GetCollection: %v", err)
+	}
+	if len(collection.Resources) != 1 || len(collection.Tools) != 0 || !info.HasMore || info.Total == nil || *info.Total != 2 {
+		t.Errorf("GetCollection first page: got %+v, %+v", collection, info)
+	}
+	collection, info, err = h.Users.GetCollection(ctx, user.ID, pagination.Page{After: info.Next, Limit: 1})
+	if err != nil {
+		t.Fatalf("GetCollection: %v", err)
+	}
+	if len(collection.Tools) != 1 || collection.Tools[0].ResourceID != tool || len(collection.Resources) != 0 || info.HasMore {
+		t.Errorf("GetCollection second page: got %+v, %+v", collection, info)
+	}
+
+	if err := h.Users.DeleteCollection(ctx, user.ID, model.ResourceTypeTool, tool); err != nil {
+		t.Errorf("DeleteCollection: %v", err)
+	}
+	if err := h.Users.DeleteCollection(ctx, user.ID, model.ResourceTypeTool, tool); err == nil {
+		t.Errorf("DeleteCollection twice: expected error")
+	}
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || detail.Collections != 0 {
+		t.Errorf("collections after delete: got %+v, %v", detail, err)
+	}
+}
+
+func testComments(t T, h Harness) {
+	ctx := context.Background()
+	author := mustUser(t, h, "frank")
+	other := mustUser(t, h, "grace")
+	tool := mustTool(t, h, author.ID, "discussed", true)
+
+	comment, err := h.Tools.AddComment(ctx, author.ID, tool, "first")
+	if err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	reply, err := h.Tools.ReplyComment(ctx, other.ID, tool, comment.CommentID, "reply")
+	if err != nil {
+		t.Fatalf("ReplyComment: %v", err)
+	}
+
+	detail, err := h.Tools.GetByID(ctx, tool)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if detail.CommentCount != 2 || len(detail.Comments) != 1 || len(detail.Comments[0].Replies) != 1 ||
+		detail.Comments[0].ReplyTotal != 1 {
+		t.Errorf("comment tree: count %d, got %+v", detail.CommentCount, detail.Comments)
+	}
+
+	if _, err := h.Tools.DeleteComment(ctx, other.ID, tool, comment.CommentID); err == nil {
+		t.Errorf("delete someone else's comment: expected error")
+	}
+	if _, err := h.Tools.DeleteComment(ctx, other.ID, tool, reply.CommentID); err == nil {
+		t.Errorf("DeleteComment on a reply: expected error")
+	}
+	if _, err := h.Tools.DeleteReply(ctx, other.ID, tool, reply.CommentID); err != nil {
+		t.Errorf("DeleteReply: %v", err)
+	}
+
+	detail, err = h.Tools.GetByID(ctx, tool)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if detail.CommentCount != 1 || len(detail.Comments) != 1 || detail.Comments[0].ReplyTotal != 0 {
+		t.Errorf("after DeleteReply: count %d, got %+v", detail.CommentCount, detail.Comments)
+	}
+}
+
+func testCommentPagination(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "olga")
+	tool := mustTool(t, h, user.ID, "paged", true)
+
+	var roots []int
+	for i := 0; i < 3; i++ {
+		comment, err := h.Tools.AddComment(ctx, user.ID, tool, fmt.Sprintf("comment %d", i))
+		if err != nil {
+			t.Fatalf("AddComment: %v", err)
+		}
+		roots = append(roots, comment.CommentID)
+	}
+	if _, err := h.Tools.ReplyComment(ctx, user.ID, tool, roots[0], "reply"); err != nil {
+		t.Fatalf("ReplyComment: %v", err)
+	}
+
+	// 只对顶层评论分页，回复跟随父评论
+	first, err := h.Tools.GetComments(ctx, tool, pagination.Page{Limit: 2, WithTotal: true})
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if len(first.Items) != 2 || first.Items[0].CommentID != roots[0] || len(first.Items[0].Replies) != 1 ||
+		!first.HasMore || first.Total == nil || *first.Total != 3 {
+		t.Errorf("GetComments first page: got %+v", first)
+	}
+	second, err := h.Tools.GetComments(ctx, tool, pagination.Page{After: first.Next, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetComments: %v", err)
+	}
+	if len(second.Items) != 1 || second.Items[0].CommentID != roots[2] || second.HasMore || second.Next != nil {
+		t.Errorf("GetComments second page: got %+v", second)
+	}
+}
+
+func testUserCascade(t T, h Harness) {
+	ctx := context.Background()
+	owner := mustUser(t, h, "heidi")
+	leaving := mustUser(t, h, "ivan")
+	tool := mustTool(t, h, leaving.ID, "orphan", true)
+	other := mustTool(t, h, owner.ID, "kept", true)
+
+	if _, err := h.Tools.CollectTool(ctx, leaving.ID, other); err != nil {
+		t.Fatalf("CollectTool: %v", err)
+	}
+	if _, err := h.Tools.AddComment(ctx, leaving.ID, other, "bye"); err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	project := mustProject(t, h, leaving.ID, "abandoned")
+
+	if err := h.Users.Delete(ctx, leaving.ID); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if err := h.Users.Delete(ctx, leaving.ID); err == nil {
+		t.Errorf("Delete twice: expected error")
+	}
+
+	if user, err := h.Users.GetByID(ctx, leaving.ID); err != nil || user != nil {
+		t.Errorf("GetByID deleted: got %+v, %v", user, err)
+	}
+	detail, err := h.Tools.GetByID(ctx, other)
+	if err != nil {
+		t.Fatalf("GetByID kept tool: %v", err)
+	}
+	if detail.CommentCount != 0 || len(detail.Comments) != 0 {
+		t.Errorf("comments of deleted user remain: %+v", detail.Comments)
+	}
+
+	// 资源本身保留，贡献者和作者关系被删除
+	orphan, err := h.Tools.GetByID(ctx, tool)
+	if err != nil || orphan == nil || len(orphan.Contributors) != 0 {
+		t.Errorf("orphan tool: got %+v, %v", orphan, err)
+	}
+	submits, err := h.Projects.GetPending(ctx, model.PendingFilter{}, firstPage(10))
+	if err != nil || len(submits.Items) != 1 || submits.Items[0].ResourceID != project || submits.Items[0].Submitor != "" {
+		t.Errorf("orphan project pending: got %+v, %v", submits, err)
+	}
+	if collection, _, err := h.Users.GetCollection(ctx, leaving.ID, firstPage(10)); err != nil || len(collection.Tools) != 0 {
+		t.Errorf("collection of deleted user: got %+v, %v", collection, err)
+	}
+}
+
+func testCourseResources(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "judy")
+	course, err := h.Fixtures.CreateCourse(ctx, model.Course{
+		Name:     "Software Engineering",
+		Teacher:  []string{"张老师"},
+		Category: []string{"required"},
+		Semester: "2024-1",
+		Credit:   3,
+	})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+
+	if _, err := h.Courses.UploadResource(ctx, user.ID, course, model.CourseUploadRequest{Description: "empty"}); err == nil {
+		t.Errorf("UploadResource without resource: expected error")
+	}
+	if _, err := h.Courses.UploadResource(ctx, user.ID, 9999, model.CourseUploadRequest{Description: "x", Resource: "https://x"}); err == nil {
+		t.Errorf("UploadResource missing course: expected error")
+	}
+	review, err := h.Courses.UploadResource(ctx, user.ID, course, model.CourseUploadRequest{
+		Description: "slides",
+		Resource:    "https://example.com/slides",
+		File:        "textbook.pdf",
+	})
+	if err != nil {
+		t.Fatalf("UploadResource: %v", err)
+	}
+	if review.Resource1 == nil || review.Resource2 == nil {
+		t.Fatalf("UploadResource: got %+v", review)
+	}
+
+	// 网页资源和上传资源提交时间相同、ID可能相同，分两页也不能重复或遗漏
+	first, err := h.Courses.GetPending(ctx, model.PendingFilter{}, pagination.Page{Limit: 1, WithTotal: true})
+	if err != nil || len(first.Items) != 1 || first.Items[0].ResourceName != "Software Engineering" ||
+		!first.HasMore || first.Total == nil || *first.Total != 2 {
+		t.Fatalf("GetPending: got %+v, %v", first, err)
+	}
+	second, err := h.Courses.GetPending(ctx, model.PendingFilter{}, pagination.Page{After: first.Next, Limit: 1})
+	if err != nil || len(second.Items) != 1 || second.HasMore || second.Items[0].ResourceType == first.Items[0].ResourceType {
+		t.Errorf("GetPending second page: got %+v, %v", second, err)
+	}
+
+	textbook := review.Resource2.ResourceID
+	if _, err := h.Courses.DownloadTextbook(ctx, course, textbook); err == nil {
+		t.Errorf("download pending textbook: expected error")
+	}
+	mustStatus(t, h, model.ResourceTypeCourseUpload, textbook, model.StatusApproved)
+	if file, err := h.Courses.DownloadTextbook(ctx, course, textbook); err != nil || file != "textbook.pdf" {
+		t.Errorf("DownloadTextbook: got %q, %v", file, err)
+	}
+
+	detail, err := h.Courses.GetByID(ctx, course)
+	if err != nil || detail == nil {
+		t.Fatalf("GetByID: %+v, %v", detail, err)
+	}
+	if len(detail.UploadForm) != 1 || len(detail.URLForm) != 0 {
+		t.Errorf("approved resources: got %+v / %+v", detail.URLForm, detail.UploadForm)
+	}
+
+	courses, err := h.Courses.GetCourses(ctx, "2024-1", []string{"required"}, "", firstPage(10))
+	if err != nil || len(courses.Items) != 1 {
+		t.Errorf("GetCourses filter: got %+v, %v", courses, err)
+	}
+	if courses, err := h.Courses.GetCourses(ctx, "2023-2", nil, "", firstPage(10)); err != nil || len(courses.Items) != 0 {
+		t.Errorf("GetCourses other semester: got %+v, %v", courses, err)
+	}
+
+	// 创建时间取列默认值，逐页翻完不能重复或遗漏
+	want := map[int]bool{}
+	for i := 0; i < 3; i++ {
+		id, err := h.Fixtures.CreateCourse(ctx, model.Course{Name: fmt.Sprintf("Elective %d", i), Semester: "2024-2"})
+		if err != nil {
+			t.Fatalf("CreateCourse: %v", err)
+		}
+		want[id] = true
+	}
+	page := firstPage(1)
+	for i := 0; ; i++ {
+		result, err := h.Courses.GetCourses(ctx, "2024-2", nil, "latest", page)
+		if err != nil {
+			t.Fatalf("GetCourses page %d: %v", i+1, err)
+		}
+		for _, c := range result.Items {
+			if !want[c.CourseID] {
+				t.Errorf("GetCourses page %d: unexpected or repeated course %d", i+1, c.CourseID)
+			}
+			delete(want, c.CourseID)
+		}
+		if !result.HasMore || i > 3 {
+			break
+		}
+		page.After = result.Next
+	}
+	if len(want) != 0 {
+		t.Errorf("GetCourses pages: missing courses %v", want)
+	}
+}
+
+func testSearch(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "ken")
+	tool := mustTool(t, h, user.ID, "Visual Studio", true)
+	mustTool(t, h, user.ID, "Hidden Visual", false)
+	mustTool(t, h, user.ID, "Postman", true)
+
+	found, err := h.Tools.Search(ctx, "visual", nil, nil, "", firstPage(10))
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	if ids := toolIDs(found.Items); len(ids) != 1 || ids[0] != tool {
+		t.Errorf("Search visual: got %v, want [%d]", ids, tool)
+	}
+	if found, err := h.Tools.Search(ctx, "nothing", nil, nil, "", firstPage(10)); err != nil || len(found.Items) != 0 {
+		t.Errorf("Search no match: got %+v, %v", found, err)
+	}
+	// 用 | 分隔的候选写法命中任一即可（拼写纠错扩展出的检索词）
+	if found, err := h.Tools.Search(ctx, "vsiual|visual studio", nil, nil, "", firstPage(10)); err != nil || len(found.Items) != 1 || found.Items[0].ResourceID != tool {
+		t.Errorf("Search alternatives: got %+v, %v", found, err)
+	}
+
+	course, err := h.Fixtures.CreateCourse(ctx, model.Course{Name: "Compilers", Teacher: []string{"李明"}, Category: []string{"elective"}})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+	if courses, err := h.Courses.Search(ctx, "李", nil, "", firstPage(10)); err != nil || len(courses.Items) != 1 || courses.Items[0].CourseID != course {
+		t.Errorf("Search course by teacher: got %+v, %v", courses, err)
+	}
+	if courses, err := h.Courses.Search(ctx, "compilers", []string{"required"}, "", firstPage(10)); err != nil || len(courses.Items) != 0 {
+		t.Errorf("Search course with category filter: got %+v, %v", courses, err)
+	}
+
+	project := mustProject(t, h, user.ID, "gallery")
+	mustStatus(t, h, model.ResourceTypeProject, project, model.StatusApproved)
+	if projects, err := h.Projects.Search(ctx, "vue", nil, "", firstPage(10)); err != nil || len(projects.Items) != 1 {
+		t.Errorf("Search project by tech: got %+v, %v", projects, err)
+	}
+}
+
+func testSearchRanking(t T, h Harness) {
+	ctx := context.Background()
+	user := mustUser(t, h, "kate")
+	submit := func(req model.ToolSubmitRequest) int {
+		t.Helper()
+		req.Link = "https://example.com/" + req.Name
+		review, err := h.Tools.Create(ctx, user.ID, req)
+		if err != nil {
+			t.Fatalf("create tool %s: %v", req.Name, err)
+		}
+		mustStatus(t, h, model.ResourceTypeTool, review.ResourceID, model.StatusApproved)
+		return review.ResourceID
+	}
+	// 名称、简介和标签分别命中
+	byName := submit(model.ToolSubmitRequest{Name: "软件工程工具箱", Description: "常用工具合集", Category: "IDE"})
+	byText := submit(model.ToolSubmitRequest{Name: "Toolkit", Description: "面向软件工程课程的效率工具", Category: "效率"})
+	byTag := submit(model.ToolSubmitRequest{Name: "Board", Description: "看板", Category: "IDE", Tags: []string{"软件工程"}})
+	mustTool(t, h, user.ID, "Unrelated", true)
+
+	for _, keyword := range []string{"软件工程", "工程"} {
+		found, err := h.Tools.Search(ctx, keyword, nil, nil, "", firstPage(10))
+		if err != nil {
+			t.Fatalf("Search %s: %v", keyword, err)
+		}
+		ids := toolIDs(found.Items)
+		if len(ids) != 3 || ids[0] != byName {
+			t.Errorf("Search %s: got %v, want %d first among [%d %d %d]", keyword, ids, byName, byName, byText, byTag)
+		}
+	}
+
+	// 每个检索词都必须命中
+	if found, err := h.Tools.Search(ctx, "软件 工具箱", nil, nil, "", firstPage(10)); err != nil || len(found.Items) != 1 || found.Items[0].ResourceID != byName {
+		t.Errorf("Search all terms: got %+v, %v", found, err)
+	}
+	if found, err := h.Tools.Search(ctx, "工程", []string{"效率"}, nil, "", firstPage(10)); err != nil || len(found.Items) != 1 || found.Items[0].ResourceID != byText {
+		t.Errorf("Search with category filter: got %+v, %v", found, err)
+	}
+	if found, err := h.Tools.Search(ctx, "工程", nil, tagquery.Tag{Name: "软件工程"}, "", firstPage(10)); err != nil || len(found.Items) != 1 || found.Items[0].ResourceID != byTag {
+		t.Errorf("Search with tag filter: got %+v, %v", found, err)
+	}
+	// LIKE 中的通配符按字面匹配
+	if found, err := h.Tools.Search(ctx, "%", nil, nil, "", firstPage(10)); err != nil || len(found.Items) != 0 {
+		t.Errorf("Search wildcard: got %+v, %v", found, err)
+	}
+
+	// 按相关度逐页翻完不能重复或遗漏
+	want := map[int]bool{byName: true, byText: true, byTag: true}
+	page := firstPage(1)
+	for i := 0; ; i++ {
+		result, err := h.Tools.Search(ctx, "工程", nil, nil, "", page)
+		if err != nil {
+			t.Fatalf("Search page %d: %v", i+1, err)
+		}
+		for _, tool := range result.Items {
+			if !want[tool.ResourceID] {
+				t.Errorf("Search page %d: unexpected or repeated tool %d", i+1, tool.ResourceID)
+			}
+			delete(want, tool.ResourceID)
+		}
+		if !result.HasMore || i > 3 {
+			break
+		}
+		page.After = result.Next
+	}
+	if len(want) != 0 {
+		t.Errorf("Search pages: missing tools %v", want)
+	}
+}
+
+// searchFixtures 统一检索用例的数据：各类资源各一个名称含 gopher 的已审核资源和一个未审核工具
+type searchFixtures struct {
+	tool, hidden, course, project int
+}
+
+func mustSearchFixtures(t T, h Harness) searchFixtures {
+	t.Helper()
+	ctx := context.Background()
+	user := mustUser(t, h, "lily")
+	tool := mustTool(t, h, user.ID, "gopher-kit", true)
+	hidden := mustTool(t, h, user.ID, "gopher-hidden", false)
+	project := mustProject(t, h, user.ID, "gopher-web")
+	mustStatus(t, h, model.ResourceTypeProject, project, model.StatusApproved)
+	course, err := h.Fixtures.CreateCourse(ctx, model.Course{
+		Name: "Gopher 101", Semester: "2024-1", Teacher: []string{"王老师"}, Category: []string{"elective"},
+	})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+	return searchFixtures{tool: tool, hidden: hidden, course: course, project: project}
+}
+
+func testUnifiedSearch(t T, h Harness) {
+	checkUnifiedSearch(t, h.Search, mustSearchFixtures(t, h))
+}
+
+// checkUnifiedSearch 数据库实现和嵌入式索引共用的检索断言
+func checkUnifiedSearch(t T, search repository.SearchRepository, f searchFixtures) {
+	t.Helper()
+	ctx := context.Background()
+	tool, course, project := f.tool, f.course, f.project
+	hits, err := search.Search(ctx, model.SearchQuery{Keyword: "gopher"}, firstPage(10))
+	if err != nil {
+		t.Fatalf("Search: %v", err)
+	}
+	got := map[string]int{}
+	for _, hit := range hits.Items {
+		got[hit.ResourceType] = hit.ResourceID
+	}
+	want := map[string]int{model.ResourceTypeTool: tool, model.ResourceTypeProject: project, model.ResourceTypeCourse: course}
+	if len(hits.Items) != 3 || fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Search gopher: got %+v, want %v", hits.Items, want)
+	}
+	for _, hit := range hits.Items {
+		if hit.ResourceType == model.ResourceTypeTool && fmt.Sprint(hit.Tags) != "[go editor]" {
+			t.Errorf("tool hit tags: got %v", hit.Tags)
+		}
+		if hit.ResourceType == model.ResourceTypeCourse && (fmt.Sprint(hit.Category) != "[elective]" || hit.Semester != "2024-1") {
+			t.Errorf("course hit: got %+v", hit)
+		}
+	}
+
+	if hits, err := search.Search(ctx, model.SearchQuery{Keyword: "gohper|gopher"}, firstPage(10)); err != nil || len(hits.Items) != 3 {
+		t.Errorf("Search alternatives: got %+v, %v", hits, err)
+	}
+
+	facets, err := search.Facets(ctx, model.SearchQuery{Keyword: "gopher"})
+	if err != nil {
+		t.Fatalf("Facets: %v", err)
+	}
+	for name, check := range map[string][]model.FacetCount{
+		"type:tool=1 course=1 project=1":  facets.Type,
+		"category:IDE=1 elective=1 web=1": facets.Category,
+		"tag:editor=1 go=1":               facets.Tag,
+		"techStack:Go=1 Vue=1":            facets.TechStack,
+		"semester:2024-1=1":               facets.Semester,
+	} {
+		if !facetsEqual(name, check) {
+			t.Errorf("facet %s: got %+v", name, check)
+		}
+	}
+
+	// 按标签筛选只剩工具；标签分面忽略自身的筛选条件
+	q := model.SearchQuery{Keyword: "gopher", Tags: []string{"go"}}
+	if hits, err := search.Search(ctx, q, firstPage(10)); err != nil || len(hits.Items) != 1 || hits.Items[0].ResourceID != tool {
+		t.Errorf("Search with tag filter: got %+v, %v", hits, err)
+	}
+	if facets, err := search.Facets(ctx, q); err != nil || !facetsEqual("type:tool=1", facets.Type) || !facetsEqual("tag:editor=1 go=1", facets.Tag) {
+		t.Errorf("Facets with tag filter: got %+v, %v", facets, err)
+	}
+
+	// 按类型筛选时类型分面仍统计全部类型
+	q = model.SearchQuery{Keyword: "gopher", Types: []string{model.ResourceTypeCourse, model.ResourceTypeProject}}
+	if hits, err := search.Search(ctx, q, firstPage(10)); err != nil || len(hits.Items) != 2 {
+		t.Errorf("Search with type filter: got %+v, %v", hits, err)
+	}
+	if facets, err := search.Facets(ctx, q); err != nil || !facetsEqual("type:tool=1 course=1 project=1", facets.Type) {
+		t.Errorf("Facets with type filter: got %+v, %v", facets, err)
+	}
+
+	// 三类资源的ID可能相同，逐页翻完不能重复或遗漏
+	for _, sort := range []string{"", "latest", "views"} {
+		seen := map[string]bool{}
+		page := firstPage(1)
+		for i := 0; ; i++ {
+			result, err := search.Search(ctx, model.SearchQuery{Keyword: "gopher", Sort: sort}, page)
+			if err != nil {
+				t.Fatalf("Search %q page %d: %v", sort, i+1, err)
+			}
+			for _, hit := range result.Items {
+				key := fmt.Sprintf("%s:%d", hit.ResourceType, hit.ResourceID)
+				if seen[key] {
+					t.Errorf("Search %q page %d: repeated %s", sort, i+1, key)
+				}
+				seen[key] = true
+			}
+			if !result.HasMore || i > 4 {
+				break
+			}
+			page.After = result.Next
+		}
+		if len(seen) != 3 {
+			t.Errorf("Search %q pages: got %v, want 3 hits", sort, seen)
+		}
+	}
+}
+
+// testSearchIndex 从仓库重建的嵌入式索引与数据库检索结果一致，资源状态变化后 Update 同步
+func testSearchIndex(t T, h Harness) {
+	ctx := context.Background()
+	f := mustSearchFixtures(t, h)
+
+	dir, err := os.MkdirTemp("", "repotest-index-")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	index, err := repository.OpenBleveSearchIndex(ctx, filepath.Join(dir, "index"), h.Search)
+	if err != nil {
+		t.Fatalf("OpenBleveSearchIndex: %v", err)
+	}
+	defer index.Close()
+
+	checkUnifiedSearch(t, index, f)
+
+	// 中文按字切分，部分匹配教师名也能检索到
+	if hits, err := index.Search(ctx, model.SearchQuery{Keyword: "老师"}, firstPage(10)); err != nil || len(hits.Items) != 1 || hits.Items[0].ResourceID != f.course {
+		t.Errorf("Search 老师: got %+v, %v", hits, err)
+	}
+
+	search := func(keyword string) []int {
+		hits, err := index.Search(ctx, model.SearchQuery{Keyword: keyword}, firstPage(10))
+		if err != nil {
+			t.Fatalf("Search %s: %v", keyword, err)
+		}
+		ids := []int{}
+		for _, hit := range hits.Items {
+			ids = append(ids, hit.ResourceID)
+		}
+		return ids
+	}
+
+	// 审核通过后加入索引，撤回审核后移除
+	mustStatus(t, h, model.ResourceTypeTool, f.hidden, model.StatusApproved)
+	if err := index.Update(ctx, model.ResourceTypeTool, f.hidden); err != nil {
+		t.Fatalf("Update approved: %v", err)
+	}
+	if got := search("hidden"); fmt.Sprint(got) != fmt.Sprint([]int{f.hidden}) {
+		t.Errorf("Search after approve: got %v, want [%d]", got, f.hidden)
+	}
+	mustStatus(t, h, model.ResourceTypeTool, f.tool, model.StatusPending)
+	if err := index.Update(ctx, model.ResourceTypeTool, f.tool); err != nil {
+		t.Fatalf("Update pending: %v", err)
+	}
+	if got := search("kit"); len(got) != 0 {
+		t.Errorf("Search after unapprove: got %v, want none", got)
+	}
+
+	// 不存在的资源不报错
+	if err := index.Update(ctx, model.ResourceTypeProject, f.project+1000); err != nil {
+		t.Errorf("Update missing resource: %v", err)
+	}
+}
+
+// testSearchIndexCounters 点赞、取消点赞和浏览量写入后，索引中的计数和按计数的排序随之更新
+func testSearchIndexCounters(t T, h Harness) {
+	ctx := context.Background()
+	owner := mustUser(t, h, "nina")
+	alpha := mustTool(t, h, owner.ID, "alpha", true)
+	beta := mustTool(t, h, owner.ID, "beta", true)
+
+	dir, err := os.MkdirTemp("", "repotest-index-")
+	if err != nil {
+		t.Fatalf("MkdirTemp: %v", err)
+	}
+	defer os.RemoveAll(dir)
+	index, err := repository.OpenBleveSearchIndex(ctx, filepath.Join(dir, "index"), h.Search)
+	if err != nil {
+		t.Fatalf("OpenBleveSearchIndex: %v", err)
+	}
+	defer index.Close()
+
+	views := service.NewViewCounter(h.Views, index, 0)
+	tools := service.NewToolService(h.Tools, index, views, service.NewSuggester(h.Search),
+		service.NewContentFilter(h.Words, h.Reports), pagination.NewCodec("repotest"))
+
+	// sorted 返回按 sort 排列的工具ID及对应的计数
+	sorted := func(sort string) string {
+		t.Helper()
+		hits, err := index.Search(ctx, model.SearchQuery{Types: []string{model.ResourceTypeTool}, Sort: sort}, firstPage(10))
+		if err != nil {
+			t.Fatalf("Search sort=%s: %v", sort, err)
+		}
+		var got []string
+		for _, hit := range hits.Items {
+			count := map[string]int{"loves": hit.Loves, "views": hit.Views}[sort]
+			got = append(got, fmt.Sprintf("%d:%d", hit.ResourceID, count))
+		}
+		return strings.Join(got, " ")
+	}
+	order := func(first, firstCount, second, secondCount int) string {
+		return fmt.Sprintf("%d:%d %d:%d", first, firstCount, second, secondCount)
+	}
+
+	// 计数相同时按文档ID倒序
+	if got, want := sorted("loves"), order(beta, 0, alpha, 0); got != want {
+		t.Fatalf("initial loves order: got %s, want %s", got, want)
+	}
+	if _, err := tools.LikeTool(ctx, owner.ID, alpha); err != nil {
+		t.Fatalf("LikeTool: %v", err)
+	}
+	if got, want := sorted("loves"), order(alpha, 1, beta, 0); got != want {
+		t.Errorf("loves order after like: got %s, want %s", got, want)
+	}
+	if _, err := tools.UnlikeTool(ctx, owner.ID, alpha); err != nil {
+		t.Fatalf("UnlikeTool: %v", err)
+	}
+	if got, want := sorted("loves"), order(beta, 0, alpha, 0); got != want {
+		t.Errorf("loves order after unlike: got %s, want %s", got, want)
+	}
+
+	// 浏览量写入数据库之前索引不变，Flush 之后同步
+	for _, visitor := range []string{"v1", "v2"} {
+		if _, err := tools.AddView(ctx, alpha, visitor); err != nil {
+			t.Fatalf("AddView: %v", err)
+		}
+	}
+	if got, want := sorted("views"), order(beta, 0, alpha, 0); got != want {
+		t.Errorf("views order before flush: got %s, want %s", got, want)
+	}
+	if err := views.Flush(ctx); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	if got, want := sorted("views"), order(alpha, 2, beta, 0); got != want {
+		t.Errorf("views order after flush: got %s, want %s", got, want)
+	}
+}
+
+// facetsEqual 比较分面统计与 "name:value=count ..." 形式的期望值，不计顺序
+func facetsEqual(want string, counts []model.FacetCount) bool {
+	expected := map[string]bool{}
+	for _, field := range strings.Fields(want[strings.Index(want, ":")+1:]) {
+		expected[field] = true
+	}
+	if len(expected) != len(counts) {
+		return false
+	}
+	for _, c := range counts {
+		if !expected[fmt.Sprintf("%s=%d", c.Value, c.Count)] {
+			return false
+		}
+	}
+	return true
+}
+
+func testProjectUpdate(t T, h Harness) {
+	ctx := context.Background()
+	author := mustUser(t, h, "leo")
+	stranger := mustUser(t, h, "mia")
+	project := mustProject(t, h, author.ID, "tracker")
+	mustStatus(t, h, model.ResourceTypeProject, project, model.StatusApproved)
+
+	if _, err := h.Projects.Update(ctx, stranger.ID, project, 0, projectRequest("stolen")); err == nil {
+		t.Errorf("update by non-author: expected error")
+	}
+
+	req := projectRequest("tracker")
+	req.TechStack = []string{"Rust", "Rust"}
+	req.Images = []string{"new.png"}
+	if _, err := h.Projects.Update(ctx, author.ID, project, 0, req); err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if detail, err := h.Projects.GetByID(ctx, project); err != nil || detail != nil {
+		t.Errorf("updated project should be pending: got %+v, %v", detail, err)
+	}
+
+	mustStatus(t, h, model.ResourceTypeProject, project, model.StatusApproved)
+	detail, err := h.Projects.GetByID(ctx, project)
+	if err != nil || detail == nil {
+		t.Fatalf("GetByID: %+v, %v", detail, err)
+	}
+	if len(detail.TechStack) != 1 || detail.TechStack[0] != "Rust" || detail.Cover != "new.png" || len(detail.Images) != 1 {
+		t.Errorf("updated relations: got %+v", detail)
+	}
+	if len(detail.Author) != 1 || detail.Author[0] != "leo_nick" {
+		t.Errorf("authors: got %v", detail.Author)
+	}
+}
+
+func testTxRollback(t T, h Harness) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	err := h.Tx.WithTx(ctx, func(ctx context.Context) error {
+		if err := h.Users.Create(ctx, &model.User{Username: "nina", Email: "nina@example.com", Password: "x"}); err != nil {
+			return err
+		}
+		return errAbort
+	})
+	if !errors.Is(err, errAbort) {
+		t.Errorf("WithTx: got %v, want %v", err, errAbort)
+	}
+	if user, err := h.Users.GetByUsername(ctx, "nina"); err != nil || user != nil {
+		t.Errorf("user created in rolled back tx: got %+v, %v", user, err)
+	}
+
+	err = h.Tx.WithTx(ctx, func(ctx context.Context) error {
+		return h.Users.Create(ctx, &model.User{Username: "nina", Email: "nina@example.com", Password: "x"})
+	})
+	if err != nil {
+		t.Fatalf("WithTx commit: %v", err)
+	}
+	if user, err := h.Users.GetByUsername(ctx, "nina"); err != nil || user == nil {
+		t.Errorf("user created in committed tx: got %+v, %v", user, err)
+	}
+}
+
+func testTrash(t T, h Harness) {
+	ctx := context.Background()
+	owner := mustUser(t, h, "oscar")
+	fan := mustUser(t, h, "pam")
+	admin := mustUser(t, h, "quinn")
+	tool := mustTool(t, h, owner.ID, "retired", true)
+	project := mustProject(t, h, owner.ID, "sunset")
+	course, err := h.Fixtures.CreateCourse(ctx, model.Course{Name: "Legacy", Semester: "2020-1"})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+	if _, err := h.Tools.CollectTool(ctx, fan.ID, tool); err != nil {
+		t.Fatalf("CollectTool: %v", err)
+	}
+
+	if item, err := h.Trash.Delete(ctx, fan.ID, fan.ID, model.ResourceTypeTool, tool, 0); err != nil || item != nil {
+		t.Errorf("Delete by non-contributor: got %+v, %v; want nil, nil", item, err)
+	}
+	item, err := h.Trash.Delete(ctx, owner.ID, owner.ID, model.ResourceTypeTool, tool, 0)
+	if err != nil || item == nil {
+		t.Fatalf("Delete: got %+v, %v", item, err)
+	}
+	if item.ResourceName != "retired" || item.AuditStatus != model.StatusApproved || item.DeletedBy != "oscar_nick" {
+		t.Errorf("Delete: got %+v", item)
+	}
+	if item, err := h.Trash.Delete(ctx, owner.ID, owner.ID, model.ResourceTypeTool, tool, 0); err != nil || item != nil {
+		t.Errorf("Delete twice: got %+v, %v; want nil, nil", item, err)
+	}
+	if _, err := h.Trash.Delete(ctx, 0, admin.ID, "unknown", tool, 0); err == nil {
+		t.Errorf("Delete unknown type: expected error")
+	}
+
+	// 回收站中的资源从公开查询和互动中消失
+	if tools, err := h.Tools.GetTools(ctx, nil, nil, "", firstPage(10)); err != nil || len(tools.Items) != 0 {
+		t.Errorf("GetTools after delete: got %+v, %v", tools, err)
+	}
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || detail != nil {
+		t.Errorf("GetByID after delete: got %+v, %v; want nil, nil", detail, err)
+	}
+	if _, err := h.Tools.LikeTool(ctx, fan.ID, tool); err == nil {
+		t.Errorf("LikeTool deleted tool: expected error")
+	}
+	if _, err := h.Tools.AddComment(ctx, fan.ID, tool, "hello"); err == nil {
+		t.Errorf("AddComment deleted tool: expected error")
+	}
+	if collection, info, err := h.Users.GetCollection(ctx, fan.ID, pagination.Page{Limit: 10, WithTotal: true}); err != nil ||
+		len(collection.Tools) != 0 || info.Total == nil || *info.Total != 0 {
+		t.Errorf("GetCollection after delete: got %+v, %+v, %v", collection, info, err)
+	}
+
+	if _, err := h.Trash.Delete(ctx, owner.ID, owner.ID, model.ResourceTypeProject, project, 0); err != nil {
+		t.Fatalf("Delete project: %v", err)
+	}
+	if submits, err := h.Projects.GetPending(ctx, model.PendingFilter{}, firstPage(10)); err != nil || len(submits.Items) != 0 {
+		t.Errorf("GetPending after delete: got %+v, %v", submits, err)
+	}
+	if _, err := h.Trash.Delete(ctx, 0, admin.ID, model.ResourceTypeCourse, course, 0); err != nil {
+		t.Fatalf("Delete course by admin: %v", err)
+	}
+	if courses, err := h.Courses.GetCourses(ctx, "", nil, "", firstPage(10)); err != nil || len(courses.Items) != 0 {
+		t.Errorf("GetCourses after delete: got %+v, %v", courses, err)
+	}
+
+	if mine, err := h.Trash.List(ctx, owner.ID, firstPage(10)); err != nil || len(mine.Items) != 2 {
+		t.Errorf("List own trash: got %+v, %v", mine, err)
+	}
+	if others, err := h.Trash.List(ctx, fan.ID, firstPage(10)); err != nil || len(others.Items) != 0 {
+		t.Errorf("List trash of non-contributor: got %+v, %v", others, err)
+	}
+	// 三类资源合并分页，按删除时间倒序
+	first, err := h.Trash.List(ctx, 0, pagination.Page{Limit: 2, WithTotal: true})
+	if err != nil || len(first.Items) != 2 || !first.HasMore || first.Total == nil || *first.Total != 3 {
+		t.Fatalf("List all trash: got %+v, %v", first, err)
+	}
+	second, err := h.Trash.List(ctx, 0, pagination.Page{After: first.Next, Limit: 2})
+	if err != nil || len(second.Items) != 1 || second.HasMore {
+		t.Fatalf("List all trash second page: got %+v, %v", second, err)
+	}
+	// 同一时刻删除的资源以类型区分先后，两页合起来恰好是三条不同的记录
+	deleters := map[string]string{}
+	for _, item := range append(first.Items, second.Items...) {
+		deleters[item.ResourceType] = item.DeletedBy
+	}
+	if len(deleters) != 3 || deleters[model.ResourceTypeCourse] != "quinn_nick" {
+		t.Errorf("List all trash pages: got %+v and %+v", first.Items, second.Items)
+	}
+
+	if item, err := h.Trash.Restore(ctx, fan.ID, model.ResourceTypeTool, tool, 0); err != nil || item != nil {
+		t.Errorf("Restore by non-contributor: got %+v, %v; want nil, nil", item, err)
+	}
+	if item, err := h.Trash.Restore(ctx, owner.ID, model.ResourceTypeTool, tool, 0); err != nil || item == nil || item.ResourceID != tool {
+		t.Fatalf("Restore: got %+v, %v", item, err)
+	}
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || detail == nil || detail.Collections != 1 {
+		t.Errorf("GetByID after restore: got %+v, %v", detail, err)
+	}
+	if item, err := h.Trash.Restore(ctx, owner.ID, model.ResourceTypeTool, tool, 0); err != nil || item != nil {
+		t.Errorf("Restore twice: got %+v, %v; want nil, nil", item, err)
+	}
+
+	if item, err := h.Trash.Purge(ctx, owner.ID, model.ResourceTypeTool, tool, 0); err != nil || item != nil {
+		t.Errorf("Purge resource not in trash: got %+v, %v; want nil, nil", item, err)
+	}
+	if item, err := h.Trash.Purge(ctx, owner.ID, model.ResourceTypeProject, project, 0); err != nil || item == nil {
+		t.Fatalf("Purge: got %+v, %v", item, err)
+	}
+	if item, err := h.Trash.Restore(ctx, owner.ID, model.ResourceTypeProject, project, 0); err != nil || item != nil {
+		t.Errorf("Restore purged project: got %+v, %v; want nil, nil", item, err)
+	}
+	// 永久删除后名称可以再次使用
+	mustProject(t, h, owner.ID, "sunset")
+
+	if purged, err := h.Trash.PurgeExpired(ctx, time.Now().Add(-time.Hour)); err != nil || purged != 0 {
+		t.Errorf("PurgeExpired before deletion: got %d, %v; want 0", purged, err)
+	}
+	if purged, err := h.Trash.PurgeExpired(ctx, time.Now().Add(time.Second)); err != nil || purged != 1 {
+		t.Errorf("PurgeExpired: got %d, %v; want 1", purged, err)
+	}
+	if all, err := h.Trash.List(ctx, 0, firstPage(10)); err != nil || len(all.Items) != 0 {
+		t.Errorf("List after PurgeExpired: got %+v, %v", all, err)
+	}
+	if detail, err := h.Courses.GetByID(ctx, course); err != nil || detail != nil {
+		t.Errorf("GetByID purged course: got %+v, %v; want nil, nil", detail, err)
+	}
+}
+
+func testVersion(t T, h Harness) {
+	ctx := context.Background()
+	author := mustUser(t, h, "rita")
+	project := mustProject(t, h, author.ID, "ledger")
+	mustStatus(t, h, model.ResourceTypeProject, project, model.StatusApproved)
+
+	detail, err := h.Projects.GetByID(ctx, project)
+	if err != nil || detail == nil || detail.Version != 1 {
+		t.Fatalf("GetByID: got %+v, %v; want version 1", detail, err)
+	}
+
+	review, err := h.Projects.Update(ctx, author.ID, project, 1, projectRequest("ledger"))
+	if err != nil || review == nil || review.Version != 2 {
+		t.Fatalf("Update with current version: got %+v, %v; want version 2", review, err)
+	}
+	if _, err := h.Projects.Update(ctx, author.ID, project, 1, projectRequest("ledger-v2")); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("Update with stale version: got %v; want ErrVersionConflict", err)
+	}
+	// 修改后重新进入待审核，公开接口看不到，作者仍能取到最新版本
+	if detail, err := h.Projects.GetByID(ctx, project); err != nil || detail != nil {
+		t.Errorf("GetByID pending project: got %+v, %v; want nil, nil", detail, err)
+	}
+	current, err := h.Projects.GetCurrent(ctx, project)
+	if err != nil || current == nil || current.Version != 2 || current.Name != "ledger" {
+		t.Fatalf("GetCurrent: got %+v, %v", current, err)
+	}
+	if review, err := h.Projects.Update(ctx, author.ID, project, 0, projectRequest("ledger")); err != nil || review.Version != 3 {
+		t.Errorf("Update without version check: got %+v, %v; want version 3", review, err)
+	}
+
+	// 删除、恢复同样检查并递增版本
+	if _, err := h.Trash.Delete(ctx, author.ID, author.ID, model.ResourceTypeProject, project, 2); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("Delete with stale version: got %v; want ErrVersionConflict", err)
+	}
+	if item, err := h.Trash.Get(ctx, author.ID, model.ResourceTypeProject, project); err != nil || item == nil ||
+		item.Version != 3 || item.DeletedAt != "" {
+		t.Errorf("Get live project: got %+v, %v", item, err)
+	}
+	item, err := h.Trash.Delete(ctx, author.ID, author.ID, model.ResourceTypeProject, project, 3)
+	if err != nil || item == nil || item.Version != 4 {
+		t.Fatalf("Delete with current version: got %+v, %v; want version 4", item, err)
+	}
+	if _, err := h.Trash.Restore(ctx, author.ID, model.ResourceTypeProject, project, 3); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("Restore with stale version: got %v; want ErrVersionConflict", err)
+	}
+	if _, err := h.Trash.Purge(ctx, author.ID, model.ResourceTypeProject, project, 3); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("Purge with stale version: got %v; want ErrVersionConflict", err)
+	}
+	if item, err := h.Trash.Restore(ctx, author.ID, model.ResourceTypeProject, project, 4); err != nil || item == nil || item.Version != 5 {
+		t.Errorf("Restore with current version: got %+v, %v; want version 5", item, err)
+	}
+	if item, err := h.Trash.Get(ctx, author.ID+1, model.ResourceTypeProject, project); err != nil || item != nil {
+		t.Errorf("Get by non-author: got %+v, %v; want nil, nil", item, err)
+	}
+
+	// 点赞、浏览等计数变化不影响版本
+	if _, err := h.Projects.LikeProject(ctx, author.ID, project); err != nil {
+		t.Fatalf("LikeProject: %v", err)
+	}
+	if current, err := h.Projects.GetCurrent(ctx, project); err != nil || current.Version != 5 {
+		t.Errorf("GetCurrent after like: got %+v, %v; want version 5", current, err)
+	}
+}
+
+func testViews(t T, h Harness) {
+	ctx := context.Background()
+	owner := mustUser(t, h, "sam")
+	tool := mustTool(t, h, owner.ID, "counter", true)
+	other := mustTool(t, h, owner.ID, "gauge", true)
+
+	if views, found, err := h.Views.Views(ctx, model.ResourceTypeTool, tool); err != nil || !found || views != 0 {
+		t.Fatalf("Views: got %d, %t, %v; want 0, true", views, found, err)
+	}
+	if _, found, err := h.Views.Views(ctx, model.ResourceTypeTool, tool+100); err != nil || found {
+		t.Errorf("Views of missing tool: got %t, %v; want not found", found, err)
+	}
+
+	err := h.Views.AddViews(ctx, []repository.ViewDelta{
+		{ResourceType: model.ResourceTypeTool, ResourceID: tool, Date: "2026-10-18", Views: 2},
+		{ResourceType: model.ResourceTypeTool, ResourceID: tool, Date: "2026-10-19", Views: 3},
+		{ResourceType: model.ResourceTypeTool, ResourceID: other, Date: "2026-10-19", Views: 1},
+	})
+	if err != nil {
+		t.Fatalf("AddViews: %v", err)
+	}
+	// 同一天的增量累加到已有记录上
+	if err := h.Views.AddViews(ctx, []repository.ViewDelta{
+		{ResourceType: model.ResourceTypeTool, ResourceID: tool, Date: "2026-10-19", Views: 4},
+	}); err != nil {
+		t.Fatalf("AddViews again: %v", err)
+	}
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || detail == nil || detail.Views != 9 {
+		t.Errorf("GetByID after AddViews: got %+v, %v; want 9 views", detail, err)
+	}
+	daily, err := h.Views.DailyViews(ctx, model.ResourceTypeTool, tool, "2026-10-01", "2026-10-31")
+	if err != nil || len(daily) != 2 || daily[0] != (model.DailyViews{Date: "2026-10-18", Views: 2}) ||
+		daily[1] != (model.DailyViews{Date: "2026-10-19", Views: 7}) {
+		t.Errorf("DailyViews: got %+v, %v", daily, err)
+	}
+	if daily, err := h.Views.DailyViews(ctx, model.ResourceTypeTool, tool, "2026-10-19", "2026-10-19"); err != nil || len(daily) != 1 {
+		t.Errorf("DailyViews single day: got %+v, %v", daily, err)
+	}
+
+	// 类型无效时整批不写入
+	if err := h.Views.AddViews(ctx, []repository.ViewDelta{
+		{ResourceType: model.ResourceTypeTool, ResourceID: tool, Date: "2026-10-19", Views: 1},
+		{ResourceType: "unknown", ResourceID: tool, Date: "2026-10-19", Views: 1},
+	}); err == nil {
+		t.Errorf("AddViews unknown type: expected error")
+	}
+	if views, _, err := h.Views.Views(ctx, model.ResourceTypeTool, tool); err != nil || views != 9 {
+		t.Errorf("Views after failed AddViews: got %d, %v; want 9", views, err)
+	}
+
+	// 已删除的资源跳过，永久删除时一并清理每日浏览量
+	if _, err := h.Trash.Delete(ctx, owner.ID, owner.ID, model.ResourceTypeTool, tool, 0); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, found, err := h.Views.Views(ctx, model.ResourceTypeTool, tool); err != nil || found {
+		t.Errorf("Views of deleted tool: got %t, %v; want not found", found, err)
+	}
+	if err := h.Views.AddViews(ctx, []repository.ViewDelta{
+		{ResourceType: model.ResourceTypeTool, ResourceID: tool, Date: "2026-10-19", Views: 5},
+	}); err != nil {
+		t.Fatalf("AddViews deleted tool: %v", err)
+	}
+	if _, err := h.Trash.Purge(ctx, owner.ID, model.ResourceTypeTool, tool, 0); err != nil {
+		t.Fatalf("Purge: %v", err)
+	}
+	if daily, err := h.Views.DailyViews(ctx, model.ResourceTypeTool, tool, "2026-10-01", "2026-10-31"); err != nil || len(daily) != 0 {
+		t.Errorf("DailyViews after purge: got %+v, %v", daily, err)
+	}
+	if daily, err := h.Views.DailyViews(ctx, model.ResourceTypeTool, other, "2026-10-01", "2026-10-31"); err != nil || len(daily) != 1 {
+		t.Errorf("DailyViews of other tool: got %+v, %v", daily, err)
+	}
+}
+
+func testReconcile(t T, h Harness) {
+	ctx := context.Background()
+	owner := mustUser(t, h, "tina")
+	fan := mustUser(t, h, "uma")
+	tool := mustTool(t, h, owner.ID, "drifting", true)
+	project := mustProject(t, h, owner.ID, "archived")
+	mustStatus(t, h, model.ResourceTypeProject, project, model.StatusApproved)
+
+	if _, err := h.Tools.LikeTool(ctx, fan.ID, tool); err != nil {
+		t.Fatalf("LikeTool: %v", err)
+	}
+	if _, err := h.Tools.CollectTool(ctx, fan.ID, tool); err != nil {
+		t.Fatalf("CollectTool: %v", err)
+	}
+	comment, err := h.Tools.AddComment(ctx, fan.ID, tool, "question")
+	if err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	for _, content := range []string{"answer", "thanks"} {
+		if _, err := h.Tools.ReplyComment(ctx, owner.ID, tool, comment.CommentID, content); err != nil {
+			t.Fatalf("ReplyComment: %v", err)
+		}
+	}
+	// 回收站中的资源仍然存在，其点赞不是孤儿行
+	if _, err := h.Projects.LikeProject(ctx, fan.ID, project); err != nil {
+		t.Fatalf("LikeProject: %v", err)
+	}
+	if _, err := h.Trash.Delete(ctx, owner.ID, owner.ID, model.ResourceTypeProject, project, 0); err != nil {
+		t.Fatalf("Delete project: %v", err)
+	}
+
+	report, err := h.Reconcile.Reconcile(ctx, false)
+	if err != nil || len(report.Drifts) != 0 || len(report.Orphans) != 0 {
+		t.Fatalf("Reconcile consistent data: got %+v, %v", report, err)
+	}
+
+	for _, err := range []error{
+		h.Fixtures.SetCounter(ctx, "tools", tool, "loves", 5),
+		h.Fixtures.SetCounter(ctx, "projects", project, "loves", 0),
+		h.Fixtures.SetCounter(ctx, "comments", comment.CommentID, "reply_total", 7),
+		h.Fixtures.InsertRelation(ctx, "likes", fan.ID, model.ResourceTypeTool, tool+100),
+		h.Fixtures.InsertRelation(ctx, "likes", owner.ID, model.ResourceTypeTool, tool+100),
+		h.Fixtures.InsertRelation(ctx, "collections", fan.ID, "unknown", tool),
+	} {
+		if err != nil {
+			t.Fatalf("prepare drift: %v", err)
+		}
+	}
+
+	wantDrifts := []model.CounterDrift{
+		{Table: "tools", ID: tool, Column: "loves", Stored: 5, Actual: 1},
+		{Table: "projects", ID: project, Column: "loves", Stored: 0, Actual: 1},
+		{Table: "comments", ID: comment.CommentID, Column: "reply_total", Stored: 7, Actual: 2},
+	}
+	wantOrphans := []model.OrphanRows{
+		{Table: "likes", ResourceType: model.ResourceTypeTool, ResourceID: tool + 100, Rows: 2},
+		{Table: "collections", ResourceType: "unknown", ResourceID: tool, Rows: 1},
+	}
+	checkReport := func(name string, report *model.ReconcileReport, err error, fixed bool) {
+		t.Helper()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if fmt.Sprint(report.Drifts) != fmt.Sprint(wantDrifts) || fmt.Sprint(report.Orphans) != fmt.Sprint(wantOrphans) ||
+			report.Fixed != fixed {
+			t.Errorf("%s: got %+v", name, report)
+		}
+	}
+
+	report, err = h.Reconcile.Reconcile(ctx, false)
+	checkReport("Reconcile dry run", report, err, false)
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || detail.Loves != 5 {
+		t.Errorf("GetByID after dry run: got %+v, %v; want 5 loves", detail, err)
+	}
+
+	report, err = h.Reconcile.Reconcile(ctx, true)
+	checkReport("Reconcile fix", report, err, true)
+	detail, err := h.Tools.GetByID(ctx, tool)
+	if err != nil || detail.Loves != 1 || detail.Collections != 1 || len(detail.Comments) != 1 || detail.Comments[0].ReplyTotal != 2 {
+		t.Errorf("GetByID after fix: got %+v, %v", detail, err)
+	}
+	if report, err := h.Reconcile.Reconcile(ctx, false); err != nil || len(report.Drifts) != 0 || len(report.Orphans) != 0 {
+		t.Errorf("Reconcile after fix: got %+v, %v", report, err)
+	}
+
+	// 恢复后的项目计数已修正
+	if _, err := h.Trash.Restore(ctx, owner.ID, model.ResourceTypeProject, project, 0); err != nil {
+		t.Fatalf("Restore project: %v", err)
+	}
+	if detail, err := h.Projects.GetByID(ctx, project); err != nil || detail == nil || detail.Likes != 1 {
+		t.Errorf("GetByID restored project: got %+v, %v; want 1 love", detail, err)
+	}
+}
+
+func testSavedSearches(t T, h Harness) {
+	ctx := context.Background()
+	owner := mustUser(t, h, "rita")
+	other := mustUser(t, h, "sam")
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	search := &model.SavedSearch{
+		UserID:    owner.ID,
+		Name:      "AI 工具",
+		Query:     model.SavedQuery{Types: []string{model.ResourceTypeTool}, Tags: []string{"AI", "Go"}},
+		Channel:   model.ChannelEmail,
+		CheckedAt: start,
+	}
+	if err := h.SavedSearches.Create(ctx, search); err != nil || search.ID == 0 {
+		t.Fatalf("Create: got id %d, %v", search.ID, err)
+	}
+	if err := h.SavedSearches.Create(ctx, &model.SavedSearch{UserID: other.ID, Name: "项目", Query: model.SavedQuery{Keyword: "gopher"},
+		Channel: model.ChannelInApp, CheckedAt: start}); err != nil {
+		t.Fatalf("Create other: %v", err)
+	}
+
+	mine, err := h.SavedSearches.List(ctx, owner.ID)
+	if err != nil || len(mine) != 1 {
+		t.Fatalf("List: got %+v, %v; want 1 search", mine, err)
+	}
+	got := mine[0]
+	if got.ID != search.ID || got.Name != "AI 工具" || got.Channel != model.ChannelEmail ||
+		fmt.Sprint(got.Query.Types, got.Query.Tags) != "[tool] [AI Go]" || !got.CheckedAt.Equal(start) || got.LastNotifiedAt != nil {
+		t.Errorf("List: got %+v", got)
+	}
+	if all, err := h.SavedSearches.List(ctx, 0); err != nil || len(all) != 2 || all[0].ID != search.ID {
+		t.Errorf("List all: got %+v, %v; want 2 searches in id order", all, err)
+	}
+
+	// 记录检查进度和提醒时间
+	checked := start.Add(30 * time.Minute)
+	if err := h.SavedSearches.MarkChecked(ctx, search.ID, checked, nil); err != nil {
+		t.Fatalf("MarkChecked: %v", err)
+	}
+	if mine, err := h.SavedSearches.List(ctx, owner.ID); err != nil || !mine[0].CheckedAt.Equal(checked) || mine[0].LastNotifiedAt != nil {
+		t.Errorf("List after MarkChecked: got %+v, %v", mine, err)
+	}
+	now := time.Now()
+	if err := h.SavedSearches.MarkChecked(ctx, search.ID, checked.Add(time.Minute), &now); err != nil {
+		t.Fatalf("MarkChecked notified: %v", err)
+	}
+	if mine, err := h.SavedSearches.List(ctx, owner.ID); err != nil || !mine[0].CheckedAt.Equal(checked.Add(time.Minute)) || mine[0].LastNotifiedAt == nil {
+		t.Errorf("List after notified: got %+v, %v", mine, err)
+	}
+
+	// 只能删除自己的检索
+	if deleted, err := h.SavedSearches.Delete(ctx, other.ID, search.ID); err != nil || deleted {
+		t.Errorf("Delete by other user: got %v, %v; want false", deleted, err)
+	}
+	if deleted, err := h.SavedSearches.Delete(ctx, owner.ID, search.ID); err != nil || !deleted {
+		t.Errorf("Delete: got %v, %v; want true", deleted, err)
+	}
+	if mine, err := h.SavedSearches.List(ctx, owner.ID); err != nil || len(mine) != 0 {
+		t.Errorf("List after Delete: got %+v, %v", mine, err)
+	}
+
+	// 上架时间窗口：审核通过的工具和项目、导入的课程，不含未审核和已删除的
+	since := time.Now().Add(-time.Second)
+	approved := mustTool(t, h, owner.ID, "fresh", true)
+	mustTool(t, h, owner.ID, "waiting", false)
+	removed := mustTool(t, h, owner.ID, "removed", true)
+	project := mustProject(t, h, owner.ID, "launch")
+	mustStatus(t, h, model.ResourceTypeProject, project, model.StatusApproved)
+	course, err := h.Fixtures.CreateCourse(ctx, model.Course{Name: "Compilers", Semester: "2024-1"})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+	if _, err := h.Trash.Delete(ctx, owner.ID, owner.ID, model.ResourceTypeTool, removed, 0); err != nil {
+		t.Fatalf("Trash.Delete: %v", err)
+	}
+	until := time.Now().Add(time.Second)
+
+	resources, err := h.SavedSearches.Approved(ctx, since, until)
+	if err != nil {
+		t.Fatalf("Approved: %v", err)
+	}
+	want := map[string]bool{
+		fmt.Sprint(model.ResourceTypeTool, approved):   true,
+		fmt.Sprint(model.ResourceTypeProject, project): true,
+		fmt.Sprint(model.ResourceTypeCourse, course):   true,
+	}
+	for i, res := range resources {
+		key := fmt.Sprint(res.ResourceType, res.ResourceID)
+		if !want[key] || res.ApprovedAt.Before(since) || res.ApprovedAt.After(until) {
+			t.Errorf("Approved: unexpected %+v", res)
+		}
+		if i > 0 && res.ApprovedAt.Before(resources[i-1].ApprovedAt) {
+			t.Errorf("Approved: %+v out of order", res)
+		}
+		delete(want, key)
+	}
+	if len(want) != 0 {
+		t.Errorf("Approved: missing %v", want)
+	}
+	if resources, err := h.SavedSearches.Approved(ctx, until, until.Add(time.Hour)); err != nil || len(resources) != 0 {
+		t.Errorf("Approved after window: got %+v, %v; want none", resources, err)
+	}
+
+	// 删除用户时一并删除其检索
+	if err := h.Users.Delete(ctx, other.ID); err != nil {
+		t.Fatalf("Users.Delete: %v", err)
+	}
+	if all, err := h.SavedSearches.List(ctx, 0); err != nil || len(all) != 0 {
+		t.Errorf("List after user deleted: got %+v, %v", all, err)
+	}
+}
+
+func testNotifications(t T, h Harness) {
+	ctx := context.Background()
+	reader := mustUser(t, h, "tina")
+	other := mustUser(t, h, "umar")
+
+	since := time.Now().Add(-time.Second)
+	var ids []int
+	for i, kind := range []string{model.NotificationSavedSearch, "system", model.NotificationSavedSearch} {
+		n := &model.Notification{
+			UserID:       reader.ID,
+			Kind:         kind,
+			Title:        fmt.Sprintf("notice %d", i),
+			Content:      "body",
+			Channel:      model.ChannelInApp,
+			ResourceType: model.ResourceTypeTool,
+			ResourceID:   i + 1,
+		}
+		if err := h.Notifications.Create(ctx, n); err != nil || n.ID == 0 || n.CreatedAt == "" {
+			t.Fatalf("Create: got %+v, %v", n, err)
+		}
+		ids = append(ids, n.ID)
+	}
+	foreign := &model.Notification{UserID: other.ID, Kind: model.NotificationSavedSearch, Title: "other", Channel: model.ChannelEmail}
+	if err := h.Notifications.Create(ctx, foreign); err != nil {
+		t.Fatalf("Create other: %v", err)
+	}
+
+	// 按时间倒序分页
+	page := pagination.Page{Limit: 2, WithTotal: true}
+	result, err := h.Notifications.List(ctx, reader.ID, false, page)
+	if err != nil || len(result.Items) != 2 || !result.HasMore || result.Total == nil || *result.Total != 3 ||
+		result.Items[0].ID != ids[2] || result.Items[1].ID != ids[1] {
+		t.Fatalf("List first page: got %+v, %v", result, err)
+	}
+	if n := result.Items[0]; n.Kind != model.NotificationSavedSearch || n.ResourceType != model.ResourceTypeTool || n.ResourceID != 3 || n.IsRead {
+		t.Errorf("List item: got %+v", n)
+	}
+	page.After = result.Next
+	if result, err := h.Notifications.List(ctx, reader.ID, false, page); err != nil || len(result.Items) != 1 ||
+		result.HasMore || result.Items[0].ID != ids[0] {
+		t.Errorf("List second page: got %+v, %v", result, err)
+	}
+
+	// 只能标记自己的通知，已读的不再计入
+	if marked, err := h.Notifications.MarkRead(ctx, other.ID, ids[0]); err != nil || marked != 0 {
+		t.Errorf("MarkRead by other user: got %d, %v; want 0", marked, err)
+	}
+	if marked, err := h.Notifications.MarkRead(ctx, reader.ID, ids[0]); err != nil || marked != 1 {
+		t.Errorf("MarkRead: got %d, %v; want 1", marked, err)
+	}
+	if marked, err := h.Notifications.MarkRead(ctx, reader.ID, ids[0]); err != nil || marked != 0 {
+		t.Errorf("MarkRead twice: got %d, %v; want 0", marked, err)
+	}
+	if result, err := h.Notifications.List(ctx, reader.ID, true, pagination.Page{Limit: 10, WithTotal: true}); err != nil ||
+		len(result.Items) != 2 || result.Total == nil || *result.Total != 2 {
+		t.Errorf("List unread: got %+v, %v; want 2 notifications", result, err)
+	}
+	if marked, err := h.Notifications.MarkRead(ctx, reader.ID, 0); err != nil || marked != 2 {
+		t.Errorf("MarkRead all: got %d, %v; want 2", marked, err)
+	}
+	if result, err := h.Notifications.List(ctx, reader.ID, true, firstPage(10)); err != nil || len(result.Items) != 0 {
+		t.Errorf("List unread after MarkRead all: got %+v, %v", result, err)
+	}
+
+	// 按类型和时间统计，已读的也计入
+	if count, err := h.Notifications.Count(ctx, reader.ID, model.NotificationSavedSearch, since); err != nil || count != 2 {
+		t.Errorf("Count: got %d, %v; want 2", count, err)
+	}
+	if count, err := h.Notifications.Count(ctx, reader.ID, model.NotificationSavedSearch, time.Now().Add(time.Minute)); err != nil || count != 0 {
+		t.Errorf("Count in future: got %d, %v; want 0", count, err)
+	}
+
+	// 删除用户时一并删除其通知
+	if err := h.Users.Delete(ctx, other.ID); err != nil {
+		t.Fatalf("Users.Delete: %v", err)
+	}
+	if count, err := h.Notifications.Count(ctx, other.ID, model.NotificationSavedSearch, since); err != nil || count != 0 {
+		t.Errorf("Count after user deleted: got %d, %v; want 0", count, err)
+	}
+}
+
+func testReview(t T, h Harness) {
+	ctx := context.Background()
+	author := mustUser(t, h, "vera")
+	admin := mustUser(t, h, "walt")
+	tool := mustTool(t, h, author.ID, "inspector", false)
+	project := mustProject(t, h, author.ID, "reviewed")
+	course, err := h.Fixtures.CreateCourse(ctx, model.Course{Name: "Databases", Semester: "2024-2"})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+	upload, err := h.Courses.UploadResource(ctx, author.ID, course, model.CourseUploadRequest{Description: "notes", Resource: "https://example.com/notes"})
+	if err != nil || upload.Resource1 == nil {
+		t.Fatalf("UploadResource: got %+v, %v", upload, err)
+	}
+	web := upload.Resource1.ResourceID
+
+	state, err := h.Reviews.Get(ctx, 0, model.ResourceTypeTool, tool)
+	if err != nil || state == nil || state.AuditStatus != model.StatusPending || state.Version != 1 || state.ResourceName != "inspector" {
+		t.Fatalf("Get: got %+v, %v", state, err)
+	}
+	if _, err := h.Reviews.Get(ctx, 0, "course", course); err == nil {
+		t.Errorf("Get course: expected error for unknown review type")
+	}
+
+	queue := []string{model.StatusPending, model.StatusResubmitted}
+	change := func(resourceType string, id int, from []string, to string) repository.StatusChange {
+		return repository.StatusChange{ResourceType: resourceType, ResourceID: id, From: from, To: to, OperatorID: admin.ID}
+	}
+
+	// 通过：记录操作人，版本加一，状态不在 From 中时不能重复审核
+	approve := change(model.ResourceTypeTool, tool, queue, model.StatusApproved)
+	approve.Version = 1
+	m, err := h.Reviews.Transition(ctx, approve)
+	if err != nil || m == nil || m.OldStatus != model.StatusPending || m.NewStatus != model.StatusApproved ||
+		m.Operator != "walt_nick" || m.Version != 2 {
+		t.Fatalf("Transition approve: got %+v, %v", m, err)
+	}
+	if _, err := h.Reviews.Transition(ctx, approve); !errors.Is(err, repository.ErrStatusConflict) {
+		t.Errorf("Transition approve twice: got %v, want ErrStatusConflict", err)
+	}
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || detail == nil {
+		t.Errorf("GetByID approved tool: got %+v, %v", detail, err)
+	}
+
+	// 隐藏后不再出现在列表中
+	hide := change(model.ResourceTypeTool, tool, []string{model.StatusApproved}, model.StatusHidden)
+	hide.RejectReason = "outdated"
+	hide.Version = 1
+	if _, err := h.Reviews.Transition(ctx, hide); !errors.Is(err, repository.ErrVersionConflict) {
+		t.Errorf("Transition hide with stale version: got %v, want ErrVersionConflict", err)
+	}
+	hide.Version = 0
+	if m, err := h.Reviews.Transition(ctx, hide); err != nil || m.NewStatus != model.StatusHidden || m.Version != 3 {
+		t.Fatalf("Transition hide: got %+v, %v", m, err)
+	}
+	if state, err := h.Reviews.Get(ctx, 0, model.ResourceTypeTool, tool); err != nil || state.AuditStatus != model.StatusHidden ||
+		state.RejectReason != "outdated" {
+		t.Errorf("Get hidden: got %+v, %v", state, err)
+	}
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || detail != nil {
+		t.Errorf("GetByID hidden tool: got %+v, %v; want nil", detail, err)
+	}
+
+	// 拒绝后由提交者重新提交，重新进入审核队列并保留拒绝理由
+	reject := change(model.ResourceTypeProject, project, queue, model.StatusRejected)
+	reject.RejectReason = "missing README"
+	if m, err := h.Reviews.Transition(ctx, reject); err != nil || m.NewStatus != model.StatusRejected {
+		t.Fatalf("Transition reject: got %+v, %v", m, err)
+	}
+	if pending, err := h.Projects.GetPending(ctx, model.PendingFilter{}, firstPage(10)); err != nil || len(pending.Items) != 0 {
+		t.Errorf("GetPending after reject: got %+v, %v", pending, err)
+	}
+	resubmit := repository.StatusChange{
+		ResourceType: model.ResourceTypeProject, ResourceID: project, From: []string{model.StatusRejected},
+		To: model.StatusResubmitted, OperatorID: admin.ID, OwnerID: admin.ID,
+	}
+	if m, err := h.Reviews.Transition(ctx, resubmit); err != nil || m != nil {
+		t.Errorf("Transition resubmit by non-submitter: got %+v, %v; want nil", m, err)
+	}
+	resubmit.OperatorID, resubmit.OwnerID = author.ID, author.ID
+	if m, err := h.Reviews.Transition(ctx, resubmit); err != nil || m.OldStatus != model.StatusRejected || m.Operator != "vera_nick" {
+		t.Fatalf("Transition resubmit: got %+v, %v", m, err)
+	}
+	if state, err := h.Reviews.Get(ctx, author.ID, model.ResourceTypeProject, project); err != nil || state == nil ||
+		state.AuditStatus != model.StatusResubmitted || state.RejectReason != "missing README" {
+		t.Errorf("Get resubmitted: got %+v, %v", state, err)
+	}
+	if pending, err := h.Projects.GetPending(ctx, model.PendingFilter{}, pagination.Page{Limit: 10, WithTotal: true}); err != nil ||
+		len(pending.Items) != 1 || pending.Total == nil || *pending.Total != 1 {
+		t.Errorf("GetPending after resubmit: got %+v, %v", pending, err)
+	}
+	if m, err := h.Reviews.Transition(ctx, change(model.ResourceTypeProject, project, queue, model.StatusApproved)); err != nil || m.Version != 4 {
+		t.Errorf("Transition approve resubmitted: got %+v, %v", m, err)
+	}
+	if state, _ := h.Reviews.Get(ctx, 0, model.ResourceTypeProject, project); state == nil || state.RejectReason != "" {
+		t.Errorf("Get approved project: got %+v; want reject reason cleared", state)
+	}
+
+	// 课程资源没有版本号，随课程删除后不能再审核
+	if m, err := h.Reviews.Transition(ctx, change(model.ResourceTypeCourseWeb, web, queue, model.StatusApproved)); err != nil ||
+		m.Version != 0 || m.ResourceType != model.ResourceTypeCourseWeb {
+		t.Errorf("Transition course resource: got %+v, %v", m, err)
+	}
+	if pending, err := h.Courses.GetPending(ctx, model.PendingFilter{}, firstPage(10)); err != nil || len(pending.Items) != 0 {
+		t.Errorf("Courses.GetPending after approve: got %+v, %v", pending, err)
+	}
+	if _, err := h.Trash.Delete(ctx, 0, admin.ID, model.ResourceTypeCourse, course, 0); err != nil {
+		t.Fatalf("Trash.Delete course: %v", err)
+	}
+	if state, err := h.Reviews.Get(ctx, 0, model.ResourceTypeCourseWeb, web); err != nil || state != nil {
+		t.Errorf("Get resource of deleted course: got %+v, %v; want nil", state, err)
+	}
+
+	// 每次变更一条记录，操作人被删除后记录保留
+	logs, err := h.Reviews.History(ctx, model.ResourceTypeTool, tool)
+	if err != nil || fmt.Sprint(logs) != fmt.Sprint([]model.StatusLog{
+		{OldStatus: model.StatusPending, NewStatus: model.StatusApproved, Operator: "walt_nick", OperateTime: logs[0].OperateTime},
+		{OldStatus: model.StatusApproved, NewStatus: model.StatusHidden, Operator: "walt_nick", OperateTime: logs[1].OperateTime},
+	}) {
+		t.Errorf("History: got %+v, %v", logs, err)
+	}
+	if err := h.Users.Delete(ctx, admin.ID); err != nil {
+		t.Fatalf("Users.Delete: %v", err)
+	}
+	if logs, err := h.Reviews.History(ctx, model.ResourceTypeProject, project); err != nil || len(logs) != 3 ||
+		logs[0].Operator != "" || logs[1].Operator != "vera_nick" {
+		t.Errorf("History after operator deleted: got %+v, %v", logs, err)
+	}
+}
+
+func testCommentReports(t T, h Harness) {
+	ctx := context.Background()
+	author := mustUser(t, h, "xena")
+	reporter := mustUser(t, h, "yuri")
+	admin := mustUser(t, h, "zack")
+	tool := mustTool(t, h, author.ID, "flagged", true)
+
+	first, err := h.Tools.AddComment(ctx, author.ID, tool, "buy cheap followers")
+	if err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+	reply, err := h.Tools.ReplyComment(ctx, reporter.ID, tool, first.CommentID, "reported as spam")
+	if err != nil {
+		t.Fatalf("ReplyComment: %v", err)
+	}
+	second, err := h.Tools.AddComment(ctx, reporter.ID, tool, "you are an idiot")
+	if err != nil {
+		t.Fatalf("AddComment: %v", err)
+	}
+
+	// 同一用户的待处理举报只记一次，系统标记单独计数
+	c, added, err := h.Reports.Report(ctx, first.CommentID, reporter.ID, model.ReportSpam, "ads")
+	if err != nil || !added || c == nil || c.Reports != 1 || c.AuthorID != author.ID || c.Author != "xena_nick" ||
+		c.ResourceType != model.ResourceTypeTool || c.ResourceID != tool || c.Hidden || c.ReportedAt == "" {
+		t.Fatalf("Report: got %+v, %v, %v", c, added, err)
+	}
+	if c, added, err := h.Reports.Report(ctx, first.CommentID, reporter.ID, model.ReportAbuse, ""); err != nil || added || c.Reports != 1 {
+		t.Errorf("Report twice: got %+v, %v, %v", c, added, err)
+	}
+	if c, added, err := h.Reports.Report(ctx, first.CommentID, 0, model.ReportOther, ""); err != nil || !added || c.Reports != 2 ||
+		c.Reasons[model.ReportSpam] != 1 || c.Reasons[model.ReportOther] != 1 {
+		t.Errorf("Report by system: got %+v, %v, %v", c, added, err)
+	}
+	if _, _, err := h.Reports.Report(ctx, second.CommentID, author.ID, model.ReportAbuse, ""); err != nil {
+		t.Fatalf("Report second: %v", err)
+	}
+	if c, _, err := h.Reports.Report(ctx, 9999, reporter.ID, model.ReportSpam, ""); err != nil || c != nil {
+		t.Errorf("Report missing comment: got %+v, %v; want nil", c, err)
+	}
+
+	// 审核队列按最早的待处理举报排序
+	queue, err := h.Reports.Pending(ctx, pagination.Page{Limit: 1, WithTotal: true})
+	if err != nil || len(queue.Items) != 1 || queue.Items[0].CommentID != first.CommentID || queue.Items[0].Reports != 2 ||
+		!queue.HasMore || queue.Total == nil || *queue.Total != 2 {
+		t.Fatalf("Pending: got %+v, %v", queue, err)
+	}
+	if queue, err = h.Reports.Pending(ctx, pagination.Page{Limit: 1, After: queue.Next}); err != nil ||
+		len(queue.Items) != 1 || queue.Items[0].CommentID != second.CommentID || queue.HasMore {
+		t.Errorf("Pending second page: got %+v, %v", queue, err)
+	}
+
+	// 隐藏的评论连同回复一起不再显示，也不能再被举报或回复
+	if hidden, err := h.Reports.Hide(ctx, first.CommentID); err != nil || !hidden {
+		t.Fatalf("Hide: got %v, %v", hidden, err)
+	}
+	if hidden, err := h.Reports.Hide(ctx, first.CommentID); err != nil || hidden {
+		t.Errorf("Hide twice: got %v, %v; want false", hidden, err)
+	}
+	detail, err := h.Tools.GetByID(ctx, tool)
+	if err != nil || detail.CommentCount != 2 || len(detail.Comments) != 1 || detail.Comments[0].CommentID != second.CommentID {
+		t.Errorf("GetByID with hidden comment: got %+v, %v", detail, err)
+	}
+	if comments, err := h.Tools.GetComments(ctx, tool, firstPage(10)); err != nil || len(comments.Items) != 1 {
+		t.Errorf("GetComments with hidden comment: got %+v, %v", comments, err)
+	}
+	if c, _, err := h.Reports.Report(ctx, first.CommentID, admin.ID, model.ReportSpam, ""); err != nil || c != nil {
+		t.Errorf("Report hidden comment: got %+v, %v; want nil", c, err)
+	}
+	if _, err := h.Tools.ReplyComment(ctx, admin.ID, tool, first.CommentID, "hello?"); err == nil {
+		t.Errorf("ReplyComment to hidden comment: expected error")
+	}
+	if c, err := h.Reports.Get(ctx, first.CommentID); err != nil || c == nil || !c.Hidden || c.Reports != 2 {
+		t.Errorf("Get hidden: got %+v, %v", c, err)
+	}
+
+	// 驳回举报恢复显示，举报全部处理，之后可以再次举报
+	before, err := h.Reports.Resolve(ctx, first.CommentID, admin.ID, model.ModerationDismiss)
+	if err != nil || before == nil || before.Reports != 2 || !before.Hidden {
+		t.Fatalf("Resolve dismiss: got %+v, %v", before, err)
+	}
+	if c, err := h.Reports.Get(ctx, first.CommentID); err != nil || c.Hidden || c.Reports != 0 || c.ReportedAt != "" {
+		t.Errorf("Get after dismiss: got %+v, %v", c, err)
+	}
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || detail.CommentCount != 3 || len(detail.Comments) != 2 {
+		t.Errorf("GetByID after dismiss: got %+v, %v", detail, err)
+	}
+	if _, added, err := h.Reports.Report(ctx, first.CommentID, reporter.ID, model.ReportSpam, ""); err != nil || !added {
+		t.Errorf("Report after dismiss: got %v, %v", added, err)
+	}
+
+	// 删除回复时更新父评论的回复数，已删除的评论不在队列中
+	if _, _, err := h.Reports.Report(ctx, reply.CommentID, author.ID, model.ReportAbuse, ""); err != nil {
+		t.Fatalf("Report reply: %v", err)
+	}
+	if before, err := h.Reports.Resolve(ctx, reply.CommentID, admin.ID, model.ModerationDelete); err != nil || before == nil || before.Reports != 1 {
+		t.Fatalf("Resolve delete: got %+v, %v", before, err)
+	}
+	if detail, err := h.Tools.GetByID(ctx, tool); err != nil || len(detail.Comments) != 2 || detail.Comments[0].ReplyTotal != 0 {
+		t.Errorf("GetByID after delete: got %+v, %v", detail, err)
+	}
+	if c, err := h.Reports.Get(ctx, reply.CommentID); err != nil || c != nil {
+		t.Errorf("Get deleted: got %+v, %v; want nil", c, err)
+	}
+	if before, err := h.Reports.Resolve(ctx, reply.CommentID, admin.ID, model.ModerationHide); err != nil || before != nil {
+		t.Errorf("Resolve deleted: got %+v, %v; want nil", before, err)
+	}
+
+	// 警告隐藏评论；处理人被删除后不影响举报记录
+	if _, err := h.Reports.Resolve(ctx, second.CommentID, admin.ID, model.ModerationWarn); err != nil {
+		t.Fatalf("Resolve warn: %v", err)
+	}
+	if c, err := h.Reports.Get(ctx, second.CommentID); err != nil || !c.Hidden || c.Reports != 0 {
+		t.Errorf("Get after warn: got %+v, %v", c, err)
+	}
+	if err := h.Users.Delete(ctx, admin.ID); err != nil {
+		t.Fatalf("Users.Delete: %v", err)
+	}
+	queue, err = h.Reports.Pending(ctx, pagination.Page{Limit: 10, WithTotal: true})
+	if err != nil || len(queue.Items) != 1 || queue.Items[0].CommentID != first.CommentID || *queue.Total != 1 {
+		t.Errorf("Pending after moderation: got %+v, %v", queue, err)
+	}
+
+	// 删除举报人时一并删除其举报
+	if err := h.Users.Delete(ctx, reporter.ID); err != nil {
+		t.Fatalf("Users.Delete reporter: %v", err)
+	}
+	if queue, err := h.Reports.Pending(ctx, firstPage(10)); err != nil || len(queue.Items) != 0 {
+		t.Errorf("Pending after reporter deleted: got %+v, %v", queue, err)
+	}
+}
+
+func testSensitiveWords(t T, h Harness) {
+	ctx := context.Background()
+	admin := mustUser(t, h, "wade")
+
+	if words, err := h.Words.List(ctx); err != nil || len(words) != 0 {
+		t.Fatalf("List empty: got %+v, %v", words, err)
+	}
+	spam := &model.SensitiveWord{Word: "spam", Level: "mild", CreatedBy: admin.ID}
+	if err := h.Words.Save(ctx, spam); err != nil || spam.ID == 0 || spam.CreatedAt == "" || spam.CreatedBy != admin.ID {
+		t.Fatalf("Save: got %+v, %v", spam, err)
+	}
+	curse := &model.SensitiveWord{Word: "混蛋", Level: "middle"}
+	if err := h.Words.Save(ctx, curse); err != nil || curse.ID == 0 || curse.ID == spam.ID {
+		t.Fatalf("Save second: got %+v, %v", curse, err)
+	}
+
+	// 词已存在时只修改等级，ID、添加人和添加时间不变
+	again := &model.SensitiveWord{Word: "spam", Level: "severe"}
+	if err := h.Words.Save(ctx, again); err != nil || again.ID != spam.ID || again.Level != "severe" ||
+		again.CreatedBy != admin.ID || again.CreatedAt != spam.CreatedAt {
+		t.Errorf("Save existing: got %+v, %v; want level changed on %+v", again, err, spam)
+	}
+	words, err := h.Words.List(ctx)
+	if err != nil || len(words) != 2 || words[0].ID != spam.ID || words[0].Level != "severe" || words[1].Word != "混蛋" {
+		t.Errorf("List: got %+v, %v", words, err)
+	}
+
+	// 删除添加人后敏感词保留
+	if err := h.Users.Delete(ctx, admin.ID); err != nil {
+		t.Fatalf("Users.Delete: %v", err)
+	}
+	if words, err := h.Words.List(ctx); err != nil || len(words) != 2 || words[0].CreatedBy != 0 {
+		t.Errorf("List after creator deleted: got %+v, %v", words, err)
+	}
+
+	if deleted, err := h.Words.Delete(ctx, spam.ID); err != nil || !deleted {
+		t.Errorf("Delete: got %v, %v", deleted, err)
+	}
+	if deleted, err := h.Words.Delete(ctx, spam.ID); err != nil || deleted {
+		t.Errorf("Delete twice: got %v, %v; want false", deleted, err)
+	}
+	if words, err := h.Words.List(ctx); err != nil || len(words) != 1 || words[0].ID != curse.ID {
+		t.Errorf("List after delete: got %+v, %v", words, err)
+	}
+}
+
+func testAuditLog(t T, h Harness) {
+	ctx := context.Background()
+	admin := mustUser(t, h, "xena")
+	other := mustUser(t, h, "yuri")
+
+	entries := []*model.AuditLog{
+		{ActorID: admin.ID, Actor: admin.Username, Action: "review.approve", TargetType: model.ResourceTypeTool, TargetID: 1,
+			Before: json.RawMessage(`{"auditStatus":"pending"}`), After: json.RawMessage(`{"auditStatus":"approved"}`), IP: "10.0.0.1", RequestID: "r1"},
+		{ActorID: other.ID, Actor: other.Username, Action: "review.reject", TargetType: model.ResourceTypeTool, TargetID: 2, IP: "10.0.0.2", RequestID: "r2"},
+		{ActorID: admin.ID, Actor: admin.Username, Action: model.AuditResourcePurge, TargetType: model.ResourceTypeTool, TargetID: 1,
+			Before: json.RawMessage(`{"name":"x"}`)},
+		{ActorID: admin.ID, Actor: admin.Username, Action: "reviewer.note", TargetType: model.AuditTargetComment, TargetID: 1},
+	}
+	for _, entry := range entries {
+		if err := h.Audit.Append(ctx, entry); err != nil || entry.ID == 0 || entry.CreatedAt == "" {
+			t.Fatalf("Append: got %+v, %v", entry, err)
+		}
+	}
+
+	list := func(filter model.AuditFilter) []int {
+		result, err := h.Audit.List(ctx, filter, pagination.Page{Limit: 10})
+		if err != nil {
+			t.Fatalf("List %+v: %v", filter, err)
+		}
+		var ids []int
+		for _, entry := range result.Items {
+			ids = append(ids, entry.ID)
+		}
+		return ids
+	}
+	id := func(i ...int) []int {
+		var ids []int
+		for _, n := range i {
+			ids = append(ids, entries[n].ID)
+		}
+		return ids
+	}
+
+	// 按时间倒序，快照原样返回，没有快照的为 null
+	result, err := h.Audit.List(ctx, model.AuditFilter{}, pagination.Page{Limit: 10, WithTotal: true})
+	if err != nil || len(result.Items) != 4 || result.Total == nil || *result.Total != 4 || result.Items[0].ID != entries[3].ID {
+		t.Fatalf("List all: got %+v, %v", result, err)
+	}
+	first := result.Items[3]
+	if first.ActorID != admin.ID || first.Actor != admin.Username || first.IP != "10.0.0.1" || first.RequestID != "r1" ||
+		string(first.Before) != `{"auditStatus":"pending"}` || string(first.After) != `{"auditStatus":"approved"}` {
+		t.Errorf("List entry: got %+v", first)
+	}
+	if string(result.Items[1].After) != "null" || string(result.Items[2].Before) != "null" {
+		t.Errorf("List empty snapshots: got %s, %s; want null", result.Items[1].After, result.Items[2].Before)
+	}
+
+	if got := list(model.AuditFilter{ActorID: admin.ID}); !slices.Equal(got, id(3, 2, 0)) {
+		t.Errorf("List by actor id: got %v", got)
+	}
+	if got := list(model.AuditFilter{Actor: other.Username}); !slices.Equal(got, id(1)) {
+		t.Errorf("List by actor name: got %v", got)
+	}
+	// 类别只匹配“类别.”开头的操作，review 不匹配 reviewer.note
+	if got := list(model.AuditFilter{Action: "review"}); !slices.Equal(got, id(1, 0)) {
+		t.Errorf("List by action category: got %v", got)
+	}
+	if got := list(model.AuditFilter{Action: "review.reject"}); !slices.Equal(got, id(1)) {
+		t.Errorf("List by action: got %v", got)
+	}
+	if got := list(model.AuditFilter{TargetType: model.ResourceTypeTool, TargetID: 1}); !slices.Equal(got, id(2, 0)) {
+		t.Errorf("List by target: got %v", got)
+	}
+
+	now := time.Now()
+	if got := list(model.AuditFilter{From: now.Add(-time.Hour), To: now.Add(time.Hour)}); len(got) != 4 {
+		t.Errorf("List in range: got %v", got)
+	}
+	if got := list(model.AuditFilter{From: now.Add(time.Hour)}); len(got) != 0 {
+		t.Errorf("List after range: got %v", got)
+	}
+	if got := list(model.AuditFilter{To: now.Add(-time.Hour)}); len(got) != 0 {
+		t.Errorf("List before range: got %v", got)
+	}
+
+	page, err := h.Audit.List(ctx, model.AuditFilter{ActorID: admin.ID}, pagination.Page{Limit: 2})
+	if err != nil || len(page.Items) != 2 || !page.HasMore {
+		t.Fatalf("List page 1: got %+v, %v", page, err)
+	}
+	page, err = h.Audit.List(ctx, model.AuditFilter{ActorID: admin.ID}, pagination.Page{After: page.Next, Limit: 2})
+	if err != nil || len(page.Items) != 1 || page.HasMore || page.Items[0].ID != entries[0].ID {
+		t.Errorf("List page 2: got %+v, %v", page, err)
+	}
+
+	// 删除操作人后日志原样保留
+	if err := h.Users.Delete(ctx, other.ID); err != nil {
+		t.Fatalf("Users.Delete: %v", err)
+	}
+	if got := list(model.AuditFilter{ActorID: other.ID}); !slices.Equal(got, id(1)) {
+		t.Errorf("List after actor deleted: got %v", got)
+	}
+}
+
+func testReviewClaims(t T, h Harness) {
+	ctx := context.Background()
+	author := mustUser(t, h, "zack")
+	var admins []*model.User
+	for _, name := range []string{"amy", "ben"} {
+		admin := &model.User{Username: name, Nickname: name + "_nick", Email: name + "@example.com", Password: "hashed", Role: "admin"}
+		if err := h.Users.Create(ctx, admin); err != nil {
+			t.Fatalf("create admin %s: %v", name, err)
+		}
+		admins = append(admins, admin)
+	}
+	amy, ben := admins[0], admins[1]
+
+	var tools []model.ReviewTarget
+	for _, name := range []string{"alpha", "beta", "gamma"} {
+		tools = append(tools, model.ReviewTarget{ResourceType: model.ResourceTypeTool, ResourceID: mustTool(t, h, author.ID, name, false)})
+	}
+	approved := mustTool(t, h, author.ID, "delta", true)
+
+	if claim, err := h.Claims.Get(ctx, tools[0]); err != nil || claim != nil {
+		t.Fatalf("Get before claim: got %+v, %v", claim, err)
+	}
+
+	// 认领后他人不能认领，自己再认领为续期
+	expires := time.Now().Add(time.Hour)
+	claim, err := h.Claims.Claim(ctx, amy.ID, tools[0], expires)
+	if err != nil || claim.ClaimantID != amy.ID || claim.Claimant != amy.Nickname || claim.ExpiresAt == "" {
+		t.Fatalf("Claim: got %+v, %v", claim, err)
+	}
+	claim, err = h.Claims.Claim(ctx, ben.ID, tools[0], expires)
+	if !errors.Is(err, repository.ErrClaimConflict) || claim == nil || claim.ClaimantID != amy.ID {
+		t.Errorf("Claim by other: got %+v, %v; want ErrClaimConflict", claim, err)
+	}
+	claim, err = h.Claims.Claim(ctx, amy.ID, tools[0], expires.Add(time.Hour))
+	if err != nil || claim.ClaimantID != amy.ID || !claim.ExpiresTime.After(expires) {
+		t.Errorf("Renew: got %+v, %v", claim, err)
+	}
+
+	// 过期的认领视为没有人认领，其他人可以直接认领
+	if _, err := h.Claims.Claim(ctx, ben.ID, tools[1], time.Now().Add(-time.Minute)); !errors.Is(err, repository.ErrClaimConflict) {
+		t.Errorf("Claim expired: got %v; want ErrClaimConflict", err)
+	}
+	if claim, err := h.Claims.Get(ctx, tools[1]); err != nil || claim != nil && (claim.ClaimantID != 0 || claim.Claimant != "") {
+		t.Errorf("Get expired: got %+v, %v", claim, err)
+	}
+	if claim, err := h.Claims.Claim(ctx, amy.ID, tools[1], expires); err != nil || claim.ClaimantID != amy.ID {
+		t.Errorf("Claim after expiry: got %+v, %v", claim, err)
+	}
+
+	pending := func(filter model.PendingFilter) []int {
+		t.Helper()
+		result, err := h.Tools.GetPending(ctx, filter, firstPage(10))
+		if err != nil {
+			t.Fatalf("GetPending %+v: %v", filter, err)
+		}
+		var ids []int
+		for _, submit := range result.Items {
+			ids = append(ids, submit.ResourceID)
+		}
+		slices.Sort(ids)
+		return ids
+	}
+	ids := func(targets ...model.ReviewTarget) []int {
+		var ids []int
+		for _, target := range targets {
+			ids = append(ids, target.ResourceID)
+		}
+		return ids
+	}
+	if got := pending(model.PendingFilter{Claim: model.ClaimMine, ReviewerID: amy.ID}); !slices.Equal(got, ids(tools[0], tools[1])) {
+		t.Errorf("GetPending mine: got %v", got)
+	}
+	if got := pending(model.PendingFilter{Claim: model.ClaimMine, ReviewerID: ben.ID}); len(got) != 0 {
+		t.Errorf("GetPending mine for other: got %v", got)
+	}
+	if got := pending(model.PendingFilter{Claim: model.ClaimUnclaimed}); !slices.Equal(got, ids(tools[2])) {
+		t.Errorf("GetPending unclaimed: got %v", got)
+	}
+
+	// 只能放弃自己的认领
+	if released, err := h.Claims.Release(ctx, ben.ID, tools[1]); err != nil || released {
+		t.Errorf("Release by other: got %v, %v", released, err)
+	}
+	if released, err := h.Claims.Release(ctx, amy.ID, tools[1]); err != nil || !released {
+		t.Errorf("Release: got %v, %v", released, err)
+	}
+	if got := pending(model.PendingFilter{Claim: model.ClaimUnclaimed}); !slices.Equal(got, ids(tools[1], tools[2])) {
+		t.Errorf("GetPending unclaimed after release: got %v", got)
+	}
+
+	// 按提交先后轮流分配，已审核的不分配，下一次接着上一次的管理员继续
+	n, err := h.Claims.Distribute(ctx)
+	if err != nil || n != 3 {
+		t.Fatalf("Distribute: got %d, %v; want 3", n, err)
+	}
+	claims, err := h.Claims.List(ctx, append(tools, model.ReviewTarget{ResourceType: model.ResourceTypeTool, ResourceID: approved}))
+	if err != nil || len(claims) != 3 {
+		t.Fatalf("List: got %+v, %v", claims, err)
+	}
+	for i, want := range []*model.User{amy, ben, amy} {
+		if got := claims[tools[i]]; got.AssigneeID != want.ID || got.Assignee != want.Nickname {
+			t.Errorf("assignee of %s: got %+v; want %s", tools[i].ResourceType, got, want.Username)
+		}
+	}
+	if claims[tools[0]].ClaimantID != amy.ID {
+		t.Errorf("Distribute changed claimant: got %+v", claims[tools[0]])
+	}
+	if got := pending(model.PendingFilter{Claim: model.ClaimAssigned, ReviewerID: ben.ID}); !slices.Equal(got, ids(tools[1])) {
+		t.Errorf("GetPending assigned: got %v", got)
+	}
+	if n, err := h.Claims.Distribute(ctx); err != nil || n != 0 {
+		t.Errorf("Distribute again: got %d, %v; want 0", n, err)
+	}
+	next := model.ReviewTarget{ResourceType: model.ResourceTypeTool, ResourceID: mustTool(t, h, author.ID, "epsilon", false)}
+	if n, err := h.Claims.Distribute(ctx); err != nil || n != 1 {
+		t.Fatalf("Distribute new: got %d, %v; want 1", n, err)
+	}
+	if claim, err := h.Claims.Get(ctx, next); err != nil || claim == nil || claim.AssigneeID != ben.ID {
+		t.Errorf("Distribute continues round robin: got %+v, %v", claim, err)
+	}
+
+	// Lock 在事务中返回当前情况，没有记录时建一条空记录，之后仍可正常认领
+	err = h.Tx.WithTx(ctx, func(ctx context.Context) error {
+		claim, err := h.Claims.Lock(ctx, tools[0])
+		if err != nil || claim == nil || claim.ClaimantID != amy.ID || claim.AssigneeID != amy.ID {
+			t.Errorf("Lock claimed: got %+v, %v", claim, err)
+		}
+		claim, err = h.Claims.Lock(ctx, next)
+		if err != nil || claim == nil || claim.ClaimantID != 0 || claim.AssigneeID != ben.ID {
+			t.Errorf("Lock assigned: got %+v, %v", claim, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	fresh := model.ReviewTarget{ResourceType: model.ResourceTypeProject, ResourceID: mustProject(t, h, author.ID, "zeta")}
+	err = h.Tx.WithTx(ctx, func(ctx context.Context) error {
+		claim, err := h.Claims.Lock(ctx, fresh)
+		if err != nil || claim == nil || claim.ReviewTarget != fresh || claim.ClaimantID != 0 || claim.AssigneeID != 0 {
+			t.Errorf("Lock without record: got %+v, %v", claim, err)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithTx: %v", err)
+	}
+	if claim, err := h.Claims.Claim(ctx, ben.ID, fresh, expires); err != nil || claim.ClaimantID != ben.ID {
+		t.Errorf("Claim after Lock: got %+v, %v", claim, err)
+	}
+
+	// 删除管理员后分配和认领都清空
+	if err := h.Users.Delete(ctx, amy.ID); err != nil {
+		t.Fatalf("Users.Delete: %v", err)
+	}
+	if claim, err := h.Claims.Get(ctx, tools[0]); err != nil || claim == nil || claim.AssigneeID != 0 || claim.ClaimantID != 0 {
+		t.Errorf("Get after admin deleted: got %+v, %v", claim, err)
+	}
+}
+
+// adminService 基于 harness 的审核服务，邮件只写日志，检索索引直接查询仓库
+func adminService(h Harness, batchLimit int) service.AdminService {
+	return service.NewAdminService(h.Tools, h.Courses, h.Projects, h.Reviews, h.Reports, h.Claims,
+		repository.NewSQLSearchIndex(h.Search), service.NewNotifier(h.Notifications, h.Users, mail.New(mail.SMTPConfig{})),
+		service.NewAuditor(h.Tx, h.Audit), pagination.NewCodec("repotest"), batchLimit, time.Hour)
+}
+
+func testBulkReview(t T, h Harness) {
+	author := mustUser(t, h, "zack")
+	amy := &model.User{Username: "amy", Nickname: "amy_nick", Email: "amy@example.com", Password: "hashed", Role: "admin"}
+	ben := &model.User{Username: "ben", Nickname: "ben_nick", Email: "ben@example.com", Password: "hashed", Role: "admin"}
+	for _, admin := range []*model.User{amy, ben} {
+		if err := h.Users.Create(context.Background(), admin); err != nil {
+			t.Fatalf("create admin %s: %v", admin.Username, err)
+		}
+	}
+	ctx := service.WithAuditActor(context.Background(), model.AuditActor{UserID: amy.ID, Name: amy.Username, IP: "127.0.0.1", RequestID: "bulk"})
+
+	var tools []int
+	versions := make(map[int]int)
+	for _, name := range []string{"alpha", "beta", "gamma", "delta", "epsilon"} {
+		id := mustTool(t, h, author.ID, name, false)
+		state, err := h.Reviews.Get(ctx, 0, model.ResourceTypeTool, id)
+		if err != nil || state == nil {
+			t.Fatalf("Reviews.Get %s: got %+v, %v", name, state, err)
+		}
+		tools = append(tools, id)
+		versions[id] = state.Version
+	}
+	course, err := h.Fixtures.CreateCourse(ctx, model.Course{Name: "Compilers", Semester: "2024-1"})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+	upload, err := h.Courses.UploadResource(ctx, author.ID, course, model.CourseUploadRequest{Description: "slides", Resource: "https://example.com/slides"})
+	if err != nil || upload.Resource1 == nil {
+		t.Fatalf("UploadResource: got %+v, %v", upload, err)
+	}
+	courseWeb := upload.Resource1.ResourceID
+	gamma := model.ReviewTarget{ResourceType: model.ResourceTypeTool, ResourceID: tools[2]}
+	if _, err := h.Claims.Claim(ctx, ben.ID, gamma, time.Now().Add(time.Hour)); err != nil {
+		t.Fatalf("Claim: %v", err)
+	}
+	item := func(id, version int, reason string) model.BulkReviewItem {
+		return model.BulkReviewItem{ResourceType: model.ResourceTypeTool, ResourceID: id, Version: &version, RejectReason: reason}
+	}
+	reviewLogs := func() []model.AuditLog {
+		t.Helper()
+		result, err := h.Audit.List(ctx, model.AuditFilter{Action: "review"}, firstPage(20))
+		if err != nil {
+			t.Fatalf("Audit.List: %v", err)
+		}
+		return result.Items
+	}
+
+	// 超过上限时整批不执行
+	svc := adminService(h, 7)
+	tooMany := model.BulkReviewRequest{Action: "approve"}
+	for i := 0; i < 8; i++ {
+		tooMany.Items = append(tooMany.Items, item(tools[0], versions[tools[0]], ""))
+	}
+	if _, err := svc.ReviewItems(ctx, amy.ID, tooMany); !errors.Is(err, service.ErrTooManyItems) {
+		t.Errorf("ReviewItems over limit: got %v; want ErrTooManyItems", err)
+	}
+	if logs := reviewLogs(); len(logs) != 0 {
+		t.Errorf("audit logs after rejected batch: got %d", len(logs))
+	}
+
+	// 各项分别执行：自己的理由优先于共用的理由，失败的项不影响其他项
+	items := []model.BulkReviewItem{
+		item(tools[0], versions[tools[0]], ""),
+		item(tools[1], versions[tools[1]], "链接失效"),
+		item(tools[2], versions[tools[2]], ""),   // 被 ben 认领
+		item(tools[3], versions[tools[3]]+1, ""), // 版本过期
+		{ResourceType: model.ResourceTypeTool, ResourceID: tools[4]},
+		item(tools[4]+1000, 1, ""),
+		// 课程资源没有版本号，不需要 version
+		{ResourceType: model.ResourceTypeCourseWeb, ResourceID: courseWeb},
+	}
+	resp, err := svc.ReviewItems(ctx, amy.ID, model.BulkReviewRequest{Action: "reject", RejectReason: "重复提交", Items: items})
+	if err != nil {
+		t.Fatalf("ReviewItems: %v", err)
+	}
+	if resp.Succeeded != 3 || resp.Failed != 4 || len(resp.Results) != 7 {
+		t.Fatalf("ReviewItems: got %d succeeded, %d failed, %d results; want 3, 4, 7", resp.Succeeded, resp.Failed, len(resp.Results))
+	}
+	var claimed *service.ClaimConflictError
+	var stale *service.PreconditionFailedError
+	for i, check := range []func(error) bool{
+		func(err error) bool { return err == nil },
+		func(err error) bool { return err == nil },
+		func(err error) bool { return errors.As(err, &claimed) && claimed.Claim.ClaimantID == ben.ID },
+		func(err error) bool { return errors.As(err, &stale) },
+		func(err error) bool { return errors.Is(err, service.ErrVersionRequired) },
+		func(err error) bool { return errors.Is(err, service.ErrResourceNotFound) },
+		func(err error) bool { return err == nil },
+	} {
+		result := resp.Results[i]
+		if !check(result.Err) || result.ResourceID != items[i].ResourceID || (result.Err == nil) != (result.Manipulate != nil) {
+			t.Errorf("result %d: got %+v", i, result)
+		}
+	}
+
+	for id, want := range map[int]string{tools[0]: "重复提交", tools[1]: "链接失效"} {
+		if state, err := h.Reviews.Get(ctx, 0, model.ResourceTypeTool, id); err != nil || state.AuditStatus != model.StatusRejected || state.RejectReason != want {
+			t.Errorf("tool %d after batch: got %+v, %v; want rejected with %q", id, state, err, want)
+		}
+	}
+	for _, id := range tools[2:] {
+		if state, err := h.Reviews.Get(ctx, 0, model.ResourceTypeTool, id); err != nil || state.AuditStatus != model.StatusPending {
+			t.Errorf("failed tool %d after batch: got %+v, %v; want pending", id, state, err)
+		}
+	}
+
+	// 成功的每一项各记一条审计日志
+	logs := reviewLogs()
+	if len(logs) != 3 {
+		t.Fatalf("audit logs: got %d; want 3", len(logs))
+	}
+	var targets []string
+	for _, entry := range logs {
+		if entry.Action != model.AuditReviewPrefix+"reject" || entry.ActorID != amy.ID || entry.RequestID != "bulk" ||
+			!strings.Contains(string(entry.Before), model.StatusPending) || !strings.Contains(string(entry.After), model.StatusRejected) {
+			t.Errorf("audit log: got %+v", entry)
+		}
+		targets = append(targets, fmt.Sprint(entry.TargetType, entry.TargetID))
+	}
+	want := []string{fmt.Sprint(model.ResourceTypeCourseWeb, courseWeb),
+		fmt.Sprint(model.ResourceTypeTool, tools[0]), fmt.Sprint(model.ResourceTypeTool, tools[1])}
+	slices.Sort(targets)
+	if !slices.Equal(targets, want) {
+		t.Errorf("audit log targets: got %v; want %v", targets, want)
+	}
+}
+
+func testClaimAudit(t T, h Harness) {
+	author := mustUser(t, h, "zack")
+	amy := &model.User{Username: "amy", Nickname: "amy_nick", Email: "amy@example.com", Password: "hashed", Role: "admin"}
+	ben := &model.User{Username: "ben", Nickname: "ben_nick", Email: "ben@example.com", Password: "hashed", Role: "admin"}
+	for _, admin := range []*model.User{amy, ben} {
+		if err := h.Users.Create(context.Background(), admin); err != nil {
+			t.Fatalf("create admin %s: %v", admin.Username, err)
+		}
+	}
+	asAmy := service.WithAuditActor(context.Background(), model.AuditActor{UserID: amy.ID, Name: amy.Username, RequestID: "claim"})
+	asBen := service.WithAuditActor(context.Background(), model.AuditActor{UserID: ben.ID, Name: ben.Username})
+	svc := adminService(h, 10)
+	alpha := mustTool(t, h, author.ID, "alpha", false)
+	mustTool(t, h, author.ID, "beta", false)
+
+	logs := func(action string) []model.AuditLog {
+		t.Helper()
+		result, err := h.Audit.List(asAmy, model.AuditFilter{Action: action}, firstPage(20))
+		if err != nil {
+			t.Fatalf("Audit.List %s: %v", action, err)
+		}
+		return result.Items
+	}
+
+	// 认领记录认领前后的情况；他人认领失败时不记录
+	if _, err := svc.ClaimItem(asAmy, amy.ID, model.ResourceTypeTool, alpha); err != nil {
+		t.Fatalf("ClaimItem: %v", err)
+	}
+	var claimed *service.ClaimConflictError
+	if _, err := svc.ClaimItem(asBen, ben.ID, model.ResourceTypeTool, alpha); !errors.As(err, &claimed) {
+		t.Errorf("ClaimItem by other: got %v; want *ClaimConflictError", err)
+	}
+	if got := logs(model.AuditReviewClaim); len(got) != 1 || got[0].ActorID != amy.ID || got[0].RequestID != "claim" ||
+		got[0].TargetType != model.ResourceTypeTool || got[0].TargetID != alpha || !strings.Contains(string(got[0].After), fmt.Sprintf(`"claimantId":%d`, amy.ID)) {
+		t.Errorf("claim audit logs: got %+v", got)
+	}
+
+	// 他人不能放弃；没有认领可放弃时不记录
+	if err := svc.ReleaseItem(asBen, ben.ID, model.ResourceTypeTool, alpha); !errors.As(err, &claimed) {
+		t.Errorf("ReleaseItem by other: got %v; want *ClaimConflictError", err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := svc.ReleaseItem(asAmy, amy.ID, model.ResourceTypeTool, alpha); err != nil {
+			t.Fatalf("ReleaseItem: %v", err)
+		}
+	}
+	if got := logs(model.AuditReviewRelease); len(got) != 1 || got[0].ActorID != amy.ID || got[0].TargetID != alpha ||
+		!strings.Contains(string(got[0].Before), `"claimantId"`) || strings.Contains(string(got[0].After), `"claimantId"`) {
+		t.Errorf("release audit logs: got %+v", got)
+	}
+
+	// 分配记录分配的数目，没有可分配的资源时不记录
+	for i := 0; i < 2; i++ {
+		if _, err := svc.AssignPending(asAmy); err != nil {
+			t.Fatalf("AssignPending: %v", err)
+		}
+	}
+	if got := logs(model.AuditReviewAssign); len(got) != 1 || got[0].TargetType != model.AuditTargetReviewQueue || string(got[0].After) != `{"assigned":2}` {
+		t.Errorf("assign audit logs: got %+v", got)
+	}
+	if got := logs("review"); len(got) != 3 {
+		t.Errorf("review audit logs: got %d; want 3", len(got))
+	}
+}
@@ -0,0 +1,195 @@
+// Package repotest 是仓库接口的契约用例，内存实现和 SQL 实现跑同一套用例，
+// 保证两者在唯一约束、级联删除、分页等行为上一致
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"time"
+)
+
+// T 是用例需要的断言接口，由 repository_test.go 传入 *testing.T
+type T interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+	Fatalf(format string, args ...interface{})
+}
+
+// Fixtures 准备接口之外的数据：课程只能由管理员导入，审核状态由审核流程修改，
+// 计数偏差和孤儿行只会由故障产生
+type Fixtures interface {
+	CreateCourse(ctx context.Context, course model.Course) (int, error)
+	SetStatus(ctx context.Context, resourceType string, resourceID int, status string) error
+	// SetCounter 直接修改计数列，table 为 tools/courses/projects/comments
+	SetCounter(ctx context.Context, table string, id int, column string, value int) error
+	// InsertRelation 不检查资源是否存在，直接写入一条点赞或收藏
+	InsertRelation(ctx context.Context, table string, userID int, resourceType string, resourceID int) error
+}
+
+// Harness 一套待测的仓库实现，每个用例使用一个全新的空库
+type Harness struct {
+	Users     repository.UserRepository
+	Tools     repository.ToolRepository
+	Courses   repository.CourseRepository
+	Projects  repository.ProjectRepository
+	Trash     repository.TrashRepository
+	Search    repository.SearchRepository
+	Views     repository.ViewRepository
+	Reconcile repository.ReconcileRepository
+	Tx        repository.Transactor
+	Fixtures  Fixtures
+
+	SavedSearches repository.SavedSearchRepository
+	Notifications repository.NotificationRepository
+	Reviews       repository.ReviewRepository
+	Reports       repository.CommentReportRepository
+	Words         repository.SensitiveWordRepository
+	Audit         repository.AuditLogRepository
+	Claims        repository.ReviewClaimRepository
+}
+
+// Case 一条契约用例
+type Case struct {
+	Name string
+	Run  func(t T, h Harness)
+}
+
+// NewMemoryHarness 基于一个新的 MemoryStore 构造仓库
+func NewMemoryHarness() Harness {
+	store := repository.NewMemoryStore()
+	return Harness{
+		Users:     repository.NewMemoryUserRepository(store),
+		Tools:     repository.NewMemoryToolRepository(store),
+		Courses:   repository.NewMemoryCourseRepository(store),
+		Projects:  repository.NewMemoryProjectRepository(store),
+		Trash:     repository.NewMemoryTrashRepository(store),
+		Search:    repository.NewMemorySearchRepository(store),
+		Views:     repository.NewMemoryViewRepository(store),
+		Reconcile: repository.NewMemoryReconcileRepository(store),
+		Tx:        store,
+		Fixtures:  store,
+
+		SavedSearches: repository.NewMemorySavedSearchRepository(store),
+		Notifications: repository.NewMemoryNotificationRepository(store),
+		Reviews:       repository.NewMemoryReviewRepository(store),
+		Reports:       repository.NewMemoryCommentReportRepository(store),
+		Words:         repository.NewMemorySensitiveWordRepository(store),
+		Audit:         repository.NewMemoryAuditLogRepository(store),
+		Claims:        repository.NewMemoryReviewClaimRepository(store),
+	}
+}
+
+// NewSQLHarness 基于数据库连接构造仓库，调用方负责保证库是空的
+func NewSQLHarness(db *repository.Database) Harness {
+	return Harness{
+		Users:     repository.NewUserRepository(db),
+		Tools:     repository.NewToolRepository(db),
+		Courses:   repository.NewCourseRepository(db),
+		Projects:  repository.NewProjectRepository(db),
+		Trash:     repository.NewTrashRepository(db),
+		Search:    repository.NewSearchRepository(db),
+		Views:     repository.NewViewRepository(db),
+		Reconcile: repository.NewReconcileRepository(db),
+		Tx:        db,
+		Fixtures:  sqlFixtures{db: db},
+
+		SavedSearches: repository.NewSavedSearchRepository(db),
+		Notifications: repository.NewNotificationRepository(db),
+		Reviews:       repository.NewReviewRepository(db),
+		Reports:       repository.NewCommentReportRepository(db),
+		Words:         repository.NewSensitiveWordRepository(db),
+		Audit:         repository.NewAuditLogRepository(db),
+		Claims:        repository.NewReviewClaimRepository(db),
+	}
+}
+
+type sqlFixtures struct {
+	db *repository.Database
+}
+
+func (f sqlFixtures) CreateCourse(ctx context.Context, course model.Course) (int, error) {
+	var id int
+	err := f.db.WithTx(ctx, func(ctx context.Context) error {
+		result, err := f.db.ExecContext(ctx,
+			`INSERT INTO courses (resource_type, name, semester, credit, cover, views, loves, collections) VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			model.ResourceTypeCourse, course.Name, course.Semester, course.Credit, course.Cover,
+			course.Views, course.Loves, course.Collections,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to create course: %w", err)
+		}
+		lastID, err := result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("failed to get last insert id: %w", err)
+		}
+		id = int(lastID)
+
+		for _, teacher := range course.Teacher {
+			if _, err := f.db.ExecContext(ctx, `INSERT INTO course_teachers (course_id, teacher_name) VALUES (?, ?)`, id, teacher); err != nil {
+				return fmt.Errorf("failed to create course teacher: %w", err)
+			}
+		}
+		for _, category := range course.Category {
+			if _, err := f.db.ExecContext(ctx, `INSERT INTO course_categories (course_id, category) VALUES (?, ?)`, id, category); err != nil {
+				return fmt.Errorf("failed to create course category: %w", err)
+			}
+		}
+		return nil
+	})
+	return id, err
+}
+
+var statusTables = map[string]string{
+	model.ResourceTypeTool:         "tools SET status = ?, audit_time = ? WHERE resource_id = ?",
+	model.ResourceTypeProject:      "projects SET status = ?, audit_time = ? WHERE project_id = ?",
+	model.ResourceTypeCourseWeb:    "course_resources_web SET status = ?, audit_time = ? WHERE resource_id = ?",
+	model.ResourceTypeCourseUpload: "course_resources_upload SET status = ?, audit_time = ? WHERE resource_id = ?",
+}
+
+func (f sqlFixtures) SetStatus(ctx context.Context, resourceType string, resourceID int, status string) error {
+	update, ok := statusTables[resourceType]
+	if !ok {
+		return fmt.Errorf("unknown resource type: %s", resourceType)
+	}
+	result, err := f.db.ExecContext(ctx, `UPDATE `+update, status, time.Now(), resourceID)
+	if err != nil {
+		return fmt.Errorf("failed to set status: %w", err)
+	}
+	if rows, _ := result.RowsAffected(); rows == 0 {
+		return fmt.Errorf("%s not found", resourceType)
+	}
+	return nil
+}
+
+// counterColumns 允许 SetCounter 修改的计数列及各表的主键
+var counterColumns = map[string]string{
+	"tools":    "resource_id",
+	"courses":  "course_id",
+	"projects": "project_id",
+	"comments": "comment_id",
+}
+
+func (f sqlFixtures) SetCounter(ctx context.Context, table string, id int, column string, value int) error {
+	idColumn, ok := counterColumns[table]
+	if !ok {
+		return fmt.Errorf("unknown table: %s", table)
+	}
+	query := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s = ?", table, column, idColumn)
+	if _, err := f.db.ExecContext(ctx, query, value, id); err != nil {
+		return fmt.Errorf("failed to set counter: %w", err)
+	}
+	return nil
+}
+
+func (f sqlFixtures) InsertRelation(ctx context.Context, table string, userID int, resourceType string, resourceID int) error {
+	if table != "likes" && table != "collections" {
+		return fmt.Errorf("unknown table: %s", table)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (user_id, resource_type, resource_id) VALUES (?, ?, ?)", table)
+	if _, err := f.db.ExecContext(ctx, query, userID, resourceType, resourceID); err != nil {
+		return fmt.Errorf("failed to insert %s: %w", table, err)
+	}
+	return nil
+}
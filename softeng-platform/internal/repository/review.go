@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+// ErrStatusConflict 资源当前的审核状态不是 StatusChange.From 中的任何一个
+var ErrStatusConflict = errors.New("status conflict")
+
+// StatusChange 一次审核状态变更
+type StatusChange struct {
+	ResourceType string
+	ResourceID   int
+	From         []string // 允许变更的当前状态
+	To           string
+	RejectReason string // 拒绝或隐藏的理由，可以为空
+	OperatorID   int
+	// OwnerID 不为 0 时只能变更该用户提交的资源
+	OwnerID int
+	// Version 为 0 时不检查版本；课程资源没有版本号，忽略
+	Version int
+}
+
+// ReviewRepository 工具、项目和课程资源的审核状态。每次变更都写一条 resource_status_logs，
+// 工具和项目的版本号随之递增
+type ReviewRepository interface {
+	// Get 返回资源当前的审核状态，ownerID 不为 0 时只查找该用户提交的资源；找不到或已删除时返回 nil
+	Get(ctx context.Context, ownerID int, resourceType string, resourceID int) (*model.ReviewState, error)
+	// Transition 变更审核状态：通过时记录审核时间并清空拒绝理由，拒绝和隐藏时记录审核时间和理由，
+	// 重新提交时清空审核时间、保留上次的拒绝理由。找不到时返回 nil；当前状态不允许时返回 ErrStatusConflict，
+	// 版本不一致时返回 ErrVersionConflict
+	Transition(ctx context.Context, change StatusChange) (*model.Maneuver, error)
+	// History 按时间先后返回资源的状态变更记录
+	History(ctx context.Context, resourceType string, resourceID int) ([]model.StatusLog, error)
+}
+
+type reviewRepository struct {
+	db *Database
+}
+
+func NewReviewRepository(db *Database) ReviewRepository {
+	return &reviewRepository{db: db}
+}
+
+// reviewTable 审核目标对应的表
+type reviewTable struct {
+	table      string
+	idColumn   string
+	nameColumn string
+	// live 资源未被删除的条件：课程资源没有软删除，随所属课程一起删除
+	live string
+	// versioned 表上是否有 version 和 updated_at 列
+	versioned bool
+}
+
+var reviewTables = map[string]reviewTable{
+	model.ResourceTypeTool: {
+		table: "tools", idColumn: "resource_id", nameColumn: "resource_name",
+		live: "deleted_at IS NULL", versioned: true,
+	},
+	model.ResourceTypeProject: {
+		table: "projects", idColumn: "project_id", nameColumn: "name",
+		live: "deleted_at IS NULL", versioned: true,
+	},
+	model.ResourceTypeCourseWeb: {
+		table: "course_resources_web", idColumn: "resource_id", nameColumn: "resource_intro",
+		live: "course_id IN (SELECT course_id FROM courses WHERE deleted_at IS NULL)",
+	},
+	model.ResourceTypeCourseUpload: {
+		table: "course_resources_upload", idColumn: "resource_id", nameColumn: "resource_intro",
+		live: "course_id IN (SELECT course_id FROM courses WHERE deleted_at IS NULL)",
+	},
+}
+
+func lookupReviewTable(resourceType string) (reviewTable, error) {
+	t, ok := reviewTables[resourceType]
+	if !ok {
+		return reviewTable{}, fmt.Errorf("unknown review type: %s", resourceType)
+	}
+	return t, nil
+}
+
+// reviewColumns 审核状态变更后 audit_time、reject_reason 的新值，nil 表示保持不变
+func reviewColumns(to, reason string, now time.Time) (auditTime, rejectReason interface{}, keepReason bool) {
+	switch to {
+	case model.StatusApproved:
+		return now, nil, false
+	case model.StatusResubmitted:
+		return nil, nil, true
+	default:
+		return now, nullIfEmpty(reason), false
+	}
+}
+
+func (r *reviewRepository) Get(ctx context.Context, ownerID int, resourceType string, resourceID int) (*model.ReviewState, error) {
+	t, err := lookupReviewTable(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	version := "0"
+	if t.versioned {
+		version = "version"
+	}
+	query := fmt.Sprintf(`SELECT %s, COALESCE(%s, ''), COALESCE(status, ''), COALESCE(reject_reason, ''), %s
+		FROM %s WHERE %s = ? AND %s`, t.idColumn, t.nameColumn, version, t.table, t.idColumn, t.live)
+	args := []interface{}{resourceID}
+	if ownerID != 0 {
+		query += ` AND submitter_id = ?`
+		args = append(args, ownerID)
+	}
+
+	state := model.ReviewState{ResourceType: resourceType}
+	err = r.db.QueryRowContext(ctx, query, args...).Scan(&state.ResourceID, &state.ResourceName,
+		&state.AuditStatus, &state.RejectReason, &state.Version)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review state: %w", err)
+	}
+	return &state, nil
+}
+
+func (r *reviewRepository) Transition(ctx context.Context, change StatusChange) (*model.Maneuver, error) {
+	t, err := lookupReviewTable(change.ResourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.Maneuver, error) {
+		current, err := r.Get(ctx, change.OwnerID, change.ResourceType, change.ResourceID)
+		if err != nil || current == nil {
+			return nil, err
+		}
+		if !slices.Contains(change.From, current.AuditStatus) {
+			return nil, ErrStatusConflict
+		}
+
+		// 状态在同一条语句中检查，并发的审核只有一个能成功
+		now := time.Now()
+		auditTime, rejectReason, keepReason := reviewColumns(change.To, change.RejectReason, now)
+		query := `UPDATE ` + t.table + ` SET status = ?, audit_time = ?`
+		args := []interface{}{change.To, auditTime}
+		if !keepReason {
+			query += `, reject_reason = ?`
+			args = append(args, rejectReason)
+		}
+		if t.versioned {
+			query += `, updated_at = ?, version = version + 1`
+			args = append(args, now)
+		}
+		query += ` WHERE ` + t.idColumn + ` = ? AND status = ?`
+		args = append(args, change.ResourceID, current.AuditStatus)
+		if t.versioned {
+			match, matchArgs := versionMatch("version", change.Version)
+			query += match
+			args = append(args, matchArgs...)
+		}
+
+		result, err := r.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update %s status: %w", change.ResourceType, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rows == 0 {
+			return nil, ErrVersionConflict
+		}
+
+		if _, err := r.db.ExecContext(ctx,
+			`INSERT INTO resource_status_logs (resource_type, resource_id, old_status, new_status, operator_id, operate_time)
+			VALUES (?, ?, ?, ?, ?, ?)`,
+			change.ResourceType, change.ResourceID, current.AuditStatus, change.To, nullIfZero(change.OperatorID), now,
+		); err != nil {
+			return nil, fmt.Errorf("failed to create status log: %w", err)
+		}
+
+		var operator string
+		err = r.db.QueryRowContext(ctx, `SELECT COALESCE(nickname, username, '') FROM users WHERE id = ?`, change.OperatorID).Scan(&operator)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get operator: %w", err)
+		}
+
+		maneuver := &model.Maneuver{
+			ResourceID:   change.ResourceID,
+			ResourceType: change.ResourceType,
+			NewStatus:    change.To,
+			OldStatus:    current.AuditStatus,
+			OperateTime:  formatTime(now),
+			Operator:     operator,
+		}
+		if t.versioned {
+			maneuver.Version = current.Version + 1
+		}
+		return maneuver, nil
+	})
+}
+
+func (r *reviewRepository) History(ctx context.Context, resourceType string, resourceID int) ([]model.StatusLog, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT COALESCE(l.old_status, ''), l.new_status, COALESCE(u.nickname, u.username, ''), l.operate_time
+		FROM resource_status_logs l LEFT JOIN users u ON u.id = l.operator_id
+		WHERE l.resource_type = ? AND l.resource_id = ?
+		ORDER BY l.id`, resourceType, resourceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status logs: %w", err)
+	}
+	defer rows.Close()
+
+	logs := []model.StatusLog{}
+	for rows.Next() {
+		var log model.StatusLog
+		var operateTime time.Time
+		if err := rows.Scan(&log.OldStatus, &log.NewStatus, &log.Operator, &operateTime); err != nil {
+			return nil, fmt.Errorf("failed to scan status log: %w", err)
+		}
+		log.OperateTime = formatTime(operateTime)
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"strings"
+	"time"
+)
+
+// ErrClaimConflict 审核目标已被其他管理员认领且未过期
+var ErrClaimConflict = errors.New("claimed by another reviewer")
+
+// ReviewClaimRepository 待审核资源的分配和认领。分配只是建议由谁审核，认领在到期前独占审核
+type ReviewClaimRepository interface {
+	// Get 返回审核目标的分配和认领情况，没有记录时返回 nil
+	Get(ctx context.Context, target model.ReviewTarget) (*model.ReviewClaim, error)
+	// Lock 在当前事务中锁住审核目标的认领记录（没有时先建一条空记录）并返回分配和认领情况，
+	// 事务结束前并发的 Claim 不能生效；须在事务中调用
+	Lock(ctx context.Context, target model.ReviewTarget) (*model.ReviewClaim, error)
+	// List 批量查询，只返回有记录的目标
+	List(ctx context.Context, targets []model.ReviewTarget) (map[model.ReviewTarget]model.ReviewClaim, error)
+	// Claim 认领到 expiresAt 为止，已是自己认领的则续期；
+	// 他人认领且未过期时返回当前情况和 ErrClaimConflict
+	Claim(ctx context.Context, reviewerID int, target model.ReviewTarget, expiresAt time.Time) (*model.ReviewClaim, error)
+	// Release 放弃 reviewerID 自己的认领，返回是否有认领被放弃
+	Release(ctx context.Context, reviewerID int, target model.ReviewTarget) (bool, error)
+	// Distribute 把还没有分配的待审核资源按提交先后轮流分配给全部管理员，
+	// 接着上一次分配到的管理员继续，返回分配的数目
+	Distribute(ctx context.Context) (int, error)
+}
+
+type reviewClaimRepository struct {
+	db *Database
+}
+
+func NewReviewClaimRepository(db *Database) ReviewClaimRepository {
+	return &reviewClaimRepository{db: db}
+}
+
+const selectReviewClaim = `SELECT rc.resource_type, rc.resource_id, rc.assignee_id, COALESCE(a.nickname, a.username, ''),
+	rc.claimant_id, COALESCE(c.nickname, c.username, ''), rc.expires_at
+	FROM review_claims rc LEFT JOIN users a ON a.id = rc.assignee_id LEFT JOIN users c ON c.id = rc.claimant_id`
+
+// scanReviewClaim 读取一行认领记录，认领过期时清空认领人
+func scanReviewClaim(scanner interface{ Scan(...interface{}) error }, now time.Time) (*model.ReviewClaim, error) {
+	var claim model.ReviewClaim
+	var assigneeID, claimantID sql.NullInt64
+	var expiresAt sql.NullTime
+	if err := scanner.Scan(&claim.ResourceType, &claim.ResourceID, &assigneeID, &claim.Assignee,
+		&claimantID, &claim.Claimant, &expiresAt); err != nil {
+		return nil, err
+	}
+	claim.AssigneeID = int(assigneeID.Int64)
+	if claimantID.Valid && expiresAt.Valid && expiresAt.Time.After(now) {
+		claim.ClaimantID = int(claimantID.Int64)
+		claim.ExpiresTime = expiresAt.Time
+		claim.ExpiresAt = formatTime(expiresAt.Time)
+	} else {
+		claim.Claimant = ""
+	}
+	return &claim, nil
+}
+
+func (r *reviewClaimRepository) Get(ctx context.Context, target model.ReviewTarget) (*model.ReviewClaim, error) {
+	claim, err := scanReviewClaim(r.db.QueryRowContext(ctx, selectReviewClaim+` WHERE rc.resource_type = ? AND rc.resource_id = ?`,
+		target.ResourceType, target.ResourceID), time.Now())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get review claim: %w", err)
+	}
+	return claim, nil
+}
+
+func (r *reviewClaimRepository) Lock(ctx context.Context, target model.ReviewTarget) (*model.ReviewClaim, error) {
+	insert := r.db.Dialect.InsertIgnore("review_claims", []string{"resource_type", "resource_id"})
+	if _, err := r.db.ExecContext(ctx, insert, target.ResourceType, target.ResourceID); err != nil {
+		return nil, fmt.Errorf("failed to create review claim: %w", err)
+	}
+	// 只锁认领记录本身，不锁联查的用户行
+	var id int
+	if err := r.db.QueryRowContext(ctx, `SELECT resource_id FROM review_claims WHERE resource_type = ? AND resource_id = ?`+
+		r.db.Dialect.ForUpdate(), target.ResourceType, target.ResourceID).Scan(&id); err != nil {
+		return nil, fmt.Errorf("failed to lock review claim: %w", err)
+	}
+	return r.Get(ctx, target)
+}
+
+func (r *reviewClaimRepository) List(ctx context.Context, targets []model.ReviewTarget) (map[model.ReviewTarget]model.ReviewClaim, error) {
+	result := make(map[model.ReviewTarget]model.ReviewClaim, len(targets))
+	if len(targets) == 0 {
+		return result, nil
+	}
+
+	conditions := make([]string, 0, len(targets))
+	args := make([]interface{}, 0, 2*len(targets))
+	for _, target := range targets {
+		conditions = append(conditions, "(rc.resource_type = ? AND rc.resource_id = ?)")
+		args = append(args, target.ResourceType, target.ResourceID)
+	}
+	rows, err := r.db.QueryContext(ctx, selectReviewClaim+` WHERE `+strings.Join(conditions, " OR "), args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list review claims: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	for rows.Next() {
+		claim, err := scanReviewClaim(rows, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan review claim: %w", err)
+		}
+		result[claim.ReviewTarget] = *claim
+	}
+	return result, rows.Err()
+}
+
+func (r *reviewClaimRepository) Claim(ctx context.Context, reviewerID int, target model.ReviewTarget, expiresAt time.Time) (*model.ReviewClaim, error) {
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.ReviewClaim, error) {
+		insert := r.db.Dialect.InsertIgnore("review_claims", []string{"resource_type", "resource_id"})
+		if _, err := r.db.ExecContext(ctx, insert, target.ResourceType, target.ResourceID); err != nil {
+			return nil, fmt.Errorf("failed to create review claim: %w", err)
+		}
+
+		// 条件在同一条语句中检查，并发的认领只有一个能成功
+		d := r.db.Dialect
+		if _, err := r.db.ExecContext(ctx, `UPDATE review_claims SET claimant_id = ?, expires_at = ?
+			WHERE resource_type = ? AND resource_id = ?
+			AND (claimant_id IS NULL OR claimant_id = ? OR expires_at IS NULL OR `+d.TimeKey("expires_at")+` <= `+d.TimeKey("?")+`)`,
+			reviewerID, expiresAt, target.ResourceType, target.ResourceID, reviewerID, time.Now(),
+		); err != nil {
+			return nil, fmt.Errorf("failed to claim review: %w", err)
+		}
+
+		// 续期时新旧到期时间可能相同，MySQL 的影响行数为 0，因此重新读取判断结果
+		claim, err := r.Get(ctx, target)
+		if err != nil {
+			return nil, err
+		}
+		if claim.ClaimantID != reviewerID {
+			return claim, ErrClaimConflict
+		}
+		return claim, nil
+	})
+}
+
+func (r *reviewClaimRepository) Release(ctx context.Context, reviewerID int, target model.ReviewTarget) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `UPDATE review_claims SET claimant_id = NULL, expires_at = NULL
+		WHERE resource_type = ? AND resource_id = ? AND claimant_id = ?`,
+		target.ResourceType, target.ResourceID, reviewerID)
+	if err != nil {
+		return false, fmt.Errorf("failed to release review claim: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+// pendingReviewTargets 全部待审核资源的子查询，参数为四组审核队列中的状态值
+const pendingReviewTargets = `(
+	SELECT '` + model.ResourceTypeTool + `' AS resource_type, resource_id, created_at FROM tools
+	WHERE status IN (?, ?) AND deleted_at IS NULL
+	UNION ALL
+	SELECT '` + model.ResourceTypeProject + `' AS resource_type, project_id AS resource_id, created_at FROM projects
+	WHERE status IN (?, ?) AND deleted_at IS NULL
+	UNION ALL
+	SELECT p.resource_type, p.resource_id, p.created_at FROM ` + pendingCourseResources + `
+) q`
+
+func (r *reviewClaimRepository) Distribute(ctx context.Context) (int, error) {
+	return inTx(ctx, r.db, func(ctx context.Context) (int, error) {
+		reviewers, err := r.reviewers(ctx)
+		if err != nil || len(reviewers) == 0 {
+			return 0, err
+		}
+
+		var last sql.NullInt64
+		var seq int
+		err = r.db.QueryRowContext(ctx,
+			`SELECT assignee_id, assign_seq FROM review_claims ORDER BY assign_seq DESC LIMIT 1`+r.db.Dialect.ForUpdate()).Scan(&last, &seq)
+		if err != nil && err != sql.ErrNoRows {
+			return 0, fmt.Errorf("failed to get last assignment: %w", err)
+		}
+
+		var args []interface{}
+		for i := 0; i < 4; i++ {
+			args = append(args, model.StatusPending, model.StatusResubmitted)
+		}
+		rows, err := r.db.QueryContext(ctx, `SELECT q.resource_type, q.resource_id FROM `+pendingReviewTargets+`
+			WHERE NOT EXISTS (SELECT 1 FROM review_claims rc
+				WHERE rc.resource_type = q.resource_type AND rc.resource_id = q.resource_id AND rc.assignee_id IS NOT NULL)
+			ORDER BY q.created_at, q.resource_type, q.resource_id`, args...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get unassigned reviews: %w", err)
+		}
+		var targets []model.ReviewTarget
+		for rows.Next() {
+			var target model.ReviewTarget
+			if err := rows.Scan(&target.ResourceType, &target.ResourceID); err != nil {
+				rows.Close()
+				return 0, fmt.Errorf("failed to scan unassigned review: %w", err)
+			}
+			targets = append(targets, target)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+
+		upsert := r.db.Dialect.Upsert("review_claims",
+			[]string{"resource_type", "resource_id", "assignee_id", "assign_seq", "assigned_at"},
+			[]string{"resource_type", "resource_id"}, []string{"assignee_id", "assign_seq", "assigned_at"})
+		now := time.Now()
+		assignee := int(last.Int64)
+		for _, target := range targets {
+			assignee = nextReviewer(reviewers, assignee)
+			seq++
+			if _, err := r.db.ExecContext(ctx, upsert, target.ResourceType, target.ResourceID, assignee, seq, now); err != nil {
+				return 0, fmt.Errorf("failed to assign review: %w", err)
+			}
+		}
+		return len(targets), nil
+	})
+}
+
+// reviewers 全部管理员的ID，升序
+func (r *reviewClaimRepository) reviewers(ctx context.Context) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM users WHERE role = 'admin' ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list reviewers: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan reviewer: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// nextReviewer 轮流分配时 last 之后的下一位管理员，last 不是管理员时按ID顺序取后一位，到末尾后回到第一位
+func nextReviewer(reviewers []int, last int) int {
+	for _, id := range reviewers {
+		if id > last {
+			return id
+		}
+	}
+	return reviewers[0]
+}
+
+// claimWhere 待审核列表按认领情况筛选的条件，typeExpr、idExpr 为审核目标类型和ID的表达式
+func claimWhere(d Dialect, filter model.PendingFilter, typeExpr, idExpr string) ([]string, []interface{}) {
+	exists := `EXISTS (SELECT 1 FROM review_claims rc WHERE rc.resource_type = ` + typeExpr + ` AND rc.resource_id = ` + idExpr
+	active := ` AND ` + d.TimeKey("rc.expires_at") + ` > ` + d.TimeKey("?")
+	switch filter.Claim {
+	case model.ClaimUnclaimed:
+		return []string{"NOT " + exists + " AND rc.claimant_id IS NOT NULL" + active + ")"}, []interface{}{time.Now()}
+	case model.ClaimMine:
+		return []string{exists + " AND rc.claimant_id = ?" + active + ")"}, []interface{}{filter.ReviewerID, time.Now()}
+	case model.ClaimAssigned:
+		return []string{exists + " AND rc.assignee_id = ?)"}, []interface{}{filter.ReviewerID}
+	}
+	return nil, nil
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"softeng-platform/internal/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ApprovedResource 一个新上架的资源：审核通过的工具、项目，或新导入的课程
+type ApprovedResource struct {
+	ResourceType string
+	ResourceID   int
+	ApprovedAt   time.Time
+}
+
+// SavedSearchRepository 用户保存的检索及检索提醒的进度
+type SavedSearchRepository interface {
+	// Create 保存检索，写回 ID 和创建时间；CheckedAt 为开始检查的时间，之前上架的资源不会提醒
+	Create(ctx context.Context, search *model.SavedSearch) error
+	// List 按创建先后返回用户保存的检索，userID 为 0 时返回全部用户的
+	List(ctx context.Context, userID int) ([]model.SavedSearch, error)
+	// Delete 删除用户保存的检索，不存在或不属于该用户时返回 false
+	Delete(ctx context.Context, userID, id int) (bool, error)
+	// MarkChecked 记录已检查到 checkedAt；notifiedAt 不为空时同时记录提醒时间
+	MarkChecked(ctx context.Context, id int, checkedAt time.Time, notifiedAt *time.Time) error
+	// Approved 按上架时间先后返回 (since, until] 之间上架且未删除的资源
+	Approved(ctx context.Context, since, until time.Time) ([]ApprovedResource, error)
+}
+
+type savedSearchRepository struct {
+	db *Database
+}
+
+func NewSavedSearchRepository(db *Database) SavedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+func (r *savedSearchRepository) Create(ctx context.Context, search *model.SavedSearch) error {
+	query, err := json.Marshal(search.Query)
+	if err != nil {
+		return fmt.Errorf("failed to encode saved search query: %w", err)
+	}
+
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO saved_searches (user_id, name, query, channel, checked_at, created_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		search.UserID, search.Name, string(query), search.Channel, search.CheckedAt, now)
+	if err != nil {
+		return fmt.Errorf("failed to create saved search: %w", err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	search.ID = int(id)
+	search.CreatedAt = formatTime(now)
+	return nil
+}
+
+func (r *savedSearchRepository) List(ctx context.Context, userID int) ([]model.SavedSearch, error) {
+	query := `SELECT id, user_id, name, query, channel, checked_at, last_notified_at, created_at FROM saved_searches`
+	var args []interface{}
+	if userID != 0 {
+		query += ` WHERE user_id = ?`
+		args = append(args, userID)
+	}
+	rows, err := r.db.QueryContext(ctx, query+` ORDER BY id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved searches: %w", err)
+	}
+	defer rows.Close()
+
+	var searches []model.SavedSearch
+	for rows.Next() {
+		var s model.SavedSearch
+		var rawQuery string
+		var checkedAt, notifiedAt sql.NullTime
+		var createdAt time.Time
+		if err := rows.Scan(&s.ID, &s.UserID, &s.Name, &rawQuery, &s.Channel, &checkedAt, &notifiedAt, &createdAt); err != nil {
+			return nil, fmt.Errorf("failed to scan saved search: %w", err)
+		}
+		if err := json.Unmarshal([]byte(rawQuery), &s.Query); err != nil {
+			return nil, fmt.Errorf("failed to decode saved search %d: %w", s.ID, err)
+		}
+		s.CheckedAt = createdAt
+		if checkedAt.Valid {
+			s.CheckedAt = checkedAt.Time
+		}
+		s.LastNotifiedAt = formatNullTime(notifiedAt)
+		s.CreatedAt = formatTime(createdAt)
+		searches = append(searches, s)
+	}
+	return searches, rows.Err()
+}
+
+func (r *savedSearchRepository) Delete(ctx context.Context, userID, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM saved_searches WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete saved search: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
+
+func (r *savedSearchRepository) MarkChecked(ctx context.Context, id int, checkedAt time.Time, notifiedAt *time.Time) error {
+	query := `UPDATE saved_searches SET checked_at = ?`
+	args := []interface{}{checkedAt}
+	if notifiedAt != nil {
+		query += `, last_notified_at = ?`
+		args = append(args, *notifiedAt)
+	}
+	if _, err := r.db.ExecContext(ctx, query+` WHERE id = ?`, append(args, id)...); err != nil {
+		return fmt.Errorf("failed to update saved search: %w", err)
+	}
+	return nil
+}
+
+func (r *savedSearchRepository) Approved(ctx context.Context, since, until time.Time) ([]ApprovedResource, error) {
+	d := r.db.Dialect
+	var parts []string
+	var args []interface{}
+	for _, resourceType := range resourceTypes {
+		t := resourceTables[resourceType]
+		// 课程没有审核流程，按导入时间计算
+		column, status := "created_at", ""
+		if t.statusColumn != "" {
+			column, status = "audit_time", fmt.Sprintf(" AND %s = '%s'", t.statusColumn, model.StatusApproved)
+		}
+		parts = append(parts, fmt.Sprintf(`SELECT '%s' AS resource_type, %s AS resource_id, %s AS approved_at FROM %s
+			WHERE deleted_at IS NULL%s AND %s > %s AND %s <= %s`,
+			resourceType, t.idColumn, column, t.table, status,
+			d.TimeKey(column), d.TimeKey("?"), d.TimeKey(column), d.TimeKey("?")))
+		args = append(args, since, until)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `SELECT resource_type, resource_id, approved_at FROM (`+
+		strings.Join(parts, " UNION ALL ")+`) a ORDER BY `+d.TimeKey("approved_at")+`, resource_type, resource_id`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list approved resources: %w", err)
+	}
+	defer rows.Close()
+
+	var resources []ApprovedResource
+	for rows.Next() {
+		var res ApprovedResource
+		if err := rows.Scan(&res.ResourceType, &res.ResourceID, &res.ApprovedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan approved resource: %w", err)
+		}
+		resources = append(resources, res)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// SQLite 只按毫秒精度排序，同一毫秒内按读出的完整时间重新排定先后
+	sort.SliceStable(resources, func(i, j int) bool {
+		return resources[i].ApprovedAt.Before(resources[j].ApprovedAt)
+	})
+	return resources, nil
+}
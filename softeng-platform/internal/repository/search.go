@@ -0,0 +1,665 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/tagquery"
+	"strings"
+	"unicode"
+)
+
+const (
+	// nameMatchWeight 检索词出现在名称中时额外加的相关度，保证名称命中的结果排在前面
+	nameMatchWeight = 10
+	// relevanceScale 相关度乘以该值后取整，分页游标中以整数保存
+	relevanceScale = 1000000
+	// maxSearchTerms 一次检索最多使用的检索词个数，maxTermAlternatives 一个检索词最多的候选写法
+	maxSearchTerms      = 8
+	maxTermAlternatives = 6
+
+	// likeEscape LIKE 模式中的转义字符，与 likeContains 配合使用
+	likeEscape = " ESCAPE '!'"
+)
+
+// searchSorts 检索结果额外支持按相关度排序
+func searchSorts(idColumn string) map[string]keyset {
+	sorts := resourceSorts(idColumn)
+	sorts["relevance"] = keyset{name: "relevance", field: byRelevance, column: "relevance", idColumn: idColumn}
+	return sorts
+}
+
+// searchSort 有关键词时默认按相关度排序，没有关键词时按最新排序
+func searchSort(sorts map[string]keyset, sort, keyword string) keyset {
+	if sort == "" && len(SearchTerms(keyword)) > 0 {
+		sort = "relevance"
+	}
+	return lookupSort(sorts, sort)
+}
+
+// SearchTerms 把关键词按空白和全文检索的运算符拆分为检索词，去掉重复。
+// 一个检索词可以用 | 分隔多个候选写法（如拼写纠错给出的 "vsiual|visual"），命中其中任一个即可
+func SearchTerms(keyword string) [][]string {
+	fields := strings.FieldsFunc(strings.ToLower(keyword), func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("+-<>()~*\"@'", r)
+	})
+	var terms [][]string
+	seen := make(map[string]bool)
+	for _, f := range fields {
+		var term []string
+		for _, alt := range strings.Split(f, "|") {
+			if alt != "" && !seen[alt] && len(term) < maxTermAlternatives {
+				seen[alt] = true
+				term = append(term, alt)
+			}
+		}
+		if len(term) > 0 && len(terms) < maxSearchTerms {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
+
+// likeContains 返回包含 term 的 LIKE 模式，% 和 _ 按字面匹配
+func likeContains(term string) string {
+	return "%" + strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(term) + "%"
+}
+
+// textSearch 一类资源的检索方式：名称与简介走主表的全文索引，
+// 标签、技术栈、教师等关联表中的短文本用 LIKE 匹配
+type textSearch struct {
+	index      fullTextIndex
+	nameColumn string
+	// related 关联表的匹配条件，每个条件中只有一个 LIKE 占位符
+	related []string
+}
+
+var (
+	toolSearch = textSearch{
+		index:      toolTextIndex,
+		nameColumn: "resource_name",
+		related:    []string{"resource_id IN (SELECT tool_id FROM tool_tags WHERE tag LIKE ?" + likeEscape + ")"},
+	}
+	courseSearch = textSearch{
+		index:      courseTextIndex,
+		nameColumn: "name",
+		related:    []string{"course_id IN (SELECT course_id FROM course_teachers WHERE teacher_name LIKE ?" + likeEscape + ")"},
+	}
+	projectSearch = textSearch{
+		index:      projectTextIndex,
+		nameColumn: "name",
+		related:    []string{"project_id IN (SELECT project_id FROM project_tech_stack WHERE tech LIKE ?" + likeEscape + ")"},
+	}
+)
+
+// query 返回检索用的列表查询：在主表中按 where 和关键词筛选，并附加 relevance 列。
+// 每个检索词都必须命中某个字段；检索词短于全文索引的最小词长或方言不支持全文检索时，
+// 主表字段改用 LIKE 匹配
+func (s textSearch) query(d Dialect, columns, keyword string, where []string, args []interface{}) listQuery {
+	var scores []string
+	var scoreArgs []interface{}
+	conditions := append([]string{}, where...)
+	conditionArgs := append([]interface{}{}, args...)
+
+	for _, term := range SearchTerms(keyword) {
+		var match []string
+		var matchArgs []interface{}
+
+		for _, alt := range term {
+			pattern := likeContains(alt)
+			scores = append(scores, fmt.Sprintf("CASE WHEN %s LIKE ?%s THEN %d ELSE 0 END", s.nameColumn, likeEscape, nameMatchWeight))
+			scoreArgs = append(scoreArgs, pattern)
+
+			if text, ok := d.FullTextTerm(alt); ok {
+				match = append(match, d.FullTextMatch(s.index))
+				matchArgs = append(matchArgs, text)
+				scores = append(scores, d.FullTextScore(s.index))
+				scoreArgs = append(scoreArgs, text)
+			} else {
+				for _, column := range s.index.columns {
+					match = append(match, column+" LIKE ?"+likeEscape)
+					matchArgs = append(matchArgs, pattern)
+				}
+			}
+			for _, related := range s.related {
+				match = append(match, related)
+				matchArgs = append(matchArgs, pattern)
+			}
+		}
+
+		conditions = append(conditions, "("+strings.Join(match, " OR ")+")")
+		conditionArgs = append(conditionArgs, matchArgs...)
+	}
+
+	score := "0"
+	if len(scores) > 0 {
+		score = fmt.Sprintf("ROUND((%s) * %d)", strings.Join(scores, " + "), relevanceScale)
+	}
+	inner := "SELECT " + columns + ", " + score + " AS relevance FROM " + s.index.table
+	if len(conditions) > 0 {
+		inner += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	return listQuery{
+		from:   "(" + inner + ") ranked",
+		args:   append(scoreArgs, conditionArgs...),
+		ranked: true,
+	}
+}
+
+// listQuery 列表查询的来源。普通列表直接查询主表，筛选条件为 where；
+// 检索时查询带 relevance 列的派生表，筛选条件都已在派生表内
+type listQuery struct {
+	columns string
+	from    string
+	where   []string
+	args    []interface{}
+	ranked  bool
+}
+
+func tableQuery(columns, table string, where []string, args []interface{}) listQuery {
+	return listQuery{columns: columns, from: table, where: where, args: args}
+}
+
+func (q listQuery) selectFrom() string {
+	if q.ranked {
+		return "SELECT * FROM " + q.from
+	}
+	return "SELECT " + q.columns + " FROM " + q.from
+}
+
+// scanner 返回扫描一行的目标；检索结果多出的 relevance 列扫描到 relevance
+func (q listQuery) scanner(rows *Rows, relevance *float64) interface{ Scan(...interface{}) error } {
+	if !q.ranked {
+		return rows
+	}
+	return rankedScanner{rows: rows, relevance: relevance}
+}
+
+type rankedScanner struct {
+	rows      *Rows
+	relevance *float64
+}
+
+func (s rankedScanner) Scan(dest ...interface{}) error {
+	return s.rows.Scan(append(dest, s.relevance)...)
+}
+
+// memorySearch 内存实现的检索：每个检索词（的某个候选写法）都必须是名称、正文或关联字段的子串（不区分大小写），
+// 与 trigram / ngram 分词的匹配结果一致；相关度为各候选写法在名称中的命中数加权后与正文命中数之和
+func memorySearch(keyword, name string, text, related []string) (int64, bool) {
+	name = strings.ToLower(name)
+	var score int64
+	for _, term := range SearchTerms(keyword) {
+		found := false
+		for _, alt := range term {
+			inName := strings.Contains(name, alt)
+			inText := containsTerm(text, alt)
+			found = found || inName || inText || containsTerm(related, alt)
+			if inName {
+				score += nameMatchWeight
+			}
+			if inText {
+				score++
+			}
+		}
+		if !found {
+			return 0, false
+		}
+	}
+	return score * relevanceScale, true
+}
+
+// MatchKeyword 判断检索文档是否命中关键词，规则与内存实现的检索相同；关键词为空时总是命中
+func MatchKeyword(doc *model.SearchDocument, keyword string) bool {
+	_, ok := memorySearch(keyword, doc.Name, []string{doc.Description, doc.Detail}, doc.Tags)
+	return ok
+}
+
+func containsTerm(values []string, term string) bool {
+	for _, v := range values {
+		if strings.Contains(strings.ToLower(v), term) {
+			return true
+		}
+	}
+	return false
+}
+
+// maxFacetValues 每个分面最多返回的取值个数
+const maxFacetValues = 20
+
+// SearchRepository 跨工具、课程、项目的统一检索
+type SearchRepository interface {
+	// Search 返回混合排序的检索结果，三类资源的ID可能相同，以资源类型区分
+	Search(ctx context.Context, q model.SearchQuery, page pagination.Page) (*pagination.Result[model.SearchHit], error)
+	// Facets 统计各分面的取值及命中数，每个分面忽略自身的筛选条件
+	Facets(ctx context.Context, q model.SearchQuery) (*model.SearchFacets, error)
+	// Document 返回一个资源的索引文档，资源不存在、未审核或已删除时返回 nil
+	Document(ctx context.Context, resourceType string, resourceID int) (*model.SearchDocument, error)
+	// Documents 依次返回全部可检索资源的索引文档，用于重建索引
+	Documents(ctx context.Context, fn func(doc *model.SearchDocument) error) error
+}
+
+type searchRepository struct {
+	db *Database
+}
+
+func NewSearchRepository(db *Database) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// unifiedSearchSorts 统一检索的排序，同值时依次按资源类型和ID排列
+var unifiedSearchSorts = func() map[string]keyset {
+	sorts := searchSorts("resource_id")
+	for name, k := range sorts {
+		k.kindColumn = "resource_type"
+		sorts[name] = k
+	}
+	return sorts
+}()
+
+// searchSource 一类资源在统一检索中的查询方式
+type searchSource struct {
+	search   textSearch
+	idColumn string
+	// columns 统一的结果列：resource_id, resource_type, name, description, category, semester,
+	// views, loves, collections, created_at
+	columns string
+	// detail 索引文档中详情列的写法
+	detail string
+	// filter 返回该类资源的筛选条件，筛选了该类资源没有的字段时返回 false
+	filter func(q model.SearchQuery) ([]string, []interface{}, bool)
+	// categories、tags 批量加载子表中的分类和标签（见 loadStrings），为空表示取自主表或没有
+	categories string
+	tags       string
+}
+
+var searchSources = map[string]searchSource{
+	model.ResourceTypeTool: {
+		search:   toolSearch,
+		idColumn: "resource_id",
+		columns: `resource_id, 'tool' AS resource_type, resource_name AS name, COALESCE(description, '') AS description,
+			COALESCE(category, '') AS category, '' AS semester, views, loves, collections, created_at`,
+		detail: "COALESCE(description_detail, '')",
+		filter: func(q model.SearchQuery) ([]string, []interface{}, bool) {
+			if len(q.TechStack) > 0 || len(q.Semester) > 0 {
+				return nil, nil, false
+			}
+			where, args := toolFilters(q.Category, tagquery.AnyOf(q.Tags))
+			return where, args, true
+		},
+		tags: `SELECT tool_id, tag FROM tool_tags WHERE tool_id IN (%s) ORDER BY id`,
+	},
+	model.ResourceTypeCourse: {
+		search:   courseSearch,
+		idColumn: "course_id",
+		columns: `course_id AS resource_id, 'course' AS resource_type, name, '' AS description,
+			'' AS category, COALESCE(semester, '') AS semester, views, loves, collections, created_at`,
+		detail: "''",
+		filter: func(q model.SearchQuery) ([]string, []interface{}, bool) {
+			if len(q.Tags) > 0 || len(q.TechStack) > 0 {
+				return nil, nil, false
+			}
+			where := []string{"deleted_at IS NULL"}
+			var args []interface{}
+			where, args = appendIn(where, args, "course_id IN (SELECT course_id FROM course_categories WHERE category IN (%s))", q.Category)
+			where, args = appendIn(where, args, "semester IN (%s)", q.Semester)
+			return where, args, true
+		},
+		categories: `SELECT course_id, category FROM course_categories WHERE course_id IN (%s) ORDER BY id`,
+		tags:       `SELECT course_id, teacher_name FROM course_teachers WHERE course_id IN (%s) ORDER BY id`,
+	},
+	model.ResourceTypeProject: {
+		search:   projectSearch,
+		idColumn: "project_id",
+		columns: `project_id AS resource_id, 'project' AS resource_type, name, COALESCE(description, '') AS description,
+			COALESCE(category, '') AS category, '' AS semester, views, loves, collections, created_at`,
+		detail: "COALESCE(detail, '')",
+		filter: func(q model.SearchQuery) ([]string, []interface{}, bool) {
+			if len(q.Tags) > 0 || len(q.Semester) > 0 {
+				return nil, nil, false
+			}
+			where := []string{"status = ?", "deleted_at IS NULL"}
+			args := []interface{}{model.StatusApproved}
+			where, args = appendIn(where, args, "category IN (%s)", q.Category)
+			where, args = appendIn(where, args, "project_id IN (SELECT project_id FROM project_tech_stack WHERE tech IN (%s))", q.TechStack)
+			return where, args, true
+		},
+		tags: `SELECT project_id, tech FROM project_tech_stack WHERE project_id IN (%s) ORDER BY id`,
+	},
+}
+
+// appendIn values 不为空时追加 IN 条件，condition 中的 %s 替换为占位符
+func appendIn(where []string, args []interface{}, condition string, values []string) ([]string, []interface{}) {
+	if len(values) == 0 {
+		return where, args
+	}
+	where = append(where, fmt.Sprintf(condition, placeholders(len(values))))
+	for _, v := range values {
+		args = append(args, v)
+	}
+	return where, args
+}
+
+// searchMatch 返回 q 命中的一类资源，columns 为派生表中的列；该类资源被筛选条件排除时返回 false
+func searchMatch(d Dialect, resourceType, columns string, q model.SearchQuery) (listQuery, bool) {
+	if len(q.Types) > 0 && !containsString(q.Types, resourceType) {
+		return listQuery{}, false
+	}
+	source := searchSources[resourceType]
+	where, args, ok := source.filter(q)
+	if !ok {
+		return listQuery{}, false
+	}
+	return source.search.query(d, columns, q.Keyword, where, args), true
+}
+
+func (r *searchRepository) Search(ctx context.Context, q model.SearchQuery, page pagination.Page) (*pagination.Result[model.SearchHit], error) {
+	sort := searchSort(unifiedSearchSorts, q.Sort, q.Keyword)
+
+	var parts []string
+	var args []interface{}
+	for _, resourceType := range resourceTypes {
+		match, ok := searchMatch(r.db.Dialect, resourceType, searchSources[resourceType].columns, q)
+		if ok {
+			parts = append(parts, match.selectFrom())
+			args = append(args, match.args...)
+		}
+	}
+	if len(parts) == 0 {
+		return pagination.NewResult[model.SearchHit](nil, nil, normalizeLimit(page.Limit)), nil
+	}
+	from := "(" + strings.Join(parts, " UNION ALL ") + ") s"
+
+	query, queryArgs, err := pageQuery(r.db.Dialect, `SELECT resource_id, resource_type, name, description, category, semester,
+		views, loves, collections, created_at, relevance FROM `+from, nil, args, sort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search: %w", err)
+	}
+	defer rows.Close()
+
+	var hits []model.SearchHit
+	var keys []pagination.Key
+	for rows.Next() {
+		var relevance float64
+		hit, err := scanSearchHit(rows, &relevance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan search hit: %w", err)
+		}
+		hit.Relevance = int64(relevance)
+		hits = append(hits, *hit)
+		keys = append(keys, searchHitKey(sort, *hit))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewResult(hits, keys, normalizeLimit(page.Limit))
+	if err := r.loadRelations(ctx, result.Items); err != nil {
+		return nil, err
+	}
+	if result.Total, err = pageTotal(ctx, r.db, from, nil, args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// scanSearchHit 扫描 searchSource.columns 中的各列，extra 为其后附加的列
+func scanSearchHit(rows *Rows, extra ...interface{}) (*model.SearchHit, error) {
+	var hit model.SearchHit
+	var category string
+	dest := []interface{}{&hit.ResourceID, &hit.ResourceType, &hit.Name, &hit.Description, &category, &hit.Semester,
+		&hit.Views, &hit.Loves, &hit.Collections, &hit.CreatedAt}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	if category != "" {
+		hit.Category = []string{category}
+	}
+	hit.CreatedDate = formatTime(hit.CreatedAt)
+	return &hit, nil
+}
+
+func searchHitKey(sort keyset, hit model.SearchHit) pagination.Key {
+	key := sort.key(sortValues{
+		createdAt: hit.CreatedAt, views: hit.Views, loves: hit.Loves, collections: hit.Collections, relevance: hit.Relevance,
+	}, hit.ResourceID)
+	key.Kind = hit.ResourceType
+	return key
+}
+
+// loadRelations 按资源类型批量加载检索结果的分类和标签
+func (r *searchRepository) loadRelations(ctx context.Context, hits []model.SearchHit) error {
+	ids := make(map[string][]int)
+	for _, hit := range hits {
+		ids[hit.ResourceType] = append(ids[hit.ResourceType], hit.ResourceID)
+	}
+
+	categories := make(map[string]map[int][]string)
+	tags := make(map[string]map[int][]string)
+	for resourceType, typeIDs := range ids {
+		source := searchSources[resourceType]
+		var err error
+		if source.categories != "" {
+			if categories[resourceType], err = loadStrings(ctx, r.db, source.categories, typeIDs); err != nil {
+				return err
+			}
+		}
+		if source.tags != "" {
+			if tags[resourceType], err = loadStrings(ctx, r.db, source.tags, typeIDs); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range hits {
+		hit := &hits[i]
+		if values, ok := categories[hit.ResourceType]; ok {
+			hit.Category = values[hit.ResourceID]
+		}
+		hit.Category = nonNil(hit.Category)
+		hit.Tags = nonNil(tags[hit.ResourceType][hit.ResourceID])
+	}
+	return nil
+}
+
+// searchFacet 一个分面：各类资源中该分面的取值，以及如何忽略该分面自身的筛选条件
+type searchFacet struct {
+	name string
+	// sources 资源类型 -> 查询 (id, value) 的语句，没有该字段的资源类型不参与统计
+	sources map[string]string
+	without func(q model.SearchQuery) model.SearchQuery
+	set     func(f *model.SearchFacets, counts []model.FacetCount)
+}
+
+var searchFacets = []searchFacet{
+	{
+		name: "type",
+		sources: map[string]string{
+			model.ResourceTypeTool:    "SELECT resource_id AS id, 'tool' AS value FROM tools",
+			model.ResourceTypeCourse:  "SELECT course_id AS id, 'course' AS value FROM courses",
+			model.ResourceTypeProject: "SELECT project_id AS id, 'project' AS value FROM projects",
+		},
+		without: func(q model.SearchQuery) model.SearchQuery { q.Types = nil; return q },
+		set:     func(f *model.SearchFacets, counts []model.FacetCount) { f.Type = counts },
+	},
+	{
+		name: "category",
+		sources: map[string]string{
+			model.ResourceTypeTool:    "SELECT resource_id AS id, category AS value FROM tools",
+			model.ResourceTypeCourse:  "SELECT course_id AS id, category AS value FROM course_categories",
+			model.ResourceTypeProject: "SELECT project_id AS id, category AS value FROM projects",
+		},
+		without: func(q model.SearchQuery) model.SearchQuery { q.Category = nil; return q },
+		set:     func(f *model.SearchFacets, counts []model.FacetCount) { f.Category = counts },
+	},
+	{
+		name: "tag",
+		sources: map[string]string{
+			model.ResourceTypeTool: "SELECT tool_id AS id, tag AS value FROM tool_tags",
+		},
+		without: func(q model.SearchQuery) model.SearchQuery { q.Tags = nil; return q },
+		set:     func(f *model.SearchFacets, counts []model.FacetCount) { f.Tag = counts },
+	},
+	{
+		name: "techStack",
+		sources: map[string]string{
+			model.ResourceTypeProject: "SELECT project_id AS id, tech AS value FROM project_tech_stack",
+		},
+		without: func(q model.SearchQuery) model.SearchQuery { q.TechStack = nil; return q },
+		set:     func(f *model.SearchFacets, counts []model.FacetCount) { f.TechStack = counts },
+	},
+	{
+		name: "semester",
+		sources: map[string]string{
+			model.ResourceTypeCourse: "SELECT course_id AS id, semester AS value FROM courses",
+		},
+		without: func(q model.SearchQuery) model.SearchQuery { q.Semester = nil; return q },
+		set:     func(f *model.SearchFacets, counts []model.FacetCount) { f.Semester = counts },
+	},
+}
+
+func (r *searchRepository) Facets(ctx context.Context, q model.SearchQuery) (*model.SearchFacets, error) {
+	facets := &model.SearchFacets{}
+	for _, facet := range searchFacets {
+		counts, err := r.facetCounts(ctx, facet, facet.without(q))
+		if err != nil {
+			return nil, err
+		}
+		facet.set(facets, counts)
+	}
+	return facets, nil
+}
+
+// facetCounts 统计 q 命中的资源在一个分面上的取值，按命中数倒序
+func (r *searchRepository) facetCounts(ctx context.Context, facet searchFacet, q model.SearchQuery) ([]model.FacetCount, error) {
+	var parts []string
+	var args []interface{}
+	for _, resourceType := range resourceTypes {
+		source, ok := facet.sources[resourceType]
+		if !ok {
+			continue
+		}
+		match, ok := searchMatch(r.db.Dialect, resourceType, searchSources[resourceType].idColumn, q)
+		if !ok {
+			continue
+		}
+		parts = append(parts, fmt.Sprintf(`SELECT v.value, COUNT(DISTINCT v.id) AS n FROM (%s) v
+			WHERE v.value IS NOT NULL AND v.value <> '' AND v.id IN (SELECT %s FROM %s)
+			GROUP BY v.value`, source, searchSources[resourceType].idColumn, match.from))
+		args = append(args, match.args...)
+	}
+	counts := []model.FacetCount{}
+	if len(parts) == 0 {
+		return counts, nil
+	}
+
+	query := `SELECT value, SUM(n) AS total FROM (` + strings.Join(parts, " UNION ALL ") + `) f
+		GROUP BY value ORDER BY total DESC, value LIMIT ?`
+	rows, err := r.db.QueryContext(ctx, query, append(args, maxFacetValues)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count facets: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var count model.FacetCount
+		if err := rows.Scan(&count.Value, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan facet: %w", err)
+		}
+		counts = append(counts, count)
+	}
+	return counts, rows.Err()
+}
+
+func (r *searchRepository) Document(ctx context.Context, resourceType string, resourceID int) (*model.SearchDocument, error) {
+	if _, err := lookupResourceTable(resourceType); err != nil {
+		return nil, err
+	}
+	var doc *model.SearchDocument
+	err := r.documents(ctx, resourceType, resourceID, func(d *model.SearchDocument) error {
+		doc = d
+		return nil
+	})
+	return doc, err
+}
+
+func (r *searchRepository) Documents(ctx context.Context, fn func(doc *model.SearchDocument) error) error {
+	for _, resourceType := range resourceTypes {
+		if err := r.documents(ctx, resourceType, 0, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// documentBatch 读取索引文档时每批加载分类和标签的资源数
+const documentBatch = 500
+
+// documents 读取一类资源的索引文档，resourceID 不为 0 时只读取该资源
+func (r *searchRepository) documents(ctx context.Context, resourceType string, resourceID int, fn func(doc *model.SearchDocument) error) error {
+	source := searchSources[resourceType]
+	where, args, _ := source.filter(model.SearchQuery{})
+	if resourceID != 0 {
+		where = append(where, source.idColumn+" = ?")
+		args = append(args, resourceID)
+	}
+	query := fmt.Sprintf("SELECT %s, %s AS detail FROM %s WHERE %s AND %s > ? ORDER BY %s LIMIT ?",
+		source.columns, source.detail, source.search.index.table, strings.Join(where, " AND "), source.idColumn, source.idColumn)
+
+	// 每批读完后再加载分类和标签：SQLite 只有一个连接，遍历结果集时不能发起其他查询
+	afterID := 0
+	for {
+		docs, err := r.documentBatch(ctx, query, append(append([]interface{}{}, args...), afterID, documentBatch))
+		if err != nil {
+			return fmt.Errorf("failed to load %s documents: %w", resourceType, err)
+		}
+		for _, doc := range docs {
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+		if len(docs) < documentBatch {
+			return nil
+		}
+		afterID = docs[len(docs)-1].ResourceID
+	}
+}
+
+func (r *searchRepository) documentBatch(ctx context.Context, query string, args []interface{}) ([]*model.SearchDocument, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hits []model.SearchHit
+	var details []string
+	for rows.Next() {
+		var detail string
+		hit, err := scanSearchHit(rows, &detail)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, *hit)
+		details = append(details, detail)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if err := r.loadRelations(ctx, hits); err != nil {
+		return nil, err
+	}
+	docs := make([]*model.SearchDocument, len(hits))
+	for i := range hits {
+		docs[i] = &model.SearchDocument{SearchHit: hits[i], Detail: details[i]}
+	}
+	return docs, nil
+}
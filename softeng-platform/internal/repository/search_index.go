@@ -0,0 +1,437 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"strconv"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/keyword"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/analysis/token/lowercase"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/unicode"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/numeric"
+	"github.com/blevesearch/bleve/v2/search"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+// SearchIndex 统一检索使用的索引。资源审核通过、修改、删除或恢复，以及点赞、收藏和浏览量写入后
+// 调用 Update 同步，索引落后时由 reindex 命令重建
+type SearchIndex interface {
+	SearchRepository
+	// Update 重新索引一个资源；资源不存在、未审核或已删除时从索引中移除
+	Update(ctx context.Context, resourceType string, resourceID int) error
+	Close() error
+}
+
+// NewSQLSearchIndex 直接查询数据库的索引实现，没有配置嵌入式索引时使用
+func NewSQLSearchIndex(repo SearchRepository) SearchIndex {
+	return sqlSearchIndex{SearchRepository: repo}
+}
+
+type sqlSearchIndex struct {
+	SearchRepository
+}
+
+func (sqlSearchIndex) Update(ctx context.Context, resourceType string, resourceID int) error {
+	return nil
+}
+
+func (sqlSearchIndex) Close() error {
+	return nil
+}
+
+const (
+	// bleveIndexVersion 索引映射的版本，映射变化时加一，打开旧版本的索引时自动重建
+	bleveIndexVersion = "1"
+	bleveVersionKey   = "softeng_index_version"
+
+	// bleveTextAnalyzer 中文按单字和相邻两字切分，其他文字按词切分并转为小写
+	bleveTextAnalyzer = "softeng_text"
+	bleveBigramFilter = "softeng_cjk_bigram"
+
+	// bleveBatchSize 重建索引时每批写入的文档数
+	bleveBatchSize = 500
+)
+
+// bleveSorts 排序方式对应的索引字段，同值时按文档ID排列
+var bleveSorts = map[string]string{
+	"relevance":   "_score",
+	"latest":      "created",
+	"views":       "views",
+	"loves":       "loves",
+	"collections": "collections",
+}
+
+// bleveSearchIndex 基于 bleve 的嵌入式倒排索引，保存在本地目录中。
+// 文档ID为 "资源类型:资源ID"，只索引已审核、未删除的资源
+type bleveSearchIndex struct {
+	index  bleve.Index
+	source SearchRepository
+}
+
+// OpenBleveSearchIndex 打开 path 处的索引，索引不存在或版本过旧时先从 source 重建。
+// 同一个索引只能被一个进程打开，已被占用时返回错误
+func OpenBleveSearchIndex(ctx context.Context, path string, source SearchRepository) (SearchIndex, error) {
+	index, err := bleve.OpenUsing(path, map[string]interface{}{"bolt_timeout": "1s"})
+	if err == nil {
+		version, verr := index.GetInternal([]byte(bleveVersionKey))
+		if verr == nil && string(version) == bleveIndexVersion {
+			return &bleveSearchIndex{index: index, source: source}, nil
+		}
+		index.Close()
+		log.Printf("Search index at %s is outdated, rebuilding", path)
+	} else if !errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+
+	if _, err := RebuildBleveSearchIndex(ctx, path, source); err != nil {
+		return nil, err
+	}
+	index, err = bleve.OpenUsing(path, map[string]interface{}{"bolt_timeout": "1s"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open search index: %w", err)
+	}
+	return &bleveSearchIndex{index: index, source: source}, nil
+}
+
+// RebuildBleveSearchIndex 从 source 读取全部可检索资源，在临时目录中建好索引后替换 path，
+// 返回索引的文档数。path 处的索引不能被其他进程打开
+func RebuildBleveSearchIndex(ctx context.Context, path string, source SearchRepository) (int, error) {
+	// 先确认没有其他进程打开着旧索引，否则替换目录会破坏正在使用的索引
+	if old, err := bleve.OpenUsing(path, map[string]interface{}{"bolt_timeout": "1s"}); err == nil {
+		old.Close()
+	} else if !errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		return 0, fmt.Errorf("search index at %s is in use or damaged: %w", path, err)
+	}
+
+	tmp := path + ".new"
+	if err := os.RemoveAll(tmp); err != nil {
+		return 0, fmt.Errorf("failed to clean %s: %w", tmp, err)
+	}
+	index, err := bleve.New(tmp, bleveMapping())
+	if err != nil {
+		return 0, fmt.Errorf("failed to create search index: %w", err)
+	}
+
+	count := 0
+	batch := index.NewBatch()
+	err = source.Documents(ctx, func(doc *model.SearchDocument) error {
+		if err := batch.Index(bleveDocID(doc.ResourceType, doc.ResourceID), bleveDocument(doc)); err != nil {
+			return err
+		}
+		count++
+		if batch.Size() >= bleveBatchSize {
+			if err := index.Batch(batch); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+		return nil
+	})
+	if err == nil {
+		err = index.Batch(batch)
+	}
+	if err == nil {
+		err = index.SetInternal([]byte(bleveVersionKey), []byte(bleveIndexVersion))
+	}
+	if cerr := index.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.RemoveAll(tmp)
+		return 0, fmt.Errorf("failed to build search index: %w", err)
+	}
+
+	if err := os.RemoveAll(path); err != nil {
+		return 0, fmt.Errorf("failed to remove old search index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return 0, fmt.Errorf("failed to replace search index: %w", err)
+	}
+	return count, nil
+}
+
+func bleveMapping() mapping.IndexMapping {
+	m := bleve.NewIndexMapping()
+	if err := m.AddCustomTokenFilter(bleveBigramFilter, map[string]interface{}{
+		"type":           cjk.BigramName,
+		"output_unigram": true,
+	}); err != nil {
+		panic(err)
+	}
+	if err := m.AddCustomAnalyzer(bleveTextAnalyzer, map[string]interface{}{
+		"type":          custom.Name,
+		"tokenizer":     unicode.Name,
+		"token_filters": []string{cjk.WidthName, lowercase.Name, bleveBigramFilter},
+	}); err != nil {
+		panic(err)
+	}
+
+	text := func(store bool) *mapping.FieldMapping {
+		f := bleve.NewTextFieldMapping()
+		f.Analyzer = bleveTextAnalyzer
+		f.Store = store
+		f.IncludeInAll = false
+		return f
+	}
+	term := func() *mapping.FieldMapping {
+		f := bleve.NewTextFieldMapping()
+		f.Analyzer = keyword.Name
+		f.IncludeInAll = false
+		return f
+	}
+	number := func() *mapping.FieldMapping {
+		f := bleve.NewNumericFieldMapping()
+		f.IncludeInAll = false
+		return f
+	}
+
+	doc := bleve.NewDocumentStaticMapping()
+	doc.AddFieldMappingsAt("name", text(true))
+	doc.AddFieldMappingsAt("description", text(true))
+	doc.AddFieldMappingsAt("detail", text(false))
+	doc.AddFieldMappingsAt("related", text(false))
+	for _, name := range []string{"type", "category", "tag", "techStack", "semester", "labels"} {
+		doc.AddFieldMappingsAt(name, term())
+	}
+	for _, name := range []string{"id", "views", "loves", "collections", "created"} {
+		doc.AddFieldMappingsAt(name, number())
+	}
+	m.DefaultMapping = doc
+	return m
+}
+
+func bleveDocID(resourceType string, resourceID int) string {
+	return resourceType + ":" + strconv.Itoa(resourceID)
+}
+
+// bleveDocument 索引中的字段：related 为参与全文检索的标签、技术栈或教师，labels 原样保存用于展示，
+// tag、techStack 只有工具、项目才有，按它们筛选时自然排除其他资源
+func bleveDocument(doc *model.SearchDocument) map[string]interface{} {
+	fields := map[string]interface{}{
+		"type":        doc.ResourceType,
+		"id":          doc.ResourceID,
+		"name":        doc.Name,
+		"description": doc.Description,
+		"detail":      doc.Detail,
+		"related":     doc.Tags,
+		"labels":      doc.Tags,
+		"category":    doc.Category,
+		"views":       doc.Views,
+		"loves":       doc.Loves,
+		"collections": doc.Collections,
+		"created":     doc.CreatedAt.UnixMicro(),
+	}
+	switch doc.ResourceType {
+	case model.ResourceTypeTool:
+		fields["tag"] = doc.Tags
+	case model.ResourceTypeProject:
+		fields["techStack"] = doc.Tags
+	case model.ResourceTypeCourse:
+		fields["semester"] = doc.Semester
+	}
+	return fields
+}
+
+func (x *bleveSearchIndex) Update(ctx context.Context, resourceType string, resourceID int) error {
+	doc, err := x.source.Document(ctx, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	id := bleveDocID(resourceType, resourceID)
+	if doc == nil {
+		return x.index.Delete(id)
+	}
+	return x.index.Index(id, bleveDocument(doc))
+}
+
+func (x *bleveSearchIndex) Close() error {
+	return x.index.Close()
+}
+
+// Document 和 Documents 读取数据库中的文档，不经过索引
+func (x *bleveSearchIndex) Document(ctx context.Context, resourceType string, resourceID int) (*model.SearchDocument, error) {
+	return x.source.Document(ctx, resourceType, resourceID)
+}
+
+func (x *bleveSearchIndex) Documents(ctx context.Context, fn func(doc *model.SearchDocument) error) error {
+	return x.source.Documents(ctx, fn)
+}
+
+// bleveQuery 把检索条件转换为索引查询：每个检索词（的某个候选写法）都必须命中名称（加权）、简介、详情或关联字段，
+// 同一筛选字段的取值之间为“或”
+func bleveQuery(q model.SearchQuery) query.Query {
+	var must []query.Query
+	for _, term := range SearchTerms(q.Keyword) {
+		var fields []query.Query
+		for _, alt := range term {
+			for _, field := range []string{"name", "description", "detail", "related"} {
+				match := bleve.NewMatchQuery(alt)
+				match.SetField(field)
+				match.Analyzer = bleveTextAnalyzer
+				match.SetOperator(query.MatchQueryOperatorAnd)
+				if field == "name" {
+					match.SetBoost(nameMatchWeight)
+				}
+				fields = append(fields, match)
+			}
+		}
+		must = append(must, bleve.NewDisjunctionQuery(fields...))
+	}
+
+	for field, values := range map[string][]string{
+		"type": q.Types, "category": q.Category, "tag": q.Tags, "techStack": q.TechStack, "semester": q.Semester,
+	} {
+		if len(values) == 0 {
+			continue
+		}
+		var terms []query.Query
+		for _, v := range values {
+			t := bleve.NewTermQuery(v)
+			t.SetField(field)
+			terms = append(terms, t)
+		}
+		must = append(must, bleve.NewDisjunctionQuery(terms...))
+	}
+
+	if len(must) == 0 {
+		return bleve.NewMatchAllQuery()
+	}
+	return bleve.NewConjunctionQuery(must...)
+}
+
+func (x *bleveSearchIndex) Search(ctx context.Context, q model.SearchQuery, page pagination.Page) (*pagination.Result[model.SearchHit], error) {
+	by := searchSort(unifiedSearchSorts, q.Sort, q.Keyword)
+	field := bleveSorts[by.name]
+	if page.After != nil && page.After.Sort != by.name {
+		return nil, pagination.ErrInvalidCursor
+	}
+
+	limit := normalizeLimit(page.Limit)
+	req := bleve.NewSearchRequestOptions(bleveQuery(q), limit+1, 0, false)
+	req.Fields = []string{"*"}
+	req.SortByCustom(search.SortOrder{bleveSortField(field), &search.SortDocID{Desc: true}})
+	if page.After != nil {
+		req.SetSearchAfter([]string{bleveSortValue(field, page.After.Value), bleveDocID(page.After.Kind, page.After.ID)})
+	}
+
+	res, err := x.index.SearchInContext(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search index: %w", err)
+	}
+
+	var hits []model.SearchHit
+	var keys []pagination.Key
+	for _, doc := range res.Hits {
+		hit := bleveHit(doc.Fields)
+		key := by.key(sortValues{
+			createdAt: hit.CreatedAt, views: hit.Views, loves: hit.Loves, collections: hit.Collections,
+		}, hit.ResourceID)
+		if field == "_score" {
+			// 游标中保存分数的二进制表示，翻页时原样还原
+			key.Value = int64(math.Float64bits(doc.Score))
+		}
+		key.Kind = hit.ResourceType
+		hits = append(hits, hit)
+		keys = append(keys, key)
+	}
+
+	result := pagination.NewResult(hits, keys, limit)
+	if page.WithTotal {
+		total := int(res.Total)
+		result.Total = &total
+	}
+	return result, nil
+}
+
+func bleveSortField(field string) search.SearchSort {
+	if field == "_score" {
+		return &search.SortScore{Desc: true}
+	}
+	return &search.SortField{Field: field, Type: search.SortFieldAsNumber, Desc: true}
+}
+
+// bleveSortValue 把游标中的排序值还原为 bleve 比较时使用的字符串，创建时间在索引中以微秒保存
+func bleveSortValue(field string, value int64) string {
+	switch field {
+	case "_score":
+		return strconv.FormatFloat(math.Float64frombits(uint64(value)), 'g', -1, 64)
+	case "created":
+		value /= int64(time.Microsecond)
+	}
+	return string(numeric.MustNewPrefixCodedInt64(numeric.Float64ToInt64(float64(value)), 0))
+}
+
+// bleveHit 从保存的字段还原检索结果；只有一个值的数组字段在 bleve 中保存为单个值
+func bleveHit(fields map[string]interface{}) model.SearchHit {
+	str := func(name string) string {
+		s, _ := fields[name].(string)
+		return s
+	}
+	num := func(name string) int {
+		f, _ := fields[name].(float64)
+		return int(f)
+	}
+	list := func(name string) []string {
+		switch v := fields[name].(type) {
+		case string:
+			return []string{v}
+		case []interface{}:
+			result := make([]string, 0, len(v))
+			for _, item := range v {
+				if s, ok := item.(string); ok {
+					result = append(result, s)
+				}
+			}
+			return result
+		}
+		return []string{}
+	}
+
+	created := time.UnixMicro(int64(num("created")))
+	return model.SearchHit{
+		ResourceType: str("type"),
+		ResourceID:   num("id"),
+		Name:         str("name"),
+		Description:  str("description"),
+		Category:     list("category"),
+		Tags:         list("labels"),
+		Semester:     str("semester"),
+		Views:        num("views"),
+		Loves:        num("loves"),
+		Collections:  num("collections"),
+		CreatedDate:  formatTime(created),
+		CreatedAt:    created,
+	}
+}
+
+func (x *bleveSearchIndex) Facets(ctx context.Context, q model.SearchQuery) (*model.SearchFacets, error) {
+	facets := &model.SearchFacets{}
+	for _, facet := range searchFacets {
+		req := bleve.NewSearchRequestOptions(bleveQuery(facet.without(q)), 0, 0, false)
+		req.AddFacet(facet.name, bleve.NewFacetRequest(facet.name, maxFacetValues))
+		res, err := x.index.SearchInContext(ctx, req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count facets: %w", err)
+		}
+
+		counts := []model.FacetCount{}
+		if result, ok := res.Facets[facet.name]; ok && result.Terms != nil {
+			for _, t := range result.Terms.Terms() {
+				counts = append(counts, model.FacetCount{Value: t.Term, Count: t.Count})
+			}
+		}
+		facet.set(facets, counts)
+	}
+	return facets, nil
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+// SensitiveWordRepository 管理员维护的敏感词表
+type SensitiveWordRepository interface {
+	// List 按添加先后返回全部敏感词
+	List(ctx context.Context) ([]model.SensitiveWord, error)
+	// Save 添加敏感词，词已存在时只修改等级；写回 ID、添加人和添加时间
+	Save(ctx context.Context, word *model.SensitiveWord) error
+	// Delete 删除敏感词，不存在时返回 false
+	Delete(ctx context.Context, id int) (bool, error)
+}
+
+type sensitiveWordRepository struct {
+	db *Database
+}
+
+func NewSensitiveWordRepository(db *Database) SensitiveWordRepository {
+	return &sensitiveWordRepository{db: db}
+}
+
+func (r *sensitiveWordRepository) List(ctx context.Context) ([]model.SensitiveWord, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, word, level, created_by, created_at FROM sensitive_words ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sensitive words: %w", err)
+	}
+	defer rows.Close()
+
+	var words []model.SensitiveWord
+	for rows.Next() {
+		w, err := scanSensitiveWord(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan sensitive word: %w", err)
+		}
+		words = append(words, *w)
+	}
+	return words, rows.Err()
+}
+
+func scanSensitiveWord(scanner interface{ Scan(...interface{}) error }) (*model.SensitiveWord, error) {
+	var w model.SensitiveWord
+	var createdBy sql.NullInt64
+	var createdAt time.Time
+	if err := scanner.Scan(&w.ID, &w.Word, &w.Level, &createdBy, &createdAt); err != nil {
+		return nil, err
+	}
+	w.CreatedBy = int(createdBy.Int64)
+	w.CreatedAt = formatTime(createdAt)
+	return &w, nil
+}
+
+func (r *sensitiveWordRepository) Save(ctx context.Context, word *model.SensitiveWord) error {
+	upsert := r.db.Dialect.Upsert("sensitive_words",
+		[]string{"word", "level", "created_by", "created_at"},
+		[]string{"word"}, []string{"level"})
+
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := r.db.ExecContext(ctx, upsert, word.Word, word.Level, nullIfZero(word.CreatedBy), time.Now()); err != nil {
+			return fmt.Errorf("failed to save sensitive word: %w", err)
+		}
+		saved, err := scanSensitiveWord(r.db.QueryRowContext(ctx,
+			`SELECT id, word, level, created_by, created_at FROM sensitive_words WHERE word = ?`, word.Word))
+		if err != nil {
+			return fmt.Errorf("failed to get sensitive word: %w", err)
+		}
+		*word = *saved
+		return nil
+	})
+}
+
+func (r *sensitiveWordRepository) Delete(ctx context.Context, id int) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM sensitive_words WHERE id = ?`, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete sensitive word: %w", err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	return rows > 0, nil
+}
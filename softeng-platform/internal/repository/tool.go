@@ -2,16 +2,33 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/tagquery"
+	"time"
 )
 
 type ToolRepository interface {
-	GetTools(ctx context.Context, category, tags []string, sort, cursor string, pageSize int) ([]map[string]interface{}, error)
-	GetByID(ctx context.Context, resourceID string) (map[string]interface{}, error)
-	Search(ctx context.Context, keyword, cursor string, pageSize int) ([]map[string]interface{}, error)
-	Create(ctx context.Context, userID int, data map[string]interface{}) (map[string]interface{}, error)
-	AddLike(ctx context.Context, userID int, resourceID string) error
-	GetLikes(ctx context.Context, resourceID string) (int, error)
-	GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) // 新增方法
+	// GetTools 分类为其中任一个且标签满足 tags 的工具，category 为空、tags 为 nil 时不筛选
+	GetTools(ctx context.Context, category []string, tags tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Tool], error)
+	GetByID(ctx context.Context, resourceID int) (*model.Tool, error)
+	// Search 按关键词检索名称、简介、详情和标签，同时按分类、标签筛选，筛选规则同 GetTools；sort 为空时按相关度排序
+	Search(ctx context.Context, keyword string, category []string, tags tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Tool], error)
+	Create(ctx context.Context, userID int, req model.ToolSubmitRequest) (*model.ResourceReview, error)
+	LikeTool(ctx context.Context, userID, resourceID int) (*model.LikeStatus, error)
+	UnlikeTool(ctx context.Context, userID, resourceID int) (*model.LikeStatus, error)
+	CollectTool(ctx context.Context, userID, resourceID int) (*model.CollectStatus, error)
+	UncollectTool(ctx context.Context, userID, resourceID int) (*model.CollectStatus, error)
+	AddComment(ctx context.Context, userID, resourceID int, content string) (*model.Comment, error)
+	DeleteComment(ctx context.Context, userID, resourceID, commentID int) (*model.Comment, error)
+	ReplyComment(ctx context.Context, userID, resourceID, commentID int, content string) (*model.Comment, error)
+	DeleteReply(ctx context.Context, userID, resourceID, commentID int) (*model.Comment, error)
+	AddView(ctx context.Context, resourceID int) (int, error)
+	GetComments(ctx context.Context, resourceID int, page pagination.Page) (*pagination.Result[model.Comment], error)
+	GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) // 新增方法
 }
 
 type toolRepository struct {
@@ -22,105 +39,320 @@ func NewToolRepository(db *Database) ToolRepository {
 	return &toolRepository{db: db}
 }
 
-func (r *toolRepository) GetTools(ctx context.Context, category, tags []string, sort, cursor string, pageSize int) ([]map[string]interface{}, error) {
-	// 实现获取工具列表的逻辑
-	return []map[string]interface{}{
-		{
-			"resourceId":   1,
-			"resourceType": "tool",
-			"resourceName": "Sample Tool",
-			"description":  "This is a sample tool",
-			"image":        "https://example.com/image.jpg",
-			"catagory":     "软件开发",
-			"tags":         []string{"免费", "AI工具"},
-			"views":        100,
-			"collections":  50,
-			"loves":        25,
-			"contributors": []string{"user1", "user2"},
-			"createdat":    "2023-01-01",
-		},
-	}, nil
+const toolColumns = `
+	resource_id, resource_name, COALESCE(resource_link, ''), COALESCE(description, ''),
+	COALESCE(description_detail, ''), COALESCE(category, ''), views, collections, loves, created_at, version
+`
+
+var (
+	toolSorts       = resourceSorts("resource_id")
+	toolSearchSorts = searchSorts("resource_id")
+)
+
+// pendingToolSort 待审核工具按提交时间先后排列
+var pendingToolSort = keyset{name: "pending", field: byCreatedAt, column: "t.created_at", idColumn: "t.resource_id", asc: true}
+
+// toolRow 工具表的一行，附带分页需要的原始创建时间
+type toolRow struct {
+	model.Tool
+	createdAt time.Time
 }
 
-func (r *toolRepository) GetByID(ctx context.Context, resourceID string) (map[string]interface{}, error) {
-	// 实现根据ID获取工具详情的逻辑
-	return map[string]interface{}{
-		"resourceId":         resourceID,
-		"resourceType":       "tool",
-		"resourceName":       "Sample Tool",
-		"resourceLink":       "https://example.com",
-		"description_detail": "Detailed description of the tool",
-		"catagory":           "软件开发",
-		"image":              []string{"https://example.com/image1.jpg", "https://example.com/image2.jpg"},
-		"tags":               []string{"免费", "AI工具", "高效"},
-		"views":              150,
-		"collections":        75,
-		"loves":              30,
-		"iscollected":        false,
-		"isliked":            false,
-		"comment_count":      5,
-		"comments":           []map[string]interface{}{},
-		"createdDate":        "2023-01-01",
-	}, nil
+func (row toolRow) sortValues() sortValues {
+	return sortValues{createdAt: row.createdAt, views: row.Views, loves: row.Loves, collections: row.Collections}
 }
 
-func (r *toolRepository) Search(ctx context.Context, keyword, cursor string, pageSize int) ([]map[string]interface{}, error) {
-	// 实现搜索工具的逻辑
-	return []map[string]interface{}{
-		{
-			"resourceId":   2,
-			"resourceType": "tool",
-			"resourceName": "Search Result Tool",
-			"description":  "This tool matches the search: " + keyword,
-			"image":        "https://example.com/search.jpg",
-			"catagory":     "论文阅读",
-			"tags":         []string{"搜索", "工具"},
-			"views":        80,
-			"collections":  40,
-			"loves":        20,
-			"contributors": []string{"user3"},
-			"createdat":    "2023-02-01",
-		},
-	}, nil
+func (r *toolRepository) GetTools(ctx context.Context, category []string, tags tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Tool], error) {
+	where, args := toolFilters(category, tags)
+	return r.pageTools(ctx, tableQuery(toolColumns, "tools", where, args), lookupSort(toolSorts, sort), page)
 }
 
-func (r *toolRepository) Create(ctx context.Context, userID int, data map[string]interface{}) (map[string]interface{}, error) {
-	// 实现创建工具的逻辑
-	return map[string]interface{}{
-		"resourceId":   3,
-		"resourceType": "tool",
-		"resource":     "https://example.com/new-tool",
-		"auditStatus":  "pending",
-		"submitTime":   "2023-12-01 10:00:00",
-		"auditTime":    nil,
-		"rejectReason": nil,
-	}, nil
+// toolFilters 已审核、未删除且符合分类和标签的工具
+func toolFilters(category []string, tags tagquery.Expr) ([]string, []interface{}) {
+	where := []string{"status = ?", "deleted_at IS NULL"}
+	args := []interface{}{model.StatusApproved}
+
+	if len(category) > 0 {
+		where = append(where, "category IN ("+placeholders(len(category))+")")
+		for _, c := range category {
+			args = append(args, c)
+		}
+	}
+	if tags != nil {
+		condition, tagArgs := tagquery.SQL(tags, "resource_id IN (SELECT tool_id FROM tool_tags WHERE tag = ?)")
+		where = append(where, condition)
+		args = append(args, tagArgs...)
+	}
+	return where, args
 }
 
-func (r *toolRepository) AddLike(ctx context.Context, userID int, resourceID string) error {
-	// 实现添加点赞的逻辑
-	return nil
+func (r *toolRepository) GetByID(ctx context.Context, resourceID int) (*model.Tool, error) {
+	query := `SELECT ` + toolColumns + ` FROM tools WHERE resource_id = ? AND status = ? AND deleted_at IS NULL`
+
+	row, err := scanTool(r.db.QueryRowContext(ctx, query, resourceID, model.StatusApproved))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get tool by id: %w", err)
+	}
+
+	tools := []model.Tool{row.Tool}
+	if err := r.loadRelations(ctx, tools); err != nil {
+		return nil, err
+	}
+	tool := &tools[0]
+
+	tool.Comments, err = listComments(ctx, r.db, model.ResourceTypeTool, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	tool.CommentCount, err = countComments(ctx, r.db, model.ResourceTypeTool, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return tool, nil
+}
+
+func (r *toolRepository) Search(ctx context.Context, keyword string, category []string, tags tagquery.Expr, sort string, page pagination.Page) (*pagination.Result[model.Tool], error) {
+	where, args := toolFilters(category, tags)
+	q := toolSearch.query(r.db.Dialect, toolColumns, keyword, where, args)
+	return r.pageTools(ctx, q, searchSort(toolSearchSorts, sort, keyword), page)
 }
 
-func (r *toolRepository) GetLikes(ctx context.Context, resourceID string) (int, error) {
-	// 实现获取点赞数的逻辑
-	return 1, nil
-}
-
-func (r *toolRepository) GetPending(ctx context.Context, cursor, limit int) ([]map[string]interface{}, error) {
-	// 实现获取待审核工具的逻辑
-	return []map[string]interface{}{
-		{
-			"submitor":     "user1",
-			"submitDate":   "2023-12-01 10:00:00",
-			"reourceId":    1,
-			"resourceType": "tool",
-			"resourcename": "新工具",
-			"catagory":     "软件开发",
-			"link":         "https://example.com/tool",
-			"description":  "这是一个新工具",
-			"tags":         []string{"AI", "免费"},
-			"file":         "tool.zip",
-		},
+// Create 在一个事务中写入工具及其标签、贡献者
+func (r *toolRepository) Create(ctx context.Context, userID int, req model.ToolSubmitRequest) (*model.ResourceReview, error) {
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.ResourceReview, error) {
+		return r.create(ctx, userID, req)
+	})
+}
+
+func (r *toolRepository) create(ctx context.Context, userID int, req model.ToolSubmitRequest) (*model.ResourceReview, error) {
+	now := time.Now()
+	result, err := r.db.ExecContext(ctx,
+		`INSERT INTO tools (resource_type, resource_name, resource_link, description, description_detail, category, status, submitter_id, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		model.ResourceTypeTool, req.Name, req.Link, req.Description, req.DescriptionDetail, req.Category,
+		model.StatusPending, userID, now, now,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create tool: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+
+	for _, tag := range uniqueStrings(req.Tags) {
+		if _, err := r.db.ExecContext(ctx, `INSERT INTO tool_tags (tool_id, tag) VALUES (?, ?)`, id, tag); err != nil {
+			return nil, fmt.Errorf("failed to create tool tag: %w", err)
+		}
+	}
+
+	if _, err := r.db.ExecContext(ctx, `INSERT INTO tool_contributors (tool_id, user_id) VALUES (?, ?)`, id, userID); err != nil {
+		return nil, fmt.Errorf("failed to create tool contributor: %w", err)
+	}
+
+	return &model.ResourceReview{
+		ResourceID:   int(id),
+		ResourceType: model.ResourceTypeTool,
+		Resource:     req.Link,
+		AuditStatus:  model.StatusPending,
+		SubmitTime:   formatTime(now),
 	}, nil
 }
+
+func (r *toolRepository) LikeTool(ctx context.Context, userID, resourceID int) (*model.LikeStatus, error) {
+	return setLike(ctx, r.db, userID, model.ResourceTypeTool, resourceID, true)
+}
+
+func (r *toolRepository) UnlikeTool(ctx context.Context, userID, resourceID int) (*model.LikeStatus, error) {
+	return setLike(ctx, r.db, userID, model.ResourceTypeTool, resourceID, false)
+}
+
+func (r *toolRepository) CollectTool(ctx context.Context, userID, resourceID int) (*model.CollectStatus, error) {
+	return setCollect(ctx, r.db, userID, model.ResourceTypeTool, resourceID, true)
+}
+
+func (r *toolRepository) UncollectTool(ctx context.Context, userID, resourceID int) (*model.CollectStatus, error) {
+	return setCollect(ctx, r.db, userID, model.ResourceTypeTool, resourceID, false)
+}
+
+func (r *toolRepository) AddComment(ctx context.Context, userID, resourceID int, content string) (*model.Comment, error) {
+	return addComment(ctx, r.db, userID, model.ResourceTypeTool, resourceID, nil, content)
+}
+
+func (r *toolRepository) DeleteComment(ctx context.Context, userID, resourceID, commentID int) (*model.Comment, error) {
+	return deleteComment(ctx, r.db, userID, model.ResourceTypeTool, resourceID, commentID, false)
+}
+
+func (r *toolRepository) ReplyComment(ctx context.Context, userID, resourceID, commentID int, content string) (*model.Comment, error) {
+	return addComment(ctx, r.db, userID, model.ResourceTypeTool, resourceID, &commentID, content)
+}
+
+func (r *toolRepository) DeleteReply(ctx context.Context, userID, resourceID, commentID int) (*model.Comment, error) {
+	return deleteComment(ctx, r.db, userID, model.ResourceTypeTool, resourceID, commentID, true)
+}
+
+func (r *toolRepository) AddView(ctx context.Context, resourceID int) (int, error) {
+	return addView(ctx, r.db, model.ResourceTypeTool, resourceID)
+}
+
+func (r *toolRepository) GetComments(ctx context.Context, resourceID int, page pagination.Page) (*pagination.Result[model.Comment], error) {
+	return pageComments(ctx, r.db, model.ResourceTypeTool, resourceID, page)
+}
+
+func (r *toolRepository) GetPending(ctx context.Context, filter model.PendingFilter, page pagination.Page) (*pagination.Result[model.Submit], error) {
+	where := []string{"t.status IN (?, ?)", "t.deleted_at IS NULL"}
+	args := []interface{}{model.StatusPending, model.StatusResubmitted}
+	claim, claimArgs := claimWhere(r.db.Dialect, filter, "'"+model.ResourceTypeTool+"'", "t.resource_id")
+	where = append(where, claim...)
+	args = append(args, claimArgs...)
+
+	query, queryArgs, err := pageQuery(r.db.Dialect, `
+		SELECT t.resource_id, t.resource_name, COALESCE(t.category, ''), COALESCE(t.resource_link, ''),
+			COALESCE(t.description, ''), t.created_at, COALESCE(u.nickname, u.username, '')
+		FROM tools t LEFT JOIN users u ON u.id = t.submitter_id`, where, args, pendingToolSort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending tools: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.Submit
+	var keys []pagination.Key
+	var ids []int
+	for rows.Next() {
+		var item model.Submit
+		var createdAt time.Time
+		if err := rows.Scan(&item.ResourceID, &item.ResourceName, &item.Category, &item.Link,
+			&item.Description, &createdAt, &item.Submitor); err != nil {
+			return nil, fmt.Errorf("failed to scan pending tool: %w", err)
+		}
+		item.ResourceType = model.ResourceTypeTool
+		item.SubmitDate = formatTime(createdAt)
+		items = append(items, item)
+		keys = append(keys, pendingToolSort.key(sortValues{createdAt: createdAt}, item.ResourceID))
+		ids = append(ids, item.ResourceID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tags, err := loadStrings(ctx, r.db, `SELECT tool_id, tag FROM tool_tags WHERE tool_id IN (%s) ORDER BY id`, ids)
+	if err != nil {
+		return nil, err
+	}
+	for i := range items {
+		items[i].Tags = nonNil(tags[items[i].ResourceID])
+	}
+
+	result := pagination.NewResult(items, keys, normalizeLimit(page.Limit))
+	if result.Total, err = pageTotal(ctx, r.db, "tools t", where, args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func scanTool(scanner interface{ Scan(...interface{}) error }) (*toolRow, error) {
+	var row toolRow
+	tool := &row.Tool
+	err := scanner.Scan(
+		&tool.ResourceID,
+		&tool.ResourceName,
+		&tool.ResourceLink,
+		&tool.Description,
+		&tool.DescriptionDetail,
+		&tool.Category,
+		&tool.Views,
+		&tool.Collections,
+		&tool.Loves,
+		&row.createdAt,
+		&tool.Version,
+	)
+	if err != nil {
+		return nil, err
+	}
+	tool.ResourceType = model.ResourceTypeTool
+	tool.CreatedDate = formatTime(row.createdAt)
+	tool.Comments = []model.Comment{}
+	return &row, nil
+}
+
+// pageTools 按筛选条件和排序查询一页已加载关联数据的工具
+func (r *toolRepository) pageTools(ctx context.Context, q listQuery, sort keyset, page pagination.Page) (*pagination.Result[model.Tool], error) {
+	query, queryArgs, err := pageQuery(r.db.Dialect, q.selectFrom(), q.where, q.args, sort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tools: %w", err)
+	}
+	defer rows.Close()
+
+	var tools []model.Tool
+	var keys []pagination.Key
+	var relevance float64
+	for rows.Next() {
+		row, err := scanTool(q.scanner(rows, &relevance))
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan tool: %w", err)
+		}
+		values := row.sortValues()
+		values.relevance = int64(relevance)
+		tools = append(tools, row.Tool)
+		keys = append(keys, sort.key(values, row.ResourceID))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewResult(tools, keys, normalizeLimit(page.Limit))
+	if err := r.loadRelations(ctx, result.Items); err != nil {
+		return nil, err
+	}
+	if result.Total, err = pageTotal(ctx, r.db, q.from, q.where, q.args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// loadRelations 批量加载工具的标签、图片和贡献者
+func (r *toolRepository) loadRelations(ctx context.Context, tools []model.Tool) error {
+	ids := make([]int, len(tools))
+	for i, t := range tools {
+		ids[i] = t.ResourceID
+	}
+
+	tags, err := loadStrings(ctx, r.db, `SELECT tool_id, tag FROM tool_tags WHERE tool_id IN (%s) ORDER BY id`, ids)
+	if err != nil {
+		return err
+	}
+	images, err := loadStrings(ctx, r.db, `SELECT tool_id, image_url FROM tool_images WHERE tool_id IN (%s) ORDER BY sort_order, id`, ids)
+	if err != nil {
+		return err
+	}
+	contributors, err := loadStrings(ctx, r.db, `
+		SELECT tc.tool_id, COALESCE(u.nickname, u.username)
+		FROM tool_contributors tc JOIN users u ON u.id = tc.user_id
+		WHERE tc.tool_id IN (%s) ORDER BY tc.id`, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range tools {
+		id := tools[i].ResourceID
+		tools[i].Tags = nonNil(tags[id])
+		tools[i].Image = nonNil(images[id])
+		tools[i].Contributors = nonNil(contributors[id])
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"strings"
+	"time"
+)
+
+// TrashRepository 工具、课程、项目的回收站。
+// ownerID 不为 0 时只能操作该用户作为贡献者/作者的资源，为 0 时不限制（管理员操作）。
+// version 为 0 时不检查版本，否则与资源当前版本不一致时返回 ErrVersionConflict
+type TrashRepository interface {
+	// Delete 将资源移入回收站；资源不存在、已在回收站或不属于 ownerID 时返回 nil
+	Delete(ctx context.Context, ownerID, operatorID int, resourceType string, resourceID, version int) (*model.TrashItem, error)
+	// Restore 将资源移出回收站，返回恢复前的回收站记录（Version 为恢复后的版本）；找不到时返回 nil
+	Restore(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error)
+	// Purge 永久删除回收站中的资源；找不到时返回 nil
+	Purge(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error)
+	// Get 返回资源当前的删除状态和版本，不在回收站中的资源 DeletedAt 为空；找不到时返回 nil
+	Get(ctx context.Context, ownerID int, resourceType string, resourceID int) (*model.TrashItem, error)
+	// List 分页列出回收站中的资源，按删除时间倒序
+	List(ctx context.Context, ownerID int, page pagination.Page) (*pagination.Result[model.TrashItem], error)
+	// PurgeExpired 永久删除 before 之前进入回收站的资源，返回删除的数量
+	PurgeExpired(ctx context.Context, before time.Time) (int, error)
+}
+
+type trashRepository struct {
+	db *Database
+}
+
+func NewTrashRepository(db *Database) TrashRepository {
+	return &trashRepository{db: db}
+}
+
+// trashSort 回收站按删除时间倒序（删除时间和创建时间一样按时间列编码），
+// 三类资源的ID可能相同，以资源类型区分
+var trashSort = keyset{
+	name: "deleted", field: byCreatedAt, column: "p.deleted_at",
+	kindColumn: "p.resource_type", idColumn: "p.resource_id",
+}
+
+// ownedBy 资源属于某个用户的条件，参数为用户ID
+func ownedBy(t resourceTable, idColumn string) string {
+	return fmt.Sprintf(" AND %s IN (SELECT %s FROM %s WHERE user_id = ?)", idColumn, t.ownerColumn, t.ownerTable)
+}
+
+// notDeleted 多态表（收藏、点赞等）中引用的资源未被删除的条件
+func notDeleted(typeColumn, idColumn string) string {
+	conditions := make([]string, len(resourceTypes))
+	for i, resourceType := range resourceTypes {
+		t := resourceTables[resourceType]
+		conditions[i] = fmt.Sprintf("NOT EXISTS (SELECT 1 FROM %s d WHERE %s = '%s' AND d.%s = %s AND d.deleted_at IS NOT NULL)",
+			t.table, typeColumn, resourceType, t.idColumn, idColumn)
+	}
+	return strings.Join(conditions, " AND ")
+}
+
+// trashState 查询回收站记录时对资源删除状态的要求
+type trashState int
+
+const (
+	trashAny trashState = iota
+	trashLive
+	trashDeleted
+)
+
+// trashSelect 一类资源的回收站记录，列为 (resource_id, resource_type, name, status, deleted_at, deleter, version)
+func trashSelect(resourceType string, ownerID int, state trashState) (string, []interface{}) {
+	t := resourceTables[resourceType]
+	status := "'" + model.StatusApproved + "'"
+	if t.statusColumn != "" {
+		status = "COALESCE(r." + t.statusColumn + ", '')"
+	}
+
+	query := fmt.Sprintf(`SELECT r.%s AS resource_id, '%s' AS resource_type, r.%s AS name, %s AS status,
+		r.deleted_at, COALESCE(u.nickname, u.username, '') AS deleter, r.version
+		FROM %s r LEFT JOIN users u ON u.id = r.deleted_by
+		WHERE 1 = 1`, t.idColumn, resourceType, t.nameColumn, status, t.table)
+	switch state {
+	case trashLive:
+		query += " AND r.deleted_at IS NULL"
+	case trashDeleted:
+		query += " AND r.deleted_at IS NOT NULL"
+	}
+	var args []interface{}
+	if ownerID != 0 {
+		query += ownedBy(t, "r."+t.idColumn)
+		args = append(args, ownerID)
+	}
+	return query, args
+}
+
+func scanTrashItem(scanner interface{ Scan(...interface{}) error }) (*model.TrashItem, error) {
+	var item model.TrashItem
+	var deletedAt sql.NullTime
+	if err := scanner.Scan(&item.ResourceID, &item.ResourceType, &item.ResourceName, &item.AuditStatus,
+		&deletedAt, &item.DeletedBy, &item.Version); err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		item.DeletedTime = deletedAt.Time
+		item.DeletedAt = formatTime(deletedAt.Time)
+	}
+	return &item, nil
+}
+
+func (r *trashRepository) Delete(ctx context.Context, ownerID, operatorID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	t, err := lookupResourceTable(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.TrashItem, error) {
+		now := time.Now()
+		match, matchArgs := versionMatch("version", version)
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = ?, deleted_by = ?, updated_at = ?, version = version + 1 WHERE %s = ? AND deleted_at IS NULL",
+			t.table, t.idColumn) + match
+		args := append([]interface{}{now, operatorID, now, resourceID}, matchArgs...)
+		if ownerID != 0 {
+			query += ownedBy(t, t.idColumn)
+			args = append(args, ownerID)
+		}
+
+		result, err := r.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to delete %s: %w", resourceType, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rows == 0 {
+			// 区分资源不存在和版本不一致
+			current, err := r.get(ctx, ownerID, resourceType, resourceID, trashLive)
+			if err != nil || current == nil {
+				return nil, err
+			}
+			return nil, ErrVersionConflict
+		}
+		return r.get(ctx, 0, resourceType, resourceID, trashDeleted)
+	})
+}
+
+func (r *trashRepository) Restore(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	t, err := lookupResourceTable(resourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.TrashItem, error) {
+		item, err := r.get(ctx, ownerID, resourceType, resourceID, trashDeleted)
+		if err != nil || item == nil {
+			return nil, err
+		}
+
+		match, matchArgs := versionMatch("version", version)
+		query := fmt.Sprintf("UPDATE %s SET deleted_at = NULL, deleted_by = NULL, updated_at = ?, version = version + 1 WHERE %s = ? AND deleted_at IS NOT NULL",
+			t.table, t.idColumn) + match
+		result, err := r.db.ExecContext(ctx, query, append([]interface{}{time.Now(), resourceID}, matchArgs...)...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore %s: %w", resourceType, err)
+		}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rows == 0 {
+			return nil, ErrVersionConflict
+		}
+
+		query = fmt.Sprintf("SELECT version FROM %s WHERE %s = ?", t.table, t.idColumn)
+		if err := r.db.QueryRowContext(ctx, query, resourceID).Scan(&item.Version); err != nil {
+			return nil, fmt.Errorf("failed to get %s version: %w", resourceType, err)
+		}
+		return item, nil
+	})
+}
+
+func (r *trashRepository) Purge(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	if _, err := lookupResourceTable(resourceType); err != nil {
+		return nil, err
+	}
+
+	return inTx(ctx, r.db, func(ctx context.Context) (*model.TrashItem, error) {
+		item, err := r.get(ctx, ownerID, resourceType, resourceID, trashDeleted)
+		if err != nil || item == nil {
+			return nil, err
+		}
+		purged, err := r.purge(ctx, resourceType, resourceID, nil, version)
+		if err != nil {
+			return nil, err
+		}
+		if !purged {
+			return nil, ErrVersionConflict
+		}
+		return item, nil
+	})
+}
+
+func (r *trashRepository) Get(ctx context.Context, ownerID int, resourceType string, resourceID int) (*model.TrashItem, error) {
+	if _, err := lookupResourceTable(resourceType); err != nil {
+		return nil, err
+	}
+	return r.get(ctx, ownerID, resourceType, resourceID, trashAny)
+}
+
+func (r *trashRepository) List(ctx context.Context, ownerID int, page pagination.Page) (*pagination.Result[model.TrashItem], error) {
+	parts := make([]string, len(resourceTypes))
+	var args []interface{}
+	for i, resourceType := range resourceTypes {
+		query, queryArgs := trashSelect(resourceType, ownerID, trashDeleted)
+		parts[i] = query
+		args = append(args, queryArgs...)
+	}
+	from := "(" + strings.Join(parts, " UNION ALL ") + ") p"
+
+	query, queryArgs, err := pageQuery(r.db.Dialect,
+		`SELECT p.resource_id, p.resource_type, p.name, p.status, p.deleted_at, p.deleter, p.version FROM `+from,
+		nil, args, trashSort, page)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list trash: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.TrashItem
+	var keys []pagination.Key
+	for rows.Next() {
+		item, err := scanTrashItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trash item: %w", err)
+		}
+		items = append(items, *item)
+
+		key := trashSort.key(sortValues{createdAt: item.DeletedTime}, item.ResourceID)
+		key.Kind = item.ResourceType
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := pagination.NewResult(items, keys, normalizeLimit(page.Limit))
+	if result.Total, err = pageTotal(ctx, r.db, from, nil, args, page); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// PurgeExpired 每个资源单独一个事务，避免长时间持有大量行锁
+func (r *trashRepository) PurgeExpired(ctx context.Context, before time.Time) (int, error) {
+	purged := 0
+	for _, resourceType := range resourceTypes {
+		t := resourceTables[resourceType]
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE deleted_at IS NOT NULL AND %s < %s",
+			t.idColumn, t.table, r.db.Dialect.TimeKey("deleted_at"), r.db.Dialect.TimeKey("?"))
+
+		ids, err := r.expiredIDs(ctx, query, before)
+		if err != nil {
+			return purged, fmt.Errorf("failed to list expired %s: %w", resourceType, err)
+		}
+
+		for _, id := range ids {
+			var deleted bool
+			err := r.db.WithTx(ctx, func(ctx context.Context) error {
+				deleted, err = r.purge(ctx, resourceType, id, &before, 0)
+				return err
+			})
+			if err != nil {
+				return purged, err
+			}
+			if deleted {
+				purged++
+			}
+		}
+	}
+	return purged, nil
+}
+
+func (r *trashRepository) expiredIDs(ctx context.Context, query string, before time.Time) ([]int, error) {
+	rows, err := r.db.QueryContext(ctx, query, before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// get 查询一条满足删除状态要求的回收站记录，找不到时返回 nil
+func (r *trashRepository) get(ctx context.Context, ownerID int, resourceType string, resourceID int, state trashState) (*model.TrashItem, error) {
+	t := resourceTables[resourceType]
+	query, args := trashSelect(resourceType, ownerID, state)
+	query += fmt.Sprintf(" AND r.%s = ?", t.idColumn)
+
+	item, err := scanTrashItem(r.db.QueryRowContext(ctx, query, append(args, resourceID)...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trash item: %w", err)
+	}
+	return item, nil
+}
+
+// purge 物理删除回收站中的资源行，before 不为 nil 时只删除在它之前进入回收站的，version 不为 0 时检查版本；
+// 图片、标签等关联表由外键级联删除，评论、点赞、收藏、每日浏览量是没有外键的多态表，需要单独删除。
+// 资源不满足条件时返回 false
+func (r *trashRepository) purge(ctx context.Context, resourceType string, resourceID int, before *time.Time, version int) (bool, error) {
+	t := resourceTables[resourceType]
+	match, matchArgs := versionMatch("version", version)
+	query := fmt.Sprintf("DELETE FROM %s WHERE %s = ? AND deleted_at IS NOT NULL", t.table, t.idColumn) + match
+	args := append([]interface{}{resourceID}, matchArgs...)
+	if before != nil {
+		// 期间资源可能已被恢复后再次删除，重新检查删除时间
+		query += fmt.Sprintf(" AND %s < %s", r.db.Dialect.TimeKey("deleted_at"), r.db.Dialect.TimeKey("?"))
+		args = append(args, *before)
+	}
+
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to purge %s: %w", resourceType, err)
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	for _, table := range []string{"comments", "likes", "collections", "resource_daily_views"} {
+		query := fmt.Sprintf("DELETE FROM %s WHERE resource_type = ? AND resource_id = ?", table)
+		if _, err := r.db.ExecContext(ctx, query, resourceType, resourceID); err != nil {
+			return false, fmt.Errorf("failed to purge %s of %s: %w", table, resourceType, err)
+		}
+	}
+	return true, nil
+}
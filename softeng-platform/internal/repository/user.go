@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
 	"time"
 )
 
@@ -16,6 +17,11 @@ type UserRepository interface {
 	GetByEmail(ctx context.Context, email string) (*model.User, error)
 	Update(ctx context.Context, user *model.User) error
 	UpdatePassword(ctx context.Context, userID int, hashedPassword string) error
+	Delete(ctx context.Context, userID int) error
+	GetCollection(ctx context.Context, userID int, page pagination.Page) (*model.UserResources, pagination.Info, error)
+	DeleteCollection(ctx context.Context, userID int, resourceType string, resourceID int) error
+	GetSubmissions(ctx context.Context, userID int) (*model.UserResources, error)
+	GetReviewStatus(ctx context.Context, userID int) (*model.UserReviewStatus, error)
 }
 
 type userRepository struct {
@@ -46,13 +52,13 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 	)
 	if err != nil {
 
-		return fmt.Errorf("failed to create user: %v", err)
+		return fmt.Errorf("failed to create user: %w", err)
 	}
 
 	// 获取自增ID
 	id, err := result.LastInsertId()
 	if err != nil {
-		return fmt.Errorf("failed to get last insert id: %v", err)
+		return fmt.Errorf("failed to get last insert id: %w", err)
 	}
 	user.ID = int(id)
 
@@ -63,7 +69,7 @@ func (r *userRepository) Create(ctx context.Context, user *model.User) error {
 func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, error) {
 	query := `
 
-		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, created_at, updated_at
+		SELECT id, username, COALESCE(nickname, ''), email, password, COALESCE(avatar, ''), COALESCE(description, ''), COALESCE(face_photo, ''), COALESCE(role, 'user'), created_at, updated_at
 
 		FROM users WHERE id = ?
 	`
@@ -87,7 +93,7 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, erro
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to get user by id: %v", err)
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
 	}
 
 	return user, nil
@@ -95,7 +101,7 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*model.User, erro
 
 func (r *userRepository) GetByUsername(ctx context.Context, username string) (*model.User, error) {
 	query := `
-		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, created_at, updated_at
+		SELECT id, username, COALESCE(nickname, ''), email, password, COALESCE(avatar, ''), COALESCE(description, ''), COALESCE(face_photo, ''), COALESCE(role, 'user'), created_at, updated_at
 		FROM users WHERE username = ?
 	`
 
@@ -118,7 +124,7 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to get user by username: %v", err)
+		return nil, fmt.Errorf("failed to get user by username: %w", err)
 	}
 
 	return user, nil
@@ -126,7 +132,7 @@ func (r *userRepository) GetByUsername(ctx context.Context, username string) (*m
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.User, error) {
 	query := `
-		SELECT id, username, nickname, email, password, avatar, description, face_photo, role, created_at, updated_at
+		SELECT id, username, COALESCE(nickname, ''), email, password, COALESCE(avatar, ''), COALESCE(description, ''), COALESCE(face_photo, ''), COALESCE(role, 'user'), created_at, updated_at
 		FROM users WHERE email = ?
 	`
 
@@ -149,7 +155,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*model.U
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
-		return nil, fmt.Errorf("failed to get user by email: %v", err)
+		return nil, fmt.Errorf("failed to get user by email: %w", err)
 	}
 
 	return user, nil
@@ -172,12 +178,12 @@ func (r *userRepository) Update(ctx context.Context, user *model.User) error {
 	)
 
 	if err != nil {
-		return fmt.Errorf("failed to update user: %v", err)
+		return fmt.Errorf("failed to update user: %w", err)
 	}
 
 	rows, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %v", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 	if rows == 0 {
 		return fmt.Errorf("user not found")
@@ -192,12 +198,12 @@ func (r *userRepository) UpdatePassword(ctx context.Context, userID int, hashedP
 
 	result, err := r.db.ExecContext(ctx, query, hashedPassword, time.Now(), userID)
 	if err != nil {
-		return fmt.Errorf("failed to update password: %v", err)
+		return fmt.Errorf("failed to update password: %w", err)
 	}
 
 	rows, err := result.RowsAffected()
 	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %v", err)
+		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 	if rows == 0 {
 		return fmt.Errorf("user not found")
@@ -206,3 +212,365 @@ func (r *userRepository) UpdatePassword(ctx context.Context, userID int, hashedP
 	return nil
 
 }
+
+// Delete 删除用户，点赞、收藏、评论和贡献者记录由外键级联删除，提交记录的 submitter_id 置空
+func (r *userRepository) Delete(ctx context.Context, userID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete user: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rows == 0 {
+		return fmt.Errorf("user not found")
+	}
+
+	return nil
+}
+
+
+// collectionSort 收藏按收藏时间倒序，三类资源共用一个游标
+var collectionSort = keyset{name: "collected", field: byCreatedAt, column: "c.created_at", idColumn: "c.id"}
+
+// GetCollection 分页的单位是收藏记录，一页中的收藏再按类型分别加载资源
+func (r *userRepository) GetCollection(ctx context.Context, userID int, page pagination.Page) (*model.UserResources, pagination.Info, error) {
+	where := []string{"c.user_id = ?", "c.resource_type IN (?, ?, ?)", notDeleted("c.resource_type", "c.resource_id")}
+	args := []interface{}{userID, model.ResourceTypeProject, model.ResourceTypeTool, model.ResourceTypeCourse}
+
+	query, queryArgs, err := pageQuery(r.db.Dialect, `SELECT c.id, c.created_at FROM collections c`, where, args, collectionSort, page)
+	if err != nil {
+		return nil, pagination.Info{}, err
+	}
+	rows, err := r.db.QueryContext(ctx, query, queryArgs...)
+	if err != nil {
+		return nil, pagination.Info{}, fmt.Errorf("failed to get collections: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	var keys []pagination.Key
+	for rows.Next() {
+		var id int
+		var createdAt time.Time
+		if err := rows.Scan(&id, &createdAt); err != nil {
+			return nil, pagination.Info{}, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		ids = append(ids, id)
+		keys = append(keys, collectionSort.key(sortValues{createdAt: createdAt}, id))
+	}
+	if err := rows.Err(); err != nil {
+		return nil, pagination.Info{}, err
+	}
+
+	paged := pagination.NewResult(ids, keys, normalizeLimit(page.Limit))
+	if paged.Total, err = pageTotal(ctx, r.db, "collections c", where, args, page); err != nil {
+		return nil, pagination.Info{}, err
+	}
+
+	result := &model.UserResources{}
+	if len(paged.Items) == 0 {
+		if err := r.fillPersonal(ctx, result, nil, nil, nil); err != nil {
+			return nil, pagination.Info{}, err
+		}
+		return result, paged.Info, nil
+	}
+
+	in := placeholders(len(paged.Items))
+	projects, err := r.personalItems(ctx, `
+		SELECT p.project_id, p.name, COALESCE(p.cover, ''), COALESCE(p.description, '')
+		FROM collections c JOIN projects p ON p.project_id = c.resource_id
+		WHERE c.resource_type = ? AND c.id IN (`+in+`)
+		ORDER BY c.created_at DESC, c.id DESC`, append([]interface{}{model.ResourceTypeProject}, intArgs(paged.Items)...)...)
+	if err != nil {
+		return nil, pagination.Info{}, err
+	}
+	tools, err := r.personalItems(ctx, `
+		SELECT t.resource_id, t.resource_name, COALESCE((SELECT image_url FROM tool_images WHERE tool_id = t.resource_id ORDER BY sort_order, id LIMIT 1), ''), COALESCE(t.description, '')
+		FROM collections c JOIN tools t ON t.resource_id = c.resource_id
+		WHERE c.resource_type = ? AND c.id IN (`+in+`)
+		ORDER BY c.created_at DESC, c.id DESC`, append([]interface{}{model.ResourceTypeTool}, intArgs(paged.Items)...)...)
+	if err != nil {
+		return nil, pagination.Info{}, err
+	}
+	courses, err := r.personalItems(ctx, `
+		SELECT co.course_id, co.name, COALESCE(co.cover, ''), COALESCE(co.semester, '')
+		FROM collections c JOIN courses co ON co.course_id = c.resource_id
+		WHERE c.resource_type = ? AND c.id IN (`+in+`)
+		ORDER BY c.created_at DESC, c.id DESC`, append([]interface{}{model.ResourceTypeCourse}, intArgs(paged.Items)...)...)
+	if err != nil {
+		return nil, pagination.Info{}, err
+	}
+
+	if err := r.fillPersonal(ctx, result, projects, tools, courses); err != nil {
+		return nil, pagination.Info{}, err
+	}
+	return result, paged.Info, nil
+}
+
+func (r *userRepository) DeleteCollection(ctx context.Context, userID int, resourceType string, resourceID int) error {
+	has, err := hasRelation(ctx, r.db, "collections", userID, resourceType, resourceID)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return fmt.Errorf("collection not found")
+	}
+
+	_, err = setCollect(ctx, r.db, userID, resourceType, resourceID, false)
+	return err
+}
+
+func (r *userRepository) GetSubmissions(ctx context.Context, userID int) (*model.UserResources, error) {
+	result := &model.UserResources{}
+
+	projects, err := r.personalItems(ctx, `
+		SELECT project_id, name, COALESCE(cover, ''), COALESCE(description, '')
+		FROM projects WHERE submitter_id = ? AND deleted_at IS NULL
+		ORDER BY created_at DESC, project_id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	tools, err := r.personalItems(ctx, `
+		SELECT t.resource_id, t.resource_name, COALESCE((SELECT image_url FROM tool_images WHERE tool_id = t.resource_id ORDER BY sort_order, id LIMIT 1), ''), COALESCE(t.description, '')
+		FROM tools t WHERE t.submitter_id = ? AND t.deleted_at IS NULL
+		ORDER BY t.created_at DESC, t.resource_id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	teaches, err := r.personalItems(ctx, `
+		SELECT resource_id, resource, image, introduce FROM (
+			SELECT w.resource_id, w.resource_url AS resource, COALESCE(c.cover, '') AS image, w.resource_intro AS introduce, w.created_at
+			FROM course_resources_web w JOIN courses c ON c.course_id = w.course_id
+			WHERE w.submitter_id = ?
+			UNION ALL
+			SELECT f.resource_id, f.resource_upload AS resource, COALESCE(c.cover, '') AS image, f.resource_intro AS introduce, f.created_at
+			FROM course_resources_upload f JOIN courses c ON c.course_id = f.course_id
+			WHERE f.submitter_id = ?
+		) t ORDER BY created_at DESC, resource_id DESC`, userID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	submitter, err := r.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.fillPersonal(ctx, result, projects, tools, nil); err != nil {
+		return nil, err
+	}
+	result.Teaches = make([]model.TeachPersonal, len(teaches))
+	for i, item := range teaches {
+		result.Teaches[i] = model.TeachPersonal(item)
+		result.Teaches[i].ResourceType = model.ResourceTypeCourse
+		result.Teaches[i].Contributer = []model.User{}
+		if submitter != nil {
+			result.Teaches[i].Contributer = []model.User{publicUser(*submitter)}
+		}
+	}
+	return result, nil
+}
+
+func (r *userRepository) GetReviewStatus(ctx context.Context, userID int) (*model.UserReviewStatus, error) {
+	result := &model.UserReviewStatus{}
+
+	var err error
+	result.Resources, err = r.reviewItems(ctx, model.ResourceTypeProject, `
+		SELECT project_id, COALESCE(github_url, ''), COALESCE(status, ''), created_at, audit_time, reject_reason
+		FROM projects WHERE submitter_id = ? AND deleted_at IS NULL
+		ORDER BY created_at DESC, project_id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+	result.Tools, err = r.reviewItems(ctx, model.ResourceTypeTool, `
+		SELECT resource_id, COALESCE(resource_link, ''), COALESCE(status, ''), created_at, audit_time, reject_reason
+		FROM tools WHERE submitter_id = ? AND deleted_at IS NULL
+		ORDER BY created_at DESC, resource_id DESC`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT resource_id, kind, intro, resource, status, created_at, audit_time, reject_reason FROM (
+			SELECT resource_id, 'web' AS kind, resource_intro AS intro, resource_url AS resource,
+				COALESCE(status, '') AS status, created_at, audit_time, reject_reason
+			FROM course_resources_web WHERE submitter_id = ?
+			UNION ALL
+			SELECT resource_id, 'upload' AS kind, resource_intro AS intro, resource_upload AS resource,
+				COALESCE(status, '') AS status, created_at, audit_time, reject_reason
+			FROM course_resources_upload WHERE submitter_id = ?
+		) t ORDER BY created_at DESC, resource_id DESC`, userID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get course resource status: %w", err)
+	}
+	defer rows.Close()
+
+	result.Teaches = []model.TeachReview{}
+	for rows.Next() {
+		var review model.TeachReview
+		var kind, intro, resource string
+		var createdAt time.Time
+		var auditTime sql.NullTime
+		var rejectReason sql.NullString
+		if err := rows.Scan(&review.ResourceID, &kind, &intro, &resource, &review.AuditStatus,
+			&createdAt, &auditTime, &rejectReason); err != nil {
+			return nil, fmt.Errorf("failed to scan course resource status: %w", err)
+		}
+		review.ResourceType = model.ResourceTypeCourse
+		review.SubmitTime = formatTime(createdAt)
+		review.AuditTime = formatNullTime(auditTime)
+		review.RejectReason = nullStringPtr(rejectReason)
+		if kind == "web" {
+			review.Resource1 = &model.ResourceWeb{ResourceIntro: intro, ResourceURL: resource, ResourceID: review.ResourceID}
+		} else {
+			review.Resource2 = &model.ResourceUpload{ResourceIntro: intro, ResourceUpload: resource, ResourceID: review.ResourceID}
+		}
+		result.Teaches = append(result.Teaches, review)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// personalItems 查询 (id, resource, image, introduce) 四列组成的个人资源列表
+func (r *userRepository) personalItems(ctx context.Context, query string, args ...interface{}) ([]model.ResourcePersonal, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get personal resources: %w", err)
+	}
+	defer rows.Close()
+
+	var items []model.ResourcePersonal
+	for rows.Next() {
+		var item model.ResourcePersonal
+		if err := rows.Scan(&item.ResourceID, &item.Resource, &item.Image, &item.Introduce); err != nil {
+			return nil, fmt.Errorf("failed to scan personal resource: %w", err)
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// fillPersonal 为个人资源加载贡献者并写入结果
+func (r *userRepository) fillPersonal(ctx context.Context, result *model.UserResources, projects, tools, courses []model.ResourcePersonal) error {
+	projectAuthors, err := r.loadUsers(ctx, `
+		SELECT pa.project_id, u.id, u.username, COALESCE(u.nickname, ''), COALESCE(u.avatar, '')
+		FROM project_authors pa JOIN users u ON u.id = pa.user_id
+		WHERE pa.project_id IN (%s) ORDER BY pa.id`, personalIDs(projects))
+	if err != nil {
+		return err
+	}
+	toolContributors, err := r.loadUsers(ctx, `
+		SELECT tc.tool_id, u.id, u.username, COALESCE(u.nickname, ''), COALESCE(u.avatar, '')
+		FROM tool_contributors tc JOIN users u ON u.id = tc.user_id
+		WHERE tc.tool_id IN (%s) ORDER BY tc.id`, personalIDs(tools))
+	if err != nil {
+		return err
+	}
+	courseContributors, err := r.loadUsers(ctx, `
+		SELECT cc.course_id, u.id, u.username, COALESCE(u.nickname, ''), COALESCE(u.avatar, '')
+		FROM course_contributors cc JOIN users u ON u.id = cc.user_id
+		WHERE cc.course_id IN (%s) ORDER BY cc.id`, personalIDs(courses))
+	if err != nil {
+		return err
+	}
+
+	result.Resources = make([]model.ResourcePersonal, len(projects))
+	for i, item := range projects {
+		item.ResourceType = model.ResourceTypeProject
+		item.Contributer = nonNilUsers(projectAuthors[item.ResourceID])
+		result.Resources[i] = item
+	}
+	result.Tools = make([]model.ToolPersonal, len(tools))
+	for i, item := range tools {
+		item.ResourceType = model.ResourceTypeTool
+		item.Contributer = nonNilUsers(toolContributors[item.ResourceID])
+		result.Tools[i] = model.ToolPersonal(item)
+	}
+	result.Teaches = make([]model.TeachPersonal, len(courses))
+	for i, item := range courses {
+		item.ResourceType = model.ResourceTypeCourse
+		item.Contributer = nonNilUsers(courseContributors[item.ResourceID])
+		result.Teaches[i] = model.TeachPersonal(item)
+	}
+	return nil
+}
+
+func (r *userRepository) loadUsers(ctx context.Context, query string, ids []int) (map[int][]model.User, error) {
+	result := make(map[int][]model.User, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, fmt.Sprintf(query, placeholders(len(ids))), intArgs(ids)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load contributors: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var user model.User
+		if err := rows.Scan(&id, &user.ID, &user.Username, &user.Nickname, &user.Avatar); err != nil {
+			return nil, fmt.Errorf("failed to scan contributor: %w", err)
+		}
+		result[id] = append(result[id], user)
+	}
+	return result, rows.Err()
+}
+
+// reviewItems 查询 (id, resource, status, created_at, audit_time, reject_reason) 组成的审核状态列表
+func (r *userRepository) reviewItems(ctx context.Context, resourceType, query string, args ...interface{}) ([]model.ResourceReview, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s status: %w", resourceType, err)
+	}
+	defer rows.Close()
+
+	items := []model.ResourceReview{}
+	for rows.Next() {
+		var item model.ResourceReview
+		var createdAt time.Time
+		var auditTime sql.NullTime
+		var rejectReason sql.NullString
+		if err := rows.Scan(&item.ResourceID, &item.Resource, &item.AuditStatus, &createdAt, &auditTime, &rejectReason); err != nil {
+			return nil, fmt.Errorf("failed to scan %s status: %w", resourceType, err)
+		}
+		item.ResourceType = resourceType
+		item.SubmitTime = formatTime(createdAt)
+		item.AuditTime = formatNullTime(auditTime)
+		item.RejectReason = nullStringPtr(rejectReason)
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+func personalIDs(items []model.ResourcePersonal) []int {
+	ids := make([]int, len(items))
+	for i, item := range items {
+		ids[i] = item.ResourceID
+	}
+	return ids
+}
+
+func nonNilUsers(users []model.User) []model.User {
+	if users == nil {
+		return []model.User{}
+	}
+	return users
+}
+
+// publicUser 只保留对外展示的用户字段
+func publicUser(user model.User) model.User {
+	return model.User{
+		ID:       user.ID,
+		Username: user.Username,
+		Nickname: user.Nickname,
+		Avatar:   user.Avatar,
+	}
+}
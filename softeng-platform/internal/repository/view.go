@@ -0,0 +1,116 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+// dateLayout 每日浏览量中日期的格式
+const dateLayout = "2006-01-02"
+
+// ViewDelta 一个资源某一天新增的浏览量
+type ViewDelta struct {
+	ResourceType string
+	ResourceID   int
+	Date         string // 2006-01-02
+	Views        int
+}
+
+// ViewRepository 浏览量的读写，去重和聚合由服务层的浏览计数器负责
+type ViewRepository interface {
+	// Views 返回资源当前的浏览量，资源不存在或已删除时 found 为 false
+	Views(ctx context.Context, resourceType string, resourceID int) (views int, found bool, err error)
+	// AddViews 在一个事务中把增量累加到资源表的 views 列和每日浏览量表，已删除的资源会被跳过
+	AddViews(ctx context.Context, deltas []ViewDelta) error
+	// DailyViews 按日期先后返回 [from, to] 范围内每天的浏览量，没有浏览的日期不返回
+	DailyViews(ctx context.Context, resourceType string, resourceID int, from, to string) ([]model.DailyViews, error)
+}
+
+type viewRepository struct {
+	db *Database
+}
+
+func NewViewRepository(db *Database) ViewRepository {
+	return &viewRepository{db: db}
+}
+
+func (r *viewRepository) Views(ctx context.Context, resourceType string, resourceID int) (int, bool, error) {
+	t, err := lookupResourceTable(resourceType)
+	if err != nil {
+		return 0, false, err
+	}
+
+	var views int
+	query := fmt.Sprintf("SELECT views FROM %s WHERE %s = ? AND deleted_at IS NULL", t.table, t.idColumn)
+	err = r.db.QueryRowContext(ctx, query, resourceID).Scan(&views)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get %s views: %w", resourceType, err)
+	}
+	return views, true, nil
+}
+
+func (r *viewRepository) AddViews(ctx context.Context, deltas []ViewDelta) error {
+	upsert := r.db.Dialect.Upsert("resource_daily_views",
+		[]string{"resource_type", "resource_id", "view_date", "views"},
+		[]string{"resource_type", "resource_id", "view_date"}, nil,
+		"views = views + "+r.db.Dialect.Excluded("views"))
+
+	return r.db.WithTx(ctx, func(ctx context.Context) error {
+		for _, d := range deltas {
+			t, err := lookupResourceTable(d.ResourceType)
+			if err != nil {
+				return err
+			}
+
+			query := fmt.Sprintf("UPDATE %s SET views = views + ? WHERE %s = ? AND deleted_at IS NULL", t.table, t.idColumn)
+			result, err := r.db.ExecContext(ctx, query, d.Views, d.ResourceID)
+			if err != nil {
+				return fmt.Errorf("failed to add %s views: %w", d.ResourceType, err)
+			}
+			rows, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("failed to get rows affected: %w", err)
+			}
+			if rows == 0 {
+				// 聚合期间资源被删除，丢弃这部分浏览量
+				continue
+			}
+
+			if _, err := r.db.ExecContext(ctx, upsert, d.ResourceType, d.ResourceID, d.Date, d.Views); err != nil {
+				return fmt.Errorf("failed to add daily views: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (r *viewRepository) DailyViews(ctx context.Context, resourceType string, resourceID int, from, to string) ([]model.DailyViews, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT view_date, views FROM resource_daily_views
+		WHERE resource_type = ? AND resource_id = ? AND view_date >= ? AND view_date <= ?
+		ORDER BY view_date`,
+		resourceType, resourceID, from, to,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily views: %w", err)
+	}
+	defer rows.Close()
+
+	daily := []model.DailyViews{}
+	for rows.Next() {
+		var date time.Time
+		var views int
+		if err := rows.Scan(&date, &views); err != nil {
+			return nil, fmt.Errorf("failed to scan daily views: %w", err)
+		}
+		daily = append(daily, model.DailyViews{Date: date.Format(dateLayout), Views: views})
+	}
+	return daily, rows.Err()
+}
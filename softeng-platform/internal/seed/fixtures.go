@@ -0,0 +1,135 @@
+// Package seed 把声明式的演示数据写入数据库，也可以生成随机数据用于压测。
+// 数据按自然键（用户名、工具名、课程名+学期、项目名等）匹配，重复执行结果不变
+package seed
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures 一份演示数据。引用用户时使用用户名，引用资源时使用 Ref
+type Fixtures struct {
+	Users       []User     `yaml:"users"`
+	Tools       []Tool     `yaml:"tools"`
+	Courses     []Course   `yaml:"courses"`
+	Projects    []Project  `yaml:"projects"`
+	Comments    []Comment  `yaml:"comments"`
+	Likes       []Relation `yaml:"likes"`
+	Collections []Relation `yaml:"collections"`
+}
+
+// User 以 username 为自然键；已存在的用户不修改密码
+type User struct {
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	Email       string `yaml:"email"`
+	Nickname    string `yaml:"nickname"`
+	Avatar      string `yaml:"avatar"`
+	Description string `yaml:"description"`
+	Role        string `yaml:"role"` // user（默认）或 admin
+}
+
+// Tool 以 name 为自然键，status 默认为 approved
+type Tool struct {
+	Name         string    `yaml:"name"`
+	Link         string    `yaml:"link"`
+	Description  string    `yaml:"description"`
+	Detail       string    `yaml:"description_detail"`
+	Category     string    `yaml:"category"`
+	Tags         []string  `yaml:"tags"`
+	Images       []string  `yaml:"images"`
+	Submitter    string    `yaml:"submitter"`
+	Contributors []string  `yaml:"contributors"`
+	Status       string    `yaml:"status"`
+	Views        int       `yaml:"views"`
+	CreatedAt    time.Time `yaml:"created_at"` // 只在创建时写入，为空时取当前时间
+}
+
+// Course 以 name + semester 为自然键
+type Course struct {
+	Name         string           `yaml:"name"`
+	Semester     string           `yaml:"semester"`
+	Credit       int              `yaml:"credit"`
+	Cover        string           `yaml:"cover"`
+	Teachers     []string         `yaml:"teachers"`
+	Categories   []string         `yaml:"categories"`
+	Contributors []string         `yaml:"contributors"`
+	Resources    []CourseResource `yaml:"resources"`
+	Views        int              `yaml:"views"`
+	CreatedAt    time.Time        `yaml:"created_at"`
+}
+
+// CourseResource 课程下的网页链接或上传文件，以课程 + intro 为自然键
+type CourseResource struct {
+	Type      string `yaml:"type"` // web（默认）或 upload
+	Intro     string `yaml:"intro"`
+	Resource  string `yaml:"resource"` // 链接地址或文件路径
+	Submitter string `yaml:"submitter"`
+	Status    string `yaml:"status"`
+}
+
+// Project 以 name 为自然键
+type Project struct {
+	Name        string    `yaml:"name"`
+	Description string    `yaml:"description"`
+	Detail      string    `yaml:"detail"`
+	GitHub      string    `yaml:"github"`
+	Category    string    `yaml:"category"`
+	Cover       string    `yaml:"cover"`
+	TechStack   []string  `yaml:"tech_stack"`
+	Images      []string  `yaml:"images"`
+	Authors     []string  `yaml:"authors"`
+	Status      string    `yaml:"status"`
+	Views       int       `yaml:"views"`
+	CreatedAt   time.Time `yaml:"created_at"`
+}
+
+// Ref 引用一个资源：type 为 tool/course/project，课程还需要 semester
+type Ref struct {
+	Type     string `yaml:"type"`
+	Name     string `yaml:"name"`
+	Semester string `yaml:"semester"`
+}
+
+func (r Ref) String() string {
+	if r.Semester != "" {
+		return fmt.Sprintf("%s %q (%s)", r.Type, r.Name, r.Semester)
+	}
+	return fmt.Sprintf("%s %q", r.Type, r.Name)
+}
+
+// Comment 以资源 + 用户 + 内容为自然键，回复同理
+type Comment struct {
+	User     string  `yaml:"user"`
+	Resource Ref     `yaml:"resource"`
+	Content  string  `yaml:"content"`
+	Replies  []Reply `yaml:"replies"`
+}
+
+type Reply struct {
+	User    string `yaml:"user"`
+	Content string `yaml:"content"`
+}
+
+// Relation 一条点赞或收藏
+type Relation struct {
+	User     string `yaml:"user"`
+	Resource Ref    `yaml:"resource"`
+}
+
+// LoadFile 读取 YAML 或 JSON 格式的演示数据（JSON 是 YAML 的子集，字段名相同）
+func LoadFile(path string) (*Fixtures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+
+	var f Fixtures
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &f, nil
+}
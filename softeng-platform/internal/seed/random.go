@@ -0,0 +1,218 @@
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"softeng-platform/internal/model"
+	"time"
+)
+
+// RandomPassword 随机生成的用户共用的密码
+const RandomPassword = "password123"
+
+// Options 随机数据的规模
+type Options struct {
+	Users    int
+	Tools    int
+	Courses  int
+	Projects int
+	// Interactions 每个资源平均的点赞、收藏和评论数
+	Interactions int
+	// Seed 随机种子，种子和规模相同时生成的数据相同，重复写入不会产生新行
+	Seed int64
+}
+
+var (
+	surnames   = []string{"张", "王", "李", "赵", "刘", "陈", "杨", "黄", "周", "吴", "徐", "孙", "马", "朱", "胡", "林"}
+	givenNames = []string{"伟", "芳", "娜", "敏", "静", "磊", "洋", "艳", "勇", "杰", "涛", "明", "超", "霞", "平", "刚", "宇", "欣"}
+
+	toolNames = []string{
+		"Visual Studio Code", "IntelliJ IDEA", "GoLand", "Git", "Docker", "Postman", "Figma", "Notion",
+		"Typora", "Wireshark", "Jenkins", "Navicat", "Xshell", "Draw.io", "Vim", "Apifox",
+	}
+	toolCategories = []string{"开发工具", "设计工具", "效率工具", "测试工具", "运维工具"}
+	toolTags       = []string{"免费", "开源", "跨平台", "IDE", "协作", "调试", "数据库", "前端", "后端", "命令行"}
+
+	courseNames = []string{
+		"软件工程", "数据结构", "操作系统", "计算机网络", "编译原理", "数据库系统",
+		"算法设计与分析", "软件测试", "人机交互", "软件项目管理", "面向对象程序设计", "软件体系结构",
+	}
+	semesters        = []string{"2023-2024-1", "2023-2024-2", "2024-2025-1", "2024-2025-2"}
+	courseCategories = []string{"required", "elective"}
+
+	projectPrefixes  = []string{"校园", "智慧", "在线", "轻量", "分布式", "开源"}
+	projectSubjects  = []string{"二手交易平台", "课程表助手", "图书管理系统", "博客系统", "问答社区", "代码评审工具", "实验室预约系统", "失物招领平台"}
+	projectCategory  = []string{"Web应用", "移动应用", "工具库", "课程设计"}
+	techStack        = []string{"Go", "Gin", "Vue", "React", "MySQL", "Redis", "Docker", "Python", "Spring Boot", "TypeScript"}
+	commentTemplates = []string{
+		"很实用，推荐给同学们了", "讲得很清楚，收藏了", "请问有配套的资料吗？", "用了一学期，体验不错",
+		"文档有点少，上手需要时间", "期末复习全靠它了", "界面很简洁", "希望能多更新一些内容",
+	}
+	replyTemplates = []string{"同感", "谢谢分享", "我也遇到了这个问题", "可以看看官方文档", "+1"}
+)
+
+// Generate 按 opts 生成随机数据。用户名为 user00001 这样的格式，密码均为 RandomPassword；
+// 资源名带序号以保证自然键唯一
+func Generate(opts Options) (*Fixtures, error) {
+	if opts.Users < 0 || opts.Tools < 0 || opts.Courses < 0 || opts.Projects < 0 || opts.Interactions < 0 {
+		return nil, errors.New("sizes must not be negative")
+	}
+	if opts.Users == 0 && opts.Tools+opts.Courses+opts.Projects > 0 {
+		return nil, errors.New("at least one user is required to generate resources")
+	}
+
+	g := &generator{r: rand.New(rand.NewSource(opts.Seed)), userCount: opts.Users, now: time.Now()}
+	f := &Fixtures{}
+	var refs []Ref
+
+	for i := 0; i < opts.Users; i++ {
+		f.Users = append(f.Users, User{
+			Username: username(i),
+			Password: RandomPassword,
+			Email:    fmt.Sprintf("%s@example.com", username(i)),
+			Nickname: pick(g.r, surnames) + pick(g.r, givenNames) + pick(g.r, givenNames),
+		})
+	}
+
+	for i := 0; i < opts.Tools; i++ {
+		name := fmt.Sprintf("%s #%d", pick(g.r, toolNames), i+1)
+		submitter := g.user()
+		f.Tools = append(f.Tools, Tool{
+			Name:         name,
+			Link:         fmt.Sprintf("https://example.com/tools/%d", i+1),
+			Description:  fmt.Sprintf("%s，适合软件工程课程使用", name),
+			Detail:       fmt.Sprintf("%s 的安装方法、常用功能和使用技巧。", name),
+			Category:     pick(g.r, toolCategories),
+			Tags:         g.sample(toolTags, 1+g.r.Intn(3)),
+			Submitter:    submitter,
+			Contributors: []string{submitter},
+			Status:       g.status(),
+			Views:        g.r.Intn(5000),
+			CreatedAt:    g.createdAt(),
+		})
+		refs = append(refs, Ref{Type: model.ResourceTypeTool, Name: name})
+	}
+
+	for i := 0; i < opts.Courses; i++ {
+		name := fmt.Sprintf("%s（%d班）", pick(g.r, courseNames), i+1)
+		semester := pick(g.r, semesters)
+		course := Course{
+			Name:         name,
+			Semester:     semester,
+			Credit:       1 + g.r.Intn(4),
+			Teachers:     []string{pick(g.r, surnames) + pick(g.r, givenNames) + "老师"},
+			Categories:   []string{pick(g.r, courseCategories)},
+			Contributors: []string{g.user()},
+			Views:        g.r.Intn(5000),
+			CreatedAt:    g.createdAt(),
+		}
+		for j, n := 0, 1+g.r.Intn(3); j < n; j++ {
+			course.Resources = append(course.Resources, CourseResource{
+				Type:      "web",
+				Intro:     fmt.Sprintf("第%d章课件", j+1),
+				Resource:  fmt.Sprintf("https://example.com/courses/%d/slides/%d", i+1, j+1),
+				Submitter: g.user(),
+				Status:    g.status(),
+			})
+		}
+		f.Courses = append(f.Courses, course)
+		refs = append(refs, Ref{Type: model.ResourceTypeCourse, Name: name, Semester: semester})
+	}
+
+	for i := 0; i < opts.Projects; i++ {
+		name := fmt.Sprintf("%s%s-%d", pick(g.r, projectPrefixes), pick(g.r, projectSubjects), i+1)
+		f.Projects = append(f.Projects, Project{
+			Name:        name,
+			Description: fmt.Sprintf("%s，课程设计项目", name),
+			Detail:      fmt.Sprintf("%s 的需求分析、系统设计与实现。", name),
+			GitHub:      fmt.Sprintf("https://github.com/example/project-%d", i+1),
+			Category:    pick(g.r, projectCategory),
+			TechStack:   g.sample(techStack, 2+g.r.Intn(3)),
+			Authors:     g.users(1 + g.r.Intn(3)),
+			Status:      g.status(),
+			Views:       g.r.Intn(5000),
+			CreatedAt:   g.createdAt(),
+		})
+		refs = append(refs, Ref{Type: model.ResourceTypeProject, Name: name})
+	}
+
+	if opts.Interactions > 0 {
+		for _, ref := range refs {
+			for _, user := range g.users(g.r.Intn(2*opts.Interactions + 1)) {
+				f.Likes = append(f.Likes, Relation{User: user, Resource: ref})
+			}
+			for _, user := range g.users(g.r.Intn(2*opts.Interactions + 1)) {
+				f.Collections = append(f.Collections, Relation{User: user, Resource: ref})
+			}
+			for n := g.r.Intn(opts.Interactions + 1); n > 0; n-- {
+				comment := Comment{User: g.user(), Resource: ref, Content: pick(g.r, commentTemplates)}
+				for m := g.r.Intn(3); m > 0; m-- {
+					comment.Replies = append(comment.Replies, Reply{User: g.user(), Content: pick(g.r, replyTemplates)})
+				}
+				f.Comments = append(f.Comments, comment)
+			}
+		}
+	}
+	return f, nil
+}
+
+type generator struct {
+	r         *rand.Rand
+	userCount int
+	now       time.Time
+}
+
+func username(i int) string {
+	return fmt.Sprintf("user%05d", i+1)
+}
+
+func (g *generator) user() string {
+	return username(g.r.Intn(g.userCount))
+}
+
+// users 随机选出至多 n 个不同的用户
+func (g *generator) users(n int) []string {
+	if n > g.userCount {
+		n = g.userCount
+	}
+	// 用户数可能很大，逐个抽取而不是打乱全部用户
+	var result []string
+	seen := make(map[int]bool, n)
+	for len(result) < n {
+		i := g.r.Intn(g.userCount)
+		if !seen[i] {
+			seen[i] = true
+			result = append(result, username(i))
+		}
+	}
+	return result
+}
+
+func (g *generator) sample(values []string, n int) []string {
+	if n > len(values) {
+		n = len(values)
+	}
+	var result []string
+	for _, i := range g.r.Perm(len(values))[:n] {
+		result = append(result, values[i])
+	}
+	return result
+}
+
+// status 大约十分之一的资源待审核，用于填充审核队列
+func (g *generator) status() string {
+	if g.r.Intn(10) == 0 {
+		return model.StatusPending
+	}
+	return model.StatusApproved
+}
+
+// createdAt 最近 180 天内的随机时间
+func (g *generator) createdAt() time.Time {
+	return g.now.Add(-time.Duration(g.r.Int63n(180*24*60)) * time.Minute).Truncate(time.Second)
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.Intn(len(values))]
+}
@@ -0,0 +1,605 @@
+package seed
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/utils"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Summary 一次写入的统计，按表记录新建和更新的行数
+type Summary struct {
+	Created map[string]int
+	Updated map[string]int
+	// Counters 写入后校对修正的计数列数量
+	Counters int
+}
+
+func (s *Summary) String() string {
+	tables := make(map[string]bool)
+	for table := range s.Created {
+		tables[table] = true
+	}
+	for table := range s.Updated {
+		tables[table] = true
+	}
+	names := make([]string, 0, len(tables))
+	for table := range tables {
+		names = append(names, table)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, table := range names {
+		fmt.Fprintf(&b, "%-24s created %d, updated %d\n", table, s.Created[table], s.Updated[table])
+	}
+	fmt.Fprintf(&b, "counters fixed: %d", s.Counters)
+	return b.String()
+}
+
+// Seeder 把 Fixtures 写入数据库。已存在的行按自然键匹配后更新描述性字段，
+// 浏览量、创建时间和密码只在创建时写入，所以重复执行同一份数据结果不变
+type Seeder struct {
+	db        *repository.Database
+	reconcile repository.ReconcileRepository
+
+	summary *Summary
+	users   map[string]int    // 用户名 -> ID
+	hashes  map[string]string // 明文密码 -> bcrypt 结果，随机数据共用密码时避免重复计算
+}
+
+func NewSeeder(db *repository.Database) *Seeder {
+	return &Seeder{
+		db:        db,
+		reconcile: repository.NewReconcileRepository(db),
+		hashes:    make(map[string]string),
+	}
+}
+
+// Apply 在一个事务中写入全部数据，最后按来源表校对点赞、收藏和回复计数
+func (s *Seeder) Apply(ctx context.Context, f *Fixtures) (*Summary, error) {
+	err := s.db.WithTx(ctx, func(ctx context.Context) error {
+		// 事务重试时从头开始
+		s.summary = &Summary{Created: make(map[string]int), Updated: make(map[string]int)}
+		s.users = make(map[string]int)
+
+		for _, u := range f.Users {
+			if err := s.user(ctx, u); err != nil {
+				return fmt.Errorf("user %q: %w", u.Username, err)
+			}
+		}
+		for _, t := range f.Tools {
+			if err := s.tool(ctx, t); err != nil {
+				return fmt.Errorf("tool %q: %w", t.Name, err)
+			}
+		}
+		for _, c := range f.Courses {
+			if err := s.course(ctx, c); err != nil {
+				return fmt.Errorf("course %q: %w", c.Name, err)
+			}
+		}
+		for _, p := range f.Projects {
+			if err := s.project(ctx, p); err != nil {
+				return fmt.Errorf("project %q: %w", p.Name, err)
+			}
+		}
+		for _, c := range f.Comments {
+			if err := s.comment(ctx, c); err != nil {
+				return fmt.Errorf("comment on %s: %w", c.Resource, err)
+			}
+		}
+		for _, r := range f.Likes {
+			if err := s.relation(ctx, "likes", r); err != nil {
+				return fmt.Errorf("like on %s: %w", r.Resource, err)
+			}
+		}
+		for _, r := range f.Collections {
+			if err := s.relation(ctx, "collections", r); err != nil {
+				return fmt.Errorf("collection on %s: %w", r.Resource, err)
+			}
+		}
+
+		report, err := s.reconcile.Reconcile(ctx, true)
+		if err != nil {
+			return err
+		}
+		s.summary.Counters = len(report.Drifts)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.summary, nil
+}
+
+// ==================== 用户 ====================
+
+func (s *Seeder) user(ctx context.Context, u User) error {
+	if u.Username == "" || u.Email == "" {
+		return errors.New("username and email are required")
+	}
+	role := orDefault(u.Role, "user")
+	nickname := orDefault(u.Nickname, u.Username)
+	now := time.Now()
+
+	id, err := s.lookup(ctx, "SELECT id FROM users WHERE username = ?", u.Username)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		if u.Password == "" {
+			return errors.New("password is required for new users")
+		}
+		hash, err := s.hash(u.Password)
+		if err != nil {
+			return err
+		}
+		id, err = s.insert(ctx, "users",
+			`INSERT INTO users (username, password, email, nickname, avatar, description, role, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			u.Username, hash, u.Email, nickname, nullable(u.Avatar), nullable(u.Description), role, now, now,
+		)
+		if err != nil {
+			return err
+		}
+	} else {
+		err := s.update(ctx, "users",
+			`UPDATE users SET email = ?, nickname = ?, avatar = ?, description = ?, role = ?, updated_at = ? WHERE id = ?`,
+			u.Email, nickname, nullable(u.Avatar), nullable(u.Description), role, now, id,
+		)
+		if err != nil {
+			return err
+		}
+	}
+	s.users[u.Username] = id
+	return nil
+}
+
+func (s *Seeder) hash(password string) (string, error) {
+	if hash, ok := s.hashes[password]; ok {
+		return hash, nil
+	}
+	hash, err := utils.HashPassword(password)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	s.hashes[password] = hash
+	return hash, nil
+}
+
+// userID 按用户名查找用户，用户必须已存在于数据库或本次数据中
+func (s *Seeder) userID(ctx context.Context, username string) (int, error) {
+	if id, ok := s.users[username]; ok {
+		return id, nil
+	}
+	id, err := s.lookup(ctx, "SELECT id FROM users WHERE username = ?", username)
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("user %q not found", username)
+	}
+	s.users[username] = id
+	return id, nil
+}
+
+// userIDs 按用户名查找多个用户，去掉重复
+func (s *Seeder) userIDs(ctx context.Context, usernames []string) ([]int, error) {
+	var ids []int
+	seen := make(map[int]bool)
+	for _, username := range usernames {
+		id, err := s.userID(ctx, username)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids, nil
+}
+
+// submitter 提交者为空时写入 NULL
+func (s *Seeder) submitter(ctx context.Context, username string) (interface{}, error) {
+	if username == "" {
+		return nil, nil
+	}
+	return s.userID(ctx, username)
+}
+
+// ==================== 资源 ====================
+
+func (s *Seeder) tool(ctx context.Context, t Tool) error {
+	submitter, err := s.submitter(ctx, t.Submitter)
+	if err != nil {
+		return err
+	}
+	contributors := t.Contributors
+	if len(contributors) == 0 && t.Submitter != "" {
+		contributors = []string{t.Submitter}
+	}
+	contributorIDs, err := s.userIDs(ctx, contributors)
+	if err != nil {
+		return err
+	}
+	status := orDefault(t.Status, model.StatusApproved)
+	now := time.Now()
+
+	id, err := s.lookup(ctx, "SELECT resource_id FROM tools WHERE resource_name = ? ORDER BY resource_id LIMIT 1", t.Name)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		createdAt := orNow(t.CreatedAt, now)
+		id, err = s.insert(ctx, "tools",
+			`INSERT INTO tools (resource_type, resource_name, resource_link, description, description_detail, category,
+			views, status, audit_time, submitter_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			model.ResourceTypeTool, t.Name, t.Link, t.Description, t.Detail, t.Category,
+			t.Views, status, auditTime(status, createdAt), submitter, createdAt, createdAt,
+		)
+	} else {
+		err = s.update(ctx, "tools",
+			`UPDATE tools SET resource_link = ?, description = ?, description_detail = ?, category = ?,
+			status = ?, submitter_id = ?, updated_at = ? WHERE resource_id = ?`,
+			t.Link, t.Description, t.Detail, t.Category, status, submitter, now, id,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.replaceStrings(ctx, "tool_tags", "tool_id", id, "tag", unique(t.Tags), false); err != nil {
+		return err
+	}
+	if err := s.replaceStrings(ctx, "tool_images", "tool_id", id, "image_url", t.Images, true); err != nil {
+		return err
+	}
+	return s.replaceUsers(ctx, "tool_contributors", "tool_id", id, contributorIDs)
+}
+
+func (s *Seeder) course(ctx context.Context, c Course) error {
+	contributorIDs, err := s.userIDs(ctx, c.Contributors)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+
+	id, err := s.lookup(ctx,
+		"SELECT course_id FROM courses WHERE name = ? AND COALESCE(semester, '') = ? ORDER BY course_id LIMIT 1",
+		c.Name, c.Semester,
+	)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		createdAt := orNow(c.CreatedAt, now)
+		id, err = s.insert(ctx, "courses",
+			`INSERT INTO courses (resource_type, name, semester, credit, cover, views, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+			model.ResourceTypeCourse, c.Name, c.Semester, c.Credit, c.Cover, c.Views, createdAt, createdAt,
+		)
+	} else {
+		err = s.update(ctx, "courses",
+			`UPDATE courses SET credit = ?, cover = ?, updated_at = ? WHERE course_id = ?`,
+			c.Credit, c.Cover, now, id,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.replaceStrings(ctx, "course_teachers", "course_id", id, "teacher_name", c.Teachers, false); err != nil {
+		return err
+	}
+	if err := s.replaceStrings(ctx, "course_categories", "course_id", id, "category", unique(c.Categories), false); err != nil {
+		return err
+	}
+	if err := s.replaceUsers(ctx, "course_contributors", "course_id", id, contributorIDs); err != nil {
+		return err
+	}
+	for i, res := range c.Resources {
+		if err := s.courseResource(ctx, id, i, res); err != nil {
+			return fmt.Errorf("resource %q: %w", res.Intro, err)
+		}
+	}
+	return nil
+}
+
+func (s *Seeder) courseResource(ctx context.Context, courseID, sortOrder int, res CourseResource) error {
+	table, column := "course_resources_web", "resource_url"
+	switch orDefault(res.Type, "web") {
+	case "web":
+	case "upload":
+		table, column = "course_resources_upload", "resource_upload"
+	default:
+		return fmt.Errorf("unknown course resource type: %s", res.Type)
+	}
+	submitter, err := s.submitter(ctx, res.Submitter)
+	if err != nil {
+		return err
+	}
+	status := orDefault(res.Status, model.StatusApproved)
+	now := time.Now()
+
+	id, err := s.lookup(ctx,
+		fmt.Sprintf("SELECT resource_id FROM %s WHERE course_id = ? AND resource_intro = ? ORDER BY resource_id LIMIT 1", table),
+		courseID, res.Intro,
+	)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		_, err = s.insert(ctx, table,
+			fmt.Sprintf(`INSERT INTO %s (course_id, resource_intro, %s, sort_order, status, audit_time, submitter_id, created_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)`, table, column),
+			courseID, res.Intro, res.Resource, sortOrder, status, auditTime(status, now), submitter, now,
+		)
+		return err
+	}
+	return s.update(ctx, table,
+		fmt.Sprintf(`UPDATE %s SET %s = ?, sort_order = ?, status = ?, submitter_id = ? WHERE resource_id = ?`, table, column),
+		res.Resource, sortOrder, status, submitter, id,
+	)
+}
+
+func (s *Seeder) project(ctx context.Context, p Project) error {
+	authorIDs, err := s.userIDs(ctx, p.Authors)
+	if err != nil {
+		return err
+	}
+	// 第一位作者为提交者
+	var submitter interface{}
+	if len(authorIDs) > 0 {
+		submitter = authorIDs[0]
+	}
+	status := orDefault(p.Status, model.StatusApproved)
+	now := time.Now()
+
+	id, err := s.lookup(ctx, "SELECT project_id FROM projects WHERE name = ?", p.Name)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		createdAt := orNow(p.CreatedAt, now)
+		id, err = s.insert(ctx, "projects",
+			`INSERT INTO projects (resource_type, name, description, detail, github_url, category, cover,
+			views, status, audit_time, submitter_id, created_at, updated_at)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			model.ResourceTypeProject, p.Name, p.Description, p.Detail, p.GitHub, p.Category, p.Cover,
+			p.Views, status, auditTime(status, createdAt), submitter, createdAt, createdAt,
+		)
+	} else {
+		err = s.update(ctx, "projects",
+			`UPDATE projects SET description = ?, detail = ?, github_url = ?, category = ?, cover = ?,
+			status = ?, submitter_id = ?, updated_at = ? WHERE project_id = ?`,
+			p.Description, p.Detail, p.GitHub, p.Category, p.Cover, status, submitter, now, id,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := s.replaceStrings(ctx, "project_tech_stack", "project_id", id, "tech", unique(p.TechStack), false); err != nil {
+		return err
+	}
+	if err := s.replaceStrings(ctx, "project_images", "project_id", id, "image_url", p.Images, true); err != nil {
+		return err
+	}
+	return s.replaceUsers(ctx, "project_authors", "project_id", id, authorIDs)
+}
+
+// resourceID 按 Ref 查找资源，资源必须已存在于数据库或本次数据中
+func (s *Seeder) resourceID(ctx context.Context, ref Ref) (int, error) {
+	var id int
+	var err error
+	switch ref.Type {
+	case model.ResourceTypeTool:
+		id, err = s.lookup(ctx, "SELECT resource_id FROM tools WHERE resource_name = ? ORDER BY resource_id LIMIT 1", ref.Name)
+	case model.ResourceTypeCourse:
+		id, err = s.lookup(ctx,
+			"SELECT course_id FROM courses WHERE name = ? AND COALESCE(semester, '') = ? ORDER BY course_id LIMIT 1",
+			ref.Name, ref.Semester,
+		)
+	case model.ResourceTypeProject:
+		id, err = s.lookup(ctx, "SELECT project_id FROM projects WHERE name = ?", ref.Name)
+	default:
+		return 0, fmt.Errorf("unknown resource type: %s", ref.Type)
+	}
+	if err != nil {
+		return 0, err
+	}
+	if id == 0 {
+		return 0, fmt.Errorf("%s not found", ref)
+	}
+	return id, nil
+}
+
+// ==================== 评论 / 点赞 / 收藏 ====================
+
+func (s *Seeder) comment(ctx context.Context, c Comment) error {
+	resourceID, err := s.resourceID(ctx, c.Resource)
+	if err != nil {
+		return err
+	}
+	parentID, err := s.ensureComment(ctx, c.Resource.Type, resourceID, nil, c.User, c.Content)
+	if err != nil {
+		return err
+	}
+	for _, reply := range c.Replies {
+		if _, err := s.ensureComment(ctx, c.Resource.Type, resourceID, &parentID, reply.User, reply.Content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureComment 评论不存在时创建，返回评论ID；reply_total 由最后的计数校对修正
+func (s *Seeder) ensureComment(ctx context.Context, resourceType string, resourceID int, parentID *int, username, content string) (int, error) {
+	userID, err := s.userID(ctx, username)
+	if err != nil {
+		return 0, err
+	}
+
+	query := `SELECT comment_id FROM comments
+		WHERE resource_type = ? AND resource_id = ? AND user_id = ? AND content = ? AND deleted_at IS NULL AND `
+	args := []interface{}{resourceType, resourceID, userID, content}
+	var parent interface{}
+	if parentID == nil {
+		query += "parent_id IS NULL"
+	} else {
+		query += "parent_id = ?"
+		args = append(args, *parentID)
+		parent = *parentID
+	}
+	id, err := s.lookup(ctx, query+" ORDER BY comment_id LIMIT 1", args...)
+	if err != nil || id != 0 {
+		return id, err
+	}
+
+	now := time.Now()
+	return s.insert(ctx, "comments",
+		`INSERT INTO comments (resource_type, resource_id, parent_id, user_id, content, created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		resourceType, resourceID, parent, userID, content, now, now,
+	)
+}
+
+// relation 写入一条点赞或收藏，已存在时忽略；资源上的计数由最后的计数校对修正
+func (s *Seeder) relation(ctx context.Context, table string, r Relation) error {
+	userID, err := s.userID(ctx, r.User)
+	if err != nil {
+		return err
+	}
+	resourceID, err := s.resourceID(ctx, r.Resource)
+	if err != nil {
+		return err
+	}
+
+	query := s.db.Dialect.InsertIgnore(table, []string{"user_id", "resource_type", "resource_id", "created_at"})
+	result, err := s.db.ExecContext(ctx, query, userID, r.Resource.Type, resourceID, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to insert into %s: %w", table, err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows > 0 {
+		s.summary.Created[table]++
+	}
+	return nil
+}
+
+// ==================== 辅助函数 ====================
+
+// lookup 按自然键查找ID，不存在时返回 0
+func (s *Seeder) lookup(ctx context.Context, query string, args ...interface{}) (int, error) {
+	var id int
+	err := s.db.QueryRowContext(ctx, query, args...).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to look up: %w", err)
+	}
+	return id, nil
+}
+
+func (s *Seeder) insert(ctx context.Context, table, query string, args ...interface{}) (int, error) {
+	result, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to insert into %s: %w", table, err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get last insert id: %w", err)
+	}
+	s.summary.Created[table]++
+	return int(id), nil
+}
+
+func (s *Seeder) update(ctx context.Context, table, query string, args ...interface{}) error {
+	if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to update %s: %w", table, err)
+	}
+	s.summary.Updated[table]++
+	return nil
+}
+
+// replaceStrings 用 values 整体替换子表中的行，ordered 为 true 时按顺序写入 sort_order
+func (s *Seeder) replaceStrings(ctx context.Context, table, parentColumn string, parentID int, column string, values []string, ordered bool) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = ?", table, parentColumn), parentID); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", table, err)
+	}
+	for i, value := range values {
+		query := fmt.Sprintf("INSERT INTO %s (%s, %s) VALUES (?, ?)", table, parentColumn, column)
+		args := []interface{}{parentID, value}
+		if ordered {
+			query = fmt.Sprintf("INSERT INTO %s (%s, %s, sort_order) VALUES (?, ?, ?)", table, parentColumn, column)
+			args = append(args, i)
+		}
+		if _, err := s.db.ExecContext(ctx, query, args...); err != nil {
+			return fmt.Errorf("failed to insert into %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// replaceUsers 用 userIDs 整体替换贡献者、作者等关联表中的行
+func (s *Seeder) replaceUsers(ctx context.Context, table, parentColumn string, parentID int, userIDs []int) error {
+	if _, err := s.db.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE %s = ?", table, parentColumn), parentID); err != nil {
+		return fmt.Errorf("failed to clear %s: %w", table, err)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s, user_id) VALUES (?, ?)", table, parentColumn)
+	for _, userID := range userIDs {
+		if _, err := s.db.ExecContext(ctx, query, parentID, userID); err != nil {
+			return fmt.Errorf("failed to insert into %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+func orDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
+func orNow(t, now time.Time) time.Time {
+	if t.IsZero() {
+		return now
+	}
+	return t
+}
+
+// auditTime 已审核的资源使用 at 作为审核时间，待审核的为 NULL
+func auditTime(status string, at time.Time) interface{} {
+	if status == model.StatusPending {
+		return nil
+	}
+	return at
+}
+
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func unique(values []string) []string {
+	var result []string
+	seen := make(map[string]bool)
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	return result
+}
@@ -0,0 +1,233 @@
+// Package sensitive 敏感词过滤。词表建成 Aho-Corasick 自动机，一次扫描找出文本中的全部敏感词；
+// 匹配时忽略大小写和全角半角的区别，并跳过夹在词中的空白、标点和符号，如“傻 逼”“f.u.c.k”
+package sensitive
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Level 敏感词的等级，等级越高处理越严格
+type Level int
+
+const (
+	None   Level = iota
+	Mild         // 内容送人工审核
+	Middle       // 敏感词替换为 *
+	Severe       // 拒绝内容
+)
+
+var levelNames = map[Level]string{Mild: "mild", Middle: "middle", Severe: "severe"}
+
+func (l Level) String() string {
+	return levelNames[l]
+}
+
+// ParseLevel 解析 mild、middle、severe
+func ParseLevel(s string) (Level, bool) {
+	for level, name := range levelNames {
+		if name == s {
+			return level, true
+		}
+	}
+	return None, false
+}
+
+// Word 词表中的一个词
+type Word struct {
+	Text  string
+	Level Level
+}
+
+// Match 文本中命中的一个敏感词，Start、End 为原文中的字符（rune）下标，不含 End
+type Match struct {
+	Word  string
+	Level Level
+	Start int
+	End   int
+}
+
+// Result 过滤结果：Level 为命中的最高等级，Text 中 Middle 等级的词已替换为 *
+type Result struct {
+	Level Level
+	Text  string
+	Words []Word // 命中的词，按首次出现的顺序，不重复
+}
+
+// Matcher 只读的自动机，由 New 构造，可以并发使用
+type Matcher struct {
+	nodes []node
+	words []Word
+	// lengths 各词归一化后的字数，用于由结束位置推出开始位置
+	lengths []int
+}
+
+type node struct {
+	next map[rune]int
+	fail int
+	// out 以该节点结尾的词（含沿失败链可达的），为 words 的下标
+	out []int
+}
+
+// New 由词表构造自动机。词按归一化后的形式去重，重复时保留最高等级；归一化后为空的词被忽略
+func New(words []Word) *Matcher {
+	m := &Matcher{nodes: []node{{}}}
+	index := make(map[string]int)
+	for _, w := range words {
+		key := normalizeWord(w.Text)
+		if key == "" || w.Level == None {
+			continue
+		}
+		if i, ok := index[key]; ok {
+			if w.Level > m.words[i].Level {
+				m.words[i].Level = w.Level
+			}
+			continue
+		}
+		index[key] = len(m.words)
+		m.words = append(m.words, Word{Text: strings.TrimSpace(w.Text), Level: w.Level})
+		m.lengths = append(m.lengths, utf8.RuneCountInString(key))
+		m.insert(key, len(m.words)-1)
+	}
+	m.link()
+	return m
+}
+
+// Len 词表中的词数
+func (m *Matcher) Len() int {
+	return len(m.words)
+}
+
+func (m *Matcher) insert(key string, word int) {
+	n := 0
+	for _, c := range key {
+		child, ok := m.nodes[n].next[c]
+		if !ok {
+			child = len(m.nodes)
+			m.nodes = append(m.nodes, node{})
+			if m.nodes[n].next == nil {
+				m.nodes[n].next = make(map[rune]int)
+			}
+			m.nodes[n].next[c] = child
+		}
+		n = child
+	}
+	m.nodes[n].out = append(m.nodes[n].out, word)
+}
+
+// link 按层次遍历建立失败链接，并把失败节点的输出并入当前节点
+func (m *Matcher) link() {
+	queue := []int{}
+	for _, child := range m.nodes[0].next {
+		queue = append(queue, child)
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for c, child := range m.nodes[n].next {
+			f := m.nodes[n].fail
+			for f != 0 {
+				if _, ok := m.nodes[f].next[c]; ok {
+					break
+				}
+				f = m.nodes[f].fail
+			}
+			if next, ok := m.nodes[f].next[c]; ok && next != child {
+				m.nodes[child].fail = next
+			}
+			fail := m.nodes[child].fail
+			m.nodes[child].out = append(m.nodes[child].out, m.nodes[fail].out...)
+			queue = append(queue, child)
+		}
+	}
+}
+
+// FindAll 按结束位置先后返回文本中命中的全部敏感词，命中位置可能重叠
+func (m *Matcher) FindAll(text string) []Match {
+	var matches []Match
+	// positions 为参与匹配的字符在原文中的下标，跳过的空白和标点不在其中
+	var positions []int
+	n := 0
+	for i, c := range []rune(text) {
+		if isNoise(c) {
+			continue
+		}
+		c = fold(c)
+		positions = append(positions, i)
+		for n != 0 {
+			if _, ok := m.nodes[n].next[c]; ok {
+				break
+			}
+			n = m.nodes[n].fail
+		}
+		n = m.nodes[n].next[c] // 根节点没有该字符时为 0，回到根节点
+		for _, w := range m.nodes[n].out {
+			matches = append(matches, Match{
+				Word:  m.words[w].Text,
+				Level: m.words[w].Level,
+				Start: positions[len(positions)-m.lengths[w]],
+				End:   i + 1,
+			})
+		}
+	}
+	return matches
+}
+
+// Filter 检查文本，把 Middle 等级的词（连同夹在其中的标点）替换为 *
+func (m *Matcher) Filter(text string) Result {
+	result := Result{Text: text}
+	matches := m.FindAll(text)
+	if len(matches) == 0 {
+		return result
+	}
+
+	runes := []rune(text)
+	seen := make(map[string]bool)
+	masked := false
+	for _, match := range matches {
+		if match.Level > result.Level {
+			result.Level = match.Level
+		}
+		if !seen[match.Word] {
+			seen[match.Word] = true
+			result.Words = append(result.Words, Word{Text: match.Word, Level: match.Level})
+		}
+		if match.Level == Middle {
+			for i := match.Start; i < match.End; i++ {
+				if !unicode.IsSpace(runes[i]) {
+					runes[i] = '*'
+				}
+			}
+			masked = true
+		}
+	}
+	if masked {
+		result.Text = string(runes)
+	}
+	return result
+}
+
+// isNoise 匹配时跳过的字符：空白、标点和符号
+func isNoise(c rune) bool {
+	return unicode.IsSpace(c) || unicode.IsPunct(c) || unicode.IsSymbol(c)
+}
+
+// fold 全角字符转为半角，再转为小写
+func fold(c rune) rune {
+	if c >= '！' && c <= '～' {
+		c -= '！' - '!'
+	}
+	return unicode.ToLower(c)
+}
+
+// normalizeWord 词表中的词去掉空白和标点、统一大小写和全半角后的形式
+func normalizeWord(text string) string {
+	var b strings.Builder
+	for _, c := range text {
+		if !isNoise(c) {
+			b.WriteRune(fold(c))
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,110 @@
+package sensitive
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFindAll(t *testing.T) {
+	tests := []struct {
+		name  string
+		words []string
+		text  string
+		want  string // 各命中的 词[Start,End)
+	}{
+		// 重叠的词都被找到，同一位置结束的按沿失败链的顺序
+		{"overlap", []string{"he", "she", "his", "hers"}, "ushers", "she[1,4) he[2,4) hers[2,6)"},
+		{"repeated", []string{"aa"}, "aaaa", "aa[0,2) aa[1,3) aa[2,4)"},
+		// abcd 在 x 处失配，沿失败链转到 bc 继续匹配 bcx
+		{"failure link", []string{"abcd", "bcx"}, "abcx", "bcx[1,4)"},
+		{"failure to root", []string{"abc", "c"}, "abxc", "c[3,4)"},
+		// 跳过夹在词中的空白、标点和符号，位置为原文中的下标
+		{"noise", []string{"fuck"}, "f.u.c.k", "fuck[0,7)"},
+		{"noise between chinese", []string{"傻逼"}, "你是傻 逼吗", "傻逼[2,5)"},
+		{"noise in word list", []string{"f-u-c-k"}, "FUCK", "f-u-c-k[0,4)"},
+		{"leading noise", []string{"ab"}, "..a*b..", "ab[2,5)"},
+		// 全角转半角、大小写不敏感
+		{"full-width text", []string{"fuck"}, "ＦＵＣＫ", "fuck[0,4)"},
+		{"full-width word", []string{"ＡＢ"}, "xab", "ＡＢ[1,3)"},
+		{"none", []string{"abc"}, "acb ab-d", ""},
+	}
+	for _, tt := range tests {
+		var words []Word
+		for _, w := range tt.words {
+			words = append(words, Word{Text: w, Level: Mild})
+		}
+		got := ""
+		for i, match := range New(words).FindAll(tt.text) {
+			if i > 0 {
+				got += " "
+			}
+			got += fmt.Sprintf("%s[%d,%d)", match.Word, match.Start, match.End)
+		}
+		if got != tt.want {
+			t.Errorf("%s: FindAll(%q) = %s, want %s", tt.name, tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	m := New([]Word{
+		{Text: "傻逼", Level: Middle},
+		{Text: "fuck", Level: Middle},
+		{Text: "赌博", Level: Mild},
+		{Text: "赌博网站", Level: Severe},
+		{Text: "代写", Level: Mild},
+	})
+
+	tests := []struct {
+		text  string
+		level Level
+		out   string
+		words string
+	}{
+		{"正常内容", None, "正常内容", "[]"},
+		// Middle 等级的词连同夹在其中的标点替换为 *，空白保留
+		{"你是傻 逼吗", Middle, "你是* *吗", "[{傻逼 middle}]"},
+		{"f.u.c.k off", Middle, "******* off", "[{fuck middle}]"},
+		{"ＦＵＣＫ！", Middle, "****！", "[{fuck middle}]"},
+		{"傻逼傻逼", Middle, "****", "[{傻逼 middle}]"},
+		// Mild 和 Severe 不替换，结果取最高等级
+		{"代写作业", Mild, "代写作业", "[{代写 mild}]"},
+		{"推荐赌博网站", Severe, "推荐赌博网站", "[{赌博 mild} {赌博网站 severe}]"},
+		{"傻逼，代写", Middle, "**，代写", "[{傻逼 middle} {代写 mild}]"},
+		{"傻逼的赌博网站", Severe, "**的赌博网站", "[{傻逼 middle} {赌博 mild} {赌博网站 severe}]"},
+	}
+	for _, tt := range tests {
+		result := m.Filter(tt.text)
+		if words := fmt.Sprint(result.Words); result.Level != tt.level || result.Text != tt.out || words != tt.words {
+			t.Errorf("Filter(%q) = %v %q %s, want %v %q %s", tt.text, result.Level, result.Text, words, tt.level, tt.out, tt.words)
+		}
+	}
+}
+
+func TestNewDeduplicates(t *testing.T) {
+	// 归一化后相同的词只保留一个，取最高等级；归一化后为空或没有等级的词被忽略
+	m := New([]Word{
+		{Text: "Spam", Level: Mild},
+		{Text: " s.p.a.m ", Level: Severe},
+		{Text: "ＳＰＡＭ", Level: Middle},
+		{Text: "...", Level: Severe},
+		{Text: "ham", Level: None},
+	})
+	if m.Len() != 1 {
+		t.Fatalf("Len = %d, want 1", m.Len())
+	}
+	if result := m.Filter("spam"); result.Level != Severe || fmt.Sprint(result.Words) != "[{Spam severe}]" {
+		t.Errorf("Filter(spam) = %v %v, want severe", result.Level, result.Words)
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for _, level := range []Level{Mild, Middle, Severe} {
+		if got, ok := ParseLevel(level.String()); !ok || got != level {
+			t.Errorf("ParseLevel(%q) = %v, %v", level.String(), got, ok)
+		}
+	}
+	if _, ok := ParseLevel("none"); ok {
+		t.Errorf("ParseLevel(none): want false")
+	}
+}
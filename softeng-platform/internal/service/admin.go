@@ -2,59 +2,211 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
 	"softeng-platform/internal/repository"
+	"time"
 )
 
+// ErrTooManyItems 批量操作的项数超过上限
+var ErrTooManyItems = errors.New("too many items")
+
+// ErrVersionRequired 批量审核中的工具或项目没有给出版本号，相当于缺少 If-Match
+var ErrVersionRequired = errors.New("version required")
+
 type AdminService interface {
-	GetPending(ctx context.Context, itemType string, cursor, limit int, sort string) (map[string]interface{}, error)
-	ReviewItem(ctx context.Context, itemID, action, rejectReason string) error
+	// GetPending 获取待审核内容，filter 按认领情况筛选工具、项目和课程资源，评论队列不能认领，忽略 filter
+	GetPending(ctx context.Context, itemType string, filter model.PendingFilter, page pagination.Request, sort string) (*model.PendingList, error)
+	// ReviewItem 管理员审核工具、项目或课程资源：approve、reject（需要理由）或 hide，
+	// 当前状态不允许时返回 ErrInvalidTransition，被其他管理员认领时返回 *ClaimConflictError；
+	// version 的含义同 TrashService。审核后释放自己的认领
+	ReviewItem(ctx context.Context, operatorID int, resourceType string, itemID, version int, action, rejectReason string) (*model.Maneuver, error)
+	// ReviewItems 批量通过或拒绝，每一项与 ReviewItem 相同，在各自的事务中执行并分别记入审计日志，
+	// 一项失败不影响其他项；项数超过上限时返回 ErrTooManyItems，整批不执行
+	ReviewItems(ctx context.Context, operatorID int, req model.BulkReviewRequest) (*model.BulkReviewResponse, error)
+	// ReviewHistory 按时间先后返回资源的状态变更记录
+	ReviewHistory(ctx context.Context, resourceType string, itemID int) ([]model.StatusLog, error)
+	// ModerateComment 处理评论审核队列中的评论：dismiss 驳回举报并恢复显示，hide 隐藏，delete 删除，
+	// warn 隐藏并警告作者；评论的待处理举报一起标为已处理，结果通知作者
+	ModerateComment(ctx context.Context, moderatorID, commentID int, action, note string) (*model.ModerationResult, error)
+	// ClaimItem 认领待审核资源，租期内其他管理员不能审核；已是自己认领的则续期。记入审计日志
+	ClaimItem(ctx context.Context, reviewerID int, resourceType string, itemID int) (*model.ReviewClaim, error)
+	// ReleaseItem 放弃自己的认领并记入审计日志，没有认领时什么也不做
+	ReleaseItem(ctx context.Context, reviewerID int, resourceType string, itemID int) error
+	// AssignPending 把还没有分配的待审核资源轮流分配给各管理员，返回分配的数目；
+	// 分配了资源时记入审计日志，定时任务调用时 ctx 中没有管理员，记为系统操作
+	AssignPending(ctx context.Context) (int, error)
 }
 
 type adminService struct {
 	toolRepo    repository.ToolRepository
 	courseRepo  repository.CourseRepository
 	projectRepo repository.ProjectRepository
+	reviews     repository.ReviewRepository
+	reports     repository.CommentReportRepository
+	claims      repository.ReviewClaimRepository
+	index       repository.SearchIndex
+	notifier    *Notifier
+	auditor     *Auditor
+	cursors     *pagination.Codec
+	batchLimit  int
+	claimLease  time.Duration
 }
 
-func NewAdminService(toolRepo repository.ToolRepository, courseRepo repository.CourseRepository, projectRepo repository.ProjectRepository) AdminService {
+// NewAdminService index 为统一检索的索引，审核结果生效后同步；notifier 通知评论作者审核结果；
+// 审核和评论处理记入 auditor 的审计日志；batchLimit 为批量审核一次最多处理的项数，claimLease 为认领的租期
+func NewAdminService(toolRepo repository.ToolRepository, courseRepo repository.CourseRepository, projectRepo repository.ProjectRepository,
+	reviews repository.ReviewRepository, reports repository.CommentReportRepository, claims repository.ReviewClaimRepository,
+	index repository.SearchIndex, notifier *Notifier, auditor *Auditor, cursors *pagination.Codec, batchLimit int, claimLease time.Duration) AdminService {
 	return &adminService{
 		toolRepo:    toolRepo,
 		courseRepo:  courseRepo,
 		projectRepo: projectRepo,
+		reviews:     reviews,
+		reports:     reports,
+		claims:      claims,
+		index:       index,
+		notifier:    notifier,
+		auditor:     auditor,
+		cursors:     cursors,
+		batchLimit:  batchLimit,
+		claimLease:  claimLease,
 	}
 }
 
-func (s *adminService) GetPending(ctx context.Context, itemType string, cursor, limit int, sort string) (map[string]interface{}, error) {
-	var data []map[string]interface{}
-	var err error
+func (s *adminService) GetPending(ctx context.Context, itemType string, filter model.PendingFilter, page pagination.Request, sort string) (*model.PendingList, error) {
+	// 审核队列总是返回待审核总数
+	scope := "pending:" + itemType
+	page.WithTotal = true
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
 
+	var result *pagination.Result[model.Submit]
 	switch itemType {
 	case "工具":
-		data, err = s.toolRepo.GetPending(ctx, cursor, limit)
+		result, err = s.toolRepo.GetPending(ctx, filter, p)
 	case "课程":
-		data, err = s.courseRepo.GetPending(ctx, cursor, limit)
+		result, err = s.courseRepo.GetPending(ctx, filter, p)
 	case "项目":
-		data, err = s.projectRepo.GetPending(ctx, cursor, limit)
+		result, err = s.projectRepo.GetPending(ctx, filter, p)
 	case "评论":
-		// 获取待审核评论
-		data = []map[string]interface{}{}
+		// 被举报或被系统标记、还没有处理的评论
+		var reported *pagination.Result[model.ReportedComment]
+		if reported, err = s.reports.Pending(ctx, p); err == nil {
+			result = &pagination.Result[model.Submit]{Info: reported.Info}
+			for _, comment := range reported.Items {
+				result.Items = append(result.Items, pendingComment(comment))
+			}
+		}
 	default:
-		data = []map[string]interface{}{}
+		result = &pagination.Result[model.Submit]{}
+	}
+	if err == nil && itemType != "评论" {
+		err = s.fillClaims(ctx, result.Items)
 	}
 
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"total":  len(data),
-		"cursor": cursor,
-		"data":   data,
+	data := result.Items
+	if data == nil {
+		data = []model.Submit{}
+	}
+	info := pageInfo(s.cursors, scope, result.Info)
+	if info.Total == nil {
+		total := len(data)
+		info.Total = &total
+	}
+
+	return &model.PendingList{
+		PageInfo: info,
+		Data:     data,
 	}, nil
 }
 
-func (s *adminService) ReviewItem(ctx context.Context, itemID, action, rejectReason string) error {
-	// 根据itemID的类型执行相应的审核操作
-	// 这里需要实现具体的审核逻辑
-	return nil
+func (s *adminService) ReviewItem(ctx context.Context, operatorID int, resourceType string, itemID, version int, action, rejectReason string) (*model.Maneuver, error) {
+	maneuver, err := audited(ctx, s.auditor, func(ctx context.Context) (*model.Maneuver, *auditChange, error) {
+		if err := checkReviewType(resourceType); err != nil {
+			return nil, nil, err
+		}
+		target := model.ReviewTarget{ResourceType: resourceType, ResourceID: itemID}
+		before, err := s.reviews.Get(ctx, 0, resourceType, itemID)
+		if err != nil {
+			return nil, nil, err
+		}
+		// 资源不存在时由 transitionStatus 返回错误，不为它建认领记录
+		if before != nil {
+			if err := s.lockClaim(ctx, operatorID, target); err != nil {
+				return nil, nil, err
+			}
+		}
+		maneuver, err := transitionStatus(ctx, s.reviews, operatorID, false, resourceType, itemID, version, action, rejectReason)
+		if err != nil {
+			return nil, nil, err
+		}
+		// 审核完成后资源不再待审核，认领随之结束
+		if _, err := s.claims.Release(ctx, operatorID, target); err != nil {
+			return nil, nil, err
+		}
+		after, err := s.reviews.Get(ctx, 0, resourceType, itemID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return maneuver, &auditChange{action: model.AuditReviewPrefix + action, targetType: resourceType,
+			targetID: itemID, before: before, after: after}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	// 通过、隐藏改变了工具和项目能否被检索到；课程资源不单独索引
+	if resourceType == model.ResourceTypeTool || resourceType == model.ResourceTypeProject {
+		updateSearchIndex(ctx, s.index, resourceType, itemID)
+	}
+	return maneuver, nil
+}
+
+func (s *adminService) ReviewItems(ctx context.Context, operatorID int, req model.BulkReviewRequest) (*model.BulkReviewResponse, error) {
+	if req.Action != "approve" && req.Action != "reject" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAction, req.Action)
+	}
+	if len(req.Items) > s.batchLimit {
+		return nil, fmt.Errorf("%w: at most %d items per batch", ErrTooManyItems, s.batchLimit)
+	}
+
+	resp := &model.BulkReviewResponse{Results: make([]model.BulkReviewResult, 0, len(req.Items))}
+	for _, item := range req.Items {
+		reason := item.RejectReason
+		if reason == "" {
+			reason = req.RejectReason
+		}
+		result := model.BulkReviewResult{ResourceType: item.ResourceType, ResourceID: item.ResourceID}
+		version := 0
+		if item.Version != nil {
+			version = *item.Version
+		}
+		if item.Version == nil && model.ReviewVersioned(item.ResourceType) {
+			result.Err = ErrVersionRequired
+		} else {
+			result.Manipulate, result.Err = s.ReviewItem(ctx, operatorID, item.ResourceType, item.ResourceID, version, req.Action, reason)
+		}
+		if result.Err != nil {
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+	return resp, nil
+}
+
+func (s *adminService) ReviewHistory(ctx context.Context, resourceType string, itemID int) ([]model.StatusLog, error) {
+	if err := checkReviewType(resourceType); err != nil {
+		return nil, err
+	}
+	return s.reviews.History(ctx, resourceType, itemID)
 }
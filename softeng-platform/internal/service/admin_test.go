@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/mail"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+	"testing"
+	"time"
+)
+
+func TestReviewItemClaimConflict(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	users := repository.NewMemoryUserRepository(store)
+	tools := repository.NewMemoryToolRepository(store)
+	reviews := repository.NewMemoryReviewRepository(store)
+	claims := repository.NewMemoryReviewClaimRepository(store)
+	audit := repository.NewMemoryAuditLogRepository(store)
+	svc := NewAdminService(tools, repository.NewMemoryCourseRepository(store), repository.NewMemoryProjectRepository(store),
+		reviews, repository.NewMemoryCommentReportRepository(store), claims, repository.NewSQLSearchIndex(repository.NewMemorySearchRepository(store)),
+		NewNotifier(repository.NewMemoryNotificationRepository(store), users, mail.New(mail.SMTPConfig{})),
+		NewAuditor(store, audit), pagination.NewCodec("test"), 10, time.Hour)
+
+	var ids []int
+	for _, u := range []struct{ name, role string }{{"zack", "user"}, {"amy", "admin"}, {"ben", "admin"}} {
+		user := &model.User{Username: u.name, Nickname: u.name, Email: u.name + "@example.com", Password: "hashed", Role: u.role}
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("create user %s: %v", u.name, err)
+		}
+		ids = append(ids, user.ID)
+	}
+	author, amy, ben := ids[0], ids[1], ids[2]
+	state := func(id int) *model.ReviewState {
+		t.Helper()
+		s, err := reviews.Get(ctx, 0, model.ResourceTypeTool, id)
+		if err != nil || s == nil {
+			t.Fatalf("Get tool %d: %v, %v", id, s, err)
+		}
+		return s
+	}
+	approvals := func() int {
+		t.Helper()
+		result, err := audit.List(ctx, model.AuditFilter{Action: model.AuditReviewPrefix + "approve"}, pagination.Page{Limit: 10})
+		if err != nil {
+			t.Fatalf("Audit.List: %v", err)
+		}
+		return len(result.Items)
+	}
+
+	review, err := tools.Create(ctx, author, model.ToolSubmitRequest{Name: "alpha", Link: "https://example.com/alpha",
+		Description: "alpha", DescriptionDetail: "alpha", Category: "IDE"})
+	if err != nil {
+		t.Fatalf("create tool: %v", err)
+	}
+	alpha := review.ResourceID
+	if _, err := svc.ClaimItem(ctx, ben, model.ResourceTypeTool, alpha); err != nil {
+		t.Fatalf("ClaimItem: %v", err)
+	}
+
+	// 他人认领期间审核失败，单独审核和批量审核都返回认领人，资源状态和版本不变，不记审计日志
+	var conflict *ClaimConflictError
+	if _, err := svc.ReviewItem(ctx, amy, model.ResourceTypeTool, alpha, 1, "approve", ""); !errors.As(err, &conflict) || conflict.Claim.ClaimantID != ben {
+		t.Fatalf("ReviewItem of a claimed tool: got %v; want *ClaimConflictError by %d", err, ben)
+	}
+	version := 1
+	bulk, err := svc.ReviewItems(ctx, amy, model.BulkReviewRequest{Action: "approve",
+		Items: []model.BulkReviewItem{{ResourceType: model.ResourceTypeTool, ResourceID: alpha, Version: &version}}})
+	if err != nil || bulk.Failed != 1 || !errors.As(bulk.Results[0].Err, &conflict) {
+		t.Fatalf("ReviewItems of a claimed tool: got %+v, %v; want a claim conflict", bulk, err)
+	}
+	if s := state(alpha); s.AuditStatus != model.StatusPending || s.Version != 1 {
+		t.Errorf("claimed tool after blocked reviews: got %s version %d; want pending version 1", s.AuditStatus, s.Version)
+	}
+	if n := approvals(); n != 0 {
+		t.Errorf("blocked reviews wrote %d audit logs", n)
+	}
+
+	// 认领人可以审核，审核后认领随之结束
+	if _, err := svc.ReviewItem(ctx, ben, model.ResourceTypeTool, alpha, 1, "approve", ""); err != nil {
+		t.Fatalf("ReviewItem by claimant: %v", err)
+	}
+	if s := state(alpha); s.AuditStatus != model.StatusApproved {
+		t.Errorf("tool after claimant's review: got %s; want approved", s.AuditStatus)
+	}
+	if claim, err := claims.Get(ctx, model.ReviewTarget{ResourceType: model.ResourceTypeTool, ResourceID: alpha}); err != nil || (claim != nil && claim.ClaimantID != 0) {
+		t.Errorf("claim after review: got %+v, %v; want released", claim, err)
+	}
+	if n := approvals(); n != 1 {
+		t.Errorf("approve audit logs: got %d, want 1", n)
+	}
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/tagquery"
+	"strings"
+	"time"
+)
+
+const (
+	// maxSavedSearches 每个用户最多保存的检索数
+	maxSavedSearches = 20
+	// maxAlertItems 一条提醒中最多列出的资源数
+	maxAlertItems = 10
+	// maxAlertBacklog 检查进度最多落后的时长，更早上架的资源不再提醒，
+	// 一条长期发不出去的检索不会让每次检查的资源越积越多
+	maxAlertBacklog = 24 * time.Hour
+	// alertRetryDelay 提醒发送失败后第一次重试的间隔，之后每次失败加倍
+	alertRetryDelay = time.Minute
+	// maxAlertRetries 连续发送失败的次数上限，达到后放弃这批资源并推进进度
+	maxAlertRetries = 5
+)
+
+var (
+	// ErrSavedSearchNotFound 保存的检索不存在或不属于当前用户
+	ErrSavedSearchNotFound = errors.New("saved search not found")
+	// ErrInvalidSavedSearch 检索条件为空、提醒方式无效或超过数量上限
+	ErrInvalidSavedSearch = errors.New("invalid saved search")
+)
+
+// resourceTypeNames 提醒内容中资源类型的名称
+var resourceTypeNames = map[string]string{
+	model.ResourceTypeTool:    "工具",
+	model.ResourceTypeCourse:  "课程",
+	model.ResourceTypeProject: "项目",
+}
+
+// SavedSearchService 用户保存的检索，新资源满足条件时由 AlertMatcher 提醒
+type SavedSearchService interface {
+	// Create 保存检索，只提醒保存之后上架的资源
+	Create(ctx context.Context, userID int, req model.SavedSearchRequest) (*model.DataResponse[*model.SavedSearch], error)
+	List(ctx context.Context, userID int) (*model.ListResponse[model.SavedSearch], error)
+	Delete(ctx context.Context, userID, id int) error
+}
+
+type savedSearchService struct {
+	searches repository.SavedSearchRepository
+}
+
+func NewSavedSearchService(searches repository.SavedSearchRepository) SavedSearchService {
+	return &savedSearchService{searches: searches}
+}
+
+func (s *savedSearchService) Create(ctx context.Context, userID int, req model.SavedSearchRequest) (*model.DataResponse[*model.SavedSearch], error) {
+	channel := req.Channel
+	if channel == "" {
+		channel = model.ChannelInApp
+	}
+	if channel != model.ChannelInApp && channel != model.ChannelEmail {
+		return nil, fmt.Errorf("%w: unknown channel %q", ErrInvalidSavedSearch, channel)
+	}
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidSavedSearch)
+	}
+	if req.Query.IsEmpty() {
+		return nil, fmt.Errorf("%w: query has no conditions", ErrInvalidSavedSearch)
+	}
+	for _, resourceType := range req.Query.Types {
+		if err := checkResourceType(resourceType); err != nil {
+			return nil, err
+		}
+	}
+	existing, err := s.searches.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxSavedSearches {
+		return nil, fmt.Errorf("%w: at most %d saved searches", ErrInvalidSavedSearch, maxSavedSearches)
+	}
+
+	search := &model.SavedSearch{
+		UserID:    userID,
+		Name:      name,
+		Query:     req.Query,
+		Channel:   channel,
+		CheckedAt: alertCheckpoint(),
+	}
+	if err := s.searches.Create(ctx, search); err != nil {
+		return nil, err
+	}
+	return model.NewDataResponse("Saved search created", search), nil
+}
+
+func (s *savedSearchService) List(ctx context.Context, userID int) (*model.ListResponse[model.SavedSearch], error) {
+	searches, err := s.searches.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewListResponse("success", searches), nil
+}
+
+func (s *savedSearchService) Delete(ctx context.Context, userID, id int) error {
+	deleted, err := s.searches.Delete(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSavedSearchNotFound
+	}
+	return nil
+}
+
+// alertCheckpoint 检查进度取整到秒：MySQL 的 TIMESTAMP 列只保存到秒，
+// 进度写回数据库后与下一次查询的起点一致，上架时间落在边界上的资源不会漏掉或重复
+func alertCheckpoint() time.Time {
+	return time.Now().Truncate(time.Second)
+}
+
+// savedQuery 编译好标签条件的检索条件
+type savedQuery struct {
+	model.SavedQuery
+	tags, techStack tagquery.Expr
+}
+
+// compileSavedQuery 与统一检索一样把 tag、techStack 当作字面的标签，取值之间为“或”
+func compileSavedQuery(q model.SavedQuery) *savedQuery {
+	return &savedQuery{SavedQuery: q, tags: tagquery.AnyOf(q.Tags), techStack: tagquery.AnyOf(q.TechStack)}
+}
+
+// match 判断资源是否满足条件，筛选规则与统一检索相同：按某类资源特有的字段筛选时其他类资源不满足
+func (q *savedQuery) match(doc *model.SearchDocument) bool {
+	if len(q.Types) > 0 && !slices.Contains(q.Types, doc.ResourceType) {
+		return false
+	}
+	switch doc.ResourceType {
+	case model.ResourceTypeTool:
+		if q.techStack != nil || len(q.Semester) > 0 || !tagquery.Match(q.tags, doc.Tags) {
+			return false
+		}
+	case model.ResourceTypeCourse:
+		if q.tags != nil || q.techStack != nil || (len(q.Semester) > 0 && !slices.Contains(q.Semester, doc.Semester)) {
+			return false
+		}
+	case model.ResourceTypeProject:
+		if q.tags != nil || len(q.Semester) > 0 || !tagquery.Match(q.techStack, doc.Tags) {
+			return false
+		}
+	}
+	if len(q.Category) > 0 && !slices.ContainsFunc(doc.Category, func(c string) bool { return slices.Contains(q.Category, c) }) {
+		return false
+	}
+	return repository.MatchKeyword(doc, q.Keyword)
+}
+
+// AlertMatcher 定期检查新上架的资源，向保存了匹配检索的用户发送提醒。
+// 每个用户在 window 内最多收到 limit 条提醒，超出时保留检查进度，额度恢复后把积累的结果合并为一条提醒。
+// Match 不能并发调用
+type AlertMatcher struct {
+	searches      repository.SavedSearchRepository
+	source        repository.SearchRepository
+	notifications repository.NotificationRepository
+	notifier      *Notifier
+	limit         int
+	window        time.Duration
+	retries       map[int]alertRetry // 发送失败等待重试的检索，只保存在内存中
+}
+
+// alertRetry 一条检索连续发送失败的次数和下次重试的时间
+type alertRetry struct {
+	failures int
+	retryAt  time.Time
+}
+
+func NewAlertMatcher(searches repository.SavedSearchRepository, source repository.SearchRepository,
+	notifications repository.NotificationRepository, notifier *Notifier, limit int, window time.Duration) *AlertMatcher {
+	return &AlertMatcher{
+		searches:      searches,
+		source:        source,
+		notifications: notifications,
+		notifier:      notifier,
+		limit:         limit,
+		window:        window,
+		retries:       make(map[int]alertRetry),
+	}
+}
+
+// approvedDocument 新上架的资源及其上架时间
+type approvedDocument struct {
+	doc        *model.SearchDocument
+	approvedAt time.Time
+}
+
+// Match 检查各条保存的检索上次检查以来上架的资源并发送提醒，返回发送的提醒数。
+// 最多检查 maxAlertBacklog 以内上架的资源。
+// 单条提醒发送失败只记录日志，不推进该检索的进度，按指数退避重试，连续失败 maxAlertRetries 次后放弃
+func (m *AlertMatcher) Match(ctx context.Context) (int, error) {
+	searches, err := m.searches.List(ctx, 0)
+	if err != nil || len(searches) == 0 {
+		return 0, err
+	}
+
+	until := alertCheckpoint()
+	since := until
+	for _, search := range searches {
+		if search.CheckedAt.Before(since) {
+			since = search.CheckedAt
+		}
+	}
+	if oldest := until.Add(-maxAlertBacklog); since.Before(oldest) {
+		since = oldest
+	}
+	approved, err := m.searches.Approved(ctx, since, until)
+	if err != nil {
+		return 0, err
+	}
+	var docs []approvedDocument
+	for _, res := range approved {
+		doc, err := m.source.Document(ctx, res.ResourceType, res.ResourceID)
+		if err != nil {
+			return 0, err
+		}
+		// 上架后又被删除或下架的资源不再提醒
+		if doc != nil {
+			docs = append(docs, approvedDocument{doc: doc, approvedAt: res.ApprovedAt})
+		}
+	}
+
+	sent := make(map[int]int) // 用户在窗口内已收到的提醒数，第一次用到时查询
+	notified := 0
+	for _, search := range searches {
+		query := compileSavedQuery(search.Query)
+		var matched []*model.SearchDocument
+		for _, d := range docs {
+			if d.approvedAt.After(search.CheckedAt) && query.match(d.doc) {
+				matched = append(matched, d.doc)
+			}
+		}
+		if len(matched) == 0 {
+			if err := m.searches.MarkChecked(ctx, search.ID, until, nil); err != nil {
+				return notified, err
+			}
+			continue
+		}
+		retry, retrying := m.retries[search.ID]
+		if retrying && until.Before(retry.retryAt) {
+			continue
+		}
+
+		count, ok := sent[search.UserID]
+		if !ok {
+			if count, err = m.notifications.Count(ctx, search.UserID, model.NotificationSavedSearch, until.Add(-m.window)); err != nil {
+				return notified, err
+			}
+			sent[search.UserID] = count
+		}
+		if count >= m.limit {
+			continue
+		}
+
+		if err := m.notifier.Notify(ctx, alertNotification(search, matched)); err != nil {
+			retry.failures++
+			if retry.failures < maxAlertRetries {
+				retry.retryAt = until.Add(alertRetryDelay << (retry.failures - 1))
+				m.retries[search.ID] = retry
+				log.Printf("Failed to send saved search alert %d (attempt %d), retrying at %s: %v",
+					search.ID, retry.failures, retry.retryAt.Format(time.DateTime), err)
+				continue
+			}
+			delete(m.retries, search.ID)
+			log.Printf("Giving up saved search alert %d after %d attempts: %v", search.ID, retry.failures, err)
+			if err := m.searches.MarkChecked(ctx, search.ID, until, nil); err != nil {
+				return notified, err
+			}
+			continue
+		}
+		delete(m.retries, search.ID)
+		sent[search.UserID]++
+		notified++
+		now := time.Now()
+		if err := m.searches.MarkChecked(ctx, search.ID, until, &now); err != nil {
+			return notified, err
+		}
+	}
+	return notified, nil
+}
+
+// alertNotification 一条检索提醒，列出前 maxAlertItems 个新资源
+func alertNotification(search model.SavedSearch, matched []*model.SearchDocument) *model.Notification {
+	var b strings.Builder
+	for i, doc := range matched {
+		if i == maxAlertItems {
+			fmt.Fprintf(&b, "……等共 %d 个\n", len(matched))
+			break
+		}
+		fmt.Fprintf(&b, "%s：%s\n", resourceTypeNames[doc.ResourceType], doc.Name)
+	}
+
+	n := &model.Notification{
+		UserID:  search.UserID,
+		Kind:    model.NotificationSavedSearch,
+		Title:   fmt.Sprintf("“%s”有 %d 个新结果", search.Name, len(matched)),
+		Content: strings.TrimSuffix(b.String(), "\n"),
+		Channel: search.Channel,
+		RefID:   search.ID,
+	}
+	if len(matched) == 1 {
+		n.ResourceType, n.ResourceID = matched[0].ResourceType, matched[0].ResourceID
+	}
+	return n
+}
+
+// Run 每隔 interval 检查一次新上架的资源，直到 ctx 取消
+func (m *AlertMatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			notified, err := m.Match(ctx)
+			if err != nil {
+				log.Printf("Failed to match saved searches: %v", err)
+				continue
+			}
+			if notified > 0 {
+				log.Printf("Sent %d saved search alerts", notified)
+			}
+		}
+	}
+}
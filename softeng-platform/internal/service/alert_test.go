@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"testing"
+	"time"
+)
+
+// failingMailer 每次发送都失败，记录发送次数
+type failingMailer struct {
+	sent int
+}
+
+func (m *failingMailer) Send(ctx context.Context, to, subject, body string) error {
+	m.sent++
+	return errors.New("smtp unavailable")
+}
+
+func TestAlertMatcherRetries(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	users := repository.NewMemoryUserRepository(store)
+	tools := repository.NewMemoryToolRepository(store)
+	searches := repository.NewMemorySavedSearchRepository(store)
+	notifications := repository.NewMemoryNotificationRepository(store)
+
+	var ids []int
+	for _, name := range []string{"rita", "sam"} {
+		user := &model.User{Username: name, Nickname: name, Email: name + "@example.com", Password: "hashed", Role: "user"}
+		if err := users.Create(ctx, user); err != nil {
+			t.Fatalf("create user %s: %v", name, err)
+		}
+		ids = append(ids, user.ID)
+	}
+
+	// 进度落后超过 maxAlertBacklog 的检索不会把检查窗口拉到更早
+	stale := time.Now().Add(-2 * maxAlertBacklog).Truncate(time.Second)
+	recent := time.Now().Add(-time.Hour).Truncate(time.Second)
+	// 与统一检索一样，标签按字面匹配，含空格的标签不会被当作表达式拆开
+	tagged := &model.SavedSearch{UserID: ids[1], Name: "多词标签", Query: model.SavedQuery{Tags: []string{"Visual Studio"}},
+		Channel: model.ChannelInApp, CheckedAt: recent}
+	email := &model.SavedSearch{UserID: ids[0], Name: "邮件", Query: model.SavedQuery{Keyword: "alertkit"},
+		Channel: model.ChannelEmail, CheckedAt: stale}
+	inApp := &model.SavedSearch{UserID: ids[1], Name: "站内", Query: model.SavedQuery{Keyword: "alertkit"},
+		Channel: model.ChannelInApp, CheckedAt: recent}
+	for _, search := range []*model.SavedSearch{tagged, email, inApp} {
+		if err := searches.Create(ctx, search); err != nil {
+			t.Fatalf("create saved search %s: %v", search.Name, err)
+		}
+	}
+
+	review, err := tools.Create(ctx, ids[1], model.ToolSubmitRequest{Name: "alertkit", Link: "https://example.com/alertkit",
+		Description: "alertkit", DescriptionDetail: "alertkit", Category: "IDE", Tags: []string{"Visual Studio"}})
+	if err != nil {
+		t.Fatalf("create tool: %v", err)
+	}
+	if err := store.SetStatus(ctx, model.ResourceTypeTool, review.ResourceID, model.StatusApproved); err != nil {
+		t.Fatalf("approve tool: %v", err)
+	}
+	// 检查进度取整到秒，等到下一秒新上架的工具才落在检查窗口内
+	time.Sleep(time.Until(time.Now().Truncate(time.Second).Add(time.Second)))
+
+	mailer := &failingMailer{}
+	matcher := NewAlertMatcher(searches, repository.NewMemorySearchRepository(store), notifications,
+		NewNotifier(notifications, users, mailer), 10, 24*time.Hour)
+	checkedAt := func(id int) time.Time {
+		t.Helper()
+		all, err := searches.List(ctx, 0)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		for _, search := range all {
+			if search.ID == id {
+				return search.CheckedAt
+			}
+		}
+		t.Fatalf("saved search %d not found", id)
+		return time.Time{}
+	}
+
+	notified, err := matcher.Match(ctx)
+	if err != nil || notified != 2 {
+		t.Fatalf("Match: got %d, %v; want 2 alerts", notified, err)
+	}
+	if count, err := notifications.Count(ctx, ids[1], model.NotificationSavedSearch, recent); err != nil || count != 2 {
+		t.Errorf("Count: got %d, %v; want alerts for both the keyword and the multi-word tag", count, err)
+	}
+	if !checkedAt(inApp.ID).After(recent) {
+		t.Errorf("notified saved search was not marked checked")
+	}
+	if !checkedAt(email.ID).Equal(stale) || mailer.sent != 1 {
+		t.Errorf("failed alert: checked at %v, sent %d; want progress kept and 1 attempt", checkedAt(email.ID), mailer.sent)
+	}
+
+	// 退避期间不重试
+	if notified, err := matcher.Match(ctx); err != nil || notified != 0 || mailer.sent != 1 {
+		t.Errorf("Match during backoff: got %d, %v, %d attempts; want no retry", notified, err, mailer.sent)
+	}
+	retry := matcher.retries[email.ID]
+	if retry.failures != 1 || !retry.retryAt.After(time.Now()) {
+		t.Errorf("retry after first failure: got %+v", retry)
+	}
+
+	// 到期后重试，连续失败 maxAlertRetries 次后放弃并推进进度
+	for attempt := 2; attempt <= maxAlertRetries; attempt++ {
+		retry := matcher.retries[email.ID]
+		retry.retryAt = time.Time{}
+		matcher.retries[email.ID] = retry
+		if _, err := matcher.Match(ctx); err != nil {
+			t.Fatalf("Match attempt %d: %v", attempt, err)
+		}
+		if mailer.sent != attempt {
+			t.Fatalf("attempt %d: mailer called %d times", attempt, mailer.sent)
+		}
+	}
+	if _, ok := matcher.retries[email.ID]; ok || !checkedAt(email.ID).After(stale) {
+		t.Errorf("after %d failures: retry %+v, checked at %v; want given up", maxAlertRetries, matcher.retries[email.ID], checkedAt(email.ID))
+	}
+	if count, err := notifications.Count(ctx, ids[0], model.NotificationSavedSearch, stale); err != nil || count != 0 {
+		t.Errorf("Count: got %d, %v; want no notification for failed alerts", count, err)
+	}
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+)
+
+// exportPageSize 导出审计日志时每次读取的条数
+const exportPageSize = 100
+
+type auditActorKey struct{}
+
+// WithAuditActor 把发起请求的管理员及请求信息放入 ctx，之后的操作以该管理员的名义记入审计日志
+func WithAuditActor(ctx context.Context, actor model.AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+func auditActorFrom(ctx context.Context) model.AuditActor {
+	actor, _ := ctx.Value(auditActorKey{}).(model.AuditActor)
+	return actor
+}
+
+// Auditor 记录管理员操作的审计日志。操作与日志在同一个事务中写入，日志写不进去时操作一并回滚，
+// 因此每个生效的操作都有记录
+type Auditor struct {
+	tx   repository.Transactor
+	logs repository.AuditLogRepository
+}
+
+func NewAuditor(tx repository.Transactor, logs repository.AuditLogRepository) *Auditor {
+	return &Auditor{tx: tx, logs: logs}
+}
+
+// auditChange 一次操作对目标的修改；before、after 为操作前后目标的快照，为 nil 表示不存在
+type auditChange struct {
+	action     string
+	targetType string
+	targetID   int
+	before     interface{}
+	after      interface{}
+}
+
+// audited 在事务中执行 fn，fn 成功时按它返回的修改追加一条审计日志；修改为 nil 表示没有需要记录的操作。
+// 检索索引、通知等无法回滚的副作用应在 audited 返回之后执行
+func audited[T any](ctx context.Context, a *Auditor, fn func(ctx context.Context) (T, *auditChange, error)) (T, error) {
+	var result T
+	err := a.tx.WithTx(ctx, func(ctx context.Context) error {
+		var change *auditChange
+		var err error
+		result, change, err = fn(ctx)
+		if err != nil || change == nil {
+			return err
+		}
+		return a.append(ctx, change)
+	})
+	return result, err
+}
+
+func (a *Auditor) append(ctx context.Context, change *auditChange) error {
+	before, err := snapshot(change.before)
+	if err != nil {
+		return err
+	}
+	after, err := snapshot(change.after)
+	if err != nil {
+		return err
+	}
+
+	actor := auditActorFrom(ctx)
+	return a.logs.Append(ctx, &model.AuditLog{
+		ActorID:    actor.UserID,
+		Actor:      actor.Name,
+		Action:     change.action,
+		TargetType: change.targetType,
+		TargetID:   change.targetID,
+		Before:     before,
+		After:      after,
+		IP:         actor.IP,
+		RequestID:  actor.RequestID,
+	})
+}
+
+// snapshot 把快照编码为 JSON，nil（含类型化的 nil 指针）返回空
+func snapshot(v interface{}) (json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode audit snapshot: %w", err)
+	}
+	if string(data) == "null" {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// AuditService 查询和导出审计日志
+type AuditService interface {
+	List(ctx context.Context, filter model.AuditFilter, page pagination.Request) (*model.ListResponse[model.AuditLog], error)
+	// Export 按时间倒序依次把满足条件的全部日志交给 fn，fn 返回错误时停止
+	Export(ctx context.Context, filter model.AuditFilter, fn func(entry *model.AuditLog) error) error
+}
+
+type auditService struct {
+	logs    repository.AuditLogRepository
+	cursors *pagination.Codec
+}
+
+func NewAuditService(logs repository.AuditLogRepository, cursors *pagination.Codec) AuditService {
+	return &auditService{logs: logs, cursors: cursors}
+}
+
+func (s *auditService) List(ctx context.Context, filter model.AuditFilter, page pagination.Request) (*model.ListResponse[model.AuditLog], error) {
+	// 游标只记录位置，换了检索条件的游标仍然有效，不会越过新的条件
+	const scope = "audit"
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.logs.List(ctx, filter, p)
+	if err != nil {
+		return nil, err
+	}
+	return pageResponse(s.cursors, scope, result), nil
+}
+
+func (s *auditService) Export(ctx context.Context, filter model.AuditFilter, fn func(entry *model.AuditLog) error) error {
+	page := pagination.Page{Limit: exportPageSize}
+	for {
+		result, err := s.logs.List(ctx, filter, page)
+		if err != nil {
+			return err
+		}
+		for i := range result.Items {
+			if err := fn(&result.Items[i]); err != nil {
+				return err
+			}
+		}
+		if !result.HasMore {
+			return nil
+		}
+		page.After = result.Next
+	}
+}
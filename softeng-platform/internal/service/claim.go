@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"time"
+)
+
+// ClaimConflictError 审核目标已被其他管理员认领，Claim 为当前的认领情况
+type ClaimConflictError struct {
+	Claim *model.ReviewClaim
+}
+
+func (e *ClaimConflictError) Error() string {
+	return fmt.Sprintf("%s %d is claimed by %s until %s", e.Claim.ResourceType, e.Claim.ResourceID, e.Claim.Claimant, e.Claim.ExpiresAt)
+}
+
+// claimConflict 审核目标被其他管理员认领且未过期时返回 *ClaimConflictError
+func claimConflict(claim *model.ReviewClaim, reviewerID int) error {
+	if claim != nil && claim.ClaimantID != 0 && claim.ClaimantID != reviewerID {
+		return &ClaimConflictError{Claim: claim}
+	}
+	return nil
+}
+
+// lockClaim 在事务中锁住审核目标的认领记录再检查，锁持有到事务结束，
+// 检查之后其他管理员的认领要等审核完成才能生效，不会出现两人同时审核
+func (s *adminService) lockClaim(ctx context.Context, reviewerID int, target model.ReviewTarget) error {
+	claim, err := s.claims.Lock(ctx, target)
+	if err != nil {
+		return err
+	}
+	return claimConflict(claim, reviewerID)
+}
+
+func (s *adminService) ClaimItem(ctx context.Context, reviewerID int, resourceType string, itemID int) (*model.ReviewClaim, error) {
+	return audited(ctx, s.auditor, func(ctx context.Context) (*model.ReviewClaim, *auditChange, error) {
+		if err := checkReviewType(resourceType); err != nil {
+			return nil, nil, err
+		}
+		state, err := s.reviews.Get(ctx, 0, resourceType, itemID)
+		if err != nil {
+			return nil, nil, err
+		}
+		if state == nil {
+			return nil, nil, ErrResourceNotFound
+		}
+		if !model.InReviewQueue(state.AuditStatus) {
+			return nil, nil, fmt.Errorf("%w: cannot claim %s %d in status %s", ErrInvalidTransition, resourceType, itemID, state.AuditStatus)
+		}
+
+		target := model.ReviewTarget{ResourceType: resourceType, ResourceID: itemID}
+		before, err := s.claims.Get(ctx, target)
+		if err != nil {
+			return nil, nil, err
+		}
+		claim, err := s.claims.Claim(ctx, reviewerID, target, time.Now().Add(s.claimLease))
+		if errors.Is(err, repository.ErrClaimConflict) {
+			return nil, nil, &ClaimConflictError{Claim: claim}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		return claim, &auditChange{action: model.AuditReviewClaim, targetType: resourceType, targetID: itemID,
+			before: before, after: claim}, nil
+	})
+}
+
+func (s *adminService) ReleaseItem(ctx context.Context, reviewerID int, resourceType string, itemID int) error {
+	_, err := audited(ctx, s.auditor, func(ctx context.Context) (struct{}, *auditChange, error) {
+		if err := checkReviewType(resourceType); err != nil {
+			return struct{}{}, nil, err
+		}
+		target := model.ReviewTarget{ResourceType: resourceType, ResourceID: itemID}
+		before, err := s.claims.Get(ctx, target)
+		if err != nil {
+			return struct{}{}, nil, err
+		}
+		if err := claimConflict(before, reviewerID); err != nil {
+			return struct{}{}, nil, err
+		}
+		// 没有认领可放弃时不记日志
+		released, err := s.claims.Release(ctx, reviewerID, target)
+		if err != nil || !released {
+			return struct{}{}, nil, err
+		}
+		after, err := s.claims.Get(ctx, target)
+		if err != nil {
+			return struct{}{}, nil, err
+		}
+		return struct{}{}, &auditChange{action: model.AuditReviewRelease, targetType: resourceType, targetID: itemID,
+			before: before, after: after}, nil
+	})
+	return err
+}
+
+func (s *adminService) AssignPending(ctx context.Context) (int, error) {
+	return audited(ctx, s.auditor, func(ctx context.Context) (int, *auditChange, error) {
+		assigned, err := s.claims.Distribute(ctx)
+		if err != nil || assigned == 0 {
+			return assigned, nil, err
+		}
+		return assigned, &auditChange{action: model.AuditReviewAssign, targetType: model.AuditTargetReviewQueue,
+			after: map[string]int{"assigned": assigned}}, nil
+	})
+}
+
+// fillClaims 为待审核列表中的资源附上分配和认领情况
+func (s *adminService) fillClaims(ctx context.Context, items []model.Submit) error {
+	targets := make([]model.ReviewTarget, 0, len(items))
+	for _, item := range items {
+		targets = append(targets, model.ReviewTarget{ResourceType: item.ResourceType, ResourceID: item.ResourceID})
+	}
+	claims, err := s.claims.List(ctx, targets)
+	if err != nil {
+		return err
+	}
+	for i, target := range targets {
+		if claim, ok := claims[target]; ok && (claim.AssigneeID != 0 || claim.ClaimantID != 0) {
+			items[i].Claim = &claim
+		}
+	}
+	return nil
+}
+
+// RunReviewAssignment 每隔 interval 把新提交的待审核资源轮流分配给各管理员，直到 ctx 取消
+func RunReviewAssignment(ctx context.Context, admin AdminService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			assigned, err := admin.AssignPending(ctx)
+			if err != nil {
+				log.Printf("Failed to assign pending reviews: %v", err)
+			}
+			if assigned > 0 {
+				log.Printf("Assigned %d pending reviews", assigned)
+			}
+		}
+	}
+}
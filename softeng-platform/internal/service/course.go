@@ -2,217 +2,250 @@ package service
 
 import (
 	"context"
+	"errors"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
 	"softeng-platform/internal/repository"
 )
 
 type CourseService interface {
-	GetCourses(ctx context.Context, semester string, category []string, sort string, limit, cursor int, resourceType string) (map[string]interface{}, error)
-	GetCourse(ctx context.Context, courseID, resourceType string) (map[string]interface{}, error)
-	SearchCourses(ctx context.Context, keyword string, category []string, limit, cursor int, resourceType string) (map[string]interface{}, error)
-	UploadResource(ctx context.Context, userID int, courseID, resourceType string, req CourseUploadRequest) (map[string]interface{}, error)
-	DownloadTextbook(ctx context.Context, courseID, textbookID string) (map[string]interface{}, error)
-	AddComment(ctx context.Context, userID int, courseID, content string) (map[string]interface{}, error)
-	DeleteComment(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	ReplyComment(ctx context.Context, userID int, courseID, commentID, content string) (map[string]interface{}, error)
-	DeleteReply(ctx context.Context, userID int, courseID, commentID string) (map[string]interface{}, error)
-	AddView(ctx context.Context, courseID string) (map[string]interface{}, error)
-	CollectCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	UncollectCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	LikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-	UnlikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error)
-}
-
-// CourseUploadRequest 课程资源上传请求
-type CourseUploadRequest struct {
-	File        string   `form:"file" json:"file"`
-	Resource    string   `form:"resource" json:"resource"`
-	Description string   `form:"description" json:"description" binding:"required"`
-	Tags        []string `form:"tags" json:"tags"`
+	GetCourses(ctx context.Context, semester string, category []string, sort string, page pagination.Request, resourceType string) (*model.CourseList, error)
+	GetCourse(ctx context.Context, courseID int, resourceType string) (*model.CourseDetailResponse, error)
+	SearchCourses(ctx context.Context, keyword string, category []string, sort string, page pagination.Request, resourceType string) (*model.CourseList, error)
+	UploadResource(ctx context.Context, userID, courseID int, resourceType string, req model.CourseUploadRequest) (*model.DataResponse[*model.TeachReview], error)
+	DownloadTextbook(ctx context.Context, courseID, textbookID int) (*model.Textbook, error)
+	AddComment(ctx context.Context, userID, courseID int, content string) (*model.DataResponse[*model.Comment], error)
+	DeleteComment(ctx context.Context, userID, courseID, commentID int) (*model.DataResponse[*model.Comment], error)
+	ReplyComment(ctx context.Context, userID, courseID, commentID int, content string) (*model.DataResponse[*model.Comment], error)
+	DeleteReply(ctx context.Context, userID, courseID, commentID int) (*model.DataResponse[*model.Comment], error)
+	AddView(ctx context.Context, courseID int, visitor string) (*model.DataResponse[model.ViewCount], error)
+	GetDailyViews(ctx context.Context, courseID, days int) (*model.DataResponse[[]model.DailyViews], error)
+	CollectCourse(ctx context.Context, userID, courseID int) (*model.DataResponse[*model.CollectStatus], error)
+	UncollectCourse(ctx context.Context, userID, courseID int) (*model.DataResponse[*model.CollectStatus], error)
+	LikeCourse(ctx context.Context, userID, courseID int) (*model.DataResponse[*model.LikeStatus], error)
+	UnlikeCourse(ctx context.Context, userID, courseID int) (*model.DataResponse[*model.LikeStatus], error)
+	GetComments(ctx context.Context, courseID int, page pagination.Request) (*model.ListResponse[model.Comment], error)
 }
 
 type courseService struct {
 	courseRepo repository.CourseRepository
+	index      repository.SearchIndex
+	views      *ViewCounter
+	suggester  *Suggester
+	filter     *ContentFilter
+	cursors    *pagination.Codec
 }
 
-func NewCourseService(courseRepo repository.CourseRepository) CourseService {
-	return &courseService{courseRepo: courseRepo}
+func NewCourseService(courseRepo repository.CourseRepository, index repository.SearchIndex, views *ViewCounter, suggester *Suggester, filter *ContentFilter, cursors *pagination.Codec) CourseService {
+	return &courseService{courseRepo: courseRepo, index: index, views: views, suggester: suggester, filter: filter, cursors: cursors}
 }
 
-func (s *courseService) GetCourses(ctx context.Context, semester string, category []string, sort string, limit, cursor int, resourceType string) (map[string]interface{}, error) {
-	courses, err := s.courseRepo.GetCourses(ctx, semester, category, sort, limit, cursor)
+func (s *courseService) GetCourses(ctx context.Context, semester string, category []string, sort string, page pagination.Request, resourceType string) (*model.CourseList, error) {
+	const scope = "courses"
+	p, err := s.cursors.Page(scope, page)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message":     "success",
-		"courses_agg": courses,
-	}, nil
+	courses, err := s.courseRepo.GetCourses(ctx, semester, category, sort, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.courseList(scope, courses), nil
 }
 
-func (s *courseService) GetCourse(ctx context.Context, courseID, resourceType string) (map[string]interface{}, error) {
+func (s *courseService) GetCourse(ctx context.Context, courseID int, resourceType string) (*model.CourseDetailResponse, error) {
 	course, err := s.courseRepo.GetByID(ctx, courseID)
 	if err != nil {
 		return nil, err
 	}
+	if course == nil {
+		return nil, errors.New("course not found")
+	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"courses": []map[string]interface{}{course},
+	return &model.CourseDetailResponse{
+		Message: "success",
+		Courses: []model.CourseDetail{*course},
 	}, nil
 }
 
-func (s *courseService) SearchCourses(ctx context.Context, keyword string, category []string, limit, cursor int, resourceType string) (map[string]interface{}, error) {
-	courses, err := s.courseRepo.Search(ctx, keyword, category, limit, cursor)
+func (s *courseService) SearchCourses(ctx context.Context, keyword string, category []string, sort string, page pagination.Request, resourceType string) (*model.CourseList, error) {
+	const scope = "courses.search"
+	p, err := s.cursors.Page(scope, page)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message":     "success",
-		"courses_agg": courses,
-	}, nil
+	courses, didYouMean, err := searchWithAssist(s.suggester, keyword, p,
+		func(keyword string, page pagination.Page) (*pagination.Result[model.Course], error) {
+			return s.courseRepo.Search(ctx, keyword, category, sort, page)
+		},
+		func(course *model.Course, terms [][]string) {
+			course.Highlights = highlights(terms, course.Name, "")
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	list := s.courseList(scope, courses)
+	list.DidYouMean = didYouMean
+	return list, nil
 }
 
-func (s *courseService) UploadResource(ctx context.Context, userID int, courseID, resourceType string, req CourseUploadRequest) (map[string]interface{}, error) {
-	// 将结构体转换为 map 传递给 repository
-	resourceData := map[string]interface{}{
-		"file":        req.File,
-		"resource":    req.Resource,
-		"description": req.Description,
-		"tags":        req.Tags,
+func (s *courseService) courseList(scope string, courses *pagination.Result[model.Course]) *model.CourseList {
+	items := courses.Items
+	if items == nil {
+		items = []model.Course{}
 	}
 
-	resource, err := s.courseRepo.UploadResource(ctx, userID, courseID, resourceData)
+	return &model.CourseList{
+		Message:    "success",
+		CoursesAgg: items,
+		PageInfo:   pageInfo(s.cursors, scope, courses.Info),
+	}
+}
+
+func (s *courseService) UploadResource(ctx context.Context, userID, courseID int, resourceType string, req model.CourseUploadRequest) (*model.DataResponse[*model.TeachReview], error) {
+	fields := append([]ContentField{{Name: "description", Text: &req.Description}}, listFields("tags", req.Tags)...)
+	if err := s.filter.Clean(fields...); err != nil {
+		return nil, err
+	}
+	resource, err := s.courseRepo.UploadResource(ctx, userID, courseID, req)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "Resource uploaded successfully",
-		"data":    resource,
-	}, nil
+	return model.NewDataResponse("Resource uploaded successfully", resource), nil
 }
 
-// 其他方法保持不变...
-func (s *courseService) DownloadTextbook(ctx context.Context, courseID, textbookID string) (map[string]interface{}, error) {
+func (s *courseService) DownloadTextbook(ctx context.Context, courseID, textbookID int) (*model.Textbook, error) {
 	content, err := s.courseRepo.DownloadTextbook(ctx, courseID, textbookID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"content": content,
+	return &model.Textbook{
+		Message: "success",
+		Content: content,
 	}, nil
 }
 
-func (s *courseService) AddComment(ctx context.Context, userID int, courseID, content string) (map[string]interface{}, error) {
+func (s *courseService) AddComment(ctx context.Context, userID, courseID int, content string) (*model.DataResponse[*model.Comment], error) {
+	content, flagged, err := s.filter.CleanComment(content)
+	if err != nil {
+		return nil, err
+	}
 	comment, err := s.courseRepo.AddComment(ctx, userID, courseID, content)
 	if err != nil {
 		return nil, err
 	}
+	s.filter.FlagComment(ctx, comment, flagged)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    comment,
-	}, nil
+	return model.NewDataResponse("success", comment), nil
 }
 
-func (s *courseService) DeleteComment(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
-	comment, err := s.courseRepo.DeleteComment(ctx, userID, courseID)
+func (s *courseService) DeleteComment(ctx context.Context, userID, courseID, commentID int) (*model.DataResponse[*model.Comment], error) {
+	comment, err := s.courseRepo.DeleteComment(ctx, userID, courseID, commentID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    comment,
-	}, nil
+	return model.NewDataResponse("success", comment), nil
 }
 
-func (s *courseService) ReplyComment(ctx context.Context, userID int, courseID, commentID, content string) (map[string]interface{}, error) {
+func (s *courseService) ReplyComment(ctx context.Context, userID, courseID, commentID int, content string) (*model.DataResponse[*model.Comment], error) {
+	content, flagged, err := s.filter.CleanComment(content)
+	if err != nil {
+		return nil, err
+	}
 	reply, err := s.courseRepo.ReplyComment(ctx, userID, courseID, commentID, content)
 	if err != nil {
 		return nil, err
 	}
+	s.filter.FlagComment(ctx, reply, flagged)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    reply,
-	}, nil
+	return model.NewDataResponse("success", reply), nil
 }
 
-func (s *courseService) DeleteReply(ctx context.Context, userID int, courseID, commentID string) (map[string]interface{}, error) {
+func (s *courseService) DeleteReply(ctx context.Context, userID, courseID, commentID int) (*model.DataResponse[*model.Comment], error) {
 	reply, err := s.courseRepo.DeleteReply(ctx, userID, courseID, commentID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    reply,
-	}, nil
+	return model.NewDataResponse("success", reply), nil
 }
 
-func (s *courseService) AddView(ctx context.Context, courseID string) (map[string]interface{}, error) {
-	views, err := s.courseRepo.AddView(ctx, courseID)
+func (s *courseService) AddView(ctx context.Context, courseID int, visitor string) (*model.DataResponse[model.ViewCount], error) {
+	views, err := s.views.Record(ctx, model.ResourceTypeCourse, courseID, visitor)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data": map[string]interface{}{
-			"views": views,
-		},
-	}, nil
+	return model.NewDataResponse("success", model.ViewCount{Views: views}), nil
 }
 
-func (s *courseService) CollectCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
+func (s *courseService) GetDailyViews(ctx context.Context, courseID, days int) (*model.DataResponse[[]model.DailyViews], error) {
+	daily, err := s.views.Daily(ctx, model.ResourceTypeCourse, courseID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDataResponse("success", daily), nil
+}
+
+func (s *courseService) CollectCourse(ctx context.Context, userID, courseID int) (*model.DataResponse[*model.CollectStatus], error) {
 	result, err := s.courseRepo.CollectCourse(ctx, userID, courseID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeCourse, courseID)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    result,
-	}, nil
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *courseService) UncollectCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
+func (s *courseService) UncollectCourse(ctx context.Context, userID, courseID int) (*model.DataResponse[*model.CollectStatus], error) {
 	result, err := s.courseRepo.UncollectCourse(ctx, userID, courseID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeCourse, courseID)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    result,
-	}, nil
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *courseService) LikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
+func (s *courseService) LikeCourse(ctx context.Context, userID, courseID int) (*model.DataResponse[*model.LikeStatus], error) {
 	result, err := s.courseRepo.LikeCourse(ctx, userID, courseID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeCourse, courseID)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    result,
-	}, nil
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *courseService) UnlikeCourse(ctx context.Context, userID int, courseID string) (map[string]interface{}, error) {
+func (s *courseService) UnlikeCourse(ctx context.Context, userID, courseID int) (*model.DataResponse[*model.LikeStatus], error) {
 	result, err := s.courseRepo.UnlikeCourse(ctx, userID, courseID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeCourse, courseID)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    result,
-	}, nil
+	return model.NewDataResponse("success", result), nil
+}
+
+func (s *courseService) GetComments(ctx context.Context, courseID int, page pagination.Request) (*model.ListResponse[model.Comment], error) {
+	scope := commentScope(model.ResourceTypeCourse, courseID)
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.courseRepo.GetComments(ctx, courseID, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return pageResponse(s.cursors, scope, comments), nil
 }
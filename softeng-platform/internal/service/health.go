@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+)
+
+// HealthService 健康检查与数据库连接池统计
+type HealthService interface {
+	// Healthy 数据库可以连通时返回 true
+	Healthy(ctx context.Context) bool
+	DBStats(ctx context.Context) (*model.DataResponse[*model.DBStats], error)
+}
+
+type healthService struct {
+	db repository.HealthChecker
+}
+
+func NewHealthService(db repository.HealthChecker) HealthService {
+	return &healthService{db: db}
+}
+
+func (s *healthService) Healthy(ctx context.Context) bool {
+	return s.db.DBStats(ctx).Status == "ok"
+}
+
+func (s *healthService) DBStats(ctx context.Context) (*model.DataResponse[*model.DBStats], error) {
+	return model.NewDataResponse("success", s.db.DBStats(ctx)), nil
+}
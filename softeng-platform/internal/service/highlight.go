@@ -0,0 +1,86 @@
+package service
+
+import (
+	"softeng-platform/internal/model"
+	"unicode"
+)
+
+const (
+	// snippetLength 简介高亮片段最多保留的字数，snippetLead 为片段中第一处命中之前保留的字数
+	snippetLength = 80
+	snippetLead   = 20
+)
+
+// highlights 返回名称和简介的高亮片段，terms 为 repository.SearchTerms 拆分出的检索词；
+// 两个字段都没有命中时返回 nil
+func highlights(terms [][]string, name, description string) *model.Highlights {
+	h := &model.Highlights{
+		Name:        highlightSpans(terms, name, false),
+		Description: highlightSpans(terms, description, true),
+	}
+	if h.Name == nil && h.Description == nil {
+		return nil
+	}
+	return h
+}
+
+// highlightSpans 把 text 按是否命中检索词（的任一候选写法，不区分大小写）切分为若干段；
+// snippet 为 true 且文本过长时只保留第一处命中附近的 snippetLength 个字。没有命中时返回 nil
+func highlightSpans(terms [][]string, text string, snippet bool) []model.HighlightSpan {
+	runes := []rune(text)
+	lower := make([]rune, len(runes))
+	for i, c := range runes {
+		lower[i] = unicode.ToLower(c)
+	}
+
+	marks := make([]bool, len(runes))
+	first := -1
+	for _, term := range terms {
+		for _, alt := range term {
+			pattern := []rune(alt)
+			for i := 0; len(pattern) > 0 && i+len(pattern) <= len(lower); i++ {
+				if string(lower[i:i+len(pattern)]) != alt {
+					continue
+				}
+				for k := i; k < i+len(pattern); k++ {
+					marks[k] = true
+				}
+				if first < 0 || i < first {
+					first = i
+				}
+			}
+		}
+	}
+	if first < 0 {
+		return nil
+	}
+
+	start, end := 0, len(runes)
+	if snippet && len(runes) > snippetLength {
+		start = first - snippetLead
+		if start < 0 {
+			start = 0
+		}
+		end = start + snippetLength
+		if end > len(runes) {
+			end, start = len(runes), len(runes)-snippetLength
+		}
+	}
+
+	var spans []model.HighlightSpan
+	if start > 0 {
+		spans = append(spans, model.HighlightSpan{Text: "…"})
+	}
+	for i := start; i < end; {
+		j := i
+		for j < end && marks[j] == marks[i] {
+			j++
+		}
+		spans = append(spans, model.HighlightSpan{Text: string(runes[i:j]), Match: marks[i]})
+		i = j
+	}
+	if end < len(runes) {
+		spans = append(spans, model.HighlightSpan{Text: "…"})
+	}
+	return spans
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxExcerptRunes 通知中引用评论内容的最大字数
+const maxExcerptRunes = 30
+
+var (
+	// ErrCommentNotFound 评论不存在、已删除，或举报时已被隐藏
+	ErrCommentNotFound = errors.New("comment not found")
+	// ErrInvalidReport 举报理由无效或举报自己的评论
+	ErrInvalidReport = errors.New("invalid report")
+)
+
+// reportReasonNames 通知中举报理由的名称
+var reportReasonNames = map[string]string{
+	model.ReportSpam:    "广告刷屏",
+	model.ReportAbuse:   "辱骂攻击",
+	model.ReportIllegal: "违法违规",
+	model.ReportOther:   "其他",
+
+	model.ReportSensitive: "包含敏感词",
+}
+
+// CommentReportService 用户举报评论。待处理的举报数达到阈值的评论自动隐藏，等待管理员在审核队列中处理
+type CommentReportService interface {
+	// Report 举报评论，重复举报不重复计数；评论不存在或已隐藏时返回 ErrCommentNotFound
+	Report(ctx context.Context, userID, commentID int, req model.CommentReportRequest) error
+}
+
+type commentReportService struct {
+	reports   repository.CommentReportRepository
+	notifier  *Notifier
+	threshold int
+}
+
+// NewCommentReportService threshold 为自动隐藏评论的举报数
+func NewCommentReportService(reports repository.CommentReportRepository, notifier *Notifier, threshold int) CommentReportService {
+	return &commentReportService{reports: reports, notifier: notifier, threshold: threshold}
+}
+
+func (s *commentReportService) Report(ctx context.Context, userID, commentID int, req model.CommentReportRequest) error {
+	if !model.IsReportReason(req.Reason) {
+		return fmt.Errorf("%w: unknown reason %q", ErrInvalidReport, req.Reason)
+	}
+	comment, err := s.reports.Get(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment == nil || comment.Hidden {
+		return ErrCommentNotFound
+	}
+	if comment.AuthorID == userID {
+		return fmt.Errorf("%w: cannot report own comment", ErrInvalidReport)
+	}
+
+	comment, added, err := s.reports.Report(ctx, commentID, userID, req.Reason, strings.TrimSpace(req.Detail))
+	if err != nil {
+		return err
+	}
+	if comment == nil {
+		return ErrCommentNotFound
+	}
+	if added && comment.Reports >= s.threshold {
+		hidden, err := s.reports.Hide(ctx, commentID)
+		if err != nil {
+			return err
+		}
+		// 并发的举报只有一个真正隐藏了评论，由它通知作者
+		if hidden {
+			notifyModeration(ctx, s.notifier, comment, "", "")
+		}
+	}
+	return nil
+}
+
+// ModerateComment 处理审核队列中的评论并通知作者，结果见 model.ModerationResult
+func (s *adminService) ModerateComment(ctx context.Context, moderatorID, commentID int, action, note string) (*model.ModerationResult, error) {
+	switch action {
+	case model.ModerationDismiss, model.ModerationHide, model.ModerationDelete, model.ModerationWarn:
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAction, action)
+	}
+
+	var comment *model.ReportedComment
+	result, err := audited(ctx, s.auditor, func(ctx context.Context) (*model.ModerationResult, *auditChange, error) {
+		var err error
+		comment, err = s.reports.Resolve(ctx, commentID, moderatorID, action)
+		if err != nil {
+			return nil, nil, err
+		}
+		if comment == nil {
+			return nil, nil, ErrCommentNotFound
+		}
+		result := &model.ModerationResult{
+			CommentID: commentID,
+			Action:    action,
+			Resolved:  comment.Reports,
+			Hidden:    action == model.ModerationHide || action == model.ModerationWarn,
+			Deleted:   action == model.ModerationDelete,
+		}
+		return result, &auditChange{action: model.AuditCommentPrefix + action, targetType: model.AuditTargetComment,
+			targetID: commentID, before: comment, after: result}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	notifyModeration(ctx, s.notifier, comment, action, strings.TrimSpace(note))
+	return result, nil
+}
+
+// pendingComment 评论审核队列中的一项，tags 为举报理由
+func pendingComment(comment model.ReportedComment) model.Submit {
+	reasons := make([]string, 0, len(comment.Reasons))
+	for reason := range comment.Reasons {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+
+	return model.Submit{
+		Submitor:     comment.Author,
+		SubmitDate:   comment.ReportedAt,
+		ResourceID:   comment.CommentID,
+		ResourceType: "comment",
+		Category:     comment.ResourceType,
+		Description:  comment.Content,
+		Tags:         reasons,
+		Report:       &comment,
+	}
+}
+
+// notifyModeration 通知作者评论的处理结果，action 为空表示因举报过多被自动隐藏。
+// 处理已经生效，发送失败只记录日志
+func notifyModeration(ctx context.Context, notifier *Notifier, comment *model.ReportedComment, action, note string) {
+	where := fmt.Sprintf("你在%s下的评论“%s”", resourceTypeNames[comment.ResourceType], excerpt(comment.Content))
+	var title, content string
+	switch action {
+	case "":
+		title, content = "你的评论已被暂时隐藏", where+"被多位用户举报，已暂时隐藏，等待管理员审核。"
+	case model.ModerationDismiss:
+		title, content = "你的评论经审核未违规", where+"被举报，经审核未发现违规，已正常显示。"
+	case model.ModerationHide:
+		title, content = "你的评论已被隐藏", where+"经审核已被隐藏。"
+	case model.ModerationDelete:
+		title, content = "你的评论已被删除", where+"经审核已被删除。"
+	case model.ModerationWarn:
+		title, content = "评论违规警告", where+"经审核违反社区规范，已被隐藏。请遵守社区规范，多次违规可能导致账号受限。"
+	}
+
+	var reasons []string
+	for reason := range comment.Reasons {
+		if name, ok := reportReasonNames[reason]; ok {
+			reasons = append(reasons, name)
+		}
+	}
+	sort.Strings(reasons)
+	if len(reasons) > 0 && action != model.ModerationDismiss {
+		content += "\n举报理由：" + strings.Join(reasons, "、")
+	}
+	if note != "" {
+		content += "\n管理员备注：" + note
+	}
+
+	err := notifier.Notify(ctx, &model.Notification{
+		UserID:       comment.AuthorID,
+		Kind:         model.NotificationModeration,
+		Title:        title,
+		Content:      content,
+		ResourceType: comment.ResourceType,
+		ResourceID:   comment.ResourceID,
+		RefID:        comment.CommentID,
+	})
+	if err != nil {
+		log.Printf("Failed to notify author of comment %d: %v", comment.CommentID, err)
+	}
+}
+
+// excerpt 截取评论开头的 maxExcerptRunes 个字
+func excerpt(content string) string {
+	if utf8.RuneCountInString(content) <= maxExcerptRunes {
+		return content
+	}
+	return string([]rune(content)[:maxExcerptRunes]) + "…"
+}
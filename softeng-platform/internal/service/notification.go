@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/mail"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+)
+
+// ErrNotificationNotFound 通知不存在、已读或不属于当前用户
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationService 用户的站内通知
+type NotificationService interface {
+	// List 按时间倒序列出通知，unreadOnly 为 true 时只返回未读的
+	List(ctx context.Context, userID int, unreadOnly bool, page pagination.Request) (*model.ListResponse[model.Notification], error)
+	// MarkRead 把一条通知标为已读，id 为 0 时标记全部，返回标记的条数
+	MarkRead(ctx context.Context, userID, id int) (int, error)
+}
+
+type notificationService struct {
+	notifications repository.NotificationRepository
+	cursors       *pagination.Codec
+}
+
+func NewNotificationService(notifications repository.NotificationRepository, cursors *pagination.Codec) NotificationService {
+	return &notificationService{notifications: notifications, cursors: cursors}
+}
+
+func (s *notificationService) List(ctx context.Context, userID int, unreadOnly bool, page pagination.Request) (*model.ListResponse[model.Notification], error) {
+	scope := fmt.Sprintf("notifications:%d", userID)
+	if unreadOnly {
+		scope += ":unread"
+	}
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.notifications.List(ctx, userID, unreadOnly, p)
+	if err != nil {
+		return nil, err
+	}
+	return pageResponse(s.cursors, scope, result), nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, id int) (int, error) {
+	marked, err := s.notifications.MarkRead(ctx, userID, id)
+	if err != nil {
+		return 0, err
+	}
+	if id != 0 && marked == 0 {
+		return 0, ErrNotificationNotFound
+	}
+	return marked, nil
+}
+
+// Notifier 向用户发送通知：总是写入站内通知，channel 为 email 时先发送邮件
+type Notifier struct {
+	notifications repository.NotificationRepository
+	users         repository.UserRepository
+	mailer        mail.Mailer
+}
+
+func NewNotifier(notifications repository.NotificationRepository, users repository.UserRepository, mailer mail.Mailer) *Notifier {
+	return &Notifier{notifications: notifications, users: users, mailer: mailer}
+}
+
+// Notify 发送通知并写回 ID。邮件发送失败时不写入站内通知，调用方可以稍后重试
+func (n *Notifier) Notify(ctx context.Context, notification *model.Notification) error {
+	if notification.Channel == "" {
+		notification.Channel = model.ChannelInApp
+	}
+	if notification.Channel == model.ChannelEmail {
+		user, err := n.users.GetByID(ctx, notification.UserID)
+		if err != nil {
+			return err
+		}
+		if user == nil {
+			return fmt.Errorf("user %d not found", notification.UserID)
+		}
+		if err := n.mailer.Send(ctx, user.Email, notification.Title, notification.Content); err != nil {
+			return err
+		}
+	}
+	return n.notifications.Create(ctx, notification)
+}
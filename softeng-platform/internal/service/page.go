@@ -0,0 +1,26 @@
+package service
+
+import (
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+)
+
+// commentScope 评论列表的游标范围，每个资源的评论各自独立
+func commentScope(resourceType string, resourceID int) string {
+	return fmt.Sprintf("comments:%s:%d", resourceType, resourceID)
+}
+
+// pageInfo 将仓库返回的分页信息编码为接口响应
+func pageInfo(cursors *pagination.Codec, scope string, info pagination.Info) model.PageInfo {
+	return model.PageInfo{
+		NextCursor: cursors.Encode(scope, info.Next),
+		HasMore:    info.HasMore,
+		Total:      info.Total,
+	}
+}
+
+// pageResponse 将一页数据转换为分页列表响应
+func pageResponse[T any](cursors *pagination.Codec, scope string, result *pagination.Result[T]) *model.ListResponse[T] {
+	return model.NewPageResponse("success", result.Items, pageInfo(cursors, scope, result.Info))
+}
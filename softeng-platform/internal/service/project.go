@@ -2,233 +2,265 @@ package service
 
 import (
 	"context"
+	"errors"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
 	"softeng-platform/internal/repository"
+	"softeng-platform/internal/tagquery"
 )
 
 type ProjectService interface {
-	GetProjects(ctx context.Context, category string, techStack []string, sort string, limit int, cursor, resourceType string) (map[string]interface{}, error)
-	GetProject(ctx context.Context, projectID string) (map[string]interface{}, error)
-	SearchProjects(ctx context.Context, keyword string, category []string, cursor string, limit int) (map[string]interface{}, error)
-	UploadProject(ctx context.Context, userID int, req ProjectUploadRequest) (map[string]interface{}, error)
-	UpdateProject(ctx context.Context, userID int, projectID string, req ProjectUploadRequest) (map[string]interface{}, error)
-	LikeProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	UnlikeProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	AddComment(ctx context.Context, userID int, projectID, content string) (map[string]interface{}, error)
-	DeleteComment(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	ReplyComment(ctx context.Context, userID int, projectID, commentID, content string) (map[string]interface{}, error)
-	DeleteReply(ctx context.Context, userID int, projectID, commentID string) (map[string]interface{}, error)
-	AddView(ctx context.Context, projectID string) (map[string]interface{}, error)
-	CollectProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-	UncollectProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error)
-}
-
-// ProjectUploadRequest 项目上传请求
-type ProjectUploadRequest struct {
-	Name        string   `form:"name" json:"name" binding:"required"`
-	Description string   `form:"description" json:"description" binding:"required"`
-	Detail      string   `form:"detail" json:"detail" binding:"required"`
-	Github      string   `form:"github" json:"github"`
-	TechStack   []string `form:"techStack" json:"techStack" binding:"required"`
-	Category    string   `form:"catagory" json:"catagory" binding:"required"`
-	Images      []string `form:"images" json:"images"`
+	// GetProjects techStack 为技术栈查询表达式（见 tagquery），多个表达式之间为“或”，
+	// 表达式有语法错误时返回 *tagquery.SyntaxError
+	GetProjects(ctx context.Context, category string, techStack []string, sort string, page pagination.Request, resourceType string) (*model.ListResponse[model.Project], error)
+	GetProject(ctx context.Context, projectID int) (*model.DataResponse[*model.ProjectDetail], error)
+	SearchProjects(ctx context.Context, keyword string, category []string, sort string, page pagination.Request) (*model.ListResponse[model.Project], error)
+	UploadProject(ctx context.Context, userID int, req model.ProjectUploadRequest) (*model.DataResponse[*model.ResourceReview], error)
+	// UpdateProject version 为客户端读到的版本号，为 0 时不检查；版本过期时返回 *PreconditionFailedError
+	UpdateProject(ctx context.Context, userID, projectID, version int, req model.ProjectUploadRequest) (*model.DataResponse[*model.ResourceReview], error)
+	LikeProject(ctx context.Context, userID, projectID int) (*model.DataResponse[*model.LikeStatus], error)
+	UnlikeProject(ctx context.Context, userID, projectID int) (*model.DataResponse[*model.LikeStatus], error)
+	AddComment(ctx context.Context, userID, projectID int, content string) (*model.DataResponse[*model.Comment], error)
+	DeleteComment(ctx context.Context, userID, projectID, commentID int) (*model.DataResponse[*model.Comment], error)
+	ReplyComment(ctx context.Context, userID, projectID, commentID int, content string) (*model.DataResponse[*model.Comment], error)
+	DeleteReply(ctx context.Context, userID, projectID, commentID int) (*model.DataResponse[*model.Comment], error)
+	AddView(ctx context.Context, projectID int, visitor string) (*model.DataResponse[model.ViewCount], error)
+	GetDailyViews(ctx context.Context, projectID, days int) (*model.DataResponse[[]model.DailyViews], error)
+	CollectProject(ctx context.Context, userID, projectID int) (*model.DataResponse[*model.CollectStatus], error)
+	UncollectProject(ctx context.Context, userID, projectID int) (*model.DataResponse[*model.CollectStatus], error)
+	GetComments(ctx context.Context, projectID int, page pagination.Request) (*model.ListResponse[model.Comment], error)
 }
 
 type projectService struct {
 	projectRepo repository.ProjectRepository
+	index       repository.SearchIndex
+	views       *ViewCounter
+	suggester   *Suggester
+	filter      *ContentFilter
+	cursors     *pagination.Codec
 }
 
-func NewProjectService(projectRepo repository.ProjectRepository) ProjectService {
-	return &projectService{projectRepo: projectRepo}
+func NewProjectService(projectRepo repository.ProjectRepository, index repository.SearchIndex, views *ViewCounter, suggester *Suggester, filter *ContentFilter, cursors *pagination.Codec) ProjectService {
+	return &projectService{projectRepo: projectRepo, index: index, views: views, suggester: suggester, filter: filter, cursors: cursors}
 }
 
-func (s *projectService) GetProjects(ctx context.Context, category string, techStack []string, sort string, limit int, cursor, resourceType string) (map[string]interface{}, error) {
-	projects, err := s.projectRepo.GetProjects(ctx, category, techStack, sort, limit, cursor)
+func (s *projectService) GetProjects(ctx context.Context, category string, techStack []string, sort string, page pagination.Request, resourceType string) (*model.ListResponse[model.Project], error) {
+	const scope = "projects"
+	p, err := s.cursors.Page(scope, page)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    projects,
-	}, nil
-}
+	expr, err := tagquery.ParseAll(techStack)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *projectService) GetProject(ctx context.Context, projectID string) (map[string]interface{}, error) {
-	project, err := s.projectRepo.GetByID(ctx, projectID)
+	projects, err := s.projectRepo.GetProjects(ctx, category, expr, sort, p)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    project,
-	}, nil
+	return pageResponse(s.cursors, scope, projects), nil
 }
 
-func (s *projectService) SearchProjects(ctx context.Context, keyword string, category []string, cursor string, limit int) (map[string]interface{}, error) {
-	projects, err := s.projectRepo.Search(ctx, keyword, category, cursor, limit)
+func (s *projectService) GetProject(ctx context.Context, projectID int) (*model.DataResponse[*model.ProjectDetail], error) {
+	project, err := s.projectRepo.GetByID(ctx, projectID)
 	if err != nil {
 		return nil, err
 	}
+	if project == nil {
+		return nil, errors.New("project not found")
+	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    projects,
-	}, nil
+	return model.NewDataResponse("success", project), nil
 }
 
-func (s *projectService) UploadProject(ctx context.Context, userID int, req ProjectUploadRequest) (map[string]interface{}, error) {
-	// 将结构体转换为 map 传递给 repository
-	projectData := map[string]interface{}{
-		"name":        req.Name,
-		"description": req.Description,
-		"detail":      req.Detail,
-		"github":      req.Github,
-		"techStack":   req.TechStack,
-		"category":    req.Category,
-		"images":      req.Images,
+func (s *projectService) SearchProjects(ctx context.Context, keyword string, category []string, sort string, page pagination.Request) (*model.ListResponse[model.Project], error) {
+	const scope = "projects.search"
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
 	}
 
-	project, err := s.projectRepo.Create(ctx, userID, projectData)
+	projects, didYouMean, err := searchWithAssist(s.suggester, keyword, p,
+		func(keyword string, page pagination.Page) (*pagination.Result[model.Project], error) {
+			return s.projectRepo.Search(ctx, keyword, category, sort, page)
+		},
+		func(project *model.Project, terms [][]string) {
+			project.Highlights = highlights(terms, project.Name, project.Description)
+		})
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "Project uploaded successfully",
-		"data":    project,
-	}, nil
+	response := pageResponse(s.cursors, scope, projects)
+	response.DidYouMean = didYouMean
+	return response, nil
 }
 
-func (s *projectService) UpdateProject(ctx context.Context, userID int, projectID string, req ProjectUploadRequest) (map[string]interface{}, error) {
-	// 将结构体转换为 map 传递给 repository
-	projectData := map[string]interface{}{
-		"name":        req.Name,
-		"description": req.Description,
-		"detail":      req.Detail,
-		"github":      req.Github,
-		"techStack":   req.TechStack,
-		"category":    req.Category,
-		"images":      req.Images,
+func (s *projectService) UploadProject(ctx context.Context, userID int, req model.ProjectUploadRequest) (*model.DataResponse[*model.ResourceReview], error) {
+	if err := s.cleanProject(&req); err != nil {
+		return nil, err
 	}
+	project, err := s.projectRepo.Create(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDataResponse("Project uploaded successfully", project), nil
+}
 
-	project, err := s.projectRepo.Update(ctx, userID, projectID, projectData)
+func (s *projectService) UpdateProject(ctx context.Context, userID, projectID, version int, req model.ProjectUploadRequest) (*model.DataResponse[*model.ResourceReview], error) {
+	if err := s.cleanProject(&req); err != nil {
+		return nil, err
+	}
+	project, err := s.projectRepo.Update(ctx, userID, projectID, version, req)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		// 返回最新内容供客户端合并，修改后的项目处于待审核状态，不能用 GetByID
+		current, err := s.projectRepo.GetCurrent(ctx, projectID)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, errors.New("project not found")
+		}
+		return nil, &PreconditionFailedError{Version: current.Version, Current: current}
+	}
 	if err != nil {
 		return nil, err
 	}
+	// 修改后的项目重新进入审核，审核通过前从检索结果中移除
+	updateSearchIndex(ctx, s.index, model.ResourceTypeProject, projectID)
 
-	return map[string]interface{}{
-		"message": "Project updated successfully",
-		"data":    project,
-	}, nil
+	return model.NewDataResponse("Project updated successfully", project), nil
 }
 
-func (s *projectService) LikeProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
+// cleanProject 按敏感词表过滤项目的名称、简介和详情
+func (s *projectService) cleanProject(req *model.ProjectUploadRequest) error {
+	fields := []ContentField{
+		{Name: "name", Text: &req.Name},
+		{Name: "description", Text: &req.Description},
+		{Name: "detail", Text: &req.Detail},
+	}
+	return s.filter.Clean(append(fields, listFields("techStack", req.TechStack)...)...)
+}
+
+func (s *projectService) LikeProject(ctx context.Context, userID, projectID int) (*model.DataResponse[*model.LikeStatus], error) {
 	result, err := s.projectRepo.LikeProject(ctx, userID, projectID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeProject, projectID)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    result,
-	}, nil
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *projectService) UnlikeProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
+func (s *projectService) UnlikeProject(ctx context.Context, userID, projectID int) (*model.DataResponse[*model.LikeStatus], error) {
 	result, err := s.projectRepo.UnlikeProject(ctx, userID, projectID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeProject, projectID)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    result,
-	}, nil
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *projectService) AddComment(ctx context.Context, userID int, projectID, content string) (map[string]interface{}, error) {
+func (s *projectService) AddComment(ctx context.Context, userID, projectID int, content string) (*model.DataResponse[*model.Comment], error) {
+	content, flagged, err := s.filter.CleanComment(content)
+	if err != nil {
+		return nil, err
+	}
 	comment, err := s.projectRepo.AddComment(ctx, userID, projectID, content)
 	if err != nil {
 		return nil, err
 	}
+	s.filter.FlagComment(ctx, comment, flagged)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    comment,
-	}, nil
+	return model.NewDataResponse("success", comment), nil
 }
 
-func (s *projectService) DeleteComment(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
-	comment, err := s.projectRepo.DeleteComment(ctx, userID, projectID)
+func (s *projectService) DeleteComment(ctx context.Context, userID, projectID, commentID int) (*model.DataResponse[*model.Comment], error) {
+	comment, err := s.projectRepo.DeleteComment(ctx, userID, projectID, commentID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    comment,
-	}, nil
+	return model.NewDataResponse("success", comment), nil
 }
 
-func (s *projectService) ReplyComment(ctx context.Context, userID int, projectID, commentID, content string) (map[string]interface{}, error) {
+func (s *projectService) ReplyComment(ctx context.Context, userID, projectID, commentID int, content string) (*model.DataResponse[*model.Comment], error) {
+	content, flagged, err := s.filter.CleanComment(content)
+	if err != nil {
+		return nil, err
+	}
 	reply, err := s.projectRepo.ReplyComment(ctx, userID, projectID, commentID, content)
 	if err != nil {
 		return nil, err
 	}
+	s.filter.FlagComment(ctx, reply, flagged)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    reply,
-	}, nil
+	return model.NewDataResponse("success", reply), nil
 }
 
-func (s *projectService) DeleteReply(ctx context.Context, userID int, projectID, commentID string) (map[string]interface{}, error) {
+func (s *projectService) DeleteReply(ctx context.Context, userID, projectID, commentID int) (*model.DataResponse[*model.Comment], error) {
 	reply, err := s.projectRepo.DeleteReply(ctx, userID, projectID, commentID)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    reply,
-	}, nil
+	return model.NewDataResponse("success", reply), nil
 }
 
-func (s *projectService) AddView(ctx context.Context, projectID string) (map[string]interface{}, error) {
-	views, err := s.projectRepo.AddView(ctx, projectID)
+func (s *projectService) AddView(ctx context.Context, projectID int, visitor string) (*model.DataResponse[model.ViewCount], error) {
+	views, err := s.views.Record(ctx, model.ResourceTypeProject, projectID, visitor)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data": map[string]interface{}{
-			"views": views,
-		},
-	}, nil
+	return model.NewDataResponse("success", model.ViewCount{Views: views}), nil
+}
+
+func (s *projectService) GetDailyViews(ctx context.Context, projectID, days int) (*model.DataResponse[[]model.DailyViews], error) {
+	daily, err := s.views.Daily(ctx, model.ResourceTypeProject, projectID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDataResponse("success", daily), nil
 }
 
-func (s *projectService) CollectProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
+func (s *projectService) CollectProject(ctx context.Context, userID, projectID int) (*model.DataResponse[*model.CollectStatus], error) {
 	result, err := s.projectRepo.CollectProject(ctx, userID, projectID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeProject, projectID)
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    result,
-	}, nil
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *projectService) UncollectProject(ctx context.Context, userID int, projectID string) (map[string]interface{}, error) {
+func (s *projectService) UncollectProject(ctx context.Context, userID, projectID int) (*model.DataResponse[*model.CollectStatus], error) {
 	result, err := s.projectRepo.UncollectProject(ctx, userID, projectID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeProject, projectID)
+
+	return model.NewDataResponse("success", result), nil
+}
+
+func (s *projectService) GetComments(ctx context.Context, projectID int, page pagination.Request) (*model.ListResponse[model.Comment], error) {
+	scope := commentScope(model.ResourceTypeProject, projectID)
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.projectRepo.GetComments(ctx, projectID, p)
+	if err != nil {
+		return nil, err
+	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    result,
-	}, nil
+	return pageResponse(s.cursors, scope, comments), nil
 }
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"log"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"time"
+)
+
+// ReconcileService 按来源表校对点赞数、收藏数和回复数，并清理多态表中的孤儿行
+type ReconcileService interface {
+	// Reconcile fix 为 false 时只报告偏差，不做修改
+	Reconcile(ctx context.Context, fix bool) (*model.ReconcileReport, error)
+}
+
+type reconcileService struct {
+	reconcileRepo repository.ReconcileRepository
+}
+
+func NewReconcileService(reconcileRepo repository.ReconcileRepository) ReconcileService {
+	return &reconcileService{reconcileRepo: reconcileRepo}
+}
+
+func (s *reconcileService) Reconcile(ctx context.Context, fix bool) (*model.ReconcileReport, error) {
+	report, err := s.reconcileRepo.Reconcile(ctx, fix)
+	if err != nil {
+		return nil, err
+	}
+	LogReconcileReport(report)
+	return report, nil
+}
+
+// LogReconcileReport 逐条记录发现的偏差和孤儿行
+func LogReconcileReport(report *model.ReconcileReport) {
+	action := "found"
+	if report.Fixed {
+		action = "fixed"
+	}
+	for _, d := range report.Drifts {
+		log.Printf("Counter drift %s: %s[%d].%s stored %d, actual %d", action, d.Table, d.ID, d.Column, d.Stored, d.Actual)
+	}
+	for _, o := range report.Orphans {
+		log.Printf("Orphaned rows %s: %d %s rows reference missing %s %d", action, o.Rows, o.Table, o.ResourceType, o.ResourceID)
+	}
+}
+
+// RunReconcile 每隔 interval 校对并修正一次计数，直到 ctx 取消
+func RunReconcile(ctx context.Context, reconcile ReconcileService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			report, err := reconcile.Reconcile(ctx, true)
+			if err != nil {
+				log.Printf("Failed to reconcile counters: %v", err)
+				continue
+			}
+			if len(report.Drifts) > 0 || len(report.Orphans) > 0 {
+				log.Printf("Reconciled %d counter drifts and %d groups of orphaned rows", len(report.Drifts), len(report.Orphans))
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"strings"
+)
+
+// ErrInvalidTransition 资源当前的审核状态不允许该操作
+var ErrInvalidTransition = errors.New("invalid status transition")
+
+// reviewTransition 审核状态机中的一条边
+type reviewTransition struct {
+	from []string
+	to   string
+	// byAuthor 由资源的提交者而不是管理员发起
+	byAuthor bool
+}
+
+// reviewTransitions 审核状态机，键为操作：待审核（含重新提交）的资源可以通过或拒绝，
+// 已通过的可以隐藏，被拒绝的可以由提交者重新提交
+var reviewTransitions = map[string]reviewTransition{
+	"approve":  {from: []string{model.StatusPending, model.StatusResubmitted}, to: model.StatusApproved},
+	"reject":   {from: []string{model.StatusPending, model.StatusResubmitted}, to: model.StatusRejected},
+	"hide":     {from: []string{model.StatusApproved}, to: model.StatusHidden},
+	"resubmit": {from: []string{model.StatusRejected}, to: model.StatusResubmitted, byAuthor: true},
+}
+
+// checkReviewType 审核目标的类型：工具、项目或两类课程资源
+func checkReviewType(resourceType string) error {
+	switch resourceType {
+	case model.ResourceTypeTool, model.ResourceTypeProject, model.ResourceTypeCourseWeb, model.ResourceTypeCourseUpload:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidResourceType, resourceType)
+}
+
+// transitionStatus 按审核状态机执行 action。byAuthor 为 true 时 operatorID 必须是资源的提交者；
+// version 的含义同 TrashService，课程资源没有版本号，不检查
+func transitionStatus(ctx context.Context, reviews repository.ReviewRepository, operatorID int, byAuthor bool,
+	resourceType string, resourceID, version int, action, reason string) (*model.Maneuver, error) {
+	if err := checkReviewType(resourceType); err != nil {
+		return nil, err
+	}
+	transition, ok := reviewTransitions[action]
+	if !ok || transition.byAuthor != byAuthor {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAction, action)
+	}
+	reason = strings.TrimSpace(reason)
+	if transition.to == model.StatusRejected && reason == "" {
+		return nil, fmt.Errorf("%w: reject reason is required", ErrInvalidAction)
+	}
+
+	change := repository.StatusChange{
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		From:         transition.from,
+		To:           transition.to,
+		RejectReason: reason,
+		OperatorID:   operatorID,
+		Version:      version,
+	}
+	if byAuthor {
+		change.OwnerID = operatorID
+	}
+
+	maneuver, err := reviews.Transition(ctx, change)
+	if errors.Is(err, repository.ErrStatusConflict) || errors.Is(err, repository.ErrVersionConflict) {
+		current, getErr := reviews.Get(ctx, change.OwnerID, resourceType, resourceID)
+		if getErr != nil {
+			return nil, getErr
+		}
+		if current == nil {
+			return nil, ErrResourceNotFound
+		}
+		// 并发的审核先完成时，版本冲突也按状态不允许处理
+		if errors.Is(err, repository.ErrStatusConflict) || !slices.Contains(transition.from, current.AuditStatus) {
+			return nil, fmt.Errorf("%w: cannot %s %s %d in status %s", ErrInvalidTransition, action, resourceType, resourceID, current.AuditStatus)
+		}
+		return nil, &PreconditionFailedError{Version: current.Version, Current: current}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if maneuver == nil {
+		return nil, ErrResourceNotFound
+	}
+	return maneuver, nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"log"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+)
+
+// SearchService 跨工具、课程、项目的统一检索
+type SearchService interface {
+	// Search 返回混合排序的一页结果；第一页同时返回分面统计，翻页时不再重复统计
+	Search(ctx context.Context, q model.SearchQuery, page pagination.Request) (*model.SearchResponse, error)
+	// Suggest 返回检索框的输入提示，limit 不大于 0 时取默认条数
+	Suggest(ctx context.Context, q string, limit int) (*model.SuggestResponse, error)
+}
+
+type searchService struct {
+	index     repository.SearchIndex
+	suggester *Suggester
+	cursors   *pagination.Codec
+}
+
+func NewSearchService(index repository.SearchIndex, suggester *Suggester, cursors *pagination.Codec) SearchService {
+	return &searchService{index: index, suggester: suggester, cursors: cursors}
+}
+
+func (s *searchService) Search(ctx context.Context, q model.SearchQuery, page pagination.Request) (*model.SearchResponse, error) {
+	for _, resourceType := range q.Types {
+		if err := checkResourceType(resourceType); err != nil {
+			return nil, err
+		}
+	}
+
+	const scope = "search"
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	hits, didYouMean, err := searchWithAssist(s.suggester, q.Keyword, p,
+		func(keyword string, page pagination.Page) (*pagination.Result[model.SearchHit], error) {
+			q := q
+			q.Keyword = keyword
+			return s.index.Search(ctx, q, page)
+		},
+		func(hit *model.SearchHit, terms [][]string) {
+			hit.Highlights = highlights(terms, hit.Name, hit.Description)
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	response := &model.SearchResponse{
+		Message:    "success",
+		Data:       hits.Items,
+		DidYouMean: didYouMean,
+		PageInfo:   pageInfo(s.cursors, scope, hits.Info),
+	}
+	if response.Data == nil {
+		response.Data = []model.SearchHit{}
+	}
+	if page.Cursor == "" {
+		// 分面按与结果相同的纠错扩展后的关键词统计
+		facetQuery := q
+		facetQuery.Keyword = s.suggester.expand(q.Keyword)
+		if response.Facets, err = s.index.Facets(ctx, facetQuery); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+func (s *searchService) Suggest(ctx context.Context, q string, limit int) (*model.SuggestResponse, error) {
+	return &model.SuggestResponse{Message: "success", Data: s.suggester.Suggest(q, limit)}, nil
+}
+
+// updateSearchIndex 资源的可见性或内容变化后同步检索索引。修改已经提交，同步失败只记录日志，
+// 索引可以用 reindex 命令重建
+func updateSearchIndex(ctx context.Context, index repository.SearchIndex, resourceType string, resourceID int) {
+	if err := index.Update(ctx, resourceType, resourceID); err != nil {
+		log.Printf("Failed to update search index for %s %d: %v", resourceType, resourceID, err)
+	}
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/sensitive"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrSensitiveContent 内容含有 severe 等级的敏感词，错误中只带字段名，不透露命中的词
+	ErrSensitiveContent = errors.New("content contains sensitive words")
+	// ErrInvalidSensitiveWord 敏感词为空（只有空白和标点）或等级无效
+	ErrInvalidSensitiveWord = errors.New("invalid sensitive word")
+	// ErrSensitiveWordNotFound 敏感词不存在
+	ErrSensitiveWordNotFound = errors.New("sensitive word not found")
+)
+
+// ContentFilter 按敏感词表过滤评论和提交的内容：severe 拒绝，middle 替换为 *，mild 送人工审核。
+// 词表建成自动机保存在内存中，由 Run 定期从数据库重建，管理员修改词表后立即重建
+type ContentFilter struct {
+	words   repository.SensitiveWordRepository
+	reports repository.CommentReportRepository
+
+	mu      sync.RWMutex
+	matcher *sensitive.Matcher
+}
+
+func NewContentFilter(words repository.SensitiveWordRepository, reports repository.CommentReportRepository) *ContentFilter {
+	return &ContentFilter{words: words, reports: reports, matcher: sensitive.New(nil)}
+}
+
+// Refresh 从数据库读取词表并重建自动机，返回词数
+func (f *ContentFilter) Refresh(ctx context.Context) (int, error) {
+	words, err := f.words.List(ctx)
+	if err != nil {
+		return 0, err
+	}
+	entries := make([]sensitive.Word, 0, len(words))
+	for _, w := range words {
+		level, ok := sensitive.ParseLevel(w.Level)
+		if !ok {
+			log.Printf("Skipping sensitive word %d with unknown level %q", w.ID, w.Level)
+			continue
+		}
+		entries = append(entries, sensitive.Word{Text: w.Word, Level: level})
+	}
+	matcher := sensitive.New(entries)
+
+	f.mu.Lock()
+	f.matcher = matcher
+	f.mu.Unlock()
+	return matcher.Len(), nil
+}
+
+// Run 每隔 interval 重建一次词表，直到 ctx 取消；多个实例共用数据库时靠它同步其他实例的修改
+func (f *ContentFilter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := f.Refresh(ctx); err != nil {
+				log.Printf("Failed to refresh sensitive words: %v", err)
+			}
+		}
+	}
+}
+
+func (f *ContentFilter) filter(text string) sensitive.Result {
+	f.mu.RLock()
+	matcher := f.matcher
+	f.mu.RUnlock()
+	return matcher.Filter(text)
+}
+
+// ContentField 提交内容中要过滤的一个字段
+type ContentField struct {
+	Name string
+	Text *string
+}
+
+// listFields 把标签等列表字段的每一项作为一个字段，过滤结果写回 values
+func listFields(name string, values []string) []ContentField {
+	fields := make([]ContentField, len(values))
+	for i := range values {
+		fields[i] = ContentField{Name: name, Text: &values[i]}
+	}
+	return fields
+}
+
+// Clean 依次过滤提交内容的各个字段，middle 等级的词原地替换为 *；
+// 任一字段含 severe 等级的词时返回 ErrSensitiveContent。
+// 提交的资源本来就要审核，mild 等级的词不另做处理
+func (f *ContentFilter) Clean(fields ...ContentField) error {
+	for _, field := range fields {
+		result := f.filter(*field.Text)
+		if result.Level == sensitive.Severe {
+			return fmt.Errorf("%w: %s", ErrSensitiveContent, field.Name)
+		}
+		*field.Text = result.Text
+	}
+	return nil
+}
+
+// CleanComment 过滤评论或回复：含 severe 等级的词时返回 ErrSensitiveContent，
+// 否则返回替换 middle 等级的词后的内容，以及命中的 mild 等级的词，保存后交给 FlagComment
+func (f *ContentFilter) CleanComment(content string) (string, []string, error) {
+	result := f.filter(content)
+	if result.Level == sensitive.Severe {
+		return "", nil, fmt.Errorf("%w: comment", ErrSensitiveContent)
+	}
+	var mild []string
+	for _, w := range result.Words {
+		if w.Level == sensitive.Mild {
+			mild = append(mild, w.Text)
+		}
+	}
+	return result.Text, mild, nil
+}
+
+// FlagComment 把命中 mild 等级敏感词的评论以系统标记送入评论审核队列并先隐藏，管理员驳回后恢复显示。
+// 评论已经保存，失败只记录日志
+func (f *ContentFilter) FlagComment(ctx context.Context, comment *model.Comment, words []string) {
+	if comment == nil || len(words) == 0 {
+		return
+	}
+	detail := "命中敏感词：" + strings.Join(words, "、")
+	if _, _, err := f.reports.Report(ctx, comment.CommentID, 0, model.ReportSensitive, detail); err != nil {
+		log.Printf("Failed to flag comment %d for review: %v", comment.CommentID, err)
+		return
+	}
+	if _, err := f.reports.Hide(ctx, comment.CommentID); err != nil {
+		log.Printf("Failed to hide flagged comment %d: %v", comment.CommentID, err)
+	}
+}
+
+// SensitiveWordService 管理员维护敏感词表，修改后立即重建过滤器的词表
+type SensitiveWordService interface {
+	List(ctx context.Context) ([]model.SensitiveWord, error)
+	// Add 添加敏感词，词已存在时修改其等级。词去掉首尾空白并转为小写，匹配时不区分大小写
+	Add(ctx context.Context, adminID int, req model.SensitiveWordRequest) (*model.SensitiveWord, error)
+	Delete(ctx context.Context, id int) error
+	// Reload 从数据库重新加载词表，返回词数；直接修改了数据库中的词表时使用
+	Reload(ctx context.Context) (int, error)
+}
+
+type sensitiveWordService struct {
+	words   repository.SensitiveWordRepository
+	filter  *ContentFilter
+	auditor *Auditor
+}
+
+func NewSensitiveWordService(words repository.SensitiveWordRepository, filter *ContentFilter, auditor *Auditor) SensitiveWordService {
+	return &sensitiveWordService{words: words, filter: filter, auditor: auditor}
+}
+
+func (s *sensitiveWordService) List(ctx context.Context) ([]model.SensitiveWord, error) {
+	words, err := s.words.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if words == nil {
+		words = []model.SensitiveWord{}
+	}
+	return words, nil
+}
+
+// find 按 ID 或词查找敏感词，不存在时返回 nil
+func (s *sensitiveWordService) find(ctx context.Context, match func(w *model.SensitiveWord) bool) (*model.SensitiveWord, error) {
+	words, err := s.words.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range words {
+		if match(&words[i]) {
+			return &words[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *sensitiveWordService) Add(ctx context.Context, adminID int, req model.SensitiveWordRequest) (*model.SensitiveWord, error) {
+	word := strings.ToLower(strings.TrimSpace(req.Word))
+	if sensitive.New([]sensitive.Word{{Text: word, Level: sensitive.Mild}}).Len() == 0 {
+		return nil, fmt.Errorf("%w: word has no letters", ErrInvalidSensitiveWord)
+	}
+	if _, ok := sensitive.ParseLevel(req.Level); !ok {
+		return nil, fmt.Errorf("%w: unknown level %q", ErrInvalidSensitiveWord, req.Level)
+	}
+
+	saved, err := audited(ctx, s.auditor, func(ctx context.Context) (*model.SensitiveWord, *auditChange, error) {
+		before, err := s.find(ctx, func(w *model.SensitiveWord) bool { return w.Word == word })
+		if err != nil {
+			return nil, nil, err
+		}
+		saved := &model.SensitiveWord{Word: word, Level: req.Level, CreatedBy: adminID}
+		if err := s.words.Save(ctx, saved); err != nil {
+			return nil, nil, err
+		}
+		return saved, &auditChange{action: model.AuditSensitiveWordSave, targetType: model.AuditTargetSensitiveWord,
+			targetID: saved.ID, before: before, after: saved}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.filter.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (s *sensitiveWordService) Delete(ctx context.Context, id int) error {
+	_, err := audited(ctx, s.auditor, func(ctx context.Context) (struct{}, *auditChange, error) {
+		before, err := s.find(ctx, func(w *model.SensitiveWord) bool { return w.ID == id })
+		if err != nil {
+			return struct{}{}, nil, err
+		}
+		deleted, err := s.words.Delete(ctx, id)
+		if err != nil {
+			return struct{}{}, nil, err
+		}
+		if !deleted {
+			return struct{}{}, nil, ErrSensitiveWordNotFound
+		}
+		return struct{}{}, &auditChange{action: model.AuditSensitiveWordDelete, targetType: model.AuditTargetSensitiveWord,
+			targetID: id, before: before}, nil
+	})
+	if err != nil {
+		return err
+	}
+	_, err = s.filter.Refresh(ctx)
+	return err
+}
+
+func (s *sensitiveWordService) Reload(ctx context.Context) (int, error) {
+	count, err := s.filter.Refresh(ctx)
+	if err != nil {
+		return 0, err
+	}
+	// 重新加载不修改数据库，只记录操作和加载后的词数
+	_, err = audited(ctx, s.auditor, func(ctx context.Context) (struct{}, *auditChange, error) {
+		return struct{}{}, &auditChange{action: model.AuditSensitiveWordReload, targetType: model.AuditTargetSensitiveWord,
+			after: map[string]int{"count": count}}, nil
+	})
+	return count, err
+}
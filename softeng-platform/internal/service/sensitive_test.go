@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"testing"
+)
+
+func TestContentFilterListFields(t *testing.T) {
+	ctx := context.Background()
+	store := repository.NewMemoryStore()
+	users := repository.NewMemoryUserRepository(store)
+	words := repository.NewMemorySensitiveWordRepository(store)
+	for _, word := range []*model.SensitiveWord{{Word: "混蛋", Level: "middle"}, {Word: "赌博网站", Level: "severe"}} {
+		if err := words.Save(ctx, word); err != nil {
+			t.Fatalf("Save %s: %v", word.Word, err)
+		}
+	}
+	filter := NewContentFilter(words, repository.NewMemoryCommentReportRepository(store))
+	if _, err := filter.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	user := &model.User{Username: "rita", Nickname: "rita", Email: "rita@example.com", Password: "hashed", Role: "user"}
+	if err := users.Create(ctx, user); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	index := repository.NewSQLSearchIndex(repository.NewMemorySearchRepository(store))
+	toolRepo := repository.NewMemoryToolRepository(store)
+	tools := NewToolService(toolRepo, index, nil, nil, filter, nil)
+	projects := NewProjectService(repository.NewMemoryProjectRepository(store), index, nil, nil, filter, nil)
+	courses := NewCourseService(repository.NewMemoryCourseRepository(store), index, nil, nil, filter, nil)
+
+	// 工具标签中 middle 等级的词替换为 *
+	review, err := tools.SubmitTool(ctx, user.ID, model.ToolSubmitRequest{Name: "kit", Link: "https://example.com/kit",
+		Description: "kit", DescriptionDetail: "kit", Category: "IDE", Tags: []string{"Go", "混蛋工具"}})
+	if err != nil {
+		t.Fatalf("SubmitTool: %v", err)
+	}
+	if err := store.SetStatus(ctx, model.ResourceTypeTool, review.Data.ResourceID, model.StatusApproved); err != nil {
+		t.Fatalf("approve tool: %v", err)
+	}
+	if tool, err := toolRepo.GetByID(ctx, review.Data.ResourceID); err != nil || tool == nil || fmt.Sprint(tool.Tags) != "[Go **工具]" {
+		t.Errorf("tool tags: got %+v, %v; want [Go **工具]", tool, err)
+	}
+
+	// 工具标签、项目技术栈、课程资源标签含 severe 等级的词时拒绝提交
+	_, err = tools.SubmitTool(ctx, user.ID, model.ToolSubmitRequest{Name: "kit2", Link: "https://example.com/kit2",
+		Description: "kit", DescriptionDetail: "kit", Category: "IDE", Tags: []string{"赌博网站"}})
+	if !errors.Is(err, ErrSensitiveContent) {
+		t.Errorf("SubmitTool with a severe tag: got %v, want ErrSensitiveContent", err)
+	}
+	_, err = projects.UploadProject(ctx, user.ID, model.ProjectUploadRequest{Name: "demo", Description: "demo", Detail: "demo",
+		Github: "https://github.com/example/demo", Category: "web", TechStack: []string{"Go", "赌博网站"}})
+	if !errors.Is(err, ErrSensitiveContent) {
+		t.Errorf("UploadProject with a severe tech stack: got %v, want ErrSensitiveContent", err)
+	}
+	courseID, err := store.CreateCourse(ctx, model.Course{Name: "软件工程", Semester: "2026春"})
+	if err != nil {
+		t.Fatalf("CreateCourse: %v", err)
+	}
+	_, err = courses.UploadResource(ctx, user.ID, courseID, model.ResourceTypeCourse,
+		model.CourseUploadRequest{Resource: "https://example.com/notes", Description: "笔记", Tags: []string{"赌博网站"}})
+	if !errors.Is(err, ErrSensitiveContent) {
+		t.Errorf("UploadResource with a severe tag: got %v, want ErrSensitiveContent", err)
+	}
+}
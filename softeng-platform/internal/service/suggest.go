@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"log"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+	"softeng-platform/internal/suggest"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// defaultSuggestions 与 maxSuggestions 为输入提示默认和最多返回的条数
+	defaultSuggestions = 10
+	maxSuggestions     = 20
+
+	// suggestLoveWeight 计算热度时一次点赞相当于的浏览次数
+	suggestLoveWeight = 10
+
+	// maxFuzzyAlternatives 一个拼写有误的检索词最多补充的相近词个数
+	maxFuzzyAlternatives = 5
+)
+
+// Suggester 检索框的输入提示和拼写纠错。资源名称、工具标签、项目技术栈和课程教师建在内存前缀树中，
+// 按热度（浏览量与加权的点赞数之和）排序；资源文本中的拉丁字母单词收入纠错词典。
+// 由 Run 定期从数据库整体重建，重建期间仍使用旧的数据
+type Suggester struct {
+	source repository.SearchRepository
+
+	mu          sync.RWMutex
+	trie        *suggest.Trie
+	suggestions []model.Suggestion // 前缀树中的记录ID为这里的下标
+	dictionary  *suggest.Dictionary
+}
+
+func NewSuggester(source repository.SearchRepository) *Suggester {
+	return &Suggester{
+		source:     source,
+		trie:       suggest.NewBuilder(maxSuggestions).Build(),
+		dictionary: suggest.NewDictionary(),
+	}
+}
+
+// Suggest 返回以 q 开头的提示，q 可以是原文、全拼或拼音首字母，忽略大小写、空白和标点
+func (s *Suggester) Suggest(q string, limit int) []model.Suggestion {
+	if limit <= 0 {
+		limit = defaultSuggestions
+	}
+	if limit > maxSuggestions {
+		limit = maxSuggestions
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	result := []model.Suggestion{}
+	for _, id := range s.trie.Lookup(q, limit) {
+		result = append(result, s.suggestions[id])
+	}
+	return result
+}
+
+// Refresh 从数据库读取全部已审核资源并重建前缀树，返回提示条数
+func (s *Suggester) Refresh(ctx context.Context) (int, error) {
+	var suggestions []model.Suggestion
+	// 标签、技术栈、教师按（类型, 文本）合并
+	shared := make(map[[2]string]int)
+	dictionary := suggest.NewDictionary()
+	err := s.source.Documents(ctx, func(doc *model.SearchDocument) error {
+		for _, text := range append([]string{doc.Name, doc.Description, doc.Detail}, doc.Tags...) {
+			dictionary.Add(text)
+		}
+		suggestions = append(suggestions, model.Suggestion{
+			Text: doc.Name, Kind: model.SuggestionName,
+			ResourceType: doc.ResourceType, ResourceID: doc.ResourceID,
+			Views: doc.Views, Loves: doc.Loves,
+		})
+
+		kind := map[string]string{
+			model.ResourceTypeTool:    model.SuggestionTag,
+			model.ResourceTypeProject: model.SuggestionTechStack,
+			model.ResourceTypeCourse:  model.SuggestionTeacher,
+		}[doc.ResourceType]
+		for _, text := range doc.Tags {
+			key := [2]string{kind, text}
+			i, ok := shared[key]
+			if !ok {
+				i = len(suggestions)
+				shared[key] = i
+				suggestions = append(suggestions, model.Suggestion{Text: text, Kind: kind, ResourceType: doc.ResourceType})
+			}
+			suggestions[i].Views += doc.Views
+			suggestions[i].Loves += doc.Loves
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// 按文本排序，使热度相同的提示顺序稳定
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		return suggestions[i].Text < suggestions[j].Text
+	})
+	builder := suggest.NewBuilder(maxSuggestions)
+	for i, suggestion := range suggestions {
+		builder.Add(suggestion.Text, i, suggestion.Views+suggestion.Loves*suggestLoveWeight)
+	}
+	trie := builder.Build()
+
+	s.mu.Lock()
+	s.trie, s.suggestions, s.dictionary = trie, suggestions, dictionary
+	s.mu.Unlock()
+	return len(suggestions), nil
+}
+
+// Run 每隔 interval 重建一次前缀树，直到 ctx 取消
+func (s *Suggester) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.Refresh(ctx); err != nil {
+				log.Printf("Failed to refresh search suggestions: %v", err)
+			}
+		}
+	}
+}
+
+// fuzzyDistance 检索时容忍的编辑距离：少于 3 个字母不纠错，3～5 个字母容忍 1 处，更长的容忍 2 处
+func fuzzyDistance(term string) int {
+	switch n := utf8.RuneCountInString(term); {
+	case n < 3:
+		return 0
+	case n <= 5:
+		return 1
+	}
+	return 2
+}
+
+// correctionDistance 给出“您是不是要找”时容忍的编辑距离，比检索时宽松
+func correctionDistance(term string) int {
+	if utf8.RuneCountInString(term) < 4 {
+		return fuzzyDistance(term)
+	}
+	return 2
+}
+
+// correctTerms 对关键词中不能直接命中任何词的拉丁字母检索词调用 fix，返回替换后的关键词。
+// 含汉字、符号或已带候选写法（|）的检索词保持不变
+func (s *Suggester) correctTerms(keyword string, fix func(dictionary *suggest.Dictionary, term string) string) string {
+	s.mu.RLock()
+	dictionary := s.dictionary
+	s.mu.RUnlock()
+
+	fields := strings.Fields(keyword)
+	for i, field := range fields {
+		term := strings.ToLower(field)
+		if suggest.IsLatin(term) && fuzzyDistance(term) > 0 && !dictionary.Contains(term) {
+			if fixed := fix(dictionary, term); fixed != "" {
+				fields[i] = fixed
+			}
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// expand 为拼写可能有误的检索词补充相近的词作为候选写法，如 "vsiual" 扩展为 "vsiual|visual"
+func (s *Suggester) expand(keyword string) string {
+	return s.correctTerms(keyword, func(dictionary *suggest.Dictionary, term string) string {
+		similar := dictionary.Similar(term, fuzzyDistance(term), maxFuzzyAlternatives)
+		if len(similar) == 0 {
+			return ""
+		}
+		return term + "|" + strings.Join(similar, "|")
+	})
+}
+
+// didYouMean 依次尝试拼写纠正后的关键词和以关键词（原文、全拼或拼音首字母）开头的最热门提示，
+// 返回第一个 found 为 true 的候选，都没有结果时返回空串
+func (s *Suggester) didYouMean(keyword string, found func(keyword string) (bool, error)) (string, error) {
+	if strings.TrimSpace(keyword) == "" {
+		return "", nil
+	}
+
+	var candidates []string
+	corrected := s.correctTerms(keyword, func(dictionary *suggest.Dictionary, term string) string {
+		if similar := dictionary.Similar(term, correctionDistance(term), 1); len(similar) > 0 {
+			return similar[0]
+		}
+		return ""
+	})
+	if corrected != strings.Join(strings.Fields(keyword), " ") {
+		candidates = append(candidates, corrected)
+	}
+	for _, suggestion := range s.Suggest(keyword, 1) {
+		if suggest.Normalize(suggestion.Text) != suggest.Normalize(keyword) {
+			candidates = append(candidates, suggestion.Text)
+		}
+	}
+
+	for _, candidate := range candidates {
+		ok, err := found(candidate)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return candidate, nil
+		}
+	}
+	return "", nil
+}
+
+// searchWithAssist 用拼写纠错扩展后的关键词检索，并按 highlight 为每条结果加上高亮；
+// 第一页没有结果时返回建议改用的关键词
+func searchWithAssist[T any](suggester *Suggester, keyword string, page pagination.Page,
+	search func(keyword string, page pagination.Page) (*pagination.Result[T], error),
+	highlight func(item *T, terms [][]string)) (*pagination.Result[T], string, error) {
+	expanded := suggester.expand(keyword)
+	result, err := search(expanded, page)
+	if err != nil {
+		return nil, "", err
+	}
+	terms := repository.SearchTerms(expanded)
+	for i := range result.Items {
+		highlight(&result.Items[i], terms)
+	}
+	if page.After != nil || len(result.Items) > 0 {
+		return result, "", nil
+	}
+
+	suggestion, err := suggester.didYouMean(keyword, func(keyword string) (bool, error) {
+		found, err := search(keyword, pagination.Page{Limit: 1})
+		if err != nil {
+			return false, err
+		}
+		return len(found.Items) > 0, nil
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return result, suggestion, nil
+}
@@ -2,181 +2,238 @@ package service
 
 import (
 	"context"
+	"errors"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
 	"softeng-platform/internal/repository"
+	"softeng-platform/internal/tagquery"
 )
 
 type ToolService interface {
-	GetTools(ctx context.Context, category, tags []string, sort, cursor string, pageSize int) (map[string]interface{}, error)
-	GetTool(ctx context.Context, resourceID, resourceType string) (map[string]interface{}, error)
-	SearchTools(ctx context.Context, keyword, cursor string, pageSize int, resourceType string) (map[string]interface{}, error)
-	SubmitTool(ctx context.Context, userID int, req ToolSubmitRequest) (map[string]interface{}, error)
-	LikeTool(ctx context.Context, userID int, resourceID string) (map[string]interface{}, error)
-	UnlikeTool(ctx context.Context, userID int, resourceID string) (map[string]interface{}, error)
-	CollectTool(ctx context.Context, userID int, resourceID, resourceType string) (map[string]interface{}, error)
-	UncollectTool(ctx context.Context, userID int, resourceID, resourceType string) (map[string]interface{}, error)
-	AddComment(ctx context.Context, userID int, resourceID, resourceType, content string) (map[string]interface{}, error)
-	DeleteComment(ctx context.Context, userID int, resourceID string) (map[string]interface{}, error)
-	ReplyComment(ctx context.Context, userID int, resourceID, commentID, resourceType, content string) (map[string]interface{}, error)
-	DeleteReply(ctx context.Context, userID int, resourceID, commentID string) (map[string]interface{}, error)
-	AddView(ctx context.Context, resourceID string) (map[string]interface{}, error)
-}
-
-// ToolSubmitRequest 工具提交请求结构体
-type ToolSubmitRequest struct {
-	Name              string   `form:"name" json:"name" binding:"required"`
-	Link              string   `form:"link" json:"link" binding:"required"`
-	Description       string   `form:"description" json:"description" binding:"required"`
-	DescriptionDetail string   `form:"description_detail" json:"description_detail" binding:"required"`
-	Category          string   `form:"catagory" json:"catagory" binding:"required"`
-	Tags              []string `form:"tags" json:"tags" binding:"required"`
+	// GetTools category 为“任一”；tags 为标签查询表达式（见 tagquery），多个表达式之间为“或”，
+	// 表达式有语法错误时返回 *tagquery.SyntaxError。SearchTools 的筛选规则相同
+	GetTools(ctx context.Context, category, tags []string, sort string, page pagination.Request) (*model.ListResponse[model.Tool], error)
+	GetTool(ctx context.Context, resourceID int, resourceType string) (*model.DataResponse[*model.Tool], error)
+	SearchTools(ctx context.Context, keyword string, category, tags []string, sort string, page pagination.Request, resourceType string) (*model.ListResponse[model.Tool], error)
+	SubmitTool(ctx context.Context, userID int, req model.ToolSubmitRequest) (*model.DataResponse[*model.ResourceReview], error)
+	LikeTool(ctx context.Context, userID, resourceID int) (*model.DataResponse[*model.LikeStatus], error)
+	UnlikeTool(ctx context.Context, userID, resourceID int) (*model.DataResponse[*model.LikeStatus], error)
+	CollectTool(ctx context.Context, userID, resourceID int, resourceType string) (*model.DataResponse[*model.CollectStatus], error)
+	UncollectTool(ctx context.Context, userID, resourceID int, resourceType string) (*model.DataResponse[*model.CollectStatus], error)
+	AddComment(ctx context.Context, userID, resourceID int, resourceType, content string) (*model.DataResponse[*model.Comment], error)
+	DeleteComment(ctx context.Context, userID, resourceID, commentID int) (*model.DataResponse[*model.Comment], error)
+	ReplyComment(ctx context.Context, userID, resourceID, commentID int, resourceType, content string) (*model.DataResponse[*model.Comment], error)
+	DeleteReply(ctx context.Context, userID, resourceID, commentID int) (*model.DataResponse[*model.Comment], error)
+	AddView(ctx context.Context, resourceID int, visitor string) (*model.DataResponse[model.ViewCount], error)
+	GetDailyViews(ctx context.Context, resourceID, days int) (*model.DataResponse[[]model.DailyViews], error)
+	GetComments(ctx context.Context, resourceID int, page pagination.Request) (*model.ListResponse[model.Comment], error)
 }
 
 type toolService struct {
-	toolRepo repository.ToolRepository
+	toolRepo  repository.ToolRepository
+	index     repository.SearchIndex
+	views     *ViewCounter
+	suggester *Suggester
+	filter    *ContentFilter
+	cursors   *pagination.Codec
 }
 
-func NewToolService(toolRepo repository.ToolRepository) ToolService {
-	return &toolService{toolRepo: toolRepo}
+func NewToolService(toolRepo repository.ToolRepository, index repository.SearchIndex, views *ViewCounter, suggester *Suggester, filter *ContentFilter, cursors *pagination.Codec) ToolService {
+	return &toolService{toolRepo: toolRepo, index: index, views: views, suggester: suggester, filter: filter, cursors: cursors}
 }
 
-func (s *toolService) GetTools(ctx context.Context, category, tags []string, sort, cursor string, pageSize int) (map[string]interface{}, error) {
-	tools, err := s.toolRepo.GetTools(ctx, category, tags, sort, cursor, pageSize)
+func (s *toolService) GetTools(ctx context.Context, category, tags []string, sort string, page pagination.Request) (*model.ListResponse[model.Tool], error) {
+	const scope = "tools"
+	p, err := s.cursors.Page(scope, page)
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    tools,
-	}, nil
+	expr, err := tagquery.ParseAll(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	tools, err := s.toolRepo.GetTools(ctx, category, expr, sort, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return pageResponse(s.cursors, scope, tools), nil
 }
 
-func (s *toolService) GetTool(ctx context.Context, resourceID, resourceType string) (map[string]interface{}, error) {
+func (s *toolService) GetTool(ctx context.Context, resourceID int, resourceType string) (*model.DataResponse[*model.Tool], error) {
 	tool, err := s.toolRepo.GetByID(ctx, resourceID)
 	if err != nil {
 		return nil, err
 	}
+	if tool == nil {
+		return nil, errors.New("tool not found")
+	}
 
-	return map[string]interface{}{
-		"message": "success",
-		"data":    tool,
-	}, nil
+	return model.NewDataResponse("success", tool), nil
 }
 
-func (s *toolService) SearchTools(ctx context.Context, keyword, cursor string, pageSize int, resourceType string) (map[string]interface{}, error) {
-	tools, err := s.toolRepo.Search(ctx, keyword, cursor, pageSize)
+func (s *toolService) SearchTools(ctx context.Context, keyword string, category, tags []string, sort string, page pagination.Request, resourceType string) (*model.ListResponse[model.Tool], error) {
+	const scope = "tools.search"
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	expr, err := tagquery.ParseAll(tags)
+	if err != nil {
+		return nil, err
+	}
+
+	tools, didYouMean, err := searchWithAssist(s.suggester, keyword, p,
+		func(keyword string, page pagination.Page) (*pagination.Result[model.Tool], error) {
+			return s.toolRepo.Search(ctx, keyword, category, expr, sort, page)
+		},
+		func(tool *model.Tool, terms [][]string) {
+			tool.Highlights = highlights(terms, tool.ResourceName, tool.Description)
+		})
 	if err != nil {
 		return nil, err
 	}
 
-	return map[string]interface{}{
-		"messages": "success",
-		"data":     tools,
-	}, nil
+	response := pageResponse(s.cursors, scope, tools)
+	response.DidYouMean = didYouMean
+	return response, nil
 }
 
-func (s *toolService) SubmitTool(ctx context.Context, userID int, req ToolSubmitRequest) (map[string]interface{}, error) {
-	// 将结构体转换为 map 传递给 repository
-	toolData := map[string]interface{}{
-		"name":               req.Name,
-		"link":               req.Link,
-		"description":        req.Description,
-		"description_detail": req.DescriptionDetail,
-		"category":           req.Category,
-		"tags":               req.Tags,
+func (s *toolService) SubmitTool(ctx context.Context, userID int, req model.ToolSubmitRequest) (*model.DataResponse[*model.ResourceReview], error) {
+	fields := []ContentField{
+		{Name: "name", Text: &req.Name},
+		{Name: "description", Text: &req.Description},
+		{Name: "description_detail", Text: &req.DescriptionDetail},
+	}
+	if err := s.filter.Clean(append(fields, listFields("tags", req.Tags)...)...); err != nil {
+		return nil, err
 	}
+	tool, err := s.toolRepo.Create(ctx, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDataResponse("Tool submitted successfully", tool), nil
+}
 
-	tool, err := s.toolRepo.Create(ctx, userID, toolData)
+func (s *toolService) LikeTool(ctx context.Context, userID, resourceID int) (*model.DataResponse[*model.LikeStatus], error) {
+	result, err := s.toolRepo.LikeTool(ctx, userID, resourceID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeTool, resourceID)
 
-	return map[string]interface{}{
-		"message": "Tool submitted successfully",
-		"data":    tool,
-	}, nil
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *toolService) LikeTool(ctx context.Context, userID int, resourceID string) (map[string]interface{}, error) {
-	err := s.toolRepo.AddLike(ctx, userID, resourceID)
+func (s *toolService) UnlikeTool(ctx context.Context, userID, resourceID int) (*model.DataResponse[*model.LikeStatus], error) {
+	result, err := s.toolRepo.UnlikeTool(ctx, userID, resourceID)
 	if err != nil {
 		return nil, err
 	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeTool, resourceID)
 
-	likes, _ := s.toolRepo.GetLikes(ctx, resourceID)
-	return map[string]interface{}{
-		"message": "success",
-		"data": map[string]interface{}{
-			"isliked": true,
-			"likes":   likes,
-		},
-	}, nil
+	return model.NewDataResponse("success", result), nil
 }
 
-// 其他方法保持不变...
-func (s *toolService) UnlikeTool(ctx context.Context, userID int, resourceID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"message": "success",
-		"data": map[string]interface{}{
-			"isliked": false,
-			"likes":   0,
-		},
-	}, nil
+func (s *toolService) CollectTool(ctx context.Context, userID, resourceID int, resourceType string) (*model.DataResponse[*model.CollectStatus], error) {
+	result, err := s.toolRepo.CollectTool(ctx, userID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeTool, resourceID)
+
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *toolService) CollectTool(ctx context.Context, userID int, resourceID, resourceType string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"messages": "success",
-		"data": map[string]interface{}{
-			"collections": 0,
-			"iscollected": true,
-		},
-	}, nil
+func (s *toolService) UncollectTool(ctx context.Context, userID, resourceID int, resourceType string) (*model.DataResponse[*model.CollectStatus], error) {
+	result, err := s.toolRepo.UncollectTool(ctx, userID, resourceID)
+	if err != nil {
+		return nil, err
+	}
+	updateSearchIndex(ctx, s.index, model.ResourceTypeTool, resourceID)
+
+	return model.NewDataResponse("success", result), nil
 }
 
-func (s *toolService) UncollectTool(ctx context.Context, userID int, resourceID, resourceType string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"messages": "success",
-		"data": map[string]interface{}{
-			"collections": 0,
-			"iscollected": false,
-		},
-	}, nil
+func (s *toolService) AddComment(ctx context.Context, userID, resourceID int, resourceType, content string) (*model.DataResponse[*model.Comment], error) {
+	content, flagged, err := s.filter.CleanComment(content)
+	if err != nil {
+		return nil, err
+	}
+	comment, err := s.toolRepo.AddComment(ctx, userID, resourceID, content)
+	if err != nil {
+		return nil, err
+	}
+	s.filter.FlagComment(ctx, comment, flagged)
+
+	return model.NewDataResponse("success", comment), nil
 }
 
-func (s *toolService) AddComment(ctx context.Context, userID int, resourceID, resourceType, content string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"message": "success",
-		"com":     map[string]interface{}{},
-	}, nil
+func (s *toolService) DeleteComment(ctx context.Context, userID, resourceID, commentID int) (*model.DataResponse[*model.Comment], error) {
+	comment, err := s.toolRepo.DeleteComment(ctx, userID, resourceID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDataResponse("success", comment), nil
 }
 
-func (s *toolService) DeleteComment(ctx context.Context, userID int, resourceID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"message": "success",
-		"data":    map[string]interface{}{},
-	}, nil
+func (s *toolService) ReplyComment(ctx context.Context, userID, resourceID, commentID int, resourceType, content string) (*model.DataResponse[*model.Comment], error) {
+	content, flagged, err := s.filter.CleanComment(content)
+	if err != nil {
+		return nil, err
+	}
+	reply, err := s.toolRepo.ReplyComment(ctx, userID, resourceID, commentID, content)
+	if err != nil {
+		return nil, err
+	}
+	s.filter.FlagComment(ctx, reply, flagged)
+
+	return model.NewDataResponse("success", reply), nil
 }
 
-func (s *toolService) ReplyComment(ctx context.Context, userID int, resourceID, commentID, resourceType, content string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"message": "success",
-		"data":    map[string]interface{}{},
-	}, nil
+func (s *toolService) DeleteReply(ctx context.Context, userID, resourceID, commentID int) (*model.DataResponse[*model.Comment], error) {
+	reply, err := s.toolRepo.DeleteReply(ctx, userID, resourceID, commentID)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDataResponse("success", reply), nil
 }
 
-func (s *toolService) DeleteReply(ctx context.Context, userID int, resourceID, commentID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"message": "success",
-		"data":    map[string]interface{}{},
-	}, nil
+func (s *toolService) AddView(ctx context.Context, resourceID int, visitor string) (*model.DataResponse[model.ViewCount], error) {
+	views, err := s.views.Record(ctx, model.ResourceTypeTool, resourceID, visitor)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDataResponse("success", model.ViewCount{Views: views}), nil
 }
 
-func (s *toolService) AddView(ctx context.Context, resourceID string) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"message": "success",
-		"data": map[string]interface{}{
-			"views": 0,
-		},
-	}, nil
+func (s *toolService) GetDailyViews(ctx context.Context, resourceID, days int) (*model.DataResponse[[]model.DailyViews], error) {
+	daily, err := s.views.Daily(ctx, model.ResourceTypeTool, resourceID, days)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewDataResponse("success", daily), nil
+}
+
+func (s *toolService) GetComments(ctx context.Context, resourceID int, page pagination.Request) (*model.ListResponse[model.Comment], error) {
+	scope := commentScope(model.ResourceTypeTool, resourceID)
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	comments, err := s.toolRepo.GetComments(ctx, resourceID, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return pageResponse(s.cursors, scope, comments), nil
 }
@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
+	"softeng-platform/internal/repository"
+	"time"
+)
+
+var (
+	// ErrResourceNotFound 资源不存在、不在预期的位置（回收站内/外），或当前用户无权操作
+	ErrResourceNotFound = errors.New("resource not found")
+	// ErrInvalidResourceType 资源类型不是 tool/course/project
+	ErrInvalidResourceType = errors.New("invalid resource type")
+	// ErrInvalidAction 不支持的资源状态操作
+	ErrInvalidAction = errors.New("invalid action")
+)
+
+// TrashService 资源的软删除、回收站与永久删除。
+// ownerID 为 0 表示管理员操作，不限制资源归属；version 为客户端读到的版本号，
+// 为 0 时不检查，版本过期时返回 *PreconditionFailedError
+type TrashService interface {
+	Delete(ctx context.Context, ownerID, operatorID int, resourceType string, resourceID, version int) (*model.TrashItem, error)
+	Restore(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error)
+	Purge(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error)
+	List(ctx context.Context, ownerID int, page pagination.Request) (*model.ListResponse[model.TrashItem], error)
+	// PurgeExpired 永久删除超过保留期的资源，返回删除的数量
+	PurgeExpired(ctx context.Context) (int, error)
+}
+
+type trashService struct {
+	trashRepo repository.TrashRepository
+	index     repository.SearchIndex
+	auditor   *Auditor
+	cursors   *pagination.Codec
+	retention time.Duration
+}
+
+// NewTrashService retention 为回收站的保留期，超过后由 RunTrashRetention 永久删除；
+// 移入回收站和恢复后同步检索索引；管理员的操作记入 auditor 的审计日志
+func NewTrashService(trashRepo repository.TrashRepository, index repository.SearchIndex, auditor *Auditor, cursors *pagination.Codec, retention time.Duration) TrashService {
+	return &trashService{trashRepo: trashRepo, index: index, auditor: auditor, cursors: cursors, retention: retention}
+}
+
+func (s *trashService) Delete(ctx context.Context, ownerID, operatorID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	if err := checkResourceType(resourceType); err != nil {
+		return nil, err
+	}
+	item, err := s.audit(ctx, ownerID, model.AuditResourceDelete, resourceType, resourceID, func(ctx context.Context) (*model.TrashItem, error) {
+		item, err := s.trashRepo.Delete(ctx, ownerID, operatorID, resourceType, resourceID, version)
+		return s.found(ctx, ownerID, resourceType, resourceID, item, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	updateSearchIndex(ctx, s.index, resourceType, resourceID)
+	return item, nil
+}
+
+func (s *trashService) Restore(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	if err := checkResourceType(resourceType); err != nil {
+		return nil, err
+	}
+	item, err := s.audit(ctx, ownerID, model.AuditResourceRestore, resourceType, resourceID, func(ctx context.Context) (*model.TrashItem, error) {
+		item, err := s.trashRepo.Restore(ctx, ownerID, resourceType, resourceID, version)
+		return s.found(ctx, ownerID, resourceType, resourceID, item, err)
+	})
+	if err != nil {
+		return nil, err
+	}
+	updateSearchIndex(ctx, s.index, resourceType, resourceID)
+	return item, nil
+}
+
+func (s *trashService) Purge(ctx context.Context, ownerID int, resourceType string, resourceID, version int) (*model.TrashItem, error) {
+	if err := checkResourceType(resourceType); err != nil {
+		return nil, err
+	}
+	return s.audit(ctx, ownerID, model.AuditResourcePurge, resourceType, resourceID, func(ctx context.Context) (*model.TrashItem, error) {
+		item, err := s.trashRepo.Purge(ctx, ownerID, resourceType, resourceID, version)
+		return s.found(ctx, ownerID, resourceType, resourceID, item, err)
+	})
+}
+
+// audit 执行 fn；管理员（ownerID 为 0）的操作连同资源操作前后的状态记入审计日志，用户处理自己的资源不记录
+func (s *trashService) audit(ctx context.Context, ownerID int, action, resourceType string, resourceID int,
+	fn func(ctx context.Context) (*model.TrashItem, error)) (*model.TrashItem, error) {
+	if ownerID != 0 {
+		return fn(ctx)
+	}
+	return audited(ctx, s.auditor, func(ctx context.Context) (*model.TrashItem, *auditChange, error) {
+		before, err := s.trashRepo.Get(ctx, 0, resourceType, resourceID)
+		if err != nil {
+			return nil, nil, err
+		}
+		item, err := fn(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		var after *model.TrashItem
+		if action != model.AuditResourcePurge {
+			after = item
+		}
+		return item, &auditChange{action: action, targetType: resourceType, targetID: resourceID, before: before, after: after}, nil
+	})
+}
+
+func (s *trashService) List(ctx context.Context, ownerID int, page pagination.Request) (*model.ListResponse[model.TrashItem], error) {
+	// 游标与用户绑定，管理员的游标范围为 trash:0
+	scope := fmt.Sprintf("trash:%d", ownerID)
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.trashRepo.List(ctx, ownerID, p)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result.Items {
+		s.fillPurgeAt(&result.Items[i])
+	}
+	return pageResponse(s.cursors, scope, result), nil
+}
+
+func (s *trashService) PurgeExpired(ctx context.Context) (int, error) {
+	return s.trashRepo.PurgeExpired(ctx, time.Now().Add(-s.retention))
+}
+
+// found 将仓库的 nil 结果转换为 ErrResourceNotFound，版本冲突时附上资源的当前状态
+func (s *trashService) found(ctx context.Context, ownerID int, resourceType string, resourceID int, item *model.TrashItem, err error) (*model.TrashItem, error) {
+	if errors.Is(err, repository.ErrVersionConflict) {
+		current, err := s.trashRepo.Get(ctx, ownerID, resourceType, resourceID)
+		if err != nil {
+			return nil, err
+		}
+		if current == nil {
+			return nil, ErrResourceNotFound
+		}
+		s.fillPurgeAt(current)
+		return nil, &PreconditionFailedError{Version: current.Version, Current: current}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrResourceNotFound
+	}
+	s.fillPurgeAt(item)
+	return item, nil
+}
+
+func (s *trashService) fillPurgeAt(item *model.TrashItem) {
+	if item.DeletedAt == "" {
+		return
+	}
+	item.PurgeAt = item.DeletedTime.Add(s.retention).Format("2006-01-02 15:04:05")
+}
+
+func checkResourceType(resourceType string) error {
+	switch resourceType {
+	case model.ResourceTypeTool, model.ResourceTypeCourse, model.ResourceTypeProject:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInvalidResourceType, resourceType)
+}
+
+// RunTrashRetention 每隔 interval 清理一次过期的回收站资源，直到 ctx 取消
+func RunTrashRetention(ctx context.Context, trash TrashService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := trash.PurgeExpired(ctx)
+			if err != nil {
+				log.Printf("Failed to purge expired trash: %v", err)
+			}
+			if purged > 0 {
+				log.Printf("Purged %d expired resources from trash", purged)
+			}
+		}
+	}
+}
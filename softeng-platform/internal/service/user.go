@@ -2,28 +2,35 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"softeng-platform/internal/model"
+	"softeng-platform/internal/pagination"
 	"softeng-platform/internal/repository"
+	"time"
 )
 
 type UserService interface {
 	GetProfile(ctx context.Context, userID int) (*model.User, error)
 	UpdateProfile(ctx context.Context, userID int, req model.UpdateProfileRequest) (*model.User, error)
-	GetCollection(ctx context.Context, userID int) (map[string]interface{}, error)
-	DeleteCollection(ctx context.Context, userID int, resourceType string, resourceID int) (map[string]interface{}, error)
-	GetStatus(ctx context.Context, userID int) (map[string]interface{}, error)
-	GetSummit(ctx context.Context, userID int) (map[string]interface{}, error)
-	UpdateResourceStatus(ctx context.Context, userID int, resourceType, resourceID, action, state string) (map[string]interface{}, error)
+	GetCollection(ctx context.Context, userID int, page pagination.Request) (*model.UserResources, error)
+	DeleteCollection(ctx context.Context, userID int, resourceType string, resourceID int, page pagination.Request) (*model.UserResources, error)
+	GetStatus(ctx context.Context, userID int) (*model.UserReviewStatus, error)
+	GetSummit(ctx context.Context, userID int) (*model.UserResources, error)
+	UpdateResourceStatus(ctx context.Context, userID int, resourceType string, resourceID, version int, action, state string) (*model.ManeuverResponse, error)
 	UpdateEmail(ctx context.Context, userID int, name, password, newEmail, code string) (*model.User, error)
 	UpdatePassword(ctx context.Context, userID int, name, email, newPassword, code string) (*model.User, error)
 }
 
 type userService struct {
 	userRepo repository.UserRepository
+	trash    TrashService
+	reviews  repository.ReviewRepository
+	cursors  *pagination.Codec
 }
 
-func NewUserService(userRepo repository.UserRepository) UserService {
-	return &userService{userRepo: userRepo}
+func NewUserService(userRepo repository.UserRepository, trash TrashService, reviews repository.ReviewRepository, cursors *pagination.Codec) UserService {
+	return &userService{userRepo: userRepo, trash: trash, reviews: reviews, cursors: cursors}
 }
 
 func (s *userService) GetProfile(ctx context.Context, userID int) (*model.User, error) {
@@ -54,57 +61,108 @@ func (s *userService) UpdateProfile(ctx context.Context, userID int, req model.U
 	return user, err
 }
 
-func (s *userService) GetCollection(ctx context.Context, userID int) (map[string]interface{}, error) {
-	// 实现获取收藏逻辑
-	return map[string]interface{}{
-		"message":   "success",
-		"resources": []interface{}{},
-		"tools":     []interface{}{},
-		"teaches":   []interface{}{},
-	}, nil
+func (s *userService) GetCollection(ctx context.Context, userID int, page pagination.Request) (*model.UserResources, error) {
+	// 游标与用户绑定，不能用来翻看他人的收藏
+	scope := fmt.Sprintf("collection:%d", userID)
+	p, err := s.cursors.Page(scope, page)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, info, err := s.userRepo.GetCollection(ctx, userID, p)
+	if err != nil {
+		return nil, err
+	}
+
+	collection.Message = "success"
+	paging := pageInfo(s.cursors, scope, info)
+	collection.PageInfo = &paging
+	return collection, nil
 }
 
-func (s *userService) DeleteCollection(ctx context.Context, userID int, resourceType string, resourceID int) (map[string]interface{}, error) {
-	// 实现删除收藏逻辑
-	return map[string]interface{}{
-		"message":   "success",
-		"resources": []interface{}{},
-		"tools":     []interface{}{},
-		"teaches":   []interface{}{},
-	}, nil
+func (s *userService) DeleteCollection(ctx context.Context, userID int, resourceType string, resourceID int, page pagination.Request) (*model.UserResources, error) {
+	if err := s.userRepo.DeleteCollection(ctx, userID, resourceType, resourceID); err != nil {
+		return nil, err
+	}
+
+	// 返回删除后的收藏列表
+	return s.GetCollection(ctx, userID, page)
 }
 
-func (s *userService) GetStatus(ctx context.Context, userID int) (map[string]interface{}, error) {
-	// 实现获取审核状态逻辑
-	return map[string]interface{}{
-		"message":   "success",
-		"resources": []interface{}{},
-		"tools":     []interface{}{},
-		"teaches":   []interface{}{},
-	}, nil
+func (s *userService) GetStatus(ctx context.Context, userID int) (*model.UserReviewStatus, error) {
+	status, err := s.userRepo.GetReviewStatus(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Message = "success"
+	return status, nil
 }
 
-func (s *userService) GetSummit(ctx context.Context, userID int) (map[string]interface{}, error) {
-	// 实现获取个人提交逻辑
-	return map[string]interface{}{
-		"message":   "success",
-		"resources": []interface{}{},
-		"tools":     []interface{}{},
-		"teaches":   []interface{}{},
-	}, nil
+func (s *userService) GetSummit(ctx context.Context, userID int) (*model.UserResources, error) {
+	summit, err := s.userRepo.GetSubmissions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summit.Message = "success"
+	return summit, nil
 }
 
-func (s *userService) UpdateResourceStatus(ctx context.Context, userID int, resourceType, resourceID, action, state string) (map[string]interface{}, error) {
-	// 实现更新资源状态逻辑
-	return map[string]interface{}{
-		"message": "success",
-		"manipulate": map[string]interface{}{
-			"resourceId":   resourceID,
-			"resourceType": resourceType,
-			"newstatus":    action,
-			"oldestatus":   "published", // 假设之前的状态
-			"operateTime":  "2023-12-01 10:00:00",
-			"operator":     "user",
+// UpdateResourceStatus 作者撤回（移入回收站）、恢复或重新提交自己的资源。
+// action 为 delete/withdraw 时移入回收站，为 restore 时恢复，恢复后仍是删除前的审核状态；
+// 为 resubmit 时把被拒绝的工具、项目或课程资源重新提交审核；version 的含义同 TrashService
+func (s *userService) UpdateResourceStatus(ctx context.Context, userID int, resourceType string, resourceID, version int, action, state string) (*model.ManeuverResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	if action == "resubmit" {
+		maneuver, err := transitionStatus(ctx, s.reviews, userID, true, resourceType, resourceID, version, action, "")
+		if err != nil {
+			return nil, err
+		}
+		return &model.ManeuverResponse{Message: "success", Manipulate: *maneuver}, nil
+	}
+
+	var item *model.TrashItem
+	var oldStatus, newStatus string
+	switch action {
+	case "delete", "withdraw":
+		item, err = s.trash.Delete(ctx, userID, userID, resourceType, resourceID, version)
+		if item != nil {
+			oldStatus, newStatus = item.AuditStatus, model.StatusDeleted
+		}
+	case "restore":
+		item, err = s.trash.Restore(ctx, userID, resourceType, resourceID, version)
+		if item != nil {
+			oldStatus, newStatus = model.StatusDeleted, item.AuditStatus
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidAction, action)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	operator := user.Nickname
+	if operator == "" {
+		operator = user.Username
+	}
+	return &model.ManeuverResponse{
+		Message: "success",
+		Manipulate: model.Maneuver{
+			ResourceID:   resourceID,
+			ResourceType: resourceType,
+			NewStatus:    newStatus,
+			OldStatus:    oldStatus,
+			OperateTime:  time.Now().Format("2006-01-02 15:04:05"),
+			Operator:     operator,
+			Version:      item.Version,
 		},
 	}, nil
 }
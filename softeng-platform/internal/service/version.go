@@ -0,0 +1,16 @@
+package service
+
+import (
+	"fmt"
+)
+
+// PreconditionFailedError 修改资源时版本号已过期，Current 为资源的当前状态，
+// 客户端合并修改后应以 Version 重试
+type PreconditionFailedError struct {
+	Version int
+	Current interface{}
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return fmt.Sprintf("resource has been modified, current version is %d", e.Version)
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"softeng-platform/internal/model"
+	"softeng-platform/internal/repository"
+	"sync"
+	"time"
+)
+
+const (
+	// dateLayout 每日浏览量的日期格式
+	dateLayout = "2006-01-02"
+	// defaultDailyViewDays 与 maxDailyViewDays 为每日浏览量默认和最多查询的天数
+	defaultDailyViewDays = 30
+	maxDailyViewDays     = 366
+)
+
+type viewResource struct {
+	resourceType string
+	resourceID   int
+}
+
+// ViewCounter 浏览计数器。同一访客在去重窗口内对同一资源的重复浏览只计一次，
+// 增量先在内存中按资源和日期聚合，由 Run 定期、以及关闭服务时由 Flush 批量写入数据库，
+// 写入后同步检索索引中的浏览量。进程异常退出时会丢失尚未写入的增量
+type ViewCounter struct {
+	viewRepo repository.ViewRepository
+	index    repository.SearchIndex
+	window   time.Duration
+
+	mu sync.Mutex
+	// seen 记录访客最近一次被计数的时间，键为 资源类型:资源ID:访客
+	seen map[string]time.Time
+	// pending 尚未写入的增量，flushing 正在写入的增量，按资源和日期聚合
+	pending  map[viewResource]map[string]int
+	flushing map[viewResource]map[string]int
+
+	// flushMu 保证同一时间只有一次写入
+	flushMu sync.Mutex
+}
+
+// NewViewCounter window 为去重窗口，不大于 0 时每次浏览都计数
+func NewViewCounter(viewRepo repository.ViewRepository, index repository.SearchIndex, window time.Duration) *ViewCounter {
+	return &ViewCounter{
+		viewRepo: viewRepo,
+		index:    index,
+		window:   window,
+		seen:     make(map[string]time.Time),
+		pending:  make(map[viewResource]map[string]int),
+	}
+}
+
+// Record 记录一次浏览，返回包含未写入增量在内的浏览量。
+// visitor 标识访客，登录用户为用户ID，匿名访客为 IP 与 User-Agent 的指纹
+func (v *ViewCounter) Record(ctx context.Context, resourceType string, resourceID int, visitor string) (int, error) {
+	if err := checkResourceType(resourceType); err != nil {
+		return 0, err
+	}
+	views, found, err := v.viewRepo.Views(ctx, resourceType, resourceID)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, ErrResourceNotFound
+	}
+
+	res := viewResource{resourceType, resourceID}
+	now := time.Now()
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	key := fmt.Sprintf("%s:%d:%s", resourceType, resourceID, visitor)
+	if last, ok := v.seen[key]; !ok || now.Sub(last) >= v.window {
+		v.seen[key] = now
+		days := v.pending[res]
+		if days == nil {
+			days = make(map[string]int)
+			v.pending[res] = days
+		}
+		days[now.Format(dateLayout)]++
+	}
+
+	for _, counts := range []map[viewResource]map[string]int{v.pending, v.flushing} {
+		for _, n := range counts[res] {
+			views += n
+		}
+	}
+	return views, nil
+}
+
+// Flush 把聚合的增量写入数据库，写入失败时增量保留到下一次
+func (v *ViewCounter) Flush(ctx context.Context) error {
+	v.flushMu.Lock()
+	defer v.flushMu.Unlock()
+
+	v.mu.Lock()
+	batch := v.pending
+	v.pending = make(map[viewResource]map[string]int)
+	v.flushing = batch
+	v.pruneSeen(time.Now())
+	v.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	var deltas []repository.ViewDelta
+	for res, days := range batch {
+		for date, n := range days {
+			deltas = append(deltas, repository.ViewDelta{
+				ResourceType: res.resourceType,
+				ResourceID:   res.resourceID,
+				Date:         date,
+				Views:        n,
+			})
+		}
+	}
+	err := v.viewRepo.AddViews(ctx, deltas)
+
+	v.mu.Lock()
+	v.flushing = nil
+	if err != nil {
+		for res, days := range batch {
+			if v.pending[res] == nil {
+				v.pending[res] = make(map[string]int)
+			}
+			for date, n := range days {
+				v.pending[res][date] += n
+			}
+		}
+	}
+	v.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for res := range batch {
+		updateSearchIndex(ctx, v.index, res.resourceType, res.resourceID)
+	}
+	return nil
+}
+
+// pruneSeen 清理已超出去重窗口的访客记录，调用方需持有 mu
+func (v *ViewCounter) pruneSeen(now time.Time) {
+	for key, last := range v.seen {
+		if now.Sub(last) >= v.window {
+			delete(v.seen, key)
+		}
+	}
+}
+
+// Daily 返回最近 days 天（含今天）每天的浏览量，包含尚未写入的增量，没有浏览的日期不返回
+func (v *ViewCounter) Daily(ctx context.Context, resourceType string, resourceID, days int) ([]model.DailyViews, error) {
+	if err := checkResourceType(resourceType); err != nil {
+		return nil, err
+	}
+	if _, found, err := v.viewRepo.Views(ctx, resourceType, resourceID); err != nil {
+		return nil, err
+	} else if !found {
+		return nil, ErrResourceNotFound
+	}
+	if days <= 0 {
+		days = defaultDailyViewDays
+	}
+	if days > maxDailyViewDays {
+		days = maxDailyViewDays
+	}
+
+	now := time.Now()
+	from := now.AddDate(0, 0, 1-days).Format(dateLayout)
+	to := now.Format(dateLayout)
+	daily, err := v.viewRepo.DailyViews(ctx, resourceType, resourceID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	res := viewResource{resourceType, resourceID}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, counts := range []map[viewResource]map[string]int{v.pending, v.flushing} {
+		for date, n := range counts[res] {
+			if date >= from && date <= to {
+				daily = addDailyViews(daily, date, n)
+			}
+		}
+	}
+	return daily, nil
+}
+
+// addDailyViews 把 n 次浏览累加到 daily 中 date 当天，保持按日期排序
+func addDailyViews(daily []model.DailyViews, date string, n int) []model.DailyViews {
+	i := 0
+	for i < len(daily) && daily[i].Date < date {
+		i++
+	}
+	if i < len(daily) && daily[i].Date == date {
+		daily[i].Views += n
+		return daily
+	}
+	daily = append(daily, model.DailyViews{})
+	copy(daily[i+1:], daily[i:])
+	daily[i] = model.DailyViews{Date: date, Views: n}
+	return daily
+}
+
+// Run 每隔 interval 写入一次聚合的浏览量，直到 ctx 取消。关闭服务时还需调用一次 Flush
+func (v *ViewCounter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := v.Flush(ctx); err != nil {
+				log.Printf("Failed to flush views: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// minWordLength 词典只收录不短于该长度的词，更短的词不做纠错
+const minWordLength = 3
+
+// Dictionary 拼写纠错用的词典，收录资源文本中的拉丁字母单词及出现次数。
+// 由 Add 构造完成后只读，可以并发查询
+type Dictionary struct {
+	words map[string]int
+}
+
+func NewDictionary() *Dictionary {
+	return &Dictionary{words: make(map[string]int)}
+}
+
+// Add 收录文本中的拉丁字母单词（按非字母数字的字符拆分，转为小写）
+func (d *Dictionary) Add(text string) {
+	for _, word := range strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	}) {
+		if IsLatin(word) && len([]rune(word)) >= minWordLength {
+			d.words[word]++
+		}
+	}
+}
+
+// IsLatin 判断 s 是否只由拉丁字母和数字组成
+func IsLatin(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !unicode.Is(unicode.Latin, c) && !unicode.IsDigit(c) {
+			return false
+		}
+	}
+	return true
+}
+
+// Contains 判断 term 是否为某个词的子串，即按子串匹配时能否直接命中
+func (d *Dictionary) Contains(term string) bool {
+	if d.words[term] > 0 {
+		return true
+	}
+	for word := range d.words {
+		if strings.Contains(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// Similar 返回与 term 的编辑距离不超过 maxDistance 的词，按距离从近到远、出现次数从多到少排列，最多 limit 个。
+// 编辑距离中插入、删除、替换和相邻两个字母互换各计 1
+func (d *Dictionary) Similar(term string, maxDistance, limit int) []string {
+	type candidate struct {
+		word     string
+		distance int
+		count    int
+	}
+	source := []rune(term)
+	var candidates []candidate
+	for word, count := range d.words {
+		target := []rune(word)
+		if abs(len(target)-len(source)) > maxDistance || word == term {
+			continue
+		}
+		if distance := editDistance(source, target); distance <= maxDistance {
+			candidates = append(candidates, candidate{word, distance, count})
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.distance != b.distance {
+			return a.distance < b.distance
+		}
+		if a.count != b.count {
+			return a.count > b.count
+		}
+		return a.word < b.word
+	})
+	var words []string
+	for i := 0; i < len(candidates) && i < limit; i++ {
+		words = append(words, candidates[i].word)
+	}
+	return words
+}
+
+// editDistance 限制换位的 Damerau-Levenshtein 距离（optimal string alignment）
+func editDistance(a, b []rune) int {
+	rows := make([][]int, len(a)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(b)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(a)][len(b)]
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,216 @@
+// Package suggest 检索框输入提示使用的前缀树。
+// 中文按全拼和拼音首字母各建一份索引键，每个节点预先保存经过它的得分最高的若干条记录，
+// 查询只需沿前缀走到对应节点
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-pinyin"
+)
+
+const (
+	// maxPinyinVariants 多音字的读音组合最多展开的个数
+	maxPinyinVariants = 4
+	// maxWordStarts 除开头外，最多从前几个词的词首开始建索引键，使 "code" 能匹配 "Visual Studio Code"
+	maxWordStarts = 4
+)
+
+// Trie 只读的前缀树，由 Builder 构造，可以并发查询
+type Trie struct {
+	root  *node
+	limit int
+}
+
+type node struct {
+	children map[rune]*node
+	top      []ranked // 经过该节点的记录，按得分从高到低，最多 limit 条
+}
+
+type ranked struct {
+	id    int
+	score int
+}
+
+// Builder 构造 Trie，不能并发使用
+type Builder struct {
+	trie *Trie
+}
+
+// NewBuilder limit 为每个前缀最多保留的记录数，即查询能返回的最大条数
+func NewBuilder(limit int) *Builder {
+	return &Builder{trie: &Trie{root: &node{}, limit: limit}}
+}
+
+// Add 为文本 text 的全部索引键登记记录 id，得分高的排在前面，得分相同时 id 小的在前
+func (b *Builder) Add(text string, id, score int) {
+	for _, key := range Keys(text) {
+		b.insert(key, ranked{id: id, score: score})
+	}
+}
+
+func (b *Builder) insert(key string, r ranked) {
+	n := b.trie.root
+	for _, c := range key {
+		child := n.children[c]
+		if child == nil {
+			if n.children == nil {
+				n.children = make(map[rune]*node)
+			}
+			child = &node{}
+			n.children[c] = child
+		}
+		n = child
+		n.top = addRanked(n.top, r, b.trie.limit)
+	}
+}
+
+// addRanked 把 r 插入有序列表，同一记录只保留一次
+func addRanked(top []ranked, r ranked, limit int) []ranked {
+	for _, existing := range top {
+		if existing.id == r.id {
+			return top
+		}
+	}
+	i := sort.Search(len(top), func(i int) bool {
+		if top[i].score != r.score {
+			return top[i].score < r.score
+		}
+		return top[i].id > r.id
+	})
+	if i >= limit {
+		return top
+	}
+	top = append(top, ranked{})
+	copy(top[i+1:], top[i:])
+	top[i] = r
+	if len(top) > limit {
+		top = top[:limit]
+	}
+	return top
+}
+
+// Build 返回构造好的 Trie，之后不能再调用 Add
+func (b *Builder) Build() *Trie {
+	return b.trie
+}
+
+// Lookup 返回索引键以 prefix 开头的记录，按得分从高到低，最多 limit 条
+func (t *Trie) Lookup(prefix string, limit int) []int {
+	prefix = Normalize(prefix)
+	if prefix == "" {
+		return nil
+	}
+	n := t.root
+	for _, c := range prefix {
+		if n = n.children[c]; n == nil {
+			return nil
+		}
+	}
+
+	if limit > len(n.top) {
+		limit = len(n.top)
+	}
+	ids := make([]int, limit)
+	for i := range ids {
+		ids[i] = n.top[i].id
+	}
+	return ids
+}
+
+// Normalize 查询和索引键的统一形式：转为小写，去掉空白和标点
+func Normalize(s string) string {
+	var b strings.Builder
+	for _, c := range strings.ToLower(s) {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			b.WriteRune(c)
+		}
+	}
+	return b.String()
+}
+
+// Keys 返回文本的全部索引键：原文，以及含汉字时的全拼和拼音首字母，多个英文词时的首字母缩写；
+// 同时从前几个词的词首开始各生成一组。例如 "软件工程" 生成 "软件工程"、"ruanjiangongcheng"、"rjgc"，
+// "Visual Studio Code" 生成 "visualstudiocode"、"studiocode"、"code"、"vsc"
+func Keys(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(c rune) bool {
+		return !unicode.IsLetter(c) && !unicode.IsDigit(c)
+	})
+
+	var keys []string
+	seen := make(map[string]bool)
+	add := func(key string) {
+		if key != "" && !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	for start := 0; start < len(words) && start <= maxWordStarts; start++ {
+		rest := strings.Join(words[start:], "")
+		add(rest)
+		for _, key := range pinyinKeys(rest) {
+			add(key)
+		}
+	}
+	if acronym := acronymKey(words); len(words) > 1 && acronym != "" {
+		add(acronym)
+	}
+	return keys
+}
+
+// acronymKey 各词首字母组成的缩写，含汉字时不生成（由拼音首字母覆盖）
+func acronymKey(words []string) string {
+	var b strings.Builder
+	for _, word := range words {
+		for _, c := range word {
+			if unicode.Is(unicode.Han, c) {
+				return ""
+			}
+		}
+		r := []rune(word)
+		b.WriteRune(r[0])
+	}
+	return b.String()
+}
+
+// pinyinKeys 返回文本的全拼和拼音首字母，非汉字原样保留；不含汉字时返回 nil。
+// 多音字按各读音组合展开，最多 maxPinyinVariants 个
+func pinyinKeys(text string) []string {
+	args := pinyin.NewArgs()
+	args.Heteronym = true
+
+	full, initials := []string{""}, []string{""}
+	hasHan := false
+	for _, c := range text {
+		if !unicode.Is(unicode.Han, c) {
+			for i := range full {
+				full[i] += string(c)
+				initials[i] += string(c)
+			}
+			continue
+		}
+
+		readings := pinyin.SinglePinyin(c, args)
+		if len(readings) == 0 {
+			continue
+		}
+		hasHan = true
+		var nextFull, nextInitials []string
+		for i := range full {
+			for _, reading := range readings {
+				if len(nextFull) == maxPinyinVariants {
+					break
+				}
+				nextFull = append(nextFull, full[i]+reading)
+				nextInitials = append(nextInitials, initials[i]+reading[:1])
+			}
+		}
+		full, initials = nextFull, nextInitials
+	}
+	if !hasHan {
+		return nil
+	}
+	return append(full, initials...)
+}
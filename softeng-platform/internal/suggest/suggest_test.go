@@ -0,0 +1,128 @@
+package suggest
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKeys(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    []string
+		notWant []string
+	}{
+		// 中文：原文、全拼和拼音首字母
+		{text: "软件工程", want: []string{"软件工程", "ruanjiangongcheng", "rjgc"}},
+		// 多音字按各读音展开
+		{text: "重庆", want: []string{"重庆", "chongqing", "zhongqing", "cq", "zq"}},
+		{text: "银行", want: []string{"yinhang", "yinxing", "yh", "yx"}},
+		// 英文：去掉空白后的全文、从各词词首开始的后缀和首字母缩写
+		{text: "Visual Studio Code", want: []string{"visualstudiocode", "studiocode", "code", "vsc"}, notWant: []string{"visual studio code", "sc"}},
+		// 中英混合时各词词首也生成拼音键
+		{text: "Go 语言", want: []string{"go语言", "goyuyan", "goyy", "语言", "yuyan", "yy"}},
+		// 只从前 maxWordStarts 个词之后的词首开始
+		{text: "a b c d e f g", want: []string{"abcdefg", "efg"}, notWant: []string{"fg", "g"}},
+		{text: "  ", notWant: []string{""}},
+	}
+	for _, tt := range tests {
+		keys := Keys(tt.text)
+		for _, key := range tt.want {
+			if !slices.Contains(keys, key) {
+				t.Errorf("Keys(%q) = %q, missing %q", tt.text, keys, key)
+			}
+		}
+		for _, key := range tt.notWant {
+			if slices.Contains(keys, key) {
+				t.Errorf("Keys(%q) = %q, should not contain %q", tt.text, keys, key)
+			}
+		}
+	}
+}
+
+func TestKeysLimitPinyinVariants(t *testing.T) {
+	// 每个“长”都有 zhang、chang 两个读音，全拼和首字母各最多展开 maxPinyinVariants 个
+	keys := Keys("长长长长")
+	if want := 1 + 2*maxPinyinVariants; len(keys) != want {
+		t.Errorf("Keys(长长长长) = %q, got %d keys, want %d", keys, len(keys), want)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := map[string]string{
+		"  Visual-Studio Code! ": "visualstudiocode",
+		"软件 工程。":                 "软件工程",
+		"C++":                    "c",
+		"":                       "",
+	}
+	for in, want := range tests {
+		if got := Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	b := NewBuilder(5)
+	b.Add("软件工程", 1, 10)
+	b.Add("软件测试", 2, 30)
+	b.Add("Visual Studio Code", 3, 5)
+	b.Add("Vim", 4, 20)
+	b.Add("重庆大学", 5, 1)
+	b.Add("Go 语言", 6, 8)
+	trie := b.Build()
+
+	tests := []struct {
+		prefix string
+		limit  int
+		want   []int
+	}{
+		// 原文、全拼和首字母都能匹配，按热度从高到低
+		{"软件", 10, []int{2, 1}},
+		{"ruanjian", 10, []int{2, 1}},
+		{"rj", 10, []int{2, 1}},
+		{"rjgc", 10, []int{1}},
+		// 多音字的各种读音
+		{"chongqing", 10, []int{5}},
+		{"zhongqing", 10, []int{5}},
+		{"cqdx", 10, []int{5}},
+		// 词首和缩写
+		{"code", 10, []int{3}},
+		{"studio", 10, []int{3}},
+		{"vsc", 10, []int{3}},
+		{"v", 10, []int{4, 3}},
+		// 查询同样经过 Normalize；去掉空白后的 vscode 不是任何索引键的前缀
+		{"V-S", 10, []int{3}},
+		{"VS Code", 10, nil},
+		// 同一记录的多个索引键经过同一前缀时只出现一次
+		{"go", 10, []int{6}},
+		{"yuyan", 10, []int{6}},
+		// limit 截断
+		{"r", 1, []int{2}},
+		{"", 10, nil},
+		{"xyz", 10, nil},
+	}
+	for _, tt := range tests {
+		got := trie.Lookup(tt.prefix, tt.limit)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Lookup(%q, %d) = %v, want %v", tt.prefix, tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestLookupKeepsTopRanked(t *testing.T) {
+	// 每个前缀只保留得分最高的 limit 条，得分相同时 id 小的在前
+	b := NewBuilder(2)
+	b.Add("alpha", 1, 5)
+	b.Add("alpine", 2, 9)
+	b.Add("algol", 3, 5)
+	b.Add("altair", 4, 1)
+	trie := b.Build()
+
+	if got := trie.Lookup("al", 10); !slices.Equal(got, []int{2, 1}) {
+		t.Errorf("Lookup(al) = %v, want [2 1]", got)
+	}
+	// 更长的前缀下被挤掉的记录仍然保留
+	if got := trie.Lookup("alt", 10); !slices.Equal(got, []int{4}) {
+		t.Errorf("Lookup(alt) = %v, want [4]", got)
+	}
+}
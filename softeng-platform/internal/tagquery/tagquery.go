@@ -0,0 +1,333 @@
+// Package tagquery 标签筛选使用的布尔查询语言，例如 `AI AND (免费 OR 开源) -付费`。
+//
+// 语法：
+//
+//	expr    = or
+//	or      = and { "OR" and }
+//	and     = unary { ["AND"] unary }     // 相邻的两项之间省略 AND
+//	unary   = ("NOT" | "-") unary | primary
+//	primary = tag | '"' 任意字符 '"' | "(" expr ")"
+//
+// AND、OR、NOT 必须大写，小写时作为普通标签；标签中含空白或括号时用双引号括起来。
+// 优先级从高到低为 NOT、AND、OR
+package tagquery
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxTags 与 maxDepth 限制表达式中的标签数和括号、NOT 的嵌套层数
+	maxTags  = 32
+	maxDepth = 16
+)
+
+// Expr 表达式的语法树节点
+type Expr interface {
+	// String 返回等价的表达式，子表达式都加上括号
+	String() string
+}
+
+// Tag 拥有该标签
+type Tag struct {
+	Name string
+}
+
+// And 两侧都成立
+type And struct {
+	Left, Right Expr
+}
+
+// Or 任一侧成立
+type Or struct {
+	Left, Right Expr
+}
+
+// Not 不成立
+type Not struct {
+	X Expr
+}
+
+func (t Tag) String() string { return quote(t.Name) }
+func (a And) String() string { return "(" + a.Left.String() + " AND " + a.Right.String() + ")" }
+func (o Or) String() string  { return "(" + o.Left.String() + " OR " + o.Right.String() + ")" }
+func (n Not) String() string { return "NOT " + n.X.String() }
+
+func quote(name string) string {
+	if name == "AND" || name == "OR" || name == "NOT" || strings.HasPrefix(name, "-") ||
+		strings.ContainsFunc(name, func(c rune) bool { return unicode.IsSpace(c) || c == '(' || c == ')' || c == '"' }) {
+		return `"` + name + `"`
+	}
+	return name
+}
+
+// SyntaxError 表达式无法解析，Pos 为出错处从 1 开始的字符位置（按字符而不是字节计）
+type SyntaxError struct {
+	Query string
+	Pos   int
+	Msg   string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid tag query %q at position %d: %s", e.Query, e.Pos, e.Msg)
+}
+
+// AnyOf 拥有其中任一标签，tags 为空时返回 nil
+func AnyOf(tags []string) Expr {
+	var expr Expr
+	for _, tag := range tags {
+		expr = or(expr, Tag{Name: tag})
+	}
+	return expr
+}
+
+func or(left, right Expr) Expr {
+	if left == nil {
+		return right
+	}
+	return Or{Left: left, Right: right}
+}
+
+// ParseAll 解析多个表达式，结果之间为“或”；空白的表达式忽略，全部为空时返回 nil。
+// 只含一个标签的表达式就是该标签本身，因此多次传入的单个标签按“任一”匹配
+func ParseAll(queries []string) (Expr, error) {
+	var expr Expr
+	for _, q := range queries {
+		e, err := Parse(q)
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			expr = or(expr, e)
+		}
+	}
+	return expr, nil
+}
+
+// Parse 解析一个表达式，只含空白时返回 nil
+func Parse(query string) (Expr, error) {
+	p := &parser{query: query, input: []rune(query)}
+	p.next()
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind == tokEOF {
+		return nil, nil
+	}
+
+	expr := p.parseOr(0)
+	if p.err == nil && p.tok.kind != tokEOF {
+		p.fail(p.tok.pos, "unexpected %s", p.tok)
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	return expr, nil
+}
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokTag
+	tokAnd
+	tokOr
+	tokNot
+	tokLParen
+	tokRParen
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of query"
+	case tokTag:
+		return "tag " + quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type parser struct {
+	query string
+	input []rune
+	off   int // 下一个未读字符的下标
+	tok   token
+	tags  int
+	err   error
+}
+
+func (p *parser) fail(pos int, format string, args ...interface{}) {
+	if p.err == nil {
+		p.err = &SyntaxError{Query: p.query, Pos: pos, Msg: fmt.Sprintf(format, args...)}
+	}
+}
+
+// next 读入下一个词法单元；出错后总是返回 EOF，使解析尽快结束
+func (p *parser) next() {
+	for p.off < len(p.input) && unicode.IsSpace(p.input[p.off]) {
+		p.off++
+	}
+	start := p.off
+	pos := start + 1
+	if p.err != nil || start == len(p.input) {
+		p.tok = token{kind: tokEOF, pos: pos}
+		return
+	}
+
+	switch c := p.input[start]; c {
+	case '(':
+		p.off++
+		p.tok = token{kind: tokLParen, text: "(", pos: pos}
+	case ')':
+		p.off++
+		p.tok = token{kind: tokRParen, text: ")", pos: pos}
+	case '-':
+		p.off++
+		p.tok = token{kind: tokNot, text: "-", pos: pos}
+	case '"':
+		end := start + 1
+		for end < len(p.input) && p.input[end] != '"' {
+			end++
+		}
+		if end == len(p.input) {
+			p.fail(pos, "unterminated quoted tag")
+			p.tok = token{kind: tokEOF, pos: pos}
+			return
+		}
+		p.off = end + 1
+		name := strings.TrimSpace(string(p.input[start+1 : end]))
+		if name == "" {
+			p.fail(pos, "empty quoted tag")
+		}
+		p.tok = token{kind: tokTag, text: name, pos: pos}
+	default:
+		end := start
+		for end < len(p.input) && !unicode.IsSpace(p.input[end]) && !strings.ContainsRune(`()"`, p.input[end]) {
+			end++
+		}
+		p.off = end
+		text := string(p.input[start:end])
+		switch text {
+		case "AND":
+			p.tok = token{kind: tokAnd, text: text, pos: pos}
+		case "OR":
+			p.tok = token{kind: tokOr, text: text, pos: pos}
+		case "NOT":
+			p.tok = token{kind: tokNot, text: text, pos: pos}
+		default:
+			p.tok = token{kind: tokTag, text: text, pos: pos}
+		}
+	}
+}
+
+func (p *parser) parseOr(depth int) Expr {
+	left := p.parseAnd(depth)
+	for p.err == nil && p.tok.kind == tokOr {
+		p.next()
+		left = Or{Left: left, Right: p.parseAnd(depth)}
+	}
+	return left
+}
+
+func (p *parser) parseAnd(depth int) Expr {
+	left := p.parseUnary(depth)
+	for p.err == nil {
+		switch p.tok.kind {
+		case tokAnd:
+			p.next()
+		case tokTag, tokNot, tokLParen:
+		default:
+			return left
+		}
+		left = And{Left: left, Right: p.parseUnary(depth)}
+	}
+	return left
+}
+
+func (p *parser) parseUnary(depth int) Expr {
+	if depth > maxDepth {
+		p.fail(p.tok.pos, "query is nested more than %d levels deep", maxDepth)
+		return nil
+	}
+	if p.tok.kind == tokNot {
+		p.next()
+		return Not{X: p.parseUnary(depth + 1)}
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *parser) parsePrimary(depth int) Expr {
+	tok := p.tok
+	switch tok.kind {
+	case tokTag:
+		p.tags++
+		if p.tags > maxTags {
+			p.fail(tok.pos, "query has more than %d tags", maxTags)
+			return nil
+		}
+		p.next()
+		return Tag{Name: tok.text}
+	case tokLParen:
+		p.next()
+		expr := p.parseOr(depth + 1)
+		if p.err == nil && p.tok.kind != tokRParen {
+			p.fail(p.tok.pos, "expected \")\" to close \"(\" at position %d, found %s", tok.pos, p.tok)
+		}
+		p.next()
+		return expr
+	}
+	p.fail(tok.pos, "expected tag, found %s", tok)
+	return nil
+}
+
+// Match 判断标签列表是否满足表达式，expr 为 nil 时总是满足
+func Match(expr Expr, tags []string) bool {
+	switch e := expr.(type) {
+	case nil:
+		return true
+	case Tag:
+		for _, tag := range tags {
+			if tag == e.Name {
+				return true
+			}
+		}
+		return false
+	case And:
+		return Match(e.Left, tags) && Match(e.Right, tags)
+	case Or:
+		return Match(e.Left, tags) || Match(e.Right, tags)
+	case Not:
+		return !Match(e.X, tags)
+	}
+	panic(fmt.Sprintf("tagquery: unknown expression %T", expr))
+}
+
+// SQL 把表达式编译为 SQL 条件。tagCondition 为判断拥有某个标签的条件，其中唯一的 ? 为标签名，例如
+// "project_id IN (SELECT project_id FROM project_tech_stack WHERE tech = ?)"；返回条件和依次对应的参数
+func SQL(expr Expr, tagCondition string) (string, []interface{}) {
+	var args []interface{}
+	var compile func(Expr) string
+	compile = func(expr Expr) string {
+		switch e := expr.(type) {
+		case Tag:
+			args = append(args, e.Name)
+			return tagCondition
+		case And:
+			return "(" + compile(e.Left) + " AND " + compile(e.Right) + ")"
+		case Or:
+			return "(" + compile(e.Left) + " OR " + compile(e.Right) + ")"
+		case Not:
+			return "NOT (" + compile(e.X) + ")"
+		}
+		panic(fmt.Sprintf("tagquery: unknown expression %T", expr))
+	}
+	return compile(expr), args
+}
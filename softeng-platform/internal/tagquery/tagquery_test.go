@@ -0,0 +1,204 @@
+package tagquery
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string // 语法树的 String()，空表示 nil
+	}{
+		{"AI", "AI"},
+		{"AI AND (免费 OR 开源) -付费", "((AI AND (免费 OR 开源)) AND NOT 付费)"},
+		// 省略 AND，优先级 NOT > AND > OR
+		{"a b OR c", "((a AND b) OR c)"},
+		{"a OR b c", "(a OR (b AND c))"},
+		{"NOT a AND b", "(NOT a AND b)"},
+		{"--a", "NOT NOT a"},
+		{"a OR b OR c", "((a OR b) OR c)"},
+		// 小写的关键字是普通标签，引号中可以有空白和括号
+		{`"machine learning" and (x)`, `(("machine learning" AND and) AND x)`},
+		{`"AND" "f(x)"`, `("AND" AND "f(x)")`},
+		{`"  Go  "`, "Go"},
+		{"C++ C#", "(C++ AND C#)"},
+		{"", ""},
+		{"  \t ", ""},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.query, err)
+			continue
+		}
+		got := ""
+		if expr != nil {
+			got = expr.String()
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %s, want %s", tt.query, got, tt.want)
+			continue
+		}
+		// String 的结果可以再次解析为同样的表达式
+		if expr != nil {
+			again, err := Parse(got)
+			if err != nil || again.String() != got {
+				t.Errorf("Parse(%q) round trip: got %v, %v", got, again, err)
+			}
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{`"unterminated`, 1, "unterminated quoted tag"},
+		{`a "b`, 3, "unterminated quoted tag"},
+		{`a ""`, 3, "empty quoted tag"},
+		{"(a OR b", 8, `expected ")" to close "(" at position 1, found end of query`},
+		{"(a (b)", 7, `expected ")" to close "(" at position 1`},
+		{"a )", 3, `unexpected ")"`},
+		{"a AND", 6, "expected tag, found end of query"},
+		{"a OR", 5, "expected tag, found end of query"},
+		{"a -", 4, "expected tag, found end of query"},
+		{"NOT", 4, "expected tag, found end of query"},
+		{"OR a", 1, `expected tag, found "OR"`},
+		{"a AND OR b", 7, `expected tag, found "OR"`},
+		{"()", 2, `expected tag, found ")"`},
+		// 位置按字符而不是字节计
+		{"免费 AND (", 9, "expected tag, found end of query"},
+		{"开源 )", 4, `unexpected ")"`},
+		{`开源 "免费`, 4, "unterminated quoted tag"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) {
+			t.Errorf("Parse(%q): got %v, want *SyntaxError", tt.query, err)
+			continue
+		}
+		if syntaxErr.Pos != tt.pos || !strings.Contains(syntaxErr.Msg, tt.msg) || syntaxErr.Query != tt.query {
+			t.Errorf("Parse(%q): got position %d %q, want position %d %q", tt.query, syntaxErr.Pos, syntaxErr.Msg, tt.pos, tt.msg)
+		}
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	nested := func(open, close string, n int) string {
+		return strings.Repeat(open, n) + "a" + strings.Repeat(close, n)
+	}
+	tags := func(n int) string {
+		names := make([]string, n)
+		for i := range names {
+			names[i] = fmt.Sprintf("t%02d", i)
+		}
+		return strings.Join(names, " ")
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		pos   int // 0 表示可以解析
+		msg   string
+	}{
+		{"parens at limit", nested("(", ")", maxDepth), 0, ""},
+		{"parens over limit", nested("(", ")", maxDepth+1), maxDepth + 2, "nested more than"},
+		{"NOT at limit", nested("-", "", maxDepth), 0, ""},
+		{"NOT over limit", nested("-", "", maxDepth+1), maxDepth + 2, "nested more than"},
+		{"tags at limit", tags(maxTags), 0, ""},
+		// 第 maxTags+1 个标签的位置，每个标签 3 个字符加一个空格
+		{"tags over limit", tags(maxTags + 1), 4*maxTags + 1, "more than"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(tt.query)
+		if tt.pos == 0 {
+			if err != nil {
+				t.Errorf("%s: %v", tt.name, err)
+			}
+			continue
+		}
+		var syntaxErr *SyntaxError
+		if !errors.As(err, &syntaxErr) || syntaxErr.Pos != tt.pos || !strings.Contains(syntaxErr.Msg, tt.msg) {
+			t.Errorf("%s: got %v, want position %d %q", tt.name, err, tt.pos, tt.msg)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	expr, err := ParseAll([]string{"Go", " ", "Rust -unsafe"})
+	if err != nil || expr.String() != "(Go OR (Rust AND NOT unsafe))" {
+		t.Errorf("ParseAll: got %v, %v", expr, err)
+	}
+	if expr, err := ParseAll([]string{"", "  "}); err != nil || expr != nil {
+		t.Errorf("ParseAll blank: got %v, %v; want nil", expr, err)
+	}
+	var syntaxErr *SyntaxError
+	if _, err := ParseAll([]string{"Go", "(Rust"}); !errors.As(err, &syntaxErr) || syntaxErr.Query != "(Rust" {
+		t.Errorf("ParseAll invalid: got %v", err)
+	}
+	if got := AnyOf([]string{"a", "b", "c"}).String(); got != "((a OR b) OR c)" {
+		t.Errorf("AnyOf: got %s", got)
+	}
+	if AnyOf(nil) != nil {
+		t.Errorf("AnyOf(nil): want nil")
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		query string
+		tags  []string
+		want  bool
+	}{
+		{"AI AND (免费 OR 开源) -付费", []string{"AI", "开源"}, true},
+		{"AI AND (免费 OR 开源) -付费", []string{"AI", "开源", "付费"}, false},
+		{"AI AND (免费 OR 开源) -付费", []string{"AI"}, false},
+		{"a OR b", []string{"b"}, true},
+		{"-a", nil, true},
+		{"NOT NOT a", []string{"a"}, true},
+		// 区分大小写，整个标签相等才匹配
+		{"go", []string{"Go", "golang"}, false},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		if got := Match(expr, tt.tags); got != tt.want {
+			t.Errorf("Match(%q, %v) = %v, want %v", tt.query, tt.tags, got, tt.want)
+		}
+	}
+	if !Match(nil, nil) {
+		t.Errorf("Match(nil) = false, want true")
+	}
+}
+
+func TestSQL(t *testing.T) {
+	const cond = "id IN (SELECT id FROM tags WHERE tag = ?)"
+	tests := []struct {
+		query string
+		want  string
+		args  []interface{}
+	}{
+		{"a", cond, []interface{}{"a"}},
+		{"a (b OR -c)", "(" + cond + " AND (" + cond + " OR NOT (" + cond + ")))", []interface{}{"a", "b", "c"}},
+		{`"x y" OR x`, "(" + cond + " OR " + cond + ")", []interface{}{"x y", "x"}},
+	}
+	for _, tt := range tests {
+		expr, err := Parse(tt.query)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tt.query, err)
+		}
+		got, args := SQL(expr, cond)
+		if got != tt.want || !slices.Equal(args, tt.args) {
+			t.Errorf("SQL(%q) = %s %v, want %s %v", tt.query, got, args, tt.want, tt.args)
+		}
+	}
+}
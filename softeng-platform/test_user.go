@@ -23,7 +23,7 @@ func main() {
 
 	// 2. 连接数据库
 	fmt.Println("连接数据库...")
-	db, err := repository.NewDatabase(cfg.DatabaseURL)
+	db, err := repository.NewDatabase(cfg.DatabaseDriver, cfg.DatabaseURL, cfg.DatabasePool)
 	if err != nil {
 		log.Fatal("连接数据库失败:", err)
 	}